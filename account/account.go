@@ -2,6 +2,7 @@ package account
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/prebid/go-gdpr/consentconstants"
@@ -15,7 +16,10 @@ import (
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 )
 
-// GetAccount looks up the config.Account object referenced by the given accountID, with access rules applied
+// GetAccount looks up the config.Account object referenced by the given accountID, with access rules applied.
+//
+// The variant of a versioned account is picked by the stored_requests.VariantSelection of the context. The callers
+// which don't set one, such as the cookie sync, setuid and event endpoints, always get the variant of an empty key.
 func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_requests.AccountFetcher, accountID string, me metrics.MetricsEngine) (account *config.Account, errs []error) {
 	if cfg.AccountRequired && accountID == metrics.PublisherUnknown {
 		return nil, []error{&errortypes.AcctRequired{
@@ -43,6 +47,12 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 		account = &pubAccount
 	} else {
 		// accountID resolved to a valid account, merge with AccountDefaults for a complete config
+		accountJSON, err := stored_requests.VariantSelectionFromContext(ctx).Account(accountID, accountJSON)
+		if err != nil {
+			return nil, []error{&errortypes.MalformedAcct{
+				Message: fmt.Sprintf("The prebid-server account config for account id \"%s\" is malformed. Please reach out to the prebid server host.", accountID),
+			}}
+		}
 		account = &config.Account{}
 		if err := jsonutil.UnmarshalValid(accountJSON, account); err != nil {
 			return nil, []error{&errortypes.MalformedAcct{
@@ -78,9 +88,48 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 		account.Privacy.IPv4Config.AnonKeepBits = iputil.IPv4DefaultMaskingBitSize
 	}
 
+	if ladderErrs := account.Targeting.ValidatePriceGranularityLadders(nil); len(ladderErrs) > 0 {
+		reportInvalidSection(account.ID, metrics.AccountConfigPriceGranularityLadders, ladderErrs, me)
+		account.Targeting.PriceGranularityLadders = nil
+	}
+
+	if keyErrs := account.Targeting.ValidateKeys(nil); len(keyErrs) > 0 {
+		reportInvalidSection(account.ID, metrics.AccountConfigTargetingKeys, keyErrs, me)
+		account.Targeting.Namespace = ""
+		account.Targeting.Keys = nil
+	}
+
+	if rateLimitErrs := account.RateLimits.Validate(nil); len(rateLimitErrs) > 0 {
+		reportInvalidSection(account.ID, metrics.AccountConfigRateLimits, rateLimitErrs, me)
+		account.RateLimits = cfg.AccountDefaults.RateLimits
+	}
+
+	if mirroringErrs := account.Mirroring.Validate(nil); len(mirroringErrs) > 0 {
+		reportInvalidSection(account.ID, metrics.AccountConfigMirroring, mirroringErrs, me)
+		account.Mirroring = cfg.AccountDefaults.Mirroring
+	}
+
+	if hooksErrs := account.Hooks.Validate(nil); len(hooksErrs) > 0 {
+		reportInvalidSection(account.ID, metrics.AccountConfigHooksExecutionPlan, hooksErrs, me)
+		account.Hooks.ExecutionPlan = cfg.AccountDefaults.Hooks.ExecutionPlan
+	}
+
+	if moduleFetcher, ok := fetcher.(*moduleConfigFetcher); ok {
+		moduleFetcher.dropInvalidModuleConfigs(account)
+	}
+
 	return account, nil
 }
 
+// invalidSectionLog logs the invalid sections of the account configs.
+var invalidSectionLog = newInvalidConfigLog()
+
+// reportInvalidSection logs and counts an invalid section of the account config, which is reset to its default.
+func reportInvalidSection(accountID string, section metrics.AccountConfigSection, errs []error, me metrics.MetricsEngine) {
+	invalidSectionLog.warnf("Invalid %s config of account %s, using the default instead: %s", section, accountID, errors.Join(errs...))
+	me.RecordAccountConfigInvalid(section)
+}
+
 // TCF2Enforcements maps enforcement algo string values to their integer representation and is
 // used to limit string compares
 var TCF2Enforcements = map[string]config.TCF2EnforcementAlgo{
@@ -27,10 +27,17 @@ var mockAccountData = map[string]json.RawMessage{
 	"valid_acct_dsa":            json.RawMessage(`{"disabled":false, "privacy": {"dsa": {"default": "` + validDSA + `"}}}`),
 	"invalid_acct_dsa":          json.RawMessage(`{"disabled":false, "privacy": {"dsa": {"default": "` + invalidDSA + `"}}}`),
 	"invalid_acct_ipv6_ipv4":    json.RawMessage(`{"disabled":false, "privacy": {"ipv6": {"anon_keep_bits": -32}, "ipv4": {"anon_keep_bits": -16}}}`),
+	"invalid_acct_ladders":      json.RawMessage(`{"disabled":false, "targeting": {"price_granularity_ladders": [{"name": "eur", "currency": "EURO"}]}}`),
+	"invalid_acct_keys":         json.RawMessage(`{"disabled":false, "targeting": {"namespace": "pb-s", "keys": [{"key": "hb_pb", "name": "price"}]}}`),
+	"invalid_acct_rate_limits":  json.RawMessage(`{"disabled":false, "rate_limits": {"enabled": true, "default": {"requests_per_second": -1}}}`),
+	"invalid_acct_mirroring":    json.RawMessage(`{"disabled":false, "mirroring": {"sample_rate": 2}}`),
+	"invalid_acct_hooks":        json.RawMessage(`{"disabled":false, "hooks": {"execution_plan": {"endpoints": {"/openrtb2/auction": {"stages": {"entrypoint": {"groups": [{"hook_sequence": [{"module_code": "acme.foo", "hook_impl_code": "foo", "conditions": {"sampling_percentage": 120}}]}]}}}}}}}`),
 	"disabled_acct":             json.RawMessage(`{"disabled":true}`),
 	"malformed_acct":            json.RawMessage(`{"disabled":"invalid type"}`),
 	"gdpr_channel_enabled_acct": json.RawMessage(`{"disabled":false,"gdpr":{"channel_enabled":{"amp":true}}}`),
 	"ccpa_channel_enabled_acct": json.RawMessage(`{"disabled":false,"ccpa":{"channel_enabled":{"amp":true}}}`),
+	"versioned_acct":            json.RawMessage(`{"variants":[{"id":"v2","weight":1,"data":{"disabled":false,"events":{"enabled":true}}},{"id":"v1","weight":0,"data":{"disabled":false}}]}`),
+	"invalid_versioned_acct":    json.RawMessage(`{"variants":[{"id":"v1","weight":0,"data":{"disabled":false}}]}`),
 }
 
 type mockAccountFetcher struct {
@@ -63,7 +70,19 @@ func TestGetAccount(t *testing.T) {
 		disabled bool
 		// checkDefaultIP indicates IPv6 and IPv6 should be set to default values
 		wantDefaultIP bool
-		wantDSA       *openrtb_ext.ExtRegsDSA
+		// wantDefaultRateLimits indicates the rate limits should be replaced by the account defaults
+		wantDefaultRateLimits bool
+		// wantDefaultMirroring indicates the mirroring should be replaced by the account defaults
+		wantDefaultMirroring bool
+		// wantDefaultExecutionPlan indicates the hooks execution plan should be replaced by the account defaults
+		wantDefaultExecutionPlan bool
+		// wantNoLadders indicates the price granularity ladders should be dropped
+		wantNoLadders bool
+		// wantNoKeys indicates the targeting namespace and keys should be dropped
+		wantNoKeys bool
+		// wantInvalidSection is the account config section expected to be counted as invalid, if any
+		wantInvalidSection metrics.AccountConfigSection
+		wantDSA            *openrtb_ext.ExtRegsDSA
 		// expected error, or nil if account should be found
 		err error
 	}{
@@ -91,6 +110,11 @@ func TestGetAccount(t *testing.T) {
 		{accountID: "valid_acct_dsa", required: true, disabled: true, wantDSA: validDSA, err: nil},
 
 		{accountID: "invalid_acct_ipv6_ipv4", required: true, disabled: false, err: nil, wantDefaultIP: true},
+		{accountID: "invalid_acct_ladders", required: false, disabled: false, err: nil, wantNoLadders: true, wantInvalidSection: metrics.AccountConfigPriceGranularityLadders},
+		{accountID: "invalid_acct_keys", required: false, disabled: false, err: nil, wantNoKeys: true, wantInvalidSection: metrics.AccountConfigTargetingKeys},
+		{accountID: "invalid_acct_rate_limits", required: false, disabled: false, err: nil, wantDefaultRateLimits: true, wantInvalidSection: metrics.AccountConfigRateLimits},
+		{accountID: "invalid_acct_mirroring", required: false, disabled: false, err: nil, wantDefaultMirroring: true, wantInvalidSection: metrics.AccountConfigMirroring},
+		{accountID: "invalid_acct_hooks", required: false, disabled: false, err: nil, wantDefaultExecutionPlan: true, wantInvalidSection: metrics.AccountConfigHooksExecutionPlan},
 		{accountID: "invalid_acct_dsa", required: false, disabled: false, err: &errortypes.MalformedAcct{}},
 
 		// pubID given and matches a host account explicitly disabled (Disabled: true on account json)
@@ -124,6 +148,7 @@ func TestGetAccount(t *testing.T) {
 
 			metrics := &metrics.MetricsEngineMock{}
 			metrics.Mock.On("RecordAccountUpgradeStatus", mock.Anything, mock.Anything).Return()
+			metrics.Mock.On("RecordAccountConfigInvalid", mock.Anything).Return()
 
 			account, errors := GetAccount(context.Background(), cfg, fetcher, test.accountID, metrics)
 
@@ -140,6 +165,27 @@ func TestGetAccount(t *testing.T) {
 				assert.Equal(t, account.Privacy.IPv6Config.AnonKeepBits, iputil.IPv6DefaultMaskingBitSize, "ipv6 should be set to default value")
 				assert.Equal(t, account.Privacy.IPv4Config.AnonKeepBits, iputil.IPv4DefaultMaskingBitSize, "ipv4 should be set to default value")
 			}
+			if test.wantDefaultRateLimits {
+				assert.Equal(t, cfg.AccountDefaults.RateLimits, account.RateLimits, "rate limits should be set to default value")
+			}
+			if test.wantDefaultMirroring {
+				assert.Equal(t, cfg.AccountDefaults.Mirroring, account.Mirroring, "mirroring should be set to default value")
+			}
+			if test.wantDefaultExecutionPlan {
+				assert.Equal(t, cfg.AccountDefaults.Hooks.ExecutionPlan, account.Hooks.ExecutionPlan, "execution plan should be set to default value")
+			}
+			if test.wantNoLadders {
+				assert.Nil(t, account.Targeting.PriceGranularityLadders, "price granularity ladders should be dropped")
+			}
+			if test.wantNoKeys {
+				assert.Empty(t, account.Targeting.Namespace, "targeting namespace should be dropped")
+				assert.Nil(t, account.Targeting.Keys, "targeting keys should be dropped")
+			}
+			if test.wantInvalidSection != "" {
+				metrics.AssertCalled(t, "RecordAccountConfigInvalid", test.wantInvalidSection)
+			} else {
+				metrics.AssertNotCalled(t, "RecordAccountConfigInvalid", mock.Anything)
+			}
 			if test.wantDSA != nil {
 				assert.Equal(t, test.wantDSA, account.Privacy.DSA.DefaultUnpacked)
 			}
@@ -147,6 +193,25 @@ func TestGetAccount(t *testing.T) {
 	}
 }
 
+func TestGetAccountVariant(t *testing.T) {
+	cfg := &config.Configuration{}
+	assert.NoError(t, cfg.MarshalAccountDefaults())
+
+	selection := stored_requests.NewVariantSelection("user1")
+	ctx := stored_requests.WithVariantSelection(context.Background(), selection)
+
+	account, errs := GetAccount(ctx, cfg, &mockAccountFetcher{}, "versioned_acct", &metrics.MetricsEngineMock{})
+	assert.Empty(t, errs)
+	assert.Equal(t, "versioned_acct", account.ID)
+	assert.True(t, account.Events.Enabled)
+	assert.Equal(t, &openrtb_ext.ExtStoredVariants{Account: "v2"}, selection.Selected())
+
+	account, errs = GetAccount(context.Background(), cfg, &mockAccountFetcher{}, "invalid_versioned_acct", &metrics.MetricsEngineMock{})
+	assert.Nil(t, account)
+	assert.Len(t, errs, 1)
+	assert.IsType(t, &errortypes.MalformedAcct{}, errs[0])
+}
+
 func TestSetDerivedConfig(t *testing.T) {
 	tests := []struct {
 		description              string
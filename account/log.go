@@ -0,0 +1,48 @@
+package account
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+)
+
+// maxInvalidConfigLogs is the number of invalid account configs logged per minute, so a misconfigured account
+// doesn't flood the logs on every request.
+const maxInvalidConfigLogs = 10
+
+// invalidConfigLog logs up to maxInvalidConfigLogs invalid account configs per minute, and the number of those
+// left out once the minute is over.
+type invalidConfigLog struct {
+	mutex       sync.Mutex
+	time        timeutil.Time
+	windowStart time.Time
+	logged      int
+	suppressed  int
+}
+
+func newInvalidConfigLog() *invalidConfigLog {
+	return &invalidConfigLog{time: &timeutil.RealTime{}}
+}
+
+func (l *invalidConfigLog) warnf(format string, args ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if now := l.time.Now(); now.Sub(l.windowStart) >= time.Minute {
+		if l.suppressed > 0 {
+			glog.Warningf("%d more invalid account configs weren't logged", l.suppressed)
+		}
+		l.windowStart = now
+		l.logged = 0
+		l.suppressed = 0
+	}
+
+	if l.logged >= maxInvalidConfigLogs {
+		l.suppressed++
+		return
+	}
+	l.logged++
+	glog.Warningf(format, args...)
+}
@@ -0,0 +1,34 @@
+package account
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTime struct {
+	time time.Time
+}
+
+func (t *fakeTime) Now() time.Time {
+	return t.time
+}
+
+func TestInvalidConfigLogIsRateLimited(t *testing.T) {
+	now := &fakeTime{time: time.Date(2020, time.July, 1, 12, 0, 0, 0, time.UTC)}
+	log := &invalidConfigLog{time: now}
+	err := errors.New("invalid is not allowed")
+
+	for i := 0; i < maxInvalidConfigLogs+5; i++ {
+		log.warnf("invalid config of account %s: %s", "acct", err)
+	}
+	assert.Equal(t, maxInvalidConfigLogs, log.logged)
+	assert.Equal(t, 5, log.suppressed)
+
+	now.time = now.time.Add(time.Minute)
+	log.warnf("invalid config of account %s: %s", "acct", err)
+	assert.Equal(t, 1, log.logged, "a new minute must reset the count")
+	assert.Equal(t, 0, log.suppressed)
+}
@@ -0,0 +1,60 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+)
+
+// ModuleConfigResolver resolves the effective config of a module in the format "vendor.module_name". It returns
+// errors for the account-level or request-level configs making the module config invalid.
+type ModuleConfigResolver interface {
+	Resolve(module string, account, request json.RawMessage) (json.RawMessage, []error)
+}
+
+// moduleConfigFetcher is an AccountFetcher carrying the resolver which validates the module configs of the
+// fetched accounts.
+type moduleConfigFetcher struct {
+	stored_requests.AccountFetcher
+	resolver ModuleConfigResolver
+	log      *invalidConfigLog
+}
+
+// WithModuleConfigResolver wraps the account fetcher so GetAccount leaves out the module configs of the accounts
+// which are invalid once merged on top of the host config. The modules then run with the host config instead.
+func WithModuleConfigResolver(fetcher stored_requests.AccountFetcher, resolver ModuleConfigResolver) stored_requests.AccountFetcher {
+	return &moduleConfigFetcher{
+		AccountFetcher: fetcher,
+		resolver:       resolver,
+		log:            newInvalidConfigLog(),
+	}
+}
+
+// dropInvalidModuleConfigs leaves out the module configs of the account which the resolver rejects.
+func (f *moduleConfigFetcher) dropInvalidModuleConfigs(account *config.Account) {
+	if f.resolver == nil || len(account.Hooks.Modules) == 0 {
+		return
+	}
+
+	// the modules may be shared with the account defaults, so they are copied rather than modified
+	var valid config.AccountModules
+	for vendor, modules := range account.Hooks.Modules {
+		for name, cfg := range modules {
+			module := vendor + "." + name
+			if _, errs := f.resolver.Resolve(module, cfg, nil); len(errs) > 0 {
+				f.log.warnf("Invalid config for %s module of account %s, using the host config instead: %s", module, account.ID, errors.Join(errs...))
+				continue
+			}
+			if valid == nil {
+				valid = make(config.AccountModules, len(account.Hooks.Modules))
+			}
+			if valid[vendor] == nil {
+				valid[vendor] = make(map[string]json.RawMessage, len(modules))
+			}
+			valid[vendor][name] = cfg
+		}
+	}
+	account.Hooks.Modules = valid
+}
@@ -0,0 +1,123 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeModuleConfigResolver rejects the configs with an "invalid" key.
+type fakeModuleConfigResolver struct{}
+
+func (r fakeModuleConfigResolver) Resolve(module string, account, request json.RawMessage) (json.RawMessage, []error) {
+	var cfg map[string]interface{}
+	if err := json.Unmarshal(account, &cfg); err != nil {
+		return nil, []error{err}
+	}
+	if _, ok := cfg["invalid"]; ok {
+		return nil, []error{errors.New("invalid is not allowed")}
+	}
+	return account, nil
+}
+
+func TestGetAccountDropsInvalidModuleConfigs(t *testing.T) {
+	fetcher := mockAccountFetcherFunc(func(accountID string) json.RawMessage {
+		return json.RawMessage(`{"hooks":{"modules":{"prebid":{"geolocation":{"overwrite":true},"creativesafety":{"invalid":1}}}}}`)
+	})
+	cfg := &config.Configuration{}
+	require.NoError(t, cfg.MarshalAccountDefaults())
+
+	testCases := []struct {
+		description     string
+		fetcher         stored_requests.AccountFetcher
+		expectedModules config.AccountModules
+	}{
+		{
+			description: "with_resolver",
+			fetcher:     WithModuleConfigResolver(fetcher, fakeModuleConfigResolver{}),
+			expectedModules: config.AccountModules{
+				"prebid": {"geolocation": json.RawMessage(`{"overwrite":true}`)},
+			},
+		},
+		{
+			description: "without_resolver",
+			fetcher:     fetcher,
+			expectedModules: config.AccountModules{
+				"prebid": {"geolocation": json.RawMessage(`{"overwrite":true}`), "creativesafety": json.RawMessage(`{"invalid":1}`)},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			account, errs := GetAccount(context.Background(), cfg, test.fetcher, "acct", &metrics.MetricsEngineMock{})
+
+			assert.Empty(t, errs)
+			assert.Equal(t, test.expectedModules, account.Hooks.Modules)
+		})
+	}
+}
+
+func TestDropInvalidModuleConfigs(t *testing.T) {
+	testCases := []struct {
+		description     string
+		resolver        ModuleConfigResolver
+		modules         config.AccountModules
+		expectedModules config.AccountModules
+	}{
+		{
+			description:     "no_resolver",
+			modules:         config.AccountModules{"prebid": {"m": json.RawMessage(`{"invalid":1}`)}},
+			expectedModules: config.AccountModules{"prebid": {"m": json.RawMessage(`{"invalid":1}`)}},
+		},
+		{
+			description:     "all_valid",
+			resolver:        fakeModuleConfigResolver{},
+			modules:         config.AccountModules{"prebid": {"m1": json.RawMessage(`{"a":1}`)}, "vendor": {"m2": json.RawMessage(`{}`)}},
+			expectedModules: config.AccountModules{"prebid": {"m1": json.RawMessage(`{"a":1}`)}, "vendor": {"m2": json.RawMessage(`{}`)}},
+		},
+		{
+			description:     "all_invalid",
+			resolver:        fakeModuleConfigResolver{},
+			modules:         config.AccountModules{"prebid": {"m": json.RawMessage(`{"invalid":1}`)}},
+			expectedModules: nil,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			fetcher := WithModuleConfigResolver(nil, test.resolver).(*moduleConfigFetcher)
+
+			account := &config.Account{ID: "acct", Hooks: config.AccountHooks{Modules: test.modules}}
+			fetcher.dropInvalidModuleConfigs(account)
+
+			assert.Equal(t, test.expectedModules, account.Hooks.Modules)
+		})
+	}
+}
+
+func TestDropInvalidModuleConfigsKeepsDefaults(t *testing.T) {
+	fetcher := WithModuleConfigResolver(nil, fakeModuleConfigResolver{}).(*moduleConfigFetcher)
+
+	defaults := config.Account{Hooks: config.AccountHooks{Modules: config.AccountModules{
+		"prebid": {"valid": json.RawMessage(`{}`), "invalid": json.RawMessage(`{"invalid":1}`)},
+	}}}
+	account := defaults
+	fetcher.dropInvalidModuleConfigs(&account)
+
+	assert.Len(t, account.Hooks.Modules["prebid"], 1)
+	assert.Len(t, defaults.Hooks.Modules["prebid"], 2, "the account defaults must not be modified")
+}
+
+type mockAccountFetcherFunc func(accountID string) json.RawMessage
+
+func (f mockAccountFetcherFunc) FetchAccount(_ context.Context, _ json.RawMessage, accountID string) (json.RawMessage, []error) {
+	return f(accountID), nil
+}
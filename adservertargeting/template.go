@@ -0,0 +1,113 @@
+package adservertargeting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/prebid-server/v3/util/templateutil"
+)
+
+// Sources available to targeting template placeholders
+const (
+	TemplateSourceBid       = "bid"
+	TemplateSourceImp       = "imp"
+	TemplateSourceRequest   = "request"
+	TemplateSourceTargeting = "targeting"
+	TemplateSourceBidder    = "bidder"
+	TemplateSourceMediaType = "mediatype"
+)
+
+// targetingTemplateSyntax lists the sources of the targeting template placeholders and whether they must be
+// followed by a path, e.g. "bid.dealid"
+var targetingTemplateSyntax = templateutil.Syntax{
+	Sources: map[string]bool{
+		TemplateSourceBid:       true,
+		TemplateSourceImp:       true,
+		TemplateSourceRequest:   true,
+		TemplateSourceTargeting: true,
+		TemplateSourceBidder:    false,
+		TemplateSourceMediaType: false,
+	},
+}
+
+// TargetingTemplate is a parsed targeting value template, using the template syntax of the stored requests.
+// Literal text is copied as is and every {{source.path}} placeholder is replaced by the value it resolves to,
+// e.g. "{{bid.w}}x{{bid.h}}". A placeholder may provide a default used when the value is missing, e.g.
+// "{{bid.dealid=nodeal}}". Targeting values are strings, so the only type a placeholder may give is string.
+type TargetingTemplate struct {
+	template templateutil.Template
+}
+
+// TemplateResolver returns the value found at the path of the source, if any
+type TemplateResolver func(source string, path []string) (string, bool)
+
+// ParseTargetingTemplate parses a targeting value template
+func ParseTargetingTemplate(template string) (TargetingTemplate, error) {
+	parsed, err := targetingTemplateSyntax.Parse(template)
+	if err != nil {
+		return TargetingTemplate{}, err
+	}
+	for _, p := range parsed.Placeholders() {
+		if p.Type != "" && p.Type != "string" {
+			return TargetingTemplate{}, fmt.Errorf("placeholder %s can't be of type %q in template %q", p, p.Type, template)
+		}
+	}
+	return TargetingTemplate{template: parsed}, nil
+}
+
+// IsEmpty returns true when the template has no content
+func (t TargetingTemplate) IsEmpty() bool {
+	return len(t.template.Parts) == 0
+}
+
+// Execute builds the value of the template. It returns false when a placeholder without a default
+// can't be resolved, in which case the targeting key should be omitted.
+func (t TargetingTemplate) Execute(resolve TemplateResolver) (string, bool) {
+	var sb strings.Builder
+	for _, part := range t.template.Parts {
+		if part.Placeholder == nil {
+			sb.WriteString(part.Literal)
+			continue
+		}
+		value, ok := resolve(part.Placeholder.Source, part.Placeholder.Path)
+		if !ok || value == "" {
+			if !part.Placeholder.HasDefault {
+				return "", false
+			}
+			value = part.Placeholder.Default
+		}
+		sb.WriteString(value)
+	}
+	return sb.String(), true
+}
+
+// LookupTemplateValue returns the string, number or boolean found at the path of the JSON data.
+// Numeric path segments index into arrays, e.g. "adomain.0".
+func LookupTemplateValue(data []byte, path []string) (string, bool) {
+	keys := make([]string, len(path))
+	for i, segment := range path {
+		if _, err := strconv.Atoi(segment); err == nil {
+			keys[i] = "[" + segment + "]"
+		} else {
+			keys[i] = segment
+		}
+	}
+
+	value, dataType, _, err := jsonparser.Get(data, keys...)
+	if err != nil {
+		return "", false
+	}
+	switch dataType {
+	case jsonparser.String:
+		unescaped, err := jsonparser.ParseString(value)
+		if err != nil {
+			return "", false
+		}
+		return unescaped, true
+	case jsonparser.Number, jsonparser.Boolean:
+		return string(value), true
+	}
+	return "", false
+}
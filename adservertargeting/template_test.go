@@ -0,0 +1,109 @@
+package adservertargeting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTargetingTemplate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		template    string
+		expectedErr string
+	}{
+		{name: "empty", template: ""},
+		{name: "literal", template: "static"},
+		{name: "placeholders", template: "{{bid.w}}x{{ bid.h }}"},
+		{name: "default", template: "{{imp.ext.gpid=none}}"},
+		{name: "string-type", template: "{{bid.crid:string=nocrid}}"},
+		{name: "no-path-sources", template: "{{bidder}}_{{mediatype}}"},
+		{name: "unterminated", template: "{{bid.w", expectedErr: `unterminated placeholder in template "{{bid.w"`},
+		{name: "unexpected-end", template: "bid}}", expectedErr: `unexpected }} in template "bid}}"`},
+		{name: "unknown-source", template: "{{seat.id}}", expectedErr: `unknown source "seat" in template "{{seat.id}}"`},
+		{name: "missing-path", template: "{{bid}}", expectedErr: `source "bid" requires a path in template "{{bid}}"`},
+		{name: "unexpected-path", template: "{{bidder.name}}", expectedErr: `source "bidder" does not accept a path in template "{{bidder.name}}"`},
+		{name: "empty-segment", template: "{{request.site..page}}", expectedErr: `invalid path segment "" in "request.site..page" in template "{{request.site..page}}"`},
+		{name: "non-string-type", template: "{{bid.w:int}}", expectedErr: `placeholder bid.w can't be of type "int" in template "{{bid.w:int}}"`},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseTargetingTemplate(test.template)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestTargetingTemplateExecute(t *testing.T) {
+	values := map[string]string{
+		"bid.w":           "300",
+		"bid.h":           "250",
+		"bidder":          "appnexus",
+		"targeting.hb_pb": "1.50",
+		"imp.ext.empty":   "",
+	}
+	resolve := func(source string, path []string) (string, bool) {
+		value, ok := values[strings.Join(append([]string{source}, path...), ".")]
+		return value, ok
+	}
+
+	testCases := []struct {
+		name          string
+		template      string
+		expectedValue string
+		expectedOK    bool
+	}{
+		{name: "literal", template: "static", expectedValue: "static", expectedOK: true},
+		{name: "size", template: "{{bid.w}}x{{bid.h}}", expectedValue: "300x250", expectedOK: true},
+		{name: "mixed", template: "{{bidder}}:{{targeting.hb_pb}}", expectedValue: "appnexus:1.50", expectedOK: true},
+		{name: "missing", template: "{{bid.dealid}}", expectedOK: false},
+		{name: "missing-with-fallback", template: "deal_{{bid.dealid=none}}", expectedValue: "deal_none", expectedOK: true},
+		{name: "empty-with-fallback", template: "{{imp.ext.empty=unset}}", expectedValue: "unset", expectedOK: true},
+		{name: "empty-fallback", template: "{{bid.dealid=}}", expectedValue: "", expectedOK: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			template, err := ParseTargetingTemplate(test.template)
+			require.NoError(t, err)
+
+			value, ok := template.Execute(resolve)
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
+
+func TestLookupTemplateValue(t *testing.T) {
+	data := []byte(`{"id":"bid1","price":1.5,"adomain":["a.com","b.com"],"ext":{"flag":true,"name":"a\"b","obj":{}}}`)
+
+	testCases := []struct {
+		name          string
+		path          []string
+		expectedValue string
+		expectedOK    bool
+	}{
+		{name: "string", path: []string{"id"}, expectedValue: "bid1", expectedOK: true},
+		{name: "number", path: []string{"price"}, expectedValue: "1.5", expectedOK: true},
+		{name: "array-index", path: []string{"adomain", "1"}, expectedValue: "b.com", expectedOK: true},
+		{name: "boolean", path: []string{"ext", "flag"}, expectedValue: "true", expectedOK: true},
+		{name: "escaped-string", path: []string{"ext", "name"}, expectedValue: `a"b`, expectedOK: true},
+		{name: "object", path: []string{"ext", "obj"}, expectedOK: false},
+		{name: "missing", path: []string{"dealid"}, expectedOK: false},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			value, ok := LookupTemplateValue(data, test.path)
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
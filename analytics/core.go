@@ -49,14 +49,15 @@ type AmpObject struct {
 
 // Loggable object of a transaction at /openrtb2/video endpoint
 type VideoObject struct {
-	Status         int
-	Errors         []error
-	Response       *openrtb2.BidResponse
-	VideoRequest   *openrtb_ext.BidRequestVideo
-	VideoResponse  *openrtb_ext.BidResponseVideo
-	StartTime      time.Time
-	SeatNonBid     []openrtb_ext.SeatNonBid
-	RequestWrapper *openrtb_ext.RequestWrapper
+	Status               int
+	Errors               []error
+	Response             *openrtb2.BidResponse
+	VideoRequest         *openrtb_ext.BidRequestVideo
+	VideoResponse        *openrtb_ext.BidResponseVideo
+	StartTime            time.Time
+	SeatNonBid           []openrtb_ext.SeatNonBid
+	RequestWrapper       *openrtb_ext.RequestWrapper
+	HookExecutionOutcome []hookexecution.StageOutcome
 }
 
 // Loggable object of a transaction at /setuid
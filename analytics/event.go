@@ -50,4 +50,6 @@ type EventRequest struct {
 	Timestamp   int64          `json:"timestamp,omitempty"`
 	Integration string         `json:"integration,omitempty"`
 	VType       VastType       `json:"vtype,omitempty"`
+	// Unverified is true when the event URL signature could not be verified and the event was accepted anyway
+	Unverified bool `json:"unverified,omitempty"`
 }
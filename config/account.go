@@ -4,11 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/prebid-server/v3/adservertargeting"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/util/iputil"
+	"golang.org/x/text/currency"
 )
 
 // ChannelType enumerates the values of integrations Prebid Server can configure for an account
@@ -42,6 +46,24 @@ type Account struct {
 	DefaultBidLimit         int                                         `mapstructure:"default_bid_limit" json:"default_bid_limit"`
 	BidAdjustments          *openrtb_ext.ExtRequestPrebidBidAdjustments `mapstructure:"bidadjustments" json:"bidadjustments"`
 	Privacy                 AccountPrivacy                              `mapstructure:"privacy" json:"privacy"`
+	Targeting               AccountTargeting                            `mapstructure:"targeting" json:"targeting"`
+	RateLimits              AccountRateLimits                           `mapstructure:"rate_limits" json:"rate_limits"`
+	Mirroring               AccountMirroring                            `mapstructure:"mirroring" json:"mirroring"`
+}
+
+// Validate checks an account config merged with the account defaults. The price floors, IP masking and
+// targeting are checked the same way as the account defaults of the host config.
+func (a *Account) Validate(errs []error) []error {
+	errs = a.PriceFloors.validate(errs)
+	errs = a.Privacy.IPv6Config.Validate(errs)
+	errs = a.Privacy.IPv4Config.Validate(errs)
+	errs = a.Targeting.Validate(errs)
+	errs = a.RateLimits.Validate(errs)
+	errs = a.Mirroring.Validate(errs)
+	if err := UnpackDSADefault(a.Privacy.DSA); err != nil {
+		errs = append(errs, fmt.Errorf("privacy.dsa.default is malformed: %v", err))
+	}
+	return errs
 }
 
 // CookieSync represents the account-level defaults for the cookie sync endpoint.
@@ -51,6 +73,151 @@ type CookieSync struct {
 	DefaultCoopSync *bool `mapstructure:"default_coop_sync" json:"default_coop_sync"`
 }
 
+// AccountTargeting represents account-specific targeting configuration
+type AccountTargeting struct {
+	PriceGranularityLadders []AccountPriceGranularityLadder `mapstructure:"price_granularity_ladders" json:"price_granularity_ladders"`
+	// Namespace replaces the "hb" namespace of the Prebid targeting keys, e.g. "pbs" yields pbs_pb and pbs_bidder
+	Namespace string                `mapstructure:"namespace" json:"namespace"`
+	Keys      []AccountTargetingKey `mapstructure:"keys" json:"keys"`
+}
+
+// AccountTargetingKey customizes a Prebid targeting key when Key names one, or adds a new key otherwise.
+// Value is a template built from bid, imp and request fields, e.g. "{{bid.w}}x{{bid.h}}" or "{{imp.tagid=none}}".
+type AccountTargetingKey struct {
+	Key      string `mapstructure:"key" json:"key"`
+	Name     string `mapstructure:"name" json:"name"`
+	Value    string `mapstructure:"value" json:"value"`
+	Suppress bool   `mapstructure:"suppress" json:"suppress"`
+}
+
+var targetingKeyNameRegex = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// AccountPriceGranularityLadder defines a named price granularity ladder whose ranges are expressed in
+// the ladder currency. It is picked when the request ad server currency matches the ladder currency.
+type AccountPriceGranularityLadder struct {
+	Name                      string                                 `mapstructure:"name" json:"name"`
+	Currency                  string                                 `mapstructure:"currency" json:"currency"`
+	PriceGranularity          *openrtb_ext.PriceGranularity          `mapstructure:"pricegranularity" json:"pricegranularity"`
+	MediaTypePriceGranularity *openrtb_ext.MediaTypePriceGranularity `mapstructure:"mediatypepricegranularity" json:"mediatypepricegranularity"`
+}
+
+// Validate checks the price granularity ladders and the targeting key customizations
+func (t *AccountTargeting) Validate(errs []error) []error {
+	errs = t.ValidatePriceGranularityLadders(errs)
+	return t.ValidateKeys(errs)
+}
+
+// ValidatePriceGranularityLadders checks that every ladder has a unique name, a well-formed currency and valid ranges
+func (t *AccountTargeting) ValidatePriceGranularityLadders(errs []error) []error {
+	names := make(map[string]struct{}, len(t.PriceGranularityLadders))
+	currencies := make(map[string]struct{}, len(t.PriceGranularityLadders))
+	for i, ladder := range t.PriceGranularityLadders {
+		if ladder.Name == "" {
+			errs = append(errs, fmt.Errorf("targeting.price_granularity_ladders[%d].name is required", i))
+		} else if _, found := names[ladder.Name]; found {
+			errs = append(errs, fmt.Errorf("targeting.price_granularity_ladders[%d].name %q is not unique", i, ladder.Name))
+		}
+		names[ladder.Name] = struct{}{}
+
+		unit, err := currency.ParseISO(ladder.Currency)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("targeting.price_granularity_ladders[%d].currency %q is not a valid ISO-4217 currency code", i, ladder.Currency))
+		} else if _, found := currencies[unit.String()]; found {
+			errs = append(errs, fmt.Errorf("targeting.price_granularity_ladders[%d].currency %s is already used by another ladder", i, unit.String()))
+		} else {
+			currencies[unit.String()] = struct{}{}
+		}
+
+		if ladder.PriceGranularity == nil && ladder.MediaTypePriceGranularity == nil {
+			errs = append(errs, fmt.Errorf("targeting.price_granularity_ladders[%d] must define pricegranularity or mediatypepricegranularity", i))
+		}
+		errs = validateLadderGranularity(errs, i, "pricegranularity", ladder.PriceGranularity)
+		if mtpg := ladder.MediaTypePriceGranularity; mtpg != nil {
+			errs = validateLadderGranularity(errs, i, "mediatypepricegranularity.banner", mtpg.Banner)
+			errs = validateLadderGranularity(errs, i, "mediatypepricegranularity.video", mtpg.Video)
+			errs = validateLadderGranularity(errs, i, "mediatypepricegranularity.native", mtpg.Native)
+		}
+	}
+	return errs
+}
+
+func validateLadderGranularity(errs []error, index int, field string, pg *openrtb_ext.PriceGranularity) []error {
+	if pg == nil {
+		return errs
+	}
+	if pg.Precision == nil || *pg.Precision < 0 || *pg.Precision > openrtb_ext.MaxDecimalFigures {
+		errs = append(errs, fmt.Errorf("targeting.price_granularity_ladders[%d].%s.precision must be between 0 and %d", index, field, openrtb_ext.MaxDecimalFigures))
+	}
+	if len(pg.Ranges) == 0 {
+		errs = append(errs, fmt.Errorf("targeting.price_granularity_ladders[%d].%s.ranges must not be empty", index, field))
+	}
+	prevMax := 0.0
+	for _, gr := range pg.Ranges {
+		if gr.Max <= prevMax {
+			errs = append(errs, fmt.Errorf(`targeting.price_granularity_ladders[%d].%s.ranges must be ordered with increasing "max"`, index, field))
+			break
+		}
+		if gr.Increment <= 0.0 {
+			errs = append(errs, fmt.Errorf("targeting.price_granularity_ladders[%d].%s.ranges increment must be a nonzero positive number", index, field))
+			break
+		}
+		prevMax = gr.Max
+	}
+	return errs
+}
+
+// ValidateKeys checks the targeting namespace and that every key customization is well-formed
+func (t *AccountTargeting) ValidateKeys(errs []error) []error {
+	if t.Namespace != "" && !targetingKeyNameRegex.MatchString(t.Namespace) {
+		errs = append(errs, fmt.Errorf("targeting.namespace %q may only contain letters, digits and underscores", t.Namespace))
+	}
+
+	customized := make(map[string]struct{}, len(t.Keys))
+	names := make(map[string]struct{}, len(t.Keys))
+	for i, key := range t.Keys {
+		if key.Key != "" {
+			if !slices.Contains(openrtb_ext.TargetingKeys, openrtb_ext.TargetingKey(key.Key)) {
+				errs = append(errs, fmt.Errorf("targeting.keys[%d].key %q is not a Prebid targeting key", i, key.Key))
+			} else if _, found := customized[key.Key]; found {
+				errs = append(errs, fmt.Errorf("targeting.keys[%d].key %q is customized more than once", i, key.Key))
+			}
+			customized[key.Key] = struct{}{}
+		} else if key.Name == "" || key.Value == "" {
+			errs = append(errs, fmt.Errorf("targeting.keys[%d] must define a name and a value to add a key", i))
+		}
+
+		if key.Suppress {
+			if key.Key == "" || key.Name != "" || key.Value != "" {
+				errs = append(errs, fmt.Errorf("targeting.keys[%d].suppress only applies to a Prebid targeting key without name or value", i))
+			}
+			continue
+		}
+
+		if key.Name != "" {
+			if !targetingKeyNameRegex.MatchString(key.Name) {
+				errs = append(errs, fmt.Errorf("targeting.keys[%d].name %q may only contain letters, digits and underscores", i, key.Name))
+			} else if _, found := names[key.Name]; found {
+				errs = append(errs, fmt.Errorf("targeting.keys[%d].name %q is not unique", i, key.Name))
+			}
+			names[key.Name] = struct{}{}
+		}
+		if _, err := adservertargeting.ParseTargetingTemplate(key.Value); err != nil {
+			errs = append(errs, fmt.Errorf("targeting.keys[%d].value is invalid: %v", i, err))
+		}
+	}
+	return errs
+}
+
+// PriceGranularityLadder returns the ladder defined for the given ad server currency, if any
+func (t *AccountTargeting) PriceGranularityLadder(adServerCurrency string) (AccountPriceGranularityLadder, bool) {
+	for _, ladder := range t.PriceGranularityLadders {
+		if strings.EqualFold(ladder.Currency, adServerCurrency) {
+			return ladder, true
+		}
+	}
+	return AccountPriceGranularityLadder{}, false
+}
+
 // AccountCCPA represents account-specific CCPA configuration
 type AccountCCPA struct {
 	Enabled        *bool          `mapstructure:"enabled" json:"enabled,omitempty"`
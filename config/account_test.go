@@ -7,6 +7,7 @@ import (
 
 	"github.com/prebid/go-gdpr/consentconstants"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -1014,3 +1015,187 @@ func TestIPMaskingValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestAccountTargetingValidate(t *testing.T) {
+	validGranularity := &openrtb_ext.PriceGranularity{
+		Precision: ptrutil.ToPtr(0),
+		Ranges:    []openrtb_ext.GranularityRange{{Min: 0, Max: 5000, Increment: 10}},
+	}
+
+	tests := []struct {
+		name      string
+		targeting AccountTargeting
+		want      []error
+	}{
+		{
+			name:      "empty",
+			targeting: AccountTargeting{},
+		},
+		{
+			name: "valid",
+			targeting: AccountTargeting{
+				PriceGranularityLadders: []AccountPriceGranularityLadder{
+					{Name: "jpy", Currency: "JPY", PriceGranularity: validGranularity},
+					{Name: "krw", Currency: "KRW", MediaTypePriceGranularity: &openrtb_ext.MediaTypePriceGranularity{Video: validGranularity}},
+				},
+			},
+		},
+		{
+			name: "duplicate-name-and-currency",
+			targeting: AccountTargeting{
+				PriceGranularityLadders: []AccountPriceGranularityLadder{
+					{Name: "jpy", Currency: "JPY", PriceGranularity: validGranularity},
+					{Name: "jpy", Currency: "jpy", PriceGranularity: validGranularity},
+				},
+			},
+			want: []error{
+				errors.New(`targeting.price_granularity_ladders[1].name "jpy" is not unique`),
+				errors.New("targeting.price_granularity_ladders[1].currency JPY is already used by another ladder"),
+			},
+		},
+		{
+			name: "missing-name-currency-and-granularity",
+			targeting: AccountTargeting{
+				PriceGranularityLadders: []AccountPriceGranularityLadder{{}},
+			},
+			want: []error{
+				errors.New("targeting.price_granularity_ladders[0].name is required"),
+				errors.New(`targeting.price_granularity_ladders[0].currency "" is not a valid ISO-4217 currency code`),
+				errors.New("targeting.price_granularity_ladders[0] must define pricegranularity or mediatypepricegranularity"),
+			},
+		},
+		{
+			name: "invalid-ranges",
+			targeting: AccountTargeting{
+				PriceGranularityLadders: []AccountPriceGranularityLadder{
+					{
+						Name:     "jpy",
+						Currency: "JPY",
+						PriceGranularity: &openrtb_ext.PriceGranularity{
+							Ranges: []openrtb_ext.GranularityRange{{Min: 0, Max: 100, Increment: 10}, {Min: 100, Max: 50, Increment: 10}},
+						},
+						MediaTypePriceGranularity: &openrtb_ext.MediaTypePriceGranularity{
+							Banner: &openrtb_ext.PriceGranularity{
+								Precision: ptrutil.ToPtr(0),
+								Ranges:    []openrtb_ext.GranularityRange{{Min: 0, Max: 100, Increment: 0}},
+							},
+						},
+					},
+				},
+			},
+			want: []error{
+				errors.New("targeting.price_granularity_ladders[0].pricegranularity.precision must be between 0 and 15"),
+				errors.New(`targeting.price_granularity_ladders[0].pricegranularity.ranges must be ordered with increasing "max"`),
+				errors.New("targeting.price_granularity_ladders[0].mediatypepricegranularity.banner.ranges increment must be a nonzero positive number"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.targeting.Validate(nil)
+			assert.ElementsMatch(t, errs, tt.want)
+		})
+	}
+}
+
+func TestAccountTargetingValidateKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		targeting AccountTargeting
+		want      []error
+	}{
+		{
+			name:      "empty",
+			targeting: AccountTargeting{},
+		},
+		{
+			name: "valid",
+			targeting: AccountTargeting{
+				Namespace: "pbs",
+				Keys: []AccountTargetingKey{
+					{Key: "hb_pb", Name: "price"},
+					{Key: "hb_format", Suppress: true},
+					{Key: "hb_size", Value: "{{bid.w}}x{{bid.h}}"},
+					{Name: "gpid", Value: "{{imp.ext.gpid=none}}"},
+				},
+			},
+		},
+		{
+			name:      "invalid-namespace",
+			targeting: AccountTargeting{Namespace: "hb-ns"},
+			want:      []error{errors.New(`targeting.namespace "hb-ns" may only contain letters, digits and underscores`)},
+		},
+		{
+			name: "unknown-key",
+			targeting: AccountTargeting{Keys: []AccountTargetingKey{
+				{Key: "hb_unknown", Name: "unknown"},
+			}},
+			want: []error{errors.New(`targeting.keys[0].key "hb_unknown" is not a Prebid targeting key`)},
+		},
+		{
+			name: "key-customized-twice",
+			targeting: AccountTargeting{Keys: []AccountTargetingKey{
+				{Key: "hb_pb", Name: "price"},
+				{Key: "hb_pb", Suppress: true},
+			}},
+			want: []error{errors.New(`targeting.keys[1].key "hb_pb" is customized more than once`)},
+		},
+		{
+			name: "added-key-without-value",
+			targeting: AccountTargeting{Keys: []AccountTargetingKey{
+				{Name: "gpid"},
+			}},
+			want: []error{errors.New("targeting.keys[0] must define a name and a value to add a key")},
+		},
+		{
+			name: "suppress-with-name",
+			targeting: AccountTargeting{Keys: []AccountTargetingKey{
+				{Key: "hb_pb", Name: "price", Suppress: true},
+			}},
+			want: []error{errors.New("targeting.keys[0].suppress only applies to a Prebid targeting key without name or value")},
+		},
+		{
+			name: "invalid-and-duplicate-names",
+			targeting: AccountTargeting{Keys: []AccountTargetingKey{
+				{Key: "hb_pb", Name: "price"},
+				{Key: "hb_bidder", Name: "price"},
+				{Name: "gp id", Value: "x"},
+			}},
+			want: []error{
+				errors.New(`targeting.keys[1].name "price" is not unique`),
+				errors.New(`targeting.keys[2].name "gp id" may only contain letters, digits and underscores`),
+			},
+		},
+		{
+			name: "invalid-value",
+			targeting: AccountTargeting{Keys: []AccountTargetingKey{
+				{Name: "gpid", Value: "{{imp.ext.gpid"},
+			}},
+			want: []error{errors.New(`targeting.keys[0].value is invalid: unterminated placeholder in template "{{imp.ext.gpid"`)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.targeting.ValidateKeys(nil)
+			assert.ElementsMatch(t, errs, tt.want)
+		})
+	}
+}
+
+func TestAccountTargetingPriceGranularityLadder(t *testing.T) {
+	targeting := AccountTargeting{
+		PriceGranularityLadders: []AccountPriceGranularityLadder{
+			{Name: "jpy", Currency: "JPY"},
+			{Name: "krw", Currency: "KRW"},
+		},
+	}
+
+	ladder, found := targeting.PriceGranularityLadder("krw")
+	assert.True(t, found)
+	assert.Equal(t, "krw", ladder.Name)
+
+	_, found = targeting.PriceGranularityLadder("EUR")
+	assert.False(t, found)
+}
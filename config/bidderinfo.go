@@ -41,7 +41,7 @@ type BidderInfo struct {
 
 	// needed for Facebook
 	PlatformID string `yaml:"platform_id" mapstructure:"platform_id"`
-	AppSecret  string `yaml:"app_secret" mapstructure:"app_secret"`
+	AppSecret  string `yaml:"app_secret" mapstructure:"app_secret" redact:"true"`
 	// EndpointCompression determines, if set, the type of compression the bid request will undergo before being sent to the corresponding bid server
 	EndpointCompression string       `yaml:"endpointCompression" mapstructure:"endpointCompression"`
 	OpenRTB             *OpenRTBInfo `yaml:"openrtb" mapstructure:"openrtb"`
@@ -88,7 +88,7 @@ type DebugInfo struct {
 
 type AdapterXAPI struct {
 	Username string `yaml:"username" mapstructure:"username"`
-	Password string `yaml:"password" mapstructure:"password"`
+	Password string `yaml:"password" mapstructure:"password" redact:"true"`
 	Tracker  string `yaml:"tracker" mapstructure:"tracker"`
 }
 
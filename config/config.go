@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -37,11 +38,12 @@ type Configuration struct {
 	// StatusResponse is the string which will be returned by the /status endpoint when things are OK.
 	// If empty, it will return a 204 with no content.
 	StatusResponse    string          `mapstructure:"status_response"`
+	Health            Health          `mapstructure:"health"`
 	AuctionTimeouts   AuctionTimeouts `mapstructure:"auction_timeouts_ms"`
 	TmaxAdjustments   TmaxAdjustments `mapstructure:"tmax_adjustments"`
 	CacheURL          Cache           `mapstructure:"cache"`
 	ExtCacheURL       ExternalCache   `mapstructure:"external_cache"`
-	RecaptchaSecret   string          `mapstructure:"recaptcha_secret"`
+	RecaptchaSecret   string          `mapstructure:"recaptcha_secret" redact:"true"`
 	HostCookie        HostCookie      `mapstructure:"host_cookie"`
 	Metrics           Metrics         `mapstructure:"metrics"`
 	StoredRequests    StoredRequests  `mapstructure:"stored_requests"`
@@ -103,11 +105,43 @@ type Configuration struct {
 	Hooks       Hooks       `mapstructure:"hooks"`
 	Validations Validations `mapstructure:"validations"`
 	PriceFloors PriceFloors `mapstructure:"price_floors"`
+	// RateLimiting enables the account rate limits of the auction endpoints
+	RateLimiting RateLimiting `mapstructure:"rate_limiting"`
+	// Mirroring copies a sample of the auction requests to a shadow PBS or a file
+	Mirroring Mirroring `mapstructure:"mirroring"`
 }
 
 type Admin struct {
 	Enabled bool `mapstructure:"enabled"`
+	// ConfigAPI exposes the host config on the admin server and allows some bidder settings to be changed at runtime
+	ConfigAPI AdminConfigAPI `mapstructure:"config_api"`
 }
+
+// AdminConfigAPI configures the /admin/config and /admin/bidders endpoints of the admin server.
+type AdminConfigAPI struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Tokens are the bearer tokens accepted in the Authorization header of the config api requests
+	Tokens []string `mapstructure:"tokens" redact:"true"`
+	// AuditLogPath is the file the runtime changes are appended to, as JSON lines. The changes are always
+	// logged through glog.
+	AuditLogPath string `mapstructure:"audit_log_path"`
+}
+
+func (cfg *AdminConfigAPI) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if len(cfg.Tokens) == 0 {
+		errs = append(errs, errors.New("admin.config_api.tokens must contain at least one token"))
+	}
+	for i, token := range cfg.Tokens {
+		if token == "" {
+			errs = append(errs, fmt.Errorf("admin.config_api.tokens[%d] must not be empty", i))
+		}
+	}
+	return errs
+}
+
 type PriceFloors struct {
 	Enabled bool              `mapstructure:"enabled"`
 	Fetcher PriceFloorFetcher `mapstructure:"fetcher"`
@@ -148,6 +182,7 @@ func (cfg *Configuration) validate(v *viper.Viper) []error {
 	errs = cfg.GDPR.validate(v, errs)
 	errs = cfg.CurrencyConverter.validate(errs)
 	errs = cfg.Debug.validate(errs)
+	errs = cfg.Event.Signing.validate(errs)
 	errs = cfg.ExtCacheURL.validate(errs)
 	errs = cfg.AccountDefaults.PriceFloors.validate(errs)
 	if cfg.AccountDefaults.Disabled {
@@ -162,6 +197,18 @@ func (cfg *Configuration) validate(v *viper.Viper) []error {
 	errs = cfg.BidderInfos.validate(errs)
 	errs = cfg.AccountDefaults.Privacy.IPv6Config.Validate(errs)
 	errs = cfg.AccountDefaults.Privacy.IPv4Config.Validate(errs)
+	errs = cfg.AccountDefaults.Targeting.Validate(errs)
+	errs = cfg.AccountDefaults.RateLimits.Validate(errs)
+	errs = cfg.RateLimiting.validate(errs)
+	errs = cfg.AccountDefaults.Mirroring.Validate(errs)
+	errs = cfg.Mirroring.validate(errs)
+	errs = cfg.Validations.validate(errs)
+	errs = cfg.Hooks.validate(errs)
+	errs = cfg.Admin.ConfigAPI.validate(errs)
+	if cfg.Metrics.Dashboard.Enabled && len(cfg.Admin.ConfigAPI.Tokens) == 0 {
+		errs = append(errs, errors.New("admin.config_api.tokens must contain at least one token to serve metrics.dashboard"))
+	}
+	errs = cfg.Health.validate(errs)
 
 	return errs
 }
@@ -520,7 +567,83 @@ type VTrack struct {
 }
 
 type Event struct {
-	TimeoutMS int64 `mapstructure:"timeout_ms"`
+	TimeoutMS int64        `mapstructure:"timeout_ms"`
+	Signing   EventSigning `mapstructure:"signing"`
+}
+
+// EventSigning configures the signature, and optionally the encryption, of the event and VAST tracking URLs
+type EventSigning struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Keys sign and verify event URLs. The first key signs new URLs while every key verifies them, so a key is
+	// rotated by adding its replacement first and removing it once the URLs it signed are no longer in use.
+	Keys    []EventSigningKey `mapstructure:"keys"`
+	Encrypt bool              `mapstructure:"encrypt"`
+	// UnsignedEvents is the handling of events without a valid signature, either "reject" or "flag"
+	UnsignedEvents string `mapstructure:"unsigned_events"`
+	// MaxAgeSeconds is how long a signed event URL stays valid. Older URLs are handled as if they were unsigned.
+	MaxAgeSeconds int `mapstructure:"max_age_seconds"`
+}
+
+type EventSigningKey struct {
+	ID     string `mapstructure:"id"`
+	Secret string `mapstructure:"secret" redact:"true"`
+	// EncryptionKey is a base64 encoded AES-128, AES-192 or AES-256 key, required when encryption is enabled
+	EncryptionKey string `mapstructure:"encryption_key" redact:"true"`
+}
+
+const (
+	EventSigningReject string = "reject"
+	EventSigningFlag   string = "flag"
+)
+
+const eventSigningMinSecretLength = 16
+
+func (cfg *EventSigning) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.UnsignedEvents != EventSigningReject && cfg.UnsignedEvents != EventSigningFlag {
+		errs = append(errs, fmt.Errorf("event.signing.unsigned_events must be %s or %s. Got %s", EventSigningReject, EventSigningFlag, cfg.UnsignedEvents))
+	}
+	if len(cfg.Keys) == 0 {
+		errs = append(errs, errors.New("event.signing.keys must contain at least one key when event signing is enabled"))
+	}
+	if cfg.MaxAgeSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("event.signing.max_age_seconds must be positive. Got %d", cfg.MaxAgeSeconds))
+	}
+
+	ids := make(map[string]struct{}, len(cfg.Keys))
+	for i, key := range cfg.Keys {
+		if key.ID == "" || strings.Contains(key.ID, ".") {
+			errs = append(errs, fmt.Errorf("event.signing.keys[%d].id is required and may not contain a dot", i))
+		} else if _, found := ids[key.ID]; found {
+			errs = append(errs, fmt.Errorf("event.signing.keys[%d].id %s is not unique", i, key.ID))
+		}
+		ids[key.ID] = struct{}{}
+
+		if len(key.Secret) < eventSigningMinSecretLength {
+			errs = append(errs, fmt.Errorf("event.signing.keys[%d].secret must be at least %d characters long", i, eventSigningMinSecretLength))
+		}
+		if cfg.Encrypt {
+			if _, err := key.AESKey(); err != nil {
+				errs = append(errs, fmt.Errorf("event.signing.keys[%d].encryption_key is invalid: %v", i, err))
+			}
+		}
+	}
+	return errs
+}
+
+// AESKey decodes the encryption key
+func (key EventSigningKey) AESKey() ([]byte, error) {
+	aesKey, err := base64.StdEncoding.DecodeString(key.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	switch len(aesKey) {
+	case 16, 24, 32:
+		return aesKey, nil
+	}
+	return nil, fmt.Errorf("key must be 16, 24 or 32 bytes long, got %d", len(aesKey))
 }
 
 type HostCookie struct {
@@ -548,6 +671,7 @@ type Metrics struct {
 	Influxdb   InfluxMetrics     `mapstructure:"influxdb"`
 	Prometheus PrometheusMetrics `mapstructure:"prometheus"`
 	Disabled   DisabledMetrics   `mapstructure:"disabled_metrics"`
+	Dashboard  DashboardMetrics  `mapstructure:"dashboard"`
 }
 
 type DisabledMetrics struct {
@@ -573,10 +697,14 @@ type DisabledMetrics struct {
 
 	// True if we want to stop collecting account modules metrics
 	AccountModulesMetrics bool `mapstructure:"account_modules_metrics"`
+
+	// True if we don't want to collect the per adapter seat non bids metric
+	AdapterNonBids bool `mapstructure:"adapter_nonbids"`
 }
 
 func (cfg *Metrics) validate(errs []error) []error {
-	return cfg.Prometheus.validate(errs)
+	errs = cfg.Prometheus.validate(errs)
+	return cfg.Dashboard.validate(errs)
 }
 
 type InfluxMetrics struct {
@@ -584,7 +712,7 @@ type InfluxMetrics struct {
 	Database           string `mapstructure:"database"`
 	Measurement        string `mapstructure:"measurement"`
 	Username           string `mapstructure:"username"`
-	Password           string `mapstructure:"password"`
+	Password           string `mapstructure:"password" redact:"true"`
 	AlignTimestamps    bool   `mapstructure:"align_timestamps"`
 	MetricSendInterval int    `mapstructure:"metric_send_interval"`
 }
@@ -603,6 +731,29 @@ func (cfg *PrometheusMetrics) validate(errs []error) []error {
 	return errs
 }
 
+// DashboardMetrics configures the bidder and account stats of the /dashboard endpoint of the admin server, kept
+// in memory over a rolling window.
+type DashboardMetrics struct {
+	Enabled       bool `mapstructure:"enabled"`
+	WindowSeconds int  `mapstructure:"window_seconds"`
+	// MaxAccounts is the number of accounts tracked in each part of the window, by each shard of bidders. The
+	// requests of the other accounts are added up.
+	MaxAccounts int `mapstructure:"max_accounts"`
+}
+
+func (cfg *DashboardMetrics) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.WindowSeconds < 30 {
+		errs = append(errs, fmt.Errorf("metrics.dashboard.window_seconds must be at least 30. Got %d", cfg.WindowSeconds))
+	}
+	if cfg.MaxAccounts < 0 {
+		errs = append(errs, fmt.Errorf("metrics.dashboard.max_accounts must be >= 0. Got %d", cfg.MaxAccounts))
+	}
+	return errs
+}
+
 func (m *PrometheusMetrics) Timeout() time.Duration {
 	return time.Duration(m.TimeoutMillisRaw) * time.Millisecond
 }
@@ -664,6 +815,20 @@ type Debug struct {
 	OverrideToken       string              `mapstructure:"override_token"`
 }
 
+// Health configures the /status/ready endpoint and the drain of the traffic on shutdown.
+type Health struct {
+	// DrainSeconds is how long /status/ready fails before the servers stop on shutdown, so the load balancers
+	// stop sending requests first.
+	DrainSeconds int `mapstructure:"drain_seconds"`
+}
+
+func (cfg *Health) validate(errs []error) []error {
+	if cfg.DrainSeconds < 0 {
+		errs = append(errs, fmt.Errorf("health.drain_seconds must be >= 0. Got %d", cfg.DrainSeconds))
+	}
+	return errs
+}
+
 type Server struct {
 	ExternalUrl string
 	GvlID       int
@@ -692,6 +857,10 @@ type Validations struct {
 	SecureMarkup          string `mapstructure:"secure_markup" json:"secure_markup"`
 	MaxCreativeWidth      int64  `mapstructure:"max_creative_width" json:"max_creative_width"`
 	MaxCreativeHeight     int64  `mapstructure:"max_creative_height" json:"max_creative_height"`
+	VASTMarkup            string `mapstructure:"vast_markup" json:"vast_markup"`
+	VASTMaxWrapperDepth   int    `mapstructure:"vast_max_wrapper_depth" json:"vast_max_wrapper_depth"`
+	// VASTWrapperTimeoutMS bounds each fetch of a wrapped VAST document, on top of the auction deadline.
+	VASTWrapperTimeoutMS int `mapstructure:"vast_wrapper_timeout_ms" json:"vast_wrapper_timeout_ms"`
 }
 
 const (
@@ -706,6 +875,34 @@ func (host *Validations) SetBannerCreativeMaxSize(account Validations) {
 	}
 }
 
+// VASTMarkupValidation returns the VAST markup validation mode, preferring the account setting over the host.
+func (host Validations) VASTMarkupValidation(account Validations) string {
+	if len(account.VASTMarkup) > 0 {
+		return account.VASTMarkup
+	}
+	return host.VASTMarkup
+}
+
+func (cfg *Validations) validate(errs []error) []error {
+	switch cfg.VASTMarkup {
+	case "", ValidationEnforce, ValidationWarn, ValidationSkip:
+	default:
+		errs = append(errs, fmt.Errorf("validations.vast_markup must be one of %s, %s or %s. Got %s", ValidationEnforce, ValidationWarn, ValidationSkip, cfg.VASTMarkup))
+	}
+	if cfg.VASTMaxWrapperDepth < 0 {
+		errs = append(errs, fmt.Errorf("validations.vast_max_wrapper_depth must be non-negative. Got %d", cfg.VASTMaxWrapperDepth))
+	}
+	if cfg.VASTMaxWrapperDepth > 0 && cfg.VASTWrapperTimeoutMS <= 0 {
+		errs = append(errs, fmt.Errorf("validations.vast_wrapper_timeout_ms must be positive when validations.vast_max_wrapper_depth is set. Got %d", cfg.VASTWrapperTimeoutMS))
+	}
+	return errs
+}
+
+// VASTWrapperTimeout returns the timeout of each fetch of a wrapped VAST document.
+func (cfg *Validations) VASTWrapperTimeout() time.Duration {
+	return time.Duration(cfg.VASTWrapperTimeoutMS) * time.Millisecond
+}
+
 func (cfg *TimeoutNotification) validate(errs []error) []error {
 	if cfg.SamplingRate < 0.0 || cfg.SamplingRate > 1.0 {
 		errs = append(errs, fmt.Errorf("debug.timeout_notification.sampling_rate must be positive and not greater than 1.0. Got %f", cfg.SamplingRate))
@@ -909,8 +1106,12 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("unix_socket_name", "prebid-server.sock") // path of the socket's file which must be listened.
 	v.SetDefault("admin_port", 6060)
 	v.SetDefault("admin.enabled", true) // boolean to determine if admin listener will be started.
+	v.SetDefault("admin.config_api.enabled", false)
+	v.SetDefault("admin.config_api.tokens", []string{})
+	v.SetDefault("admin.config_api.audit_log_path", "")
 	v.SetDefault("garbage_collector_threshold", 0)
 	v.SetDefault("status_response", "")
+	v.SetDefault("health.drain_seconds", 0)
 	v.SetDefault("datacenter", "")
 	v.SetDefault("auction_timeouts_ms.default", 0)
 	v.SetDefault("auction_timeouts_ms.max", 0)
@@ -938,6 +1139,9 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("host_schain_node", nil)
 	v.SetDefault("validations.banner_creative_max_size", ValidationSkip)
 	v.SetDefault("validations.secure_markup", ValidationSkip)
+	v.SetDefault("validations.vast_markup", ValidationSkip)
+	v.SetDefault("validations.vast_max_wrapper_depth", 0)
+	v.SetDefault("validations.vast_wrapper_timeout_ms", 1000)
 	v.SetDefault("validations.max_creative_size.height", 0)
 	v.SetDefault("validations.max_creative_size.width", 0)
 	v.SetDefault("http_client.max_connections_per_host", 0) // unlimited
@@ -955,6 +1159,7 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("metrics.disabled_metrics.adapter_connections_metrics", true)
 	v.SetDefault("metrics.disabled_metrics.adapter_buyeruid_scrubbed", true)
 	v.SetDefault("metrics.disabled_metrics.adapter_gdpr_request_blocked", false)
+	v.SetDefault("metrics.disabled_metrics.adapter_nonbids", true)
 	v.SetDefault("metrics.influxdb.host", "")
 	v.SetDefault("metrics.influxdb.database", "")
 	v.SetDefault("metrics.influxdb.measurement", "")
@@ -966,6 +1171,9 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("metrics.prometheus.namespace", "")
 	v.SetDefault("metrics.prometheus.subsystem", "")
 	v.SetDefault("metrics.prometheus.timeout_ms", 10000)
+	v.SetDefault("metrics.dashboard.enabled", false)
+	v.SetDefault("metrics.dashboard.window_seconds", 300)
+	v.SetDefault("metrics.dashboard.max_accounts", 100)
 	v.SetDefault("category_mapping.filesystem.enabled", true)
 	v.SetDefault("category_mapping.filesystem.directorypath", "./static/category-mapping")
 	v.SetDefault("category_mapping.http.endpoint", "")
@@ -991,6 +1199,7 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("stored_requests.database.poll_for_updates.amp_query", "")
 	v.SetDefault("stored_requests.filesystem.enabled", false)
 	v.SetDefault("stored_requests.filesystem.directorypath", "./stored_requests/data/by_id")
+	v.SetDefault("stored_requests.filesystem.watch", false)
 	v.SetDefault("stored_requests.directorypath", "./stored_requests/data/by_id")
 	v.SetDefault("stored_requests.http.endpoint", "")
 	v.SetDefault("stored_requests.http.amp_endpoint", "")
@@ -999,11 +1208,31 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("stored_requests.in_memory_cache.request_cache_size_bytes", 0)
 	v.SetDefault("stored_requests.in_memory_cache.imp_cache_size_bytes", 0)
 	v.SetDefault("stored_requests.in_memory_cache.resp_cache_size_bytes", 0)
+	v.SetDefault("stored_requests.in_memory_cache.negative_ttl_seconds", 0)
+	v.SetDefault("stored_requests.in_memory_cache.max_age_seconds", 0)
+	v.SetDefault("stored_requests.in_memory_cache.stale_while_revalidate_seconds", 0)
+	v.SetDefault("stored_requests.in_memory_cache.stale_if_error_seconds", 0)
 	v.SetDefault("stored_requests.cache_events_api", false)
 	v.SetDefault("stored_requests.http_events.endpoint", "")
 	v.SetDefault("stored_requests.http_events.amp_endpoint", "")
 	v.SetDefault("stored_requests.http_events.refresh_rate_seconds", 0)
 	v.SetDefault("stored_requests.http_events.timeout_ms", 0)
+	v.SetDefault("stored_requests.database.write.save_query", "")
+	v.SetDefault("stored_requests.database.write.delete_query", "")
+	v.SetDefault("stored_requests.database.write.amp_save_query", "")
+	v.SetDefault("stored_requests.database.write.amp_delete_query", "")
+	v.SetDefault("stored_requests.database.notifications.channel", "")
+	v.SetDefault("stored_requests.database.notifications.amp_channel", "")
+	v.SetDefault("stored_requests.database.notifications.min_reconnect_interval_ms", 1000)
+	v.SetDefault("stored_requests.database.notifications.max_reconnect_interval_ms", 60000)
+	v.SetDefault("stored_requests.database.notifications.change_log.refresh_rate_seconds", 1)
+	v.SetDefault("stored_requests.database.notifications.change_log.timeout_ms", 1000)
+	v.SetDefault("stored_requests.database.notifications.change_log.query", "")
+	v.SetDefault("stored_requests.database.notifications.change_log.last_sequence_query", "")
+	v.SetDefault("stored_requests.database.notifications.change_log.amp_query", "")
+	v.SetDefault("stored_requests.write_api.enabled", false)
+	v.SetDefault("stored_requests.write_api.endpoint", "/storeddata/openrtb2")
+	v.SetDefault("stored_requests.write_api.tokens", []string{})
 	// stored_video is short for stored_video_requests.
 	// PBS is not in the business of storing video content beyond the normal prebid cache system.
 	v.SetDefault("stored_video_req.database.connection.driver", "")
@@ -1027,17 +1256,38 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("stored_video_req.database.poll_for_updates.amp_query", "")
 	v.SetDefault("stored_video_req.filesystem.enabled", false)
 	v.SetDefault("stored_video_req.filesystem.directorypath", "")
+	v.SetDefault("stored_video_req.filesystem.watch", false)
 	v.SetDefault("stored_video_req.http.endpoint", "")
 	v.SetDefault("stored_video_req.in_memory_cache.type", "none")
 	v.SetDefault("stored_video_req.in_memory_cache.ttl_seconds", 0)
 	v.SetDefault("stored_video_req.in_memory_cache.request_cache_size_bytes", 0)
 	v.SetDefault("stored_video_req.in_memory_cache.imp_cache_size_bytes", 0)
 	v.SetDefault("stored_video_req.in_memory_cache.resp_cache_size_bytes", 0)
+	v.SetDefault("stored_video_req.in_memory_cache.negative_ttl_seconds", 0)
+	v.SetDefault("stored_video_req.in_memory_cache.max_age_seconds", 0)
+	v.SetDefault("stored_video_req.in_memory_cache.stale_while_revalidate_seconds", 0)
+	v.SetDefault("stored_video_req.in_memory_cache.stale_if_error_seconds", 0)
 	v.SetDefault("stored_video_req.cache_events.enabled", false)
 	v.SetDefault("stored_video_req.cache_events.endpoint", "")
 	v.SetDefault("stored_video_req.http_events.endpoint", "")
 	v.SetDefault("stored_video_req.http_events.refresh_rate_seconds", 0)
 	v.SetDefault("stored_video_req.http_events.timeout_ms", 0)
+	v.SetDefault("stored_video_req.database.write.save_query", "")
+	v.SetDefault("stored_video_req.database.write.delete_query", "")
+	v.SetDefault("stored_video_req.database.write.amp_save_query", "")
+	v.SetDefault("stored_video_req.database.write.amp_delete_query", "")
+	v.SetDefault("stored_video_req.database.notifications.channel", "")
+	v.SetDefault("stored_video_req.database.notifications.amp_channel", "")
+	v.SetDefault("stored_video_req.database.notifications.min_reconnect_interval_ms", 1000)
+	v.SetDefault("stored_video_req.database.notifications.max_reconnect_interval_ms", 60000)
+	v.SetDefault("stored_video_req.database.notifications.change_log.refresh_rate_seconds", 1)
+	v.SetDefault("stored_video_req.database.notifications.change_log.timeout_ms", 1000)
+	v.SetDefault("stored_video_req.database.notifications.change_log.query", "")
+	v.SetDefault("stored_video_req.database.notifications.change_log.last_sequence_query", "")
+	v.SetDefault("stored_video_req.database.notifications.change_log.amp_query", "")
+	v.SetDefault("stored_video_req.write_api.enabled", false)
+	v.SetDefault("stored_video_req.write_api.endpoint", "/storeddata/video")
+	v.SetDefault("stored_video_req.write_api.tokens", []string{})
 	v.SetDefault("stored_responses.database.connection.driver", "")
 	v.SetDefault("stored_responses.database.connection.dbname", "")
 	v.SetDefault("stored_responses.database.connection.host", "")
@@ -1059,29 +1309,62 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("stored_responses.database.poll_for_updates.amp_query", "")
 	v.SetDefault("stored_responses.filesystem.enabled", false)
 	v.SetDefault("stored_responses.filesystem.directorypath", "")
+	v.SetDefault("stored_responses.filesystem.watch", false)
 	v.SetDefault("stored_responses.http.endpoint", "")
 	v.SetDefault("stored_responses.in_memory_cache.type", "none")
 	v.SetDefault("stored_responses.in_memory_cache.ttl_seconds", 0)
 	v.SetDefault("stored_responses.in_memory_cache.request_cache_size_bytes", 0)
 	v.SetDefault("stored_responses.in_memory_cache.imp_cache_size_bytes", 0)
 	v.SetDefault("stored_responses.in_memory_cache.resp_cache_size_bytes", 0)
+	v.SetDefault("stored_responses.in_memory_cache.negative_ttl_seconds", 0)
+	v.SetDefault("stored_responses.in_memory_cache.max_age_seconds", 0)
+	v.SetDefault("stored_responses.in_memory_cache.stale_while_revalidate_seconds", 0)
+	v.SetDefault("stored_responses.in_memory_cache.stale_if_error_seconds", 0)
 	v.SetDefault("stored_responses.cache_events.enabled", false)
 	v.SetDefault("stored_responses.cache_events.endpoint", "")
 	v.SetDefault("stored_responses.http_events.endpoint", "")
 	v.SetDefault("stored_responses.http_events.refresh_rate_seconds", 0)
 	v.SetDefault("stored_responses.http_events.timeout_ms", 0)
+	v.SetDefault("stored_responses.database.write.save_query", "")
+	v.SetDefault("stored_responses.database.write.delete_query", "")
+	v.SetDefault("stored_responses.database.write.amp_save_query", "")
+	v.SetDefault("stored_responses.database.write.amp_delete_query", "")
+	v.SetDefault("stored_responses.database.notifications.channel", "")
+	v.SetDefault("stored_responses.database.notifications.amp_channel", "")
+	v.SetDefault("stored_responses.database.notifications.min_reconnect_interval_ms", 1000)
+	v.SetDefault("stored_responses.database.notifications.max_reconnect_interval_ms", 60000)
+	v.SetDefault("stored_responses.database.notifications.change_log.refresh_rate_seconds", 1)
+	v.SetDefault("stored_responses.database.notifications.change_log.timeout_ms", 1000)
+	v.SetDefault("stored_responses.database.notifications.change_log.query", "")
+	v.SetDefault("stored_responses.database.notifications.change_log.last_sequence_query", "")
+	v.SetDefault("stored_responses.database.notifications.change_log.amp_query", "")
+	v.SetDefault("stored_responses.write_api.enabled", false)
+	v.SetDefault("stored_responses.write_api.endpoint", "/storeddata/responses")
+	v.SetDefault("stored_responses.write_api.tokens", []string{})
 
 	v.SetDefault("vtrack.timeout_ms", 2000)
 	v.SetDefault("vtrack.allow_unknown_bidder", true)
 	v.SetDefault("vtrack.enabled", true)
 
 	v.SetDefault("event.timeout_ms", 1000)
+	v.SetDefault("event.signing.enabled", false)
+	v.SetDefault("event.signing.encrypt", false)
+	v.SetDefault("event.signing.unsigned_events", EventSigningFlag)
+	v.SetDefault("event.signing.max_age_seconds", 86400)
 
 	v.SetDefault("user_sync.priority_groups", [][]string{})
 
 	v.SetDefault("accounts.filesystem.enabled", false)
 	v.SetDefault("accounts.filesystem.directorypath", "./stored_requests/data/by_id")
+	v.SetDefault("accounts.filesystem.watch", false)
 	v.SetDefault("accounts.in_memory_cache.type", "none")
+	v.SetDefault("accounts.in_memory_cache.negative_ttl_seconds", 0)
+	v.SetDefault("accounts.in_memory_cache.max_age_seconds", 0)
+	v.SetDefault("accounts.in_memory_cache.stale_while_revalidate_seconds", 0)
+	v.SetDefault("accounts.in_memory_cache.stale_if_error_seconds", 0)
+	v.SetDefault("accounts.write_api.enabled", false)
+	v.SetDefault("accounts.write_api.endpoint", "/storeddata/accounts")
+	v.SetDefault("accounts.write_api.tokens", []string{})
 
 	v.BindEnv("user_sync.external_url")
 	v.BindEnv("user_sync.coop_sync.default")
@@ -1172,6 +1455,30 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("account_defaults.privacy.privacysandbox.cookiedeprecation.ttl_sec", 604800)
 
 	v.SetDefault("account_defaults.events_enabled", false)
+	v.SetDefault("account_defaults.rate_limits.enabled", false)
+	v.SetDefault("account_defaults.rate_limits.scope", RateLimitScopeAccount)
+	v.SetDefault("account_defaults.rate_limits.max_properties", RateLimitDefaultMaxProperties)
+	v.SetDefault("account_defaults.rate_limits.action", RateLimitActionReject)
+	v.SetDefault("rate_limiting.enabled", false)
+	v.SetDefault("rate_limiting.backend", "local")
+	v.SetDefault("rate_limiting.redis.mode", RateLimitRedisStandalone)
+	v.SetDefault("rate_limiting.redis.addresses", []string{})
+	v.SetDefault("rate_limiting.redis.master_name", "")
+	v.SetDefault("rate_limiting.redis.username", "")
+	v.SetDefault("rate_limiting.redis.password", "")
+	v.SetDefault("rate_limiting.redis.db", 0)
+	v.SetDefault("rate_limiting.redis.tls", false)
+	v.SetDefault("rate_limiting.redis.timeout_ms", 20)
+	v.SetDefault("rate_limiting.redis.pool_size", 16)
+	v.SetDefault("rate_limiting.redis.key_prefix", "pbs:ratelimit:")
+	v.SetDefault("account_defaults.mirroring.sample_rate", 0)
+	v.SetDefault("mirroring.enabled", false)
+	v.SetDefault("mirroring.target", MirroringTargetHTTP)
+	v.SetDefault("mirroring.http.endpoint", "")
+	v.SetDefault("mirroring.http.timeout_ms", 1000)
+	v.SetDefault("mirroring.http.workers", 4)
+	v.SetDefault("mirroring.file.path", "")
+	v.SetDefault("mirroring.queue_size", 1000)
 	v.BindEnv("account_defaults.privacy.dsa.default")
 	v.BindEnv("account_defaults.privacy.dsa.gdpr_only")
 	v.SetDefault("account_defaults.privacy.ipv6.anon_keep_bits", 56)
@@ -1273,6 +1580,7 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("experiment.adscert.remote.signing_timeout_ms", 5)
 
 	v.SetDefault("hooks.enabled", false)
+	v.SetDefault("hooks.module_cache.size_bytes", 10*1024*1024)
 
 	for bidderName := range bidderInfos {
 		setBidderDefaults(v, strings.ToLower(bidderName))
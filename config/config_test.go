@@ -146,6 +146,16 @@ func TestDefaults(t *testing.T) {
 
 	cmpInts(t, "port", 8000, cfg.Port)
 	cmpInts(t, "admin_port", 6060, cfg.AdminPort)
+	cmpBools(t, "admin.config_api.enabled", false, cfg.Admin.ConfigAPI.Enabled)
+	cmpBools(t, "metrics.dashboard.enabled", false, cfg.Metrics.Dashboard.Enabled)
+	cmpInts(t, "metrics.dashboard.window_seconds", 300, cfg.Metrics.Dashboard.WindowSeconds)
+	cmpInts(t, "metrics.dashboard.max_accounts", 100, cfg.Metrics.Dashboard.MaxAccounts)
+	cmpBools(t, "mirroring.enabled", false, cfg.Mirroring.Enabled)
+	cmpStrings(t, "mirroring.target", "http", cfg.Mirroring.Target)
+	cmpInts(t, "mirroring.http.timeout_ms", 1000, cfg.Mirroring.HTTP.Timeout)
+	cmpInts(t, "mirroring.http.workers", 4, cfg.Mirroring.HTTP.Workers)
+	cmpInts(t, "mirroring.queue_size", 1000, cfg.Mirroring.QueueSize)
+	cmpInts(t, "health.drain_seconds", 0, cfg.Health.DrainSeconds)
 	cmpInts(t, "auction_timeouts_ms.max", 0, int(cfg.AuctionTimeouts.Max))
 	cmpInts(t, "max_request_size", 1024*256, int(cfg.MaxRequestSize))
 	cmpInts(t, "host_cookie.ttl_days", 90, int(cfg.HostCookie.TTL))
@@ -160,6 +170,7 @@ func TestDefaults(t *testing.T) {
 	cmpBools(t, "adapter_connections_metrics", true, cfg.Metrics.Disabled.AdapterConnectionMetrics)
 	cmpBools(t, "adapter_buyeruid_scrubbed", true, cfg.Metrics.Disabled.AdapterBuyerUIDScrubbed)
 	cmpBools(t, "adapter_gdpr_request_blocked", false, cfg.Metrics.Disabled.AdapterGDPRRequestBlocked)
+	cmpBools(t, "adapter_nonbids", true, cfg.Metrics.Disabled.AdapterNonBids)
 	cmpStrings(t, "certificates_file", "", cfg.PemCertsFile)
 	cmpInts(t, "stored_requests_timeout_ms", 50, cfg.StoredRequestsTimeout)
 	cmpBools(t, "stored_requests.filesystem.enabled", false, cfg.StoredRequests.Files.Enabled)
@@ -213,10 +224,14 @@ func TestDefaults(t *testing.T) {
 	cmpBools(t, "account_defaults.events.enabled", false, cfg.AccountDefaults.Events.Enabled)
 
 	cmpBools(t, "hooks.enabled", false, cfg.Hooks.Enabled)
+	cmpInts(t, "hooks.module_cache.size_bytes", 10*1024*1024, cfg.Hooks.ModuleCache.SizeBytes)
 	cmpStrings(t, "validations.banner_creative_max_size", "skip", cfg.Validations.BannerCreativeMaxSize)
 	cmpStrings(t, "validations.secure_markup", "skip", cfg.Validations.SecureMarkup)
 	cmpInts(t, "validations.max_creative_width", 0, int(cfg.Validations.MaxCreativeWidth))
 	cmpInts(t, "validations.max_creative_height", 0, int(cfg.Validations.MaxCreativeHeight))
+	cmpStrings(t, "validations.vast_markup", "skip", cfg.Validations.VASTMarkup)
+	cmpInts(t, "validations.vast_max_wrapper_depth", 0, cfg.Validations.VASTMaxWrapperDepth)
+	cmpInts(t, "validations.vast_wrapper_timeout_ms", 1000, cfg.Validations.VASTWrapperTimeoutMS)
 	cmpBools(t, "account_modules_metrics", false, cfg.Metrics.Disabled.AccountModulesMetrics)
 
 	cmpBools(t, "tmax_adjustments.enabled", false, cfg.TmaxAdjustments.Enabled)
@@ -450,6 +465,7 @@ metrics:
     adapter_connections_metrics: true
     adapter_buyeruid_scrubbed: false
     adapter_gdpr_request_blocked: true
+    adapter_nonbids: false
     account_modules_metrics: true
 blocked_apps: ["spamAppID","sketchy-app-id"]
 account_required: true
@@ -469,6 +485,9 @@ validations:
     secure_markup: "skip"
     max_creative_width: 0
     max_creative_height: 0
+    vast_markup: "enforce"
+    vast_max_wrapper_depth: 3
+    vast_wrapper_timeout_ms: 500
 experiment:
     adscert:
         mode: inprocess
@@ -482,6 +501,8 @@ experiment:
             signing_timeout_ms: 10
 hooks:
     enabled: true
+    module_cache:
+        size_bytes: 1048576
 price_floors:
     enabled: true
     fetcher:
@@ -640,6 +661,9 @@ func TestFullConfig(t *testing.T) {
 	cmpStrings(t, "validations.secure_markup", "skip", cfg.Validations.SecureMarkup)
 	cmpInts(t, "validations.max_creative_width", 0, int(cfg.Validations.MaxCreativeWidth))
 	cmpInts(t, "validations.max_creative_height", 0, int(cfg.Validations.MaxCreativeHeight))
+	cmpStrings(t, "validations.vast_markup", "enforce", cfg.Validations.VASTMarkup)
+	cmpInts(t, "validations.vast_max_wrapper_depth", 3, cfg.Validations.VASTMaxWrapperDepth)
+	cmpInts(t, "validations.vast_wrapper_timeout_ms", 500, cfg.Validations.VASTWrapperTimeoutMS)
 	cmpBools(t, "tmax_adjustments.enabled", true, cfg.TmaxAdjustments.Enabled)
 	cmpUnsignedInts(t, "tmax_adjustments.bidder_response_duration_min_ms", 700, cfg.TmaxAdjustments.BidderResponseDurationMin)
 	cmpUnsignedInts(t, "tmax_adjustments.bidder_network_latency_buffer_ms", 100, cfg.TmaxAdjustments.BidderNetworkLatencyBuffer)
@@ -865,6 +889,7 @@ func TestFullConfig(t *testing.T) {
 	cmpBools(t, "adapter_connections_metrics", true, cfg.Metrics.Disabled.AdapterConnectionMetrics)
 	cmpBools(t, "adapter_buyeruid_scrubbed", false, cfg.Metrics.Disabled.AdapterBuyerUIDScrubbed)
 	cmpBools(t, "adapter_gdpr_request_blocked", true, cfg.Metrics.Disabled.AdapterGDPRRequestBlocked)
+	cmpBools(t, "adapter_nonbids", false, cfg.Metrics.Disabled.AdapterNonBids)
 	cmpStrings(t, "certificates_file", "/etc/ssl/cert.pem", cfg.PemCertsFile)
 	cmpStrings(t, "request_validation.ipv4_private_networks", "1.1.1.0/24", cfg.RequestValidation.IPv4PrivateNetworks[0])
 	cmpStrings(t, "request_validation.ipv6_private_networks", "1111::/16", cfg.RequestValidation.IPv6PrivateNetworks[0])
@@ -879,6 +904,7 @@ func TestFullConfig(t *testing.T) {
 	cmpStrings(t, "experiment.adscert.remote.url", "", cfg.Experiment.AdCerts.Remote.Url)
 	cmpInts(t, "experiment.adscert.remote.signing_timeout_ms", 10, cfg.Experiment.AdCerts.Remote.SigningTimeoutMs)
 	cmpBools(t, "hooks.enabled", true, cfg.Hooks.Enabled)
+	cmpInts(t, "hooks.module_cache.size_bytes", 1048576, cfg.Hooks.ModuleCache.SizeBytes)
 	cmpBools(t, "account_modules_metrics", true, cfg.Metrics.Disabled.AccountModulesMetrics)
 	cmpBools(t, "analytics.agma.enabled", true, cfg.Analytics.Agma.Enabled)
 	cmpStrings(t, "analytics.agma.endpoint.timeout", "5s", cfg.Analytics.Agma.Endpoint.Timeout)
@@ -1193,6 +1219,21 @@ func TestNegativeRequestSize(t *testing.T) {
 	assertOneError(t, cfg.validate(v), "cfg.max_request_size must be >= 0. Got -1")
 }
 
+func TestNegativeHealthDrain(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.Health.DrainSeconds = -1
+	assertOneError(t, cfg.validate(v), "health.drain_seconds must be >= 0. Got -1")
+}
+
+func TestDashboardWithoutAdminTokens(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.Metrics.Dashboard.Enabled = true
+	assertOneError(t, cfg.validate(v), "admin.config_api.tokens must contain at least one token to serve metrics.dashboard")
+
+	cfg.Admin.ConfigAPI.Tokens = []string{"token"}
+	assert.Empty(t, cfg.validate(v))
+}
+
 func TestNegativePrometheusTimeout(t *testing.T) {
 	cfg, v := newDefaultConfig(t)
 	cfg.Metrics.Prometheus.Port = 8001
@@ -1376,6 +1417,179 @@ func TestValidateDebug(t *testing.T) {
 	assert.NotNil(t, err, "cfg.debug.timeout_notification.sampling_rate should not be allowed to be greater than 1.0, but it was allowed")
 }
 
+func TestValidateEventSigning(t *testing.T) {
+	validKey := EventSigningKey{ID: "k1", Secret: "0123456789abcdef", EncryptionKey: "MDEyMzQ1Njc4OWFiY2RlZg=="}
+
+	testCases := []struct {
+		name        string
+		signing     EventSigning
+		expectedErr []error
+	}{
+		{
+			name:    "disabled",
+			signing: EventSigning{UnsignedEvents: "invalid"},
+		},
+		{
+			name:    "valid",
+			signing: EventSigning{Enabled: true, Encrypt: true, UnsignedEvents: EventSigningReject, Keys: []EventSigningKey{validKey}, MaxAgeSeconds: 3600},
+		},
+		{
+			name:    "no-keys",
+			signing: EventSigning{Enabled: true, UnsignedEvents: EventSigningFlag, MaxAgeSeconds: 3600},
+			expectedErr: []error{
+				errors.New("event.signing.keys must contain at least one key when event signing is enabled"),
+			},
+		},
+		{
+			name: "invalid-keys",
+			signing: EventSigning{
+				Enabled:        true,
+				Encrypt:        true,
+				UnsignedEvents: "drop",
+				MaxAgeSeconds:  3600,
+				Keys: []EventSigningKey{
+					validKey,
+					{ID: "k1", Secret: "short", EncryptionKey: "c2hvcnQ="},
+					{ID: "k.2", Secret: "0123456789abcdef", EncryptionKey: validKey.EncryptionKey},
+				},
+			},
+			expectedErr: []error{
+				errors.New("event.signing.unsigned_events must be reject or flag. Got drop"),
+				errors.New("event.signing.keys[1].id k1 is not unique"),
+				errors.New("event.signing.keys[1].secret must be at least 16 characters long"),
+				errors.New("event.signing.keys[1].encryption_key is invalid: key must be 16, 24 or 32 bytes long, got 5"),
+				errors.New("event.signing.keys[2].id is required and may not contain a dot"),
+			},
+		},
+		{
+			name:    "no-max-age",
+			signing: EventSigning{Enabled: true, UnsignedEvents: EventSigningFlag, Keys: []EventSigningKey{validKey}},
+			expectedErr: []error{
+				errors.New("event.signing.max_age_seconds must be positive. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			errs := test.signing.validate(nil)
+			assert.Equal(t, test.expectedErr, errs)
+		})
+	}
+}
+
+func TestValidateAdminConfigAPI(t *testing.T) {
+	testCases := []struct {
+		name        string
+		configAPI   AdminConfigAPI
+		expectedErr []error
+	}{
+		{
+			name:      "disabled",
+			configAPI: AdminConfigAPI{},
+		},
+		{
+			name:      "valid",
+			configAPI: AdminConfigAPI{Enabled: true, Tokens: []string{"t1"}, AuditLogPath: "/var/log/pbs-audit.log"},
+		},
+		{
+			name:        "no-tokens",
+			configAPI:   AdminConfigAPI{Enabled: true},
+			expectedErr: []error{errors.New("admin.config_api.tokens must contain at least one token")},
+		},
+		{
+			name:        "empty-token",
+			configAPI:   AdminConfigAPI{Enabled: true, Tokens: []string{"t1", ""}},
+			expectedErr: []error{errors.New("admin.config_api.tokens[1] must not be empty")},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			errs := test.configAPI.validate(nil)
+			assert.Equal(t, test.expectedErr, errs)
+		})
+	}
+}
+
+func TestValidateDashboardMetrics(t *testing.T) {
+	testCases := []struct {
+		name        string
+		dashboard   DashboardMetrics
+		expectedErr []error
+	}{
+		{
+			name:      "disabled",
+			dashboard: DashboardMetrics{WindowSeconds: 1},
+		},
+		{
+			name:      "valid",
+			dashboard: DashboardMetrics{Enabled: true, WindowSeconds: 300, MaxAccounts: 100},
+		},
+		{
+			name:      "invalid",
+			dashboard: DashboardMetrics{Enabled: true, WindowSeconds: 10, MaxAccounts: -1},
+			expectedErr: []error{
+				errors.New("metrics.dashboard.window_seconds must be at least 30. Got 10"),
+				errors.New("metrics.dashboard.max_accounts must be >= 0. Got -1"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			errs := test.dashboard.validate(nil)
+			assert.Equal(t, test.expectedErr, errs)
+		})
+	}
+}
+
+func TestValidateValidations(t *testing.T) {
+	testCases := []struct {
+		name        string
+		validations Validations
+		expectedErr []error
+	}{
+		{
+			name:        "defaults",
+			validations: Validations{VASTMarkup: ValidationSkip},
+		},
+		{
+			name:        "enforce-with-wrapper-depth",
+			validations: Validations{VASTMarkup: ValidationEnforce, VASTMaxWrapperDepth: 5, VASTWrapperTimeoutMS: 1000},
+		},
+		{
+			name:        "invalid-mode",
+			validations: Validations{VASTMarkup: "reject"},
+			expectedErr: []error{errors.New("validations.vast_markup must be one of enforce, warn or skip. Got reject")},
+		},
+		{
+			name:        "negative-wrapper-depth",
+			validations: Validations{VASTMarkup: ValidationWarn, VASTMaxWrapperDepth: -1},
+			expectedErr: []error{errors.New("validations.vast_max_wrapper_depth must be non-negative. Got -1")},
+		},
+		{
+			name:        "wrapper-depth-without-timeout",
+			validations: Validations{VASTMarkup: ValidationEnforce, VASTMaxWrapperDepth: 5},
+			expectedErr: []error{errors.New("validations.vast_wrapper_timeout_ms must be positive when validations.vast_max_wrapper_depth is set. Got 0")},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			errs := test.validations.validate(nil)
+			assert.Equal(t, test.expectedErr, errs)
+		})
+	}
+}
+
+func TestVASTMarkupValidation(t *testing.T) {
+	host := Validations{VASTMarkup: ValidationWarn}
+
+	assert.Equal(t, ValidationWarn, host.VASTMarkupValidation(Validations{}))
+	assert.Equal(t, ValidationEnforce, host.VASTMarkupValidation(Validations{VASTMarkup: ValidationEnforce}))
+}
+
 func TestValidateAccountsConfigRestrictions(t *testing.T) {
 	cfg, v := newDefaultConfig(t)
 	cfg.Accounts.Files.Enabled = true
@@ -40,7 +40,7 @@ type AdsCertInProcess struct {
 	// Origin is ads.cert hostname for the originating party
 	Origin string `mapstructure:"origin"`
 	// PrivateKey is a base-64 encoded private key.
-	PrivateKey string `mapstructure:"key"`
+	PrivateKey string `mapstructure:"key" redact:"true"`
 	// DNSCheckIntervalInSeconds specifies frequency to check origin _delivery._adscert and _adscert subdomains, used for indexing data, default: 30
 	DNSCheckIntervalInSeconds int `mapstructure:"domain_check_interval_seconds"`
 	// DNSRenewalIntervalInSeconds specifies frequency to renew origin _delivery._adscert and _adscert subdomains, used for indexing data, default: 30
@@ -1,5 +1,7 @@
 package config
 
+import "fmt"
+
 type Hooks struct {
 	Enabled bool    `mapstructure:"enabled"`
 	Modules Modules `mapstructure:"modules"`
@@ -7,12 +9,21 @@ type Hooks struct {
 	HostExecutionPlan HookExecutionPlan `mapstructure:"host_execution_plan"`
 	// DefaultAccountExecutionPlan can be replaced by the account-specific hook execution plan
 	DefaultAccountExecutionPlan HookExecutionPlan `mapstructure:"default_account_execution_plan"`
+	// ModuleCache configures the in-memory cache shared by modules
+	ModuleCache ModuleCache `mapstructure:"module_cache"`
+}
+
+type ModuleCache struct {
+	// SizeBytes is the size of the cache, a non-positive value disables it
+	SizeBytes int `mapstructure:"size_bytes"`
 }
 
 // Modules mapping provides module specific configuration, format: map[vendor_name]map[module_name]interface{}
 // actual configuration parsing performed by modules
 type Modules map[string]map[string]interface{}
 
+// HookExecutionPlan maps endpoints, e.g. "/openrtb2/auction", "/cookie_sync", "/setuid" or "/event",
+// to the groups of hooks executed at each of their stages.
 type HookExecutionPlan struct {
 	Endpoints map[string]struct {
 		Stages map[string]struct {
@@ -30,5 +41,74 @@ type HookExecutionGroup struct {
 		ModuleCode string `mapstructure:"module_code" json:"module_code"`
 		// HookImplCode is an arbitrary value, used to identify hook when sending metrics, debug information, etc.
 		HookImplCode string `mapstructure:"hook_impl_code" json:"hook_impl_code"`
+		// Conditions restrict the execution of the hook to the matching requests, the hook runs on every request if not set.
+		Conditions *HookConditions `mapstructure:"conditions" json:"conditions,omitempty"`
 	} `mapstructure:"hook_sequence" json:"hook_sequence"`
 }
+
+// HookConditions restrict the execution of a hook to the requests matching all of them.
+// An empty list matches every request. A condition on a value unknown at the stage,
+// like the country at the entrypoint stage or the bidder outside the bidder stages, doesn't match.
+type HookConditions struct {
+	// Channels matches the channel of the request: "amp", "app", "video", "web" or "dooh".
+	Channels []ChannelType `mapstructure:"channels" json:"channels,omitempty"`
+	// MediaTypes matches requests with at least one impression of one of the media types: "banner", "video", "audio" or "native".
+	MediaTypes []string `mapstructure:"media_types" json:"media_types,omitempty"`
+	// Countries matches device.geo.country, as ISO-3166-1 alpha-3 codes.
+	Countries []string `mapstructure:"countries" json:"countries,omitempty"`
+	// Bidders matches the bidder of the bidder_request and raw_bidder_response stages.
+	Bidders []string `mapstructure:"bidders" json:"bidders,omitempty"`
+	// Accounts matches the account ID of the request.
+	Accounts []string `mapstructure:"accounts" json:"accounts,omitempty"`
+	// SamplingPercentage runs the hook on the given percentage of requests, all of them if not set.
+	// A request is sampled once per module, so the hooks of a module with the same percentage run on the same requests.
+	SamplingPercentage *float64 `mapstructure:"sampling_percentage" json:"sampling_percentage,omitempty"`
+}
+
+var hookConditionChannels = map[ChannelType]struct{}{ChannelAMP: {}, ChannelApp: {}, ChannelVideo: {}, ChannelWeb: {}, ChannelDOOH: {}}
+var hookConditionMediaTypes = map[string]struct{}{"banner": {}, "video": {}, "audio": {}, "native": {}}
+
+func (h *Hooks) validate(errs []error) []error {
+	errs = h.HostExecutionPlan.validate("hooks.host_execution_plan", errs)
+	errs = h.DefaultAccountExecutionPlan.validate("hooks.default_account_execution_plan", errs)
+	return errs
+}
+
+// Validate checks the hook conditions of the account execution plan.
+func (h *AccountHooks) Validate(errs []error) []error {
+	return h.ExecutionPlan.validate("hooks.execution_plan", errs)
+}
+
+func (p HookExecutionPlan) validate(path string, errs []error) []error {
+	for endpoint, endpointCfg := range p.Endpoints {
+		for stage, stageCfg := range endpointCfg.Stages {
+			for i, group := range stageCfg.Groups {
+				for j, hook := range group.HookSequence {
+					if hook.Conditions == nil {
+						continue
+					}
+					hookPath := fmt.Sprintf("%s.endpoints.%s.stages.%s.groups[%d].hook_sequence[%d].conditions", path, endpoint, stage, i, j)
+					errs = hook.Conditions.validate(hookPath, errs)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func (c *HookConditions) validate(path string, errs []error) []error {
+	for _, channel := range c.Channels {
+		if _, ok := hookConditionChannels[channel]; !ok {
+			errs = append(errs, fmt.Errorf("%s.channels contains invalid channel %q", path, channel))
+		}
+	}
+	for _, mediaType := range c.MediaTypes {
+		if _, ok := hookConditionMediaTypes[mediaType]; !ok {
+			errs = append(errs, fmt.Errorf("%s.media_types contains invalid media type %q", path, mediaType))
+		}
+	}
+	if c.SamplingPercentage != nil && (*c.SamplingPercentage < 0 || *c.SamplingPercentage > 100) {
+		errs = append(errs, fmt.Errorf("%s.sampling_percentage must be between 0 and 100. Got %g", path, *c.SamplingPercentage))
+	}
+	return errs
+}
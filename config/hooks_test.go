@@ -0,0 +1,90 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookConditionsValidate(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenCondition HookConditions
+		expectedErrs   []string
+	}{
+		{
+			description:    "empty",
+			givenCondition: HookConditions{},
+		},
+		{
+			description: "valid",
+			givenCondition: HookConditions{
+				Channels:           []ChannelType{ChannelApp, ChannelWeb},
+				MediaTypes:         []string{"banner", "native"},
+				Countries:          []string{"USA"},
+				Bidders:            []string{"appnexus"},
+				Accounts:           []string{"1001"},
+				SamplingPercentage: ptrutil.ToPtr(12.5),
+			},
+		},
+		{
+			description: "invalid",
+			givenCondition: HookConditions{
+				Channels:           []ChannelType{"tv"},
+				MediaTypes:         []string{"display"},
+				SamplingPercentage: ptrutil.ToPtr(120.0),
+			},
+			expectedErrs: []string{
+				`conditions.channels contains invalid channel "tv"`,
+				`conditions.media_types contains invalid media type "display"`,
+				`conditions.sampling_percentage must be between 0 and 100. Got 120`,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			errs := test.givenCondition.validate("conditions", nil)
+
+			errMessages := make([]string, 0, len(errs))
+			for _, err := range errs {
+				errMessages = append(errMessages, err.Error())
+			}
+			assert.ElementsMatch(t, test.expectedErrs, errMessages)
+		})
+	}
+}
+
+func TestHooksValidate(t *testing.T) {
+	hooks := Hooks{}
+	v := viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+hooks:
+  host_execution_plan:
+    endpoints:
+      /openrtb2/auction:
+        stages:
+          processed_auction_request:
+            groups:
+              - timeout: 5
+                hook_sequence:
+                  - module_code: acme.foo
+                    hook_impl_code: foo
+                  - module_code: acme.bar
+                    hook_impl_code: bar
+                    conditions:
+                      channels: [app]
+                      sampling_percentage: 101
+`)))
+	require.NoError(t, v.UnmarshalKey("hooks", &hooks))
+	require.NotNil(t, hooks.HostExecutionPlan.Endpoints["/openrtb2/auction"].Stages["processed_auction_request"].Groups[0].HookSequence[1].Conditions)
+
+	errs := hooks.validate(nil)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "hooks.host_execution_plan.endpoints./openrtb2/auction.stages.processed_auction_request.groups[0].hook_sequence[1].conditions.sampling_percentage must be between 0 and 100. Got 101")
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// The endpoints whose requests can be mirrored
+const (
+	MirroringEndpointAuction = "auction"
+	MirroringEndpointAMP     = "amp"
+	MirroringEndpointVideo   = "video"
+)
+
+var mirroringEndpoints = []string{MirroringEndpointAuction, MirroringEndpointAMP, MirroringEndpointVideo}
+
+// The targets the mirrored requests are copied to
+const (
+	// MirroringTargetHTTP posts the mirrored requests to a shadow PBS
+	MirroringTargetHTTP = "http"
+	// MirroringTargetFile appends the mirrored requests to a NDJSON file
+	MirroringTargetFile = "file"
+)
+
+// Mirroring configures the host side of the request mirroring. The share of the requests mirrored is set
+// in the account config, with the host default in account_defaults.mirroring.
+type Mirroring struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Target is "http" to post the requests to a shadow PBS, or "file" to append them to a NDJSON file.
+	Target string        `mapstructure:"target"`
+	HTTP   MirroringHTTP `mapstructure:"http"`
+	File   MirroringFile `mapstructure:"file"`
+	// QueueSize is the number of requests waiting to be copied to the target. The requests which don't
+	// fit in the queue are dropped.
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+// MirroringHTTP configures the shadow PBS of the "http" mirroring target.
+type MirroringHTTP struct {
+	// Endpoint is the url of the auction endpoint of the shadow PBS, e.g. https://shadow/openrtb2/auction.
+	// The amp and video requests are posted to it too, as they are resolved to OpenRTB requests.
+	Endpoint string `mapstructure:"endpoint"`
+	// Timeout is the number of milliseconds to wait for the shadow PBS.
+	Timeout int `mapstructure:"timeout_ms"`
+	// Workers is the number of requests posted at the same time.
+	Workers int `mapstructure:"workers"`
+}
+
+// MirroringFile configures the file of the "file" mirroring target.
+type MirroringFile struct {
+	Path string `mapstructure:"path"`
+}
+
+func (cfg *Mirroring) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	switch cfg.Target {
+	case MirroringTargetHTTP:
+		if endpoint, err := url.Parse(cfg.HTTP.Endpoint); err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+			errs = append(errs, fmt.Errorf("mirroring.http.endpoint must be an absolute url when mirroring.target=http. Got %s", cfg.HTTP.Endpoint))
+		}
+		if cfg.HTTP.Timeout <= 0 {
+			errs = append(errs, fmt.Errorf("mirroring.http.timeout_ms must be > 0. Got %d", cfg.HTTP.Timeout))
+		}
+		if cfg.HTTP.Workers <= 0 {
+			errs = append(errs, fmt.Errorf("mirroring.http.workers must be > 0. Got %d", cfg.HTTP.Workers))
+		}
+	case MirroringTargetFile:
+		if cfg.File.Path == "" {
+			errs = append(errs, fmt.Errorf("mirroring.file.path must be set when mirroring.target=file"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("mirroring.target must be %s or %s. Got %s", MirroringTargetHTTP, MirroringTargetFile, cfg.Target))
+	}
+	if cfg.QueueSize <= 0 {
+		errs = append(errs, fmt.Errorf("mirroring.queue_size must be > 0. Got %d", cfg.QueueSize))
+	}
+	return errs
+}
+
+// AccountMirroring sets the share of the requests of an account which are mirrored.
+type AccountMirroring struct {
+	// SampleRate is the share of the requests mirrored, between 0 and 1.
+	SampleRate float64 `mapstructure:"sample_rate" json:"sample_rate"`
+	// Endpoints are the endpoints whose requests are mirrored, among auction, amp and video. All the
+	// endpoints are mirrored when empty.
+	Endpoints []string `mapstructure:"endpoints" json:"endpoints"`
+}
+
+// Mirrors returns true if the requests of the endpoint can be mirrored.
+func (m *AccountMirroring) Mirrors(endpoint string) bool {
+	return m.SampleRate > 0 && (len(m.Endpoints) == 0 || slices.Contains(m.Endpoints, endpoint))
+}
+
+// Validate checks the account mirroring.
+func (m *AccountMirroring) Validate(errs []error) []error {
+	if m.SampleRate < 0 || m.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("mirroring.sample_rate must be between 0 and 1. Got %g", m.SampleRate))
+	}
+	for _, endpoint := range m.Endpoints {
+		if !slices.Contains(mirroringEndpoints, endpoint) {
+			errs = append(errs, fmt.Errorf("mirroring.endpoints has an unknown endpoint %s", endpoint))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirroringValidation(t *testing.T) {
+	tests := []struct {
+		description string
+		cfg         Mirroring
+		wantErrs    int
+	}{
+		{
+			description: "disabled ignores the target",
+			cfg:         Mirroring{Enabled: false, Target: "kafka"},
+		},
+		{
+			description: "http",
+			cfg:         Mirroring{Enabled: true, Target: "http", HTTP: MirroringHTTP{Endpoint: "https://shadow.example.com", Timeout: 1000, Workers: 4}, QueueSize: 1000},
+		},
+		{
+			description: "http without endpoint, timeout and workers",
+			cfg:         Mirroring{Enabled: true, Target: "http", QueueSize: 1000},
+			wantErrs:    3,
+		},
+		{
+			description: "http with relative endpoint",
+			cfg:         Mirroring{Enabled: true, Target: "http", HTTP: MirroringHTTP{Endpoint: "shadow/pbs", Timeout: 1000, Workers: 4}, QueueSize: 1000},
+			wantErrs:    1,
+		},
+		{
+			description: "file",
+			cfg:         Mirroring{Enabled: true, Target: "file", File: MirroringFile{Path: "/var/log/pbs/mirror.ndjson"}, QueueSize: 1000},
+		},
+		{
+			description: "file without path",
+			cfg:         Mirroring{Enabled: true, Target: "file", QueueSize: 1000},
+			wantErrs:    1,
+		},
+		{
+			description: "unknown target and no queue",
+			cfg:         Mirroring{Enabled: true, Target: "kafka"},
+			wantErrs:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Len(t, tt.cfg.validate(nil), tt.wantErrs)
+		})
+	}
+}
+
+func TestAccountMirroringValidation(t *testing.T) {
+	tests := []struct {
+		description string
+		mirroring   AccountMirroring
+		wantErrs    int
+	}{
+		{
+			description: "empty",
+		},
+		{
+			description: "valid",
+			mirroring:   AccountMirroring{SampleRate: 0.01, Endpoints: []string{"auction", "amp"}},
+		},
+		{
+			description: "negative sample rate",
+			mirroring:   AccountMirroring{SampleRate: -0.1},
+			wantErrs:    1,
+		},
+		{
+			description: "sample rate over 1",
+			mirroring:   AccountMirroring{SampleRate: 1.5},
+			wantErrs:    1,
+		},
+		{
+			description: "unknown endpoint",
+			mirroring:   AccountMirroring{SampleRate: 1, Endpoints: []string{"auction", "cookie_sync"}},
+			wantErrs:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Len(t, tt.mirroring.Validate(nil), tt.wantErrs)
+		})
+	}
+}
+
+func TestAccountMirroringMirrors(t *testing.T) {
+	tests := []struct {
+		description string
+		mirroring   AccountMirroring
+		endpoint    string
+		want        bool
+	}{
+		{
+			description: "no sample rate",
+			mirroring:   AccountMirroring{Endpoints: []string{"auction"}},
+			endpoint:    "auction",
+		},
+		{
+			description: "all endpoints",
+			mirroring:   AccountMirroring{SampleRate: 0.5},
+			endpoint:    "video",
+			want:        true,
+		},
+		{
+			description: "listed endpoint",
+			mirroring:   AccountMirroring{SampleRate: 0.5, Endpoints: []string{"auction", "amp"}},
+			endpoint:    "amp",
+			want:        true,
+		},
+		{
+			description: "unlisted endpoint",
+			mirroring:   AccountMirroring{SampleRate: 0.5, Endpoints: []string{"auction"}},
+			endpoint:    "amp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.mirroring.Mirrors(tt.endpoint))
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+// The endpoints which can have their own account rate limits
+const (
+	RateLimitEndpointAuction = "auction"
+	RateLimitEndpointAMP     = "amp"
+	RateLimitEndpointVideo   = "video"
+)
+
+var rateLimitEndpoints = []string{RateLimitEndpointAuction, RateLimitEndpointAMP, RateLimitEndpointVideo}
+
+// The keys of the account rate limits
+const (
+	// RateLimitScopeAccount shares the limits between all the requests of the account to an endpoint
+	RateLimitScopeAccount = "account"
+	// RateLimitScopeProperty applies the limits to each app bundle or site domain of the account
+	RateLimitScopeProperty = "property"
+)
+
+// RateLimitDefaultMaxProperties is the number of property buckets of an account endpoint when the account
+// doesn't set max_properties.
+const RateLimitDefaultMaxProperties = 100
+
+// The responses to the requests over the account rate limits
+const (
+	// RateLimitActionReject rejects the request with a 429 Too Many Requests status
+	RateLimitActionReject = "reject"
+	// RateLimitActionNoBid responds with a no-bid response
+	RateLimitActionNoBid = "no_bid"
+)
+
+// RateLimiting configures the host side of the account rate limits. The limits themselves are set
+// in the account config, with the host defaults in account_defaults.rate_limits.
+type RateLimiting struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Backend keeps the token buckets: "local" keeps them in each PBS instance, while "redis" shares
+	// them across all the instances using the same Redis server.
+	Backend string            `mapstructure:"backend"`
+	Redis   RateLimitingRedis `mapstructure:"redis"`
+}
+
+// The deployments of the Redis servers of the "redis" rate limiting backend
+const (
+	RateLimitRedisStandalone = "standalone"
+	RateLimitRedisCluster    = "cluster"
+	RateLimitRedisSentinel   = "sentinel"
+)
+
+// RateLimitingRedis configures the connection to the Redis servers of the "redis" rate limiting backend.
+type RateLimitingRedis struct {
+	// Mode is "standalone" for a single server, "cluster" for a Redis Cluster or "sentinel" for a server
+	// monitored by Redis Sentinel.
+	Mode string `mapstructure:"mode"`
+	// Addresses are the host:port of the server, of cluster nodes to discover the cluster from, or of the sentinels.
+	Addresses []string `mapstructure:"addresses"`
+	// MasterName is the name of the master monitored by the sentinels.
+	MasterName string `mapstructure:"master_name"`
+	// Username is the ACL user. The default user is used when it's empty.
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password" redact:"true"`
+	// DB is the database of the standalone or sentinel server. A Redis Cluster only has database 0.
+	DB int `mapstructure:"db"`
+	// TLS connects to the servers over TLS, verified with the system root certificates.
+	TLS bool `mapstructure:"tls"`
+	// Timeout is the number of milliseconds to wait for Redis before allowing the request.
+	Timeout int `mapstructure:"timeout_ms"`
+	// PoolSize is the maximum number of connections to each server.
+	PoolSize int `mapstructure:"pool_size"`
+	// KeyPrefix is prepended to the keys of the token buckets.
+	KeyPrefix string `mapstructure:"key_prefix"`
+}
+
+func (cfg *RateLimiting) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	switch cfg.Backend {
+	case "local":
+	case "redis":
+		switch cfg.Redis.Mode {
+		case RateLimitRedisStandalone:
+			if len(cfg.Redis.Addresses) != 1 {
+				errs = append(errs, fmt.Errorf("rate_limiting.redis.addresses must have one address with rate_limiting.redis.mode=standalone. Got %d", len(cfg.Redis.Addresses)))
+			}
+		case RateLimitRedisCluster:
+			if len(cfg.Redis.Addresses) == 0 {
+				errs = append(errs, fmt.Errorf("rate_limiting.redis.addresses must be set with rate_limiting.redis.mode=cluster"))
+			}
+			if cfg.Redis.DB != 0 {
+				errs = append(errs, fmt.Errorf("rate_limiting.redis.db must be 0 with rate_limiting.redis.mode=cluster. Got %d", cfg.Redis.DB))
+			}
+		case RateLimitRedisSentinel:
+			if len(cfg.Redis.Addresses) == 0 {
+				errs = append(errs, fmt.Errorf("rate_limiting.redis.addresses must be set with rate_limiting.redis.mode=sentinel"))
+			}
+			if cfg.Redis.MasterName == "" {
+				errs = append(errs, fmt.Errorf("rate_limiting.redis.master_name must be set with rate_limiting.redis.mode=sentinel"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("rate_limiting.redis.mode must be %s, %s or %s. Got %s", RateLimitRedisStandalone, RateLimitRedisCluster, RateLimitRedisSentinel, cfg.Redis.Mode))
+		}
+		if cfg.Redis.Timeout <= 0 {
+			errs = append(errs, fmt.Errorf("rate_limiting.redis.timeout_ms must be > 0. Got %d", cfg.Redis.Timeout))
+		}
+		if cfg.Redis.PoolSize <= 0 {
+			errs = append(errs, fmt.Errorf("rate_limiting.redis.pool_size must be > 0. Got %d", cfg.Redis.PoolSize))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("rate_limiting.backend must be local or redis. Got %s", cfg.Backend))
+	}
+	return errs
+}
+
+// AccountRateLimits sets the token bucket limits of the requests of an account.
+type AccountRateLimits struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// Default is the limit of the endpoints without their own.
+	Default AccountRateLimit `mapstructure:"default" json:"default"`
+	// Endpoints are the limits of the auction, amp and video endpoints.
+	Endpoints map[string]AccountRateLimit `mapstructure:"endpoints" json:"endpoints"`
+	// Scope is "account" to limit all the requests of the account together, or "property" to limit
+	// the requests of each app bundle or site domain. Defaults to "account".
+	Scope string `mapstructure:"scope" json:"scope"`
+	// MaxProperties bounds the buckets of the "property" scope: the properties are hashed to at most MaxProperties
+	// buckets per endpoint, and all the requests of the account also share a bucket MaxProperties times the limit.
+	MaxProperties int `mapstructure:"max_properties" json:"max_properties"`
+	// Action is "reject" to respond to the requests over the limits with a 429 status, or "no_bid" to
+	// respond with a no-bid response. Defaults to "reject".
+	Action string `mapstructure:"action" json:"action"`
+}
+
+// AccountRateLimit is a token bucket holding up to Burst requests, refilled at RequestsPerSecond.
+// A RequestsPerSecond of 0 leaves the requests unlimited.
+type AccountRateLimit struct {
+	RequestsPerSecond float64 `mapstructure:"requests_per_second" json:"requests_per_second"`
+	// Burst defaults to RequestsPerSecond, rounded up.
+	Burst int `mapstructure:"burst" json:"burst"`
+}
+
+// Limit returns the rate limit of the endpoint.
+func (l *AccountRateLimits) Limit(endpoint string) AccountRateLimit {
+	if limit, ok := l.Endpoints[endpoint]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+// Validate checks the account rate limits.
+func (l *AccountRateLimits) Validate(errs []error) []error {
+	errs = l.Default.validate("rate_limits.default", errs)
+	for endpoint, limit := range l.Endpoints {
+		if !slices.Contains(rateLimitEndpoints, endpoint) {
+			errs = append(errs, fmt.Errorf("rate_limits.endpoints has an unknown endpoint %s", endpoint))
+		}
+		errs = limit.validate("rate_limits.endpoints."+endpoint, errs)
+	}
+	if l.Scope != "" && l.Scope != RateLimitScopeAccount && l.Scope != RateLimitScopeProperty {
+		errs = append(errs, fmt.Errorf("rate_limits.scope must be %s or %s. Got %s", RateLimitScopeAccount, RateLimitScopeProperty, l.Scope))
+	}
+	if l.MaxProperties < 0 {
+		errs = append(errs, fmt.Errorf("rate_limits.max_properties must be >= 0. Got %d", l.MaxProperties))
+	}
+	if l.Action != "" && l.Action != RateLimitActionReject && l.Action != RateLimitActionNoBid {
+		errs = append(errs, fmt.Errorf("rate_limits.action must be %s or %s. Got %s", RateLimitActionReject, RateLimitActionNoBid, l.Action))
+	}
+	return errs
+}
+
+func (l AccountRateLimit) validate(field string, errs []error) []error {
+	if l.RequestsPerSecond < 0 {
+		errs = append(errs, fmt.Errorf("%s.requests_per_second must be >= 0. Got %g", field, l.RequestsPerSecond))
+	}
+	if l.Burst < 0 {
+		errs = append(errs, fmt.Errorf("%s.burst must be >= 0. Got %d", field, l.Burst))
+	}
+	return errs
+}
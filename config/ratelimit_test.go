@@ -0,0 +1,129 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitingValidation(t *testing.T) {
+	tests := []struct {
+		description string
+		cfg         RateLimiting
+		wantErrs    int
+	}{
+		{
+			description: "disabled ignores the backend",
+			cfg:         RateLimiting{Enabled: false, Backend: "memcached"},
+		},
+		{
+			description: "local",
+			cfg:         RateLimiting{Enabled: true, Backend: "local"},
+		},
+		{
+			description: "redis",
+			cfg:         RateLimiting{Enabled: true, Backend: "redis", Redis: RateLimitingRedis{Mode: RateLimitRedisStandalone, Addresses: []string{"localhost:6379"}, Timeout: 20, PoolSize: 16}},
+		},
+		{
+			description: "redis without address, timeout and pool",
+			cfg:         RateLimiting{Enabled: true, Backend: "redis", Redis: RateLimitingRedis{Mode: RateLimitRedisStandalone}},
+			wantErrs:    3,
+		},
+		{
+			description: "redis cluster",
+			cfg:         RateLimiting{Enabled: true, Backend: "redis", Redis: RateLimitingRedis{Mode: RateLimitRedisCluster, Addresses: []string{"node1:6379", "node2:6379"}, Timeout: 20, PoolSize: 16}},
+		},
+		{
+			description: "redis cluster with a db",
+			cfg:         RateLimiting{Enabled: true, Backend: "redis", Redis: RateLimitingRedis{Mode: RateLimitRedisCluster, Addresses: []string{"node1:6379"}, DB: 1, Timeout: 20, PoolSize: 16}},
+			wantErrs:    1,
+		},
+		{
+			description: "redis sentinel",
+			cfg:         RateLimiting{Enabled: true, Backend: "redis", Redis: RateLimitingRedis{Mode: RateLimitRedisSentinel, Addresses: []string{"sentinel:26379"}, MasterName: "ratelimit", Timeout: 20, PoolSize: 16}},
+		},
+		{
+			description: "redis sentinel without master name",
+			cfg:         RateLimiting{Enabled: true, Backend: "redis", Redis: RateLimitingRedis{Mode: RateLimitRedisSentinel, Addresses: []string{"sentinel:26379"}, Timeout: 20, PoolSize: 16}},
+			wantErrs:    1,
+		},
+		{
+			description: "redis unknown mode",
+			cfg:         RateLimiting{Enabled: true, Backend: "redis", Redis: RateLimitingRedis{Mode: "replicas", Addresses: []string{"localhost:6379"}, Timeout: 20, PoolSize: 16}},
+			wantErrs:    1,
+		},
+		{
+			description: "unknown backend",
+			cfg:         RateLimiting{Enabled: true, Backend: "memcached"},
+			wantErrs:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Len(t, tt.cfg.validate(nil), tt.wantErrs)
+		})
+	}
+}
+
+func TestAccountRateLimitsValidation(t *testing.T) {
+	tests := []struct {
+		description string
+		limits      AccountRateLimits
+		wantErrs    int
+	}{
+		{
+			description: "empty",
+			limits:      AccountRateLimits{},
+		},
+		{
+			description: "valid",
+			limits: AccountRateLimits{
+				Enabled:   true,
+				Default:   AccountRateLimit{RequestsPerSecond: 100},
+				Endpoints: map[string]AccountRateLimit{RateLimitEndpointAMP: {RequestsPerSecond: 2.5, Burst: 10}},
+				Scope:     RateLimitScopeProperty,
+				Action:    RateLimitActionNoBid,
+			},
+		},
+		{
+			description: "negative limits",
+			limits: AccountRateLimits{
+				Default:   AccountRateLimit{RequestsPerSecond: -1},
+				Endpoints: map[string]AccountRateLimit{RateLimitEndpointVideo: {Burst: -1}},
+			},
+			wantErrs: 2,
+		},
+		{
+			description: "unknown endpoint",
+			limits:      AccountRateLimits{Endpoints: map[string]AccountRateLimit{"cookie_sync": {RequestsPerSecond: 1}}},
+			wantErrs:    1,
+		},
+		{
+			description: "negative max properties",
+			limits:      AccountRateLimits{Scope: RateLimitScopeProperty, MaxProperties: -1},
+			wantErrs:    1,
+		},
+		{
+			description: "unknown scope and action",
+			limits:      AccountRateLimits{Scope: "domain", Action: "drop"},
+			wantErrs:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Len(t, tt.limits.Validate(nil), tt.wantErrs)
+		})
+	}
+}
+
+func TestAccountRateLimitsLimit(t *testing.T) {
+	limits := AccountRateLimits{
+		Default:   AccountRateLimit{RequestsPerSecond: 100},
+		Endpoints: map[string]AccountRateLimit{RateLimitEndpointAMP: {RequestsPerSecond: 10, Burst: 20}},
+	}
+
+	assert.Equal(t, AccountRateLimit{RequestsPerSecond: 10, Burst: 20}, limits.Limit(RateLimitEndpointAMP))
+	assert.Equal(t, AccountRateLimit{RequestsPerSecond: 100}, limits.Limit(RateLimitEndpointAuction))
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// redactedValue replaces the values of the secret settings in the redacted config.
+const redactedValue = "<REDACTED>"
+
+// secretNameRegexp matches the setting names holding a secret. It errs on the side of redacting too much, so the
+// secrets added later are redacted even if their field isn't tagged.
+var secretNameRegexp = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|private_?key|credential|encryption|(^|_)key$)`)
+
+// Redacted returns the configuration as nested maps keyed by the setting names, as they appear in the config files.
+// The values of the fields tagged `redact:"true"` and of the settings whose name looks like it holds a secret
+// (passwords, secrets, tokens, keys) are replaced by "<REDACTED>", as are the credentials of the URLs. The fields
+// which aren't settings, such as parsed values, are left out.
+func (cfg *Configuration) Redacted() map[string]interface{} {
+	redacted, _ := redact(reflect.ValueOf(cfg)).(map[string]interface{})
+	return redacted
+}
+
+func redact(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.CanInterface() {
+		if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+			if text, err := marshaler.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := make(map[string]interface{})
+		redactStruct(v, fields)
+		return fields
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		entries := make(map[string]interface{}, v.Len())
+		for _, key := range v.MapKeys() {
+			name := fmt.Sprintf("%v", key.Interface())
+			entries[name] = redactSetting(name, v.MapIndex(key))
+		}
+		return entries
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		elements := make([]interface{}, v.Len())
+		for i := range elements {
+			elements[i] = redact(v.Index(i))
+		}
+		return elements
+	case reflect.String:
+		return redactURL(v.String())
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+	}
+	return v.Interface()
+}
+
+// redactURL replaces the user info of the URLs, which may hold the credentials of a database or a Redis server.
+func redactURL(value string) string {
+	if !strings.Contains(value, "@") {
+		return value
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	u.User = url.User(redactedValue)
+	// the user info is written back unescaped so the redaction is readable
+	return strings.Replace(u.String(), url.PathEscape(redactedValue), redactedValue, 1)
+}
+
+// redactStruct adds the settings of the struct to the fields map. Squashed structs add their own settings to it.
+func redactStruct(v reflect.Value, fields map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, squash := settingName(field)
+		if squash && v.Field(i).Kind() == reflect.Struct {
+			redactStruct(v.Field(i), fields)
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		if field.Tag.Get("redact") == "true" {
+			fields[name] = redactSecret(v.Field(i))
+			continue
+		}
+		fields[name] = redactSetting(name, v.Field(i))
+	}
+}
+
+func redactSetting(name string, v reflect.Value) interface{} {
+	if secretNameRegexp.MatchString(name) {
+		return redactSecret(v)
+	}
+	return redact(v)
+}
+
+// redactSecret replaces the value of a secret, unless it's not set.
+func redactSecret(v reflect.Value) interface{} {
+	if v.IsZero() {
+		return redact(v)
+	}
+	return redactedValue
+}
+
+// settingName returns the name of the setting held by the field: its mapstructure tag, or its json tag for the
+// types defined outside of the config package. Fields without either tag aren't settings.
+func settingName(field reflect.StructField) (name string, squash bool) {
+	tag, ok := field.Tag.Lookup("mapstructure")
+	if !ok {
+		tag, ok = field.Tag.Lookup("json")
+	}
+	if !ok || tag == "-" {
+		return "", false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	return name, strings.Contains(options, "squash")
+}
@@ -71,6 +71,9 @@ type StoredRequests struct {
 	// HTTPEvents configures an instance of stored_requests/events/http/http.go.
 	// If non-nil, the server will use those endpoints to populate and update the cache.
 	HTTPEvents HTTPEventsConfig `mapstructure:"http_events"`
+	// WriteAPI configures an instance of stored_requests/events/admin/admin.go.
+	// If enabled, stored data can be validated and written to the filesystem or database through this endpoint.
+	WriteAPI WriteAPIConfig `mapstructure:"write_api"`
 }
 
 // HTTPEventsConfig configures stored_requests/events/http/http.go
@@ -97,12 +100,56 @@ type CacheEventsConfig struct {
 	Endpoint string `mapstructure:"endpoint"`
 }
 
+// WriteAPIConfig configures stored_requests/events/admin/admin.go
+type WriteAPIConfig struct {
+	// Enabled should be true to enable the write api endpoints
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint is the url path prefix exposed for this stored data write api
+	Endpoint string `mapstructure:"endpoint"`
+	// Tokens are the bearer tokens accepted in the Authorization header of the write api requests
+	Tokens []string `mapstructure:"tokens" redact:"true"`
+}
+
+func (cfg *WriteAPIConfig) validate(storedCfg *StoredRequests, errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	section := storedCfg.Section()
+
+	if storedCfg.DataType() == CategoryDataType {
+		return append(errs, fmt.Errorf("%s: write_api is not supported for categories", section))
+	}
+	if !strings.HasPrefix(cfg.Endpoint, "/") {
+		errs = append(errs, fmt.Errorf("%s: write_api.endpoint must be a path starting with /", section))
+	}
+	if len(cfg.Tokens) == 0 {
+		errs = append(errs, fmt.Errorf("%s: write_api.tokens must contain at least one token", section))
+	}
+	for i, token := range cfg.Tokens {
+		if token == "" {
+			errs = append(errs, fmt.Errorf("%s: write_api.tokens[%d] must not be empty", section, i))
+		}
+	}
+	writesToDatabase := storedCfg.Database.ConnectionInfo.Database != "" && storedCfg.Database.WriteQueries.isEnabled()
+	if !storedCfg.Files.Enabled && !writesToDatabase {
+		errs = append(errs, fmt.Errorf("%s: write_api requires filesystem.enabled or the database.write queries", section))
+	}
+	// the database fetcher doesn't load accounts, so they couldn't be read back through the write api
+	if writesToDatabase && storedCfg.DataType() == AccountDataType {
+		errs = append(errs, fmt.Errorf("%s: write_api doesn't support the database.write queries for accounts, use filesystem.enabled", section))
+	}
+	return errs
+}
+
 // FileFetcherConfig configures a stored_requests/backends/file_fetcher/fetcher.go
 type FileFetcherConfig struct {
 	// Enabled should be true if Stored Requests should be loaded from the filesystem.
 	Enabled bool `mapstructure:"enabled"`
 	// Path to the directory this file fetcher gets data from.
 	Path string `mapstructure:"directorypath"`
+	// Watch should be true if the files should be reloaded when they are created, modified or deleted.
+	// EventProducers are in stored_requests/events/filesystem
+	Watch bool `mapstructure:"watch"`
 }
 
 // HTTPFetcherConfig configures a stored_requests/backends/http_fetcher/fetcher.go
@@ -126,6 +173,11 @@ func resolvedStoredRequestsConfig(cfg *Configuration) {
 	amp.HTTP.Endpoint = sr.HTTP.AmpEndpoint
 	amp.CacheEvents.Endpoint = "/storedrequests/amp"
 	amp.HTTPEvents.Endpoint = sr.HTTPEvents.AmpEndpoint
+	amp.Database.WriteQueries.SaveQuery = sr.Database.WriteQueries.AmpSaveQuery
+	amp.Database.WriteQueries.DeleteQuery = sr.Database.WriteQueries.AmpDeleteQuery
+	amp.WriteAPI.Endpoint = "/storeddata/amp"
+	amp.Database.Notifications.Channel = sr.Database.Notifications.AmpChannel
+	amp.Database.Notifications.ChangeLog.Query = sr.Database.Notifications.ChangeLog.AmpQuery
 
 	// Set data types for each section
 	cfg.StoredRequests.dataType = RequestDataType
@@ -165,6 +217,7 @@ func (cfg *StoredRequests) validate(errs []error) []error {
 		}
 	}
 	errs = cfg.InMemoryCache.validate(cfg.DataType(), errs)
+	errs = cfg.WriteAPI.validate(cfg, errs)
 	return errs
 }
 
@@ -175,6 +228,8 @@ type DatabaseConfig struct {
 	FetcherQueries      DatabaseFetcherQueries   `mapstructure:"fetcher"`
 	CacheInitialization DatabaseCacheInitializer `mapstructure:"initialize_caches"`
 	PollUpdates         DatabaseUpdatePolling    `mapstructure:"poll_for_updates"`
+	WriteQueries        DatabaseWriteQueries     `mapstructure:"write"`
+	Notifications       DatabaseNotifications    `mapstructure:"notifications"`
 }
 
 func (cfg *DatabaseConfig) validate(dataType DataType, errs []error) []error {
@@ -184,6 +239,8 @@ func (cfg *DatabaseConfig) validate(dataType DataType, errs []error) []error {
 
 	errs = cfg.CacheInitialization.validate(dataType, errs)
 	errs = cfg.PollUpdates.validate(dataType, errs)
+	errs = cfg.WriteQueries.validate(dataType, errs)
+	errs = cfg.Notifications.validate(dataType, cfg.ConnectionInfo.Driver, errs)
 	return errs
 }
 
@@ -195,7 +252,7 @@ type DatabaseConnection struct {
 	Host        string `mapstructure:"host"`
 	Port        int    `mapstructure:"port"`
 	Username    string `mapstructure:"user"`
-	Password    string `mapstructure:"password"`
+	Password    string `mapstructure:"password" redact:"true"`
 	QueryString string `mapstructure:"query_string"`
 	TLS         TLS    `mapstructure:"tls"`
 }
@@ -203,7 +260,7 @@ type DatabaseConnection struct {
 type TLS struct {
 	RootCert   string `mapstructure:"root_cert"`
 	ClientCert string `mapstructure:"client_cert"`
-	ClientKey  string `mapstructure:"client_key"`
+	ClientKey  string `mapstructure:"client_key" redact:"true"`
 }
 
 type DatabaseFetcherQueries struct {
@@ -308,6 +365,137 @@ func (cfg *DatabaseUpdatePolling) validate(dataType DataType, errs []error) []er
 	return errs
 }
 
+// DatabaseWriteQueries are used by the write api to save and delete stored data.
+type DatabaseWriteQueries struct {
+	// SaveQuery inserts or updates stored data. It may use the $ID, $TYPE and $DATA parameters,
+	// where $TYPE is one of "request", "imp" or "response". For example, with Postgres:
+	//
+	// INSERT INTO stored_data (id, type, data, last_updated)
+	//   VALUES ($ID, $TYPE, $DATA, now())
+	//   ON CONFLICT (id, type) DO UPDATE SET data = $DATA, last_updated = now()
+	SaveQuery string `mapstructure:"save_query"`
+	// DeleteQuery deletes stored data. It may use the $ID and $TYPE parameters.
+	DeleteQuery string `mapstructure:"delete_query"`
+	// AmpSaveQuery is the same as SaveQuery, but used for the `/openrtb2/amp` stored requests.
+	AmpSaveQuery string `mapstructure:"amp_save_query"`
+	// AmpDeleteQuery is the same as DeleteQuery, but used for the `/openrtb2/amp` stored requests.
+	AmpDeleteQuery string `mapstructure:"amp_delete_query"`
+}
+
+func (cfg *DatabaseWriteQueries) isEnabled() bool {
+	return cfg.SaveQuery != "" && cfg.DeleteQuery != ""
+}
+
+func (cfg *DatabaseWriteQueries) validate(dataType DataType, errs []error) []error {
+	section := dataType.Section()
+	if cfg.SaveQuery == "" && cfg.DeleteQuery == "" {
+		return errs
+	}
+
+	if cfg.SaveQuery == "" || cfg.DeleteQuery == "" {
+		errs = append(errs, fmt.Errorf("%s: database.write.save_query and database.write.delete_query must be set together", section))
+	}
+	if cfg.SaveQuery != "" && (!strings.Contains(cfg.SaveQuery, "$ID") || !strings.Contains(cfg.SaveQuery, "$DATA")) {
+		errs = append(errs, fmt.Errorf("%s: database.write.save_query must contain $ID and $DATA parameters", section))
+	}
+	if cfg.DeleteQuery != "" && !strings.Contains(cfg.DeleteQuery, "$ID") {
+		errs = append(errs, fmt.Errorf("%s: database.write.delete_query must contain $ID parameter", section))
+	}
+	return errs
+}
+
+// DatabaseNotifications are used to update the caches as soon as the stored data changes,
+// rather than waiting for the next poll_for_updates query.
+type DatabaseNotifications struct {
+	// Channel is the Postgres channel the changes are sent to with NOTIFY or pg_notify(). Each notification
+	// payload is a JSON object such as:
+	//
+	// {"id": "req1", "type": "request", "data": {...}, "timestamp": "2024-01-02T15:04:05.123+00:00"}
+	//
+	// where type is one of "request", "imp", "response" or "account". The data is left out when the stored data was
+	// deleted or is too large for a notification, which invalidates it in the caches. Accounts are always invalidated,
+	// as the caches hold them merged with the account defaults.
+	Channel string `mapstructure:"channel"`
+	// AmpChannel is the same as Channel, but used for the `/openrtb2/amp` stored requests.
+	AmpChannel string `mapstructure:"amp_channel"`
+	// MinReconnectInterval and MaxReconnectInterval bound the delay between the attempts to listen again
+	// after the connection was lost.
+	MinReconnectInterval int `mapstructure:"min_reconnect_interval_ms"`
+	MaxReconnectInterval int `mapstructure:"max_reconnect_interval_ms"`
+	// ChangeLog reads the changes from a change log table, for databases without notifications such as MySQL.
+	ChangeLog DatabaseChangeLog `mapstructure:"change_log"`
+}
+
+func (cfg *DatabaseNotifications) validate(dataType DataType, driver string, errs []error) []error {
+	section := dataType.Section()
+	if cfg.Channel != "" {
+		if driver != "postgres" {
+			errs = append(errs, fmt.Errorf("%s: database.notifications.channel is only supported by the postgres driver", section))
+		}
+		if cfg.MinReconnectInterval <= 0 {
+			errs = append(errs, fmt.Errorf("%s: database.notifications.min_reconnect_interval_ms must be > 0", section))
+		}
+		if cfg.MaxReconnectInterval < cfg.MinReconnectInterval {
+			errs = append(errs, fmt.Errorf("%s: database.notifications.max_reconnect_interval_ms must be >= min_reconnect_interval_ms", section))
+		}
+		if cfg.ChangeLog.Query != "" {
+			errs = append(errs, fmt.Errorf("%s: database.notifications.channel and database.notifications.change_log.query can't be used together", section))
+		}
+	}
+	return cfg.ChangeLog.validate(dataType, errs)
+}
+
+// DatabaseChangeLog reads the stored data changes in the order they were made. The table is usually
+// filled by triggers on the stored data tables.
+type DatabaseChangeLog struct {
+	// RefreshRate determines how frequently the Query and AmpQuery are run, in seconds.
+	RefreshRate int `mapstructure:"refresh_rate_seconds"`
+
+	// Timeout is the amount of time before a call to the database is aborted.
+	Timeout int `mapstructure:"timeout_ms"`
+
+	// Query returns the changes made after the last change read, in order. An example Query is:
+	//
+	// SELECT seq, id, data, type, changed_at
+	//   FROM stored_data_changes
+	//   WHERE seq > $LAST_SEQUENCE
+	//   ORDER BY seq
+	//   LIMIT 1000
+	//
+	// The seq column must be an increasing integer, such as an AUTO_INCREMENT primary key. The data is NULL
+	// when the stored data was deleted. changed_at is the time of the change, used to measure the event lag.
+	Query string `mapstructure:"query"`
+	// AmpQuery is the same as Query, but used for the `/openrtb2/amp` stored requests.
+	AmpQuery string `mapstructure:"amp_query"`
+
+	// LastSequenceQuery returns the seq of the last change, or NULL if there's none. The changes logged before
+	// PBS started are in the caches already, so the first Query starts after it. An example LastSequenceQuery is:
+	//
+	// SELECT MAX(seq) FROM stored_data_changes
+	LastSequenceQuery string `mapstructure:"last_sequence_query"`
+}
+
+func (cfg *DatabaseChangeLog) validate(dataType DataType, errs []error) []error {
+	section := dataType.Section()
+	if cfg.Query == "" {
+		return errs
+	}
+
+	if cfg.RefreshRate <= 0 {
+		errs = append(errs, fmt.Errorf("%s: database.notifications.change_log.refresh_rate_seconds must be > 0", section))
+	}
+	if cfg.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("%s: database.notifications.change_log.timeout_ms must be > 0", section))
+	}
+	if !strings.Contains(cfg.Query, "$LAST_SEQUENCE") {
+		errs = append(errs, fmt.Errorf("%s: database.notifications.change_log.query must contain $LAST_SEQUENCE parameter", section))
+	}
+	if cfg.LastSequenceQuery == "" {
+		errs = append(errs, fmt.Errorf("%s: database.notifications.change_log.last_sequence_query must be set with the query", section))
+	}
+	return errs
+}
+
 type InMemoryCache struct {
 	// Identify the type of memory cache. "none", "unbounded", "lru"
 	Type string `mapstructure:"type"`
@@ -322,6 +510,17 @@ type InMemoryCache struct {
 	ImpCacheSize int `mapstructure:"imp_cache_size_bytes"`
 	// ResponsesCacheSize is the max number of bytes allowed in the cache for Stored Responses. Values <= 0 will have no limit
 	RespCacheSize int `mapstructure:"resp_cache_size_bytes"`
+	// NegativeTTL is the number of seconds the ids which weren't found are cached as missing, so they
+	// aren't fetched again on every request. 0 disables negative caching. Only supported by lru caches.
+	NegativeTTL int `mapstructure:"negative_ttl_seconds"`
+	// MaxAge is the number of seconds a cached value is fresh. Older values are fetched again, unless served
+	// stale as set by StaleWhileRevalidate and StaleIfError. 0 means the values are fresh until evicted.
+	MaxAge int `mapstructure:"max_age_seconds"`
+	// StaleWhileRevalidate is the number of seconds after MaxAge a value is still served, while it's
+	// refreshed in the background.
+	StaleWhileRevalidate int `mapstructure:"stale_while_revalidate_seconds"`
+	// StaleIfError is the number of seconds after MaxAge a value is still served if it can't be fetched.
+	StaleIfError int `mapstructure:"stale_if_error_seconds"`
 }
 
 func (cfg *InMemoryCache) validate(dataType DataType, errs []error) []error {
@@ -377,5 +576,24 @@ func (cfg *InMemoryCache) validate(dataType DataType, errs []error) []error {
 	default:
 		errs = append(errs, fmt.Errorf("%s: in_memory_cache.type %s is invalid", section, cfg.Type))
 	}
+	if cfg.Type == "unbounded" || cfg.Type == "lru" {
+		errs = cfg.validatePolicy(section, errs)
+	}
+	return errs
+}
+
+func (cfg *InMemoryCache) validatePolicy(section string, errs []error) []error {
+	if cfg.NegativeTTL < 0 || cfg.MaxAge < 0 || cfg.StaleWhileRevalidate < 0 || cfg.StaleIfError < 0 {
+		return append(errs, fmt.Errorf("%s: in_memory_cache.negative_ttl_seconds, max_age_seconds, stale_while_revalidate_seconds and stale_if_error_seconds must be >= 0", section))
+	}
+	if cfg.NegativeTTL > 0 && cfg.Type != "lru" {
+		errs = append(errs, fmt.Errorf("%s: in_memory_cache.negative_ttl_seconds is only supported for lru caches. Got %d", section, cfg.NegativeTTL))
+	}
+	if cfg.MaxAge == 0 && (cfg.StaleWhileRevalidate > 0 || cfg.StaleIfError > 0) {
+		errs = append(errs, fmt.Errorf("%s: in_memory_cache.stale_while_revalidate_seconds and stale_if_error_seconds require in_memory_cache.max_age_seconds > 0", section))
+	}
+	if cfg.TTL > 0 && cfg.MaxAge > 0 && cfg.TTL < cfg.MaxAge+max(cfg.StaleWhileRevalidate, cfg.StaleIfError) {
+		errs = append(errs, fmt.Errorf("%s: in_memory_cache.ttl_seconds must be >= max_age_seconds plus the largest of stale_while_revalidate_seconds and stale_if_error_seconds, or the values would be evicted while they can still be served. Got %d", section, cfg.TTL))
+	}
 	return errs
 }
@@ -103,6 +103,63 @@ func TestInMemoryCacheValidationSingleCache(t *testing.T) {
 	}).validate(AccountDataType, nil))
 }
 
+func TestInMemoryCachePolicyValidation(t *testing.T) {
+	tests := []struct {
+		description string
+		cache       InMemoryCache
+		wantErr     bool
+	}{
+		{
+			description: "lru with policy",
+			cache:       InMemoryCache{Type: "lru", Size: 1000, TTL: 600, NegativeTTL: 30, MaxAge: 60, StaleWhileRevalidate: 60, StaleIfError: 540},
+		},
+		{
+			description: "unbounded with stale data",
+			cache:       InMemoryCache{Type: "unbounded", MaxAge: 60, StaleWhileRevalidate: 60, StaleIfError: 3600},
+		},
+		{
+			description: "none ignores the policy",
+			cache:       InMemoryCache{Type: "none", NegativeTTL: 30, StaleIfError: 60},
+		},
+		{
+			description: "negative value",
+			cache:       InMemoryCache{Type: "lru", Size: 1000, MaxAge: -1},
+			wantErr:     true,
+		},
+		{
+			description: "unbounded with negative caching",
+			cache:       InMemoryCache{Type: "unbounded", NegativeTTL: 30},
+			wantErr:     true,
+		},
+		{
+			description: "stale while revalidate without max age",
+			cache:       InMemoryCache{Type: "lru", Size: 1000, StaleWhileRevalidate: 60},
+			wantErr:     true,
+		},
+		{
+			description: "stale if error without max age",
+			cache:       InMemoryCache{Type: "unbounded", StaleIfError: 60},
+			wantErr:     true,
+		},
+		{
+			description: "ttl shorter than the stale window",
+			cache:       InMemoryCache{Type: "lru", Size: 1000, TTL: 300, MaxAge: 60, StaleIfError: 600},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			errs := tt.cache.validate(AccountDataType, nil)
+			if tt.wantErr {
+				assert.Len(t, errs, 1)
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
+
 func TestDatabaseConfigValidation(t *testing.T) {
 	tests := []struct {
 		description            string
@@ -205,6 +262,174 @@ func TestDatabaseConfigValidation(t *testing.T) {
 	}
 }
 
+func TestDatabaseWriteQueriesValidation(t *testing.T) {
+	tests := []struct {
+		description    string
+		saveQuery      string
+		deleteQuery    string
+		wantErrorCount int
+	}{
+		{
+			description: "No queries",
+		},
+		{
+			description: "Valid queries",
+			saveQuery:   "INSERT INTO stored_data (id, type, data) VALUES ($ID, $TYPE, $DATA)",
+			deleteQuery: "DELETE FROM stored_data WHERE id = $ID AND type = $TYPE",
+		},
+		{
+			description:    "Save query without delete query",
+			saveQuery:      "INSERT INTO stored_data (id, type, data) VALUES ($ID, $TYPE, $DATA)",
+			wantErrorCount: 1,
+		},
+		{
+			description:    "Queries missing parameters",
+			saveQuery:      "INSERT INTO stored_data (id) VALUES ($ID)",
+			deleteQuery:    "DELETE FROM stored_data",
+			wantErrorCount: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		queries := &DatabaseWriteQueries{SaveQuery: tt.saveQuery, DeleteQuery: tt.deleteQuery}
+		errs := queries.validate(RequestDataType, nil)
+		assert.Equal(t, tt.wantErrorCount, len(errs), tt.description)
+	}
+}
+
+func TestDatabaseNotificationsValidation(t *testing.T) {
+	validChangeLog := DatabaseChangeLog{
+		RefreshRate:       1,
+		Timeout:           1000,
+		Query:             "SELECT seq, id, data, type, changed_at FROM stored_data_changes WHERE seq > $LAST_SEQUENCE",
+		LastSequenceQuery: "SELECT MAX(seq) FROM stored_data_changes",
+	}
+
+	tests := []struct {
+		description    string
+		driver         string
+		notifications  DatabaseNotifications
+		wantErrorCount int
+	}{
+		{
+			description: "Disabled",
+			driver:      "mysql",
+		},
+		{
+			description:   "Valid channel",
+			driver:        "postgres",
+			notifications: DatabaseNotifications{Channel: "stored_data", MinReconnectInterval: 1000, MaxReconnectInterval: 60000},
+		},
+		{
+			description:    "Channel with mysql",
+			driver:         "mysql",
+			notifications:  DatabaseNotifications{Channel: "stored_data", MinReconnectInterval: 1000, MaxReconnectInterval: 60000},
+			wantErrorCount: 1,
+		},
+		{
+			description:    "Invalid reconnect intervals",
+			driver:         "postgres",
+			notifications:  DatabaseNotifications{Channel: "stored_data", MinReconnectInterval: 0, MaxReconnectInterval: -1},
+			wantErrorCount: 2,
+		},
+		{
+			description:   "Valid change log",
+			driver:        "mysql",
+			notifications: DatabaseNotifications{ChangeLog: validChangeLog},
+		},
+		{
+			description:    "Channel with change log",
+			driver:         "postgres",
+			notifications:  DatabaseNotifications{Channel: "stored_data", MinReconnectInterval: 1000, MaxReconnectInterval: 60000, ChangeLog: validChangeLog},
+			wantErrorCount: 1,
+		},
+		{
+			description:    "Invalid change log",
+			driver:         "mysql",
+			notifications:  DatabaseNotifications{ChangeLog: DatabaseChangeLog{Query: "SELECT seq, id, data, type, changed_at FROM stored_data_changes"}},
+			wantErrorCount: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		errs := tt.notifications.validate(RequestDataType, tt.driver, nil)
+		assert.Equal(t, tt.wantErrorCount, len(errs), tt.description)
+	}
+}
+
+func TestWriteAPIValidation(t *testing.T) {
+	tests := []struct {
+		description    string
+		dataType       DataType
+		writeAPI       WriteAPIConfig
+		files          bool
+		database       bool
+		wantErrorCount int
+	}{
+		{
+			description: "Disabled",
+			dataType:    RequestDataType,
+		},
+		{
+			description: "Enabled with files",
+			dataType:    AccountDataType,
+			writeAPI:    WriteAPIConfig{Enabled: true, Endpoint: "/storeddata/accounts", Tokens: []string{"token"}},
+			files:       true,
+		},
+		{
+			description: "Enabled with database",
+			dataType:    RequestDataType,
+			writeAPI:    WriteAPIConfig{Enabled: true, Endpoint: "/storeddata/openrtb2", Tokens: []string{"token"}},
+			database:    true,
+		},
+		{
+			description:    "Accounts with database",
+			dataType:       AccountDataType,
+			writeAPI:       WriteAPIConfig{Enabled: true, Endpoint: "/storeddata/accounts", Tokens: []string{"token"}},
+			database:       true,
+			wantErrorCount: 1,
+		},
+		{
+			description:    "Enabled without backend",
+			dataType:       RequestDataType,
+			writeAPI:       WriteAPIConfig{Enabled: true, Endpoint: "/storeddata/openrtb2", Tokens: []string{"token"}},
+			wantErrorCount: 1,
+		},
+		{
+			description:    "Invalid endpoint and tokens",
+			dataType:       RequestDataType,
+			writeAPI:       WriteAPIConfig{Enabled: true, Endpoint: "storeddata", Tokens: []string{""}},
+			files:          true,
+			wantErrorCount: 2,
+		},
+		{
+			description:    "No tokens",
+			dataType:       VideoDataType,
+			writeAPI:       WriteAPIConfig{Enabled: true, Endpoint: "/storeddata/video"},
+			files:          true,
+			wantErrorCount: 1,
+		},
+		{
+			description:    "Categories",
+			dataType:       CategoryDataType,
+			writeAPI:       WriteAPIConfig{Enabled: true, Endpoint: "/storeddata/categories", Tokens: []string{"token"}},
+			files:          true,
+			wantErrorCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		cfg := &StoredRequests{dataType: tt.dataType, WriteAPI: tt.writeAPI}
+		cfg.Files.Enabled = tt.files
+		if tt.database {
+			cfg.Database.ConnectionInfo.Database = "db"
+			cfg.Database.WriteQueries = DatabaseWriteQueries{SaveQuery: "save $ID $DATA", DeleteQuery: "delete $ID"}
+		}
+		errs := cfg.WriteAPI.validate(cfg, nil)
+		assert.Equal(t, tt.wantErrorCount, len(errs), tt.description)
+	}
+}
+
 func assertErrsExist(t *testing.T, err []error) {
 	t.Helper()
 	if len(err) == 0 {
@@ -250,6 +475,16 @@ func TestResolveConfig(t *testing.T) {
 				PollUpdates: DatabaseUpdatePolling{
 					AmpQuery: "amp-poll-query",
 				},
+				WriteQueries: DatabaseWriteQueries{
+					AmpSaveQuery:   "amp-save-query",
+					AmpDeleteQuery: "amp-delete-query",
+				},
+				Notifications: DatabaseNotifications{
+					AmpChannel: "amp-channel",
+					ChangeLog: DatabaseChangeLog{
+						AmpQuery: "amp-change-log-query",
+					},
+				},
 			},
 			HTTP: HTTPFetcherConfig{
 				AmpEndpoint: "amp-http-fetcher-endpoint",
@@ -289,4 +524,9 @@ func TestResolveConfig(t *testing.T) {
 	assertStringsEqual(t, amp.HTTP.Endpoint, cfg.StoredRequests.HTTP.AmpEndpoint)
 	assertStringsEqual(t, amp.HTTPEvents.Endpoint, cfg.StoredRequests.HTTPEvents.AmpEndpoint)
 	assertStringsEqual(t, amp.CacheEvents.Endpoint, "/storedrequests/amp")
+	assertStringsEqual(t, amp.Database.WriteQueries.SaveQuery, cfg.StoredRequests.Database.WriteQueries.AmpSaveQuery)
+	assertStringsEqual(t, amp.Database.WriteQueries.DeleteQuery, cfg.StoredRequests.Database.WriteQueries.AmpDeleteQuery)
+	assertStringsEqual(t, amp.WriteAPI.Endpoint, "/storeddata/amp")
+	assertStringsEqual(t, amp.Database.Notifications.Channel, cfg.StoredRequests.Database.Notifications.AmpChannel)
+	assertStringsEqual(t, amp.Database.Notifications.ChangeLog.Query, cfg.StoredRequests.Database.Notifications.ChangeLog.AmpQuery)
 }
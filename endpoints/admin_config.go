@@ -0,0 +1,143 @@
+package endpoints
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/hostconfig"
+	"github.com/prebid/prebid-server/v3/util/httputil"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// maxBidderOverrideSize is the largest bidder override accepted by the admin bidders endpoint.
+const maxBidderOverrideSize = 1 << 16
+
+// NewAdminConfigEndpoint returns the effective host config, with the runtime changes of the bidders applied and
+// the secrets redacted.
+//
+// Requests must carry one of the tokens in an "Authorization: Bearer {token}" header.
+func NewAdminConfigEndpoint(cfg *config.Configuration, store *hostconfig.Store, tokens []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := authorizeAdmin(w, r, tokens); !ok {
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		effective := *cfg
+		effective.BidderInfos = store.Load().BidderInfos
+		writeAdminJSON(w, "/admin/config", effective.Redacted())
+	}
+}
+
+// NewAdminBiddersEndpoint manages the runtime changes of the bidders, which must be registered on the "/admin/bidders"
+// and "/admin/bidders/" paths:
+//
+// GET /admin/bidders returns the current runtime overrides and their version.
+// POST /admin/bidders/{bidder} merges the override of the body, e.g. {"disabled":true} or {"endpoint":"https://..."}.
+// DELETE /admin/bidders/{bidder} removes the override, so the bidder is back to the settings of the config files.
+//
+// Requests must carry one of the tokens in an "Authorization: Bearer {token}" header. The changes are recorded in
+// the audit log with the index of the token used.
+func NewAdminBiddersEndpoint(store *hostconfig.Store, tokens []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tokenIndex, ok := authorizeAdmin(w, r, tokens)
+		if !ok {
+			return
+		}
+
+		bidder := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/bidders"), "/")
+		if bidder == "" {
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", http.MethodGet)
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			writeAdminJSON(w, "/admin/bidders", store.Load())
+			return
+		}
+
+		actor := fmt.Sprintf("token[%d]@%s", tokenIndex, r.RemoteAddr)
+		var snapshot *hostconfig.Snapshot
+		var err error
+		switch r.Method {
+		case http.MethodPost:
+			var override hostconfig.BidderOverride
+			if override, err = readBidderOverride(r); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, "Invalid bidder override: %v\n", err)
+				return
+			}
+			snapshot, err = store.UpdateBidder(actor, bidder, override)
+		case http.MethodDelete:
+			snapshot, err = store.ResetBidder(actor, bidder)
+		default:
+			w.Header().Set("Allow", "POST, DELETE")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err != nil {
+			if hostconfig.IsInvalidChange(err) {
+				w.WriteHeader(http.StatusBadRequest)
+			} else {
+				glog.Errorf("/admin/bidders failed to change bidder %s: %v", bidder, err)
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			w.Write([]byte(err.Error()))
+			return
+		}
+		writeAdminJSON(w, "/admin/bidders", snapshot)
+	}
+}
+
+func readBidderOverride(r *http.Request) (hostconfig.BidderOverride, error) {
+	var override hostconfig.BidderOverride
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBidderOverrideSize+1))
+	if err != nil {
+		return override, err
+	}
+	if len(body) > maxBidderOverrideSize {
+		return override, fmt.Errorf("the body is larger than %d bytes", maxBidderOverrideSize)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&override); err != nil {
+		return override, err
+	}
+	if override.IsEmpty() {
+		return override, fmt.Errorf("the body must set disabled or endpoint")
+	}
+	return override, nil
+}
+
+// authorizeAdmin returns the index of the token of the request, or responds with 401 Unauthorized.
+func authorizeAdmin(w http.ResponseWriter, r *http.Request, tokens []string) (int, bool) {
+	index := httputil.BearerTokenIndex(r, tokens)
+	if index < 0 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		w.WriteHeader(http.StatusUnauthorized)
+		return index, false
+	}
+	return index, true
+}
+
+func writeAdminJSON(w http.ResponseWriter, endpoint string, v interface{}) {
+	jsonOutput, err := jsonutil.Marshal(v)
+	if err != nil {
+		glog.Errorf("%s Critical error when trying to marshal the response: %v", endpoint, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonOutput)
+}
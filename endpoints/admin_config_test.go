@@ -0,0 +1,217 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/hostconfig"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAdminTime struct{}
+
+func (fakeAdminTime) Now() time.Time {
+	return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+type fakeAdminAuditLog struct {
+	entries []hostconfig.AuditEntry
+}
+
+func (l *fakeAdminAuditLog) Record(entry hostconfig.AuditEntry) error {
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func newAdminTestStore(auditLog hostconfig.AuditLog) *hostconfig.Store {
+	return hostconfig.NewStore(config.BidderInfos{
+		"appnexus": {Endpoint: "https://appnexus.com"},
+		"rubicon":  {Endpoint: "https://rubicon.com", Disabled: true},
+	}, auditLog, fakeAdminTime{})
+}
+
+func newAdminRequest(method, path, body, token string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.RemoteAddr = "10.0.0.1:1234"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestAdminConfigEndpoint(t *testing.T) {
+	cfg := &config.Configuration{Port: 8000, RecaptchaSecret: "recaptcha"}
+	store := newAdminTestStore(&fakeAdminAuditLog{})
+	_, err := store.UpdateBidder("admin", "appnexus", hostconfig.BidderOverride{Endpoint: ptrutil.ToPtr("https://new.appnexus.com")})
+	require.NoError(t, err)
+	handler := NewAdminConfigEndpoint(cfg, store, []string{"t1", "t2"})
+
+	testCases := []struct {
+		description  string
+		method       string
+		token        string
+		expectedCode int
+	}{
+		{description: "no_token", method: http.MethodGet, expectedCode: http.StatusUnauthorized},
+		{description: "wrong_token", method: http.MethodGet, token: "t3", expectedCode: http.StatusUnauthorized},
+		{description: "post_not_allowed", method: http.MethodPost, token: "t1", expectedCode: http.StatusMethodNotAllowed},
+		{description: "get", method: http.MethodGet, token: "t2", expectedCode: http.StatusOK},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler(w, newAdminRequest(test.method, "/admin/config", "", test.token))
+			assert.Equal(t, test.expectedCode, w.Code)
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+
+			var view map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &view))
+			assert.Equal(t, float64(8000), view["port"])
+			assert.Equal(t, "<REDACTED>", view["recaptcha_secret"])
+			adapters := view["adapters"].(map[string]interface{})
+			assert.Equal(t, "https://new.appnexus.com", adapters["appnexus"].(map[string]interface{})["endpoint"], "the runtime changes are applied")
+		})
+	}
+	assert.Empty(t, cfg.BidderInfos, "the config isn't modified")
+}
+
+func TestAdminBiddersEndpoint(t *testing.T) {
+	testCases := []struct {
+		description    string
+		method         string
+		path           string
+		body           string
+		token          string
+		expectedCode   int
+		expectedBody   string
+		expectedAudits int
+	}{
+		{
+			description:  "no_token",
+			method:       http.MethodPost,
+			path:         "/admin/bidders/appnexus",
+			body:         `{"disabled":true}`,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			description:  "list",
+			method:       http.MethodGet,
+			path:         "/admin/bidders",
+			token:        "t1",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"version":0,"updated_at":"2026-01-01T00:00:00Z","overrides":{}}`,
+		},
+		{
+			description:  "list_post_not_allowed",
+			method:       http.MethodPost,
+			path:         "/admin/bidders",
+			token:        "t1",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			description:    "disable",
+			method:         http.MethodPost,
+			path:           "/admin/bidders/AppNexus",
+			body:           `{"disabled":true}`,
+			token:          "t2",
+			expectedCode:   http.StatusOK,
+			expectedBody:   `{"version":1,"updated_at":"2026-01-01T00:00:00Z","overrides":{"appnexus":{"disabled":true}}}`,
+			expectedAudits: 1,
+		},
+		{
+			description:    "override_endpoint",
+			method:         http.MethodPost,
+			path:           "/admin/bidders/appnexus",
+			body:           `{"endpoint":"https://new.appnexus.com"}`,
+			token:          "t1",
+			expectedCode:   http.StatusOK,
+			expectedBody:   `{"version":1,"updated_at":"2026-01-01T00:00:00Z","overrides":{"appnexus":{"endpoint":"https://new.appnexus.com"}}}`,
+			expectedAudits: 1,
+		},
+		{
+			description:    "reset",
+			method:         http.MethodDelete,
+			path:           "/admin/bidders/appnexus",
+			token:          "t1",
+			expectedCode:   http.StatusOK,
+			expectedBody:   `{"version":1,"updated_at":"2026-01-01T00:00:00Z","overrides":{}}`,
+			expectedAudits: 1,
+		},
+		{
+			description:  "unknown_field",
+			method:       http.MethodPost,
+			path:         "/admin/bidders/appnexus",
+			body:         `{"enabled":false}`,
+			token:        "t1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "empty_override",
+			method:       http.MethodPost,
+			path:         "/admin/bidders/appnexus",
+			body:         `{}`,
+			token:        "t1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "unknown_bidder",
+			method:       http.MethodPost,
+			path:         "/admin/bidders/notabidder",
+			body:         `{"disabled":true}`,
+			token:        "t1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "enable_bidder_disabled_in_config",
+			method:       http.MethodPost,
+			path:         "/admin/bidders/rubicon",
+			body:         `{"disabled":false}`,
+			token:        "t1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "put_not_allowed",
+			method:       http.MethodPut,
+			path:         "/admin/bidders/appnexus",
+			body:         `{"disabled":true}`,
+			token:        "t1",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			auditLog := &fakeAdminAuditLog{}
+			handler := NewAdminBiddersEndpoint(newAdminTestStore(auditLog), []string{"t1", "t2"})
+
+			w := httptest.NewRecorder()
+			handler(w, newAdminRequest(test.method, test.path, test.body, test.token))
+
+			assert.Equal(t, test.expectedCode, w.Code, w.Body.String())
+			if test.expectedBody != "" {
+				assert.JSONEq(t, test.expectedBody, w.Body.String())
+			}
+			assert.Len(t, auditLog.entries, test.expectedAudits)
+		})
+	}
+}
+
+func TestAdminBiddersEndpointActor(t *testing.T) {
+	auditLog := &fakeAdminAuditLog{}
+	handler := NewAdminBiddersEndpoint(newAdminTestStore(auditLog), []string{"t1", "t2"})
+
+	handler(httptest.NewRecorder(), newAdminRequest(http.MethodPost, "/admin/bidders/appnexus", `{"disabled":true}`, "t2"))
+
+	require.Len(t, auditLog.entries, 1)
+	assert.Equal(t, "token[1]@10.0.0.1:1234", auditLog.entries[0].Actor)
+}
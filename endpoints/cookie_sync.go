@@ -21,6 +21,8 @@ import (
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/errortypes"
 	"github.com/prebid/prebid-server/v3/gdpr"
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookexecution"
 	"github.com/prebid/prebid-server/v3/macros"
 	"github.com/prebid/prebid-server/v3/metrics"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
@@ -58,7 +60,8 @@ func NewCookieSyncEndpoint(
 	metrics metrics.MetricsEngine,
 	analyticsRunner analytics.Runner,
 	accountsFetcher stored_requests.AccountFetcher,
-	bidders map[string]openrtb_ext.BidderName) HTTPRouterHandler {
+	bidders map[string]openrtb_ext.BidderName,
+	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder) HTTPRouterHandler {
 
 	bidderHashSet := make(map[string]struct{}, len(bidders))
 	for _, bidder := range bidders {
@@ -75,21 +78,23 @@ func NewCookieSyncEndpoint(
 			ccpaEnforce:            config.CCPA.Enforce,
 			bidderHashSet:          bidderHashSet,
 		},
-		metrics:         metrics,
-		pbsAnalytics:    analyticsRunner,
-		accountsFetcher: accountsFetcher,
-		time:            &timeutil.RealTime{},
+		metrics:                  metrics,
+		pbsAnalytics:             analyticsRunner,
+		accountsFetcher:          accountsFetcher,
+		time:                     &timeutil.RealTime{},
+		hookExecutionPlanBuilder: hookExecutionPlanBuilder,
 	}
 }
 
 type cookieSyncEndpoint struct {
-	chooser         usersync.Chooser
-	config          *config.Configuration
-	privacyConfig   usersyncPrivacyConfig
-	metrics         metrics.MetricsEngine
-	pbsAnalytics    analytics.Runner
-	accountsFetcher stored_requests.AccountFetcher
-	time            timeutil.Time
+	chooser                  usersync.Chooser
+	config                   *config.Configuration
+	privacyConfig            usersyncPrivacyConfig
+	metrics                  metrics.MetricsEngine
+	pbsAnalytics             analytics.Runner
+	accountsFetcher          stored_requests.AccountFetcher
+	time                     timeutil.Time
+	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder
 }
 
 func (c *cookieSyncEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -115,9 +120,21 @@ func (c *cookieSyncEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ ht
 		c.metrics.RecordCookieSync(metrics.CookieSyncGDPRHostCookieBlocked)
 		c.handleResponse(w, request.SyncTypeFilter, cookie, privacyMacros, nil, result.BiddersEvaluated, request.Debug)
 	case usersync.StatusOK:
-		c.metrics.RecordCookieSync(metrics.CookieSyncOK)
 		c.writeSyncerMetrics(result.BiddersEvaluated)
-		c.handleResponse(w, request.SyncTypeFilter, cookie, privacyMacros, result.SyncersChosen, result.BiddersEvaluated, request.Debug)
+
+		hookExecutor := hookexecution.NewHookExecutor(c.hookExecutionPlanBuilder, hookexecution.EndpointCookieSync, c.metrics)
+		hookExecutor.SetAccount(account)
+		hookExecutor.SetActivityControl(privacy.NewActivityControl(&account.Privacy))
+
+		syncersChosen, rejectErr := hookExecutor.ExecuteCookieSyncStage(request, result.SyncersChosen)
+		if rejectErr != nil {
+			c.metrics.RecordCookieSync(metrics.CookieSyncRejectedByHook)
+			c.handleResponse(w, request.SyncTypeFilter, cookie, privacyMacros, nil, result.BiddersEvaluated, request.Debug)
+			return
+		}
+
+		c.metrics.RecordCookieSync(metrics.CookieSyncOK)
+		c.handleResponse(w, request.SyncTypeFilter, cookie, privacyMacros, syncersChosen, result.BiddersEvaluated, request.Debug)
 	}
 }
 
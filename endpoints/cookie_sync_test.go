@@ -19,6 +19,8 @@ import (
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/errortypes"
 	"github.com/prebid/prebid-server/v3/gdpr"
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
 	"github.com/prebid/prebid-server/v3/macros"
 	"github.com/prebid/prebid-server/v3/metrics"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
@@ -75,6 +77,7 @@ func TestNewCookieSyncEndpoint(t *testing.T) {
 		&analytics,
 		&fetcher,
 		bidders,
+		hooks.EmptyPlanBuilder{},
 	)
 	result := endpoint.(*cookieSyncEndpoint)
 
@@ -377,10 +380,11 @@ func TestCookieSyncHandle(t *testing.T) {
 				tcf2ConfigBuilder:      tcf2ConfigBuilder,
 				ccpaEnforce:            true,
 			},
-			metrics:         &mockMetrics,
-			pbsAnalytics:    &mockAnalytics,
-			accountsFetcher: &fakeAccountFetcher,
-			time:            &fakeTime{time: time.Date(2024, 2, 22, 9, 42, 4, 13, time.UTC)},
+			metrics:                  &mockMetrics,
+			pbsAnalytics:             &mockAnalytics,
+			accountsFetcher:          &fakeAccountFetcher,
+			time:                     &fakeTime{time: time.Date(2024, 2, 22, 9, 42, 4, 13, time.UTC)},
+			hookExecutionPlanBuilder: hooks.EmptyPlanBuilder{},
 		}
 		assert.NoError(t, endpoint.config.MarshalAccountDefaults())
 
@@ -1684,6 +1688,78 @@ func TestParseBidderFilter(t *testing.T) {
 	}
 }
 
+func TestCookieSyncHandleHooks(t *testing.T) {
+	syncTypeExpected := []usersync.SyncType{usersync.SyncTypeIFrame, usersync.SyncTypeRedirect}
+	syncer := MockSyncer{}
+	syncer.On("GetSync", syncTypeExpected, macros.UserSyncPrivacy{}).Return(usersync.Sync{URL: "aURL", Type: usersync.SyncTypeRedirect}, nil).Maybe()
+
+	chooserResult := usersync.Result{
+		Status: usersync.StatusOK,
+		BiddersEvaluated: []usersync.BidderEvaluation{
+			{Bidder: "a", SyncerKey: "aSyncer", Status: usersync.StatusOK},
+			{Bidder: "b", SyncerKey: "bSyncer", Status: usersync.StatusOK},
+		},
+		SyncersChosen: []usersync.SyncerChoice{{Bidder: "a", Syncer: &syncer}, {Bidder: "b", Syncer: &syncer}},
+	}
+
+	testCases := []struct {
+		description    string
+		givenHook      fakeUserSyncHook
+		expectedStatus metrics.CookieSyncStatus
+		expectedBody   string
+	}{
+		{
+			description:    "hook-filters-syncers",
+			givenHook:      fakeUserSyncHook{},
+			expectedStatus: metrics.CookieSyncOK,
+			expectedBody:   `{"status":"no_cookie","bidder_status":[{"bidder":"a","no_cookie":true,"usersync":{"url":"aURL","type":"redirect"}}]}` + "\n",
+		},
+		{
+			description:    "hook-rejects",
+			givenHook:      fakeUserSyncHook{reject: true},
+			expectedStatus: metrics.CookieSyncRejectedByHook,
+			expectedBody:   `{"status":"no_cookie","bidder_status":[]}` + "\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			mockMetrics := metrics.MetricsEngineMock{}
+			mockMetrics.On("RecordCookieSync", test.expectedStatus).Once()
+			mockMetrics.On("RecordSyncerRequest", mock.Anything, metrics.SyncerCookieSyncOK)
+			mockMetrics.On("RecordModuleCalled", mock.Anything, mock.Anything)
+			mockMetrics.On("RecordModuleSuccessUpdated", mock.Anything).Maybe()
+			mockMetrics.On("RecordModuleSuccessRejected", mock.Anything).Maybe()
+
+			mockAnalytics := MockAnalyticsRunner{}
+			mockAnalytics.On("LogCookieSyncObject", mock.Anything).Once()
+
+			endpoint := cookieSyncEndpoint{
+				chooser: FakeChooser{Result: chooserResult},
+				config:  &config.Configuration{},
+				privacyConfig: usersyncPrivacyConfig{
+					gdprConfig:             config.GDPR{Enabled: true, DefaultValue: "0"},
+					gdprPermissionsBuilder: fakePermissionsBuilder{permissions: &fakePermissions{}}.Builder,
+					tcf2ConfigBuilder:      fakeTCF2ConfigBuilder{cfg: gdpr.NewTCF2Config(config.TCF2{}, config.AccountGDPR{})}.Builder,
+				},
+				metrics:                  &mockMetrics,
+				pbsAnalytics:             &mockAnalytics,
+				accountsFetcher:          FakeAccountsFetcher{},
+				time:                     &fakeTime{time: time.Now()},
+				hookExecutionPlanBuilder: fakeUserSyncPlanBuilder{hook: test.givenHook},
+			}
+			assert.NoError(t, endpoint.config.MarshalAccountDefaults())
+
+			writer := httptest.NewRecorder()
+			endpoint.Handle(writer, httptest.NewRequest("POST", "/cookiesync", strings.NewReader(`{}`)), nil)
+
+			assert.Equal(t, http.StatusOK, writer.Code)
+			assert.Equal(t, test.expectedBody, writer.Body.String())
+			mockMetrics.AssertExpectations(t)
+		})
+	}
+}
+
 func TestCookieSyncHandleError(t *testing.T) {
 	err := errors.New("anyError")
 
@@ -2240,6 +2316,60 @@ func TestCombineErrors(t *testing.T) {
 	}
 }
 
+// fakeUserSyncHook removes the syncer of bidder "b" and upper cases UIDs, or rejects everything
+type fakeUserSyncHook struct {
+	reject bool
+}
+
+func (h fakeUserSyncHook) HandleCookieSyncHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.CookieSyncPayload) (hookstage.HookResult[hookstage.CookieSyncPayload], error) {
+	if h.reject {
+		return hookstage.HookResult[hookstage.CookieSyncPayload]{Reject: true}, nil
+	}
+
+	changeSet := hookstage.ChangeSet[hookstage.CookieSyncPayload]{}
+	changeSet.AddMutation(func(payload hookstage.CookieSyncPayload) (hookstage.CookieSyncPayload, error) {
+		var syncers []usersync.SyncerChoice
+		for _, syncer := range payload.Syncers {
+			if syncer.Bidder != "b" {
+				syncers = append(syncers, syncer)
+			}
+		}
+		payload.Syncers = syncers
+		return payload, nil
+	}, hookstage.MutationDelete, "syncers")
+	return hookstage.HookResult[hookstage.CookieSyncPayload]{ChangeSet: changeSet}, nil
+}
+
+func (h fakeUserSyncHook) HandleSetUIDHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.SetUIDPayload) (hookstage.HookResult[hookstage.SetUIDPayload], error) {
+	if h.reject {
+		return hookstage.HookResult[hookstage.SetUIDPayload]{Reject: true}, nil
+	}
+
+	changeSet := hookstage.ChangeSet[hookstage.SetUIDPayload]{}
+	changeSet.AddMutation(func(payload hookstage.SetUIDPayload) (hookstage.SetUIDPayload, error) {
+		payload.UID = strings.ToUpper(payload.UID)
+		return payload, nil
+	}, hookstage.MutationUpdate, "uid")
+	return hookstage.HookResult[hookstage.SetUIDPayload]{ChangeSet: changeSet}, nil
+}
+
+type fakeUserSyncPlanBuilder struct {
+	hooks.EmptyPlanBuilder
+	hook fakeUserSyncHook
+}
+
+func (b fakeUserSyncPlanBuilder) PlanForCookieSyncStage(_ string, _ *config.Account) hooks.Plan[hookstage.CookieSync] {
+	return hooks.Plan[hookstage.CookieSync]{
+		{Timeout: time.Second, Hooks: []hooks.HookWrapper[hookstage.CookieSync]{{Module: "foobar", Code: "foo", Hook: b.hook}}},
+	}
+}
+
+func (b fakeUserSyncPlanBuilder) PlanForSetUIDStage(_ string, _ *config.Account) hooks.Plan[hookstage.SetUID] {
+	return hooks.Plan[hookstage.SetUID]{
+		{Timeout: time.Second, Hooks: []hooks.HookWrapper[hookstage.SetUID]{{Module: "foobar", Code: "foo", Hook: b.hook}}},
+	}
+}
+
 type FakeChooser struct {
 	Result usersync.Result
 }
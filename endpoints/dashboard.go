@@ -0,0 +1,119 @@
+package endpoints
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/metrics/dashboard"
+)
+
+type dashboardReporter interface {
+	Report() dashboard.Report
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"percent": func(rate float64) string { return fmt.Sprintf("%.1f%%", rate*100) },
+	"errors":  formatCounts[string],
+	"nonbids": formatCounts[int],
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="10">
+<title>Prebid Server dashboard</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th:first-child, td:first-child, td.counts { text-align: left; }
+</style>
+</head>
+<body>
+<p>Last {{.WindowSeconds}} seconds, from {{.From.Format "2006-01-02 15:04:05 MST"}} to {{.To.Format "2006-01-02 15:04:05 MST"}}.</p>
+{{range .Tables}}
+<h2>{{.Title}}</h2>
+<table>
+<tr><th>Name</th><th>Requests</th><th>Bid rate</th><th>Timeout rate</th><th>Errors</th><th>p50 (ms)</th><th>p95 (ms)</th><th>p99 (ms)</th><th>Bids</th><th>Average CPM</th><th>Seat non bids</th></tr>
+{{range .Stats}}<tr><td>{{.Name}}</td><td>{{.Requests}}</td><td>{{percent .BidRate}}</td><td>{{percent .TimeoutRate}}</td><td class="counts">{{errors .Errors}}</td><td>{{.LatencyP50}}</td><td>{{.LatencyP95}}</td><td>{{.LatencyP99}}</td><td>{{.Bids}}</td><td>{{printf "%.3f" .AverageCPM}}</td><td class="counts">{{nonbids .NonBids}}</td></tr>
+{{else}}<tr><td colspan="11">No requests</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+type dashboardTable struct {
+	Title string
+	Stats []dashboard.Stats
+}
+
+// NewDashboardEndpoint returns the recent stats of the bidders and accounts: request count, bid and timeout rates,
+// errors, latency percentiles, average CPM and seat non bids.
+//
+// The stats are served as an HTML table to browsers or with the format=html query param, and as JSON otherwise.
+// The requests must have one of the admin tokens, as the stats break down the traffic by account.
+func NewDashboardEndpoint(reporter dashboardReporter, tokens []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := authorizeAdmin(w, r, tokens); !ok {
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		report := reporter.Report()
+
+		format := r.URL.Query().Get("format")
+		if format == "" && strings.Contains(r.Header.Get("Accept"), "text/html") {
+			format = "html"
+		}
+		if format != "html" {
+			writeAdminJSON(w, "/dashboard", report)
+			return
+		}
+
+		data := struct {
+			dashboard.Report
+			Tables []dashboardTable
+		}{
+			Report: report,
+			Tables: []dashboardTable{{Title: "Bidders", Stats: report.Bidders}, {Title: "Accounts", Stats: report.Accounts}},
+		}
+		var html strings.Builder
+		if err := dashboardTemplate.Execute(&html, data); err != nil {
+			glog.Errorf("/dashboard Critical error when trying to render the dashboard: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(html.String()))
+	}
+}
+
+// formatCounts formats the counts of the errors or non bids, sorted by decreasing count.
+func formatCounts[K comparable](counts map[K]int) string {
+	type entry struct {
+		key   string
+		count int
+	}
+	entries := make([]entry, 0, len(counts))
+	for key, count := range counts {
+		entries = append(entries, entry{key: fmt.Sprint(key), count: count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return entries[i].key < entries[j].key
+	})
+	formatted := make([]string, len(entries))
+	for i, e := range entries {
+		formatted[i] = fmt.Sprintf("%s: %d", e.key, e.count)
+	}
+	return strings.Join(formatted, ", ")
+}
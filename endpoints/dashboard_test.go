@@ -0,0 +1,129 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/metrics/dashboard"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDashboardReporter struct {
+	report dashboard.Report
+}
+
+func (r fakeDashboardReporter) Report() dashboard.Report {
+	return r.report
+}
+
+func TestDashboardEndpoint(t *testing.T) {
+	reporter := fakeDashboardReporter{report: dashboard.Report{
+		WindowSeconds: 300,
+		From:          time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:            time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC),
+		Bidders: []dashboard.Stats{{
+			Name:       "appnexus",
+			Requests:   4,
+			BidRate:    0.75,
+			Errors:     map[string]int{"timeout": 1, "badinput": 3},
+			LatencyP50: 20,
+			Bids:       2,
+			AverageCPM: 1.5,
+			NonBids:    map[int]int{301: 2},
+		}},
+	}}
+	handler := NewDashboardEndpoint(reporter, []string{"token1", "token2"})
+
+	testCases := []struct {
+		description         string
+		method              string
+		path                string
+		token               string
+		accept              string
+		expectedCode        int
+		expectedContentType string
+		expectedBody        []string
+	}{
+		{
+			description:         "json",
+			method:              http.MethodGet,
+			path:                "/dashboard",
+			token:               "token2",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        []string{`"window_seconds":300`, `"name":"appnexus"`, `"bid_rate":0.75`, `"nonbids":{"301":2}`},
+		},
+		{
+			description:         "html_format",
+			method:              http.MethodGet,
+			path:                "/dashboard?format=html",
+			token:               "token1",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        []string{"<td>appnexus</td>", "<td>75.0%</td>", "badinput: 3, timeout: 1", "301: 2", "<td>1.500</td>", "No requests"},
+		},
+		{
+			description:         "html_accept",
+			method:              http.MethodGet,
+			path:                "/dashboard",
+			token:               "token1",
+			accept:              "text/html,application/xhtml+xml",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        []string{"<td>appnexus</td>"},
+		},
+		{
+			description:         "json_format_overrides_accept",
+			method:              http.MethodGet,
+			path:                "/dashboard?format=json",
+			token:               "token1",
+			accept:              "text/html",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+		},
+		{
+			description:  "post_not_allowed",
+			method:       http.MethodPost,
+			path:         "/dashboard",
+			token:        "token1",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			description:  "no_token",
+			method:       http.MethodGet,
+			path:         "/dashboard",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			description:  "wrong_token",
+			method:       http.MethodGet,
+			path:         "/dashboard",
+			token:        "token3",
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, nil)
+			if test.token != "" {
+				req.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			assert.Equal(t, test.expectedCode, w.Code)
+			if test.expectedContentType != "" {
+				assert.Equal(t, test.expectedContentType, w.Header().Get("Content-Type"))
+			}
+			for _, expected := range test.expectedBody {
+				assert.Contains(t, w.Body.String(), expected)
+			}
+		})
+	}
+}
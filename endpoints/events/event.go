@@ -12,11 +12,15 @@ import (
 
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 
+	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
 	accountService "github.com/prebid/prebid-server/v3/account"
 	"github.com/prebid/prebid-server/v3/analytics"
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
 	"github.com/prebid/prebid-server/v3/metrics"
 	"github.com/prebid/prebid-server/v3/privacy"
 	"github.com/prebid/prebid-server/v3/stored_requests"
@@ -47,21 +51,33 @@ type eventEndpoint struct {
 	Cfg           *config.Configuration
 	TrackingPixel *httputil.Pixel
 	MetricsEngine metrics.MetricsEngine
+	Signer        *URLSigner
+	PlanBuilder   hooks.ExecutionPlanBuilder
 }
 
-func NewEventEndpoint(cfg *config.Configuration, accounts stored_requests.AccountFetcher, analytics analytics.Runner, me metrics.MetricsEngine) httprouter.Handle {
+func NewEventEndpoint(cfg *config.Configuration, accounts stored_requests.AccountFetcher, analytics analytics.Runner, me metrics.MetricsEngine, signer *URLSigner, planBuilder hooks.ExecutionPlanBuilder) httprouter.Handle {
 	ee := &eventEndpoint{
 		Accounts:      accounts,
 		Analytics:     analytics,
 		Cfg:           cfg,
 		TrackingPixel: &httputil.Pixel1x1PNG,
 		MetricsEngine: me,
+		Signer:        signer,
+		PlanBuilder:   planBuilder,
 	}
 
 	return ee.Handle
 }
 
 func (e *eventEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	// verify the signature and decrypt the event parameters
+	r, unverified, err := e.verifySignature(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, "invalid request: %s\n", err.Error())
+		return
+	}
+
 	// parse event request from http req
 	eventRequest, errs := ParseEventRequest(r)
 
@@ -85,6 +101,7 @@ func (e *eventEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httprou
 		return
 	}
 	eventRequest.AccountID = accountId
+	eventRequest.Unverified = unverified
 
 	if eventRequest.Analytics != analytics.Enabled {
 		w.WriteHeader(http.StatusNoContent)
@@ -119,11 +136,21 @@ func (e *eventEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httprou
 
 	activities := privacy.NewActivityControl(&account.Privacy)
 
-	// handle notification event
-	e.Analytics.LogNotificationEventObject(&analytics.NotificationEvent{
-		Request: eventRequest,
-		Account: account,
-	}, activities)
+	hookExecutor := hookexecution.NewHookExecutor(e.PlanBuilder, hookexecution.EndpointEvent, e.MetricsEngine)
+	hookExecutor.SetAccount(account)
+	hookExecutor.SetActivityControl(activities)
+
+	// rejected events are not sent to analytics, the response to the client stays the same
+	event, rejectErr := hookExecutor.ExecuteEventStage(toEventPayload(eventRequest))
+	if rejectErr == nil {
+		applyEventPayload(eventRequest, event)
+
+		// handle notification event
+		e.Analytics.LogNotificationEventObject(&analytics.NotificationEvent{
+			Request: eventRequest,
+			Account: account,
+		}, activities)
+	}
 
 	// Add tracking pixel if format == image
 	if eventRequest.Format == analytics.Image {
@@ -137,8 +164,71 @@ func (e *eventEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httprou
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// EventRequestToUrl converts an analytics.EventRequest to an URL
-func EventRequestToUrl(externalUrl string, request *analytics.EventRequest) string {
+// toEventPayload returns the event parameters hooks are allowed to see and modify
+func toEventPayload(er *analytics.EventRequest) hookstage.EventPayload {
+	return hookstage.EventPayload{
+		Type:        string(er.Type),
+		VType:       string(er.VType),
+		BidID:       er.BidID,
+		Bidder:      er.Bidder,
+		Timestamp:   er.Timestamp,
+		Integration: er.Integration,
+	}
+}
+
+// applyEventPayload updates the event request with the parameters returned by hooks
+func applyEventPayload(er *analytics.EventRequest, payload hookstage.EventPayload) {
+	er.Type = analytics.EventType(payload.Type)
+	er.VType = analytics.VastType(payload.VType)
+	er.BidID = payload.BidID
+	er.Bidder = payload.Bidder
+	er.Timestamp = payload.Timestamp
+	er.Integration = payload.Integration
+}
+
+// verifySignature returns the request with verified and decrypted parameters. Events without a valid
+// signature are rejected with an error or flagged as unverified, depending on the configuration.
+func (e *eventEndpoint) verifySignature(r *http.Request) (*http.Request, bool, error) {
+	if e.Signer == nil {
+		return r, false, nil
+	}
+
+	params, err := e.Signer.Verify(r.URL.Query())
+	if err != nil {
+		status := metrics.EventSignatureInvalid
+		if errors.Is(err, errUnsignedEvent) {
+			status = metrics.EventSignatureUnsigned
+		} else if errors.Is(err, errExpiredSignature) {
+			status = metrics.EventSignatureExpired
+		}
+		e.MetricsEngine.RecordEventSignatureFailure(status)
+
+		if e.Signer.RejectUnsigned() {
+			return r, false, err
+		}
+		return r, true, nil
+	}
+
+	verified := r.Clone(r.Context())
+	verified.URL.RawQuery = params.Encode()
+	return verified, false, nil
+}
+
+// EventRequestToUrl converts an analytics.EventRequest to an URL, signed when a signer is provided
+func EventRequestToUrl(externalUrl string, request *analytics.EventRequest, signer *URLSigner) string {
+	if signer != nil {
+		params := optionalParameterValues(request)
+		params.Set(TypeParameter, string(request.Type))
+		params.Set(BidIdParameter, request.BidID)
+		params.Set(AccountIdParameter, request.AccountID)
+
+		query, err := signer.Sign(params)
+		if err == nil {
+			return externalUrl + "/event?" + query
+		}
+		glog.Errorf("Unable to sign event URL: %v", err)
+	}
+
 	s := fmt.Sprintf(TemplateUrl, externalUrl, request.Type, request.BidID, request.AccountID)
 
 	return s + optionalParameters(request)
@@ -231,6 +321,16 @@ func HandleAccountServiceErrors(errs []error) (status int, messages []string) {
 }
 
 func optionalParameters(request *analytics.EventRequest) string {
+	opt := optionalParameterValues(request).Encode()
+
+	if opt != "" {
+		return "&" + opt
+	}
+
+	return opt
+}
+
+func optionalParameterValues(request *analytics.EventRequest) url.Values {
 	r := url.Values{}
 
 	// timestamp
@@ -263,13 +363,7 @@ func optionalParameters(request *analytics.EventRequest) string {
 		r.Add(IntegrationTypeParameter, request.Integration)
 	}
 
-	opt := r.Encode()
-
-	if opt != "" {
-		return "&" + opt
-	}
-
-	return opt
+	return r
 }
 
 // readType validates analytics.EventRequest type
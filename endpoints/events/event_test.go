@@ -15,7 +15,10 @@ import (
 	"github.com/prebid/prebid-server/v3/analytics"
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
 	"github.com/prebid/prebid-server/v3/metrics"
+	metricsConfig "github.com/prebid/prebid-server/v3/metrics/config"
 	"github.com/prebid/prebid-server/v3/privacy"
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/stretchr/testify/assert"
@@ -25,6 +28,7 @@ type eventsMockAnalyticsModule struct {
 	Fail    bool
 	Error   error
 	Invoked bool
+	Event   *analytics.NotificationEvent
 }
 
 func (e *eventsMockAnalyticsModule) LogAuctionObject(ao *analytics.AuctionObject, _ privacy.ActivityControl) {
@@ -62,6 +66,7 @@ func (e *eventsMockAnalyticsModule) LogNotificationEventObject(ne *analytics.Not
 		panic(e.Error)
 	}
 	e.Invoked = true
+	e.Event = ne
 }
 
 func (e *eventsMockAnalyticsModule) Shutdown() {}
@@ -123,7 +128,7 @@ func TestShouldReturnBadRequestWhenTypeIsMissing(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?b=test", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -159,7 +164,7 @@ func TestShouldReturnBadRequestWhenTypeIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=test&b=t", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccounts, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccounts, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -195,7 +200,7 @@ func TestShouldReturnBadRequestWhenBidIdIsMissing(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -231,7 +236,7 @@ func TestShouldReturnBadRequestWhenTimestampIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=q", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -267,7 +272,7 @@ func TestShouldReturnUnauthorizedWhenAccountIsMissing(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -303,7 +308,7 @@ func TestShouldReturnBadRequestWhenFormatValueIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=q", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -339,7 +344,7 @@ func TestShouldReturnBadRequestWhenAnalyticsValueIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=4", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -378,7 +383,7 @@ func TestShouldNotPassEventToAnalyticsReporterWhenAccountNotFoundAndDefaultIsFal
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=1&a=testacc", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -412,7 +417,7 @@ func TestShouldReturnBadRequestWhenIntegrationValueIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=bidId&f=b&ts=1000&x=1&a=accountId&bidder=bidder&int=Te$tIntegrationType", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -451,7 +456,7 @@ func TestShouldNotPassEventToAnalyticsReporterWhenAccountEventNotEnabled(t *test
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=1&a=events_disabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -489,7 +494,7 @@ func TestShouldPassEventToAnalyticsReporterWhenAccountEventEnabled(t *testing.T)
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=1&a=events_enabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -523,7 +528,7 @@ func TestShouldNotPassEventToAnalyticsReporterWhenAnalyticsValueIsZero(t *testin
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=0&a=events_enabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -557,7 +562,7 @@ func TestShouldRespondWithPixelAndContentTypeWhenRequestFormatIsImage(t *testing
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=i&x=1&a=events_enabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -598,7 +603,7 @@ func TestShouldRespondWithNoContentWhenRequestFormatIsNotDefined(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=imp&b=test&ts=1234&x=1&a=events_enabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 
 	// execute
 	e(recorder, req, nil)
@@ -681,6 +686,81 @@ func TestShouldParseEventCorrectly(t *testing.T) {
 	}
 }
 
+func TestEventSignatureVerification(t *testing.T) {
+	signingKey := config.EventSigningKey{ID: "k1", Secret: "0123456789abcdef"}
+	eventRequest := &analytics.EventRequest{
+		Type:      analytics.Win,
+		BidID:     "bid1",
+		AccountID: "events_enabled",
+		Format:    analytics.Blank,
+		Analytics: analytics.Enabled,
+	}
+
+	testCases := []struct {
+		name               string
+		unsignedEvents     string
+		signed             bool
+		expectedStatus     int
+		expectedMetric     metrics.EventSignatureStatus
+		expectedInvoked    bool
+		expectedUnverified bool
+	}{
+		{
+			name:            "signed",
+			unsignedEvents:  config.EventSigningReject,
+			signed:          true,
+			expectedStatus:  http.StatusNoContent,
+			expectedInvoked: true,
+		},
+		{
+			name:           "unsigned-rejected",
+			unsignedEvents: config.EventSigningReject,
+			expectedStatus: http.StatusUnauthorized,
+			expectedMetric: metrics.EventSignatureUnsigned,
+		},
+		{
+			name:               "unsigned-flagged",
+			unsignedEvents:     config.EventSigningFlag,
+			expectedStatus:     http.StatusNoContent,
+			expectedMetric:     metrics.EventSignatureUnsigned,
+			expectedInvoked:    true,
+			expectedUnverified: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			signer, err := NewURLSigner(config.EventSigning{Enabled: true, MaxAgeSeconds: 60, UnsignedEvents: test.unsignedEvents, Keys: []config.EventSigningKey{signingKey}})
+			assert.NoError(t, err)
+
+			eventURL := EventRequestToUrl("http://localhost:8000", eventRequest, nil)
+			if test.signed {
+				eventURL = EventRequestToUrl("http://localhost:8000", eventRequest, signer)
+			}
+
+			metricsEngine := &metrics.MetricsEngineMock{}
+			if test.expectedMetric != "" {
+				metricsEngine.On("RecordEventSignatureFailure", test.expectedMetric).Return()
+			}
+			mockAnalyticsModule := &eventsMockAnalyticsModule{}
+			cfg := &config.Configuration{AccountDefaults: config.Account{}}
+			cfg.MarshalAccountDefaults()
+
+			recorder := httptest.NewRecorder()
+			e := NewEventEndpoint(cfg, &mockAccountsFetcher{}, mockAnalyticsModule, metricsEngine, signer, hooks.EmptyPlanBuilder{})
+			e(recorder, httptest.NewRequest("GET", eventURL, nil), nil)
+
+			assert.Equal(t, test.expectedStatus, recorder.Result().StatusCode)
+			assert.Equal(t, test.expectedInvoked, mockAnalyticsModule.Invoked)
+			if test.expectedInvoked {
+				assert.Equal(t, "bid1", mockAnalyticsModule.Event.Request.BidID)
+				assert.Equal(t, test.expectedUnverified, mockAnalyticsModule.Event.Request.Unverified)
+			}
+			metricsEngine.AssertExpectations(t)
+		})
+	}
+}
+
 func TestEventRequestToUrl(t *testing.T) {
 	externalUrl := "http://localhost:8000"
 	tests := map[string]struct {
@@ -728,7 +808,7 @@ func TestEventRequestToUrl(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			expected := EventRequestToUrl(externalUrl, test.er)
+			expected := EventRequestToUrl(externalUrl, test.er, nil)
 			// validate
 			assert.Equal(t, test.want, expected)
 		})
@@ -815,7 +895,7 @@ func TestShouldReturnBadRequestWhenVTypeIsInvalid(t *testing.T) {
 
 		recorder := httptest.NewRecorder()
 
-		e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+		e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil, hooks.EmptyPlanBuilder{})
 		e(recorder, test.req, nil)
 
 		d, err := io.ReadAll(recorder.Result().Body)
@@ -915,3 +995,69 @@ func TestReadVType(t *testing.T) {
 		})
 	}
 }
+
+func TestEventHooks(t *testing.T) {
+	testCases := []struct {
+		name            string
+		hook            fakeEventHook
+		expectedInvoked bool
+	}{
+		{
+			name:            "hook-updates-event",
+			hook:            fakeEventHook{},
+			expectedInvoked: true,
+		},
+		{
+			name: "hook-rejects",
+			hook: fakeEventHook{reject: true},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			mockAnalyticsModule := &eventsMockAnalyticsModule{}
+			cfg := &config.Configuration{AccountDefaults: config.Account{}}
+			cfg.MarshalAccountDefaults()
+
+			recorder := httptest.NewRecorder()
+			e := NewEventEndpoint(cfg, &mockAccountsFetcher{}, mockAnalyticsModule, &metricsConfig.NilMetricsEngine{}, nil, fakeEventPlanBuilder{hook: test.hook})
+			e(recorder, httptest.NewRequest("GET", "/event?t=win&b=bid1&a=events_enabled&bidder=Appnexus", nil), nil)
+
+			assert.Equal(t, http.StatusNoContent, recorder.Result().StatusCode, "the response doesn't depend on hooks")
+			assert.Equal(t, test.expectedInvoked, mockAnalyticsModule.Invoked)
+			if test.expectedInvoked {
+				assert.Equal(t, "appnexus", mockAnalyticsModule.Event.Request.Bidder)
+				assert.Equal(t, "bid1", mockAnalyticsModule.Event.Request.BidID)
+			}
+		})
+	}
+}
+
+// fakeEventHook lower cases the bidder of events or rejects them
+type fakeEventHook struct {
+	reject bool
+}
+
+func (h fakeEventHook) HandleEventHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.EventPayload) (hookstage.HookResult[hookstage.EventPayload], error) {
+	if h.reject {
+		return hookstage.HookResult[hookstage.EventPayload]{Reject: true}, nil
+	}
+
+	changeSet := hookstage.ChangeSet[hookstage.EventPayload]{}
+	changeSet.AddMutation(func(payload hookstage.EventPayload) (hookstage.EventPayload, error) {
+		payload.Bidder = strings.ToLower(payload.Bidder)
+		return payload, nil
+	}, hookstage.MutationUpdate, "bidder")
+	return hookstage.HookResult[hookstage.EventPayload]{ChangeSet: changeSet}, nil
+}
+
+type fakeEventPlanBuilder struct {
+	hooks.EmptyPlanBuilder
+	hook fakeEventHook
+}
+
+func (b fakeEventPlanBuilder) PlanForEventStage(_ string, _ *config.Account) hooks.Plan[hookstage.Event] {
+	return hooks.Plan[hookstage.Event]{
+		{Timeout: time.Second, Hooks: []hooks.HookWrapper[hookstage.Event]{{Module: "foobar", Code: "foo", Hook: b.hook}}},
+	}
+}
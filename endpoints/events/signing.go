@@ -0,0 +1,201 @@
+package events
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+)
+
+const (
+	// SignatureParameter holds the key id and the HMAC of the other event parameters
+	SignatureParameter = "sig"
+	// EncryptedParameter holds the key id and the AES-GCM encrypted event parameters
+	EncryptedParameter = "e"
+	// SignedAtParameter holds the time in milliseconds at which the event URL was signed
+	SignedAtParameter = "sts"
+
+	keyIDSeparator = "."
+)
+
+var (
+	errUnsignedEvent    = errors.New("event is not signed")
+	errInvalidSignature = errors.New("event signature is invalid")
+	errExpiredSignature = errors.New("event signature is expired")
+)
+
+// URLSigner signs, and optionally encrypts, the parameters of the event URLs Prebid Server generates
+// and verifies them when the events are received. A nil URLSigner leaves the URLs untouched.
+type URLSigner struct {
+	keys    []urlSigningKey
+	byID    map[string]urlSigningKey
+	encrypt bool
+	reject  bool
+	maxAge  time.Duration
+	time    timeutil.Time
+}
+
+type urlSigningKey struct {
+	id     string
+	secret []byte
+	aead   cipher.AEAD
+}
+
+// NewURLSigner builds a URLSigner from the event signing configuration. It returns nil when signing is disabled.
+func NewURLSigner(cfg config.EventSigning) (*URLSigner, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("event signing requires at least one key")
+	}
+
+	signer := &URLSigner{
+		byID:    make(map[string]urlSigningKey, len(cfg.Keys)),
+		encrypt: cfg.Encrypt,
+		reject:  cfg.UnsignedEvents == config.EventSigningReject,
+		maxAge:  time.Duration(cfg.MaxAgeSeconds) * time.Second,
+		time:    &timeutil.RealTime{},
+	}
+	for _, keyCfg := range cfg.Keys {
+		key := urlSigningKey{
+			id:     keyCfg.ID,
+			secret: []byte(keyCfg.Secret),
+		}
+		if cfg.Encrypt {
+			aesKey, err := keyCfg.AESKey()
+			if err != nil {
+				return nil, err
+			}
+			block, err := aes.NewCipher(aesKey)
+			if err != nil {
+				return nil, err
+			}
+			if key.aead, err = cipher.NewGCM(block); err != nil {
+				return nil, err
+			}
+		}
+		signer.keys = append(signer.keys, key)
+		signer.byID[key.id] = key
+	}
+	return signer, nil
+}
+
+// RejectUnsigned returns true when events without a valid signature must be rejected rather than flagged
+func (s *URLSigner) RejectUnsigned() bool {
+	return s.reject
+}
+
+// Sign returns the query string for the event parameters, encrypted if configured, and signed with the current key.
+// The signing time is added to the signed parameters so that Verify can reject replays of old URLs.
+func (s *URLSigner) Sign(params url.Values) (string, error) {
+	key := s.keys[0]
+
+	if s.encrypt {
+		nonce := make([]byte, key.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		sealed := key.aead.Seal(nonce, nonce, []byte(params.Encode()), []byte(key.id))
+		params = url.Values{EncryptedParameter: {key.id + keyIDSeparator + base64.RawURLEncoding.EncodeToString(sealed)}}
+	} else {
+		params = cloneValues(params)
+	}
+	params.Set(SignedAtParameter, strconv.FormatInt(s.time.Now().UnixMilli(), 10))
+
+	signed := params.Encode()
+	return signed + "&" + SignatureParameter + "=" + url.QueryEscape(key.id+keyIDSeparator+key.sign(signed)), nil
+}
+
+// Verify checks the signature and the age of the event parameters and returns them decrypted and without the signature
+func (s *URLSigner) Verify(params url.Values) (url.Values, error) {
+	signature := params.Get(SignatureParameter)
+	if signature == "" {
+		return params, errUnsignedEvent
+	}
+
+	key, mac, found := s.lookup(signature)
+	if !found {
+		return params, errInvalidSignature
+	}
+
+	unsigned := make(url.Values, len(params))
+	for name, values := range params {
+		if name != SignatureParameter {
+			unsigned[name] = values
+		}
+	}
+	if !hmac.Equal([]byte(mac), []byte(key.sign(unsigned.Encode()))) {
+		return params, errInvalidSignature
+	}
+
+	signedAt, err := strconv.ParseInt(unsigned.Get(SignedAtParameter), 10, 64)
+	if err != nil {
+		return params, errInvalidSignature
+	}
+	if s.time.Now().Sub(time.UnixMilli(signedAt)) > s.maxAge {
+		return params, errExpiredSignature
+	}
+	unsigned.Del(SignedAtParameter)
+
+	encrypted := unsigned.Get(EncryptedParameter)
+	if encrypted == "" {
+		return unsigned, nil
+	}
+	return s.decrypt(encrypted)
+}
+
+func cloneValues(values url.Values) url.Values {
+	clone := make(url.Values, len(values)+1)
+	for name, value := range values {
+		clone[name] = value
+	}
+	return clone
+}
+
+func (s *URLSigner) decrypt(encrypted string) (url.Values, error) {
+	key, payload, found := s.lookup(encrypted)
+	if !found || key.aead == nil {
+		return nil, errInvalidSignature
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < key.aead.NonceSize() {
+		return nil, errInvalidSignature
+	}
+	nonce, ciphertext := sealed[:key.aead.NonceSize()], sealed[key.aead.NonceSize():]
+	plaintext, err := key.aead.Open(nil, nonce, ciphertext, []byte(key.id))
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+	params, err := url.ParseQuery(string(plaintext))
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+	return params, nil
+}
+
+// lookup splits a "keyid.value" parameter and returns the key it refers to
+func (s *URLSigner) lookup(value string) (urlSigningKey, string, bool) {
+	id, payload, found := strings.Cut(value, keyIDSeparator)
+	if !found {
+		return urlSigningKey{}, "", false
+	}
+	key, found := s.byID[id]
+	return key, payload, found
+}
+
+func (key urlSigningKey) sign(message string) string {
+	mac := hmac.New(sha256.New, key.secret)
+	mac.Write([]byte(message))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,213 @@
+package events
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	signingKeyCurrent  = config.EventSigningKey{ID: "k2", Secret: "current-secret-0123", EncryptionKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="}
+	signingKeyPrevious = config.EventSigningKey{ID: "k1", Secret: "previous-secret-0123", EncryptionKey: "MDEyMzQ1Njc4OWFiY2RlZg=="}
+)
+
+func TestNewURLSigner(t *testing.T) {
+	signer, err := NewURLSigner(config.EventSigning{})
+	assert.NoError(t, err)
+	assert.Nil(t, signer)
+
+	_, err = NewURLSigner(config.EventSigning{Enabled: true})
+	assert.EqualError(t, err, "event signing requires at least one key")
+
+	_, err = NewURLSigner(config.EventSigning{Enabled: true, MaxAgeSeconds: 60, Encrypt: true, Keys: []config.EventSigningKey{{ID: "k", Secret: "secret", EncryptionKey: "bad"}}})
+	assert.Error(t, err)
+
+	signer, err = NewURLSigner(config.EventSigning{Enabled: true, MaxAgeSeconds: 60, UnsignedEvents: config.EventSigningReject, Keys: []config.EventSigningKey{signingKeyCurrent}})
+	require.NoError(t, err)
+	assert.True(t, signer.RejectUnsigned())
+}
+
+func TestURLSignerRoundTrip(t *testing.T) {
+	params := url.Values{
+		TypeParameter:      {"win"},
+		BidIdParameter:     {"bid1"},
+		AccountIdParameter: {"account1"},
+		BidderParameter:    {"appnexus"},
+	}
+
+	for _, encrypt := range []bool{false, true} {
+		signer, err := NewURLSigner(config.EventSigning{Enabled: true, MaxAgeSeconds: 60, Encrypt: encrypt, Keys: []config.EventSigningKey{signingKeyCurrent}})
+		require.NoError(t, err)
+
+		query, err := signer.Sign(params)
+		require.NoError(t, err)
+
+		signed, err := url.ParseQuery(query)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(signed.Get(SignatureParameter), "k2."))
+		if encrypt {
+			assert.Empty(t, signed.Get(BidIdParameter), "encrypted URLs must not expose the parameters")
+			assert.True(t, strings.HasPrefix(signed.Get(EncryptedParameter), "k2."))
+		} else {
+			assert.Equal(t, "bid1", signed.Get(BidIdParameter))
+		}
+
+		verified, err := signer.Verify(signed)
+		require.NoError(t, err)
+		assert.Equal(t, params, verified)
+	}
+}
+
+func TestURLSignerKeyRotation(t *testing.T) {
+	params := url.Values{TypeParameter: {"imp"}, BidIdParameter: {"bid1"}, AccountIdParameter: {"account1"}}
+
+	previous, err := NewURLSigner(config.EventSigning{Enabled: true, MaxAgeSeconds: 60, Encrypt: true, Keys: []config.EventSigningKey{signingKeyPrevious}})
+	require.NoError(t, err)
+	query, err := previous.Sign(params)
+	require.NoError(t, err)
+	signed, err := url.ParseQuery(query)
+	require.NoError(t, err)
+
+	rotated, err := NewURLSigner(config.EventSigning{Enabled: true, MaxAgeSeconds: 60, Encrypt: true, Keys: []config.EventSigningKey{signingKeyCurrent, signingKeyPrevious}})
+	require.NoError(t, err)
+	verified, err := rotated.Verify(signed)
+	require.NoError(t, err)
+	assert.Equal(t, params, verified)
+
+	retired, err := NewURLSigner(config.EventSigning{Enabled: true, MaxAgeSeconds: 60, Encrypt: true, Keys: []config.EventSigningKey{signingKeyCurrent}})
+	require.NoError(t, err)
+	_, err = retired.Verify(signed)
+	assert.Equal(t, errInvalidSignature, err)
+}
+
+func TestURLSignerVerifyFailures(t *testing.T) {
+	signer, err := NewURLSigner(config.EventSigning{Enabled: true, MaxAgeSeconds: 60, Keys: []config.EventSigningKey{signingKeyCurrent}})
+	require.NoError(t, err)
+
+	query, err := signer.Sign(url.Values{TypeParameter: {"win"}, BidIdParameter: {"bid1"}, AccountIdParameter: {"account1"}})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		modify      func(url.Values)
+		expectedErr error
+	}{
+		{
+			name:        "unsigned",
+			modify:      func(v url.Values) { v.Del(SignatureParameter) },
+			expectedErr: errUnsignedEvent,
+		},
+		{
+			name:        "tampered-parameter",
+			modify:      func(v url.Values) { v.Set(AccountIdParameter, "account2") },
+			expectedErr: errInvalidSignature,
+		},
+		{
+			name:        "added-parameter",
+			modify:      func(v url.Values) { v.Set(AnalyticsParameter, "0") },
+			expectedErr: errInvalidSignature,
+		},
+		{
+			name:        "unknown-key",
+			modify:      func(v url.Values) { v.Set(SignatureParameter, "k9"+v.Get(SignatureParameter)[2:]) },
+			expectedErr: errInvalidSignature,
+		},
+		{
+			name:        "missing-key-id",
+			modify:      func(v url.Values) { v.Set(SignatureParameter, "signature") },
+			expectedErr: errInvalidSignature,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			params, err := url.ParseQuery(query)
+			require.NoError(t, err)
+			test.modify(params)
+
+			_, err = signer.Verify(params)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+type fakeTime struct {
+	time time.Time
+}
+
+func (ft *fakeTime) Now() time.Time {
+	return ft.time
+}
+
+func TestURLSignerMaxAge(t *testing.T) {
+	signedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name        string
+		verifiedAt  time.Time
+		modify      func(url.Values)
+		expectedErr error
+	}{
+		{
+			name:       "fresh",
+			verifiedAt: signedAt.Add(30 * time.Second),
+		},
+		{
+			name:       "at-max-age",
+			verifiedAt: signedAt.Add(time.Minute),
+		},
+		{
+			name:        "expired",
+			verifiedAt:  signedAt.Add(time.Minute + time.Millisecond),
+			expectedErr: errExpiredSignature,
+		},
+		{
+			name:       "refreshed-timestamp",
+			verifiedAt: signedAt.Add(2 * time.Minute),
+			modify: func(v url.Values) {
+				v.Set(SignedAtParameter, strconv.FormatInt(signedAt.Add(2*time.Minute).UnixMilli(), 10))
+			},
+			expectedErr: errInvalidSignature,
+		},
+		{
+			name:        "missing-timestamp",
+			verifiedAt:  signedAt,
+			modify:      func(v url.Values) { v.Del(SignedAtParameter) },
+			expectedErr: errInvalidSignature,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			for _, encrypt := range []bool{false, true} {
+				signer, err := NewURLSigner(config.EventSigning{Enabled: true, MaxAgeSeconds: 60, Encrypt: encrypt, Keys: []config.EventSigningKey{signingKeyCurrent}})
+				require.NoError(t, err)
+				clock := &fakeTime{time: signedAt}
+				signer.time = clock
+
+				params := url.Values{TypeParameter: {"win"}, BidIdParameter: {"bid1"}, AccountIdParameter: {"account1"}}
+				query, err := signer.Sign(params)
+				require.NoError(t, err)
+				signed, err := url.ParseQuery(query)
+				require.NoError(t, err)
+				assert.Equal(t, strconv.FormatInt(signedAt.UnixMilli(), 10), signed.Get(SignedAtParameter))
+				if test.modify != nil {
+					test.modify(signed)
+				}
+
+				clock.time = test.verifiedAt
+				verified, err := signer.Verify(signed)
+				assert.Equal(t, test.expectedErr, err, "encrypt: %t", encrypt)
+				if test.expectedErr == nil {
+					assert.Equal(t, params, verified, "encrypt: %t", encrypt)
+				}
+			}
+		})
+	}
+}
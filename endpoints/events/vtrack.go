@@ -143,7 +143,7 @@ func (v *vtrackEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httpro
 }
 
 // GetVastUrlTracking creates a vast url tracking
-func GetVastUrlTracking(externalUrl string, bidid string, bidder string, accountId string, timestamp int64, integration string) string {
+func GetVastUrlTracking(externalUrl string, bidid string, bidder string, accountId string, timestamp int64, integration string, signer *URLSigner) string {
 
 	eventReq := &analytics.EventRequest{
 		Type:        analytics.Imp,
@@ -155,7 +155,7 @@ func GetVastUrlTracking(externalUrl string, bidid string, bidder string, account
 		Integration: integration,
 	}
 
-	return EventRequestToUrl(externalUrl, eventReq)
+	return EventRequestToUrl(externalUrl, eventReq, signer)
 }
 
 // ParseVTrackRequest parses a BidCacheRequest from an HTTP Request
@@ -245,7 +245,9 @@ func (v *vtrackEndpoint) cachePutObjects(ctx context.Context, req *BidCacheReque
 		}
 
 		if _, ok := biddersAllowingVastUpdate[c.Bidder]; ok && nc.Data != nil {
-			nc.Data = ModifyVastXmlJSON(v.Cfg.ExternalURL, nc.Data, c.BidID, c.Bidder, accountId, c.Timestamp, integration)
+			// the tracking URLs carry the bid ids and timestamps of the caller, so they are left unsigned rather than
+			// letting anyone obtain valid signatures for any event
+			nc.Data = ModifyVastXmlJSON(v.Cfg.ExternalURL, nc.Data, c.BidID, c.Bidder, accountId, c.Timestamp, integration, nil)
 		}
 
 		cacheables = append(cacheables, *nc)
@@ -297,7 +299,7 @@ func getIntegrationType(httpRequest *http.Request) (string, error) {
 }
 
 // ModifyVastXmlString rewrites and returns the string vastXML and a flag indicating if it was modified
-func ModifyVastXmlString(externalUrl, vast, bidid, bidder, accountID string, timestamp int64, integrationType string) (string, bool) {
+func ModifyVastXmlString(externalUrl, vast, bidid, bidder, accountID string, timestamp int64, integrationType string, signer *URLSigner) (string, bool) {
 	ci := strings.Index(vast, ImpressionCloseTag)
 
 	// no impression tag - pass it as it is
@@ -305,7 +307,7 @@ func ModifyVastXmlString(externalUrl, vast, bidid, bidder, accountID string, tim
 		return vast, false
 	}
 
-	vastUrlTracking := GetVastUrlTracking(externalUrl, bidid, bidder, accountID, timestamp, integrationType)
+	vastUrlTracking := GetVastUrlTracking(externalUrl, bidid, bidder, accountID, timestamp, integrationType, signer)
 	impressionUrl := "<![CDATA[" + vastUrlTracking + "]]>"
 	oi := strings.Index(vast, ImpressionOpenTag)
 
@@ -317,13 +319,13 @@ func ModifyVastXmlString(externalUrl, vast, bidid, bidder, accountID string, tim
 }
 
 // ModifyVastXmlJSON modifies BidCacheRequest element Vast XML data
-func ModifyVastXmlJSON(externalUrl string, data json.RawMessage, bidid, bidder, accountId string, timestamp int64, integrationType string) json.RawMessage {
+func ModifyVastXmlJSON(externalUrl string, data json.RawMessage, bidid, bidder, accountId string, timestamp int64, integrationType string, signer *URLSigner) json.RawMessage {
 	var vast string
 	if err := jsonutil.Unmarshal(data, &vast); err != nil {
 		// failed to decode json, fall back to string
 		vast = string(data)
 	}
-	vast, ok := ModifyVastXmlString(externalUrl, vast, bidid, bidder, accountId, timestamp, integrationType)
+	vast, ok := ModifyVastXmlString(externalUrl, vast, bidid, bidder, accountId, timestamp, integrationType, signer)
 	if !ok {
 		return data
 	}
@@ -743,7 +743,7 @@ func TestShouldRespondWithInternalErrorPbsCacheIsNotConfigured(t *testing.T) {
 }
 
 func TestVastUrlShouldReturnExpectedUrl(t *testing.T) {
-	url := GetVastUrlTracking("http://external-url", "bidId", "bidder", "accountId", 1000, "integrationType")
+	url := GetVastUrlTracking("http://external-url", "bidId", "bidder", "accountId", 1000, "integrationType", nil)
 	assert.Equal(t, "http://external-url/event?t=imp&b=bidId&a=accountId&bidder=bidder&f=b&int=integrationType&ts=1000", url, "Invalid vast url")
 }
 
@@ -0,0 +1,47 @@
+package info
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/golang/glog"
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+type moduleSchemas interface {
+	Schema(module string) json.RawMessage
+}
+
+type moduleInfo struct {
+	Hooks  []string        `json:"hooks"`
+	Schema json.RawMessage `json:"schema,omitempty"`
+}
+
+// NewModulesEndpoint builds a handler for the /info/modules endpoint, listing the enabled modules
+// along with the stages they provide hooks for and the JSON schema of their config.
+func NewModulesEndpoint(moduleStages map[string][]string, schemas moduleSchemas) httprouter.Handle {
+	response, err := prepareModulesResponse(moduleStages, schemas)
+	if err != nil {
+		glog.Fatalf("error creating /info/modules endpoint response: %v", err)
+	}
+
+	return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(response); err != nil {
+			glog.Errorf("error writing response to /info/modules: %v", err)
+		}
+	}
+}
+
+func prepareModulesResponse(moduleStages map[string][]string, schemas moduleSchemas) ([]byte, error) {
+	modules := make(map[string]moduleInfo, len(moduleStages))
+	for module, stages := range moduleStages {
+		hooks := slices.Clone(stages)
+		slices.Sort(hooks)
+
+		modules[module] = moduleInfo{Hooks: hooks, Schema: schemas.Schema(module)}
+	}
+	return jsonutil.Marshal(modules)
+}
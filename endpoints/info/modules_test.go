@@ -0,0 +1,53 @@
+package info
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModulesEndpoint(t *testing.T) {
+	testCases := []struct {
+		description  string
+		givenStages  map[string][]string
+		givenSchemas fakeModuleSchemas
+		expected     string
+	}{
+		{
+			description: "no-modules",
+			givenStages: map[string][]string{},
+			expected:    `{}`,
+		},
+		{
+			description: "modules-with-and-without-schema",
+			givenStages: map[string][]string{
+				"acme.foo": {"processed_auction_request", "entrypoint"},
+				"acme.bar": {"raw_bidder_response"},
+			},
+			givenSchemas: fakeModuleSchemas{"acme.foo": json.RawMessage(`{"type": "object"}`)},
+			expected:     `{"acme.bar":{"hooks":["raw_bidder_response"]},"acme.foo":{"hooks":["entrypoint","processed_auction_request"],"schema":{"type":"object"}}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			handler := NewModulesEndpoint(test.givenStages, test.givenSchemas)
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, "/info/modules", nil), nil)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.JSONEq(t, test.expected, w.Body.String())
+		})
+	}
+}
+
+type fakeModuleSchemas map[string]json.RawMessage
+
+func (s fakeModuleSchemas) Schema(module string) json.RawMessage {
+	return s[module]
+}
@@ -0,0 +1,62 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/modules"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+type moduleHealthReporter interface {
+	Health(ctx context.Context) map[string]modules.ModuleHealth
+}
+
+// NewModulesHealthEndpoint returns the health of the hook modules reporting it.
+// The endpoint responds with 503 Service Unavailable if any of the modules is unhealthy.
+func NewModulesHealthEndpoint(reporter moduleHealthReporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		health := reporter.Health(r.Context())
+
+		jsonOutput, err := jsonutil.Marshal(health)
+		if err != nil {
+			glog.Errorf("/modules/health Critical error when trying to marshal modules health: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		for _, moduleHealth := range health {
+			if !moduleHealth.Healthy {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				break
+			}
+		}
+		w.Write(jsonOutput)
+	}
+}
+
+// NewModulesReloadEndpoint reloads the hook modules config when receiving a POST request
+// authorized with one of the admin tokens.
+func NewModulesReloadEndpoint(reload func() error, tokens []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := authorizeAdmin(w, r, tokens); !ok {
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := reload(); err != nil {
+			glog.Errorf("/modules/reload failed: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
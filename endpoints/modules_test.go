@@ -0,0 +1,132 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/modules"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModulesHealthEndpoint(t *testing.T) {
+	testCases := []struct {
+		description  string
+		givenHealth  map[string]modules.ModuleHealth
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			description:  "no-modules-reporting",
+			givenHealth:  map[string]modules.ModuleHealth{},
+			expectedCode: http.StatusOK,
+			expectedBody: `{}`,
+		},
+		{
+			description:  "all-healthy",
+			givenHealth:  map[string]modules.ModuleHealth{"acme.foo": {Healthy: true}},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"acme.foo":{"healthy":true}}`,
+		},
+		{
+			description: "one-unhealthy",
+			givenHealth: map[string]modules.ModuleHealth{
+				"acme.foo": {Healthy: true},
+				"acme.bar": {Healthy: false, Error: "data file missing"},
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"acme.bar":{"healthy":false,"error":"data file missing"},"acme.foo":{"healthy":true}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			handler := NewModulesHealthEndpoint(fakeModuleHealthReporter(test.givenHealth))
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, "/modules/health", nil))
+
+			assert.Equal(t, test.expectedCode, w.Code)
+			assert.JSONEq(t, test.expectedBody, w.Body.String())
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestModulesReloadEndpoint(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenMethod    string
+		givenToken     string
+		givenReloadErr error
+		expectedCode   int
+		expectedBody   string
+		expectedReload bool
+	}{
+		{
+			description:    "reloaded",
+			givenMethod:    http.MethodPost,
+			givenToken:     "secret",
+			expectedCode:   http.StatusNoContent,
+			expectedReload: true,
+		},
+		{
+			description:    "no-token",
+			givenMethod:    http.MethodPost,
+			expectedCode:   http.StatusUnauthorized,
+			expectedReload: false,
+		},
+		{
+			description:    "wrong-token",
+			givenMethod:    http.MethodPost,
+			givenToken:     "wrong",
+			expectedCode:   http.StatusUnauthorized,
+			expectedReload: false,
+		},
+		{
+			description:    "reload-failed",
+			givenMethod:    http.MethodPost,
+			givenToken:     "secret",
+			givenReloadErr: errors.New("invalid config"),
+			expectedCode:   http.StatusInternalServerError,
+			expectedBody:   "invalid config",
+			expectedReload: true,
+		},
+		{
+			description:    "get-not-allowed",
+			givenMethod:    http.MethodGet,
+			givenToken:     "secret",
+			expectedCode:   http.StatusMethodNotAllowed,
+			expectedReload: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			reloaded := false
+			handler := NewModulesReloadEndpoint(func() error {
+				reloaded = true
+				return test.givenReloadErr
+			}, []string{"secret"})
+
+			req := httptest.NewRequest(test.givenMethod, "/modules/reload", nil)
+			if test.givenToken != "" {
+				req.Header.Set("Authorization", "Bearer "+test.givenToken)
+			}
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			assert.Equal(t, test.expectedCode, w.Code)
+			assert.Equal(t, test.expectedBody, w.Body.String())
+			assert.Equal(t, test.expectedReload, reloaded)
+		})
+	}
+}
+
+type fakeModuleHealthReporter map[string]modules.ModuleHealth
+
+func (r fakeModuleHealthReporter) Health(_ context.Context) map[string]modules.ModuleHealth {
+	return r
+}
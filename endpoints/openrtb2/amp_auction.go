@@ -30,8 +30,10 @@ import (
 	"github.com/prebid/prebid-server/v3/gdpr"
 	"github.com/prebid/prebid-server/v3/hooks"
 	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/mirror"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/ratelimit"
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/v3/stored_responses"
@@ -71,6 +73,8 @@ func NewAmpEndpoint(
 	storedRespFetcher stored_requests.Fetcher,
 	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
 	tmaxAdjustments *exchange.TmaxAdjustmentsPreprocessed,
+	requestMirror *mirror.Mirror,
+	rateLimiter *ratelimit.RateLimiter,
 ) (httprouter.Handle, error) {
 
 	if ex == nil || requestValidator == nil || requestsById == nil || accounts == nil || cfg == nil || metricsEngine == nil {
@@ -105,6 +109,8 @@ func NewAmpEndpoint(
 		hookExecutionPlanBuilder,
 		tmaxAdjustments,
 		openrtb_ext.NormalizeBidderName,
+		rateLimiter,
+		requestMirror,
 	}).AmpAuction), nil
 
 }
@@ -160,6 +166,15 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 
 	// There is no body for AMP requests, so we pass a nil body and ignore the return value.
 	_, rejectErr := hookExecutor.ExecuteEntrypointStage(r, nilBody)
+
+	// Read UserSyncs/Cookie from Request
+	usersyncs := usersync.ReadCookie(r, usersync.Base64Decoder{}, &deps.cfg.HostCookie)
+	usersync.SyncHostCookie(r, usersyncs, &deps.cfg.HostCookie)
+
+	// The selection of the variants of versioned stored data is shared by the stored request and the account.
+	variants := stored_requests.NewVariantSelection(getAmpVariantKey(r, usersyncs, deps.cfg.HostCookie.Family, deps.uuidGenerator))
+	r = r.WithContext(stored_requests.WithVariantSelection(r.Context(), variants))
+
 	reqWrapper, storedAuctionResponses, storedBidResponses, bidderImpReplaceImp, errL := deps.parseAmpRequest(r)
 	ao.Errors = append(ao.Errors, errL...)
 	// Process reject after parsing amp request, so we can use reqWrapper.
@@ -180,7 +195,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 
 	ao.RequestWrapper = reqWrapper
 
-	ctx := context.Background()
+	ctx := stored_requests.WithVariantSelection(context.Background(), variants)
 	var cancel context.CancelFunc
 	if reqWrapper.TMax > 0 {
 		ctx, cancel = context.WithDeadline(ctx, start.Add(time.Duration(reqWrapper.TMax)*time.Millisecond))
@@ -189,9 +204,6 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	}
 	defer cancel()
 
-	// Read UserSyncs/Cookie from Request
-	usersyncs := usersync.ReadCookie(r, usersync.Base64Decoder{}, &deps.cfg.HostCookie)
-	usersync.SyncHostCookie(r, usersyncs, &deps.cfg.HostCookie)
 	if usersyncs.HasAnyLiveSyncs() {
 		labels.CookieFlag = metrics.CookieFlagYes
 	} else {
@@ -231,6 +243,24 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		return
 	}
 
+	if err := deps.rateLimiter.Limit(ctx, account, config.RateLimitEndpointAMP, labels.RType, reqWrapper.BidRequest); err != nil {
+		labels.RequestStatus = metrics.RequestStatusRateLimited
+		ao.Errors = append(ao.Errors, err)
+		if rateLimitErr := findRateLimitedNoBid([]error{err}); rateLimitErr != nil {
+			response := &openrtb2.BidResponse{ID: reqWrapper.ID, NBR: openrtb3.NoBidTechnicalError.Ptr()}
+			ao.AuctionResponse = response
+			labels, ao = sendAmpResponse(w, hookExecutor, &exchange.AuctionResponse{BidResponse: response}, reqWrapper, account, labels, ao, errL)
+			return
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(w, "Invalid request: %s\n", err.Error())
+		return
+	}
+
+	if err := setStoredVariants(reqWrapper, variants.Selected()); err != nil {
+		errL = append(errL, err)
+	}
+
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	if errs := deps.setFieldsImplicitly(r, reqWrapper, account); len(errs) > 0 {
 		errL = append(errL, errs...)
@@ -258,6 +288,8 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 
 	secGPC := r.Header.Get("Sec-GPC")
 
+	deps.mirrorRequest(config.MirroringEndpointAMP, account, labels.RType, reqWrapper)
+
 	auctionRequest := &exchange.AuctionRequest{
 		BidRequestWrapper:          reqWrapper,
 		Account:                    *account,
@@ -354,15 +386,17 @@ func sendAmpResponse(
 	// go in the AMP response
 	targets := map[string]string{}
 	byteCache := []byte("\"hb_cache_id")
+	byteCacheID := []byte("\"cacheId\"")
 	if response != nil {
 		for _, seatBids := range response.SeatBid {
 			for _, bid := range seatBids.Bid {
-				if bytes.Contains(bid.Ext, byteCache) {
+				if bytes.Contains(bid.Ext, byteCache) || bytes.Contains(bid.Ext, byteCacheID) {
 					// Looking for cache_id to be set, as this should only be set on winning bids (or
 					// deal bids), and AMP can only deliver cached ads in any case.
 					// Note, this could cause issues if a targeting key value starts with "hb_cache_id",
 					// but this is a very unlikely corner case. Doing this so we can catch "hb_cache_id"
-					// and "hb_cache_id_{deal}", which allows for deal support in AMP.
+					// and "hb_cache_id_{deal}", which allows for deal support in AMP. The cache id is also
+					// looked up in ext.prebid.cache since accounts may rename the targeting keys.
 					bidExt := &openrtb_ext.ExtBid{}
 					err := jsonutil.Unmarshal(bid.Ext, bidExt)
 					if err != nil {
@@ -404,7 +438,8 @@ func sendAmpResponse(
 	ao.AmpTargetingValues = targets
 
 	// Fixes #231
-	enc := json.NewEncoder(w) // nosemgrep: json-encoder-needs-type
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf) // nosemgrep: json-encoder-needs-type
 	enc.SetEscapeHTML(false)
 	// Explicitly set content type to text/plain, which had previously been
 	// the implied behavior from the time the project was launched.
@@ -412,10 +447,19 @@ func sendAmpResponse(
 	// nevertheless we will keep it as such for compatibility reasons.
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 
-	// If an error happens when encoding the response, there isn't much we can do.
+	if err := enc.Encode(ampResponse); err != nil {
+		labels.RequestStatus = metrics.RequestStatusNetworkErr
+		ao.Errors = append(ao.Errors, fmt.Errorf("/openrtb2/amp Failed to send response: %v", err))
+		return labels, ao
+	}
+
+	var body []byte
+	body, ao.HookExecutionOutcome = executeExitpointStage(w, hookExecutor, buf.Bytes())
+
+	// If an error happens when writing the response, there isn't much we can do.
 	// If we've sent _any_ bytes, then Go would have sent the 200 status code first.
 	// That status code can't be un-sent... so the best we can do is log the error.
-	if err := enc.Encode(ampResponse); err != nil {
+	if _, err := w.Write(body); err != nil {
 		labels.RequestStatus = metrics.RequestStatusNetworkErr
 		ao.Errors = append(ao.Errors, fmt.Errorf("/openrtb2/amp Failed to send response: %v", err))
 	}
@@ -494,6 +538,19 @@ func getExtBidResponse(
 	return ao, extBidResponse
 }
 
+// getAmpVariantKey returns the key assigning the variants of versioned stored data to an AMP request, which has no
+// body identifying the user. The host cookie ID is preferred, so a user keeps the same variants across requests, then
+// the page and the ad slot, so every request of a page view gets the same variants, and otherwise a random key.
+func getAmpVariantKey(httpRequest *http.Request, usersyncs *usersync.Cookie, hostFamily string, uuidGenerator uuidutil.UUIDGenerator) string {
+	if uid, _, _ := usersyncs.GetUID(hostFamily); hostFamily != "" && uid != "" {
+		return uid
+	}
+	if curl := httpRequest.FormValue("curl"); curl != "" {
+		return curl + "|" + httpRequest.FormValue("tag_id")
+	}
+	return getVariantKey(nil, uuidGenerator)
+}
+
 // parseRequest turns the HTTP request into an OpenRTB request.
 // If the errors list is empty, then the returned request will be valid according to the OpenRTB 2.5 spec.
 // In case of "strong recommendations" in the spec, it tends to be restrictive. If a better workaround is
@@ -542,6 +599,7 @@ func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request) (req
 		return nil, nil, nil, nil, []error{err}
 	}
 
+	variants := stored_requests.VariantSelectionFromContext(httpRequest.Context())
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(deps.cfg.StoredRequestsTimeout)*time.Millisecond)
 	defer cancel()
 
@@ -549,13 +607,20 @@ func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request) (req
 	if len(errs) > 0 {
 		return nil, nil, nil, nil, errs
 	}
+	if storedRequests, errs = variants.Requests(storedRequests); len(errs) > 0 {
+		return nil, nil, nil, nil, errs
+	}
 	if len(storedRequests) == 0 {
 		errs = []error{fmt.Errorf("No AMP config found for tag_id '%s'", ampParams.StoredRequestID)}
 		return
 	}
 
 	// The fetched config becomes the entire OpenRTB request
-	requestJSON := storedRequests[ampParams.StoredRequestID]
+	requestJSON, err := stored_requests.ResolveTemplate(storedRequests[ampParams.StoredRequestID], stored_requests.TemplateValues{AMP: httpRequest.URL.Query()})
+	if err != nil {
+		errs = []error{fmt.Errorf("tag_id '%s': %v", ampParams.StoredRequestID, err)}
+		return
+	}
 	if err := jsonutil.UnmarshalValid(requestJSON, req); err != nil {
 		errs = []error{err}
 		return
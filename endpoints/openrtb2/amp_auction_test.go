@@ -33,7 +33,9 @@ import (
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/ortb"
 	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/ratelimit"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 )
 
@@ -57,6 +59,7 @@ func TestGoodAmpRequests(t *testing.T) {
 				"buyeruids-camel-case.json",
 				"aliased-buyeruids-case-insensitive.json",
 				"ortb-2.5-to-2.6-upconvert.json",
+				"templated-stored-request.json",
 			},
 		},
 		{
@@ -204,6 +207,112 @@ func TestAccountErrors(t *testing.T) {
 	}
 }
 
+func TestAmpRateLimited(t *testing.T) {
+	testCases := []struct {
+		description  string
+		action       string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			description:  "reject",
+			action:       config.RateLimitActionReject,
+			expectedCode: http.StatusTooManyRequests,
+			expectedBody: "Invalid request: Request rate of account unknown on amp is over 0.001 requests per second\n",
+		},
+		{
+			description:  "no_bid",
+			action:       config.RateLimitActionNoBid,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"targeting":{},"ortb2":{"ext":{}}}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			stored := map[string]json.RawMessage{
+				"1": json.RawMessage(validRequest(t, "site.json")),
+			}
+			cfg := &config.Configuration{MaxRequestSize: maxSize}
+			cfg.AccountDefaults.RateLimits = config.AccountRateLimits{
+				Enabled:   true,
+				Endpoints: map[string]config.AccountRateLimit{config.RateLimitEndpointAMP: {RequestsPerSecond: 0.001, Burst: 1}},
+				Action:    tc.action,
+			}
+			exchange := &mockAmpExchange{}
+
+			endpoint, _ := NewAmpEndpoint(
+				fakeUUIDGenerator{},
+				exchange,
+				ortb.NewRequestValidator(openrtb_ext.BuildBidderMap(), map[string]string{}, newParamsValidator(t)),
+				&mockAmpStoredReqFetcher{stored},
+				empty_fetcher.EmptyFetcher{},
+				cfg,
+				&metricsConfig.NilMetricsEngine{},
+				analyticsBuild.New(&config.Analytics{}),
+				map[string]string{},
+				[]byte{},
+				openrtb_ext.BuildBidderMap(),
+				empty_fetcher.EmptyFetcher{},
+				hooks.EmptyPlanBuilder{},
+				nil,
+				nil,
+				ratelimit.NewRateLimiter(config.RateLimiting{Enabled: true, Backend: "local"}, &metricsConfig.NilMetricsEngine{}),
+			)
+
+			recorder := httptest.NewRecorder()
+			endpoint(recorder, httptest.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil), nil)
+			assert.Equal(t, http.StatusOK, recorder.Code, "the first request fits in the burst")
+			exchange.lastRequest = nil
+
+			recorder = httptest.NewRecorder()
+			endpoint(recorder, httptest.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil), nil)
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+			assert.Equal(t, tc.expectedBody, recorder.Body.String())
+			assert.Nil(t, exchange.lastRequest, "the limited request doesn't run an auction")
+		})
+	}
+}
+
+func TestAmpMirrored(t *testing.T) {
+	stored := map[string]json.RawMessage{
+		"1": json.RawMessage(validRequest(t, "site.json")),
+	}
+	cfg := &config.Configuration{MaxRequestSize: maxSize}
+	cfg.AccountDefaults.Mirroring = config.AccountMirroring{SampleRate: 1, Endpoints: []string{config.MirroringEndpointAMP}}
+	requestMirror, mirrorPath := newTestMirror(t)
+	exchange := &mockAmpExchange{}
+
+	endpoint, _ := NewAmpEndpoint(
+		fakeUUIDGenerator{},
+		exchange,
+		ortb.NewRequestValidator(openrtb_ext.BuildBidderMap(), map[string]string{}, newParamsValidator(t)),
+		&mockAmpStoredReqFetcher{stored},
+		empty_fetcher.EmptyFetcher{},
+		cfg,
+		&metricsConfig.NilMetricsEngine{},
+		analyticsBuild.New(&config.Analytics{}),
+		map[string]string{},
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		empty_fetcher.EmptyFetcher{},
+		hooks.EmptyPlanBuilder{},
+		nil,
+		requestMirror,
+		nil,
+	)
+
+	recorder := httptest.NewRecorder()
+	endpoint(recorder, httptest.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil), nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	mirrored := readMirroredRequests(t, requestMirror, mirrorPath)
+	require.Len(t, mirrored, 1)
+	assert.Equal(t, config.MirroringEndpointAMP, mirrored[0].Endpoint)
+	assert.Equal(t, exchange.lastRequest.ID, mirrored[0].BidRequest.ID)
+	assert.NotNil(t, mirrored[0].BidRequest.Site, "the stored request is resolved")
+}
+
 // Prevents #683
 func TestAMPPageInfo(t *testing.T) {
 	const page = "http://test.somepage.co.uk:1234?myquery=1&other=2"
@@ -227,6 +336,8 @@ func TestAMPPageInfo(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 	request := httptest.NewRequest("GET", fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&curl=%s", url.QueryEscape(page)), nil)
 	recorder := httptest.NewRecorder()
@@ -324,6 +435,8 @@ func TestGDPRConsent(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
 			nil,
+			nil,
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -712,6 +825,8 @@ func TestCCPAConsent(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
 			nil,
+			nil,
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -819,6 +934,8 @@ func TestConsentWarnings(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
 			nil,
+			nil,
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -920,6 +1037,8 @@ func TestNewAndLegacyConsentBothProvided(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
 			nil,
+			nil,
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -968,6 +1087,8 @@ func TestAMPSiteExt(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 	request, err := http.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil)
 	if !assert.NoError(t, err) {
@@ -1033,6 +1154,8 @@ func TestAmpBadRequests(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	for id, test := range badRequests {
@@ -1124,6 +1247,8 @@ func TestAmpDebug(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	for requestID := range requests {
@@ -1260,6 +1385,8 @@ func TestQueryParamOverrides(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	requestID := "1"
@@ -1418,6 +1545,8 @@ func (s formatOverrideSpec) execute(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	url := fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&debug=1&w=%d&h=%d&ow=%d&oh=%d&ms=%s&account=%s", s.width, s.height, s.overrideWidth, s.overrideHeight, s.multisize, s.account)
@@ -1935,6 +2064,8 @@ func ampObjectTestSetup(t *testing.T, inTagId string, inStoredRequest json.RawMe
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 	return &actualAmpObject, endpoint
 }
@@ -1988,6 +2119,8 @@ func TestAmpAuctionResponseHeaders(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	for _, test := range testCases {
@@ -2024,6 +2157,8 @@ func TestRequestWithTargeting(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 	url, err := url.Parse("/openrtb2/auction/amp")
 	assert.NoError(t, err, "unexpected error received while parsing url")
@@ -2346,6 +2481,42 @@ func TestSendAmpResponse_LogsErrors(t *testing.T) {
 	}
 }
 
+func TestSendAmpResponse_ExitpointHook(t *testing.T) {
+	testCases := []struct {
+		description string
+		reqWrapper  *openrtb_ext.RequestWrapper
+	}{
+		{
+			description: "request",
+			reqWrapper:  &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "some-id"}},
+		},
+		{
+			description: "no_request",
+			reqWrapper:  nil,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			planBuilder := mockPlanBuilder{exitpointPlan: makePlan[hookstage.Exitpoint](mockExitpointHook{})}
+			hookExecutor := hookexecution.NewHookExecutor(planBuilder, hookexecution.EndpointAmp, &metricsConfig.NilMetricsEngine{})
+			hookExecutor.SetAccount(&config.Account{})
+
+			writer := httptest.NewRecorder()
+			auctionResponse := &exchange.AuctionResponse{BidResponse: &openrtb2.BidResponse{ID: "some-id", Ext: json.RawMessage("{}")}}
+
+			_, ao := sendAmpResponse(writer, hookExecutor, auctionResponse, test.reqWrapper, &config.Account{}, metrics.Labels{}, analytics.AmpObject{}, nil)
+
+			assert.Empty(t, ao.Errors)
+			assert.Equal(t, `{"id":"some-id","exitpoint":true}`, writer.Body.String())
+			assert.Equal(t, "exitpoint", writer.Header().Get("X-Hook"))
+			if assert.Len(t, ao.HookExecutionOutcome, 1) {
+				assert.Equal(t, hooks.StageExitpoint.String(), ao.HookExecutionOutcome[0].Stage)
+			}
+		})
+	}
+}
+
 type errorResponseWriter struct{}
 
 func (e errorResponseWriter) Header() http.Header {
@@ -2482,6 +2653,8 @@ func TestAmpAuctionDebugWarningsOnly(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	for _, test := range testCases {
@@ -2502,3 +2675,52 @@ func TestAmpAuctionDebugWarningsOnly(t *testing.T) {
 		assert.Equal(t, test.expectedWarnings, response.ORTB2.Ext.Warnings)
 	}
 }
+
+func TestGetAmpVariantKey(t *testing.T) {
+	testCases := []struct {
+		description string
+		query       string
+		hostUID     string
+		hostFamily  string
+		expected    string
+	}{
+		{
+			description: "host-cookie",
+			query:       "tag_id=tag1&curl=https%3A%2F%2Fexample.com%2Fpage",
+			hostUID:     "user1",
+			hostFamily:  "host",
+			expected:    "user1",
+		},
+		{
+			description: "no-host-family",
+			query:       "tag_id=tag1&curl=https%3A%2F%2Fexample.com%2Fpage",
+			hostUID:     "user1",
+			expected:    "https://example.com/page|tag1",
+		},
+		{
+			description: "page",
+			query:       "tag_id=tag1&curl=https%3A%2F%2Fexample.com%2Fpage",
+			hostFamily:  "host",
+			expected:    "https://example.com/page|tag1",
+		},
+		{
+			description: "random",
+			query:       "tag_id=tag1",
+			hostFamily:  "host",
+			expected:    "uuid1",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			usersyncs := usersync.NewCookie()
+			if test.hostUID != "" {
+				require.NoError(t, usersyncs.Sync(test.hostFamily, test.hostUID))
+			}
+			httpRequest := httptest.NewRequest("GET", "/openrtb2/amp?"+test.query, nil)
+
+			key := getAmpVariantKey(httpRequest, usersyncs, test.hostFamily, fakeUUIDGenerator{id: "uuid1"})
+			assert.Equal(t, test.expected, key)
+		})
+	}
+}
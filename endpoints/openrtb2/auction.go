@@ -1,6 +1,7 @@
 package openrtb2
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
 	"encoding/json"
@@ -29,6 +30,7 @@ import (
 	"github.com/prebid/prebid-server/v3/privacysandbox"
 	"github.com/prebid/prebid-server/v3/schain"
 	"golang.org/x/net/publicsuffix"
+	goCurrency "golang.org/x/text/currency"
 	jsonpatch "gopkg.in/evanphx/json-patch.v5"
 
 	accountService "github.com/prebid/prebid-server/v3/account"
@@ -40,10 +42,12 @@ import (
 	"github.com/prebid/prebid-server/v3/gdpr"
 	"github.com/prebid/prebid-server/v3/hooks/hookexecution"
 	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/mirror"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/prebid_cache_client"
 	"github.com/prebid/prebid-server/v3/privacy/ccpa"
 	"github.com/prebid/prebid-server/v3/privacy/lmt"
+	"github.com/prebid/prebid-server/v3/ratelimit"
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/v3/stored_responses"
@@ -98,6 +102,8 @@ func NewEndpoint(
 	storedRespFetcher stored_requests.Fetcher,
 	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
 	tmaxAdjustments *exchange.TmaxAdjustmentsPreprocessed,
+	requestMirror *mirror.Mirror,
+	rateLimiter *ratelimit.RateLimiter,
 ) (httprouter.Handle, error) {
 	if ex == nil || requestValidator == nil || requestsById == nil || accounts == nil || cfg == nil || metricsEngine == nil {
 		return nil, errors.New("NewEndpoint requires non-nil arguments.")
@@ -130,7 +136,9 @@ func NewEndpoint(
 		storedRespFetcher,
 		hookExecutionPlanBuilder,
 		tmaxAdjustments,
-		openrtb_ext.NormalizeBidderName}).Auction), nil
+		openrtb_ext.NormalizeBidderName,
+		rateLimiter,
+		requestMirror}).Auction), nil
 }
 
 type endpointDeps struct {
@@ -154,6 +162,8 @@ type endpointDeps struct {
 	hookExecutionPlanBuilder  hooks.ExecutionPlanBuilder
 	tmaxAdjustments           *exchange.TmaxAdjustmentsPreprocessed
 	normalizeBidderName       openrtb_ext.BidderNameNormalizer
+	rateLimiter               *ratelimit.RateLimiter
+	mirror                    *mirror.Mirror
 }
 
 func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -192,6 +202,12 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	setBrowsingTopicsHeader(w, r)
 
 	req, impExtInfoMap, storedAuctionResponses, storedBidResponses, bidderImpReplaceImp, account, errL := deps.parseRequest(r, &labels, hookExecutor)
+	if rateLimitErr := findRateLimitedNoBid(errL); rateLimitErr != nil {
+		ao.RequestWrapper = req
+		labels, ao = noBidRateLimitedRequest(rateLimitErr, w, hookExecutor, req.BidRequest, account, labels, ao)
+		return
+	}
+
 	if errortypes.ContainsFatalError(errL) && writeError(errL, w, &labels) {
 		return
 	}
@@ -242,6 +258,8 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 
 	warnings := errortypes.WarningOnly(errL)
 
+	deps.mirrorRequest(config.MirroringEndpointAuction, account, labels.RType, req)
+
 	auctionRequest := &exchange.AuctionRequest{
 		BidRequestWrapper:          req,
 		Account:                    *account,
@@ -347,6 +365,48 @@ func rejectAuctionRequest(
 	return sendAuctionResponse(w, hookExecutor, response, request, account, labels, ao)
 }
 
+// findRateLimitedNoBid returns the error of a request over the rate limits of an account responding to such
+// requests with no-bids, or nil.
+func findRateLimitedNoBid(errs []error) *errortypes.RateLimited {
+	for _, err := range errs {
+		if rateLimitErr, ok := err.(*errortypes.RateLimited); ok && rateLimitErr.NoBid {
+			return rateLimitErr
+		}
+	}
+	return nil
+}
+
+func noBidRateLimitedRequest(
+	rateLimitErr *errortypes.RateLimited,
+	w http.ResponseWriter,
+	hookExecutor hookexecution.HookStageExecutor,
+	request *openrtb2.BidRequest,
+	account *config.Account,
+	labels metrics.Labels,
+	ao analytics.AuctionObject,
+) (metrics.Labels, analytics.AuctionObject) {
+	response := &openrtb2.BidResponse{ID: request.ID, NBR: openrtb3.NoBidTechnicalError.Ptr()}
+
+	labels.RequestStatus = metrics.RequestStatusRateLimited
+	ao.Response = response
+	ao.Errors = append(ao.Errors, rateLimitErr)
+
+	return sendAuctionResponse(w, hookExecutor, response, request, account, labels, ao)
+}
+
+// mirrorRequest copies the resolved request if it's sampled by the mirroring of its account. The request is only
+// rebuilt once it's sampled.
+func (deps *endpointDeps) mirrorRequest(endpoint string, account *config.Account, requestType metrics.RequestType, req *openrtb_ext.RequestWrapper) {
+	if !deps.mirror.Sample(endpoint, account) {
+		return
+	}
+	if err := req.RebuildRequest(); err != nil {
+		glog.Errorf("Failed to rebuild the mirrored request of account %s: %v", account.ID, err)
+		return
+	}
+	deps.mirror.Copy(endpoint, account, requestType, req.BidRequest)
+}
+
 func sendAuctionResponse(
 	w http.ResponseWriter,
 	hookExecutor hookexecution.HookStageExecutor,
@@ -377,15 +437,25 @@ func sendAuctionResponse(
 	}
 
 	// Fixes #231
-	enc := json.NewEncoder(w)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
 	enc.SetEscapeHTML(false)
 
 	w.Header().Set("Content-Type", "application/json")
 
-	// If an error happens when encoding the response, there isn't much we can do.
+	if err := enc.Encode(response); err != nil {
+		labels.RequestStatus = metrics.RequestStatusNetworkErr
+		ao.Errors = append(ao.Errors, fmt.Errorf("/openrtb2/auction Failed to send response: %v", err))
+		return labels, ao
+	}
+
+	var body []byte
+	body, ao.HookExecutionOutcome = executeExitpointStage(w, hookExecutor, buf.Bytes())
+
+	// If an error happens when writing the response, there isn't much we can do.
 	// If we've sent _any_ bytes, then Go would have sent the 200 status code first.
 	// That status code can't be un-sent... so the best we can do is log the error.
-	if err := enc.Encode(response); err != nil {
+	if _, err := w.Write(body); err != nil {
 		labels.RequestStatus = metrics.RequestStatusNetworkErr
 		ao.Errors = append(ao.Errors, fmt.Errorf("/openrtb2/auction Failed to send response: %v", err))
 	}
@@ -393,6 +463,14 @@ func sendAuctionResponse(
 	return labels, ao
 }
 
+// executeExitpointStage runs the exitpoint hooks on the encoded response and returns the body to write along with the
+// outcomes of all stages. The exitpoint hooks run after the response is encoded, so their outcomes can't be part of the
+// response debug information, but they're passed to the analytics modules.
+func executeExitpointStage(w http.ResponseWriter, hookExecutor hookexecution.HookStageExecutor, body []byte) ([]byte, []hookexecution.StageOutcome) {
+	body = hookExecutor.ExecuteExitpointStage(w.Header(), body)
+	return body, hookExecutor.GetOutcomes()
+}
+
 // setBrowsingTopicsHeader always set the Observe-Browsing-Topics header to a value of ?1 if the Sec-Browsing-Topics is present in request
 func setBrowsingTopicsHeader(w http.ResponseWriter, r *http.Request) {
 	if value := r.Header.Get(secBrowsingTopics); value != "" {
@@ -468,6 +546,10 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metric
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
+	// the variants of versioned stored data are picked once for the request, so the account uses the same key
+	variants := stored_requests.NewVariantSelection(getVariantKey(requestJson, deps.uuidGenerator))
+	ctx = stored_requests.WithVariantSelection(ctx, variants)
+
 	impInfo, errs := parseImpInfo(requestJson)
 	if len(errs) > 0 {
 		return nil, nil, nil, nil, nil, nil, errs
@@ -534,6 +616,16 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metric
 		return
 	}
 
+	if err := deps.rateLimiter.Limit(ctx, account, config.RateLimitEndpointAuction, labels.RType, req.BidRequest); err != nil {
+		errs = []error{err}
+		return
+	}
+
+	if err := setStoredVariants(req, variants.Selected()); err != nil {
+		errs = []error{err}
+		return
+	}
+
 	// normalize to openrtb 2.6
 	if err := openrtb_ext.ConvertUpTo26(req); err != nil {
 		errs = []error{err}
@@ -770,8 +862,8 @@ func (deps *endpointDeps) validateRequest(account *config.Account, httpReq *http
 		return []error{errors.New("request missing required field: \"id\"")}
 	}
 
-	if req.TMax < 0 {
-		return []error{fmt.Errorf("request.tmax must be nonnegative. Got %d", req.TMax)}
+	if err := ortb.ValidateTMax(req.TMax); err != nil {
+		return []error{err}
 	}
 
 	if req.LenImp() < 1 {
@@ -805,7 +897,7 @@ func (deps *endpointDeps) validateRequest(account *config.Account, httpReq *http
 	if reqPrebid != nil {
 		requestAliases = reqPrebid.Aliases
 
-		if err := deps.validateAliases(requestAliases); err != nil {
+		if err := deps.requestValidator.ValidateAliases(requestAliases); err != nil {
 			return []error{err}
 		}
 
@@ -813,7 +905,7 @@ func (deps *endpointDeps) validateRequest(account *config.Account, httpReq *http
 			return []error{err}
 		}
 
-		if err := deps.validateBidAdjustmentFactors(reqPrebid.BidAdjustmentFactors, requestAliases); err != nil {
+		if err := deps.requestValidator.ValidateBidAdjustmentFactors(reqPrebid.BidAdjustmentFactors, requestAliases); err != nil {
 			return []error{err}
 		}
 
@@ -834,7 +926,7 @@ func (deps *endpointDeps) validateRequest(account *config.Account, httpReq *http
 		return []error{err}
 	}
 
-	if err := validateExactlyOneInventoryType(req); err != nil {
+	if err := ortb.ValidateInventoryType(req, true); err != nil {
 		return []error{err}
 	}
 
@@ -961,34 +1053,6 @@ func validateAndFillSourceTID(req *openrtb_ext.RequestWrapper, generateRequestID
 	return nil
 }
 
-func (deps *endpointDeps) validateBidAdjustmentFactors(adjustmentFactors map[string]float64, aliases map[string]string) error {
-	uniqueBidders := make(map[string]struct{})
-	for bidderToAdjust, adjustmentFactor := range adjustmentFactors {
-		if adjustmentFactor <= 0 {
-			return fmt.Errorf("request.ext.prebid.bidadjustmentfactors.%s must be a positive number. Got %f", bidderToAdjust, adjustmentFactor)
-		}
-
-		bidderName := bidderToAdjust
-		normalizedCoreBidder, ok := openrtb_ext.NormalizeBidderName(bidderToAdjust)
-		if ok {
-			bidderName = normalizedCoreBidder.String()
-		}
-
-		if _, exists := uniqueBidders[bidderName]; exists {
-			return fmt.Errorf("cannot have multiple bidders that differ only in case style")
-		} else {
-			uniqueBidders[bidderName] = struct{}{}
-		}
-
-		if _, isBidder := deps.bidderMap[bidderName]; !isBidder {
-			if _, isAlias := aliases[bidderToAdjust]; !isAlias {
-				return fmt.Errorf("request.ext.prebid.bidadjustmentfactors.%s is not a known bidder or alias", bidderToAdjust)
-			}
-		}
-	}
-	return nil
-}
-
 func validateSChains(sChains []*openrtb_ext.ExtRequestPrebidSChain) error {
 	_, err := schain.BidderToPrebidSChains(sChains)
 	return err
@@ -1047,26 +1111,6 @@ func (deps *endpointDeps) parseBidExt(req *openrtb_ext.RequestWrapper) error {
 	return nil
 }
 
-func (deps *endpointDeps) validateAliases(aliases map[string]string) error {
-	for alias, bidderName := range aliases {
-		normalisedBidderName, _ := openrtb_ext.NormalizeBidderName(bidderName)
-		coreBidderName := normalisedBidderName.String()
-		if _, isCoreBidderDisabled := deps.disabledBidders[coreBidderName]; isCoreBidderDisabled {
-			return fmt.Errorf("request.ext.prebid.aliases.%s refers to disabled bidder: %s", alias, bidderName)
-		}
-
-		if _, isCoreBidder := deps.bidderMap[coreBidderName]; !isCoreBidder {
-			return fmt.Errorf("request.ext.prebid.aliases.%s refers to unknown bidder: %s", alias, bidderName)
-		}
-
-		if alias == coreBidderName {
-			return fmt.Errorf("request.ext.prebid.aliases.%s defines a no-op alias. Choose a different alias, or remove this entry.", alias)
-		}
-		aliases[alias] = coreBidderName
-	}
-	return nil
-}
-
 func (deps *endpointDeps) validateAliasesGVLIDs(aliasesGVLIDs map[string]uint16, aliases map[string]string) error {
 	for alias, vendorId := range aliasesGVLIDs {
 
@@ -1142,6 +1186,12 @@ func validateTargeting(t *openrtb_ext.ExtRequestTargeting) error {
 		}
 	}
 
+	if t.AdServerCurrency != "" {
+		if _, err := goCurrency.ParseISO(t.AdServerCurrency); err != nil {
+			return fmt.Errorf("ext.prebid.targeting.adservercurrency must be a valid ISO-4217 currency code, got: %s", t.AdServerCurrency)
+		}
+	}
+
 	if t.MediaTypePriceGranularity != nil {
 		if t.MediaTypePriceGranularity.Video != nil {
 			if err := validatePriceGranularity(t.MediaTypePriceGranularity.Video); err != nil {
@@ -1417,30 +1467,6 @@ func validateOrFillCookieDeprecation(httpReq *http.Request, req *openrtb_ext.Req
 	return nil
 }
 
-func validateExactlyOneInventoryType(reqWrapper *openrtb_ext.RequestWrapper) error {
-
-	// Prep for mutual exclusion check
-	invTypeNumMatches := 0
-	if reqWrapper.Site != nil {
-		invTypeNumMatches++
-	}
-	if reqWrapper.App != nil {
-		invTypeNumMatches++
-	}
-	if reqWrapper.DOOH != nil {
-		invTypeNumMatches++
-	}
-
-	if invTypeNumMatches == 0 {
-		return errors.New("One of request.site or request.app or request.dooh must be defined")
-	} else if invTypeNumMatches >= 2 {
-		return errors.New("No more than one of request.site or request.app or request.dooh can be defined")
-	} else {
-		return nil
-	}
-
-}
-
 func validateOrFillChannel(reqWrapper *openrtb_ext.RequestWrapper, isAmp bool) error {
 	requestExt, err := reqWrapper.GetRequestExt()
 	if err != nil {
@@ -1676,11 +1702,72 @@ func (deps *endpointDeps) getStoredRequests(ctx context.Context, requestJson []b
 		return "", false, nil, nil, errs
 	}
 
+	storedRequests, storedImps, errs = selectStoredVariants(ctx, storedRequests, storedImps)
+	if len(errs) != 0 {
+		return "", false, nil, nil, errs
+	}
+
 	return storedBidRequestId, hasStoredBidRequest, storedRequests, storedImps, errs
 }
 
+// selectStoredVariants replaces the versioned stored requests and imps by the variants assigned to the request.
+func selectStoredVariants(ctx context.Context, storedRequests, storedImps map[string]json.RawMessage) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	variants := stored_requests.VariantSelectionFromContext(ctx)
+	storedRequests, errs := variants.Requests(storedRequests)
+	storedImps, impErrs := variants.Imps(storedImps)
+	return storedRequests, storedImps, append(errs, impErrs...)
+}
+
+// getVariantKey returns the key assigning the variants of versioned stored data to the request. The user is preferred,
+// so a user keeps the same variants across requests, then the request ID and otherwise a random key.
+func getVariantKey(requestJson []byte, uuidGenerator uuidutil.UUIDGenerator) string {
+	for _, path := range [][]string{{"user", "id"}, {"device", "ifa"}, {"id"}} {
+		if value, err := jsonparser.GetString(requestJson, path...); err == nil && value != "" {
+			return value
+		}
+	}
+	if key, err := uuidGenerator.Generate(); err == nil {
+		return key
+	}
+	return ""
+}
+
+// setStoredVariants records the variants of versioned stored data used by the request in ext.prebid.storedvariants,
+// replacing any value sent in the request.
+func setStoredVariants(req *openrtb_ext.RequestWrapper, variants *openrtb_ext.ExtStoredVariants) error {
+	reqExt, err := req.GetRequestExt()
+	if err != nil {
+		return err
+	}
+	prebid := reqExt.GetPrebid()
+	if prebid == nil {
+		if variants == nil {
+			return nil
+		}
+		prebid = &openrtb_ext.ExtRequestPrebid{}
+	}
+	if prebid.StoredVariants == nil && variants == nil {
+		return nil
+	}
+	prebid.StoredVariants = variants
+	reqExt.SetPrebid(prebid)
+	return nil
+}
+
 func (deps *endpointDeps) processStoredRequests(requestJson []byte, impInfo []ImpExtPrebidData, storedRequests map[string]json.RawMessage, storedImps map[string]json.RawMessage, storedBidRequestId string, hasStoredBidRequest bool) ([]byte, map[string]exchange.ImpExtInfo, []error) {
-	bidRequestID, err := getBidRequestID(storedRequests[storedBidRequestId])
+	storedRequest := storedRequests[storedBidRequestId]
+	if hasStoredBidRequest && stored_requests.IsTemplate(storedRequest) {
+		params, err := getStoredRequestParams(requestJson)
+		if err != nil {
+			return nil, nil, []error{err}
+		}
+		storedRequest, err = stored_requests.ResolveTemplate(storedRequest, stored_requests.TemplateValues{Request: requestJson, Params: params})
+		if err != nil {
+			return nil, nil, []error{fmt.Errorf("ext.prebid.storedrequest.id %s: %v", storedBidRequestId, err)}
+		}
+	}
+
+	bidRequestID, err := getBidRequestID(storedRequest)
 	if err != nil {
 		return nil, nil, []error{err}
 	}
@@ -1698,7 +1785,7 @@ func (deps *endpointDeps) processStoredRequests(requestJson []byte, impInfo []Im
 			if err != nil {
 				return nil, nil, []error{err}
 			}
-			uuidPatch, err = jsonpatch.MergePatch(storedRequests[storedBidRequestId], uuidPatch)
+			uuidPatch, err = jsonpatch.MergePatch(storedRequest, uuidPatch)
 			if err != nil {
 				errL := storedRequestErrorChecker(requestJson, storedRequests, storedBidRequestId)
 				return nil, nil, errL
@@ -1709,7 +1796,7 @@ func (deps *endpointDeps) processStoredRequests(requestJson []byte, impInfo []Im
 				return nil, nil, errL
 			}
 		} else {
-			resolvedRequest, err = jsonpatch.MergePatch(storedRequests[storedBidRequestId], requestJson)
+			resolvedRequest, err = jsonpatch.MergePatch(storedRequest, requestJson)
 			if err != nil {
 				errL := storedRequestErrorChecker(requestJson, storedRequests, storedBidRequestId)
 				return nil, nil, errL
@@ -1742,7 +1829,16 @@ func (deps *endpointDeps) processStoredRequests(requestJson []byte, impInfo []Im
 	resolvedImps := make([]json.RawMessage, 0, len(impInfo))
 	for i, impData := range impInfo {
 		if impData.ImpExtPrebid.StoredRequest != nil && len(impData.ImpExtPrebid.StoredRequest.ID) > 0 {
-			resolvedImp, err := jsonpatch.MergePatch(storedImps[impData.ImpExtPrebid.StoredRequest.ID], impData.Imp)
+			// templated stored imps are resolved for each imp using them
+			storedImp, err := stored_requests.ResolveTemplate(storedImps[impData.ImpExtPrebid.StoredRequest.ID], stored_requests.TemplateValues{
+				Request: requestJson,
+				Imp:     impData.Imp,
+				Params:  impData.ImpExtPrebid.StoredRequest.Params,
+			})
+			if err != nil {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid.storedrequest.id %s: %v", i, impData.ImpExtPrebid.StoredRequest.ID, err)}
+			}
+			resolvedImp, err := jsonpatch.MergePatch(storedImp, impData.Imp)
 
 			if err != nil {
 				hasErr, errMessage := getJsonSyntaxError(impData.Imp)
@@ -1772,7 +1868,7 @@ func (deps *endpointDeps) processStoredRequests(requestJson []byte, impInfo []Im
 			if err != nil && err != jsonparser.KeyPathNotFoundError {
 				return nil, nil, []error{err}
 			}
-			impExtInfoMap[impId] = exchange.ImpExtInfo{EchoVideoAttrs: echoVideoAttributes, StoredImp: storedImps[impData.ImpExtPrebid.StoredRequest.ID], Passthrough: passthrough}
+			impExtInfoMap[impId] = exchange.ImpExtInfo{EchoVideoAttrs: echoVideoAttributes, StoredImp: storedImp, Passthrough: passthrough}
 
 		} else {
 			resolvedImps = append(resolvedImps, impData.Imp)
@@ -1823,6 +1919,23 @@ type ImpExtPrebidData struct {
 	ImpExtPrebid openrtb_ext.ExtImpPrebid
 }
 
+// getStoredRequestParams parses the params of a templated Stored Request from some json, without doing a full (slow) unmarshal.
+func getStoredRequestParams(data []byte) (map[string]json.RawMessage, error) {
+	// These keys must be kept in sync with openrtb_ext.ExtStoredRequest
+	paramsJson, _, _, err := jsonparser.Get(data, "ext", openrtb_ext.PrebidExtKey, "storedrequest", "params")
+	if err == jsonparser.KeyPathNotFoundError {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var params map[string]json.RawMessage
+	if err := jsonutil.UnmarshalValid(paramsJson, &params); err != nil {
+		return nil, fmt.Errorf("ext.prebid.storedrequest.params must be an object: %v", err)
+	}
+	return params, nil
+}
+
 // getStoredRequestId parses a Stored Request ID from some json, without doing a full (slow) unmarshal.
 // It returns the ID, true/false whether a stored request key existed, and an error if anything went wrong
 // (e.g. malformed json, id not a string, etc).
@@ -1919,6 +2032,10 @@ func writeError(errs []error, w http.ResponseWriter, labels *metrics.Labels) boo
 				httpStatus = http.StatusInternalServerError
 				metricsStatus = metrics.RequestStatusAccountConfigErr
 				break
+			} else if erVal == errortypes.RateLimitedErrorCode {
+				httpStatus = http.StatusTooManyRequests
+				metricsStatus = metrics.RequestStatusRateLimited
+				break
 			}
 		}
 		w.WriteHeader(httpStatus)
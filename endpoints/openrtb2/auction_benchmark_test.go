@@ -98,7 +98,7 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		empty_fetcher.EmptyFetcher{},
 		&adscert.NilSigner{},
 		macros.NewStringIndexBasedReplacer(),
-		nil,
+		nil, nil,
 	)
 
 	endpoint, _ := NewEndpoint(
@@ -116,6 +116,8 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	b.ResetTimer()
@@ -33,14 +33,18 @@ import (
 	"github.com/prebid/prebid-server/v3/hooks/hookstage"
 	"github.com/prebid/prebid-server/v3/metrics"
 	metricsConfig "github.com/prebid/prebid-server/v3/metrics/config"
+	"github.com/prebid/prebid-server/v3/mirror"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/ortb"
+	"github.com/prebid/prebid-server/v3/ratelimit"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/v3/stored_responses"
 	"github.com/prebid/prebid-server/v3/util/iputil"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	gometrics "github.com/rcrowley/go-metrics"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const jsonFileExtension string = ".json"
@@ -485,6 +489,8 @@ func TestExplicitUserId(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	endpoint(httptest.NewRecorder(), request, nil)
@@ -543,6 +549,8 @@ func doBadAliasRequest(t *testing.T, filename string, expectMsg string) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", bytes.NewReader(testBidRequest))
@@ -598,6 +606,8 @@ func TestNilExchange(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	if err == nil {
@@ -624,6 +634,8 @@ func TestExchangeError(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
@@ -751,6 +763,8 @@ func TestImplicitIPsEndToEnd(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
 			nil,
+			nil,
+			nil,
 		)
 
 		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, test.reqJSONFile)))
@@ -951,6 +965,8 @@ func TestImplicitDNTEndToEnd(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
 			nil,
+			nil,
+			nil,
 		)
 
 		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, test.reqJSONFile)))
@@ -1193,6 +1209,8 @@ func TestStoredRequests(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	testStoreVideoAttr := []bool{true, true, false, false, false}
@@ -1224,6 +1242,160 @@ func TestStoredRequests(t *testing.T) {
 	}
 }
 
+func TestTemplatedStoredRequests(t *testing.T) {
+	deps := &endpointDeps{
+		cfg:           &config.Configuration{MaxRequestSize: maxSize},
+		uuidGenerator: fakeUUIDGenerator{},
+	}
+	storedRequests := map[string]json.RawMessage{
+		"req1": json.RawMessage(`{"id":"req1","tmax":"{{params.tmax:int=500}}","site":{"page":"{{request.site.page}}"}}`),
+	}
+	storedImps := map[string]json.RawMessage{
+		"imp1": json.RawMessage(`{"tagid":"slot-{{params.slot}}","banner":{"format":[{"w":"{{params.w:int=300}}","h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placementId":"{{params.placement:int}}"}}}}}`),
+	}
+
+	testCases := []struct {
+		description     string
+		request         string
+		expectedRequest string
+		expectedError   string
+	}{
+		{
+			description: "resolved",
+			request: `{"site":{"page":"http://example.com"},"imp":[` +
+				`{"id":"a","ext":{"prebid":{"storedrequest":{"id":"imp1","params":{"slot":"top","placement":"123"}}}}},` +
+				`{"id":"b","ext":{"prebid":{"storedrequest":{"id":"imp1","params":{"slot":"side","w":160,"placement":456}}}}}` +
+				`],"ext":{"prebid":{"storedrequest":{"id":"req1","params":{"tmax":"800"}}}}}`,
+			expectedRequest: `{"id":"req1","tmax":800,"site":{"page":"http://example.com"},"imp":[` +
+				`{"id":"a","tagid":"slot-top","banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placementId":123}},"storedrequest":{"id":"imp1","params":{"slot":"top","placement":"123"}}}}},` +
+				`{"id":"b","tagid":"slot-side","banner":{"format":[{"w":160,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placementId":456}},"storedrequest":{"id":"imp1","params":{"slot":"side","w":160,"placement":456}}}}}` +
+				`],"ext":{"prebid":{"storedrequest":{"id":"req1","params":{"tmax":"800"}}}}}`,
+		},
+		{
+			description:   "missing-request-value",
+			request:       `{"imp":[],"ext":{"prebid":{"storedrequest":{"id":"req1"}}}}`,
+			expectedError: "ext.prebid.storedrequest.id req1: template parameter request.site.page is missing",
+		},
+		{
+			description:   "missing-imp-param",
+			request:       `{"imp":[{"id":"a","ext":{"prebid":{"storedrequest":{"id":"imp1","params":{"placement":1}}}}}]}`,
+			expectedError: "request.imp[0].ext.prebid.storedrequest.id imp1: template parameter params.slot is missing",
+		},
+		{
+			description:   "invalid-params",
+			request:       `{"site":{"page":"http://example.com"},"imp":[],"ext":{"prebid":{"storedrequest":{"id":"req1","params":[]}}}}`,
+			expectedError: "ext.prebid.storedrequest.params must be an object",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			impInfo, errs := parseImpInfo([]byte(test.request))
+			require.Empty(t, errs)
+			storedBidRequestId, hasStoredBidRequest, err := getStoredRequestId([]byte(test.request))
+			require.NoError(t, err)
+
+			request, _, errs := deps.processStoredRequests(json.RawMessage(test.request), impInfo, storedRequests, storedImps, storedBidRequestId, hasStoredBidRequest)
+			if test.expectedError != "" {
+				require.Len(t, errs, 1)
+				assert.ErrorContains(t, errs[0], test.expectedError)
+				return
+			}
+			require.Empty(t, errs)
+			assert.JSONEq(t, test.expectedRequest, string(request))
+		})
+	}
+}
+
+func TestGetVariantKey(t *testing.T) {
+	testCases := []struct {
+		description string
+		request     string
+		uuid        fakeUUIDGenerator
+		expected    string
+	}{
+		{
+			description: "user-id",
+			request:     `{"id":"req1","user":{"id":"user1"},"device":{"ifa":"ifa1"}}`,
+			expected:    "user1",
+		},
+		{
+			description: "device-ifa",
+			request:     `{"id":"req1","user":{},"device":{"ifa":"ifa1"}}`,
+			expected:    "ifa1",
+		},
+		{
+			description: "request-id",
+			request:     `{"id":"req1","user":{"id":""}}`,
+			expected:    "req1",
+		},
+		{
+			description: "random",
+			request:     `{}`,
+			uuid:        fakeUUIDGenerator{id: "uuid1"},
+			expected:    "uuid1",
+		},
+		{
+			description: "random-error",
+			request:     `{}`,
+			uuid:        fakeUUIDGenerator{id: "uuid1", err: errors.New("error")},
+			expected:    "",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, getVariantKey([]byte(test.request), test.uuid))
+		})
+	}
+}
+
+func TestSetStoredVariants(t *testing.T) {
+	variants := &openrtb_ext.ExtStoredVariants{Requests: map[string]string{"req1": "v2"}, Account: "v1"}
+
+	testCases := []struct {
+		description string
+		ext         string
+		variants    *openrtb_ext.ExtStoredVariants
+		expectedExt string
+	}{
+		{
+			description: "no-variants",
+			ext:         `{"prebid":{"debug":true}}`,
+			expectedExt: `{"prebid":{"debug":true}}`,
+		},
+		{
+			description: "no-ext",
+			variants:    variants,
+			expectedExt: `{"prebid":{"storedvariants":{"requests":{"req1":"v2"},"account":"v1"}}}`,
+		},
+		{
+			description: "replaces-incoming-variants",
+			ext:         `{"prebid":{"debug":true,"storedvariants":{"account":"v9"}}}`,
+			variants:    variants,
+			expectedExt: `{"prebid":{"debug":true,"storedvariants":{"requests":{"req1":"v2"},"account":"v1"}}}`,
+		},
+		{
+			description: "removes-incoming-variants",
+			ext:         `{"prebid":{"debug":true,"storedvariants":{"account":"v9"}}}`,
+			expectedExt: `{"prebid":{"debug":true}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			req := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "req1"}}
+			if test.ext != "" {
+				req.Ext = json.RawMessage(test.ext)
+			}
+
+			require.NoError(t, setStoredVariants(req, test.variants))
+			require.NoError(t, req.RebuildRequest())
+			assert.JSONEq(t, test.expectedExt, string(req.Ext))
+		})
+	}
+}
+
 func TestMergeBidderParams(t *testing.T) {
 	testCases := []struct {
 		description         string
@@ -1546,81 +1718,6 @@ func TestMergeBidderParamsImpExtPrebid(t *testing.T) {
 	}
 }
 
-func TestValidateExactlyOneInventoryType(t *testing.T) {
-
-	testCases := []struct {
-		description         string
-		givenRequestWrapper *openrtb_ext.RequestWrapper
-		expectedError       error
-	}{
-		{
-			description:         "None provided - invalid",
-			givenRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{}},
-			expectedError:       errors.New("One of request.site or request.app or request.dooh must be defined"),
-		},
-		{
-			description: "Only site provided",
-			givenRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
-				Site: &openrtb2.Site{},
-			}},
-			expectedError: nil,
-		},
-		{
-			description: "Only app provided",
-			givenRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
-				App: &openrtb2.App{},
-			}},
-			expectedError: nil,
-		},
-		{
-			description: "Only dooh provided",
-			givenRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
-				DOOH: &openrtb2.DOOH{},
-			}},
-			expectedError: nil,
-		},
-		{
-			description: "Two provided (site+app) - invalid",
-			givenRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
-				Site: &openrtb2.Site{},
-				App:  &openrtb2.App{},
-			}},
-			expectedError: errors.New("No more than one of request.site or request.app or request.dooh can be defined"),
-		},
-		{
-			description: "Two provided (site+dooh) - invalid",
-			givenRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
-				Site: &openrtb2.Site{},
-				DOOH: &openrtb2.DOOH{},
-			}},
-			expectedError: errors.New("No more than one of request.site or request.app or request.dooh can be defined"),
-		},
-		{
-			description: "Two provided (app+dooh) - invalid",
-			givenRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
-				App:  &openrtb2.App{},
-				DOOH: &openrtb2.DOOH{},
-			}},
-			expectedError: errors.New("No more than one of request.site or request.app or request.dooh can be defined"),
-		},
-		{
-			description: "Three provided - invalid",
-			givenRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
-				Site: &openrtb2.Site{},
-				App:  &openrtb2.App{},
-				DOOH: &openrtb2.DOOH{},
-			}},
-			expectedError: errors.New("No more than one of request.site or request.app or request.dooh can be defined"),
-		},
-	}
-
-	for _, test := range testCases {
-		error := validateExactlyOneInventoryType(test.givenRequestWrapper)
-		assert.Equalf(t, test.expectedError, error, "Error doesn't match: %s\n", test.description)
-	}
-
-}
-
 func TestValidateRequest(t *testing.T) {
 	deps := &endpointDeps{
 		fakeUUIDGenerator{},
@@ -1643,6 +1740,8 @@ func TestValidateRequest(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	testCases := []struct {
@@ -2151,6 +2250,20 @@ func TestValidateTargeting(t *testing.T) {
 			},
 			expectedError: errors.New("Price granularity error: range list must be ordered with increasing \"max\""),
 		},
+		{
+			name: "adservercurrency-ok",
+			givenTargeting: &openrtb_ext.ExtRequestTargeting{
+				AdServerCurrency: "JPY",
+			},
+			expectedError: nil,
+		},
+		{
+			name: "adservercurrency-invalid",
+			givenTargeting: &openrtb_ext.ExtRequestTargeting{
+				AdServerCurrency: "YEN!",
+			},
+			expectedError: errors.New("ext.prebid.targeting.adservercurrency must be a valid ISO-4217 currency code, got: YEN!"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -2361,6 +2474,8 @@ func TestSetIntegrationType(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	testCases := []struct {
@@ -2428,6 +2543,8 @@ func TestStoredRequestGenerateUuid(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	req := &openrtb2.BidRequest{}
@@ -2533,6 +2650,8 @@ func TestOversizedRequest(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -2573,6 +2692,8 @@ func TestRequestSizeEdgeCase(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -2589,6 +2710,181 @@ func TestRequestSizeEdgeCase(t *testing.T) {
 	}
 }
 
+func TestAuctionRateLimited(t *testing.T) {
+	testCases := []struct {
+		description          string
+		action               string
+		expectedCode         int
+		expectedBody         string
+		expectedStatusMetric metrics.RequestStatus
+	}{
+		{
+			description:          "reject",
+			action:               config.RateLimitActionReject,
+			expectedCode:         http.StatusTooManyRequests,
+			expectedBody:         "Invalid request: Request rate of account unknown on auction is over 0.001 requests per second\n",
+			expectedStatusMetric: metrics.RequestStatusRateLimited,
+		},
+		{
+			description:          "no_bid",
+			action:               config.RateLimitActionNoBid,
+			expectedCode:         http.StatusOK,
+			expectedBody:         `{"id":"some-request-id","nbr":1}` + "\n",
+			expectedStatusMetric: metrics.RequestStatusRateLimited,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := &config.Configuration{MaxRequestSize: maxSize}
+			cfg.AccountDefaults.RateLimits = config.AccountRateLimits{
+				Enabled: true,
+				Default: config.AccountRateLimit{RequestsPerSecond: 0.001, Burst: 1},
+				Action:  tc.action,
+			}
+			metricsEngine := metrics.NewMetrics(gometrics.NewRegistry(), openrtb_ext.CoreBidderNames(), config.DisabledMetrics{}, nil, nil)
+
+			deps := &endpointDeps{
+				fakeUUIDGenerator{},
+				&nobidExchange{},
+				ortb.NewRequestValidator(openrtb_ext.BuildBidderMap(), map[string]string{}, mockBidderParamValidator{}),
+				&mockStoredReqFetcher{},
+				empty_fetcher.EmptyFetcher{},
+				empty_fetcher.EmptyFetcher{},
+				cfg,
+				metricsEngine,
+				analyticsBuild.New(&config.Analytics{}),
+				map[string]string{},
+				false,
+				[]byte{},
+				openrtb_ext.BuildBidderMap(),
+				nil,
+				nil,
+				hardcodedResponseIPValidator{response: true},
+				empty_fetcher.EmptyFetcher{},
+				hooks.EmptyPlanBuilder{},
+				nil,
+				openrtb_ext.NormalizeBidderName,
+				ratelimit.NewRateLimiter(config.RateLimiting{Enabled: true, Backend: "local"}, metricsEngine),
+				nil,
+			}
+			reqBody := validRequest(t, "site.json")
+
+			recorder := httptest.NewRecorder()
+			deps.Auction(recorder, httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody)), nil)
+			assert.Equal(t, http.StatusOK, recorder.Code, "the first request fits in the burst")
+
+			recorder = httptest.NewRecorder()
+			deps.Auction(recorder, httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody)), nil)
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+			assert.Equal(t, tc.expectedBody, recorder.Body.String())
+
+			assert.Equal(t, int64(1), metricsEngine.RateLimitMeter[metrics.ReqTypeORTB2Web][metrics.RateLimitLimited].Count())
+			assert.Equal(t, int64(1), metricsEngine.RequestStatuses[metrics.ReqTypeORTB2Web][tc.expectedStatusMetric].Count())
+		})
+	}
+}
+
+func TestAuctionMirrored(t *testing.T) {
+	testCases := []struct {
+		description    string
+		mirroring      config.AccountMirroring
+		expectMirrored bool
+	}{
+		{
+			description:    "sampled",
+			mirroring:      config.AccountMirroring{SampleRate: 1},
+			expectMirrored: true,
+		},
+		{
+			description: "endpoint_not_mirrored",
+			mirroring:   config.AccountMirroring{SampleRate: 1, Endpoints: []string{config.MirroringEndpointAMP}},
+		},
+		{
+			description: "not_sampled",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := &config.Configuration{MaxRequestSize: maxSize}
+			cfg.AccountDefaults.Mirroring = tc.mirroring
+			requestMirror, mirrorPath := newTestMirror(t)
+			ex := &mockExchange{}
+
+			endpoint, _ := NewEndpoint(
+				fakeUUIDGenerator{},
+				ex,
+				ortb.NewRequestValidator(openrtb_ext.BuildBidderMap(), map[string]string{}, mockBidderParamValidator{}),
+				&mockStoredReqFetcher{},
+				empty_fetcher.EmptyFetcher{},
+				cfg,
+				&metricsConfig.NilMetricsEngine{},
+				analyticsBuild.New(&config.Analytics{}),
+				map[string]string{},
+				[]byte{},
+				openrtb_ext.BuildBidderMap(),
+				empty_fetcher.EmptyFetcher{},
+				hooks.EmptyPlanBuilder{},
+				nil,
+				requestMirror,
+				nil,
+			)
+
+			recorder := httptest.NewRecorder()
+			endpoint(recorder, httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json"))), nil)
+			assert.Equal(t, http.StatusOK, recorder.Code)
+
+			mirrored := readMirroredRequests(t, requestMirror, mirrorPath)
+			if !tc.expectMirrored {
+				assert.Empty(t, mirrored)
+				return
+			}
+			require.Len(t, mirrored, 1)
+			assert.Equal(t, config.MirroringEndpointAuction, mirrored[0].Endpoint)
+			assert.Equal(t, ex.lastRequest.ID, mirrored[0].BidRequest.ID)
+			assert.Equal(t, len(ex.lastRequest.Imp), len(mirrored[0].BidRequest.Imp))
+		})
+	}
+}
+
+type mirroredRequest struct {
+	Endpoint   string              `json:"endpoint"`
+	Account    string              `json:"account"`
+	BidRequest openrtb2.BidRequest `json:"request"`
+}
+
+// newTestMirror returns a Mirror appending the requests to a file of the test.
+func newTestMirror(t *testing.T) (*mirror.Mirror, string) {
+	path := t.TempDir() + "/mirror.ndjson"
+	requestMirror, err := mirror.NewMirror(config.Mirroring{
+		Enabled:   true,
+		Target:    config.MirroringTargetFile,
+		File:      config.MirroringFile{Path: path},
+		QueueSize: 10,
+	}, http.DefaultClient, &metricsConfig.NilMetricsEngine{})
+	require.NoError(t, err)
+	return requestMirror, path
+}
+
+// readMirroredRequests shuts the mirror down, so the queued requests are written, and reads them.
+func readMirroredRequests(t *testing.T, requestMirror *mirror.Mirror, path string) []mirroredRequest {
+	requestMirror.Shutdown()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var mirrored []mirroredRequest
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		if line == "" {
+			continue
+		}
+		var req mirroredRequest
+		require.NoError(t, json.Unmarshal([]byte(line), &req))
+		mirrored = append(mirrored, req)
+	}
+	return mirrored
+}
+
 // TestNoEncoding prevents #231.
 func TestNoEncoding(t *testing.T) {
 	endpoint, _ := NewEndpoint(
@@ -2606,6 +2902,8 @@ func TestNoEncoding(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -2691,6 +2989,8 @@ func TestContentType(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -2734,6 +3034,8 @@ func TestCurrencyTrunc(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	ui := int64(1)
@@ -2783,6 +3085,8 @@ func TestCCPAInvalid(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	ui := int64(1)
@@ -2836,6 +3140,8 @@ func TestNoSaleInvalid(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	ui := int64(1)
@@ -2892,6 +3198,8 @@ func TestValidateSourceTID(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	ui := int64(1)
@@ -2938,6 +3246,8 @@ func TestSChainInvalid(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	ui := int64(1)
@@ -3379,6 +3689,8 @@ func TestEidPermissionsInvalid(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	ui := int64(1)
@@ -3653,6 +3965,8 @@ func TestIOS14EndToEnd(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "app-ios140-no-ifa.json")))
@@ -3721,6 +4035,8 @@ func TestAuctionWarnings(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
@@ -3768,6 +4084,8 @@ func TestParseRequestParseImpInfoError(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
@@ -3849,6 +4167,8 @@ func TestParseGzipedRequest(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
@@ -3952,6 +4272,8 @@ func TestAuctionResponseHeaders(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		nil,
+		nil,
 	)
 
 	for _, test := range testCases {
@@ -4032,6 +4354,8 @@ func TestParseRequestMergeBidderParams(t *testing.T) {
 				hooks.EmptyPlanBuilder{},
 				nil,
 				openrtb_ext.NormalizeBidderName,
+				nil,
+				nil,
 			}
 
 			hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
@@ -4138,6 +4462,8 @@ func TestParseRequestStoredResponses(t *testing.T) {
 				hooks.EmptyPlanBuilder{},
 				nil,
 				openrtb_ext.NormalizeBidderName,
+				nil,
+				nil,
 			}
 
 			hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
@@ -4251,6 +4577,8 @@ func TestParseRequestStoredBidResponses(t *testing.T) {
 				hooks.EmptyPlanBuilder{},
 				nil,
 				openrtb_ext.NormalizeBidderName,
+				nil,
+				nil,
 			}
 
 			hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
@@ -4289,6 +4617,8 @@ func TestValidateStoredResp(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	testCases := []struct {
@@ -5107,6 +5437,8 @@ func TestParseRequestMultiBid(t *testing.T) {
 				hooks.EmptyPlanBuilder{},
 				nil,
 				openrtb_ext.NormalizeBidderName,
+				nil,
+				nil,
 			}
 
 			hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
@@ -5161,6 +5493,42 @@ func getIntegrationFromRequest(req *openrtb_ext.RequestWrapper) (string, error)
 	return reqPrebid.Integration, nil
 }
 
+func TestSendAuctionResponse_ExitpointHook(t *testing.T) {
+	planBuilder := mockPlanBuilder{exitpointPlan: makePlan[hookstage.Exitpoint](mockExitpointHook{})}
+	hookExecutor := hookexecution.NewHookExecutor(planBuilder, hookexecution.EndpointAuction, &metricsConfig.NilMetricsEngine{})
+	hookExecutor.SetAccount(&config.Account{})
+
+	writer := httptest.NewRecorder()
+	response := &openrtb2.BidResponse{ID: "some-id"}
+
+	_, ao := sendAuctionResponse(writer, hookExecutor, response, &openrtb2.BidRequest{ID: "some-id"}, &config.Account{}, metrics.Labels{}, analytics.AuctionObject{})
+
+	assert.Empty(t, ao.Errors)
+	assert.Equal(t, `{"id":"some-id","exitpoint":true}`, writer.Body.String())
+	assert.Equal(t, "exitpoint", writer.Header().Get("X-Hook"))
+	if assert.Len(t, ao.HookExecutionOutcome, 1) {
+		assert.Equal(t, hooks.StageExitpoint.String(), ao.HookExecutionOutcome[0].Stage)
+	}
+}
+
+// mockExitpointHook replaces the encoded response and adds a header
+type mockExitpointHook struct{}
+
+func (m mockExitpointHook) HandleExitpointHook(
+	_ context.Context,
+	_ hookstage.ModuleInvocationContext,
+	_ hookstage.ExitpointPayload,
+) (hookstage.HookResult[hookstage.ExitpointPayload], error) {
+	changeSet := hookstage.ChangeSet[hookstage.ExitpointPayload]{}
+	changeSet.AddMutation(func(payload hookstage.ExitpointPayload) (hookstage.ExitpointPayload, error) {
+		payload.Headers.Set("X-Hook", "exitpoint")
+		payload.Body = []byte(`{"id":"some-id","exitpoint":true}`)
+		return payload, nil
+	}, hookstage.MutationUpdate, "response")
+
+	return hookstage.HookResult[hookstage.ExitpointPayload]{ChangeSet: changeSet}, nil
+}
+
 type mockStageExecutor struct {
 	hookexecution.EmptyHookExecutor
 
@@ -5217,62 +5585,6 @@ func TestSetSeatNonBidRaw(t *testing.T) {
 	}
 }
 
-func TestValidateAliases(t *testing.T) {
-	deps := &endpointDeps{
-		disabledBidders: map[string]string{"rubicon": "rubicon"},
-		bidderMap:       map[string]openrtb_ext.BidderName{"appnexus": openrtb_ext.BidderName("appnexus")},
-	}
-
-	testCases := []struct {
-		description     string
-		aliases         map[string]string
-		expectedAliases map[string]string
-		expectedError   error
-	}{
-		{
-			description:     "valid case",
-			aliases:         map[string]string{"test": "appnexus"},
-			expectedAliases: map[string]string{"test": "appnexus"},
-			expectedError:   nil,
-		},
-		{
-			description:     "valid case - case insensitive",
-			aliases:         map[string]string{"test": "Appnexus"},
-			expectedAliases: map[string]string{"test": "appnexus"},
-			expectedError:   nil,
-		},
-		{
-			description:     "disabled bidder",
-			aliases:         map[string]string{"test": "rubicon"},
-			expectedAliases: nil,
-			expectedError:   errors.New("request.ext.prebid.aliases.test refers to disabled bidder: rubicon"),
-		},
-		{
-			description:     "coreBidderName not found",
-			aliases:         map[string]string{"test": "anyBidder"},
-			expectedAliases: nil,
-			expectedError:   errors.New("request.ext.prebid.aliases.test refers to unknown bidder: anyBidder"),
-		},
-		{
-			description:     "alias name is coreBidder name",
-			aliases:         map[string]string{"appnexus": "appnexus"},
-			expectedAliases: nil,
-			expectedError:   errors.New("request.ext.prebid.aliases.appnexus defines a no-op alias. Choose a different alias, or remove this entry."),
-		},
-	}
-
-	for _, testCase := range testCases {
-		t.Run(testCase.description, func(t *testing.T) {
-			err := deps.validateAliases(testCase.aliases)
-			if err != nil {
-				assert.Equal(t, testCase.expectedError, err)
-			} else {
-				assert.ObjectsAreEqualValues(testCase.expectedAliases, map[string]string{"test": "appnexus"})
-			}
-		})
-	}
-}
-
 func fakeNormalizeBidderName(name string) (openrtb_ext.BidderName, bool) {
 	return openrtb_ext.BidderName(strings.ToLower(name)), true
 }
@@ -5823,6 +6135,8 @@ func TestValidateRequestCookieDeprecation(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	for _, test := range testCases {
@@ -29,8 +29,10 @@ import (
 	"github.com/prebid/prebid-server/v3/errortypes"
 	"github.com/prebid/prebid-server/v3/exchange"
 	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/mirror"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/prebid_cache_client"
+	"github.com/prebid/prebid-server/v3/ratelimit"
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/v3/usersync"
@@ -57,10 +59,13 @@ func NewVideoEndpoint(
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
 	cache prebid_cache_client.Client,
+	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
 	tmaxAdjustments *exchange.TmaxAdjustmentsPreprocessed,
+	requestMirror *mirror.Mirror,
+	rateLimiter *ratelimit.RateLimiter,
 ) (httprouter.Handle, error) {
 
-	if ex == nil || requestValidator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
+	if ex == nil || requestValidator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil || hookExecutionPlanBuilder == nil {
 		return nil, errors.New("NewVideoEndpoint requires non-nil arguments.")
 	}
 
@@ -91,9 +96,11 @@ func NewVideoEndpoint(
 		videoEndpointRegexp,
 		ipValidator,
 		empty_fetcher.EmptyFetcher{},
-		hooks.EmptyPlanBuilder{},
+		hookExecutionPlanBuilder,
 		tmaxAdjustments,
-		openrtb_ext.NormalizeBidderName}).VideoAuctionEndpoint), nil
+		openrtb_ext.NormalizeBidderName,
+		rateLimiter,
+		requestMirror}).VideoAuctionEndpoint), nil
 }
 
 /*
@@ -128,6 +135,8 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		StartTime: start,
 	}
 
+	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointVideo, deps.metricsEngine)
+
 	labels := metrics.Labels{
 		Source:        metrics.DemandUnknown,
 		RType:         metrics.ReqTypeVideo,
@@ -187,6 +196,10 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		}
 	}
 
+	// the variants of versioned stored data are picked once for the request, so the account uses the same key
+	variants := stored_requests.NewVariantSelection(getVariantKey(requestJson, deps.uuidGenerator))
+	variantsCtx := stored_requests.WithVariantSelection(context.Background(), variants)
+
 	//load additional data - stored simplified req
 	storedRequestId, err := getVideoStoredRequestId(requestJson)
 
@@ -196,7 +209,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 			return
 		}
 	} else {
-		storedRequest, errs := deps.loadStoredVideoRequest(context.Background(), storedRequestId)
+		storedRequest, errs := deps.loadStoredVideoRequest(variantsCtx, storedRequestId, requestJson)
 		if len(errs) > 0 {
 			handleError(&labels, w, errs, &vo, &debugLog)
 			return
@@ -241,7 +254,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	}
 
 	//create impressions array
-	imps, podErrors := deps.createImpressions(videoBidReq, podErrors)
+	imps, podErrors := deps.createImpressions(variantsCtx, videoBidReq, podErrors)
 
 	if len(podErrors) == initialPodNumber {
 		resPodErr := make([]string, 0)
@@ -270,7 +283,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	ctx := context.Background()
+	ctx := variantsCtx
 	timeout := deps.cfg.AuctionTimeouts.LimitAuctionTimeout(time.Duration(bidReqWrapper.TMax) * time.Millisecond)
 	if timeout > 0 {
 		var cancel context.CancelFunc
@@ -303,6 +316,26 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if err := deps.rateLimiter.Limit(ctx, account, config.RateLimitEndpointVideo, labels.RType, bidReqWrapper.BidRequest); err != nil {
+		labels.RequestStatus = metrics.RequestStatusRateLimited
+		vo.Errors = append(vo.Errors, err)
+		if rateLimitErr := findRateLimitedNoBid([]error{err}); rateLimitErr != nil {
+			vo.VideoResponse = &openrtb_ext.BidResponseVideo{AdPods: []*openrtb_ext.AdPod{}}
+			resp, _ := jsonutil.Marshal(vo.VideoResponse)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(resp)
+			return
+		}
+		vo.Status = http.StatusTooManyRequests
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(w, "Invalid request: %s\n", err.Error())
+		return
+	}
+
+	if err := setStoredVariants(bidReqWrapper, variants.Selected()); err != nil {
+		errL = append(errL, err)
+	}
+
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	if errs := deps.setFieldsImplicitly(r, bidReqWrapper, account); len(errs) > 0 {
 		errL = append(errL, errs...)
@@ -316,10 +349,15 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	}
 
 	activityControl = privacy.NewActivityControl(&account.Privacy)
+	hookExecutor.SetActivityControl(activityControl)
+	hookExecutor.SetAccount(account)
 
 	warnings := errortypes.WarningOnly(errL)
 
 	secGPC := r.Header.Get("Sec-GPC")
+
+	deps.mirrorRequest(config.MirroringEndpointVideo, account, labels.RType, bidReqWrapper)
+
 	auctionRequest := &exchange.AuctionRequest{
 		BidRequestWrapper:          bidReqWrapper,
 		Account:                    *account,
@@ -330,7 +368,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		Warnings:                   warnings,
 		GlobalPrivacyControlHeader: secGPC,
 		PubID:                      labels.PubID,
-		HookExecutor:               hookexecution.EmptyHookExecutor{},
+		HookExecutor:               hookExecutor,
 		TmaxAdjustments:            deps.tmaxAdjustments,
 		Activities:                 activityControl,
 	}
@@ -394,6 +432,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	resp, vo.HookExecutionOutcome = executeExitpointStage(w, hookExecutor, resp)
 	w.Write(resp)
 }
 
@@ -438,7 +477,7 @@ func handleError(labels *metrics.Labels, w http.ResponseWriter, errL []error, vo
 	vo.Errors = append(vo.Errors, errL...)
 }
 
-func (deps *endpointDeps) createImpressions(videoReq *openrtb_ext.BidRequestVideo, podErrors []PodError) ([]openrtb2.Imp, []PodError) {
+func (deps *endpointDeps) createImpressions(ctx context.Context, videoReq *openrtb_ext.BidRequestVideo, podErrors []PodError) ([]openrtb2.Imp, []PodError) {
 	videoDur := videoReq.PodConfig.DurationRangeSec
 	minDuration, maxDuration := minMax(videoDur)
 	reqExactDur := videoReq.PodConfig.RequireExactDuration
@@ -449,7 +488,7 @@ func (deps *endpointDeps) createImpressions(videoReq *openrtb_ext.BidRequestVide
 
 		//load stored impression
 		storedImpressionId := string(pod.ConfigId)
-		storedImp, errs := deps.loadStoredImp(storedImpressionId)
+		storedImp, errs := deps.loadStoredImp(ctx, storedImpressionId, videoReq)
 		if errs != nil {
 			err := fmt.Sprintf("unable to load configid %s, Pod id: %d", storedImpressionId, pod.PodId)
 			podErr := PodError{}
@@ -507,8 +546,9 @@ func createImpressionTemplate(imp openrtb2.Imp, video *openrtb2.Video) openrtb2.
 	return imp
 }
 
-func (deps *endpointDeps) loadStoredImp(storedImpId string) (openrtb2.Imp, []error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(deps.cfg.StoredRequestsTimeout)*time.Millisecond)
+// loadStoredImp loads the stored imp of a pod. A templated stored imp is resolved from the video request.
+func (deps *endpointDeps) loadStoredImp(ctx context.Context, storedImpId string, videoReq *openrtb_ext.BidRequestVideo) (openrtb2.Imp, []error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(deps.cfg.StoredRequestsTimeout)*time.Millisecond)
 	defer cancel()
 
 	impr := openrtb2.Imp{}
@@ -516,8 +556,22 @@ func (deps *endpointDeps) loadStoredImp(storedImpId string) (openrtb2.Imp, []err
 	if err != nil {
 		return impr, err
 	}
+	if imp, err = stored_requests.VariantSelectionFromContext(ctx).Imps(imp); err != nil {
+		return impr, err
+	}
 
-	if err := jsonutil.UnmarshalValid(imp[storedImpId], &impr); err != nil {
+	storedImp := imp[storedImpId]
+	if stored_requests.IsTemplate(storedImp) {
+		requestJson, err := jsonutil.Marshal(videoReq)
+		if err != nil {
+			return impr, []error{err}
+		}
+		if storedImp, err = stored_requests.ResolveTemplate(storedImp, stored_requests.TemplateValues{Request: requestJson}); err != nil {
+			return impr, []error{err}
+		}
+	}
+
+	if err := jsonutil.UnmarshalValid(storedImp, &impr); err != nil {
 		return impr, []error{err}
 	}
 	return impr, nil
@@ -616,10 +670,20 @@ func findAdPod(podInd int64, pods []*openrtb_ext.AdPod) *openrtb_ext.AdPod {
 	return nil
 }
 
-func (deps *endpointDeps) loadStoredVideoRequest(ctx context.Context, storedRequestId string) ([]byte, []error) {
+// loadStoredVideoRequest loads the stored video request. A templated stored request is resolved from the incoming request.
+func (deps *endpointDeps) loadStoredVideoRequest(ctx context.Context, storedRequestId string, requestJson []byte) ([]byte, []error) {
 	storedRequests, _, errs := deps.videoFetcher.FetchRequests(ctx, []string{storedRequestId}, []string{})
-	jsonString := storedRequests[storedRequestId]
-	return jsonString, errs
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if storedRequests, errs = stored_requests.VariantSelectionFromContext(ctx).Requests(storedRequests); len(errs) > 0 {
+		return nil, errs
+	}
+	storedRequest, err := stored_requests.ResolveTemplate(storedRequests[storedRequestId], stored_requests.TemplateValues{Request: requestJson})
+	if err != nil {
+		return nil, []error{fmt.Errorf("storedrequestid %s: %v", storedRequestId, err)}
+	}
+	return storedRequest, nil
 }
 
 func getVideoStoredRequestId(request []byte) (string, error) {
@@ -17,12 +17,14 @@ import (
 	"github.com/prebid/prebid-server/v3/errortypes"
 	"github.com/prebid/prebid-server/v3/exchange"
 	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
 	"github.com/prebid/prebid-server/v3/metrics"
 	metricsConfig "github.com/prebid/prebid-server/v3/metrics/config"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/ortb"
 	"github.com/prebid/prebid-server/v3/prebid_cache_client"
 	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/ratelimit"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 	"github.com/prebid/prebid-server/v3/util/ptrutil"
@@ -68,6 +70,73 @@ func TestVideoEndpointImpressionsNumber(t *testing.T) {
 	assert.Equal(t, "ABC_123", resp.AdPods[0].Targeting[0].HbDeal, "If DealID exists in bid response, hb_deal targeting needs to be added to resp")
 }
 
+func TestVideoEndpointRateLimited(t *testing.T) {
+	testCases := []struct {
+		description  string
+		action       string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			description:  "reject",
+			action:       config.RateLimitActionReject,
+			expectedCode: http.StatusTooManyRequests,
+			expectedBody: "Invalid request: Request rate of account unknown on video is over 0.001 requests per second\n",
+		},
+		{
+			description:  "no_bid",
+			action:       config.RateLimitActionNoBid,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"adPods":[]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ex := &mockExchangeVideo{}
+			deps := mockDeps(t, ex)
+			deps.accounts = empty_fetcher.EmptyFetcher{}
+			deps.cfg.AccountDefaults.RateLimits = config.AccountRateLimits{
+				Enabled: true,
+				Default: config.AccountRateLimit{RequestsPerSecond: 0.001, Burst: 1},
+				Action:  tc.action,
+			}
+			deps.rateLimiter = ratelimit.NewRateLimiter(config.RateLimiting{Enabled: true, Backend: "local"}, deps.metricsEngine)
+			reqBody := readVideoTestFile(t, "sample-requests/video/video_valid_sample.json")
+
+			recorder := httptest.NewRecorder()
+			deps.VideoAuctionEndpoint(recorder, httptest.NewRequest("POST", "/openrtb2/video", strings.NewReader(reqBody)), nil)
+			assert.Equal(t, http.StatusOK, recorder.Code, "the first request fits in the burst")
+			ex.lastRequest = nil
+
+			recorder = httptest.NewRecorder()
+			deps.VideoAuctionEndpoint(recorder, httptest.NewRequest("POST", "/openrtb2/video", strings.NewReader(reqBody)), nil)
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+			assert.Equal(t, tc.expectedBody, recorder.Body.String())
+			assert.Nil(t, ex.lastRequest, "the limited request doesn't run an auction")
+		})
+	}
+}
+
+func TestVideoEndpointMirrored(t *testing.T) {
+	ex := &mockExchangeVideo{}
+	deps := mockDeps(t, ex)
+	deps.accounts = empty_fetcher.EmptyFetcher{}
+	deps.cfg.AccountDefaults.Mirroring = config.AccountMirroring{SampleRate: 1}
+	requestMirror, mirrorPath := newTestMirror(t)
+	deps.mirror = requestMirror
+	reqBody := readVideoTestFile(t, "sample-requests/video/video_valid_sample.json")
+
+	recorder := httptest.NewRecorder()
+	deps.VideoAuctionEndpoint(recorder, httptest.NewRequest("POST", "/openrtb2/video", strings.NewReader(reqBody)), nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	mirrored := readMirroredRequests(t, requestMirror, mirrorPath)
+	require.Len(t, mirrored, 1)
+	assert.Equal(t, config.MirroringEndpointVideo, mirrored[0].Endpoint)
+	assert.Len(t, mirrored[0].BidRequest.Imp, len(ex.lastRequest.Imp), "the pods are resolved to impressions")
+}
+
 func TestVideoEndpointImpressionsDuration(t *testing.T) {
 	ex := &mockExchangeVideo{}
 	reqBody := readVideoTestFile(t, "sample-requests/video/video_valid_sample_different_durations.json")
@@ -893,6 +962,23 @@ func TestHandleErrorMetrics(t *testing.T) {
 	assert.Equal(t, "request missing required field: PodConfig.Pods", mod.videoObjects[0].Errors[1].Error(), "Second error in AnalyticsObject should have message regarding Pods")
 }
 
+func TestVideoEndpointExitpointHook(t *testing.T) {
+	ex := &mockExchangeVideo{}
+	reqBody := readVideoTestFile(t, "sample-requests/video/video_valid_sample.json")
+	req := httptest.NewRequest("POST", "/openrtb2/video", strings.NewReader(reqBody))
+	recorder := httptest.NewRecorder()
+
+	deps, _, mod := mockDepsWithMetrics(t, ex)
+	deps.hookExecutionPlanBuilder = mockPlanBuilder{exitpointPlan: makePlan[hookstage.Exitpoint](mockExitpointHook{})}
+	deps.VideoAuctionEndpoint(recorder, req, nil)
+
+	assert.Equal(t, `{"id":"some-id","exitpoint":true}`, recorder.Body.String())
+	assert.Equal(t, "exitpoint", recorder.Header().Get("X-Hook"))
+	if assert.Len(t, mod.videoObjects, 1) && assert.Len(t, mod.videoObjects[0].HookExecutionOutcome, 1) {
+		assert.Equal(t, hooks.StageExitpoint.String(), mod.videoObjects[0].HookExecutionOutcome[0].Stage)
+	}
+}
+
 func TestParseVideoRequestWithUserAgentAndHeader(t *testing.T) {
 	ex := &mockExchangeVideo{}
 	reqBody := readVideoTestFile(t, "sample-requests/video/video_valid_sample_with_device_user_agent.json")
@@ -1252,6 +1338,8 @@ func mockDepsWithMetrics(t *testing.T, ex *mockExchangeVideo) (*endpointDeps, *m
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 	return deps, metrics, mockModule
 }
@@ -1303,6 +1391,8 @@ func mockDeps(t *testing.T, ex *mockExchangeVideo) *endpointDeps {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 }
 
@@ -1328,6 +1418,8 @@ func mockDepsAppendBidderNames(t *testing.T, ex *mockExchangeAppendBidderNames)
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	return deps
@@ -1355,6 +1447,8 @@ func mockDepsNoBids(t *testing.T, ex *mockExchangeVideoNoBids) *endpointDeps {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		nil,
+		nil,
 	}
 
 	return edep
@@ -1378,6 +1472,28 @@ func (m *mockCacheClient) GetExtCacheData() (scheme string, host string, path st
 type mockVideoStoredReqFetcher struct {
 }
 
+// mockVideoTemplateFetcher returns the same templated data for every id.
+type mockVideoTemplateFetcher struct {
+	request json.RawMessage
+	imp     json.RawMessage
+}
+
+func (cf mockVideoTemplateFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error) {
+	requestData = make(map[string]json.RawMessage, len(requestIDs))
+	for _, id := range requestIDs {
+		requestData[id] = cf.request
+	}
+	impData = make(map[string]json.RawMessage, len(impIDs))
+	for _, id := range impIDs {
+		impData[id] = cf.imp
+	}
+	return requestData, impData, nil
+}
+
+func (cf mockVideoTemplateFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return nil, nil
+}
+
 func (cf mockVideoStoredReqFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error) {
 	return testVideoStoredRequestData, testVideoStoredImpData, nil
 }
@@ -1386,6 +1502,30 @@ func (cf mockVideoStoredReqFetcher) FetchResponses(ctx context.Context, ids []st
 	return nil, nil
 }
 
+func TestVideoStoredTemplates(t *testing.T) {
+	fetcher := mockVideoTemplateFetcher{
+		request: json.RawMessage(`{"site":{"page":"https://{{request.site.domain}}/video"}}`),
+		imp:     json.RawMessage(`{"id":"pod","tagid":"{{request.site.domain}}","video":{"w":"{{request.device.w:int=640}}"}}`),
+	}
+	deps := &endpointDeps{
+		cfg:              &config.Configuration{StoredRequestsTimeout: 50},
+		videoFetcher:     fetcher,
+		storedReqFetcher: fetcher,
+	}
+
+	storedRequest, errs := deps.loadStoredVideoRequest(context.Background(), "req", []byte(`{"site":{"domain":"example.com"}}`))
+	require.Empty(t, errs)
+	assert.JSONEq(t, `{"site":{"page":"https://example.com/video"}}`, string(storedRequest))
+
+	imp, errs := deps.loadStoredImp(context.Background(), "imp", &openrtb_ext.BidRequestVideo{Site: &openrtb2.Site{Domain: "example.com"}})
+	require.Empty(t, errs)
+	assert.Equal(t, "example.com", imp.TagID)
+	assert.Equal(t, ptrutil.ToPtr[int64](640), imp.Video.W)
+
+	_, errs = deps.loadStoredVideoRequest(context.Background(), "req", []byte(`{}`))
+	assert.Equal(t, []error{errors.New("storedrequestid req: template parameter request.site.domain is missing")}, errs)
+}
+
 type mockExchangeVideo struct {
 	lastRequest *openrtb2.BidRequest
 	cache       *mockCacheClient
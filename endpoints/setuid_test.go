@@ -17,6 +17,7 @@ import (
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/errortypes"
 	"github.com/prebid/prebid-server/v3/gdpr"
+	"github.com/prebid/prebid-server/v3/hooks"
 	"github.com/prebid/prebid-server/v3/macros"
 	"github.com/prebid/prebid-server/v3/metrics"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
@@ -1578,6 +1579,51 @@ func assertHasSyncs(t *testing.T, testCase string, resp *httptest.ResponseRecord
 	}
 }
 
+func TestSetUIDEndpointHooks(t *testing.T) {
+	testCases := []struct {
+		description          string
+		givenHook            fakeUserSyncHook
+		expectedResponseCode int
+		expectedSyncs        map[string]string
+	}{
+		{
+			description:          "hook-updates-uid",
+			givenHook:            fakeUserSyncHook{},
+			expectedResponseCode: http.StatusOK,
+			expectedSyncs:        map[string]string{"pubmatic": "ABC"},
+		},
+		{
+			description:          "hook-rejects",
+			givenHook:            fakeUserSyncHook{reject: true},
+			expectedResponseCode: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := config.Configuration{UserSync: config.UserSync{PriorityGroups: [][]string{{"pubmatic"}}}}
+			assert.NoError(t, cfg.MarshalAccountDefaults())
+
+			gdprPermsBuilder := fakePermissionsBuilder{permissions: &fakePermsSetUID{allowHost: true, personalInfoAllowed: true}}.Builder
+			tcf2ConfigBuilder := fakeTCF2ConfigBuilder{cfg: gdpr.NewTCF2Config(config.TCF2{}, config.AccountGDPR{})}.Builder
+			syncersByBidder := map[string]usersync.Syncer{"pubmatic": fakeSyncer{key: "pubmatic", defaultSyncType: usersync.SyncTypeIFrame}}
+
+			endpoint := NewSetUIDEndpoint(&cfg, syncersByBidder, gdprPermsBuilder, tcf2ConfigBuilder, analyticsBuild.New(&config.Analytics{}),
+				FakeAccountsFetcher{}, &metricsConf.NilMetricsEngine{}, fakeUserSyncPlanBuilder{hook: test.givenHook})
+
+			response := httptest.NewRecorder()
+			endpoint(response, makeRequest("/setuid?bidder=pubmatic&uid=abc&gdpr=0", nil), nil)
+
+			assert.Equal(t, test.expectedResponseCode, response.Code)
+			if test.expectedSyncs == nil {
+				assert.Empty(t, response.Header().Get("Set-Cookie"))
+				return
+			}
+			assertHasSyncs(t, test.description, response, test.expectedSyncs)
+		})
+	}
+}
+
 func makeRequest(uri string, existingSyncs map[string]string) *http.Request {
 	request := httptest.NewRequest("GET", uri, nil)
 	if len(existingSyncs) > 0 {
@@ -1639,7 +1685,7 @@ func doRequest(req *http.Request, analytics analytics.Runner, metrics metrics.Me
 		"valid_acct_with_invalid_activities":                 json.RawMessage(`{"privacy":{"allowactivities":{"syncUser":{"rules":[{"condition":{"componentName": ["bidderA.bidderB.bidderC"]}}]}}}}`),
 	}}
 
-	endpoint := NewSetUIDEndpoint(&cfg, syncersByBidder, gdprPermsBuilder, tcf2ConfigBuilder, analytics, fakeAccountsFetcher, metrics)
+	endpoint := NewSetUIDEndpoint(&cfg, syncersByBidder, gdprPermsBuilder, tcf2ConfigBuilder, analytics, fakeAccountsFetcher, metrics, hooks.EmptyPlanBuilder{})
 	response := httptest.NewRecorder()
 	endpoint(response, req, nil)
 	return response
@@ -3,12 +3,16 @@ package endpoints
 import (
 	"net/http"
 
+	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/v3/health"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
 )
 
-// NewStatusEndpoint returns a handler which writes the given response when the app is ready to serve requests.
+// NewStatusEndpoint returns a handler which writes the given response when the app is up. It backs /status and the
+// /status/live liveness endpoint.
 func NewStatusEndpoint(response string) httprouter.Handle {
-	// Today, the app always considers itself ready to serve requests.
+	// The app is considered up as long as it responds. NewReadyEndpoint tells if it can serve requests.
 	if response == "" {
 		return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 			w.WriteHeader(http.StatusNoContent)
@@ -20,3 +24,23 @@ func NewStatusEndpoint(response string) httprouter.Handle {
 		w.Write(responseBytes)
 	}
 }
+
+// NewReadyEndpoint returns a handler which writes the status of the readiness checks. It responds with a 503 while
+// the data needed to serve requests is loading, or when the traffic is drained on shutdown.
+func NewReadyEndpoint(checker *health.Checker) httprouter.Handle {
+	return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		status := checker.Status()
+		jsonOutput, err := jsonutil.Marshal(status)
+		if err != nil {
+			glog.Errorf("/status/ready Critical error when trying to marshal the response: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if !status.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write(jsonOutput)
+	}
+}
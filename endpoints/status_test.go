@@ -1,9 +1,13 @@
 package endpoints
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/prebid/prebid-server/v3/health"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestStatusNoContent(t *testing.T) {
@@ -26,3 +30,50 @@ func TestStatusWithContent(t *testing.T) {
 		t.Errorf("Bad status body. Expected %s, got %s", "ready", w.Body.String())
 	}
 }
+
+func TestReady(t *testing.T) {
+	testCases := []struct {
+		description  string
+		check        health.Check
+		drain        bool
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			description:  "ready",
+			check:        func() error { return nil },
+			expectedCode: http.StatusOK,
+			expectedBody: `{"ready":true,"checks":{"gvl":"ok"}}`,
+		},
+		{
+			description:  "loading",
+			check:        func() error { return errors.New("vendor lists not loaded yet") },
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"ready":false,"checks":{"gvl":"vendor lists not loaded yet"}}`,
+		},
+		{
+			description:  "draining",
+			check:        func() error { return nil },
+			drain:        true,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"ready":false,"draining":true,"checks":{"gvl":"ok"}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			checker := health.NewChecker()
+			checker.Register("gvl", test.check)
+			if test.drain {
+				checker.Drain()
+			}
+
+			w := httptest.NewRecorder()
+			NewReadyEndpoint(checker)(w, nil, nil)
+
+			assert.Equal(t, test.expectedCode, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.JSONEq(t, test.expectedBody, w.Body.String())
+		})
+	}
+}
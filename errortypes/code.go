@@ -18,6 +18,7 @@ const (
 	FailedToMarshalErrorCode
 	FailedToUnmarshalErrorCode
 	InvalidImpFirstPartyDataErrorCode
+	RateLimitedErrorCode
 )
 
 // Defines numeric codes for well-known warnings.
@@ -37,6 +38,9 @@ const (
 	SecBrowsingTopicsWarningCode
 	InvalidUserEIDsWarningCode
 	InvalidUserUIDsWarningCode
+	AdServerCurrencyWarningCode
+	InvalidVASTWarningCode
+	AccountTargetingWarningCode
 )
 
 // Coder provides an error or warning code with severity.
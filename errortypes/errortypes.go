@@ -289,3 +289,22 @@ func (err *InvalidImpFirstPartyData) Code() int {
 func (err *InvalidImpFirstPartyData) Severity() Severity {
 	return SeverityFatal
 }
+
+// RateLimited should be used when a request is over the rate limits of its account.
+// NoBid is set when the account asks for a no-bid response instead of a 429 Too Many Requests status.
+type RateLimited struct {
+	Message string
+	NoBid   bool
+}
+
+func (err *RateLimited) Error() string {
+	return err.Message
+}
+
+func (err *RateLimited) Code() int {
+	return RateLimitedErrorCode
+}
+
+func (err *RateLimited) Severity() Severity {
+	return SeverityFatal
+}
@@ -20,14 +20,16 @@ func BuildAdapters(client *http.Client, cfg *config.Configuration, infos config.
 
 	exchangeBidders := make(map[openrtb_ext.BidderName]AdaptedBidder, len(bidders))
 	for bidderName, bidder := range bidders {
-		info := infos[string(bidderName)]
-		exchangeBidder := AdaptBidder(bidder, client, cfg, me, bidderName, info.Debug, info.EndpointCompression)
-		exchangeBidder = addValidatedBidderMiddleware(exchangeBidder)
-		exchangeBidders[bidderName] = exchangeBidder
+		exchangeBidders[bidderName] = buildExchangeBidder(bidder, bidderName, infos[string(bidderName)], client, cfg, me)
 	}
 	return exchangeBidders, nil
 }
 
+func buildExchangeBidder(bidder adapters.Bidder, bidderName openrtb_ext.BidderName, info config.BidderInfo, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine) AdaptedBidder {
+	exchangeBidder := AdaptBidder(bidder, client, cfg, me, bidderName, info.Debug, info.EndpointCompression)
+	return addValidatedBidderMiddleware(exchangeBidder)
+}
+
 func buildBidders(infos config.BidderInfos, builders map[openrtb_ext.BidderName]adapters.Builder, server config.Server) (map[openrtb_ext.BidderName]adapters.Bidder, []error) {
 	bidders := make(map[openrtb_ext.BidderName]adapters.Bidder)
 	var errs []error
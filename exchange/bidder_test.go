@@ -3133,7 +3133,7 @@ func TestSeatNonBid(t *testing.T) {
 				seatNonBids: SeatNonBidBuilder{
 					"pubmatic": {{
 						ImpId:      "1234",
-						StatusCode: int(ErrorTimeout),
+						StatusCode: int(openrtb_ext.ErrorTimeout),
 					}},
 				},
 				errors:   []error{&errortypes.Timeout{Message: context.DeadlineExceeded.Error()}},
@@ -3152,8 +3152,8 @@ func TestSeatNonBid(t *testing.T) {
 			expect: expect{
 				seatNonBids: SeatNonBidBuilder{
 					"appnexus": {
-						{ImpId: "1234", StatusCode: int(ErrorBidderUnreachable)},
-						{ImpId: "4567", StatusCode: int(ErrorBidderUnreachable)},
+						{ImpId: "1234", StatusCode: int(openrtb_ext.ErrorBidderUnreachable)},
+						{ImpId: "4567", StatusCode: int(openrtb_ext.ErrorBidderUnreachable)},
 					},
 				},
 				seatBids: []*entities.PbsOrtbSeatBid{{Bids: []*entities.PbsOrtbBid{}, Currency: "USD", Seat: "appnexus", HttpCalls: []*openrtb_ext.ExtHttpCall{}}},
@@ -23,6 +23,7 @@ import (
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/currency"
 	"github.com/prebid/prebid-server/v3/dsa"
+	"github.com/prebid/prebid-server/v3/endpoints/events"
 	"github.com/prebid/prebid-server/v3/errortypes"
 	"github.com/prebid/prebid-server/v3/exchange/entities"
 	"github.com/prebid/prebid-server/v3/experiment/adscert"
@@ -39,6 +40,7 @@ import (
 	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 	"github.com/prebid/prebid-server/v3/util/maputil"
+	"github.com/prebid/prebid-server/v3/vast"
 
 	"github.com/buger/jsonparser"
 	"github.com/gofrs/uuid"
@@ -85,6 +87,9 @@ type exchange struct {
 	macroReplacer            macros.Replacer
 	priceFloorEnabled        bool
 	priceFloorFetcher        floors.FloorFetcher
+	vastProcessor            *vast.Processor
+	eventSigner              *events.URLSigner
+	runtimeBidders           *runtimeBidders
 }
 
 // Container to pass out response ext data from the GetAllBids goroutines back into the main thread
@@ -136,7 +141,7 @@ func (randomDeduplicateBidBooleanGenerator) Generate() bool {
 	return rand.Intn(100) < 50
 }
 
-func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, requestValidator ortb.RequestValidator, syncersByBidder map[string]usersync.Syncer, metricsEngine metrics.MetricsEngine, infos config.BidderInfos, gdprPermsBuilder gdpr.PermissionsBuilder, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher, adsCertSigner adscert.Signer, macroReplacer macros.Replacer, priceFloorFetcher floors.FloorFetcher) Exchange {
+func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, requestValidator ortb.RequestValidator, syncersByBidder map[string]usersync.Syncer, metricsEngine metrics.MetricsEngine, infos config.BidderInfos, gdprPermsBuilder gdpr.PermissionsBuilder, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher, adsCertSigner adscert.Signer, macroReplacer macros.Replacer, priceFloorFetcher floors.FloorFetcher, eventSigner *events.URLSigner) Exchange {
 	bidderToSyncerKey := map[string]string{}
 	for bidder, syncer := range syncersByBidder {
 		bidderToSyncerKey[bidder] = syncer.Key()
@@ -184,6 +189,9 @@ func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid
 		macroReplacer:            macroReplacer,
 		priceFloorEnabled:        cfg.PriceFloors.Enabled,
 		priceFloorFetcher:        priceFloorFetcher,
+		vastProcessor:            vast.NewProcessor(vast.NewWrapperClient(cfg.Validations.VASTWrapperTimeout()), cfg.Validations.VASTMaxWrapperDepth),
+		eventSigner:              eventSigner,
+		runtimeBidders:           &runtimeBidders{},
 	}
 }
 
@@ -267,14 +275,25 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 
 	cacheInstructions := getExtCacheInstructions(requestExtPrebid)
 
+	var targetingKeysErr error
 	targData := getExtTargetData(requestExtPrebid, cacheInstructions)
 	if targData != nil {
 		_, targData.cacheHost, targData.cachePath = e.cache.GetExtCacheData()
+		targData.keyTemplates, targetingKeysErr = accountTargetingKeyTemplates(r.RequestType, r.Account.Targeting, r.BidRequestWrapper.BidRequest)
 	}
 
 	// Get currency rates conversions for the auction
 	conversions := currency.GetAuctionCurrencyRates(e.currencyConverter, requestExtPrebid.CurrencyConversions)
 
+	var adServerCurrencyErr error
+	if targData != nil {
+		bidCurrency := "USD"
+		if len(r.BidRequestWrapper.Cur) > 0 {
+			bidCurrency = r.BidRequestWrapper.Cur[0]
+		}
+		adServerCurrencyErr = targData.setAdServerCurrency(requestExtPrebid.Targeting.AdServerCurrency, bidCurrency, r.Account.Targeting, conversions)
+	}
+
 	var floorErrs []error
 	if e.priceFloorEnabled {
 		floorErrs = floors.EnrichWithPriceFloors(r.BidRequestWrapper, r.Account, conversions, e.priceFloorFetcher)
@@ -351,7 +370,15 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 			return nil, err
 		}
 	}
+	bidderRequests, disabledErrs := e.removeRuntimeDisabledBidders(bidderRequests)
+	errs = append(errs, disabledErrs...)
 	errs = append(errs, floorErrs...)
+	if adServerCurrencyErr != nil {
+		errs = append(errs, adServerCurrencyErr)
+	}
+	if targetingKeysErr != nil {
+		errs = append(errs, targetingKeysErr)
+	}
 
 	mergedBidAdj, err := bidadjustment.Merge(r.BidRequestWrapper, r.Account.BidAdjustments)
 	if err != nil {
@@ -409,6 +436,7 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 		if extraRespInfo.seatNonBidBuilder != nil {
 			seatNonBidBuilder = extraRespInfo.seatNonBidBuilder
 		}
+		seatNonBidBuilder.appendSeatNonBid(r.HookExecutor.GetSeatNonBid())
 	}
 
 	var (
@@ -428,14 +456,17 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 				errs = append(errs, &errortypes.Warning{
 					Message:     fmt.Sprintf("%s bid id %s rejected - bid price %.4f %s is less than bid floor %.4f %s for imp %s", rejectedBid.Seat, rejectedBid.Bids[0].Bid.ID, rejectedBid.Bids[0].Bid.Price, rejectedBid.Currency, rejectedBid.Bids[0].BidFloors.FloorValue, rejectedBid.Bids[0].BidFloors.FloorCurrency, rejectedBid.Bids[0].Bid.ImpID),
 					WarningCode: errortypes.FloorBidRejectionWarningCode})
-				rejectionReason := ResponseRejectedBelowFloor
+				rejectionReason := openrtb_ext.ResponseRejectedBelowFloor
 				if rejectedBid.Bids[0].Bid.DealID != "" {
-					rejectionReason = ResponseRejectedBelowDealFloor
+					rejectionReason = openrtb_ext.ResponseRejectedBelowDealFloor
 				}
 				seatNonBidBuilder.rejectBid(rejectedBid.Bids[0], int(rejectionReason), rejectedBid.Seat)
 			}
 		}
 
+		vastValidation := e.bidValidationEnforcement.VASTMarkupValidation(r.Account.Validations)
+		errs = append(errs, e.validateVASTBids(ctx, r.BidRequestWrapper, adapterBids, vastValidation, &seatNonBidBuilder)...)
+
 		var bidCategory map[string]string
 		//If includebrandcategory is present in ext then CE feature is on.
 		if requestExtPrebid.Targeting != nil && requestExtPrebid.Targeting.IncludeBrandCategory != nil {
@@ -461,7 +492,7 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 			}
 		}
 
-		evTracking := getEventTracking(requestExtPrebid, r.StartTime, &r.Account, e.bidderInfo, e.externalURL)
+		evTracking := getEventTracking(requestExtPrebid, r.StartTime, &r.Account, e.bidderInfo, e.externalURL, e.eventSigner)
 		adapterBids = evTracking.modifyBidsForEvents(adapterBids)
 
 		r.HookExecutor.ExecuteAllProcessedBidResponsesStage(adapterBids)
@@ -543,6 +574,7 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 		return nil, err
 	}
 	bidResponseExt = setSeatNonBid(bidResponseExt, seatNonBidBuilder)
+	e.recordSeatNonBids(r, seatNonBidBuilder)
 
 	return &AuctionResponse{
 		BidResponse:    bidResponse,
@@ -755,7 +787,7 @@ func (e *exchange) getAllBids(
 				bidderRequestStartTime: start,
 				responseDebugAllowed:   responseDebugAllowed,
 			}
-			seatBids, extraBidderRespInfo, err := e.adapterMap[bidderRequest.BidderCoreName].requestBid(ctx, bidderRequest, conversions, &reqInfo, e.adsCertSigner, bidReqOptions, alternateBidderCodes, hookExecutor, bidAdjustmentRules)
+			seatBids, extraBidderRespInfo, err := e.adapter(bidderRequest.BidderCoreName).requestBid(ctx, bidderRequest, conversions, &reqInfo, e.adsCertSigner, bidReqOptions, alternateBidderCodes, hookExecutor, bidAdjustmentRules)
 			brw.bidderResponseStartTime = extraBidderRespInfo.respProcessingStartTime
 
 			// Add in time reporting
@@ -1032,7 +1064,7 @@ func applyCategoryMapping(ctx context.Context, targeting openrtb_ext.ExtRequestT
 					//on receiving bids from adapters if no unique IAB category is returned  or if no ad server category is returned discard the bid
 					bidsToRemove = append(bidsToRemove, bidInd)
 					rejections = updateRejections(rejections, bidID, "Bid did not contain a category")
-					seatNonBidBuilder.rejectBid(bid, int(ResponseRejectedCategoryMappingInvalid), string(bidderName))
+					seatNonBidBuilder.rejectBid(bid, int(openrtb_ext.ResponseRejectedCategoryMappingInvalid), string(bidderName))
 					continue
 				}
 				if translateCategories {
@@ -1296,12 +1328,12 @@ func (e *exchange) makeBid(bids []*entities.PbsOrtbBid, auc *auction, returnCrea
 			}
 			bidResponseExt.Warnings[adapter] = append(bidResponseExt.Warnings[adapter], dsaMessage)
 
-			seatNonBidBuilder.rejectBid(bid, int(ResponseRejectedGeneral), adapter.String())
+			seatNonBidBuilder.rejectBid(bid, int(openrtb_ext.ResponseRejectedGeneral), adapter.String())
 			continue // Don't add bid to result
 		}
 		if e.bidValidationEnforcement.BannerCreativeMaxSize == config.ValidationEnforce && bid.BidType == openrtb_ext.BidTypeBanner {
 			if !e.validateBannerCreativeSize(bid, bidResponseExt, adapter, pubID, e.bidValidationEnforcement.BannerCreativeMaxSize) {
-				seatNonBidBuilder.rejectBid(bid, int(ResponseRejectedCreativeSizeNotAllowed), adapter.String())
+				seatNonBidBuilder.rejectBid(bid, int(openrtb_ext.ResponseRejectedCreativeSizeNotAllowed), adapter.String())
 				continue // Don't add bid to result
 			}
 		} else if e.bidValidationEnforcement.BannerCreativeMaxSize == config.ValidationWarn && bid.BidType == openrtb_ext.BidTypeBanner {
@@ -1310,7 +1342,7 @@ func (e *exchange) makeBid(bids []*entities.PbsOrtbBid, auc *auction, returnCrea
 		if _, ok := impExtInfoMap[bid.Bid.ImpID]; ok {
 			if e.bidValidationEnforcement.SecureMarkup == config.ValidationEnforce && (bid.BidType == openrtb_ext.BidTypeBanner || bid.BidType == openrtb_ext.BidTypeVideo) {
 				if !e.validateBidAdM(bid, bidResponseExt, adapter, pubID, e.bidValidationEnforcement.SecureMarkup) {
-					seatNonBidBuilder.rejectBid(bid, int(ResponseRejectedCreativeNotSecure), adapter.String())
+					seatNonBidBuilder.rejectBid(bid, int(openrtb_ext.ResponseRejectedCreativeNotSecure), adapter.String())
 					continue // Don't add bid to result
 				}
 			} else if e.bidValidationEnforcement.SecureMarkup == config.ValidationWarn && (bid.BidType == openrtb_ext.BidTypeBanner || bid.BidType == openrtb_ext.BidTypeVideo) {
@@ -1609,6 +1641,21 @@ func setErrorMessageSecureMarkup(validationType string) string {
 	return ""
 }
 
+// recordSeatNonBids records the non bids of the response. The seats which aren't bidder names, such as the seats
+// of alternate bidder codes, aren't recorded.
+func (e *exchange) recordSeatNonBids(r *AuctionRequest, seatNonBidBuilder SeatNonBidBuilder) {
+	for seat, nonBids := range seatNonBidBuilder {
+		adapter, ok := openrtb_ext.NormalizeBidderName(seat)
+		if !ok {
+			continue
+		}
+		labels := metrics.AdapterLabels{RType: r.RequestType, Adapter: adapter, PubID: r.PubID}
+		for _, nonBid := range nonBids {
+			e.me.RecordAdapterNonBid(labels, nonBid.StatusCode)
+		}
+	}
+}
+
 // setSeatNonBid adds SeatNonBids within bidResponse.Ext.Prebid.SeatNonBid
 func setSeatNonBid(bidResponseExt *openrtb_ext.ExtBidResponse, seatNonBidBuilder SeatNonBidBuilder) *openrtb_ext.ExtBidResponse {
 	if len(seatNonBidBuilder) == 0 {
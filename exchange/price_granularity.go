@@ -1,11 +1,16 @@
 package exchange
 
 import (
+	"fmt"
 	"math"
 	"strconv"
 
 	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/currency"
+	"github.com/prebid/prebid-server/v3/errortypes"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
 )
 
 // GetPriceBucket is the externally facing function for computing CPM buckets
@@ -30,6 +35,9 @@ func GetPriceBucket(bid openrtb2.Bid, targetingData targetData) string {
 	precision := *config.Precision
 
 	cpm := bid.Price
+	if targetingData.adServerCurrencyRate > 0 {
+		cpm = cpm * targetingData.adServerCurrencyRate
+	}
 	for i := 0; i < len(config.Ranges); i++ {
 		if config.Ranges[i].Max > bucketMax {
 			bucketMax = config.Ranges[i].Max
@@ -56,3 +64,29 @@ func getCpmTarget(cpm float64, bucketMin float64, increment float64, precision i
 	roundedCPM := math.Floor((cpm-bucketMin)/increment)*increment + bucketMin
 	return strconv.FormatFloat(roundedCPM, 'f', precision, 64)
 }
+
+// setAdServerCurrency prepares price bucketing for the ad server currency requested in ext.prebid.targeting.
+// Bid prices are converted from the bid currency to the ad server currency and, if the account defines a price
+// granularity ladder for that currency, the ladder replaces the price granularity of the request.
+func (targData *targetData) setAdServerCurrency(adServerCurrency, bidCurrency string, accountTargeting config.AccountTargeting, conversions currency.Conversions) error {
+	if targData == nil || adServerCurrency == "" {
+		return nil
+	}
+
+	rate, err := conversions.GetRate(bidCurrency, adServerCurrency)
+	if err != nil {
+		return &errortypes.Warning{
+			WarningCode: errortypes.AdServerCurrencyWarningCode,
+			Message:     fmt.Sprintf("Unable to convert bid prices from %s to ad server currency %s, price buckets are computed in %s: %v", bidCurrency, adServerCurrency, bidCurrency, err),
+		}
+	}
+	targData.adServerCurrencyRate = rate
+
+	if ladder, found := accountTargeting.PriceGranularityLadder(adServerCurrency); found {
+		if ladder.PriceGranularity != nil {
+			targData.priceGranularity = *ladder.PriceGranularity
+		}
+		targData.mediaTypePriceGranularity = ptrutil.ValueOrDefault(ladder.MediaTypePriceGranularity)
+	}
+	return nil
+}
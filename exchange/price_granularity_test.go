@@ -6,6 +6,9 @@ import (
 	"testing"
 
 	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/currency"
+	"github.com/prebid/prebid-server/v3/errortypes"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/util/ptrutil"
 	"github.com/stretchr/testify/assert"
@@ -199,3 +202,102 @@ func TestGetPriceBucketString(t *testing.T) {
 		}
 	}
 }
+
+func TestGetPriceBucketAdServerCurrency(t *testing.T) {
+	jpyLadder := openrtb_ext.PriceGranularity{
+		Precision: ptrutil.ToPtr(0),
+		Ranges:    []openrtb_ext.GranularityRange{{Min: 0, Max: 5000, Increment: 50}},
+	}
+
+	testCases := []struct {
+		name                string
+		bid                 openrtb2.Bid
+		targetData          targetData
+		expectedPriceBucket string
+	}{
+		{
+			name:                "no-conversion",
+			bid:                 openrtb2.Bid{Price: 1.87},
+			targetData:          targetData{priceGranularity: jpyLadder},
+			expectedPriceBucket: "0",
+		},
+		{
+			name:                "converted-to-ad-server-currency",
+			bid:                 openrtb2.Bid{Price: 1.87},
+			targetData:          targetData{priceGranularity: jpyLadder, adServerCurrencyRate: 150},
+			expectedPriceBucket: "250",
+		},
+		{
+			name:                "converted-above-max",
+			bid:                 openrtb2.Bid{Price: 40},
+			targetData:          targetData{priceGranularity: jpyLadder, adServerCurrencyRate: 150},
+			expectedPriceBucket: "5000",
+		},
+		{
+			name:                "converted-media-type-ladder",
+			bid:                 openrtb2.Bid{Price: 2, MType: openrtb2.MarkupVideo},
+			targetData:          targetData{priceGranularity: openrtb_ext.NewPriceGranularityDefault(), mediaTypePriceGranularity: openrtb_ext.MediaTypePriceGranularity{Video: &jpyLadder}, adServerCurrencyRate: 149},
+			expectedPriceBucket: "250",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedPriceBucket, GetPriceBucket(tc.bid, tc.targetData))
+		})
+	}
+}
+
+func TestSetAdServerCurrency(t *testing.T) {
+	jpyLadder := &openrtb_ext.PriceGranularity{
+		Precision: ptrutil.ToPtr(0),
+		Ranges:    []openrtb_ext.GranularityRange{{Min: 0, Max: 5000, Increment: 50}},
+	}
+	accountTargeting := config.AccountTargeting{
+		PriceGranularityLadders: []config.AccountPriceGranularityLadder{
+			{Name: "jpy", Currency: "JPY", MediaTypePriceGranularity: &openrtb_ext.MediaTypePriceGranularity{Banner: jpyLadder}},
+		},
+	}
+	conversions := currency.NewRates(map[string]map[string]float64{"USD": {"JPY": 150, "KRW": 1300}})
+	medium := openrtb_ext.NewPriceGranularityDefault()
+
+	testCases := []struct {
+		name               string
+		adServerCurrency   string
+		expectedTargetData targetData
+		expectedErr        error
+	}{
+		{
+			name:               "no-ad-server-currency",
+			expectedTargetData: targetData{priceGranularity: medium},
+		},
+		{
+			name:               "account-ladder",
+			adServerCurrency:   "JPY",
+			expectedTargetData: targetData{priceGranularity: medium, mediaTypePriceGranularity: openrtb_ext.MediaTypePriceGranularity{Banner: jpyLadder}, adServerCurrencyRate: 150},
+		},
+		{
+			name:               "no-account-ladder",
+			adServerCurrency:   "KRW",
+			expectedTargetData: targetData{priceGranularity: medium, adServerCurrencyRate: 1300},
+		},
+		{
+			name:               "no-conversion-rate",
+			adServerCurrency:   "EUR",
+			expectedTargetData: targetData{priceGranularity: medium},
+			expectedErr: &errortypes.Warning{
+				WarningCode: errortypes.AdServerCurrencyWarningCode,
+				Message:     "Unable to convert bid prices from USD to ad server currency EUR, price buckets are computed in USD: Currency conversion rate not found: 'USD' => 'EUR'",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			targData := &targetData{priceGranularity: medium}
+			err := targData.setAdServerCurrency(tc.adServerCurrency, "USD", accountTargeting, conversions)
+			assert.Equal(t, tc.expectedErr, err)
+			assert.Equal(t, tc.expectedTargetData, *targData)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/hostconfig"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// runtimeBidders holds the bidder changes made at runtime through the host config. A nil runtimeBidders has none.
+type runtimeBidders struct {
+	current atomic.Pointer[bidderOverrides]
+}
+
+type bidderOverrides struct {
+	disabled map[openrtb_ext.BidderName]struct{}
+	// adapters are built with the bidder infos overridden at runtime, and replace the ones built at startup.
+	adapters map[openrtb_ext.BidderName]AdaptedBidder
+}
+
+func (rb *runtimeBidders) load() *bidderOverrides {
+	if rb == nil {
+		return nil
+	}
+	return rb.current.Load()
+}
+
+// SubscribeToHostConfig applies the bidder changes of the host config store to the exchange: the disabled bidders
+// are left out of the auctions, and the bidders with an endpoint override get a new adapter. A change whose adapter
+// can't be built is rejected.
+func SubscribeToHostConfig(ex Exchange, store *hostconfig.Store, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine) error {
+	e, ok := ex.(*exchange)
+	if !ok {
+		return errors.New("the exchange doesn't support host config changes")
+	}
+	if e.runtimeBidders == nil {
+		return errors.New("the exchange wasn't built with NewExchange")
+	}
+	server := config.Server{ExternalUrl: cfg.ExternalURL, GvlID: cfg.GDPR.HostVendorID, DataCenter: cfg.DataCenter}
+
+	store.Subscribe(func(snapshot *hostconfig.Snapshot) (func(), error) {
+		overrides := &bidderOverrides{
+			disabled: make(map[openrtb_ext.BidderName]struct{}),
+			adapters: make(map[openrtb_ext.BidderName]AdaptedBidder),
+		}
+		for bidder, override := range snapshot.Overrides {
+			bidderName := openrtb_ext.BidderName(bidder)
+			if snapshot.IsBidderDisabled(bidderName) {
+				overrides.disabled[bidderName] = struct{}{}
+				continue
+			}
+			if override.Endpoint == nil {
+				continue
+			}
+			info := snapshot.BidderInfos[bidder]
+			bidders, errs := buildBidders(config.BidderInfos{bidder: info}, newAdapterBuilders(), server)
+			if len(errs) > 0 {
+				return nil, errs[0]
+			}
+			overrides.adapters[bidderName] = buildExchangeBidder(bidders[bidderName], bidderName, info, client, cfg, me)
+		}
+		return func() { e.runtimeBidders.current.Store(overrides) }, nil
+	})
+	return nil
+}
+
+// adapter returns the adapter of the bidder, taking the runtime overrides into account.
+func (e *exchange) adapter(bidder openrtb_ext.BidderName) AdaptedBidder {
+	if overrides := e.runtimeBidders.load(); overrides != nil {
+		if adapter, ok := overrides.adapters[bidder]; ok {
+			return adapter
+		}
+	}
+	return e.adapterMap[bidder]
+}
+
+// removeRuntimeDisabledBidders drops the requests to the bidders disabled at runtime, with the same warning as
+// the bidders disabled in the config files.
+func (e *exchange) removeRuntimeDisabledBidders(bidderRequests []BidderRequest) ([]BidderRequest, []error) {
+	overrides := e.runtimeBidders.load()
+	if overrides == nil || len(overrides.disabled) == 0 {
+		return bidderRequests, nil
+	}
+
+	var errs []error
+	enabled := make([]BidderRequest, 0, len(bidderRequests))
+	for _, bidderRequest := range bidderRequests {
+		if _, disabled := overrides.disabled[bidderRequest.BidderCoreName]; disabled {
+			errs = append(errs, &errortypes.BidderTemporarilyDisabled{
+				Message: fmt.Sprintf(`Bidder "%s" has been disabled on this instance of Prebid Server. Please work with the PBS host to enable this bidder again.`, bidderRequest.BidderName),
+			})
+			continue
+		}
+		enabled = append(enabled, bidderRequest)
+	}
+	return enabled, errs
+}
@@ -0,0 +1,87 @@
+package exchange
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/hostconfig"
+	metricsConfig "github.com/prebid/prebid-server/v3/metrics/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeToHostConfig(t *testing.T) {
+	infos := config.BidderInfos{
+		"appnexus": {Endpoint: "https://appnexus.com"},
+		"aceex":    {Endpoint: "https://aceex.com/{{.Host}}"},
+	}
+	appnexusAdapter := &validatedBidder{}
+	aceexAdapter := &validatedBidder{}
+	e := &exchange{
+		adapterMap:     map[openrtb_ext.BidderName]AdaptedBidder{openrtb_ext.BidderAppnexus: appnexusAdapter, openrtb_ext.BidderAceex: aceexAdapter},
+		runtimeBidders: &runtimeBidders{},
+	}
+	auditLog, _ := hostconfig.NewAuditLog("")
+	store := hostconfig.NewStore(infos, auditLog, &timeutil.RealTime{})
+	require.NoError(t, SubscribeToHostConfig(e, store, &http.Client{}, &config.Configuration{}, &metricsConfig.NilMetricsEngine{}))
+
+	// endpoint override
+	_, err := store.UpdateBidder("admin", "aceex", hostconfig.BidderOverride{Endpoint: ptrutil.ToPtr("https://new.aceex.com/{{.Host}}")})
+	require.NoError(t, err)
+	assert.NotSame(t, aceexAdapter, e.adapter(openrtb_ext.BidderAceex), "the adapter is rebuilt")
+	assert.NotNil(t, e.adapter(openrtb_ext.BidderAceex))
+	assert.Same(t, appnexusAdapter, e.adapter(openrtb_ext.BidderAppnexus))
+	rebuilt := e.adapter(openrtb_ext.BidderAceex)
+
+	// invalid endpoint override
+	_, err = store.UpdateBidder("admin", "aceex", hostconfig.BidderOverride{Endpoint: ptrutil.ToPtr("https://new.aceex.com/{{.Host")})
+	assert.ErrorContains(t, err, "unable to parse endpoint url template")
+	assert.Same(t, rebuilt, e.adapter(openrtb_ext.BidderAceex), "the rejected change isn't applied")
+
+	// disabled bidder
+	_, err = store.UpdateBidder("admin", "appnexus", hostconfig.BidderOverride{Disabled: ptrutil.ToPtr(true)})
+	require.NoError(t, err)
+	requests, errs := e.removeRuntimeDisabledBidders([]BidderRequest{
+		{BidderName: "appnexus", BidderCoreName: openrtb_ext.BidderAppnexus},
+		{BidderName: "anAlias", BidderCoreName: openrtb_ext.BidderAppnexus},
+		{BidderName: "aceex", BidderCoreName: openrtb_ext.BidderAceex},
+	})
+	assert.Equal(t, []BidderRequest{{BidderName: "aceex", BidderCoreName: openrtb_ext.BidderAceex}}, requests)
+	assert.Equal(t, []error{
+		&errortypes.BidderTemporarilyDisabled{Message: `Bidder "appnexus" has been disabled on this instance of Prebid Server. Please work with the PBS host to enable this bidder again.`},
+		&errortypes.BidderTemporarilyDisabled{Message: `Bidder "anAlias" has been disabled on this instance of Prebid Server. Please work with the PBS host to enable this bidder again.`},
+	}, errs)
+
+	// back to the config files
+	_, err = store.ResetBidder("admin", "appnexus")
+	require.NoError(t, err)
+	_, err = store.ResetBidder("admin", "aceex")
+	require.NoError(t, err)
+	assert.Same(t, aceexAdapter, e.adapter(openrtb_ext.BidderAceex))
+	requests, errs = e.removeRuntimeDisabledBidders([]BidderRequest{{BidderName: "appnexus", BidderCoreName: openrtb_ext.BidderAppnexus}})
+	assert.Len(t, requests, 1)
+	assert.Empty(t, errs)
+}
+
+func TestSubscribeToHostConfigUnsupportedExchange(t *testing.T) {
+	store := hostconfig.NewStore(config.BidderInfos{}, nil, &timeutil.RealTime{})
+
+	assert.Error(t, SubscribeToHostConfig(&exchange{}, store, &http.Client{}, &config.Configuration{}, &metricsConfig.NilMetricsEngine{}))
+}
+
+func TestRuntimeBiddersNil(t *testing.T) {
+	appnexusAdapter := &validatedBidder{}
+	e := &exchange{adapterMap: map[openrtb_ext.BidderName]AdaptedBidder{openrtb_ext.BidderAppnexus: appnexusAdapter}}
+	bidderRequests := []BidderRequest{{BidderName: "appnexus", BidderCoreName: openrtb_ext.BidderAppnexus}}
+
+	requests, errs := e.removeRuntimeDisabledBidders(bidderRequests)
+
+	assert.Equal(t, bidderRequests, requests)
+	assert.Empty(t, errs)
+	assert.Same(t, appnexusAdapter, e.adapter(openrtb_ext.BidderAppnexus))
+}
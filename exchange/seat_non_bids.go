@@ -36,7 +36,7 @@ func (b SeatNonBidBuilder) rejectBid(bid *entities.PbsOrtbBid, nonBidReason int,
 }
 
 // rejectImps appends a non bid object to the builder for every specified imp
-func (b SeatNonBidBuilder) rejectImps(impIds []string, nonBidReason NonBidReason, seat string) {
+func (b SeatNonBidBuilder) rejectImps(impIds []string, nonBidReason openrtb_ext.NonBidReason, seat string) {
 	nonBids := []openrtb_ext.NonBid{}
 	for _, impId := range impIds {
 		nonBid := openrtb_ext.NonBid{
@@ -51,6 +51,16 @@ func (b SeatNonBidBuilder) rejectImps(impIds []string, nonBidReason NonBidReason
 	}
 }
 
+// appendSeatNonBid adds the non bids reported outside of the exchange, such as by hook modules, to the builder.
+func (b SeatNonBidBuilder) appendSeatNonBid(seatNonBids []openrtb_ext.SeatNonBid) {
+	if b == nil {
+		return
+	}
+	for _, seatNonBid := range seatNonBids {
+		b[seatNonBid.Seat] = append(b[seatNonBid.Seat], seatNonBid.NonBid...)
+	}
+}
+
 // slice transforms the seat non bid map into a slice of SeatNonBid objects representing the non-bids for each seat
 func (b SeatNonBidBuilder) Slice() []openrtb_ext.SeatNonBid {
 	seatNonBid := make([]openrtb_ext.SeatNonBid, 0)
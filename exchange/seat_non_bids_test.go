@@ -66,14 +66,14 @@ func TestRejectBid(t *testing.T) {
 						Price: 10,
 					},
 				},
-				nonBidReason: int(ErrorGeneral),
+				nonBidReason: int(openrtb_ext.ErrorGeneral),
 				seat:         "seat1",
 			},
 			want: SeatNonBidBuilder{
 				"seat1": []openrtb_ext.NonBid{
 					{
 						ImpId:      "Imp1",
-						StatusCode: int(ErrorGeneral),
+						StatusCode: int(openrtb_ext.ErrorGeneral),
 						Ext: &openrtb_ext.NonBidExt{
 							Prebid: openrtb_ext.ExtResponseNonBidPrebid{
 								Bid: openrtb_ext.NonBidObject{
@@ -92,7 +92,7 @@ func TestRejectBid(t *testing.T) {
 					"seat1": []openrtb_ext.NonBid{
 						{
 							ImpId:      "Imp1",
-							StatusCode: int(ErrorGeneral),
+							StatusCode: int(openrtb_ext.ErrorGeneral),
 						},
 					},
 				},
@@ -104,20 +104,20 @@ func TestRejectBid(t *testing.T) {
 						Price: 10,
 					},
 				},
-				nonBidReason: int(ErrorGeneral),
+				nonBidReason: int(openrtb_ext.ErrorGeneral),
 				seat:         "seat2",
 			},
 			want: SeatNonBidBuilder{
 				"seat1": []openrtb_ext.NonBid{
 					{
 						ImpId:      "Imp1",
-						StatusCode: int(ErrorGeneral),
+						StatusCode: int(openrtb_ext.ErrorGeneral),
 					},
 				},
 				"seat2": []openrtb_ext.NonBid{
 					{
 						ImpId:      "Imp2",
-						StatusCode: int(ErrorGeneral),
+						StatusCode: int(openrtb_ext.ErrorGeneral),
 						Ext: &openrtb_ext.NonBidExt{
 							Prebid: openrtb_ext.ExtResponseNonBidPrebid{
 								Bid: openrtb_ext.NonBidObject{
@@ -136,7 +136,7 @@ func TestRejectBid(t *testing.T) {
 					"seat1": []openrtb_ext.NonBid{
 						{
 							ImpId:      "Imp1",
-							StatusCode: int(ErrorGeneral),
+							StatusCode: int(openrtb_ext.ErrorGeneral),
 						},
 					},
 				},
@@ -148,18 +148,18 @@ func TestRejectBid(t *testing.T) {
 						Price: 10,
 					},
 				},
-				nonBidReason: int(ErrorGeneral),
+				nonBidReason: int(openrtb_ext.ErrorGeneral),
 				seat:         "seat1",
 			},
 			want: SeatNonBidBuilder{
 				"seat1": []openrtb_ext.NonBid{
 					{
 						ImpId:      "Imp1",
-						StatusCode: int(ErrorGeneral),
+						StatusCode: int(openrtb_ext.ErrorGeneral),
 					},
 					{
 						ImpId:      "Imp2",
-						StatusCode: int(ErrorGeneral),
+						StatusCode: int(openrtb_ext.ErrorGeneral),
 						Ext: &openrtb_ext.NonBidExt{
 							Prebid: openrtb_ext.ExtResponseNonBidPrebid{
 								Bid: openrtb_ext.NonBidObject{
@@ -254,6 +254,46 @@ func TestAppend(t *testing.T) {
 	}
 }
 
+func TestAppendSeatNonBid(t *testing.T) {
+	tests := []struct {
+		name     string
+		builder  SeatNonBidBuilder
+		toAppend []openrtb_ext.SeatNonBid
+		expected SeatNonBidBuilder
+	}{
+		{
+			name:     "nil_builder",
+			builder:  nil,
+			toAppend: []openrtb_ext.SeatNonBid{{Seat: "seat1", NonBid: []openrtb_ext.NonBid{{ImpId: "imp1"}}}},
+			expected: nil,
+		},
+		{
+			name:     "nil_append",
+			builder:  SeatNonBidBuilder{"seat1": []openrtb_ext.NonBid{{ImpId: "imp1"}}},
+			toAppend: nil,
+			expected: SeatNonBidBuilder{"seat1": []openrtb_ext.NonBid{{ImpId: "imp1"}}},
+		},
+		{
+			name:    "append_same_and_different_seats",
+			builder: SeatNonBidBuilder{"seat1": []openrtb_ext.NonBid{{ImpId: "imp1"}}},
+			toAppend: []openrtb_ext.SeatNonBid{
+				{Seat: "seat1", NonBid: []openrtb_ext.NonBid{{ImpId: "imp2"}}},
+				{Seat: "seat2", NonBid: []openrtb_ext.NonBid{{ImpId: "imp3"}}},
+			},
+			expected: SeatNonBidBuilder{
+				"seat1": []openrtb_ext.NonBid{{ImpId: "imp1"}, {ImpId: "imp2"}},
+				"seat2": []openrtb_ext.NonBid{{ImpId: "imp3"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.builder.appendSeatNonBid(tt.toAppend)
+			assert.Equal(t, tt.expected, tt.builder)
+		})
+	}
+}
+
 func TestRejectImps(t *testing.T) {
 	tests := []struct {
 		name    string
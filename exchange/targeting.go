@@ -30,6 +30,11 @@ type targetData struct {
 	// cacheHost and cachePath exist to supply cache host and path as targeting parameters
 	cacheHost string
 	cachePath string
+	// adServerCurrencyRate converts bid prices into the ad server currency before bucketing.
+	// A zero value means bid prices are bucketed in the bid currency.
+	adServerCurrencyRate float64
+	// keyTemplates holds the account targeting key customizations, nil when the legacy keys are used
+	keyTemplates *targetingKeyTemplates
 }
 
 // setTargeting writes all the targeting params into the bids.
@@ -64,41 +69,43 @@ func (targData *targetData) setTargeting(auc *auction, isApp bool, categoryMappi
 
 				bidHasDeal := len(topBid.Bid.DealID) > 0
 
-				targets := make(map[string]string, 10)
+				values := make([]targetingValue, 0, 10)
 				if cpm, ok := auc.roundedPrices[topBid]; ok {
-					targData.addKeys(targets, openrtb_ext.HbpbConstantKey, cpm, targetingBidderCode, isOverallWinner, truncateTargetAttr, bidHasDeal)
+					values = append(values, targetingValue{openrtb_ext.HbpbConstantKey, cpm})
 				}
-				targData.addKeys(targets, openrtb_ext.HbBidderConstantKey, string(targetingBidderCode), targetingBidderCode, isOverallWinner, truncateTargetAttr, bidHasDeal)
+				values = append(values, targetingValue{openrtb_ext.HbBidderConstantKey, string(targetingBidderCode)})
 				if hbSize := makeHbSize(topBid.Bid); hbSize != "" {
-					targData.addKeys(targets, openrtb_ext.HbSizeConstantKey, hbSize, targetingBidderCode, isOverallWinner, truncateTargetAttr, bidHasDeal)
+					values = append(values, targetingValue{openrtb_ext.HbSizeConstantKey, hbSize})
 				}
 				if cacheID, ok := auc.cacheIds[topBid.Bid]; ok {
-					targData.addKeys(targets, openrtb_ext.HbCacheKey, cacheID, targetingBidderCode, isOverallWinner, truncateTargetAttr, bidHasDeal)
+					values = append(values, targetingValue{openrtb_ext.HbCacheKey, cacheID})
 				}
 				if vastID, ok := auc.vastCacheIds[topBid.Bid]; ok {
-					targData.addKeys(targets, openrtb_ext.HbVastCacheKey, vastID, targetingBidderCode, isOverallWinner, truncateTargetAttr, bidHasDeal)
+					values = append(values, targetingValue{openrtb_ext.HbVastCacheKey, vastID})
 				}
 				if targData.includeFormat {
-					targData.addKeys(targets, openrtb_ext.HbFormatKey, string(topBid.BidType), targetingBidderCode, isOverallWinner, truncateTargetAttr, bidHasDeal)
+					values = append(values, targetingValue{openrtb_ext.HbFormatKey, string(topBid.BidType)})
 				}
 
 				if targData.cacheHost != "" {
-					targData.addKeys(targets, openrtb_ext.HbConstantCacheHostKey, targData.cacheHost, targetingBidderCode, isOverallWinner, truncateTargetAttr, bidHasDeal)
+					values = append(values, targetingValue{openrtb_ext.HbConstantCacheHostKey, targData.cacheHost})
 				}
 				if targData.cachePath != "" {
-					targData.addKeys(targets, openrtb_ext.HbConstantCachePathKey, targData.cachePath, targetingBidderCode, isOverallWinner, truncateTargetAttr, bidHasDeal)
+					values = append(values, targetingValue{openrtb_ext.HbConstantCachePathKey, targData.cachePath})
 				}
 
 				if bidHasDeal {
-					targData.addKeys(targets, openrtb_ext.HbDealIDConstantKey, topBid.Bid.DealID, targetingBidderCode, isOverallWinner, truncateTargetAttr, bidHasDeal)
+					values = append(values, targetingValue{openrtb_ext.HbDealIDConstantKey, topBid.Bid.DealID})
 				}
 
 				if isApp {
-					targData.addKeys(targets, openrtb_ext.HbEnvKey, openrtb_ext.HbEnvKeyApp, targetingBidderCode, isOverallWinner, truncateTargetAttr, bidHasDeal)
+					values = append(values, targetingValue{openrtb_ext.HbEnvKey, openrtb_ext.HbEnvKeyApp})
 				}
 				if len(categoryMapping) > 0 {
-					targData.addKeys(targets, openrtb_ext.HbCategoryDurationKey, categoryMapping[topBid.Bid.ID], targetingBidderCode, isOverallWinner, truncateTargetAttr, bidHasDeal)
+					values = append(values, targetingValue{openrtb_ext.HbCategoryDurationKey, categoryMapping[topBid.Bid.ID]})
 				}
+				targets := make(map[string]string, 10)
+				targData.addTargets(targets, values, topBid, targetingBidderCode, isOverallWinner, truncateTargetAttr, bidHasDeal)
 				topBid.BidTargets = targets
 			}
 		}
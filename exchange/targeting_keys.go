@@ -0,0 +1,186 @@
+package exchange
+
+import (
+	"strings"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/adservertargeting"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/exchange/entities"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// targetingValue is a Prebid targeting key and its value for a bid, before account customizations
+type targetingValue struct {
+	key   openrtb_ext.TargetingKey
+	value string
+}
+
+type targetingKeyTemplate struct {
+	name     openrtb_ext.TargetingKey
+	suppress bool
+	value    adservertargeting.TargetingTemplate
+}
+
+// targetingKeyTemplates applies the account targeting namespace and key customizations to the Prebid
+// targeting keys of a request. Request and imp JSON are only built when a template refers to them.
+type targetingKeyTemplates struct {
+	namespace  string
+	customized map[openrtb_ext.TargetingKey]targetingKeyTemplate
+	added      []targetingKeyTemplate
+
+	bidRequest  *openrtb2.BidRequest
+	requestJSON []byte
+	impJSON     map[string][]byte
+}
+
+// accountTargetingKeyTemplates returns the account targeting key templates of the request. The video endpoint builds
+// its response from the legacy targeting keys, so they aren't applied to video requests and a warning is returned.
+func accountTargetingKeyTemplates(requestType metrics.RequestType, accountTargeting config.AccountTargeting, bidRequest *openrtb2.BidRequest) (*targetingKeyTemplates, error) {
+	if requestType != metrics.ReqTypeVideo {
+		return newTargetingKeyTemplates(accountTargeting, bidRequest)
+	}
+	if accountTargeting.Namespace != "" || len(accountTargeting.Keys) > 0 {
+		return nil, &errortypes.Warning{
+			WarningCode: errortypes.AccountTargetingWarningCode,
+			Message:     "the account targeting namespace and keys are not applied to video requests, which use the legacy targeting keys",
+		}
+	}
+	return nil, nil
+}
+
+// newTargetingKeyTemplates returns nil when the account keeps the legacy targeting keys
+func newTargetingKeyTemplates(accountTargeting config.AccountTargeting, bidRequest *openrtb2.BidRequest) (*targetingKeyTemplates, error) {
+	if accountTargeting.Namespace == "" && len(accountTargeting.Keys) == 0 {
+		return nil, nil
+	}
+
+	templates := &targetingKeyTemplates{
+		namespace:  accountTargeting.Namespace,
+		customized: make(map[openrtb_ext.TargetingKey]targetingKeyTemplate, len(accountTargeting.Keys)),
+		bidRequest: bidRequest,
+		impJSON:    make(map[string][]byte),
+	}
+	for _, key := range accountTargeting.Keys {
+		value, err := adservertargeting.ParseTargetingTemplate(key.Value)
+		if err != nil {
+			return nil, err
+		}
+		template := targetingKeyTemplate{
+			name:     openrtb_ext.TargetingKey(key.Name),
+			suppress: key.Suppress,
+			value:    value,
+		}
+		if key.Key == "" {
+			templates.added = append(templates.added, template)
+		} else {
+			templates.customized[openrtb_ext.TargetingKey(key.Key)] = template
+		}
+	}
+	return templates, nil
+}
+
+// addTargets adds the targeting keys of a bid, applying the account customizations if any
+func (targData *targetData) addTargets(targets map[string]string, values []targetingValue, bid *entities.PbsOrtbBid, bidderName openrtb_ext.BidderName, overallWinner bool, truncateTargetAttr *int, bidHasDeal bool) {
+	templates := targData.keyTemplates
+	if templates == nil {
+		for _, v := range values {
+			targData.addKeys(targets, v.key, v.value, bidderName, overallWinner, truncateTargetAttr, bidHasDeal)
+		}
+		return
+	}
+
+	legacyValues := make(map[string]string, len(values))
+	for _, v := range values {
+		legacyValues[string(v.key)] = v.value
+	}
+	resolve := templates.resolver(bid, bidderName, legacyValues)
+
+	for _, v := range values {
+		key, value := templates.namespaced(v.key), v.value
+		if template, ok := templates.customized[v.key]; ok {
+			if template.suppress {
+				continue
+			}
+			if template.name != "" {
+				key = template.name
+			}
+			if !template.value.IsEmpty() {
+				var resolved bool
+				if value, resolved = template.value.Execute(resolve); !resolved {
+					continue
+				}
+			}
+		}
+		targData.addKeys(targets, key, value, bidderName, overallWinner, truncateTargetAttr, bidHasDeal)
+	}
+
+	for _, template := range templates.added {
+		if value, ok := template.value.Execute(resolve); ok {
+			targData.addKeys(targets, template.name, value, bidderName, overallWinner, truncateTargetAttr, bidHasDeal)
+		}
+	}
+}
+
+// namespaced moves a Prebid targeting key into the account namespace
+func (templates *targetingKeyTemplates) namespaced(key openrtb_ext.TargetingKey) openrtb_ext.TargetingKey {
+	if templates.namespace == "" {
+		return key
+	}
+	return openrtb_ext.TargetingKey(templates.namespace + strings.TrimPrefix(string(key), openrtb_ext.TargetingKeyNamespace))
+}
+
+func (templates *targetingKeyTemplates) resolver(bid *entities.PbsOrtbBid, bidderName openrtb_ext.BidderName, legacyValues map[string]string) adservertargeting.TemplateResolver {
+	var bidJSON []byte
+	return func(source string, path []string) (string, bool) {
+		switch source {
+		case adservertargeting.TemplateSourceBidder:
+			return bidderName.String(), true
+		case adservertargeting.TemplateSourceMediaType:
+			return string(bid.BidType), true
+		case adservertargeting.TemplateSourceTargeting:
+			value, ok := legacyValues[strings.Join(path, ".")]
+			return value, ok
+		case adservertargeting.TemplateSourceBid:
+			if bidJSON == nil {
+				var err error
+				if bidJSON, err = jsonutil.Marshal(bid.Bid); err != nil {
+					return "", false
+				}
+			}
+			return adservertargeting.LookupTemplateValue(bidJSON, path)
+		case adservertargeting.TemplateSourceImp:
+			return adservertargeting.LookupTemplateValue(templates.getImpJSON(bid.Bid.ImpID), path)
+		case adservertargeting.TemplateSourceRequest:
+			return adservertargeting.LookupTemplateValue(templates.getRequestJSON(), path)
+		}
+		return "", false
+	}
+}
+
+func (templates *targetingKeyTemplates) getRequestJSON() []byte {
+	if templates.requestJSON == nil && templates.bidRequest != nil {
+		templates.requestJSON, _ = jsonutil.Marshal(templates.bidRequest)
+	}
+	return templates.requestJSON
+}
+
+func (templates *targetingKeyTemplates) getImpJSON(impID string) []byte {
+	if impJSON, ok := templates.impJSON[impID]; ok {
+		return impJSON
+	}
+	if templates.bidRequest == nil {
+		return nil
+	}
+	for i := range templates.bidRequest.Imp {
+		if templates.bidRequest.Imp[i].ID == impID {
+			impJSON, _ := jsonutil.Marshal(templates.bidRequest.Imp[i])
+			templates.impJSON[impID] = impJSON
+			return impJSON
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/exchange/entities"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTargetingWithKeyTemplates(t *testing.T) {
+	bidRequest := &openrtb2.BidRequest{
+		ID: "req1",
+		Imp: []openrtb2.Imp{
+			{ID: "ImpId-1", TagID: "slot-top", Ext: json.RawMessage(`{"gpid":"/1234/home#top"}`)},
+		},
+		Site: &openrtb2.Site{Domain: "publisher.com"},
+	}
+
+	testCases := []struct {
+		name              string
+		accountTargeting  config.AccountTargeting
+		includeBidderKeys bool
+		expectedTargets   map[string]string
+	}{
+		{
+			name: "legacy-keys",
+			expectedTargets: map[string]string{
+				"hb_bidder": "appnexus",
+				"hb_pb":     "1.20",
+				"hb_size":   "300x250",
+				"hb_deal":   "mydeal",
+			},
+		},
+		{
+			name:             "namespace",
+			accountTargeting: config.AccountTargeting{Namespace: "pbs"},
+			expectedTargets: map[string]string{
+				"pbs_bidder": "appnexus",
+				"pbs_pb":     "1.20",
+				"pbs_size":   "300x250",
+				"pbs_deal":   "mydeal",
+			},
+		},
+		{
+			name: "rename-suppress-override-and-add",
+			accountTargeting: config.AccountTargeting{
+				Keys: []config.AccountTargetingKey{
+					{Key: "hb_pb", Name: "price"},
+					{Key: "hb_size", Suppress: true},
+					{Key: "hb_deal", Value: "deal_{{bid.dealid}}"},
+					{Name: "gpid", Value: "{{imp.ext.gpid}}"},
+					{Name: "slot", Value: "{{imp.tagid}}@{{request.site.domain}}"},
+					{Name: "summary", Value: "{{bidder}}:{{mediatype}}:{{targeting.hb_pb}}"},
+					{Name: "missing", Value: "{{bid.crid}}"},
+					{Name: "fallback", Value: "{{bid.crid=nocrid}}"},
+				},
+			},
+			expectedTargets: map[string]string{
+				"hb_bidder": "appnexus",
+				"price":     "1.20",
+				"hb_deal":   "deal_mydeal",
+				"gpid":      "/1234/home#top",
+				"slot":      "slot-top@publisher.com",
+				"summary":   "appnexus:banner:1.20",
+				"fallback":  "nocrid",
+			},
+		},
+		{
+			name: "bidder-keys",
+			accountTargeting: config.AccountTargeting{
+				Namespace: "pbs",
+				Keys: []config.AccountTargetingKey{
+					{Key: "hb_pb", Name: "price"},
+					{Name: "tag", Value: "{{imp.tagid}}"},
+				},
+			},
+			includeBidderKeys: true,
+			expectedTargets: map[string]string{
+				"pbs_bidder":          "appnexus",
+				"pbs_bidder_appnexus": "appnexus",
+				"price":               "1.20",
+				"price_appnexus":      "1.20",
+				"pbs_size":            "300x250",
+				"pbs_size_appnexus":   "300x250",
+				"pbs_deal":            "mydeal",
+				"pbs_deal_appnexus":   "mydeal",
+				"tag":                 "slot-top",
+				"tag_appnexus":        "slot-top",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			keyTemplates, err := newTargetingKeyTemplates(test.accountTargeting, bidRequest)
+			require.NoError(t, err)
+
+			bid := &entities.PbsOrtbBid{
+				Bid:     &openrtb2.Bid{ID: "bid1", ImpID: "ImpId-1", Price: 1.23, W: 300, H: 250, DealID: "mydeal"},
+				BidType: openrtb_ext.BidTypeBanner,
+			}
+			auc := &auction{
+				allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*entities.PbsOrtbBid{
+					"ImpId-1": {openrtb_ext.BidderAppnexus: {bid}},
+				},
+				winningBids: map[string]*entities.PbsOrtbBid{"ImpId-1": bid},
+			}
+			targData := targetData{
+				priceGranularity:  lookupPriceGranularity("med"),
+				includeWinners:    true,
+				includeBidderKeys: test.includeBidderKeys,
+				keyTemplates:      keyTemplates,
+			}
+			auc.setRoundedPrices(targData)
+
+			// A zero truncation length keeps the keys whole
+			targData.setTargeting(auc, false, nil, ptrutil.ToPtr(0), nil)
+			assert.Equal(t, test.expectedTargets, bid.BidTargets)
+		})
+	}
+}
+
+func TestNewTargetingKeyTemplates(t *testing.T) {
+	keyTemplates, err := newTargetingKeyTemplates(config.AccountTargeting{}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, keyTemplates)
+
+	_, err = newTargetingKeyTemplates(config.AccountTargeting{Keys: []config.AccountTargetingKey{{Name: "bad", Value: "{{bid"}}}, nil)
+	assert.Error(t, err)
+}
+
+func TestAccountTargetingKeyTemplates(t *testing.T) {
+	accountTargeting := config.AccountTargeting{Namespace: "pbs"}
+
+	testCases := []struct {
+		name               string
+		requestType        metrics.RequestType
+		accountTargeting   config.AccountTargeting
+		expectedTemplates  bool
+		expectedWarningMsg string
+	}{
+		{
+			name:              "auction",
+			requestType:       metrics.ReqTypeORTB2Web,
+			accountTargeting:  accountTargeting,
+			expectedTemplates: true,
+		},
+		{
+			name:               "video",
+			requestType:        metrics.ReqTypeVideo,
+			accountTargeting:   accountTargeting,
+			expectedWarningMsg: "the account targeting namespace and keys are not applied to video requests, which use the legacy targeting keys",
+		},
+		{
+			name:        "video-legacy-keys",
+			requestType: metrics.ReqTypeVideo,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			keyTemplates, err := accountTargetingKeyTemplates(test.requestType, test.accountTargeting, &openrtb2.BidRequest{})
+			assert.Equal(t, test.expectedTemplates, keyTemplates != nil)
+			if test.expectedWarningMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, &errortypes.Warning{WarningCode: errortypes.AccountTargetingWarningCode, Message: test.expectedWarningMsg}, err)
+		})
+	}
+}
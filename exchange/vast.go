@@ -0,0 +1,120 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/exchange/entities"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/vast"
+)
+
+type vastBidResult struct {
+	result vast.Result
+	err    error
+}
+
+// validateVASTBids parses and validates the markup of video bids against the video object of their imp.
+// Invalid bids are rejected in enforce mode and reported in warn mode. Video bids without a duration
+// get the duration of their creative, which is needed for ad pod placement.
+func (e *exchange) validateVASTBids(ctx context.Context, bidRequest *openrtb_ext.RequestWrapper, adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid, validationType string, seatNonBidBuilder *SeatNonBidBuilder) []error {
+	if e.vastProcessor == nil || (validationType != config.ValidationEnforce && validationType != config.ValidationWarn) {
+		return nil
+	}
+
+	videoByImpID := make(map[string]*openrtb2.Video, len(bidRequest.Imp))
+	for i := range bidRequest.Imp {
+		videoByImpID[bidRequest.Imp[i].ID] = bidRequest.Imp[i].Video
+	}
+
+	results := make(map[*entities.PbsOrtbBid]*vastBidResult)
+	for _, seatBid := range adapterBids {
+		if seatBid == nil {
+			continue
+		}
+		for _, bid := range seatBid.Bids {
+			if isVASTBid(bid) {
+				results[bid] = &vastBidResult{}
+			}
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	// Wrapper unwrapping makes network calls, so bids are processed concurrently within the request deadline.
+	var wg sync.WaitGroup
+	for bid, bidResult := range results {
+		wg.Add(1)
+		go func(bid *entities.PbsOrtbBid, bidResult *vastBidResult) {
+			defer wg.Done()
+			bidResult.result, bidResult.err = e.vastProcessor.Process(ctx, bid.Bid.AdM, videoByImpID[bid.Bid.ImpID])
+		}(bid, bidResult)
+	}
+	wg.Wait()
+
+	var errs []error
+	for bidderName, seatBid := range adapterBids {
+		if seatBid == nil {
+			continue
+		}
+		seat := seatBid.Seat
+		if seat == "" {
+			seat = bidderName.String()
+		}
+
+		validBids := seatBid.Bids[:0]
+		for _, bid := range seatBid.Bids {
+			bidResult, ok := results[bid]
+			if !ok {
+				validBids = append(validBids, bid)
+				continue
+			}
+
+			if bidResult.err != nil {
+				if validationType == config.ValidationEnforce {
+					errs = append(errs, &errortypes.Warning{
+						Message:     fmt.Sprintf("%s bid id %s rejected - invalid VAST for imp %s: %v", seat, bid.Bid.ID, bid.Bid.ImpID, bidResult.err),
+						WarningCode: errortypes.InvalidVASTWarningCode,
+					})
+					seatNonBidBuilder.rejectBid(bid, int(openrtb_ext.ResponseRejectedInvalidCreative), seat)
+					continue
+				}
+				errs = append(errs, &errortypes.Warning{
+					Message:     fmt.Sprintf("%s bid id %s has invalid VAST for imp %s: %v", seat, bid.Bid.ID, bid.Bid.ImpID, bidResult.err),
+					WarningCode: errortypes.InvalidVASTWarningCode,
+				})
+			}
+
+			setVideoDuration(bid, bidResult.result)
+			validBids = append(validBids, bid)
+		}
+		seatBid.Bids = validBids
+	}
+	return errs
+}
+
+func isVASTBid(bid *entities.PbsOrtbBid) bool {
+	return bid != nil && bid.Bid != nil && bid.BidType == openrtb_ext.BidTypeVideo && strings.TrimSpace(bid.Bid.AdM) != ""
+}
+
+// setVideoDuration fills in the duration of a video bid which didn't declare one.
+func setVideoDuration(bid *entities.PbsOrtbBid, result vast.Result) {
+	duration := result.DurationSeconds()
+	if duration <= 0 || bid.Bid.Dur > 0 {
+		return
+	}
+
+	bid.Bid.Dur = int64(duration)
+	if bid.BidVideo == nil {
+		bid.BidVideo = &openrtb_ext.ExtBidPrebidVideo{}
+	}
+	if bid.BidVideo.Duration == 0 {
+		bid.BidVideo.Duration = duration
+	}
+}
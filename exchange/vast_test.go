@@ -0,0 +1,118 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/exchange/entities"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+const validVAST = `<VAST version="3.0"><Ad><InLine><Creatives><Creative><Linear><Duration>00:00:30</Duration><MediaFiles><MediaFile type="video/mp4"><![CDATA[https://cdn.test/ad.mp4]]></MediaFile></MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>`
+
+func TestValidateVASTBids(t *testing.T) {
+	newAdapterBids := func() map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid {
+		return map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+			"pubmatic": {
+				Seat: "pubmatic",
+				Bids: []*entities.PbsOrtbBid{
+					{Bid: &openrtb2.Bid{ID: "valid", ImpID: "imp1", AdM: validVAST}, BidType: openrtb_ext.BidTypeVideo},
+					{Bid: &openrtb2.Bid{ID: "invalid", ImpID: "imp1", AdM: "<VAST"}, BidType: openrtb_ext.BidTypeVideo},
+					{Bid: &openrtb2.Bid{ID: "declared-duration", ImpID: "imp1", AdM: validVAST, Dur: 15}, BidType: openrtb_ext.BidTypeVideo, BidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 15}},
+					{Bid: &openrtb2.Bid{ID: "nurl-only", ImpID: "imp1", NURL: "https://win.test"}, BidType: openrtb_ext.BidTypeVideo},
+					{Bid: &openrtb2.Bid{ID: "banner", ImpID: "imp2", AdM: "<div></div>"}, BidType: openrtb_ext.BidTypeBanner},
+				},
+			},
+		}
+	}
+	bidRequest := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{ID: "imp1", Video: &openrtb2.Video{MIMEs: []string{"video/mp4"}, MaxDuration: 30}},
+			{ID: "imp2", Banner: &openrtb2.Banner{}},
+		},
+	}}
+
+	testCases := []struct {
+		name              string
+		validationType    string
+		expectedBidIDs    []string
+		expectedNonBids   int
+		expectedErrs      []error
+		expectedDurations map[string]int64
+	}{
+		{
+			name:           "skip",
+			validationType: config.ValidationSkip,
+			expectedBidIDs: []string{"valid", "invalid", "declared-duration", "nurl-only", "banner"},
+			expectedDurations: map[string]int64{
+				"valid":             0,
+				"declared-duration": 15,
+			},
+		},
+		{
+			name:           "warn",
+			validationType: config.ValidationWarn,
+			expectedBidIDs: []string{"valid", "invalid", "declared-duration", "nurl-only", "banner"},
+			expectedErrs: []error{&errortypes.Warning{
+				Message:     "pubmatic bid id invalid has invalid VAST for imp imp1: malformed VAST: XML syntax error on line 1: unexpected EOF",
+				WarningCode: errortypes.InvalidVASTWarningCode,
+			}},
+			expectedDurations: map[string]int64{
+				"valid":             30,
+				"declared-duration": 15,
+			},
+		},
+		{
+			name:            "enforce",
+			validationType:  config.ValidationEnforce,
+			expectedBidIDs:  []string{"valid", "declared-duration", "nurl-only", "banner"},
+			expectedNonBids: 1,
+			expectedErrs: []error{&errortypes.Warning{
+				Message:     "pubmatic bid id invalid rejected - invalid VAST for imp imp1: malformed VAST: XML syntax error on line 1: unexpected EOF",
+				WarningCode: errortypes.InvalidVASTWarningCode,
+			}},
+			expectedDurations: map[string]int64{
+				"valid":             30,
+				"declared-duration": 15,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			e := &exchange{vastProcessor: vast.NewProcessor(nil, 0)}
+			adapterBids := newAdapterBids()
+			seatNonBidBuilder := SeatNonBidBuilder{}
+
+			errs := e.validateVASTBids(context.Background(), bidRequest, adapterBids, test.validationType, &seatNonBidBuilder)
+			assert.Equal(t, test.expectedErrs, errs)
+
+			var bidIDs []string
+			for _, bid := range adapterBids["pubmatic"].Bids {
+				bidIDs = append(bidIDs, bid.Bid.ID)
+				if expectedDuration, ok := test.expectedDurations[bid.Bid.ID]; ok {
+					assert.Equal(t, expectedDuration, bid.Bid.Dur, bid.Bid.ID)
+					if expectedDuration > 0 {
+						assert.Equal(t, int(expectedDuration), bid.BidVideo.Duration, bid.Bid.ID)
+					}
+				}
+			}
+			assert.Equal(t, test.expectedBidIDs, bidIDs)
+
+			nonBids := seatNonBidBuilder.Slice()
+			if test.expectedNonBids == 0 {
+				assert.Empty(t, nonBids)
+				return
+			}
+			if assert.Len(t, nonBids, 1) && assert.Len(t, nonBids[0].NonBid, test.expectedNonBids) {
+				assert.Equal(t, int(openrtb_ext.ResponseRejectedInvalidCreative), nonBids[0].NonBid[0].StatusCode)
+				assert.Equal(t, "imp1", nonBids[0].NonBid[0].ImpId)
+			}
+		})
+	}
+}
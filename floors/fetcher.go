@@ -9,6 +9,7 @@ import (
 	"math"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/alitto/pond"
@@ -51,6 +52,7 @@ type PriceFloorFetcher struct {
 	time            timeutil.Time         // time interface to record request timings
 	metricEngine    metrics.MetricsEngine // Records malfunctions in dynamic fetch
 	maxRetries      int                   // Max number of retries for failing URLs
+	running         atomic.Bool           // True while the fetcher processes the fetch requests
 }
 
 type FetchQueue []*fetchInfo
@@ -201,9 +203,15 @@ func (f *PriceFloorFetcher) submit(fetchConfig *fetchInfo) {
 	}
 }
 
+// Running returns true while the fetcher processes the fetch requests, from its start until it is stopped
+func (f *PriceFloorFetcher) Running() bool {
+	return f.running.Load()
+}
+
 func (f *PriceFloorFetcher) Fetcher() {
 	//Create Ticker of 5 minutes
 	ticker := time.NewTicker(time.Duration(refetchCheckInterval) * time.Second)
+	f.running.Store(true)
 
 	for {
 		select {
@@ -223,6 +231,7 @@ func (f *PriceFloorFetcher) Fetcher() {
 				f.submit(nextFetch.(*fetchInfo))
 			}
 		case <-f.done:
+			f.running.Store(false)
 			ticker.Stop()
 			glog.Info("Price Floor fetcher terminated")
 			return
@@ -1305,3 +1305,19 @@ func TestFetchWhenPriceFloorsDisabled(t *testing.T) {
 	assert.Equal(t, (*openrtb_ext.PriceFloorRules)(nil), data, "floor data should be nil as fetcher instance does not created")
 	assert.Equal(t, openrtb_ext.FetchNone, status, "floor status should be none as fetcher instance does not created")
 }
+
+func TestPriceFloorFetcherRunning(t *testing.T) {
+	floorConfig := config.PriceFloors{
+		Enabled: true,
+		Fetcher: config.PriceFloorFetcher{
+			CacheSize: 1,
+			Worker:    5,
+			Capacity:  10,
+		},
+	}
+	fetcherInstance := NewPriceFloorFetcher(floorConfig, http.DefaultClient, &metricsConf.NilMetricsEngine{})
+	assert.Eventually(t, fetcherInstance.Running, time.Second, time.Millisecond, "the fetcher should be running once started")
+
+	fetcherInstance.Stop()
+	assert.Eventually(t, func() bool { return !fetcherInstance.Running() }, time.Second, time.Millisecond, "the fetcher should not be running once stopped")
+}
@@ -232,14 +232,17 @@ func getDeviceType(request *openrtb_ext.RequestWrapper) string {
 	if request.Device == nil || len(request.Device.UA) == 0 {
 		return value
 	}
-	if isMobileDevice(request.Device.UA) {
-		value = Phone
-	} else if isTabletDevice(request.Device.UA) {
-		value = Tablet
-	} else {
-		value = Desktop
+	return UserAgentDeviceType(request.Device.UA)
+}
+
+// UserAgentDeviceType returns the device type of the user agent: Phone, Tablet or Desktop
+func UserAgentDeviceType(userAgent string) string {
+	if isMobileDevice(userAgent) {
+		return Phone
+	} else if isTabletDevice(userAgent) {
+		return Tablet
 	}
-	return value
+	return Desktop
 }
 
 // getDeviceCountry returns device country provided into request
@@ -27,12 +27,31 @@ type VendorListFetcher func(ctx context.Context, specVersion uint16, listVersion
 //
 // Nothing in this file is exported. Public APIs can be found in gdpr.go
 
-func NewVendorListFetcher(initCtx context.Context, cfg config.GDPR, client *http.Client, urlMaker func(uint16, uint16) string) VendorListFetcher {
+// preloadRetryInterval is the delay before the first retry of the preload. It doubles after each failed retry, up to
+// preloadMaxRetryInterval, so an unreachable vendor list server isn't polled every few seconds for hours.
+const (
+	preloadRetryInterval    = 10 * time.Second
+	preloadMaxRetryInterval = 5 * time.Minute
+)
+
+// NewVendorListFetcher returns the fetcher, and a function telling if the latest vendor lists have been loaded.
+//
+// If the latest vendor lists can't be preloaded, the preload is retried in the background, with an increasing delay,
+// until they are or until initCtx is done. The retries aren't limited in number since PBS isn't ready without the lists.
+func NewVendorListFetcher(initCtx context.Context, cfg config.GDPR, client *http.Client, urlMaker func(uint16, uint16) string) (VendorListFetcher, func() bool) {
 	cacheSave, cacheLoad := newVendorListCache()
 
-	preloadContext, cancel := context.WithTimeout(initCtx, cfg.Timeouts.InitTimeout())
-	defer cancel()
-	preloadCache(preloadContext, client, urlMaker, cacheSave)
+	preload := func() bool {
+		preloadContext, cancel := context.WithTimeout(initCtx, cfg.Timeouts.InitTimeout())
+		defer cancel()
+		return preloadCache(preloadContext, client, urlMaker, cacheSave)
+	}
+	loaded := &atomic.Bool{}
+	if preload() {
+		loaded.Store(true)
+	} else {
+		go retryPreload(initCtx, preloadRetryInterval, preloadMaxRetryInterval, preload, loaded)
+	}
 
 	saveOneRateLimited := newOccasionalSaver(cfg.Timeouts.ActiveTimeout())
 	return func(ctx context.Context, specVersion, listVersion uint16) (vendorlist.VendorList, error) {
@@ -53,6 +72,28 @@ func NewVendorListFetcher(initCtx context.Context, cfg config.GDPR, client *http
 
 		// Give Up
 		return nil, makeVendorListNotFoundError(specVersion, listVersion)
+	}, loaded.Load
+}
+
+// retryPreload runs the preload until it succeeds, or until the context is done. The delay between the attempts
+// starts at interval and doubles after each failure, up to maxInterval.
+func retryPreload(ctx context.Context, interval, maxInterval time.Duration, preload func() bool, loaded *atomic.Bool) {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			glog.Infof("Retrying to preload the GDPR vendor lists")
+			if preload() {
+				loaded.Store(true)
+				return
+			}
+			interval = min(2*interval, maxInterval)
+			timer.Reset(interval)
+		}
 	}
 }
 
@@ -60,8 +101,9 @@ func makeVendorListNotFoundError(specVersion, listVersion uint16) error {
 	return fmt.Errorf("gdpr vendor list spec version %d list version %d does not exist, or has not been loaded yet. Try again in a few minutes", specVersion, listVersion)
 }
 
-// preloadCache saves all the known versions of the vendor list for future use.
-func preloadCache(ctx context.Context, client *http.Client, urlMaker func(uint16, uint16) string, saver saveVendors) {
+// preloadCache saves all the known versions of the vendor list for future use. It returns true if the latest
+// versions were saved.
+func preloadCache(ctx context.Context, client *http.Client, urlMaker func(uint16, uint16) string, saver saveVendors) bool {
 	versions := [2]struct {
 		specVersion      uint16
 		firstListVersion uint16
@@ -75,13 +117,18 @@ func preloadCache(ctx context.Context, client *http.Client, urlMaker func(uint16
 			firstListVersion: 1,
 		},
 	}
+	latestSaved := true
 	for _, v := range versions {
 		latestVersion := saveOne(ctx, client, urlMaker(v.specVersion, 0), saver)
+		if latestVersion == 0 {
+			latestSaved = false
+		}
 
 		for i := v.firstListVersion; i < latestVersion; i++ {
 			saveOne(ctx, client, urlMaker(v.specVersion, i), saver)
 		}
 	}
+	return latestSaved
 }
 
 // Make a URL which can be used to fetch a given version of the Global Vendor List. If the version is 0,
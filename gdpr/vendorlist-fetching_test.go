@@ -5,9 +5,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/prebid/go-gdpr/api"
 	"github.com/prebid/go-gdpr/consentconstants"
@@ -95,7 +98,7 @@ func TestFetcherThrottling(t *testing.T) {
 	})))
 	defer server.Close()
 
-	fetcher := NewVendorListFetcher(context.Background(), testConfig(), server.Client(), testURLMaker(server))
+	fetcher, _ := NewVendorListFetcher(context.Background(), testConfig(), server.Client(), testURLMaker(server))
 
 	// Dynamically Load List 2 Successfully
 	_, errList1 := fetcher(context.Background(), 3, 2)
@@ -118,7 +121,7 @@ func TestMalformedVendorlist(t *testing.T) {
 	})))
 	defer server.Close()
 
-	fetcher := NewVendorListFetcher(context.Background(), testConfig(), server.Client(), testURLMaker(server))
+	fetcher, _ := NewVendorListFetcher(context.Background(), testConfig(), server.Client(), testURLMaker(server))
 	_, err := fetcher(context.Background(), 3, 1)
 
 	// Fetching should fail since vendor list could not be unmarshalled.
@@ -131,7 +134,7 @@ func TestServerUrlInvalid(t *testing.T) {
 
 	invalidURLGenerator := func(uint16, uint16) string { return " http://invalid-url-has-leading-whitespace" }
 
-	fetcher := NewVendorListFetcher(context.Background(), testConfig(), server.Client(), invalidURLGenerator)
+	fetcher, _ := NewVendorListFetcher(context.Background(), testConfig(), server.Client(), invalidURLGenerator)
 	_, err := fetcher(context.Background(), 3, 1)
 
 	assert.EqualError(t, err, "gdpr vendor list spec version 3 list version 1 does not exist, or has not been loaded yet. Try again in a few minutes")
@@ -141,12 +144,81 @@ func TestServerUnavailable(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	server.Close()
 
-	fetcher := NewVendorListFetcher(context.Background(), testConfig(), server.Client(), testURLMaker(server))
+	fetcher, _ := NewVendorListFetcher(context.Background(), testConfig(), server.Client(), testURLMaker(server))
 	_, err := fetcher(context.Background(), 3, 1)
 
 	assert.EqualError(t, err, "gdpr vendor list spec version 3 list version 1 does not exist, or has not been loaded yet. Try again in a few minutes")
 }
 
+func TestFetcherLoaded(t *testing.T) {
+	testCases := []struct {
+		description    string
+		vendorLists    map[int]map[int]string
+		expectedLoaded bool
+	}{
+		{
+			description: "latest_lists_loaded",
+			vendorLists: map[int]map[int]string{
+				2: {1: MarshalVendorList(vendorList{GVLSpecificationVersion: 2, VendorListVersion: 1})},
+				3: {1: vendorList1},
+			},
+			expectedLoaded: true,
+		},
+		{
+			description: "latest_list_missing",
+			vendorLists: map[int]map[int]string{
+				3: {1: vendorList1},
+			},
+			expectedLoaded: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(mockServer(serverSettings{
+				vendorListLatestVersion: 1,
+				vendorLists:             test.vendorLists,
+			})))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			_, loaded := NewVendorListFetcher(ctx, testConfig(), server.Client(), testURLMaker(server))
+
+			assert.Equal(t, test.expectedLoaded, loaded())
+		})
+	}
+}
+
+func TestRetryPreload(t *testing.T) {
+	var attemptTimes []time.Time
+	preload := func() bool {
+		attemptTimes = append(attemptTimes, time.Now())
+		return len(attemptTimes) == 4
+	}
+	loaded := &atomic.Bool{}
+
+	start := time.Now()
+	retryPreload(context.Background(), 10*time.Millisecond, 20*time.Millisecond, preload, loaded)
+
+	require.Len(t, attemptTimes, 4)
+	// the delays are 10ms, then doubled to 20ms and capped there
+	assert.GreaterOrEqual(t, attemptTimes[0].Sub(start), 10*time.Millisecond)
+	assert.GreaterOrEqual(t, attemptTimes[1].Sub(attemptTimes[0]), 20*time.Millisecond)
+	assert.GreaterOrEqual(t, attemptTimes[2].Sub(attemptTimes[1]), 20*time.Millisecond)
+	assert.True(t, loaded.Load())
+}
+
+func TestRetryPreloadCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	loaded := &atomic.Bool{}
+
+	retryPreload(ctx, time.Hour, time.Hour, func() bool { return true }, loaded)
+
+	assert.False(t, loaded.Load())
+}
+
 func TestVendorListURLMaker(t *testing.T) {
 	testCases := []struct {
 		description string
@@ -253,7 +325,8 @@ func TestPreloadCache(t *testing.T) {
 	defer server.Close()
 
 	s := make(saver, 0, 5)
-	preloadCache(context.Background(), server.Client(), testURLMaker(server), s.saveVendorLists)
+	latestSaved := preloadCache(context.Background(), server.Client(), testURLMaker(server), s.saveVendorLists)
+	assert.True(t, latestSaved)
 
 	expectedLoadedVersions := []versionInfo{
 		{specVersion: 2, listVersion: 2},
@@ -380,7 +453,7 @@ type testExpected struct {
 
 func runTest(t *testing.T, test test, server *httptest.Server) {
 	config := testConfig()
-	fetcher := NewVendorListFetcher(context.Background(), config, server.Client(), testURLMaker(server))
+	fetcher, _ := NewVendorListFetcher(context.Background(), config, server.Client(), testURLMaker(server))
 	vendorList, err := fetcher(context.Background(), test.setup.specVersion, test.setup.listVersion)
 
 	if test.expected.errorMessage != "" {
@@ -0,0 +1,90 @@
+// Package health tells if PBS is ready to serve requests. The dependencies loading their data in the background
+// register a check, and PBS is ready once all the checks pass, until it starts draining on shutdown.
+package health
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// checkOK is the result of a passing check in the Status.
+const checkOK = "ok"
+
+// Check returns an error while the dependency isn't ready.
+type Check func() error
+
+// Loaded returns a Check failing until the data is loaded.
+func Loaded(data string, loaded func() bool) Check {
+	return func() error {
+		if !loaded() {
+			return fmt.Errorf("%s not loaded yet", data)
+		}
+		return nil
+	}
+}
+
+// Checker runs the readiness checks.
+//
+// A nil *Checker is always ready, so the dependencies don't need to check if readiness is used.
+type Checker struct {
+	mutex    sync.RWMutex
+	checks   map[string]Check
+	draining atomic.Bool
+}
+
+// Status is the readiness of PBS, with the result of each check.
+type Status struct {
+	Ready    bool `json:"ready"`
+	Draining bool `json:"draining,omitempty"`
+	// Checks are "ok" or the error of each check.
+	Checks map[string]string `json:"checks"`
+}
+
+func NewChecker() *Checker {
+	return &Checker{
+		checks: make(map[string]Check),
+	}
+}
+
+// Register adds a check, replacing the check already registered with the same name.
+func (c *Checker) Register(name string, check Check) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.checks[name] = check
+}
+
+// Drain makes PBS not ready anymore, so the load balancers stop sending requests before the servers stop.
+func (c *Checker) Drain() {
+	if c == nil {
+		return
+	}
+	c.draining.Store(true)
+}
+
+// Status runs the checks.
+func (c *Checker) Status() Status {
+	status := Status{Ready: true, Checks: make(map[string]string)}
+	if c == nil {
+		return status
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for name, check := range c.checks {
+		if err := check(); err != nil {
+			status.Ready = false
+			status.Checks[name] = err.Error()
+		} else {
+			status.Checks[name] = checkOK
+		}
+	}
+	if c.draining.Load() {
+		status.Ready = false
+		status.Draining = true
+	}
+	return status
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	testCases := []struct {
+		description    string
+		checks         map[string]Check
+		drain          bool
+		expectedStatus Status
+	}{
+		{
+			description:    "no_checks",
+			expectedStatus: Status{Ready: true, Checks: map[string]string{}},
+		},
+		{
+			description: "all_pass",
+			checks: map[string]Check{
+				"currency_rates": func() error { return nil },
+				"gvl":            Loaded("vendor lists", func() bool { return true }),
+			},
+			expectedStatus: Status{Ready: true, Checks: map[string]string{"currency_rates": "ok", "gvl": "ok"}},
+		},
+		{
+			description: "one_fails",
+			checks: map[string]Check{
+				"currency_rates": func() error { return errors.New("fetch failed") },
+				"gvl":            Loaded("vendor lists", func() bool { return true }),
+			},
+			expectedStatus: Status{Ready: false, Checks: map[string]string{"currency_rates": "fetch failed", "gvl": "ok"}},
+		},
+		{
+			description: "not_loaded",
+			checks: map[string]Check{
+				"gvl": Loaded("vendor lists", func() bool { return false }),
+			},
+			expectedStatus: Status{Ready: false, Checks: map[string]string{"gvl": "vendor lists not loaded yet"}},
+		},
+		{
+			description: "draining",
+			checks: map[string]Check{
+				"gvl": Loaded("vendor lists", func() bool { return true }),
+			},
+			drain:          true,
+			expectedStatus: Status{Ready: false, Draining: true, Checks: map[string]string{"gvl": "ok"}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			checker := NewChecker()
+			for name, check := range test.checks {
+				checker.Register(name, check)
+			}
+			if test.drain {
+				checker.Drain()
+			}
+
+			assert.Equal(t, test.expectedStatus, checker.Status())
+		})
+	}
+}
+
+func TestRegisterReplaces(t *testing.T) {
+	checker := NewChecker()
+	checker.Register("gvl", func() error { return errors.New("not loaded") })
+	checker.Register("gvl", func() error { return nil })
+
+	assert.True(t, checker.Status().Ready)
+}
+
+func TestNilChecker(t *testing.T) {
+	var checker *Checker
+	checker.Register("gvl", func() error { return errors.New("not loaded") })
+	checker.Drain()
+
+	assert.Equal(t, Status{Ready: true, Checks: map[string]string{}}, checker.Status())
+}
@@ -1,6 +1,8 @@
 package hooks
 
 import (
+	"encoding/json"
+
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/hooks/hookstage"
 )
@@ -36,3 +38,23 @@ func (e EmptyPlanBuilder) PlanForAllProcessedBidResponsesStage(endpoint string,
 func (e EmptyPlanBuilder) PlanForAuctionResponseStage(endpoint string, account *config.Account) Plan[hookstage.AuctionResponse] {
 	return nil
 }
+
+func (e EmptyPlanBuilder) PlanForExitpointStage(endpoint string, account *config.Account) Plan[hookstage.Exitpoint] {
+	return nil
+}
+
+func (e EmptyPlanBuilder) PlanForCookieSyncStage(endpoint string, account *config.Account) Plan[hookstage.CookieSync] {
+	return nil
+}
+
+func (e EmptyPlanBuilder) PlanForSetUIDStage(endpoint string, account *config.Account) Plan[hookstage.SetUID] {
+	return nil
+}
+
+func (e EmptyPlanBuilder) PlanForEventStage(endpoint string, account *config.Account) Plan[hookstage.Event] {
+	return nil
+}
+
+func (e EmptyPlanBuilder) ModuleConfig(module string, account, request json.RawMessage) (json.RawMessage, []error) {
+	return nil, nil
+}
@@ -0,0 +1,221 @@
+package hookexecution
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// Names of the hook conditions reported when a hook is skipped.
+const (
+	conditionChannel   = "channels"
+	conditionMediaType = "media_types"
+	conditionCountry   = "countries"
+	conditionBidder    = "bidders"
+	conditionAccount   = "accounts"
+	conditionSampling  = "sampling_percentage"
+)
+
+const (
+	mediaTypeBanner = "banner"
+	mediaTypeVideo  = "video"
+	mediaTypeAudio  = "audio"
+	mediaTypeNative = "native"
+)
+
+// requestAttributes holds the values of the request that hook conditions are evaluated against.
+type requestAttributes struct {
+	sync.RWMutex
+	channel    config.ChannelType
+	mediaTypes []string
+	country    string
+}
+
+// moduleSamples holds a random value in [0, 100) drawn once per module for the request,
+// compared against the sampling percentage of the module hooks.
+type moduleSamples struct {
+	sync.Mutex
+	values map[string]float64
+}
+
+// setFromRequest sets the attributes from the bid request. The imps are read from the wrapper, so the imps changed
+// through the wrapper and not rebuilt into the bid request yet are taken into account.
+func (a *requestAttributes) setFromRequest(endpoint string, request *openrtb_ext.RequestWrapper) {
+	if request == nil || request.BidRequest == nil {
+		return
+	}
+
+	channel := endpointChannel(endpoint)
+	if channel == "" {
+		switch {
+		case request.App != nil:
+			channel = config.ChannelApp
+		case request.DOOH != nil:
+			channel = config.ChannelDOOH
+		case request.Site != nil:
+			channel = config.ChannelWeb
+		}
+	}
+
+	var mediaTypes []string
+	for _, imp := range request.GetImp() {
+		mediaTypes = appendMediaType(mediaTypes, mediaTypeBanner, imp.Banner != nil)
+		mediaTypes = appendMediaType(mediaTypes, mediaTypeVideo, imp.Video != nil)
+		mediaTypes = appendMediaType(mediaTypes, mediaTypeAudio, imp.Audio != nil)
+		mediaTypes = appendMediaType(mediaTypes, mediaTypeNative, imp.Native != nil)
+	}
+
+	var country string
+	if request.Device != nil && request.Device.Geo != nil {
+		country = request.Device.Geo.Country
+	}
+
+	a.set(channel, mediaTypes, country)
+}
+
+// setFromRawRequest sets the attributes from the bid request JSON, without unmarshalling the whole request.
+func (a *requestAttributes) setFromRawRequest(endpoint string, body []byte) {
+	channel := endpointChannel(endpoint)
+	if channel == "" {
+		switch {
+		case hasKey(body, "app"):
+			channel = config.ChannelApp
+		case hasKey(body, "dooh"):
+			channel = config.ChannelDOOH
+		case hasKey(body, "site"):
+			channel = config.ChannelWeb
+		}
+	}
+
+	var mediaTypes []string
+	jsonparser.ArrayEach(body, func(imp []byte, _ jsonparser.ValueType, _ int, _ error) {
+		mediaTypes = appendMediaType(mediaTypes, mediaTypeBanner, hasKey(imp, "banner"))
+		mediaTypes = appendMediaType(mediaTypes, mediaTypeVideo, hasKey(imp, "video"))
+		mediaTypes = appendMediaType(mediaTypes, mediaTypeAudio, hasKey(imp, "audio"))
+		mediaTypes = appendMediaType(mediaTypes, mediaTypeNative, hasKey(imp, "native"))
+	}, "imp")
+
+	country, _ := jsonparser.GetString(body, "device", "geo", "country")
+
+	a.set(channel, mediaTypes, country)
+}
+
+func (a *requestAttributes) set(channel config.ChannelType, mediaTypes []string, country string) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.channel = channel
+	a.mediaTypes = mediaTypes
+	a.country = country
+}
+
+func (a *requestAttributes) matches(conditions *config.HookConditions) (string, bool) {
+	a.RLock()
+	defer a.RUnlock()
+
+	if len(conditions.Channels) > 0 && !slices.Contains(conditions.Channels, a.channel) {
+		return conditionChannel, false
+	}
+	if len(conditions.MediaTypes) > 0 && !containsAny(conditions.MediaTypes, a.mediaTypes) {
+		return conditionMediaType, false
+	}
+	if len(conditions.Countries) > 0 && !containsFold(conditions.Countries, a.country) {
+		return conditionCountry, false
+	}
+	return "", true
+}
+
+// get returns the value drawn for the module, drawing it on first use.
+func (s *moduleSamples) get(module string) float64 {
+	if s == nil {
+		return rand.Float64() * 100
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if value, ok := s.values[module]; ok {
+		return value
+	}
+	if s.values == nil {
+		s.values = make(map[string]float64)
+	}
+	value := rand.Float64() * 100
+	s.values[module] = value
+	return value
+}
+
+// unmetCondition returns the name of the first condition of the hook the request doesn't match, if any.
+func (ctx executionContext) unmetCondition(module string, conditions *config.HookConditions) (string, bool) {
+	if conditions == nil {
+		return "", false
+	}
+
+	request := ctx.request
+	if request == nil {
+		request = &requestAttributes{}
+	}
+	if condition, ok := request.matches(conditions); !ok {
+		return condition, true
+	}
+
+	if len(conditions.Bidders) > 0 && !containsFold(conditions.Bidders, ctx.bidder) {
+		return conditionBidder, true
+	}
+	if len(conditions.Accounts) > 0 && !slices.Contains(conditions.Accounts, ctx.accountID) {
+		return conditionAccount, true
+	}
+	if conditions.SamplingPercentage != nil && ctx.samples.get(module) >= *conditions.SamplingPercentage {
+		return conditionSampling, true
+	}
+
+	return "", false
+}
+
+func endpointChannel(endpoint string) config.ChannelType {
+	switch endpoint {
+	case EndpointAmp:
+		return config.ChannelAMP
+	case EndpointVideo:
+		return config.ChannelVideo
+	}
+	return ""
+}
+
+func appendMediaType(mediaTypes []string, mediaType string, present bool) []string {
+	if present && !slices.Contains(mediaTypes, mediaType) {
+		return append(mediaTypes, mediaType)
+	}
+	return mediaTypes
+}
+
+func hasKey(data []byte, key string) bool {
+	_, dataType, _, err := jsonparser.Get(data, key)
+	return err == nil && dataType != jsonparser.Null
+}
+
+func containsAny(values, candidates []string) bool {
+	for _, candidate := range candidates {
+		if slices.Contains(values, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package hookexecution
+
+import (
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmetCondition(t *testing.T) {
+	appRequest := &requestAttributes{channel: config.ChannelApp, mediaTypes: []string{"banner", "video"}, country: "USA"}
+
+	testCases := []struct {
+		description       string
+		givenConditions   *config.HookConditions
+		givenRequest      *requestAttributes
+		givenBidder       string
+		givenAccountID    string
+		expectedCondition string
+		expectedSkip      bool
+	}{
+		{
+			description:     "no-conditions",
+			givenConditions: nil,
+			givenRequest:    appRequest,
+			expectedSkip:    false,
+		},
+		{
+			description: "all-conditions-met",
+			givenConditions: &config.HookConditions{
+				Channels:           []config.ChannelType{config.ChannelWeb, config.ChannelApp},
+				MediaTypes:         []string{"video"},
+				Countries:          []string{"usa"},
+				Bidders:            []string{"AppNexus"},
+				Accounts:           []string{"1001"},
+				SamplingPercentage: ptrutil.ToPtr(100.0),
+			},
+			givenRequest:   appRequest,
+			givenBidder:    "appnexus",
+			givenAccountID: "1001",
+			expectedSkip:   false,
+		},
+		{
+			description:       "channel-not-met",
+			givenConditions:   &config.HookConditions{Channels: []config.ChannelType{config.ChannelWeb}},
+			givenRequest:      appRequest,
+			expectedCondition: "channels",
+			expectedSkip:      true,
+		},
+		{
+			description:       "media-type-not-met",
+			givenConditions:   &config.HookConditions{MediaTypes: []string{"native"}},
+			givenRequest:      appRequest,
+			expectedCondition: "media_types",
+			expectedSkip:      true,
+		},
+		{
+			description:       "country-not-met",
+			givenConditions:   &config.HookConditions{Countries: []string{"DEU"}},
+			givenRequest:      appRequest,
+			expectedCondition: "countries",
+			expectedSkip:      true,
+		},
+		{
+			description:       "country-unknown-at-stage",
+			givenConditions:   &config.HookConditions{Countries: []string{"USA"}},
+			givenRequest:      nil,
+			expectedCondition: "countries",
+			expectedSkip:      true,
+		},
+		{
+			description:       "bidder-not-met",
+			givenConditions:   &config.HookConditions{Bidders: []string{"rubicon"}},
+			givenRequest:      appRequest,
+			givenBidder:       "appnexus",
+			expectedCondition: "bidders",
+			expectedSkip:      true,
+		},
+		{
+			description:       "bidder-outside-bidder-stages",
+			givenConditions:   &config.HookConditions{Bidders: []string{"appnexus"}},
+			givenRequest:      appRequest,
+			expectedCondition: "bidders",
+			expectedSkip:      true,
+		},
+		{
+			description:       "account-not-met",
+			givenConditions:   &config.HookConditions{Accounts: []string{"1001"}},
+			givenRequest:      appRequest,
+			givenAccountID:    "2002",
+			expectedCondition: "accounts",
+			expectedSkip:      true,
+		},
+		{
+			description:       "sampled-out",
+			givenConditions:   &config.HookConditions{SamplingPercentage: ptrutil.ToPtr(0.0)},
+			givenRequest:      appRequest,
+			expectedCondition: "sampling_percentage",
+			expectedSkip:      true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			ctx := executionContext{request: test.givenRequest, samples: &moduleSamples{}, bidder: test.givenBidder, accountID: test.givenAccountID}
+
+			condition, skip := ctx.unmetCondition("acme.foo", test.givenConditions)
+			assert.Equal(t, test.expectedSkip, skip)
+			assert.Equal(t, test.expectedCondition, condition)
+		})
+	}
+}
+
+func TestModuleSamples(t *testing.T) {
+	samples := &moduleSamples{}
+
+	first := samples.get("acme.foo")
+	assert.GreaterOrEqual(t, first, 0.0)
+	assert.Less(t, first, 100.0)
+	assert.Equal(t, first, samples.get("acme.foo"), "a module must be sampled once per request")
+}
+
+func TestRequestAttributes(t *testing.T) {
+	testCases := []struct {
+		description        string
+		givenEndpoint      string
+		givenRequest       *openrtb2.BidRequest
+		givenRawRequest    string
+		expectedChannel    config.ChannelType
+		expectedMediaTypes []string
+		expectedCountry    string
+	}{
+		{
+			description:   "app",
+			givenEndpoint: EndpointAuction,
+			givenRequest: &openrtb2.BidRequest{
+				App:    &openrtb2.App{},
+				Imp:    []openrtb2.Imp{{Banner: &openrtb2.Banner{}}, {Banner: &openrtb2.Banner{}, Native: &openrtb2.Native{}}},
+				Device: &openrtb2.Device{Geo: &openrtb2.Geo{Country: "USA"}},
+			},
+			givenRawRequest:    `{"app":{},"imp":[{"banner":{}},{"banner":{},"native":{}}],"device":{"geo":{"country":"USA"}}}`,
+			expectedChannel:    config.ChannelApp,
+			expectedMediaTypes: []string{"banner", "native"},
+			expectedCountry:    "USA",
+		},
+		{
+			description:        "web",
+			givenEndpoint:      EndpointAuction,
+			givenRequest:       &openrtb2.BidRequest{Site: &openrtb2.Site{}, Imp: []openrtb2.Imp{{Video: &openrtb2.Video{}}, {Audio: &openrtb2.Audio{}}}},
+			givenRawRequest:    `{"site":{},"imp":[{"video":{}},{"audio":{}}]}`,
+			expectedChannel:    config.ChannelWeb,
+			expectedMediaTypes: []string{"video", "audio"},
+		},
+		{
+			description:     "dooh",
+			givenEndpoint:   EndpointAuction,
+			givenRequest:    &openrtb2.BidRequest{DOOH: &openrtb2.DOOH{}},
+			givenRawRequest: `{"dooh":{}}`,
+			expectedChannel: config.ChannelDOOH,
+		},
+		{
+			description:        "amp-endpoint",
+			givenEndpoint:      EndpointAmp,
+			givenRequest:       &openrtb2.BidRequest{Site: &openrtb2.Site{}, Imp: []openrtb2.Imp{{Banner: &openrtb2.Banner{}}}},
+			givenRawRequest:    `{"site":{},"imp":[{"banner":{}}]}`,
+			expectedChannel:    config.ChannelAMP,
+			expectedMediaTypes: []string{"banner"},
+		},
+		{
+			description:        "video-endpoint",
+			givenEndpoint:      EndpointVideo,
+			givenRequest:       &openrtb2.BidRequest{App: &openrtb2.App{}, Imp: []openrtb2.Imp{{Video: &openrtb2.Video{}}}},
+			givenRawRequest:    `{"app":{},"imp":[{"video":{}}]}`,
+			expectedChannel:    config.ChannelVideo,
+			expectedMediaTypes: []string{"video"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			attributes := &requestAttributes{}
+			attributes.setFromRequest(test.givenEndpoint, &openrtb_ext.RequestWrapper{BidRequest: test.givenRequest})
+			assert.Equal(t, test.expectedChannel, attributes.channel)
+			assert.Equal(t, test.expectedMediaTypes, attributes.mediaTypes)
+			assert.Equal(t, test.expectedCountry, attributes.country)
+
+			rawAttributes := &requestAttributes{}
+			rawAttributes.setFromRawRequest(test.givenEndpoint, []byte(test.givenRawRequest))
+			assert.Equal(t, test.expectedChannel, rawAttributes.channel)
+			assert.Equal(t, test.expectedMediaTypes, rawAttributes.mediaTypes)
+			assert.Equal(t, test.expectedCountry, rawAttributes.country)
+		})
+	}
+}
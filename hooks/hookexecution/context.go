@@ -1,10 +1,13 @@
 package hookexecution
 
 import (
+	"encoding/json"
+	"strings"
 	"sync"
 
 	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/hooks"
 	"github.com/prebid/prebid-server/v3/hooks/hookstage"
 	"github.com/prebid/prebid-server/v3/privacy"
 )
@@ -16,7 +19,13 @@ type executionContext struct {
 	accountID       string
 	account         *config.Account
 	moduleContexts  *moduleContexts
+	moduleConfigs   *moduleConfigs
 	activityControl privacy.ActivityControl
+	// request and samples hold the values hook conditions are evaluated against
+	request *requestAttributes
+	samples *moduleSamples
+	// bidder is set at the bidder stages
+	bidder string
 }
 
 func (ctx executionContext) getModuleContext(moduleName string) hookstage.ModuleInvocationContext {
@@ -36,9 +45,84 @@ func (ctx executionContext) getModuleContext(moduleName string) hookstage.Module
 		moduleInvocationCtx.AccountConfig = cfg
 	}
 
+	if ctx.moduleConfigs != nil {
+		resolved := ctx.moduleConfigs.get(moduleName, ctx.account)
+		moduleInvocationCtx.Config = resolved.effective
+		moduleInvocationCtx.HostAccountConfig = resolved.hostAccount
+	}
+
 	return moduleInvocationCtx
 }
 
+// moduleConfigs caches the effective config of the modules invoked for the request.
+type moduleConfigs struct {
+	sync.Mutex
+	planBuilder hooks.ExecutionPlanBuilder
+	// request holds the request-level module config, format: {"vendor": {"module_name": config}}
+	request  map[string]map[string]json.RawMessage
+	resolved map[string]resolvedModuleConfig
+}
+
+// resolvedModuleConfig holds the configs of a module passed to its hooks.
+type resolvedModuleConfig struct {
+	effective   json.RawMessage
+	hostAccount json.RawMessage
+}
+
+func (mc *moduleConfigs) get(moduleName string, account *config.Account) resolvedModuleConfig {
+	mc.Lock()
+	defer mc.Unlock()
+
+	if cfg, ok := mc.resolved[moduleName]; ok {
+		return cfg
+	}
+
+	var accountCfg json.RawMessage
+	if account != nil {
+		accountCfg, _ = account.Hooks.Modules.ModuleConfig(moduleName)
+	}
+
+	var requestCfg json.RawMessage
+	if vendor, module, ok := strings.Cut(moduleName, "."); ok {
+		requestCfg = mc.request[vendor][module]
+	}
+
+	// the account-level config is validated when the account is fetched, so the errors come from the request
+	var cfg resolvedModuleConfig
+	cfg.hostAccount, _ = mc.planBuilder.ModuleConfig(moduleName, accountCfg, nil)
+	cfg.effective = cfg.hostAccount
+	if len(requestCfg) > 0 {
+		var errs []error
+		cfg.effective, errs = mc.planBuilder.ModuleConfig(moduleName, accountCfg, requestCfg)
+		for _, err := range errs {
+			glog.V(2).Infof("Invalid request config for %s module: %s", moduleName, err)
+		}
+	}
+
+	if mc.resolved == nil {
+		mc.resolved = make(map[string]resolvedModuleConfig)
+	}
+	mc.resolved[moduleName] = cfg
+	return cfg
+}
+
+// setRequest sets the request-level module config, format: {"vendor": {"module_name": config}}
+func (mc *moduleConfigs) setRequest(request map[string]map[string]json.RawMessage) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	mc.request = request
+	mc.resolved = nil
+}
+
+// reset drops the resolved configs, e.g. once the account is known.
+func (mc *moduleConfigs) reset() {
+	mc.Lock()
+	defer mc.Unlock()
+
+	mc.resolved = nil
+}
+
 // moduleContexts preserves data the module wants to pass to itself from earlier stages to later stages.
 type moduleContexts struct {
 	sync.RWMutex
@@ -71,7 +71,18 @@ func executeGroup[H any, P any](
 	rejected := make(chan struct{})
 	resp := make(chan hookResponse[P], len(group.Hooks))
 
+	var skipped []HookOutcome
 	for _, hook := range group.Hooks {
+		if condition, skip := executionCtx.unmetCondition(hook.Module, hook.Conditions); skip {
+			skipped = append(skipped, HookOutcome{
+				HookID:  HookID{ModuleCode: hook.Module, HookImplCode: hook.Code},
+				Status:  StatusSkipped,
+				Action:  ActionNone,
+				Message: fmt.Sprintf("condition not met: %s", condition),
+			})
+			continue
+		}
+
 		mCtx := executionCtx.getModuleContext(hook.Module)
 		newPayload := handleModuleActivities(hook.Code, executionCtx.activityControl, payload, executionCtx.account)
 		wg.Add(1)
@@ -88,7 +99,9 @@ func executeGroup[H any, P any](
 
 	hookResponses := collectHookResponses(resp, rejected)
 
-	return handleHookResponses(executionCtx, hookResponses, payload, metricEngine)
+	groupOutcome, payload, groupModuleCtx, rejectErr := handleHookResponses(executionCtx, hookResponses, payload, metricEngine)
+	groupOutcome.InvocationResults = append(groupOutcome.InvocationResults, skipped...)
+	return groupOutcome, payload, groupModuleCtx, rejectErr
 }
 
 func executeHook[H any, P any](
@@ -210,6 +223,7 @@ func handleHookResponse[P any](
 		rejectErr = handleHookReject(ctx, hr, &hookOutcome, metricEngine, labels)
 	} else {
 		payload = handleHookMutations(payload, hr, &hookOutcome, metricEngine, labels)
+		hookOutcome.SeatNonBid = hr.Result.SeatNonBid
 	}
 
 	return payload, hookOutcome, rejectErr
@@ -2,9 +2,12 @@ package hookexecution
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"sync"
 
+	"github.com/buger/jsonparser"
+	"github.com/golang/glog"
 	"github.com/prebid/openrtb/v20/openrtb2"
 	"github.com/prebid/prebid-server/v3/adapters"
 	"github.com/prebid/prebid-server/v3/config"
@@ -14,11 +17,17 @@ import (
 	"github.com/prebid/prebid-server/v3/metrics"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/usersync"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
 )
 
 const (
-	EndpointAuction = "/openrtb2/auction"
-	EndpointAmp     = "/openrtb2/amp"
+	EndpointAuction    = "/openrtb2/auction"
+	EndpointAmp        = "/openrtb2/amp"
+	EndpointVideo      = "/openrtb2/video"
+	EndpointCookieSync = "/cookie_sync"
+	EndpointSetUID     = "/setuid"
+	EndpointEvent      = "/event"
 )
 
 // An entity specifies the type of object that was processed during the execution of the stage.
@@ -26,9 +35,12 @@ type entity string
 
 const (
 	entityHttpRequest              entity = "http-request"
+	entityHttpResponse             entity = "http-response"
 	entityAuctionRequest           entity = "auction-request"
 	entityAuctionResponse          entity = "auction_response"
 	entityAllProcessedBidResponses entity = "all_processed_bid_responses"
+	entityUserSync                 entity = "user-sync"
+	entityNotificationEvent        entity = "notification-event"
 )
 
 type StageExecutor interface {
@@ -39,6 +51,11 @@ type StageExecutor interface {
 	ExecuteRawBidderResponseStage(response *adapters.BidderResponse, bidder string) *RejectError
 	ExecuteAllProcessedBidResponsesStage(adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid)
 	ExecuteAuctionResponseStage(response *openrtb2.BidResponse)
+	ExecuteExitpointStage(headers http.Header, body []byte) []byte
+	ExecuteCookieSyncStage(request usersync.Request, syncers []usersync.SyncerChoice) ([]usersync.SyncerChoice, *RejectError)
+	ExecuteSetUIDStage(syncer string, uid string) (string, *RejectError)
+	ExecuteEventStage(event hookstage.EventPayload) (hookstage.EventPayload, *RejectError)
+	GetSeatNonBid() []openrtb_ext.SeatNonBid
 }
 
 type HookStageExecutor interface {
@@ -55,6 +72,9 @@ type hookExecutor struct {
 	planBuilder     hooks.ExecutionPlanBuilder
 	stageOutcomes   []StageOutcome
 	moduleContexts  *moduleContexts
+	moduleConfigs   *moduleConfigs
+	request         *requestAttributes
+	samples         *moduleSamples
 	metricEngine    metrics.MetricsEngine
 	activityControl privacy.ActivityControl
 	// Mutex needed for BidderRequest and RawBidderResponse Stages as they are run in several goroutines
@@ -67,6 +87,9 @@ func NewHookExecutor(builder hooks.ExecutionPlanBuilder, endpoint string, me met
 		planBuilder:    builder,
 		stageOutcomes:  []StageOutcome{},
 		moduleContexts: &moduleContexts{ctxs: make(map[string]hookstage.ModuleContext)},
+		moduleConfigs:  &moduleConfigs{planBuilder: builder},
+		request:        &requestAttributes{},
+		samples:        &moduleSamples{},
 		metricEngine:   me,
 	}
 }
@@ -78,6 +101,7 @@ func (e *hookExecutor) SetAccount(account *config.Account) {
 
 	e.account = account
 	e.accountID = account.ID
+	e.moduleConfigs.reset()
 }
 
 func (e *hookExecutor) SetActivityControl(activityControl privacy.ActivityControl) {
@@ -88,6 +112,22 @@ func (e *hookExecutor) GetOutcomes() []StageOutcome {
 	return e.stageOutcomes
 }
 
+// GetSeatNonBid returns the bids rejected by hooks of all executed stages.
+func (e *hookExecutor) GetSeatNonBid() []openrtb_ext.SeatNonBid {
+	e.Lock()
+	defer e.Unlock()
+
+	var seatNonBid []openrtb_ext.SeatNonBid
+	for _, stageOutcome := range e.stageOutcomes {
+		for _, groupOutcome := range stageOutcome.Groups {
+			for _, hookOutcome := range groupOutcome.InvocationResults {
+				seatNonBid = append(seatNonBid, hookOutcome.SeatNonBid...)
+			}
+		}
+	}
+	return seatNonBid
+}
+
 func (e *hookExecutor) ExecuteEntrypointStage(req *http.Request, body []byte) ([]byte, *RejectError) {
 	plan := e.planBuilder.PlanForEntrypointStage(e.endpoint)
 	if len(plan) == 0 {
@@ -132,6 +172,9 @@ func (e *hookExecutor) ExecuteRawAuctionStage(requestBody []byte) ([]byte, *Reje
 		return hook.HandleRawAuctionHook(ctx, moduleCtx, payload)
 	}
 
+	e.setRequestModuleConfigs(requestBody)
+	e.request.setFromRawRequest(e.endpoint, requestBody)
+
 	stageName := hooks.StageRawAuctionRequest.String()
 	executionCtx := e.newContext(stageName)
 	payload := hookstage.RawAuctionRequestPayload(requestBody)
@@ -143,10 +186,21 @@ func (e *hookExecutor) ExecuteRawAuctionStage(requestBody []byte) ([]byte, *Reje
 	e.saveModuleContexts(contexts)
 	e.pushStageOutcome(outcome)
 
+	// the hooks may have changed the request, so the conditions of the next stages see their changes
+	e.request.setFromRawRequest(e.endpoint, payload)
+
 	return payload, reject
 }
 
 func (e *hookExecutor) ExecuteProcessedAuctionStage(request *openrtb_ext.RequestWrapper) error {
+	// the request-level module config and the request attributes apply to this stage and all the following ones
+	if request != nil && request.BidRequest != nil {
+		if reqExt, err := request.GetRequestExt(); err == nil && reqExt.GetPrebid() != nil {
+			e.moduleConfigs.setRequest(reqExt.GetPrebid().Modules)
+		}
+		e.request.setFromRequest(e.endpoint, request)
+	}
+
 	plan := e.planBuilder.PlanForProcessedAuctionStage(e.endpoint, e.account)
 	if len(plan) == 0 {
 		return nil
@@ -176,6 +230,9 @@ func (e *hookExecutor) ExecuteProcessedAuctionStage(request *openrtb_ext.Request
 	e.saveModuleContexts(contexts)
 	e.pushStageOutcome(outcome)
 
+	// the hooks may have changed the request, so the conditions of the next stages see their changes
+	e.request.setFromRequest(e.endpoint, request)
+
 	// remove type information if there is no rejection
 	if reject == nil {
 		return nil
@@ -201,6 +258,10 @@ func (e *hookExecutor) ExecuteBidderRequestStage(req *openrtb_ext.RequestWrapper
 
 	stageName := hooks.StageBidderRequest.String()
 	executionCtx := e.newContext(stageName)
+	executionCtx.bidder = bidder
+	// the conditions are evaluated against the request sent to the bidder, which only holds the bidder's imps
+	executionCtx.request = &requestAttributes{}
+	executionCtx.request.setFromRequest(e.endpoint, req)
 	payload := hookstage.BidderRequestPayload{Request: req, Bidder: bidder}
 	outcome, _, contexts, reject := executeStage(executionCtx, plan, payload, handler, e.metricEngine)
 	outcome.Entity = entity(bidder)
@@ -229,6 +290,7 @@ func (e *hookExecutor) ExecuteRawBidderResponseStage(response *adapters.BidderRe
 
 	stageName := hooks.StageRawBidderResponse.String()
 	executionCtx := e.newContext(stageName)
+	executionCtx.bidder = bidder
 	payload := hookstage.RawBidderResponsePayload{BidderResponse: response, Bidder: bidder}
 
 	outcome, payload, contexts, reject := executeStage(executionCtx, plan, payload, handler, e.metricEngine)
@@ -295,12 +357,153 @@ func (e *hookExecutor) ExecuteAuctionResponseStage(response *openrtb2.BidRespons
 	e.pushStageOutcome(outcome)
 }
 
+// ExecuteExitpointStage runs the exitpoint hooks against the encoded response. Hooks may modify
+// the response headers in place and the returned body is the one to write to the client.
+func (e *hookExecutor) ExecuteExitpointStage(headers http.Header, body []byte) []byte {
+	plan := e.planBuilder.PlanForExitpointStage(e.endpoint, e.account)
+	if len(plan) == 0 {
+		return body
+	}
+
+	handler := func(
+		ctx context.Context,
+		moduleCtx hookstage.ModuleInvocationContext,
+		hook hookstage.Exitpoint,
+		payload hookstage.ExitpointPayload,
+	) (hookstage.HookResult[hookstage.ExitpointPayload], error) {
+		return hook.HandleExitpointHook(ctx, moduleCtx, payload)
+	}
+
+	stageName := hooks.StageExitpoint.String()
+	executionCtx := e.newContext(stageName)
+	payload := hookstage.ExitpointPayload{Headers: headers, Body: body}
+
+	outcome, payload, contexts, _ := executeStage(executionCtx, plan, payload, handler, e.metricEngine)
+	outcome.Entity = entityHttpResponse
+	outcome.Stage = stageName
+
+	e.saveModuleContexts(contexts)
+	e.pushStageOutcome(outcome)
+
+	return payload.Body
+}
+
+// ExecuteCookieSyncStage runs the cookie sync hooks against the syncers chosen for the request
+// and returns the syncers to include in the response.
+func (e *hookExecutor) ExecuteCookieSyncStage(request usersync.Request, syncers []usersync.SyncerChoice) ([]usersync.SyncerChoice, *RejectError) {
+	plan := e.planBuilder.PlanForCookieSyncStage(e.endpoint, e.account)
+	if len(plan) == 0 {
+		return syncers, nil
+	}
+
+	handler := func(
+		ctx context.Context,
+		moduleCtx hookstage.ModuleInvocationContext,
+		hook hookstage.CookieSync,
+		payload hookstage.CookieSyncPayload,
+	) (hookstage.HookResult[hookstage.CookieSyncPayload], error) {
+		return hook.HandleCookieSyncHook(ctx, moduleCtx, payload)
+	}
+
+	stageName := hooks.StageCookieSync.String()
+	executionCtx := e.newContext(stageName)
+	payload := hookstage.CookieSyncPayload{Request: request, Syncers: syncers}
+
+	outcome, payload, contexts, reject := executeStage(executionCtx, plan, payload, handler, e.metricEngine)
+	outcome.Entity = entityUserSync
+	outcome.Stage = stageName
+
+	e.saveModuleContexts(contexts)
+	e.pushStageOutcome(outcome)
+
+	return payload.Syncers, reject
+}
+
+// ExecuteSetUIDStage runs the setuid hooks against the UID received for the syncer
+// and returns the UID to save to the cookie.
+func (e *hookExecutor) ExecuteSetUIDStage(syncer string, uid string) (string, *RejectError) {
+	plan := e.planBuilder.PlanForSetUIDStage(e.endpoint, e.account)
+	if len(plan) == 0 {
+		return uid, nil
+	}
+
+	handler := func(
+		ctx context.Context,
+		moduleCtx hookstage.ModuleInvocationContext,
+		hook hookstage.SetUID,
+		payload hookstage.SetUIDPayload,
+	) (hookstage.HookResult[hookstage.SetUIDPayload], error) {
+		return hook.HandleSetUIDHook(ctx, moduleCtx, payload)
+	}
+
+	stageName := hooks.StageSetUID.String()
+	executionCtx := e.newContext(stageName)
+	payload := hookstage.SetUIDPayload{Syncer: syncer, UID: uid}
+
+	outcome, payload, contexts, reject := executeStage(executionCtx, plan, payload, handler, e.metricEngine)
+	outcome.Entity = entityUserSync
+	outcome.Stage = stageName
+
+	e.saveModuleContexts(contexts)
+	e.pushStageOutcome(outcome)
+
+	return payload.UID, reject
+}
+
+// ExecuteEventStage runs the event hooks against the notification event
+// and returns the event to send to analytics modules.
+func (e *hookExecutor) ExecuteEventStage(event hookstage.EventPayload) (hookstage.EventPayload, *RejectError) {
+	plan := e.planBuilder.PlanForEventStage(e.endpoint, e.account)
+	if len(plan) == 0 {
+		return event, nil
+	}
+
+	handler := func(
+		ctx context.Context,
+		moduleCtx hookstage.ModuleInvocationContext,
+		hook hookstage.Event,
+		payload hookstage.EventPayload,
+	) (hookstage.HookResult[hookstage.EventPayload], error) {
+		return hook.HandleEventHook(ctx, moduleCtx, payload)
+	}
+
+	stageName := hooks.StageEvent.String()
+	executionCtx := e.newContext(stageName)
+
+	outcome, payload, contexts, reject := executeStage(executionCtx, plan, event, handler, e.metricEngine)
+	outcome.Entity = entityNotificationEvent
+	outcome.Stage = stageName
+
+	e.saveModuleContexts(contexts)
+	e.pushStageOutcome(outcome)
+
+	return payload, reject
+}
+
+// setRequestModuleConfigs reads the request-level module config from the ext.prebid.modules field of the raw request.
+func (e *hookExecutor) setRequestModuleConfigs(requestBody []byte) {
+	modules, _, _, err := jsonparser.Get(requestBody, "ext", "prebid", "modules")
+	if err != nil {
+		return
+	}
+
+	var request map[string]map[string]json.RawMessage
+	if err := jsonutil.Unmarshal(modules, &request); err != nil {
+		glog.Warningf("Failed to parse request-level module config: %s", err)
+		return
+	}
+	e.moduleConfigs.setRequest(request)
+}
+
 func (e *hookExecutor) newContext(stage string) executionContext {
 	return executionContext{
 		account:         e.account,
 		accountID:       e.accountID,
 		endpoint:        e.endpoint,
 		moduleContexts:  e.moduleContexts,
+		moduleConfigs:   e.moduleConfigs,
+		request:         e.request,
+		samples:         e.samples,
 		stage:           stage,
 		activityControl: e.activityControl,
 	}
@@ -354,3 +557,23 @@ func (executor EmptyHookExecutor) ExecuteAllProcessedBidResponsesStage(_ map[ope
 }
 
 func (executor EmptyHookExecutor) ExecuteAuctionResponseStage(_ *openrtb2.BidResponse) {}
+
+func (executor EmptyHookExecutor) ExecuteExitpointStage(_ http.Header, body []byte) []byte {
+	return body
+}
+
+func (executor EmptyHookExecutor) ExecuteCookieSyncStage(_ usersync.Request, syncers []usersync.SyncerChoice) ([]usersync.SyncerChoice, *RejectError) {
+	return syncers, nil
+}
+
+func (executor EmptyHookExecutor) ExecuteSetUIDStage(_ string, uid string) (string, *RejectError) {
+	return uid, nil
+}
+
+func (executor EmptyHookExecutor) ExecuteEventStage(event hookstage.EventPayload) (hookstage.EventPayload, *RejectError) {
+	return event, nil
+}
+
+func (executor EmptyHookExecutor) GetSeatNonBid() []openrtb_ext.SeatNonBid {
+	return nil
+}
@@ -2,9 +2,12 @@ package hookexecution
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -19,9 +22,11 @@ import (
 	metricsConfig "github.com/prebid/prebid-server/v3/metrics/config"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/prebid/prebid-server/v3/util/ptrutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEmptyHookExecutor(t *testing.T) {
@@ -40,10 +45,12 @@ func TestEmptyHookExecutor(t *testing.T) {
 	processedAuctionRejectErr := executor.ExecuteProcessedAuctionStage(&openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{}})
 	bidderRequestRejectErr := executor.ExecuteBidderRequestStage(&openrtb_ext.RequestWrapper{BidRequest: bidderRequest}, "bidder-name")
 	executor.ExecuteAuctionResponseStage(&openrtb2.BidResponse{})
+	executor.ExecuteExitpointStage(http.Header{}, []byte{})
 
 	outcomes := executor.GetOutcomes()
 	assert.Equal(t, EmptyHookExecutor{}, executor, "EmptyHookExecutor shouldn't be changed.")
 	assert.Empty(t, outcomes, "EmptyHookExecutor shouldn't return stage outcomes.")
+	assert.Empty(t, executor.GetSeatNonBid(), "EmptyHookExecutor shouldn't return seat non bids.")
 
 	assert.Nil(t, entrypointRejectErr, "EmptyHookExecutor shouldn't return reject error at entrypoint stage.")
 	assert.Equal(t, body, entrypointBody, "EmptyHookExecutor shouldn't change body at entrypoint stage.")
@@ -1952,6 +1959,206 @@ func TestExecuteAuctionResponseStage(t *testing.T) {
 	}
 }
 
+func TestExecuteExitpointStage(t *testing.T) {
+	foobarModuleCtx := &moduleContexts{ctxs: map[string]hookstage.ModuleContext{"foobar": nil}}
+	body := []byte(`{"id":"some-id"}`)
+
+	testCases := []struct {
+		description            string
+		givenPlanBuilder       hooks.ExecutionPlanBuilder
+		expectedBody           []byte
+		expectedHeaders        http.Header
+		expectedModuleContexts *moduleContexts
+		expectedStageOutcomes  []StageOutcome
+	}{
+		{
+			description:            "Payload not changed if hook execution plan empty",
+			givenPlanBuilder:       hooks.EmptyPlanBuilder{},
+			expectedBody:           body,
+			expectedHeaders:        http.Header{"Content-Type": {"application/json"}},
+			expectedModuleContexts: &moduleContexts{ctxs: map[string]hookstage.ModuleContext{}},
+			expectedStageOutcomes:  []StageOutcome{},
+		},
+		{
+			description:            "Payload changed if hooks return mutations",
+			givenPlanBuilder:       TestApplyHookMutationsBuilder{},
+			expectedBody:           []byte(`<VAST version="4.0"></VAST>`),
+			expectedHeaders:        http.Header{"Content-Type": {"application/json"}, "Server-Timing": {"auction;dur=10"}},
+			expectedModuleContexts: foobarModuleCtx,
+			expectedStageOutcomes: []StageOutcome{
+				{
+					Entity: entityHttpResponse,
+					Stage:  hooks.StageExitpoint.String(),
+					Groups: []GroupOutcome{
+						{
+							InvocationResults: []HookOutcome{
+								{
+									AnalyticsTags: hookanalytics.Analytics{},
+									HookID:        HookID{ModuleCode: "foobar", HookImplCode: "foo"},
+									Status:        StatusSuccess,
+									Action:        ActionUpdate,
+									Message:       "",
+									DebugMessages: []string{
+										fmt.Sprintf("Hook mutation successfully applied, affected key: header.server-timing, mutation type: %s", hookstage.MutationUpdate),
+										fmt.Sprintf("Hook mutation successfully applied, affected key: body, mutation type: %s", hookstage.MutationUpdate),
+									},
+									Errors:   nil,
+									Warnings: nil,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			description:            "Stage execution can't be rejected - stage doesn't support rejection",
+			givenPlanBuilder:       TestRejectPlanBuilder{},
+			expectedBody:           body,
+			expectedHeaders:        http.Header{"Content-Type": {"application/json"}},
+			expectedModuleContexts: foobarModuleCtx,
+			expectedStageOutcomes: []StageOutcome{
+				{
+					Entity: entityHttpResponse,
+					Stage:  hooks.StageExitpoint.String(),
+					Groups: []GroupOutcome{
+						{
+							InvocationResults: []HookOutcome{
+								{
+									AnalyticsTags: hookanalytics.Analytics{},
+									HookID:        HookID{ModuleCode: "foobar", HookImplCode: "foo"},
+									Status:        StatusExecutionFailure,
+									Action:        "",
+									Message:       "",
+									DebugMessages: nil,
+									Errors: []string{
+										fmt.Sprintf("Module (name: foobar, hook code: foo) tried to reject request on the %s stage that does not support rejection", hooks.StageExitpoint),
+									},
+									Warnings: nil,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			exec := NewHookExecutor(test.givenPlanBuilder, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+			headers := http.Header{"Content-Type": {"application/json"}}
+
+			newBody := exec.ExecuteExitpointStage(headers, body)
+
+			assert.Equal(t, test.expectedBody, newBody, "Incorrect response body.")
+			assert.Equal(t, test.expectedHeaders, headers, "Incorrect response headers.")
+			assert.Equal(t, test.expectedModuleContexts, exec.moduleContexts, "Incorrect module contexts")
+
+			stageOutcomes := exec.GetOutcomes()
+			if len(test.expectedStageOutcomes) == 0 {
+				assert.Empty(t, stageOutcomes, "Incorrect stage outcomes.")
+			} else {
+				assertEqualStageOutcomes(t, test.expectedStageOutcomes[0], stageOutcomes[0])
+			}
+		})
+	}
+}
+
+func TestExecuteUserSyncAndEventStages(t *testing.T) {
+	syncers := []usersync.SyncerChoice{{Bidder: "appnexus"}, {Bidder: "rubicon"}}
+	event := hookstage.EventPayload{Type: "win", BidID: "bid1"}
+
+	testCases := []struct {
+		description      string
+		givenPlanBuilder hooks.ExecutionPlanBuilder
+		expectedSyncers  []usersync.SyncerChoice
+		expectedUID      string
+		expectedEvent    hookstage.EventPayload
+		expectedReject   bool
+		expectedOutcomes int
+		expectedStatus   Status
+	}{
+		{
+			description:      "Payload not changed if hook execution plan empty",
+			givenPlanBuilder: hooks.EmptyPlanBuilder{},
+			expectedSyncers:  syncers,
+			expectedUID:      "uid",
+			expectedEvent:    event,
+		},
+		{
+			description:      "Payload changed if hooks return mutations",
+			givenPlanBuilder: TestApplyHookMutationsBuilder{},
+			expectedSyncers:  syncers[:1],
+			expectedUID:      "adnxs-uid",
+			expectedEvent:    hookstage.EventPayload{Type: "win", BidID: "bid1", Integration: "web"},
+			expectedOutcomes: 3,
+			expectedStatus:   StatusSuccess,
+		},
+		{
+			description:      "Stage execution can be rejected",
+			givenPlanBuilder: TestRejectPlanBuilder{},
+			expectedSyncers:  syncers,
+			expectedUID:      "uid",
+			expectedEvent:    event,
+			expectedReject:   true,
+			expectedOutcomes: 3,
+			expectedStatus:   StatusSuccess,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			exec := NewHookExecutor(test.givenPlanBuilder, EndpointCookieSync, &metricsConfig.NilMetricsEngine{})
+
+			newSyncers, rejectErr := exec.ExecuteCookieSyncStage(usersync.Request{}, syncers)
+			assert.Equal(t, test.expectedReject, rejectErr != nil, "Incorrect cookie sync rejection.")
+			assert.Equal(t, test.expectedSyncers, newSyncers, "Incorrect syncers.")
+
+			newUID, rejectErr := exec.ExecuteSetUIDStage("adnxs", "uid")
+			assert.Equal(t, test.expectedReject, rejectErr != nil, "Incorrect setuid rejection.")
+			assert.Equal(t, test.expectedUID, newUID, "Incorrect UID.")
+
+			newEvent, rejectErr := exec.ExecuteEventStage(event)
+			assert.Equal(t, test.expectedReject, rejectErr != nil, "Incorrect event rejection.")
+			assert.Equal(t, test.expectedEvent, newEvent, "Incorrect event.")
+
+			stageOutcomes := exec.GetOutcomes()
+			require.Len(t, stageOutcomes, test.expectedOutcomes, "Incorrect stage outcomes.")
+			for i, stage := range []hooks.Stage{hooks.StageCookieSync, hooks.StageSetUID, hooks.StageEvent}[:test.expectedOutcomes] {
+				assert.Equal(t, stage.String(), stageOutcomes[i].Stage)
+				assert.Equal(t, test.expectedStatus, stageOutcomes[i].Groups[0].InvocationResults[0].Status)
+			}
+		})
+	}
+}
+
+func TestGetSeatNonBid(t *testing.T) {
+	nonBidA := openrtb_ext.NonBid{ImpId: "imp1", StatusCode: 356}
+	nonBidB := openrtb_ext.NonBid{ImpId: "imp2", StatusCode: 356}
+
+	exec := NewHookExecutor(hooks.EmptyPlanBuilder{}, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+	exec.pushStageOutcome(StageOutcome{
+		Stage: hooks.StageRawBidderResponse.String(),
+		Groups: []GroupOutcome{
+			{InvocationResults: []HookOutcome{{SeatNonBid: []openrtb_ext.SeatNonBid{{Seat: "appnexus", NonBid: []openrtb_ext.NonBid{nonBidA}}}}}},
+			{InvocationResults: []HookOutcome{{}}},
+		},
+	})
+	exec.pushStageOutcome(StageOutcome{
+		Stage: hooks.StageRawBidderResponse.String(),
+		Groups: []GroupOutcome{
+			{InvocationResults: []HookOutcome{{SeatNonBid: []openrtb_ext.SeatNonBid{{Seat: "rubicon", NonBid: []openrtb_ext.NonBid{nonBidB}}}}}},
+		},
+	})
+
+	expected := []openrtb_ext.SeatNonBid{
+		{Seat: "appnexus", NonBid: []openrtb_ext.NonBid{nonBidA}},
+		{Seat: "rubicon", NonBid: []openrtb_ext.NonBid{nonBidB}},
+	}
+	assert.Equal(t, expected, exec.GetSeatNonBid())
+}
+
 func TestInterStageContextCommunication(t *testing.T) {
 	body := []byte(`{"foo": "bar"}`)
 	reader := bytes.NewReader(body)
@@ -2169,6 +2376,50 @@ func (e TestApplyHookMutationsBuilder) PlanForAuctionResponseStage(_ string, _ *
 	}
 }
 
+func (e TestApplyHookMutationsBuilder) PlanForExitpointStage(_ string, _ *config.Account) hooks.Plan[hookstage.Exitpoint] {
+	return hooks.Plan[hookstage.Exitpoint]{
+		hooks.Group[hookstage.Exitpoint]{
+			Timeout: 1 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.Exitpoint]{
+				{Module: "foobar", Code: "foo", Hook: mockUpdateExitpointHook{}},
+			},
+		},
+	}
+}
+
+func (e TestApplyHookMutationsBuilder) PlanForCookieSyncStage(_ string, _ *config.Account) hooks.Plan[hookstage.CookieSync] {
+	return hooks.Plan[hookstage.CookieSync]{
+		hooks.Group[hookstage.CookieSync]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.CookieSync]{
+				{Module: "foobar", Code: "foo", Hook: mockUpdateUserSyncAndEventHook{}},
+			},
+		},
+	}
+}
+
+func (e TestApplyHookMutationsBuilder) PlanForSetUIDStage(_ string, _ *config.Account) hooks.Plan[hookstage.SetUID] {
+	return hooks.Plan[hookstage.SetUID]{
+		hooks.Group[hookstage.SetUID]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.SetUID]{
+				{Module: "foobar", Code: "foo", Hook: mockUpdateUserSyncAndEventHook{}},
+			},
+		},
+	}
+}
+
+func (e TestApplyHookMutationsBuilder) PlanForEventStage(_ string, _ *config.Account) hooks.Plan[hookstage.Event] {
+	return hooks.Plan[hookstage.Event]{
+		hooks.Group[hookstage.Event]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.Event]{
+				{Module: "foobar", Code: "foo", Hook: mockUpdateUserSyncAndEventHook{}},
+			},
+		},
+	}
+}
+
 type TestRejectPlanBuilder struct {
 	hooks.EmptyPlanBuilder
 }
@@ -2333,6 +2584,51 @@ func (e TestRejectPlanBuilder) PlanForAuctionResponseStage(_ string, _ *config.A
 	}
 }
 
+func (e TestRejectPlanBuilder) PlanForExitpointStage(_ string, _ *config.Account) hooks.Plan[hookstage.Exitpoint] {
+	return hooks.Plan[hookstage.Exitpoint]{
+		// rejection ignored, stage doesn't support rejection
+		hooks.Group[hookstage.Exitpoint]{
+			Timeout: 1 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.Exitpoint]{
+				{Module: "foobar", Code: "foo", Hook: mockRejectHook{}},
+			},
+		},
+	}
+}
+
+func (e TestRejectPlanBuilder) PlanForCookieSyncStage(_ string, _ *config.Account) hooks.Plan[hookstage.CookieSync] {
+	return hooks.Plan[hookstage.CookieSync]{
+		hooks.Group[hookstage.CookieSync]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.CookieSync]{
+				{Module: "foobar", Code: "foo", Hook: mockRejectHook{}},
+			},
+		},
+	}
+}
+
+func (e TestRejectPlanBuilder) PlanForSetUIDStage(_ string, _ *config.Account) hooks.Plan[hookstage.SetUID] {
+	return hooks.Plan[hookstage.SetUID]{
+		hooks.Group[hookstage.SetUID]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.SetUID]{
+				{Module: "foobar", Code: "foo", Hook: mockRejectHook{}},
+			},
+		},
+	}
+}
+
+func (e TestRejectPlanBuilder) PlanForEventStage(_ string, _ *config.Account) hooks.Plan[hookstage.Event] {
+	return hooks.Plan[hookstage.Event]{
+		hooks.Group[hookstage.Event]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.Event]{
+				{Module: "foobar", Code: "foo", Hook: mockRejectHook{}},
+			},
+		},
+	}
+}
+
 type TestWithTimeoutPlanBuilder struct {
 	hooks.EmptyPlanBuilder
 }
@@ -2457,6 +2753,10 @@ func (e TestWithTimeoutPlanBuilder) PlanForAuctionResponseStage(_ string, _ *con
 	}
 }
 
+func (e TestWithTimeoutPlanBuilder) PlanForExitpointStage(_ string, _ *config.Account) hooks.Plan[hookstage.Exitpoint] {
+	return hooks.Plan[hookstage.Exitpoint]{}
+}
+
 type TestWithModuleContextsPlanBuilder struct {
 	hooks.EmptyPlanBuilder
 }
@@ -2585,6 +2885,10 @@ func (e TestWithModuleContextsPlanBuilder) PlanForAuctionResponseStage(_ string,
 	}
 }
 
+func (e TestWithModuleContextsPlanBuilder) PlanForExitpointStage(_ string, _ *config.Account) hooks.Plan[hookstage.Exitpoint] {
+	return hooks.Plan[hookstage.Exitpoint]{}
+}
+
 type TestAllHookResultsBuilder struct {
 	hooks.EmptyPlanBuilder
 }
@@ -2612,3 +2916,220 @@ func (e TestAllHookResultsBuilder) PlanForEntrypointStage(_ string) hooks.Plan[h
 		},
 	}
 }
+
+func TestModuleConfigResolution(t *testing.T) {
+	// the request can only override the limits
+	schemaDirectory := t.TempDir()
+	schema := `{"type": "object", "properties": {"limits": {"type": "object", "x-request-override": true}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(schemaDirectory, "acme.foo.json"), []byte(schema), 0644))
+
+	moduleConfigs, err := hooks.NewModuleConfigs(schemaDirectory, config.Modules{"acme": {"foo": map[string]interface{}{"enabled": true, "mode": "block", "limits": map[string]interface{}{"min": 1, "max": 10}}}})
+	require.NoError(t, err)
+
+	hook := &mockModuleConfigHook{}
+	exec := NewHookExecutor(TestWithModuleConfigPlanBuilder{hook: hook, configs: moduleConfigs}, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+
+	// account known before the raw auction stage, request config read from the raw request
+	exec.SetAccount(&config.Account{ID: "some-account", Hooks: config.AccountHooks{Modules: config.AccountModules{"acme": {"foo": json.RawMessage(`{"mode":"report","limits":{"max":5}}`)}}}})
+	_, reject := exec.ExecuteRawAuctionStage([]byte(`{"ext":{"prebid":{"modules":{"acme":{"foo":{"limits":{"max":3}}}}}}}`))
+	require.Nil(t, reject)
+
+	// request config updated by the processed request
+	req := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Ext: json.RawMessage(`{"prebid":{"modules":{"acme":{"foo":{"limits":{"min":null}}}}}}`)}}
+	require.NoError(t, exec.ExecuteProcessedAuctionStage(req))
+
+	// a request config switching the module off is ignored
+	req = &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Ext: json.RawMessage(`{"prebid":{"modules":{"acme":{"foo":{"enabled":false}}}}}`)}}
+	require.NoError(t, exec.ExecuteProcessedAuctionStage(req))
+
+	require.Len(t, hook.configs, 3)
+	assert.JSONEq(t, `{"enabled":true,"mode":"report","limits":{"min":1,"max":3}}`, string(hook.configs[0]), "raw auction stage config")
+	assert.JSONEq(t, `{"enabled":true,"mode":"report","limits":{"max":5}}`, string(hook.configs[1]), "processed auction stage config")
+	assert.JSONEq(t, `{"enabled":true,"mode":"report","limits":{"min":1,"max":5}}`, string(hook.configs[2]), "request switching the module off")
+
+	// the host and account config never includes the request config
+	for _, cfg := range hook.hostAccountConfigs {
+		assert.JSONEq(t, `{"enabled":true,"mode":"report","limits":{"min":1,"max":5}}`, string(cfg))
+	}
+}
+
+type TestWithModuleConfigPlanBuilder struct {
+	hooks.EmptyPlanBuilder
+	hook    *mockModuleConfigHook
+	configs *hooks.ModuleConfigs
+}
+
+func (e TestWithModuleConfigPlanBuilder) PlanForRawAuctionStage(_ string, _ *config.Account) hooks.Plan[hookstage.RawAuctionRequest] {
+	return hooks.Plan[hookstage.RawAuctionRequest]{
+		hooks.Group[hookstage.RawAuctionRequest]{
+			Timeout: 10 * time.Millisecond,
+			Hooks:   []hooks.HookWrapper[hookstage.RawAuctionRequest]{{Module: "acme.foo", Code: "foo", Hook: e.hook}},
+		},
+	}
+}
+
+func (e TestWithModuleConfigPlanBuilder) PlanForProcessedAuctionStage(_ string, _ *config.Account) hooks.Plan[hookstage.ProcessedAuctionRequest] {
+	return hooks.Plan[hookstage.ProcessedAuctionRequest]{
+		hooks.Group[hookstage.ProcessedAuctionRequest]{
+			Timeout: 10 * time.Millisecond,
+			Hooks:   []hooks.HookWrapper[hookstage.ProcessedAuctionRequest]{{Module: "acme.foo", Code: "foo", Hook: e.hook}},
+		},
+	}
+}
+
+func (e TestWithModuleConfigPlanBuilder) ModuleConfig(module string, account, request json.RawMessage) (json.RawMessage, []error) {
+	return e.configs.Resolve(module, account, request)
+}
+
+func TestExecuteStageSkipsHooksWithUnmetConditions(t *testing.T) {
+	exec := NewHookExecutor(TestWithConditionsPlanBuilder{}, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+	exec.SetAccount(&config.Account{ID: "1001"})
+
+	req := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{App: &openrtb2.App{}, Imp: []openrtb2.Imp{{ID: "imp1", Banner: &openrtb2.Banner{}}}}}
+	require.NoError(t, exec.ExecuteProcessedAuctionStage(req))
+	require.Nil(t, exec.ExecuteBidderRequestStage(req, "appnexus"))
+
+	outcomes := exec.GetOutcomes()
+	require.Len(t, outcomes, 2)
+
+	processedResults := outcomes[0].Groups[0].InvocationResults
+	require.Len(t, processedResults, 2)
+	assert.Equal(t, HookID{ModuleCode: "module-1", HookImplCode: "app-banner"}, processedResults[0].HookID)
+	assert.Equal(t, StatusSuccess, processedResults[0].Status)
+	assert.Equal(t, HookOutcome{
+		HookID:  HookID{ModuleCode: "module-2", HookImplCode: "web-only"},
+		Status:  StatusSkipped,
+		Action:  ActionNone,
+		Message: "condition not met: channels",
+	}, processedResults[1])
+
+	bidderResults := outcomes[1].Groups[0].InvocationResults
+	require.Len(t, bidderResults, 2)
+	assert.Equal(t, HookID{ModuleCode: "module-1", HookImplCode: "appnexus-account"}, bidderResults[0].HookID)
+	assert.Equal(t, StatusSuccess, bidderResults[0].Status)
+	assert.Equal(t, HookOutcome{
+		HookID:  HookID{ModuleCode: "module-2", HookImplCode: "rubicon-only"},
+		Status:  StatusSkipped,
+		Action:  ActionNone,
+		Message: "condition not met: bidders",
+	}, bidderResults[1])
+}
+
+type TestWithConditionsPlanBuilder struct {
+	hooks.EmptyPlanBuilder
+}
+
+func (e TestWithConditionsPlanBuilder) PlanForProcessedAuctionStage(_ string, _ *config.Account) hooks.Plan[hookstage.ProcessedAuctionRequest] {
+	return hooks.Plan[hookstage.ProcessedAuctionRequest]{
+		hooks.Group[hookstage.ProcessedAuctionRequest]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.ProcessedAuctionRequest]{
+				{
+					Module:     "module-1",
+					Code:       "app-banner",
+					Hook:       mockModuleContextHook{key: "processed-auction-ctx", val: "some-ctx"},
+					Conditions: &config.HookConditions{Channels: []config.ChannelType{config.ChannelApp}, MediaTypes: []string{"banner"}},
+				},
+				{
+					Module:     "module-2",
+					Code:       "web-only",
+					Hook:       mockModuleContextHook{key: "processed-auction-ctx", val: "some-ctx"},
+					Conditions: &config.HookConditions{Channels: []config.ChannelType{config.ChannelWeb}},
+				},
+			},
+		},
+	}
+}
+
+func (e TestWithConditionsPlanBuilder) PlanForBidderRequestStage(_ string, _ *config.Account) hooks.Plan[hookstage.BidderRequest] {
+	return hooks.Plan[hookstage.BidderRequest]{
+		hooks.Group[hookstage.BidderRequest]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.BidderRequest]{
+				{
+					Module:     "module-1",
+					Code:       "appnexus-account",
+					Hook:       mockModuleContextHook{key: "bidder-request-ctx", val: "some-ctx"},
+					Conditions: &config.HookConditions{Bidders: []string{"appnexus"}, Accounts: []string{"1001"}},
+				},
+				{
+					Module:     "module-2",
+					Code:       "rubicon-only",
+					Hook:       mockModuleContextHook{key: "bidder-request-ctx", val: "some-ctx"},
+					Conditions: &config.HookConditions{Bidders: []string{"rubicon"}},
+				},
+			},
+		},
+	}
+}
+
+func TestExecuteStageConditionsSeeRequestChanges(t *testing.T) {
+	exec := NewHookExecutor(TestWithRequestChangesPlanBuilder{}, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+	exec.SetAccount(&config.Account{ID: "1001"})
+
+	req := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
+		Site:   &openrtb2.Site{},
+		Device: &openrtb2.Device{},
+		Imp:    []openrtb2.Imp{{ID: "imp1", Banner: &openrtb2.Banner{}}, {ID: "imp2", Video: &openrtb2.Video{}}},
+	}}
+	require.NoError(t, exec.ExecuteProcessedAuctionStage(req))
+	assert.Equal(t, "DEU", exec.request.country, "the attributes must be refreshed with the changes of the stage")
+
+	bidderReq := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
+		Site:   &openrtb2.Site{},
+		Device: req.Device,
+		Imp:    []openrtb2.Imp{{ID: "imp2", Video: &openrtb2.Video{}}},
+	}}
+	require.Nil(t, exec.ExecuteBidderRequestStage(bidderReq, "appnexus"))
+
+	outcomes := exec.GetOutcomes()
+	require.Len(t, outcomes, 2)
+
+	bidderResults := outcomes[1].Groups[0].InvocationResults
+	require.Len(t, bidderResults, 2)
+	assert.Equal(t, HookID{ModuleCode: "module-1", HookImplCode: "country"}, bidderResults[0].HookID)
+	assert.Equal(t, StatusSuccess, bidderResults[0].Status)
+	assert.Equal(t, HookOutcome{
+		HookID:  HookID{ModuleCode: "module-2", HookImplCode: "banner"},
+		Status:  StatusSkipped,
+		Action:  ActionNone,
+		Message: "condition not met: media_types",
+	}, bidderResults[1], "the bidder request only holds a video imp")
+}
+
+type TestWithRequestChangesPlanBuilder struct {
+	hooks.EmptyPlanBuilder
+}
+
+func (e TestWithRequestChangesPlanBuilder) PlanForProcessedAuctionStage(_ string, _ *config.Account) hooks.Plan[hookstage.ProcessedAuctionRequest] {
+	return hooks.Plan[hookstage.ProcessedAuctionRequest]{
+		hooks.Group[hookstage.ProcessedAuctionRequest]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.ProcessedAuctionRequest]{
+				{Module: "module-1", Code: "set-country", Hook: mockSetCountryHook{country: "DEU"}},
+			},
+		},
+	}
+}
+
+func (e TestWithRequestChangesPlanBuilder) PlanForBidderRequestStage(_ string, _ *config.Account) hooks.Plan[hookstage.BidderRequest] {
+	return hooks.Plan[hookstage.BidderRequest]{
+		hooks.Group[hookstage.BidderRequest]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.BidderRequest]{
+				{
+					Module:     "module-1",
+					Code:       "country",
+					Hook:       mockModuleContextHook{key: "bidder-request-ctx", val: "some-ctx"},
+					Conditions: &config.HookConditions{Countries: []string{"DEU"}},
+				},
+				{
+					Module:     "module-2",
+					Code:       "banner",
+					Hook:       mockModuleContextHook{key: "bidder-request-ctx", val: "some-ctx"},
+					Conditions: &config.HookConditions{MediaTypes: []string{"banner"}},
+				},
+			},
+		},
+	}
+}
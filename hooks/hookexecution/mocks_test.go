@@ -2,9 +2,12 @@ package hookexecution
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"sync"
 	"time"
 
+	"github.com/prebid/openrtb/v20/openrtb2"
 	"github.com/prebid/prebid-server/v3/hooks/hookstage"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/util/ptrutil"
@@ -100,6 +103,22 @@ func (e mockRejectHook) HandleAuctionResponseHook(_ context.Context, _ hookstage
 	return hookstage.HookResult[hookstage.AuctionResponsePayload]{Reject: true}, nil
 }
 
+func (e mockRejectHook) HandleExitpointHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.ExitpointPayload) (hookstage.HookResult[hookstage.ExitpointPayload], error) {
+	return hookstage.HookResult[hookstage.ExitpointPayload]{Reject: true}, nil
+}
+
+func (e mockRejectHook) HandleCookieSyncHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.CookieSyncPayload) (hookstage.HookResult[hookstage.CookieSyncPayload], error) {
+	return hookstage.HookResult[hookstage.CookieSyncPayload]{Reject: true}, nil
+}
+
+func (e mockRejectHook) HandleSetUIDHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.SetUIDPayload) (hookstage.HookResult[hookstage.SetUIDPayload], error) {
+	return hookstage.HookResult[hookstage.SetUIDPayload]{Reject: true}, nil
+}
+
+func (e mockRejectHook) HandleEventHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.EventPayload) (hookstage.HookResult[hookstage.EventPayload], error) {
+	return hookstage.HookResult[hookstage.EventPayload]{Reject: true}, nil
+}
+
 type mockTimeoutHook struct{}
 
 func (e mockTimeoutHook) HandleEntrypointHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.EntrypointPayload) (hookstage.HookResult[hookstage.EntrypointPayload], error) {
@@ -385,3 +404,98 @@ func (e mockUpdateBidResponseHook) HandleAuctionResponseHook(_ context.Context,
 
 	return hookstage.HookResult[hookstage.AuctionResponsePayload]{ChangeSet: c}, nil
 }
+
+type mockUpdateExitpointHook struct{}
+
+func (e mockUpdateExitpointHook) HandleExitpointHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.ExitpointPayload) (hookstage.HookResult[hookstage.ExitpointPayload], error) {
+	c := hookstage.ChangeSet[hookstage.ExitpointPayload]{}
+	c.AddMutation(
+		func(payload hookstage.ExitpointPayload) (hookstage.ExitpointPayload, error) {
+			payload.Headers.Set("Server-Timing", "auction;dur=10")
+			return payload, nil
+		}, hookstage.MutationUpdate, "header", "server-timing",
+	).AddMutation(
+		func(payload hookstage.ExitpointPayload) (hookstage.ExitpointPayload, error) {
+			payload.Body = []byte(`<VAST version="4.0"></VAST>`)
+			return payload, nil
+		}, hookstage.MutationUpdate, "body",
+	)
+
+	return hookstage.HookResult[hookstage.ExitpointPayload]{ChangeSet: c}, nil
+}
+
+type mockUpdateUserSyncAndEventHook struct{}
+
+func (e mockUpdateUserSyncAndEventHook) HandleCookieSyncHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.CookieSyncPayload) (hookstage.HookResult[hookstage.CookieSyncPayload], error) {
+	c := hookstage.ChangeSet[hookstage.CookieSyncPayload]{}
+	c.AddMutation(
+		func(payload hookstage.CookieSyncPayload) (hookstage.CookieSyncPayload, error) {
+			payload.Syncers = payload.Syncers[:1]
+			return payload, nil
+		}, hookstage.MutationDelete, "syncers",
+	)
+
+	return hookstage.HookResult[hookstage.CookieSyncPayload]{ChangeSet: c}, nil
+}
+
+func (e mockUpdateUserSyncAndEventHook) HandleSetUIDHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.SetUIDPayload) (hookstage.HookResult[hookstage.SetUIDPayload], error) {
+	c := hookstage.ChangeSet[hookstage.SetUIDPayload]{}
+	c.AddMutation(
+		func(payload hookstage.SetUIDPayload) (hookstage.SetUIDPayload, error) {
+			payload.UID = payload.Syncer + "-" + payload.UID
+			return payload, nil
+		}, hookstage.MutationUpdate, "uid",
+	)
+
+	return hookstage.HookResult[hookstage.SetUIDPayload]{ChangeSet: c}, nil
+}
+
+func (e mockUpdateUserSyncAndEventHook) HandleEventHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.EventPayload) (hookstage.HookResult[hookstage.EventPayload], error) {
+	c := hookstage.ChangeSet[hookstage.EventPayload]{}
+	c.AddMutation(
+		func(payload hookstage.EventPayload) (hookstage.EventPayload, error) {
+			payload.Integration = "web"
+			return payload, nil
+		}, hookstage.MutationUpdate, "integration",
+	)
+
+	return hookstage.HookResult[hookstage.EventPayload]{ChangeSet: c}, nil
+}
+
+// mockModuleConfigHook records the effective and the host and account module configs passed to the hook at every stage
+type mockModuleConfigHook struct {
+	sync.Mutex
+	configs            []json.RawMessage
+	hostAccountConfigs []json.RawMessage
+}
+
+func (e *mockModuleConfigHook) HandleRawAuctionHook(_ context.Context, miCtx hookstage.ModuleInvocationContext, _ hookstage.RawAuctionRequestPayload) (hookstage.HookResult[hookstage.RawAuctionRequestPayload], error) {
+	e.record(miCtx)
+	return hookstage.HookResult[hookstage.RawAuctionRequestPayload]{}, nil
+}
+
+func (e *mockModuleConfigHook) HandleProcessedAuctionHook(_ context.Context, miCtx hookstage.ModuleInvocationContext, _ hookstage.ProcessedAuctionRequestPayload) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	e.record(miCtx)
+	return hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{}, nil
+}
+
+func (e *mockModuleConfigHook) record(miCtx hookstage.ModuleInvocationContext) {
+	e.Lock()
+	defer e.Unlock()
+	e.configs = append(e.configs, miCtx.Config)
+	e.hostAccountConfigs = append(e.hostAccountConfigs, miCtx.HostAccountConfig)
+}
+
+type mockSetCountryHook struct {
+	country string
+}
+
+func (h mockSetCountryHook) HandleProcessedAuctionHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.ProcessedAuctionRequestPayload) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	c := hookstage.ChangeSet[hookstage.ProcessedAuctionRequestPayload]{}
+	c.AddMutation(func(payload hookstage.ProcessedAuctionRequestPayload) (hookstage.ProcessedAuctionRequestPayload, error) {
+		payload.Request.Device.Geo = &openrtb2.Geo{Country: h.country}
+		return payload, nil
+	}, hookstage.MutationUpdate, "bidRequest", "device.geo.country")
+
+	return hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{ChangeSet: c}, nil
+}
@@ -0,0 +1,31 @@
+package hookstage
+
+import (
+	"context"
+
+	"github.com/prebid/prebid-server/v3/usersync"
+)
+
+// CookieSync hooks are invoked by the "/cookie_sync" endpoint
+// after the syncers are chosen and before the response is written.
+//
+// At this stage, account config is available,
+// so it can be configured at the account-level execution plan,
+// the account-level module config is passed to hooks.
+//
+// Rejection results in a response without syncs.
+type CookieSync interface {
+	HandleCookieSyncHook(
+		context.Context,
+		ModuleInvocationContext,
+		CookieSyncPayload,
+	) (HookResult[CookieSyncPayload], error)
+}
+
+// CookieSyncPayload consists of the parsed cookie sync request and the syncers chosen for it.
+// Hooks are allowed to modify the list of syncers, e.g. to filter out some of them.
+// The request is informational, changes to it are ignored.
+type CookieSyncPayload struct {
+	Request usersync.Request
+	Syncers []usersync.SyncerChoice
+}
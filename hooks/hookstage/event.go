@@ -0,0 +1,33 @@
+package hookstage
+
+import (
+	"context"
+)
+
+// Event hooks are invoked by the "/event" endpoint
+// after the notification event is parsed and before it is sent to analytics modules.
+//
+// At this stage, account config is available,
+// so it can be configured at the account-level execution plan,
+// the account-level module config is passed to hooks.
+//
+// Rejection results in the event not being sent to analytics modules.
+// The response to the client is not affected.
+type Event interface {
+	HandleEventHook(
+		context.Context,
+		ModuleInvocationContext,
+		EventPayload,
+	) (HookResult[EventPayload], error)
+}
+
+// EventPayload consists of the notification event parameters.
+// Hooks are allowed to modify them, e.g. to enrich or normalize the event.
+type EventPayload struct {
+	Type        string
+	VType       string
+	BidID       string
+	Bidder      string
+	Timestamp   int64
+	Integration string
+}
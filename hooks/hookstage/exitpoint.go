@@ -0,0 +1,30 @@
+package hookstage
+
+import (
+	"context"
+	"net/http"
+)
+
+// Exitpoint hooks are invoked at the very end of request processing,
+// after the response is encoded and before it is written to the client.
+//
+// At this stage, account config is available,
+// so it can be configured at the account-level execution plan,
+// the account-level module config is passed to hooks.
+//
+// Rejection is not supported at this stage.
+type Exitpoint interface {
+	HandleExitpointHook(
+		context.Context,
+		ModuleInvocationContext,
+		ExitpointPayload,
+	) (HookResult[ExitpointPayload], error)
+}
+
+// ExitpointPayload consists of the HTTP response headers and the encoded response body.
+// Hooks are allowed to modify this data using mutations, e.g. to convert
+// the response to another format or to add headers such as Server-Timing.
+type ExitpointPayload struct {
+	Headers http.Header
+	Body    []byte
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
 )
 
 // HookResult represents the result of execution the concrete hook instance.
@@ -16,13 +17,22 @@ type HookResult[T any] struct {
 	Warnings      []string
 	DebugMessages []string
 	AnalyticsTags hookanalytics.Analytics
-	ModuleContext ModuleContext // holds values that the module wants to pass to itself at later stages
+	ModuleContext ModuleContext            // holds values that the module wants to pass to itself at later stages
+	SeatNonBid    []openrtb_ext.SeatNonBid // holds bids rejected by the hook, reported in bidresponse.ext.prebid.seatnonbid
 }
 
 // ModuleInvocationContext holds data passed to the module hook during invocation.
 type ModuleInvocationContext struct {
 	// AccountConfig represents module config rewritten at the account-level.
 	AccountConfig json.RawMessage
+	// Config represents the effective module config: the host-level config deep-merged with
+	// the account-level config and the request-level ext.prebid.modules config, in that order.
+	// Overrides making the config invalid against the module schema are left out, as well as
+	// the request-level config setting fields the module schema doesn't let the request override.
+	Config json.RawMessage
+	// HostAccountConfig represents the host-level config deep-merged with the account-level config, without the
+	// request-level config. Modules read the settings the host and the publishers enforce from it.
+	HostAccountConfig json.RawMessage
 	// Endpoint represents the path of the current endpoint.
 	Endpoint string
 	// ModuleContext holds values that the module passes to itself from the previous stages.
@@ -0,0 +1,29 @@
+package hookstage
+
+import (
+	"context"
+)
+
+// SetUID hooks are invoked by the "/setuid" endpoint
+// after the privacy checks and before the UID is saved to the cookie.
+//
+// At this stage, account config is available,
+// so it can be configured at the account-level execution plan,
+// the account-level module config is passed to hooks.
+//
+// Rejection results in the cookie being left unchanged.
+type SetUID interface {
+	HandleSetUIDHook(
+		context.Context,
+		ModuleInvocationContext,
+		SetUIDPayload,
+	) (HookResult[SetUIDPayload], error)
+}
+
+// SetUIDPayload consists of the syncer key and the UID received for it.
+// Hooks are allowed to modify the UID, e.g. to decode or normalize it.
+// An empty UID removes the UID of the syncer from the cookie.
+type SetUIDPayload struct {
+	Syncer string
+	UID    string
+}
@@ -0,0 +1,257 @@
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+	"github.com/xeipuuv/gojsonschema"
+	jsonpatch "gopkg.in/evanphx/json-patch.v5"
+)
+
+// ModuleConfigs resolves the effective configuration of modules and validates it against
+// the JSON schemas shipped by the modules.
+//
+// A module ships its schema as "<vendor>.<module_name>.json" in the schema directory.
+// The configuration of modules without a schema isn't validated.
+//
+// The effective configuration is built from three layers, each one deep-merged on top of the previous one
+// following the JSON Merge Patch rules (RFC 7396): objects are merged recursively, other values
+// replace the previous ones and a null value removes the key.
+//   - the host-level config: hooks.modules.<vendor>.<module_name>
+//   - the account-level config: hooks.modules.<vendor>.<module_name> of the account
+//   - the request-level config: ext.prebid.modules.<vendor>.<module_name> of the bid request
+//
+// The request-level config is only merged if it sets the top-level fields the module schema annotates with
+// "x-request-override": true, as any caller can send it. Modules without a schema can't be overridden by the request.
+type ModuleConfigs struct {
+	schemas        map[string]*gojsonschema.Schema
+	schemaContents map[string]json.RawMessage
+	// requestOverrides holds the top-level fields of each module config the request can override
+	requestOverrides map[string][]string
+	host             atomic.Pointer[map[string]json.RawMessage]
+}
+
+// requestOverrideKeyword annotates the schema properties the request-level config can override.
+const requestOverrideKeyword = "x-request-override"
+
+// enforcementFields are the fields the host and the publishers rely on to control what modules do, such as
+// switching a module on or enforcing its rules. A module schema can't let the request override them.
+var enforcementFields = []string{"enabled", "report_only", "rules"}
+
+// moduleSchemaAnnotations holds the annotations read from a module schema.
+type moduleSchemaAnnotations struct {
+	Properties map[string]struct {
+		RequestOverride bool `json:"x-request-override"`
+	} `json:"properties"`
+}
+
+// NewModuleConfigs loads the module schemas from the directory and validates the host-level config against them.
+func NewModuleConfigs(schemaDirectory string, host config.Modules) (*ModuleConfigs, error) {
+	files, err := os.ReadDir(schemaDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module schemas from directory %s: %s", schemaDirectory, err)
+	}
+
+	configs := &ModuleConfigs{
+		schemas:          make(map[string]*gojsonschema.Schema, len(files)),
+		schemaContents:   make(map[string]json.RawMessage, len(files)),
+		requestOverrides: make(map[string][]string, len(files)),
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		module := strings.TrimSuffix(file.Name(), ".json")
+		if vendor, name, ok := strings.Cut(module, "."); !ok || vendor == "" || name == "" {
+			return nil, fmt.Errorf("module schema file %s must be named <vendor>.<module_name>.json", file.Name())
+		}
+
+		contents, err := os.ReadFile(filepath.Join(schemaDirectory, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read module schema %s: %s", file.Name(), err)
+		}
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(contents))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load module schema %s: %s", file.Name(), err)
+		}
+
+		overrides, err := readRequestOverrides(contents)
+		if err != nil {
+			return nil, fmt.Errorf("invalid module schema %s: %s", file.Name(), err)
+		}
+
+		configs.schemas[module] = schema
+		configs.schemaContents[module] = contents
+		configs.requestOverrides[module] = overrides
+	}
+
+	if err := configs.SetHostConfig(host); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// SetHostConfig validates and replaces the host-level config, e.g. when modules are reloaded.
+// The previous config is kept if any module config is invalid.
+func (c *ModuleConfigs) SetHostConfig(host config.Modules) error {
+	hostConfigs := make(map[string]json.RawMessage)
+	var errs []error
+	for vendor, modules := range host {
+		for name, data := range modules {
+			module := vendor + "." + name
+			cfg, err := jsonutil.Marshal(data)
+			if err != nil {
+				errs = append(errs, fmt.Errorf(`failed to marshal "%s" module config: %s`, module, err))
+				continue
+			}
+			if err := c.Validate(module, cfg); err != nil {
+				errs = append(errs, fmt.Errorf(`invalid "%s" module config: %s`, module, err))
+				continue
+			}
+			hostConfigs[module] = cfg
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	c.host.Store(&hostConfigs)
+	return nil
+}
+
+// readRequestOverrides returns the top-level properties of the schema annotated with "x-request-override": true.
+func readRequestOverrides(schema json.RawMessage) ([]string, error) {
+	var annotations moduleSchemaAnnotations
+	if err := jsonutil.Unmarshal(schema, &annotations); err != nil {
+		return nil, err
+	}
+
+	var overrides []string
+	for field, property := range annotations.Properties {
+		if !property.RequestOverride {
+			continue
+		}
+		if slices.Contains(enforcementFields, field) {
+			return nil, fmt.Errorf("%s can't be overridden by the request, remove %s", field, requestOverrideKeyword)
+		}
+		overrides = append(overrides, field)
+	}
+	return overrides, nil
+}
+
+// Resolve returns the effective config of the module in the format "vendor.module_name".
+// A nil ModuleConfigs only merges the account-level config.
+//
+// Every layer is validated once merged. An account-level or request-level config making the module config invalid,
+// or a request-level config setting fields the module doesn't let the request override, is ignored and the error
+// is returned along with the config resolved from the remaining layers.
+func (c *ModuleConfigs) Resolve(module string, account, request json.RawMessage) (json.RawMessage, []error) {
+	var cfg json.RawMessage
+	if c != nil {
+		if host := c.host.Load(); host != nil {
+			cfg = (*host)[module]
+		}
+	}
+
+	var errs []error
+	if len(account) > 0 {
+		merged, err := c.merge(module, cfg, account)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(`account config of "%s" module ignored: %s`, module, err))
+		} else {
+			cfg = merged
+		}
+	}
+	if len(request) > 0 {
+		merged, err := c.mergeRequest(module, cfg, request)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(`request config of "%s" module ignored: %s`, module, err))
+		} else {
+			cfg = merged
+		}
+	}
+
+	return cfg, errs
+}
+
+// mergeRequest merges the request-level config if it only sets the fields the module lets the request override.
+func (c *ModuleConfigs) mergeRequest(module string, cfg, request json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := jsonutil.Unmarshal(request, &fields); err != nil {
+		return nil, fmt.Errorf("request config must be an object: %s", err)
+	}
+
+	var allowed []string
+	if c != nil {
+		allowed = c.requestOverrides[module]
+	}
+	var denied []string
+	for field := range fields {
+		if !slices.Contains(allowed, field) {
+			denied = append(denied, field)
+		}
+	}
+	if len(denied) > 0 {
+		slices.Sort(denied)
+		return nil, fmt.Errorf("the request can't override %s", strings.Join(denied, ", "))
+	}
+
+	return c.merge(module, cfg, request)
+}
+
+func (c *ModuleConfigs) merge(module string, cfg, override json.RawMessage) (json.RawMessage, error) {
+	if len(cfg) == 0 {
+		cfg = json.RawMessage(`{}`)
+	}
+
+	merged, err := jsonpatch.MergePatch(cfg, override)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Validate(module, merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// Validate validates the module config against the module schema, if the module ships one.
+func (c *ModuleConfigs) Validate(module string, cfg json.RawMessage) error {
+	if c == nil {
+		return nil
+	}
+
+	schema, ok := c.schemas[module]
+	if !ok {
+		return nil
+	}
+
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(cfg))
+	if err != nil {
+		return err
+	}
+	if !result.Valid() {
+		messages := make([]string, 0, len(result.Errors()))
+		for _, resultErr := range result.Errors() {
+			messages = append(messages, resultErr.String())
+		}
+		return errors.New(strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// Schema returns the JSON schema of the module config, or nil if the module doesn't ship one.
+func (c *ModuleConfigs) Schema(module string) json.RawMessage {
+	if c == nil {
+		return nil
+	}
+	return c.schemaContents[module]
+}
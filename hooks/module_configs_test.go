@@ -0,0 +1,246 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testModuleSchema = `{
+  "type": "object",
+  "properties": {
+    "enabled": {"type": "boolean"},
+    "mode": {"type": "string", "enum": ["block", "report"]},
+    "limits": {
+      "type": "object",
+      "x-request-override": true,
+      "properties": {
+        "max": {"type": "integer"},
+        "min": {"type": "integer"}
+      },
+      "additionalProperties": false
+    }
+  },
+  "additionalProperties": false
+}`
+
+func writeModuleSchemas(t *testing.T, schemas map[string]string) string {
+	dir := t.TempDir()
+	for name, schema := range schemas {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(schema), 0644))
+	}
+	return dir
+}
+
+func TestNewModuleConfigs(t *testing.T) {
+	testCases := []struct {
+		description string
+		givenFiles  map[string]string
+		givenHost   config.Modules
+		expectedErr string
+	}{
+		{
+			description: "valid-host-config",
+			givenFiles:  map[string]string{"acme.foo.json": testModuleSchema, "README.md": "not a schema"},
+			givenHost:   config.Modules{"acme": {"foo": map[string]interface{}{"enabled": true, "mode": "block"}, "bar": map[string]interface{}{"any": 1}}},
+		},
+		{
+			description: "invalid-host-config",
+			givenFiles:  map[string]string{"acme.foo.json": testModuleSchema},
+			givenHost:   config.Modules{"acme": {"foo": map[string]interface{}{"enabled": true, "mdoe": "block"}}},
+			expectedErr: `invalid "acme.foo" module config: (root): Additional property mdoe is not allowed`,
+		},
+		{
+			description: "invalid-schema-file-name",
+			givenFiles:  map[string]string{"foo.json": testModuleSchema},
+			expectedErr: "module schema file foo.json must be named <vendor>.<module_name>.json",
+		},
+		{
+			description: "invalid-schema",
+			givenFiles:  map[string]string{"acme.foo.json": `{"type": 1}`},
+			expectedErr: "failed to load module schema acme.foo.json",
+		},
+		{
+			description: "enforcement-field-overridable",
+			givenFiles:  map[string]string{"acme.foo.json": `{"type": "object", "properties": {"enabled": {"type": "boolean", "x-request-override": true}}}`},
+			expectedErr: "invalid module schema acme.foo.json: enabled can't be overridden by the request, remove x-request-override",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			_, err := NewModuleConfigs(writeModuleSchemas(t, test.givenFiles), test.givenHost)
+			if test.expectedErr != "" {
+				assert.ErrorContains(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewModuleConfigsMissingDirectory(t *testing.T) {
+	_, err := NewModuleConfigs("/does/not/exist", nil)
+	assert.ErrorContains(t, err, "failed to read module schemas from directory /does/not/exist")
+}
+
+func TestShippedModuleSchemas(t *testing.T) {
+	configs, err := NewModuleConfigs("../static/module-schemas", nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, configs.schemas)
+
+	// only the fields which don't control the modules can be overridden by the request
+	assert.Equal(t, []string{"overwrite"}, configs.requestOverrides["prebid.geolocation"])
+	assert.Empty(t, configs.requestOverrides["prebid.creativesafety"])
+	assert.Empty(t, configs.requestOverrides["prebid.rulesengine"])
+
+	// the creative safety signatures default their id to the pattern
+	assert.NoError(t, configs.Validate("prebid.creativesafety", json.RawMessage(`{"signatures":{"js_patterns":[{"pattern":"eval(atob("}]}}`)))
+	assert.Error(t, configs.Validate("prebid.creativesafety", json.RawMessage(`{"signatures":{"js_patterns":[{"id":"eval-atob"}]}}`)))
+}
+
+func TestModuleConfigsResolve(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenModule    string
+		givenAccount   json.RawMessage
+		givenRequest   json.RawMessage
+		expectedConfig json.RawMessage
+		expectedErrs   []string
+	}{
+		{
+			description:    "host-only",
+			givenModule:    "acme.foo",
+			expectedConfig: json.RawMessage(`{"enabled":true,"limits":{"max":10,"min":1},"mode":"block"}`),
+		},
+		{
+			description:    "account-deep-merged",
+			givenModule:    "acme.foo",
+			givenAccount:   json.RawMessage(`{"limits":{"max":5}}`),
+			expectedConfig: json.RawMessage(`{"enabled":true,"limits":{"max":5,"min":1},"mode":"block"}`),
+		},
+		{
+			description:    "request-merged-after-account",
+			givenModule:    "acme.foo",
+			givenAccount:   json.RawMessage(`{"limits":{"max":5},"mode":"report"}`),
+			givenRequest:   json.RawMessage(`{"limits":{"max":3}}`),
+			expectedConfig: json.RawMessage(`{"enabled":true,"limits":{"max":3,"min":1},"mode":"report"}`),
+		},
+		{
+			description:    "null-removes-key",
+			givenModule:    "acme.foo",
+			givenAccount:   json.RawMessage(`{"limits":null}`),
+			expectedConfig: json.RawMessage(`{"enabled":true,"mode":"block"}`),
+		},
+		{
+			description:    "invalid-account-ignored",
+			givenModule:    "acme.foo",
+			givenAccount:   json.RawMessage(`{"mode":"drop"}`),
+			givenRequest:   json.RawMessage(`{"limits":{"max":3}}`),
+			expectedConfig: json.RawMessage(`{"enabled":true,"limits":{"max":3,"min":1},"mode":"block"}`),
+			expectedErrs:   []string{`account config of "acme.foo" module ignored: mode: mode must be one of the following: "block", "report"`},
+		},
+		{
+			description:    "invalid-request-ignored",
+			givenModule:    "acme.foo",
+			givenAccount:   json.RawMessage(`{"mode":"report"}`),
+			givenRequest:   json.RawMessage(`{"limits":{"max":"3"}}`),
+			expectedConfig: json.RawMessage(`{"enabled":true,"limits":{"max":10,"min":1},"mode":"report"}`),
+			expectedErrs:   []string{`request config of "acme.foo" module ignored: limits.max: Invalid type. Expected: integer, given: string`},
+		},
+		{
+			description:    "malformed-account-ignored",
+			givenModule:    "acme.foo",
+			givenAccount:   json.RawMessage(`{`),
+			expectedConfig: json.RawMessage(`{"enabled":true,"limits":{"max":10,"min":1},"mode":"block"}`),
+			expectedErrs:   []string{`account config of "acme.foo" module ignored: Invalid JSON Patch`},
+		},
+		{
+			description:    "module-without-schema",
+			givenModule:    "acme.bar",
+			givenAccount:   json.RawMessage(`{"any":2}`),
+			expectedConfig: json.RawMessage(`{"any":2,"enabled":true}`),
+		},
+		{
+			description:    "request-enforcement-field-ignored",
+			givenModule:    "acme.foo",
+			givenRequest:   json.RawMessage(`{"enabled":false,"limits":{"max":3}}`),
+			expectedConfig: json.RawMessage(`{"enabled":true,"limits":{"max":10,"min":1},"mode":"block"}`),
+			expectedErrs:   []string{`request config of "acme.foo" module ignored: the request can't override enabled`},
+		},
+		{
+			description:    "request-not-overridable-fields-ignored",
+			givenModule:    "acme.foo",
+			givenRequest:   json.RawMessage(`{"mode":"report","other":1}`),
+			expectedConfig: json.RawMessage(`{"enabled":true,"limits":{"max":10,"min":1},"mode":"block"}`),
+			expectedErrs:   []string{`request config of "acme.foo" module ignored: the request can't override mode, other`},
+		},
+		{
+			description:    "request-not-an-object-ignored",
+			givenModule:    "acme.foo",
+			givenRequest:   json.RawMessage(`3`),
+			expectedConfig: json.RawMessage(`{"enabled":true,"limits":{"max":10,"min":1},"mode":"block"}`),
+			expectedErrs:   []string{`request config of "acme.foo" module ignored: request config must be an object: expect { or n, but found 3`},
+		},
+		{
+			description:    "module-without-schema-not-overridable-by-request",
+			givenModule:    "acme.bar",
+			givenRequest:   json.RawMessage(`{"any":3}`),
+			expectedConfig: json.RawMessage(`{"any":1,"enabled":true}`),
+			expectedErrs:   []string{`request config of "acme.bar" module ignored: the request can't override any`},
+		},
+	}
+
+	host := config.Modules{"acme": {
+		"foo": map[string]interface{}{"enabled": true, "mode": "block", "limits": map[string]interface{}{"min": 1, "max": 10}},
+		"bar": map[string]interface{}{"enabled": true, "any": 1},
+	}}
+	configs, err := NewModuleConfigs(writeModuleSchemas(t, map[string]string{"acme.foo.json": testModuleSchema}), host)
+	require.NoError(t, err)
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			cfg, errs := configs.Resolve(test.givenModule, test.givenAccount, test.givenRequest)
+			assert.JSONEq(t, string(test.expectedConfig), string(cfg))
+
+			errMessages := make([]string, 0, len(errs))
+			for _, err := range errs {
+				errMessages = append(errMessages, err.Error())
+			}
+			if len(test.expectedErrs) == 0 {
+				assert.Empty(t, errMessages)
+			} else {
+				assert.Equal(t, test.expectedErrs, errMessages)
+			}
+		})
+	}
+}
+
+func TestModuleConfigsSetHostConfig(t *testing.T) {
+	configs, err := NewModuleConfigs(writeModuleSchemas(t, map[string]string{"acme.foo.json": testModuleSchema}), config.Modules{"acme": {"foo": map[string]interface{}{"mode": "block"}}})
+	require.NoError(t, err)
+
+	err = configs.SetHostConfig(config.Modules{"acme": {"foo": map[string]interface{}{"mode": "drop"}}})
+	assert.Error(t, err)
+	cfg, _ := configs.Resolve("acme.foo", nil, nil)
+	assert.JSONEq(t, `{"mode":"block"}`, string(cfg), "invalid config must not replace the previous one")
+
+	require.NoError(t, configs.SetHostConfig(config.Modules{"acme": {"foo": map[string]interface{}{"mode": "report"}}}))
+	cfg, _ = configs.Resolve("acme.foo", nil, nil)
+	assert.JSONEq(t, `{"mode":"report"}`, string(cfg))
+}
+
+func TestModuleConfigsNil(t *testing.T) {
+	var configs *ModuleConfigs
+
+	cfg, errs := configs.Resolve("acme.foo", json.RawMessage(`{"a":{"b":1}}`), json.RawMessage(`{"a":{"c":2}}`))
+	assert.JSONEq(t, `{"a":{"b":1}}`, string(cfg))
+	assert.Len(t, errs, 1, "the request can't override modules without a schema")
+	assert.NoError(t, configs.Validate("acme.foo", json.RawMessage(`{}`)))
+	assert.Nil(t, configs.Schema("acme.foo"))
+}
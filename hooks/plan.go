@@ -1,6 +1,7 @@
 package hooks
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/golang/glog"
@@ -19,6 +20,10 @@ const (
 	StageRawBidderResponse        Stage = "raw_bidder_response"
 	StageAllProcessedBidResponses Stage = "all_processed_bid_responses"
 	StageAuctionResponse          Stage = "auction_response"
+	StageExitpoint                Stage = "exitpoint"
+	StageCookieSync               Stage = "cookie_sync"
+	StageSetUID                   Stage = "setuid"
+	StageEvent                    Stage = "event"
 )
 
 func (s Stage) String() string {
@@ -27,7 +32,8 @@ func (s Stage) String() string {
 
 func (s Stage) IsRejectable() bool {
 	return s != StageAllProcessedBidResponses &&
-		s != StageAuctionResponse
+		s != StageAuctionResponse &&
+		s != StageExitpoint
 }
 
 // ExecutionPlanBuilder is the interface that provides methods
@@ -41,6 +47,13 @@ type ExecutionPlanBuilder interface {
 	PlanForRawBidderResponseStage(endpoint string, account *config.Account) Plan[hookstage.RawBidderResponse]
 	PlanForAllProcessedBidResponsesStage(endpoint string, account *config.Account) Plan[hookstage.AllProcessedBidResponses]
 	PlanForAuctionResponseStage(endpoint string, account *config.Account) Plan[hookstage.AuctionResponse]
+	PlanForExitpointStage(endpoint string, account *config.Account) Plan[hookstage.Exitpoint]
+	PlanForCookieSyncStage(endpoint string, account *config.Account) Plan[hookstage.CookieSync]
+	PlanForSetUIDStage(endpoint string, account *config.Account) Plan[hookstage.SetUID]
+	PlanForEventStage(endpoint string, account *config.Account) Plan[hookstage.Event]
+	// ModuleConfig returns the effective config of the module, merging the given account-level and request-level
+	// config on top of the host-level config. Overrides ignored because they are invalid are reported as errors.
+	ModuleConfig(module string, account, request json.RawMessage) (json.RawMessage, []error)
 }
 
 // Plan represents a slice of groups of hooks of a specific type grouped in the established order.
@@ -65,15 +78,18 @@ type HookWrapper[T any] struct {
 	Code string
 	// Hook is an instance of the specific hook interface.
 	Hook T
+	// Conditions restrict the execution of the hook to the matching requests, the hook runs on every request if nil.
+	Conditions *config.HookConditions
 }
 
 // NewExecutionPlanBuilder returns a new instance of the ExecutionPlanBuilder interface.
 // Depending on the hooks' status, method returns a real PlanBuilder or the EmptyPlanBuilder.
-func NewExecutionPlanBuilder(hooks config.Hooks, repo HookRepository) ExecutionPlanBuilder {
+func NewExecutionPlanBuilder(hooks config.Hooks, repo HookRepository, configs *ModuleConfigs) ExecutionPlanBuilder {
 	if hooks.Enabled {
 		return PlanBuilder{
-			hooks: hooks,
-			repo:  repo,
+			hooks:   hooks,
+			repo:    repo,
+			configs: configs,
 		}
 	}
 	return EmptyPlanBuilder{}
@@ -82,8 +98,9 @@ func NewExecutionPlanBuilder(hooks config.Hooks, repo HookRepository) ExecutionP
 // PlanBuilder is a concrete implementation of the ExecutionPlanBuilder interface.
 // Which returns hook execution plans for specific stage defined by the hook config.
 type PlanBuilder struct {
-	hooks config.Hooks
-	repo  HookRepository
+	hooks   config.Hooks
+	repo    HookRepository
+	configs *ModuleConfigs
 }
 
 func (p PlanBuilder) PlanForEntrypointStage(endpoint string) Plan[hookstage.Entrypoint] {
@@ -156,6 +173,50 @@ func (p PlanBuilder) PlanForAuctionResponseStage(endpoint string, account *confi
 	)
 }
 
+func (p PlanBuilder) PlanForExitpointStage(endpoint string, account *config.Account) Plan[hookstage.Exitpoint] {
+	return getMergedPlan(
+		p.hooks,
+		account,
+		endpoint,
+		StageExitpoint,
+		p.repo.GetExitpointHook,
+	)
+}
+
+func (p PlanBuilder) PlanForCookieSyncStage(endpoint string, account *config.Account) Plan[hookstage.CookieSync] {
+	return getMergedPlan(
+		p.hooks,
+		account,
+		endpoint,
+		StageCookieSync,
+		p.repo.GetCookieSyncHook,
+	)
+}
+
+func (p PlanBuilder) PlanForSetUIDStage(endpoint string, account *config.Account) Plan[hookstage.SetUID] {
+	return getMergedPlan(
+		p.hooks,
+		account,
+		endpoint,
+		StageSetUID,
+		p.repo.GetSetUIDHook,
+	)
+}
+
+func (p PlanBuilder) PlanForEventStage(endpoint string, account *config.Account) Plan[hookstage.Event] {
+	return getMergedPlan(
+		p.hooks,
+		account,
+		endpoint,
+		StageEvent,
+		p.repo.GetEventHook,
+	)
+}
+
+func (p PlanBuilder) ModuleConfig(module string, account, request json.RawMessage) (json.RawMessage, []error) {
+	return p.configs.Resolve(module, account, request)
+}
+
 type hookFn[T any] func(moduleName string) (T, bool)
 
 func getMergedPlan[T any](
@@ -196,7 +257,7 @@ func getGroup[T any](getHookFn hookFn[T], cfg config.HookExecutionGroup) Group[T
 
 	for _, hookCfg := range cfg.HookSequence {
 		if h, ok := getHookFn(hookCfg.ModuleCode); ok {
-			group.Hooks = append(group.Hooks, HookWrapper[T]{Module: hookCfg.ModuleCode, Code: hookCfg.HookImplCode, Hook: h})
+			group.Hooks = append(group.Hooks, HookWrapper[T]{Module: hookCfg.ModuleCode, Code: hookCfg.HookImplCode, Hook: h, Conditions: hookCfg.Conditions})
 		} else {
 			glog.Warningf("Not found hook while building hook execution plan: %s %s", hookCfg.ModuleCode, hookCfg.HookImplCode)
 		}
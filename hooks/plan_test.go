@@ -9,6 +9,7 @@ import (
 	"github.com/prebid/prebid-server/v3/hooks/hookstage"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewExecutionPlanBuilder(t *testing.T) {
@@ -29,7 +30,7 @@ func TestNewExecutionPlanBuilder(t *testing.T) {
 
 	for name, test := range testCases {
 		t.Run(name, func(t *testing.T) {
-			gotPlanBuilder := NewExecutionPlanBuilder(test.givenConfig, nil)
+			gotPlanBuilder := NewExecutionPlanBuilder(test.givenConfig, nil, nil)
 			assert.Equal(t, test.expectedPlanBuilder, gotPlanBuilder)
 		})
 	}
@@ -770,6 +771,94 @@ func TestPlanForAuctionResponseStage(t *testing.T) {
 	}
 }
 
+func TestPlanForExitpointStage(t *testing.T) {
+	const group1 string = `{"timeout":  5, "hook_sequence": [{"module_code": "foobar", "hook_impl_code": "foo"}]}`
+	const group2 string = `{"timeout": 10, "hook_sequence": [{"module_code": "prebid", "hook_impl_code": "bar"}]}`
+	const hostPlanData string = `{"endpoints": {"/openrtb2/video": {"stages": {"exitpoint": {"groups": [` + group1 + `]}}}}}`
+	const accountPlanData string = `{"execution_plan": {"endpoints": {"/openrtb2/video": {"stages": {"exitpoint": {"groups": [` + group2 + `]}}}}}}`
+
+	hooks := map[string]interface{}{
+		"foobar": fakeExitpointHook{},
+		"prebid": fakeExitpointHook{},
+	}
+
+	testCases := map[string]struct {
+		givenEndpoint string
+		expectedPlan  Plan[hookstage.Exitpoint]
+	}{
+		"Host and account execution plans are merged": {
+			givenEndpoint: "/openrtb2/video",
+			expectedPlan: Plan[hookstage.Exitpoint]{
+				Group[hookstage.Exitpoint]{
+					Timeout: 5 * time.Millisecond,
+					Hooks: []HookWrapper[hookstage.Exitpoint]{
+						{Module: "foobar", Code: "foo", Hook: fakeExitpointHook{}},
+					},
+				},
+				Group[hookstage.Exitpoint]{
+					Timeout: 10 * time.Millisecond,
+					Hooks: []HookWrapper[hookstage.Exitpoint]{
+						{Module: "prebid", Code: "bar", Hook: fakeExitpointHook{}},
+					},
+				},
+			},
+		},
+		"Empty plan for endpoint without exitpoint hooks": {
+			givenEndpoint: "/openrtb2/auction",
+			expectedPlan:  Plan[hookstage.Exitpoint]{},
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			account := new(config.Account)
+			if err := jsonutil.UnmarshalValid([]byte(accountPlanData), &account.Hooks); err != nil {
+				t.Fatal(err)
+			}
+
+			planBuilder, err := getPlanBuilder(hooks, []byte(hostPlanData), []byte(`{}`))
+			if assert.NoError(t, err, "Failed to init hook execution plan builder") {
+				plan := planBuilder.PlanForExitpointStage(test.givenEndpoint, account)
+				assert.Equal(t, test.expectedPlan, plan)
+			}
+		})
+	}
+}
+
+func TestPlanForCookieSyncSetUIDAndEventStages(t *testing.T) {
+	const group1 string = `{"timeout":  5, "hook_sequence": [{"module_code": "foobar", "hook_impl_code": "foo"}]}`
+	const group2 string = `{"timeout": 10, "hook_sequence": [{"module_code": "prebid", "hook_impl_code": "bar"}]}`
+	const hostPlanData string = `{"endpoints": {` +
+		`"/cookie_sync": {"stages": {"cookie_sync": {"groups": [` + group1 + `]}}},` +
+		`"/setuid": {"stages": {"setuid": {"groups": [` + group1 + `]}}},` +
+		`"/event": {"stages": {"event": {"groups": [` + group1 + `]}}}}}`
+	const accountPlanData string = `{"execution_plan": {"endpoints": {"/event": {"stages": {"event": {"groups": [` + group2 + `]}}}}}}`
+
+	hooks := map[string]interface{}{
+		"foobar": fakeUserSyncAndEventHook{},
+		"prebid": fakeUserSyncAndEventHook{},
+	}
+
+	account := new(config.Account)
+	require.NoError(t, jsonutil.UnmarshalValid([]byte(accountPlanData), &account.Hooks))
+
+	planBuilder, err := getPlanBuilder(hooks, []byte(hostPlanData), []byte(`{}`))
+	require.NoError(t, err, "Failed to init hook execution plan builder")
+
+	hostGroup := []HookWrapper[hookstage.CookieSync]{{Module: "foobar", Code: "foo", Hook: fakeUserSyncAndEventHook{}}}
+	assert.Equal(t, Plan[hookstage.CookieSync]{{Timeout: 5 * time.Millisecond, Hooks: hostGroup}}, planBuilder.PlanForCookieSyncStage("/cookie_sync", account))
+	assert.Equal(t, Plan[hookstage.CookieSync]{}, planBuilder.PlanForCookieSyncStage("/setuid", account))
+
+	assert.Equal(t, Plan[hookstage.SetUID]{
+		{Timeout: 5 * time.Millisecond, Hooks: []HookWrapper[hookstage.SetUID]{{Module: "foobar", Code: "foo", Hook: fakeUserSyncAndEventHook{}}}},
+	}, planBuilder.PlanForSetUIDStage("/setuid", account))
+
+	assert.Equal(t, Plan[hookstage.Event]{
+		{Timeout: 5 * time.Millisecond, Hooks: []HookWrapper[hookstage.Event]{{Module: "foobar", Code: "foo", Hook: fakeUserSyncAndEventHook{}}}},
+		{Timeout: 10 * time.Millisecond, Hooks: []HookWrapper[hookstage.Event]{{Module: "prebid", Code: "bar", Hook: fakeUserSyncAndEventHook{}}}},
+	}, planBuilder.PlanForEventStage("/event", account), "host and account execution plans are merged")
+}
+
 func getPlanBuilder(
 	moduleHooks map[string]interface{},
 	hostPlanData, accountPlanData []byte,
@@ -798,7 +887,7 @@ func getPlanBuilder(
 		return nil, err
 	}
 
-	return NewExecutionPlanBuilder(hooks, repo), nil
+	return NewExecutionPlanBuilder(hooks, repo, nil), nil
 }
 
 type fakeEntrypointHook struct{}
@@ -870,3 +959,39 @@ func (f fakeAuctionResponseHook) HandleAuctionResponseHook(
 ) (hookstage.HookResult[hookstage.AuctionResponsePayload], error) {
 	return hookstage.HookResult[hookstage.AuctionResponsePayload]{}, nil
 }
+
+type fakeExitpointHook struct{}
+
+func (f fakeExitpointHook) HandleExitpointHook(
+	_ context.Context,
+	_ hookstage.ModuleInvocationContext,
+	_ hookstage.ExitpointPayload,
+) (hookstage.HookResult[hookstage.ExitpointPayload], error) {
+	return hookstage.HookResult[hookstage.ExitpointPayload]{}, nil
+}
+
+type fakeUserSyncAndEventHook struct{}
+
+func (f fakeUserSyncAndEventHook) HandleCookieSyncHook(
+	_ context.Context,
+	_ hookstage.ModuleInvocationContext,
+	_ hookstage.CookieSyncPayload,
+) (hookstage.HookResult[hookstage.CookieSyncPayload], error) {
+	return hookstage.HookResult[hookstage.CookieSyncPayload]{}, nil
+}
+
+func (f fakeUserSyncAndEventHook) HandleSetUIDHook(
+	_ context.Context,
+	_ hookstage.ModuleInvocationContext,
+	_ hookstage.SetUIDPayload,
+) (hookstage.HookResult[hookstage.SetUIDPayload], error) {
+	return hookstage.HookResult[hookstage.SetUIDPayload]{}, nil
+}
+
+func (f fakeUserSyncAndEventHook) HandleEventHook(
+	_ context.Context,
+	_ hookstage.ModuleInvocationContext,
+	_ hookstage.EventPayload,
+) (hookstage.HookResult[hookstage.EventPayload], error) {
+	return hookstage.HookResult[hookstage.EventPayload]{}, nil
+}
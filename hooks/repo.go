@@ -21,6 +21,10 @@ type HookRepository interface {
 	GetRawBidderResponseHook(id string) (hookstage.RawBidderResponse, bool)
 	GetAllProcessedBidResponsesHook(id string) (hookstage.AllProcessedBidResponses, bool)
 	GetAuctionResponseHook(id string) (hookstage.AuctionResponse, bool)
+	GetExitpointHook(id string) (hookstage.Exitpoint, bool)
+	GetCookieSyncHook(id string) (hookstage.CookieSync, bool)
+	GetSetUIDHook(id string) (hookstage.SetUID, bool)
+	GetEventHook(id string) (hookstage.Event, bool)
 }
 
 // NewHookRepository returns a new instance of the HookRepository interface.
@@ -49,6 +53,10 @@ type hookRepository struct {
 	rawBidderResponseHooks       map[string]hookstage.RawBidderResponse
 	allProcessedBidResponseHooks map[string]hookstage.AllProcessedBidResponses
 	auctionResponseHooks         map[string]hookstage.AuctionResponse
+	exitpointHooks               map[string]hookstage.Exitpoint
+	cookieSyncHooks              map[string]hookstage.CookieSync
+	setUIDHooks                  map[string]hookstage.SetUID
+	eventHooks                   map[string]hookstage.Event
 }
 
 func (r *hookRepository) GetEntrypointHook(id string) (hookstage.Entrypoint, bool) {
@@ -79,6 +87,22 @@ func (r *hookRepository) GetAuctionResponseHook(id string) (hookstage.AuctionRes
 	return getHook(r.auctionResponseHooks, id)
 }
 
+func (r *hookRepository) GetExitpointHook(id string) (hookstage.Exitpoint, bool) {
+	return getHook(r.exitpointHooks, id)
+}
+
+func (r *hookRepository) GetCookieSyncHook(id string) (hookstage.CookieSync, bool) {
+	return getHook(r.cookieSyncHooks, id)
+}
+
+func (r *hookRepository) GetSetUIDHook(id string) (hookstage.SetUID, bool) {
+	return getHook(r.setUIDHooks, id)
+}
+
+func (r *hookRepository) GetEventHook(id string) (hookstage.Event, bool) {
+	return getHook(r.eventHooks, id)
+}
+
 func (r *hookRepository) add(id string, hook interface{}) error {
 	var hasAnyHooks bool
 	var err error
@@ -132,6 +156,34 @@ func (r *hookRepository) add(id string, hook interface{}) error {
 		}
 	}
 
+	if h, ok := hook.(hookstage.Exitpoint); ok {
+		hasAnyHooks = true
+		if r.exitpointHooks, err = addHook(r.exitpointHooks, h, id); err != nil {
+			return err
+		}
+	}
+
+	if h, ok := hook.(hookstage.CookieSync); ok {
+		hasAnyHooks = true
+		if r.cookieSyncHooks, err = addHook(r.cookieSyncHooks, h, id); err != nil {
+			return err
+		}
+	}
+
+	if h, ok := hook.(hookstage.SetUID); ok {
+		hasAnyHooks = true
+		if r.setUIDHooks, err = addHook(r.setUIDHooks, h, id); err != nil {
+			return err
+		}
+	}
+
+	if h, ok := hook.(hookstage.Event); ok {
+		hasAnyHooks = true
+		if r.eventHooks, err = addHook(r.eventHooks, h, id); err != nil {
+			return err
+		}
+	}
+
 	if !hasAnyHooks {
 		return fmt.Errorf(`hook "%s" does not implement any supported hook interface`, id)
 	}
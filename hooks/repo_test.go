@@ -28,6 +28,24 @@ func TestNewHookRepository(t *testing.T) {
 				return repo.GetEntrypointHook(id)
 			},
 		},
+		"Added exitpoint hook returns": {
+			isFound:      true,
+			providedHook: exitpointHook{},
+			expectedHook: exitpointHook{},
+			expectedErr:  nil,
+			getHookFn: func(repo HookRepository) (interface{}, bool) {
+				return repo.GetExitpointHook(id)
+			},
+		},
+		"Added user sync hook returns": {
+			isFound:      true,
+			providedHook: userSyncHook{},
+			expectedHook: userSyncHook{},
+			expectedErr:  nil,
+			getHookFn: func(repo HookRepository) (interface{}, bool) {
+				return repo.GetSetUIDHook(id)
+			},
+		},
 		"Not found hook": {
 			isFound:      false,
 			providedHook: hook{},
@@ -74,3 +92,19 @@ type hook struct{}
 func (h hook) HandleEntrypointHook(ctx context.Context, context hookstage.ModuleInvocationContext, payload hookstage.EntrypointPayload) (hookstage.HookResult[hookstage.EntrypointPayload], error) {
 	return hookstage.HookResult[hookstage.EntrypointPayload]{}, nil
 }
+
+type exitpointHook struct{}
+
+func (h exitpointHook) HandleExitpointHook(ctx context.Context, context hookstage.ModuleInvocationContext, payload hookstage.ExitpointPayload) (hookstage.HookResult[hookstage.ExitpointPayload], error) {
+	return hookstage.HookResult[hookstage.ExitpointPayload]{}, nil
+}
+
+type userSyncHook struct{}
+
+func (h userSyncHook) HandleCookieSyncHook(ctx context.Context, context hookstage.ModuleInvocationContext, payload hookstage.CookieSyncPayload) (hookstage.HookResult[hookstage.CookieSyncPayload], error) {
+	return hookstage.HookResult[hookstage.CookieSyncPayload]{}, nil
+}
+
+func (h userSyncHook) HandleSetUIDHook(ctx context.Context, context hookstage.ModuleInvocationContext, payload hookstage.SetUIDPayload) (hookstage.HookResult[hookstage.SetUIDPayload], error) {
+	return hookstage.HookResult[hookstage.SetUIDPayload]{}, nil
+}
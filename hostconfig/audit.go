@@ -0,0 +1,78 @@
+package hostconfig
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	AuditActionUpdateBidder = "update_bidder"
+	AuditActionResetBidder  = "reset_bidder"
+)
+
+// AuditEntry records a runtime change of the host config.
+type AuditEntry struct {
+	Time    time.Time      `json:"time"`
+	Version int            `json:"version"`
+	Actor   string         `json:"actor"`
+	Action  string         `json:"action"`
+	Bidder  string         `json:"bidder"`
+	Before  BidderOverride `json:"before"`
+	After   BidderOverride `json:"after"`
+}
+
+// AuditLog records the runtime changes. A change is only applied once recorded.
+type AuditLog interface {
+	Record(entry AuditEntry) error
+}
+
+// NewAuditLog returns an AuditLog writing the changes to glog and, if the path isn't empty, appending them to
+// the file as JSON lines.
+func NewAuditLog(path string) (AuditLog, error) {
+	if path == "" {
+		return glogAuditLog{}, nil
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &fileAuditLog{file: file}, nil
+}
+
+type glogAuditLog struct{}
+
+func (glogAuditLog) Record(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	glog.Infof("Host config change: %s", line)
+	return nil
+}
+
+type fileAuditLog struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+func (l *fileAuditLog) Record(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	glog.Infof("Host config change: %s", line)
+	return nil
+}
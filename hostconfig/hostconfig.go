@@ -0,0 +1,219 @@
+// Package hostconfig holds the host config settings which can be changed at runtime through the admin server,
+// without a redeploy.
+//
+// The changes are applied atomically: the exchange and the endpoints read an immutable Snapshot of the settings,
+// and a new Snapshot replaces it once every subscriber has accepted the change.
+package hostconfig
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+)
+
+// BidderOverride changes the settings of a bidder at runtime. Nil fields keep the settings of the config files.
+type BidderOverride struct {
+	Disabled *bool   `json:"disabled,omitempty"`
+	Endpoint *string `json:"endpoint,omitempty"`
+}
+
+// IsEmpty returns true if the override doesn't change any setting.
+func (o BidderOverride) IsEmpty() bool {
+	return o.Disabled == nil && o.Endpoint == nil
+}
+
+// merge returns the override with the fields set in the update replacing its own.
+func (o BidderOverride) merge(update BidderOverride) BidderOverride {
+	if update.Disabled != nil {
+		o.Disabled = update.Disabled
+	}
+	if update.Endpoint != nil {
+		o.Endpoint = update.Endpoint
+	}
+	return o
+}
+
+// Snapshot is a version of the runtime settings. It must not be modified.
+type Snapshot struct {
+	Version   int                       `json:"version"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+	Overrides map[string]BidderOverride `json:"overrides"`
+	// BidderInfos are the bidder infos of the config files with the overrides applied.
+	BidderInfos config.BidderInfos `json:"-"`
+}
+
+// IsBidderDisabled returns true if the bidder has been disabled at runtime.
+func (s *Snapshot) IsBidderDisabled(bidder openrtb_ext.BidderName) bool {
+	override, ok := s.Overrides[string(bidder)]
+	return ok && override.Disabled != nil && *override.Disabled
+}
+
+// Subscriber prepares the change to a new snapshot. It returns an error to reject the change, or a commit function
+// called once every subscriber accepted it. The commit function must not fail.
+type Subscriber func(snapshot *Snapshot) (commit func(), err error)
+
+// Store holds the current snapshot of the runtime settings.
+type Store struct {
+	// mutex serializes the updates. Readers only load the current snapshot.
+	mutex       sync.Mutex
+	current     atomic.Pointer[Snapshot]
+	bidderInfos config.BidderInfos
+	subscribers []Subscriber
+	auditLog    AuditLog
+	time        timeutil.Time
+}
+
+// NewStore returns a Store whose first snapshot has the bidder infos of the config files and no override.
+func NewStore(bidderInfos config.BidderInfos, auditLog AuditLog, timeSource timeutil.Time) *Store {
+	store := &Store{bidderInfos: bidderInfos, auditLog: auditLog, time: timeSource}
+	store.current.Store(&Snapshot{
+		UpdatedAt:   timeSource.Now(),
+		Overrides:   map[string]BidderOverride{},
+		BidderInfos: bidderInfos,
+	})
+	return store
+}
+
+// Load returns the current snapshot.
+func (s *Store) Load() *Snapshot {
+	return s.current.Load()
+}
+
+// Subscribe adds a subscriber to the changes. It isn't called for the current snapshot.
+func (s *Store) Subscribe(subscriber Subscriber) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.subscribers = append(s.subscribers, subscriber)
+}
+
+// UpdateBidder merges the update into the runtime override of the bidder. The actor identifies who made the
+// change in the audit log.
+func (s *Store) UpdateBidder(actor, bidder string, update BidderOverride) (*Snapshot, error) {
+	return s.change(actor, AuditActionUpdateBidder, bidder, func(override BidderOverride) BidderOverride {
+		return override.merge(update)
+	})
+}
+
+// ResetBidder removes the runtime override of the bidder, so it's back to the settings of the config files.
+func (s *Store) ResetBidder(actor, bidder string) (*Snapshot, error) {
+	return s.change(actor, AuditActionResetBidder, bidder, func(BidderOverride) BidderOverride {
+		return BidderOverride{}
+	})
+}
+
+func (s *Store) change(actor, action, bidder string, apply func(BidderOverride) BidderOverride) (*Snapshot, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	bidderName, ok := openrtb_ext.NormalizeBidderName(bidder)
+	if !ok {
+		return nil, &UnknownBidderError{Bidder: bidder}
+	}
+	bidder = string(bidderName)
+	info, ok := s.bidderInfos[bidder]
+	if !ok {
+		return nil, &UnknownBidderError{Bidder: bidder}
+	}
+
+	current := s.current.Load()
+	before := current.Overrides[bidder]
+	after := apply(before)
+	if err := validateOverride(bidder, info, after); err != nil {
+		return nil, err
+	}
+
+	next := &Snapshot{
+		Version:     current.Version + 1,
+		UpdatedAt:   s.time.Now(),
+		Overrides:   maps.Clone(current.Overrides),
+		BidderInfos: maps.Clone(current.BidderInfos),
+	}
+	if after.IsEmpty() {
+		delete(next.Overrides, bidder)
+	} else {
+		next.Overrides[bidder] = after
+	}
+	next.BidderInfos[bidder] = applyOverride(info, after)
+
+	commits := make([]func(), 0, len(s.subscribers))
+	for _, subscriber := range s.subscribers {
+		commit, err := subscriber(next)
+		if err != nil {
+			return nil, &InvalidChangeError{Message: err.Error()}
+		}
+		commits = append(commits, commit)
+	}
+
+	// a change which can't be audited isn't applied
+	entry := AuditEntry{
+		Time:    next.UpdatedAt,
+		Version: next.Version,
+		Actor:   actor,
+		Action:  action,
+		Bidder:  bidder,
+		Before:  before,
+		After:   after,
+	}
+	if err := s.auditLog.Record(entry); err != nil {
+		return nil, fmt.Errorf("failed to write the audit log: %v", err)
+	}
+
+	s.current.Store(next)
+	for _, commit := range commits {
+		commit()
+	}
+	return next, nil
+}
+
+func validateOverride(bidder string, info config.BidderInfo, override BidderOverride) error {
+	// the request validation and the endpoints only know the bidders enabled in the config files
+	if override.Disabled != nil && !*override.Disabled && info.Disabled {
+		return &InvalidChangeError{Message: fmt.Sprintf("bidder %s is disabled in the config files and can't be enabled at runtime", bidder)}
+	}
+	if override.Endpoint != nil && *override.Endpoint == "" {
+		return &InvalidChangeError{Message: "the endpoint must not be empty"}
+	}
+	return nil
+}
+
+func applyOverride(info config.BidderInfo, override BidderOverride) config.BidderInfo {
+	if override.Disabled != nil {
+		info.Disabled = info.Disabled || *override.Disabled
+	}
+	if override.Endpoint != nil {
+		info.Endpoint = *override.Endpoint
+	}
+	return info
+}
+
+// UnknownBidderError is returned for changes to a bidder missing from the config files.
+type UnknownBidderError struct {
+	Bidder string
+}
+
+func (err *UnknownBidderError) Error() string {
+	return fmt.Sprintf("unknown bidder %s", err.Bidder)
+}
+
+// InvalidChangeError is returned for changes which are rejected, leaving the current snapshot in place.
+type InvalidChangeError struct {
+	Message string
+}
+
+func (err *InvalidChangeError) Error() string {
+	return err.Message
+}
+
+// IsInvalidChange returns true if the error comes from a change of the request, rather than from the server.
+func IsInvalidChange(err error) bool {
+	var unknownErr *UnknownBidderError
+	var invalidErr *InvalidChangeError
+	return errors.As(err, &unknownErr) || errors.As(err, &invalidErr)
+}
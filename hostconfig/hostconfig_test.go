@@ -0,0 +1,224 @@
+package hostconfig
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTime struct {
+	time time.Time
+}
+
+func (ft *fakeTime) Now() time.Time {
+	return ft.time
+}
+
+type fakeAuditLog struct {
+	entries []AuditEntry
+	err     error
+}
+
+func (l *fakeAuditLog) Record(entry AuditEntry) error {
+	if l.err != nil {
+		return l.err
+	}
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+var testTime = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestStore(auditLog AuditLog) *Store {
+	infos := config.BidderInfos{
+		"appnexus": {Endpoint: "https://appnexus.com"},
+		"rubicon":  {Endpoint: "https://rubicon.com", Disabled: true},
+	}
+	return NewStore(infos, auditLog, &fakeTime{time: testTime})
+}
+
+func TestUpdateBidder(t *testing.T) {
+	auditLog := &fakeAuditLog{}
+	store := newTestStore(auditLog)
+	first := store.Load()
+
+	snapshot, err := store.UpdateBidder("admin", "AppNexus", BidderOverride{Disabled: ptrutil.ToPtr(true)})
+	require.NoError(t, err)
+	snapshot, err = store.UpdateBidder("admin", "appnexus", BidderOverride{Endpoint: ptrutil.ToPtr("https://new.appnexus.com")})
+	require.NoError(t, err)
+
+	assert.Same(t, snapshot, store.Load())
+	assert.Equal(t, 2, snapshot.Version)
+	assert.Equal(t, map[string]BidderOverride{"appnexus": {Disabled: ptrutil.ToPtr(true), Endpoint: ptrutil.ToPtr("https://new.appnexus.com")}}, snapshot.Overrides, "the updates are merged")
+	assert.Equal(t, config.BidderInfo{Endpoint: "https://new.appnexus.com", Disabled: true}, snapshot.BidderInfos["appnexus"])
+	assert.True(t, snapshot.IsBidderDisabled(openrtb_ext.BidderAppnexus))
+
+	assert.Equal(t, 0, first.Version, "the previous snapshots are left as is")
+	assert.Empty(t, first.Overrides)
+	assert.Equal(t, "https://appnexus.com", first.BidderInfos["appnexus"].Endpoint)
+
+	require.Len(t, auditLog.entries, 2)
+	assert.Equal(t, AuditEntry{
+		Time:    testTime,
+		Version: 2,
+		Actor:   "admin",
+		Action:  AuditActionUpdateBidder,
+		Bidder:  "appnexus",
+		Before:  BidderOverride{Disabled: ptrutil.ToPtr(true)},
+		After:   BidderOverride{Disabled: ptrutil.ToPtr(true), Endpoint: ptrutil.ToPtr("https://new.appnexus.com")},
+	}, auditLog.entries[1])
+}
+
+func TestResetBidder(t *testing.T) {
+	auditLog := &fakeAuditLog{}
+	store := newTestStore(auditLog)
+
+	_, err := store.UpdateBidder("admin", "appnexus", BidderOverride{Endpoint: ptrutil.ToPtr("https://new.appnexus.com")})
+	require.NoError(t, err)
+	snapshot, err := store.ResetBidder("admin", "appnexus")
+	require.NoError(t, err)
+
+	assert.Empty(t, snapshot.Overrides)
+	assert.Equal(t, "https://appnexus.com", snapshot.BidderInfos["appnexus"].Endpoint)
+	assert.Equal(t, AuditActionResetBidder, auditLog.entries[1].Action)
+}
+
+func TestUpdateBidderRejected(t *testing.T) {
+	testCases := []struct {
+		description string
+		bidder      string
+		update      BidderOverride
+		subscriber  Subscriber
+		auditErr    error
+		expectedErr string
+		invalid     bool
+	}{
+		{
+			description: "unknown_bidder",
+			bidder:      "notabidder",
+			update:      BidderOverride{Disabled: ptrutil.ToPtr(true)},
+			expectedErr: "unknown bidder notabidder",
+			invalid:     true,
+		},
+		{
+			description: "bidder_without_config",
+			bidder:      "openx",
+			update:      BidderOverride{Disabled: ptrutil.ToPtr(true)},
+			expectedErr: "unknown bidder openx",
+			invalid:     true,
+		},
+		{
+			description: "enable_bidder_disabled_in_config",
+			bidder:      "rubicon",
+			update:      BidderOverride{Disabled: ptrutil.ToPtr(false)},
+			expectedErr: "bidder rubicon is disabled in the config files and can't be enabled at runtime",
+			invalid:     true,
+		},
+		{
+			description: "empty_endpoint",
+			bidder:      "appnexus",
+			update:      BidderOverride{Endpoint: ptrutil.ToPtr("")},
+			expectedErr: "the endpoint must not be empty",
+			invalid:     true,
+		},
+		{
+			description: "subscriber_rejects",
+			bidder:      "appnexus",
+			update:      BidderOverride{Endpoint: ptrutil.ToPtr("{{.Bad")},
+			subscriber: func(*Snapshot) (func(), error) {
+				return nil, errors.New("appnexus: invalid endpoint template")
+			},
+			expectedErr: "appnexus: invalid endpoint template",
+			invalid:     true,
+		},
+		{
+			description: "audit_log_fails",
+			bidder:      "appnexus",
+			update:      BidderOverride{Disabled: ptrutil.ToPtr(true)},
+			auditErr:    errors.New("disk full"),
+			expectedErr: "failed to write the audit log: disk full",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			store := newTestStore(&fakeAuditLog{err: tc.auditErr})
+			committed := false
+			store.Subscribe(func(snapshot *Snapshot) (func(), error) {
+				return func() { committed = true }, nil
+			})
+			if tc.subscriber != nil {
+				store.Subscribe(tc.subscriber)
+			}
+
+			_, err := store.UpdateBidder("admin", tc.bidder, tc.update)
+
+			assert.EqualError(t, err, tc.expectedErr)
+			assert.Equal(t, tc.invalid, IsInvalidChange(err))
+			assert.Equal(t, 0, store.Load().Version, "the snapshot isn't replaced")
+			assert.False(t, committed, "the subscribers don't commit")
+		})
+	}
+}
+
+func TestSubscribersCommitAfterSwap(t *testing.T) {
+	store := newTestStore(&fakeAuditLog{})
+	var prepared, committed *Snapshot
+	store.Subscribe(func(snapshot *Snapshot) (func(), error) {
+		prepared = snapshot
+		return func() {
+			committed = store.Load()
+		}, nil
+	})
+
+	snapshot, err := store.UpdateBidder("admin", "appnexus", BidderOverride{Disabled: ptrutil.ToPtr(true)})
+
+	require.NoError(t, err)
+	assert.Same(t, snapshot, prepared)
+	assert.Same(t, snapshot, committed)
+}
+
+func TestFileAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := NewAuditLog(path)
+	require.NoError(t, err)
+
+	entry := AuditEntry{Time: testTime, Version: 1, Actor: "admin", Action: AuditActionUpdateBidder, Bidder: "appnexus", After: BidderOverride{Disabled: ptrutil.ToPtr(true)}}
+	require.NoError(t, auditLog.Record(entry))
+	entry.Version = 2
+	require.NoError(t, auditLog.Record(entry))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"time":"2026-01-01T00:00:00Z","version":1,"actor":"admin","action":"update_bidder","bidder":"appnexus","before":{},"after":{"disabled":true}}`, lines[0])
+
+	var second AuditEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, 2, second.Version)
+}
+
+func TestNewAuditLogError(t *testing.T) {
+	_, err := NewAuditLog(filepath.Join(t.TempDir(), "missing", "audit.log"))
+	assert.Error(t, err)
+
+	auditLog, err := NewAuditLog("")
+	require.NoError(t, err)
+	assert.NoError(t, auditLog.Record(AuditEntry{Bidder: "appnexus"}))
+}
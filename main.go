@@ -2,9 +2,13 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"syscall"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
@@ -49,7 +53,7 @@ func main() {
 	garbageCollectionThreshold := make([]byte, cfg.GarbageCollectorThreshold)
 	defer runtime.KeepAlive(garbageCollectionThreshold)
 
-	err = serve(cfg)
+	err = serve(cfg, bidderInfos)
 	if err != nil {
 		glog.Exitf("prebid-server failed: %v", err)
 	}
@@ -64,7 +68,7 @@ func loadConfig(bidderInfos config.BidderInfos) (*config.Configuration, error) {
 	return config.New(v, bidderInfos, openrtb_ext.NormalizeBidderName)
 }
 
-func serve(cfg *config.Configuration) error {
+func serve(cfg *config.Configuration, bidderInfos config.BidderInfos) error {
 	fetchingInterval := time.Duration(cfg.CurrencyConverter.FetchIntervalSeconds) * time.Second
 	staleRatesThreshold := time.Duration(cfg.CurrencyConverter.StaleRatesSeconds) * time.Second
 	currencyConverter := currency.NewRateConverter(&http.Client{}, cfg.CurrencyConverter.FetchURL, staleRatesThreshold)
@@ -77,11 +81,36 @@ func serve(cfg *config.Configuration) error {
 		return err
 	}
 
+	reloadModules := func() error {
+		newCfg, err := loadConfig(bidderInfos)
+		if err != nil {
+			return fmt.Errorf("configuration could not be loaded or did not pass validation: %v", err)
+		}
+		if err := r.ModuleConfigs.SetHostConfig(newCfg.Hooks.Modules); err != nil {
+			return err
+		}
+		return r.ModuleLifecycle.Reload(newCfg.Hooks.Modules)
+	}
+	go reloadModulesOnSignal(reloadModules)
+
 	corsRouter := router.SupportCORS(r)
-	if err := server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(currencyConverter, fetchingInterval), r.MetricsEngine); err != nil {
+	if err := server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(cfg, currencyConverter, fetchingInterval, r.ModuleLifecycle, reloadModules, r.HostConfig, r.MetricsEngine.Dashboard), r.MetricsEngine, r.Health); err != nil {
 		glog.Fatalf("prebid-server returned an error: %v", err)
 	}
 
 	r.Shutdown()
 	return nil
 }
+
+// reloadModulesOnSignal reloads the hook modules config every time the process receives a SIGHUP.
+func reloadModulesOnSignal(reload func() error) {
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+
+	for range reloadSignals {
+		glog.Info("Reloading hook modules config")
+		if err := reload(); err != nil {
+			glog.Errorf("Failed to reload hook modules config: %v", err)
+		}
+	}
+}
@@ -5,8 +5,10 @@ import (
 
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/metrics/dashboard"
 	prometheusmetrics "github.com/prebid/prebid-server/v3/metrics/prometheus"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
 	gometrics "github.com/rcrowley/go-metrics"
 	influxdb "github.com/vrischmann/go-metrics-influxdb"
 )
@@ -44,6 +46,11 @@ func NewMetricsEngine(cfg *config.Configuration, adapterList []openrtb_ext.Bidde
 		engineList = append(engineList, returnEngine.PrometheusMetrics)
 	}
 
+	if cfg.Metrics.Dashboard.Enabled {
+		returnEngine.Dashboard = dashboard.NewRecorder(cfg.Metrics.Dashboard, &timeutil.RealTime{})
+		engineList = append(engineList, &dashboardMetricsEngine{recorder: returnEngine.Dashboard})
+	}
+
 	// Now return the proper metrics engine
 	if len(engineList) > 1 {
 		returnEngine.MetricsEngine = &engineList
@@ -61,6 +68,30 @@ type DetailedMetricsEngine struct {
 	metrics.MetricsEngine
 	GoMetrics         *metrics.Metrics
 	PrometheusMetrics *prometheusmetrics.Metrics
+	// Dashboard is nil unless metrics.dashboard.enabled is true.
+	Dashboard *dashboard.Recorder
+}
+
+// dashboardMetricsEngine passes the adapter metrics shown by the dashboard to its recorder.
+type dashboardMetricsEngine struct {
+	NilMetricsEngine
+	recorder *dashboard.Recorder
+}
+
+func (me *dashboardMetricsEngine) RecordAdapterRequest(labels metrics.AdapterLabels) {
+	me.recorder.RecordAdapterRequest(labels)
+}
+
+func (me *dashboardMetricsEngine) RecordAdapterTime(labels metrics.AdapterLabels, length time.Duration) {
+	me.recorder.RecordAdapterTime(labels, length)
+}
+
+func (me *dashboardMetricsEngine) RecordAdapterPrice(labels metrics.AdapterLabels, cpm float64) {
+	me.recorder.RecordAdapterPrice(labels, cpm)
+}
+
+func (me *dashboardMetricsEngine) RecordAdapterNonBid(labels metrics.AdapterLabels, reason int) {
+	me.recorder.RecordAdapterNonBid(labels, reason)
 }
 
 // MultiMetricsEngine logs metrics to multiple metrics databases The can be useful in transitioning
@@ -113,6 +144,13 @@ func (me *MultiMetricsEngine) RecordStoredDataFetchTime(labels metrics.StoredDat
 	}
 }
 
+// RecordStoredDataEventLag across all engines
+func (me *MultiMetricsEngine) RecordStoredDataEventLag(labels metrics.StoredDataLabels, lag time.Duration) {
+	for _, thisME := range *me {
+		thisME.RecordStoredDataEventLag(labels, lag)
+	}
+}
+
 // RecordStoredDataError across all engines
 func (me *MultiMetricsEngine) RecordStoredDataError(labels metrics.StoredDataLabels) {
 	for _, thisME := range *me {
@@ -259,6 +297,34 @@ func (me *MultiMetricsEngine) RecordTimeoutNotice(success bool) {
 	}
 }
 
+// RecordEventSignatureFailure across all engines
+func (me *MultiMetricsEngine) RecordEventSignatureFailure(status metrics.EventSignatureStatus) {
+	for _, thisME := range *me {
+		thisME.RecordEventSignatureFailure(status)
+	}
+}
+
+// RecordRateLimit across all engines
+func (me *MultiMetricsEngine) RecordRateLimit(labels metrics.RateLimitLabels) {
+	for _, thisME := range *me {
+		thisME.RecordRateLimit(labels)
+	}
+}
+
+// RecordMirroredRequest across all engines
+func (me *MultiMetricsEngine) RecordMirroredRequest(requestType metrics.RequestType, result metrics.MirrorResult) {
+	for _, thisME := range *me {
+		thisME.RecordMirroredRequest(requestType, result)
+	}
+}
+
+// RecordAccountConfigInvalid across all engines
+func (me *MultiMetricsEngine) RecordAccountConfigInvalid(section metrics.AccountConfigSection) {
+	for _, thisME := range *me {
+		thisME.RecordAccountConfigInvalid(section)
+	}
+}
+
 // RecordRequestPrivacy across all engines
 func (me *MultiMetricsEngine) RecordRequestPrivacy(privacy metrics.PrivacyLabels) {
 	for _, thisME := range *me {
@@ -273,6 +339,13 @@ func (me *MultiMetricsEngine) RecordAdapterBuyerUIDScrubbed(adapter openrtb_ext.
 	}
 }
 
+// RecordAdapterNonBid across all engines
+func (me *MultiMetricsEngine) RecordAdapterNonBid(labels metrics.AdapterLabels, reason int) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterNonBid(labels, reason)
+	}
+}
+
 // RecordAdapterGDPRRequestBlocked across all engines
 func (me *MultiMetricsEngine) RecordAdapterGDPRRequestBlocked(adapter openrtb_ext.BidderName) {
 	for _, thisME := range *me {
@@ -403,6 +476,10 @@ func (me *NilMetricsEngine) RecordRequestTime(labels metrics.Labels, length time
 func (me *NilMetricsEngine) RecordStoredDataFetchTime(labels metrics.StoredDataLabels, length time.Duration) {
 }
 
+// RecordStoredDataEventLag as a noop
+func (me *NilMetricsEngine) RecordStoredDataEventLag(labels metrics.StoredDataLabels, lag time.Duration) {
+}
+
 // RecordStoredDataError as a noop
 func (me *NilMetricsEngine) RecordStoredDataError(labels metrics.StoredDataLabels) {
 }
@@ -487,6 +564,22 @@ func (me *NilMetricsEngine) RecordRequestQueueTime(success bool, requestType met
 func (me *NilMetricsEngine) RecordTimeoutNotice(success bool) {
 }
 
+// RecordEventSignatureFailure as a noop
+func (me *NilMetricsEngine) RecordEventSignatureFailure(status metrics.EventSignatureStatus) {
+}
+
+// RecordRateLimit as a noop
+func (me *NilMetricsEngine) RecordRateLimit(labels metrics.RateLimitLabels) {
+}
+
+// RecordMirroredRequest as a noop
+func (me *NilMetricsEngine) RecordMirroredRequest(requestType metrics.RequestType, result metrics.MirrorResult) {
+}
+
+// RecordAccountConfigInvalid as a noop
+func (me *NilMetricsEngine) RecordAccountConfigInvalid(section metrics.AccountConfigSection) {
+}
+
 // RecordRequestPrivacy as a noop
 func (me *NilMetricsEngine) RecordRequestPrivacy(privacy metrics.PrivacyLabels) {
 }
@@ -495,6 +588,10 @@ func (me *NilMetricsEngine) RecordRequestPrivacy(privacy metrics.PrivacyLabels)
 func (me *NilMetricsEngine) RecordAdapterBuyerUIDScrubbed(adapter openrtb_ext.BidderName) {
 }
 
+// RecordAdapterNonBid as a noop
+func (me *NilMetricsEngine) RecordAdapterNonBid(labels metrics.AdapterLabels, reason int) {
+}
+
 // RecordAdapterGDPRRequestBlocked as a noop
 func (me *NilMetricsEngine) RecordAdapterGDPRRequestBlocked(adapter openrtb_ext.BidderName) {
 }
@@ -39,6 +39,27 @@ func TestGoMetricsEngine(t *testing.T) {
 	}
 }
 
+func TestDashboardMetricsEngine(t *testing.T) {
+	cfg := mainConfig.Configuration{}
+	cfg.Metrics.Dashboard = mainConfig.DashboardMetrics{Enabled: true, WindowSeconds: 300, MaxAccounts: 10}
+	testEngine := NewMetricsEngine(&cfg, openrtb_ext.CoreBidderNames(), nil, modulesStages)
+	if testEngine.Dashboard == nil {
+		t.Fatal("Expected a dashboard recorder, but didn't get it")
+	}
+
+	labels := metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus, PubID: "test1", AdapterBids: metrics.AdapterBidPresent}
+	testEngine.RecordAdapterRequest(labels)
+	testEngine.RecordAdapterTime(labels, 20*time.Millisecond)
+	testEngine.RecordAdapterPrice(labels, 2)
+	testEngine.RecordAdapterNonBid(labels, 301)
+	testEngine.RecordRequest(metrics.Labels{})
+
+	report := testEngine.Dashboard.Report()
+	if len(report.Bidders) != 1 || report.Bidders[0].Requests != 1 || report.Bidders[0].AverageCPM != 2 || report.Bidders[0].NonBids[301] != 1 {
+		t.Errorf("Unexpected dashboard bidders %+v", report.Bidders)
+	}
+}
+
 func TestMultiMetricsEngine(t *testing.T) {
 	cfg := mainConfig.Configuration{}
 	cfg.Metrics.Influxdb.Host = "localhost"
@@ -0,0 +1,330 @@
+// Package dashboard keeps in memory the recent stats of each bidder and account, for a quick view of their health
+// from the admin server.
+//
+// The stats cover a rolling window split in windowParts parts. A part is reset when the window moves past it, so
+// the memory used doesn't depend on the traffic. The bidders are spread over shards with their own lock, so the
+// requests of different bidders don't wait on each other.
+package dashboard
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+)
+
+const (
+	windowParts = 30
+	shardCount  = 16
+	// otherAccounts adds up the accounts over the max accounts of a window part.
+	otherAccounts = "(other)"
+)
+
+// latencyBounds are the upper bounds of the latency histogram buckets, in milliseconds. The last bucket has
+// no upper bound.
+var latencyBounds = [...]float64{5, 10, 20, 30, 50, 75, 100, 150, 200, 300, 400, 500, 750, 1000, 1500, 2000, 3000, 5000}
+
+// Recorder records the adapter metrics used by the dashboard.
+type Recorder struct {
+	time        timeutil.Time
+	window      time.Duration
+	partLength  time.Duration
+	maxAccounts int
+	shards      [shardCount]shard
+}
+
+// shard holds the stats of the bidders hashed to it, and of the accounts of their requests.
+type shard struct {
+	mutex sync.Mutex
+	parts [windowParts]windowPart
+}
+
+type windowPart struct {
+	start    time.Time
+	bidders  map[string]*stats
+	accounts map[string]*stats
+}
+
+type stats struct {
+	requests     int
+	withBids     int
+	timeouts     int
+	errors       map[metrics.AdapterError]int
+	latencies    [len(latencyBounds) + 1]int
+	maxLatency   float64
+	priceSum     float64
+	prices       int
+	nonBidCounts map[int]int
+}
+
+// NewRecorder returns a Recorder keeping the stats over the window of the config.
+func NewRecorder(cfg config.DashboardMetrics, timeSource timeutil.Time) *Recorder {
+	window := time.Duration(cfg.WindowSeconds) * time.Second
+	return &Recorder{
+		time:        timeSource,
+		window:      window,
+		partLength:  window / windowParts,
+		maxAccounts: cfg.MaxAccounts,
+	}
+}
+
+// RecordAdapterRequest counts the request, whether it got bids and its errors.
+func (r *Recorder) RecordAdapterRequest(labels metrics.AdapterLabels) {
+	r.record(labels, func(s *stats) {
+		s.requests++
+		if labels.AdapterBids == metrics.AdapterBidPresent {
+			s.withBids++
+		}
+		if _, ok := labels.AdapterErrors[metrics.AdapterErrorTimeout]; ok {
+			s.timeouts++
+		}
+		for adapterErr := range labels.AdapterErrors {
+			if s.errors == nil {
+				s.errors = make(map[metrics.AdapterError]int)
+			}
+			s.errors[adapterErr]++
+		}
+	})
+}
+
+// RecordAdapterTime adds the response time of the request to the latency histogram.
+func (r *Recorder) RecordAdapterTime(labels metrics.AdapterLabels, length time.Duration) {
+	latency := float64(length) / float64(time.Millisecond)
+	bucket := sort.SearchFloat64s(latencyBounds[:], latency)
+	r.record(labels, func(s *stats) {
+		s.latencies[bucket]++
+		s.maxLatency = math.Max(s.maxLatency, latency)
+	})
+}
+
+// RecordAdapterPrice adds the CPM of a bid to the average.
+func (r *Recorder) RecordAdapterPrice(labels metrics.AdapterLabels, cpm float64) {
+	r.record(labels, func(s *stats) {
+		s.priceSum += cpm
+		s.prices++
+	})
+}
+
+// RecordAdapterNonBid counts a seat non bid with its status code.
+func (r *Recorder) RecordAdapterNonBid(labels metrics.AdapterLabels, reason int) {
+	r.record(labels, func(s *stats) {
+		if s.nonBidCounts == nil {
+			s.nonBidCounts = make(map[int]int)
+		}
+		s.nonBidCounts[reason]++
+	})
+}
+
+// record updates the stats of the bidder and of the account of the labels in the current part of the window of
+// the shard of the bidder.
+func (r *Recorder) record(labels metrics.AdapterLabels, update func(s *stats)) {
+	now := r.time.Now()
+	bidder := strings.ToLower(string(labels.Adapter))
+	shard := &r.shards[shardOf(bidder)]
+
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	part := shard.part(now, r.partLength)
+	update(statsOf(part.bidders, bidder))
+
+	account := labels.PubID
+	if account == "" {
+		account = metrics.PublisherUnknown
+	}
+	if _, ok := part.accounts[account]; !ok && len(part.accounts) >= r.maxAccounts {
+		account = otherAccounts
+	}
+	update(statsOf(part.accounts, account))
+}
+
+func shardOf(bidder string) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(bidder))
+	return hash.Sum32() % shardCount
+}
+
+// part returns the part of the window holding the time, after resetting it if it holds an older time.
+func (s *shard) part(now time.Time, partLength time.Duration) *windowPart {
+	start := now.Truncate(partLength)
+	part := &s.parts[int(start.UnixNano()/int64(partLength))%windowParts]
+	if !part.start.Equal(start) {
+		*part = windowPart{
+			start:    start,
+			bidders:  make(map[string]*stats),
+			accounts: make(map[string]*stats),
+		}
+	}
+	return part
+}
+
+func statsOf(statsByName map[string]*stats, name string) *stats {
+	s, ok := statsByName[name]
+	if !ok {
+		s = &stats{}
+		statsByName[name] = s
+	}
+	return s
+}
+
+func (s *stats) add(other *stats) {
+	s.requests += other.requests
+	s.withBids += other.withBids
+	s.timeouts += other.timeouts
+	for adapterErr, count := range other.errors {
+		if s.errors == nil {
+			s.errors = make(map[metrics.AdapterError]int)
+		}
+		s.errors[adapterErr] += count
+	}
+	for i, count := range other.latencies {
+		s.latencies[i] += count
+	}
+	s.maxLatency = math.Max(s.maxLatency, other.maxLatency)
+	s.priceSum += other.priceSum
+	s.prices += other.prices
+	for reason, count := range other.nonBidCounts {
+		if s.nonBidCounts == nil {
+			s.nonBidCounts = make(map[int]int)
+		}
+		s.nonBidCounts[reason] += count
+	}
+}
+
+// Report is the stats of the bidders and accounts over the window, sorted by decreasing number of requests.
+type Report struct {
+	WindowSeconds int       `json:"window_seconds"`
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	Bidders       []Stats   `json:"bidders"`
+	Accounts      []Stats   `json:"accounts"`
+}
+
+// Stats is the stats of a bidder or an account over the window. The rates are between 0 and 1.
+type Stats struct {
+	Name        string         `json:"name"`
+	Requests    int            `json:"requests"`
+	BidRate     float64        `json:"bid_rate"`
+	TimeoutRate float64        `json:"timeout_rate"`
+	Errors      map[string]int `json:"errors,omitempty"`
+	LatencyP50  float64        `json:"latency_p50_ms"`
+	LatencyP95  float64        `json:"latency_p95_ms"`
+	LatencyP99  float64        `json:"latency_p99_ms"`
+	Bids        int            `json:"bids"`
+	AverageCPM  float64        `json:"average_cpm"`
+	// NonBids counts the seat non bids by status code.
+	NonBids map[int]int `json:"nonbids,omitempty"`
+}
+
+// Report returns the stats over the window ending now.
+func (r *Recorder) Report() Report {
+	now := r.time.Now()
+	// the current part is partly elapsed, so the window starts within the oldest part
+	from := now.Truncate(r.partLength).Add(r.partLength - r.window)
+	bidders := make(map[string]*stats)
+	accounts := make(map[string]*stats)
+
+	for i := range r.shards {
+		shard := &r.shards[i]
+		shard.mutex.Lock()
+		for j := range shard.parts {
+			part := &shard.parts[j]
+			if part.start.Before(from) || part.start.After(now) {
+				continue
+			}
+			for name, s := range part.bidders {
+				statsOf(bidders, name).add(s)
+			}
+			for name, s := range part.accounts {
+				statsOf(accounts, name).add(s)
+			}
+		}
+		shard.mutex.Unlock()
+	}
+
+	return Report{
+		WindowSeconds: int(r.window / time.Second),
+		From:          from,
+		To:            now,
+		Bidders:       sortedStats(bidders),
+		Accounts:      sortedStats(accounts),
+	}
+}
+
+func sortedStats(statsByName map[string]*stats) []Stats {
+	sorted := make([]Stats, 0, len(statsByName))
+	for name, s := range statsByName {
+		sorted = append(sorted, s.report(name))
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Requests != sorted[j].Requests {
+			return sorted[i].Requests > sorted[j].Requests
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+func (s *stats) report(name string) Stats {
+	report := Stats{
+		Name:       name,
+		Requests:   s.requests,
+		LatencyP50: s.percentile(0.50),
+		LatencyP95: s.percentile(0.95),
+		LatencyP99: s.percentile(0.99),
+		Bids:       s.prices,
+		NonBids:    s.nonBidCounts,
+	}
+	if s.requests > 0 {
+		report.BidRate = float64(s.withBids) / float64(s.requests)
+		report.TimeoutRate = float64(s.timeouts) / float64(s.requests)
+	}
+	if s.prices > 0 {
+		report.AverageCPM = s.priceSum / float64(s.prices)
+	}
+	if len(s.errors) > 0 {
+		report.Errors = make(map[string]int, len(s.errors))
+		for adapterErr, count := range s.errors {
+			report.Errors[string(adapterErr)] = count
+		}
+	}
+	return report
+}
+
+// percentile estimates the latency percentile from the histogram, assuming the latencies are evenly spread
+// within their bucket. The last bucket is capped by the max latency.
+func (s *stats) percentile(p float64) float64 {
+	total := 0
+	for _, count := range s.latencies {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := p * float64(total)
+	seen := 0
+	for i, count := range s.latencies {
+		if count == 0 || float64(seen+count) < rank {
+			seen += count
+			continue
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = latencyBounds[i-1]
+		}
+		upper := s.maxLatency
+		if i < len(latencyBounds) {
+			upper = math.Min(latencyBounds[i], s.maxLatency)
+		}
+		value := lower + (upper-lower)*(rank-float64(seen))/float64(count)
+		return math.Round(value*10) / 10
+	}
+	return s.maxLatency
+}
@@ -0,0 +1,186 @@
+package dashboard
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTime struct {
+	time time.Time
+}
+
+func (ft *fakeTime) Now() time.Time {
+	return ft.time
+}
+
+func newTestRecorder(maxAccounts int) (*Recorder, *fakeTime) {
+	now := &fakeTime{time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	return NewRecorder(config.DashboardMetrics{Enabled: true, WindowSeconds: 300, MaxAccounts: maxAccounts}, now), now
+}
+
+func TestReport(t *testing.T) {
+	recorder, now := newTestRecorder(10)
+	appnexus := metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus, PubID: "acct1"}
+	rubicon := metrics.AdapterLabels{Adapter: openrtb_ext.BidderRubicon, PubID: "acct2"}
+
+	for i := 0; i < 4; i++ {
+		labels := appnexus
+		labels.AdapterBids = metrics.AdapterBidPresent
+		if i == 3 {
+			labels.AdapterBids = metrics.AdapterBidNone
+			labels.AdapterErrors = map[metrics.AdapterError]struct{}{metrics.AdapterErrorTimeout: {}}
+		}
+		recorder.RecordAdapterRequest(labels)
+		recorder.RecordAdapterTime(appnexus, time.Duration(10*(i+1))*time.Millisecond)
+	}
+	recorder.RecordAdapterPrice(appnexus, 1)
+	recorder.RecordAdapterPrice(appnexus, 2)
+	recorder.RecordAdapterNonBid(appnexus, 301)
+	recorder.RecordAdapterNonBid(appnexus, 301)
+
+	now.time = now.time.Add(time.Minute)
+	recorder.RecordAdapterRequest(metrics.AdapterLabels{Adapter: openrtb_ext.BidderRubicon, PubID: "acct2", AdapterErrors: map[metrics.AdapterError]struct{}{metrics.AdapterErrorBadServerResponse: {}}})
+	recorder.RecordAdapterTime(rubicon, 600*time.Millisecond)
+
+	report := recorder.Report()
+
+	assert.Equal(t, 300, report.WindowSeconds)
+	assert.Equal(t, now.time, report.To)
+	require.Len(t, report.Bidders, 2)
+	assert.Equal(t, Stats{
+		Name:        "appnexus",
+		Requests:    4,
+		BidRate:     0.75,
+		TimeoutRate: 0.25,
+		Errors:      map[string]int{"timeout": 1},
+		LatencyP50:  20,
+		LatencyP95:  38,
+		LatencyP99:  39.6,
+		Bids:        2,
+		AverageCPM:  1.5,
+		NonBids:     map[int]int{301: 2},
+	}, report.Bidders[0])
+	assert.Equal(t, Stats{
+		Name:       "rubicon",
+		Requests:   1,
+		Errors:     map[string]int{"badserverresponse": 1},
+		LatencyP50: 550,
+		LatencyP95: 595,
+		LatencyP99: 599,
+	}, report.Bidders[1])
+
+	require.Len(t, report.Accounts, 2)
+	assert.Equal(t, "acct1", report.Accounts[0].Name)
+	assert.Equal(t, 4, report.Accounts[0].Requests)
+	assert.Equal(t, "acct2", report.Accounts[1].Name)
+}
+
+func TestReportRollingWindow(t *testing.T) {
+	recorder, now := newTestRecorder(10)
+	labels := metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus, PubID: "acct"}
+
+	recorder.RecordAdapterRequest(labels)
+	now.time = now.time.Add(4 * time.Minute)
+	recorder.RecordAdapterRequest(labels)
+	assert.Equal(t, 2, recorder.Report().Bidders[0].Requests)
+
+	now.time = now.time.Add(time.Minute + time.Second)
+	assert.Equal(t, 1, recorder.Report().Bidders[0].Requests, "the first request left the window")
+
+	// the part of the first request is reused
+	now.time = now.time.Add(5*time.Minute - time.Second)
+	recorder.RecordAdapterRequest(labels)
+	report := recorder.Report()
+	require.Len(t, report.Bidders, 1)
+	assert.Equal(t, 1, report.Bidders[0].Requests)
+}
+
+func TestMaxAccounts(t *testing.T) {
+	recorder, _ := newTestRecorder(2)
+
+	for _, account := range []string{"acct1", "acct2", "acct3", "acct4", "acct1", ""} {
+		recorder.RecordAdapterRequest(metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus, PubID: account})
+	}
+
+	report := recorder.Report()
+	assert.Equal(t, []Stats{
+		{Name: "(other)", Requests: 3},
+		{Name: "acct1", Requests: 2},
+		{Name: "acct2", Requests: 1},
+	}, report.Accounts)
+}
+
+func TestRecordConcurrently(t *testing.T) {
+	recorder, _ := newTestRecorder(10)
+	bidders := []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus, openrtb_ext.BidderRubicon, openrtb_ext.BidderPubmatic, openrtb_ext.BidderOpenx}
+
+	var wg sync.WaitGroup
+	for _, bidder := range bidders {
+		wg.Add(1)
+		go func(labels metrics.AdapterLabels) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				recorder.RecordAdapterRequest(labels)
+			}
+		}(metrics.AdapterLabels{Adapter: bidder, PubID: "acct"})
+	}
+	wg.Wait()
+
+	report := recorder.Report()
+	require.Len(t, report.Bidders, len(bidders))
+	for _, bidderStats := range report.Bidders {
+		assert.Equal(t, 100, bidderStats.Requests, bidderStats.Name)
+	}
+	assert.Equal(t, []Stats{{Name: "acct", Requests: 400}}, report.Accounts, "the account stats of the shards are added up")
+}
+
+func TestPercentile(t *testing.T) {
+	testCases := []struct {
+		description string
+		latencies   []time.Duration
+		expectedP50 float64
+		expectedP99 float64
+	}{
+		{
+			description: "empty",
+		},
+		{
+			description: "one",
+			latencies:   []time.Duration{7 * time.Millisecond},
+			expectedP50: 6,
+			expectedP99: 7,
+		},
+		{
+			description: "over_the_last_bound",
+			latencies:   []time.Duration{6 * time.Second, 8 * time.Second},
+			expectedP50: 6500,
+			expectedP99: 7970,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			recorder, _ := newTestRecorder(10)
+			for _, latency := range tc.latencies {
+				recorder.RecordAdapterTime(metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus}, latency)
+			}
+			var s stats
+			shard := &recorder.shards[shardOf("appnexus")]
+			for i := range shard.parts {
+				if bidder, ok := shard.parts[i].bidders["appnexus"]; ok {
+					s.add(bidder)
+				}
+			}
+
+			assert.Equal(t, tc.expectedP50, s.percentile(0.5))
+			assert.Equal(t, tc.expectedP99, s.percentile(0.99))
+		})
+	}
+}
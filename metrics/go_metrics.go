@@ -28,6 +28,7 @@ type Metrics struct {
 	PrebidCacheRequestTimerError   metrics.Timer
 	StoredDataFetchTimer           map[StoredDataType]map[StoredDataFetchType]metrics.Timer
 	StoredDataErrorMeter           map[StoredDataType]map[StoredDataError]metrics.Meter
+	StoredDataEventLagTimer        map[StoredDataType]metrics.Timer
 	StoredReqCacheMeter            map[CacheResult]metrics.Meter
 	StoredImpCacheMeter            map[CacheResult]metrics.Meter
 	AccountCacheMeter              map[CacheResult]metrics.Meter
@@ -56,6 +57,14 @@ type Metrics struct {
 	TimeoutNotificationSuccess metrics.Meter
 	TimeoutNotificationFailure metrics.Meter
 
+	EventSignatureFailureMeter map[EventSignatureStatus]metrics.Meter
+
+	RateLimitMeter map[RequestType]map[RateLimitResult]metrics.Meter
+
+	MirrorMeter map[RequestType]map[MirrorResult]metrics.Meter
+
+	AccountConfigInvalidMeter map[AccountConfigSection]metrics.Meter
+
 	// TCF adaption metrics
 	PrivacyCCPARequest       metrics.Meter
 	PrivacyCCPARequestOptOut metrics.Meter
@@ -101,6 +110,7 @@ type AdapterMetrics struct {
 	ConnWaitTime       metrics.Timer
 	BuyerUIDScrubbed   metrics.Meter
 	GDPRRequestBlocked metrics.Meter
+	NonBidsMeter       metrics.Meter
 
 	BidValidationCreativeSizeErrorMeter metrics.Meter
 	BidValidationCreativeSizeWarnMeter  metrics.Meter
@@ -123,6 +133,7 @@ type accountMetrics struct {
 	adapterMetrics       map[string]*AdapterMetrics
 	moduleMetrics        map[string]*ModuleMetrics
 	storedResponsesMeter metrics.Meter
+	rateLimitedMeter     metrics.Meter
 
 	bidValidationCreativeSizeMeter     metrics.Meter
 	bidValidationCreativeSizeWarnMeter metrics.Meter
@@ -169,6 +180,7 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []string, disabledMetr
 		PrebidCacheRequestTimerSuccess: blankTimer,
 		PrebidCacheRequestTimerError:   blankTimer,
 		StoredDataFetchTimer:           make(map[StoredDataType]map[StoredDataFetchType]metrics.Timer),
+		StoredDataEventLagTimer:        make(map[StoredDataType]metrics.Timer),
 		StoredDataErrorMeter:           make(map[StoredDataType]map[StoredDataError]metrics.Meter),
 		StoredReqCacheMeter:            make(map[CacheResult]metrics.Meter),
 		StoredImpCacheMeter:            make(map[CacheResult]metrics.Meter),
@@ -190,6 +202,14 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []string, disabledMetr
 		TimeoutNotificationSuccess: blankMeter,
 		TimeoutNotificationFailure: blankMeter,
 
+		EventSignatureFailureMeter: make(map[EventSignatureStatus]metrics.Meter),
+
+		RateLimitMeter: make(map[RequestType]map[RateLimitResult]metrics.Meter),
+
+		MirrorMeter: make(map[RequestType]map[MirrorResult]metrics.Meter),
+
+		AccountConfigInvalidMeter: make(map[AccountConfigSection]metrics.Meter),
+
 		PrivacyCCPARequest:       blankMeter,
 		PrivacyCCPARequestOptOut: blankMeter,
 		PrivacyCOPPARequest:      blankMeter,
@@ -226,6 +246,18 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []string, disabledMetr
 		for _, s := range RequestStatuses() {
 			newMetrics.RequestStatuses[t][s] = blankMeter
 		}
+		newMetrics.RateLimitMeter[t] = make(map[RateLimitResult]metrics.Meter)
+		for _, r := range RateLimitResults() {
+			newMetrics.RateLimitMeter[t][r] = blankMeter
+		}
+		newMetrics.MirrorMeter[t] = make(map[MirrorResult]metrics.Meter)
+		for _, r := range MirrorResults() {
+			newMetrics.MirrorMeter[t][r] = blankMeter
+		}
+	}
+
+	for _, s := range AccountConfigSections() {
+		newMetrics.AccountConfigInvalidMeter[s] = blankMeter
 	}
 
 	for _, c := range CacheResults() {
@@ -241,6 +273,7 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []string, disabledMetr
 	for _, dt := range StoredDataTypes() {
 		newMetrics.StoredDataFetchTimer[dt] = make(map[StoredDataFetchType]metrics.Timer)
 		newMetrics.StoredDataErrorMeter[dt] = make(map[StoredDataError]metrics.Meter)
+		newMetrics.StoredDataEventLagTimer[dt] = blankTimer
 		for _, ft := range StoredDataFetchTypes() {
 			newMetrics.StoredDataFetchTimer[dt][ft] = blankTimer
 		}
@@ -312,6 +345,7 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 			meterName := fmt.Sprintf("stored_%s_error.%s", string(dt), string(e))
 			newMetrics.StoredDataErrorMeter[dt][e] = metrics.GetOrRegisterMeter(meterName, registry)
 		}
+		newMetrics.StoredDataEventLagTimer[dt] = metrics.GetOrRegisterTimer(fmt.Sprintf("stored_%s_event_lag", string(dt)), registry)
 	}
 
 	newMetrics.AmpNoCookieMeter = metrics.GetOrRegisterMeter("amp_no_cookie_requests", registry)
@@ -359,6 +393,20 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 
 	newMetrics.TimeoutNotificationSuccess = metrics.GetOrRegisterMeter("timeout_notification.ok", registry)
 	newMetrics.TimeoutNotificationFailure = metrics.GetOrRegisterMeter("timeout_notification.failed", registry)
+	for _, s := range EventSignatureStatuses() {
+		newMetrics.EventSignatureFailureMeter[s] = metrics.GetOrRegisterMeter(fmt.Sprintf("event_signature_failures.%s", s), registry)
+	}
+	for _, t := range RequestTypes() {
+		for _, r := range RateLimitResults() {
+			newMetrics.RateLimitMeter[t][r] = metrics.GetOrRegisterMeter(fmt.Sprintf("rate_limit.%s.%s", t, r), registry)
+		}
+		for _, r := range MirrorResults() {
+			newMetrics.MirrorMeter[t][r] = metrics.GetOrRegisterMeter(fmt.Sprintf("mirror.%s.%s", t, r), registry)
+		}
+	}
+	for _, s := range AccountConfigSections() {
+		newMetrics.AccountConfigInvalidMeter[s] = metrics.GetOrRegisterMeter(fmt.Sprintf("account_config_invalid.%s", s), registry)
+	}
 
 	newMetrics.PrivacyCCPARequest = metrics.GetOrRegisterMeter("privacy.request.ccpa.specified", registry)
 	newMetrics.PrivacyCCPARequestOptOut = metrics.GetOrRegisterMeter("privacy.request.ccpa.opt-out", registry)
@@ -413,6 +461,9 @@ func makeBlankAdapterMetrics(disabledMetrics config.DisabledMetrics) *AdapterMet
 	if !disabledMetrics.AdapterGDPRRequestBlocked {
 		newAdapter.GDPRRequestBlocked = blankMeter
 	}
+	if !disabledMetrics.AdapterNonBids {
+		newAdapter.NonBidsMeter = blankMeter
+	}
 	for _, err := range AdapterErrors() {
 		newAdapter.ErrorMeters[err] = blankMeter
 	}
@@ -488,6 +539,7 @@ func registerAdapterMetrics(registry metrics.Registry, adapterOrAccount string,
 		am.BidsReceivedMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.bids_received", adapterOrAccount, exchange), registry)
 	}
 	am.PanicMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.requests.panic", adapterOrAccount, exchange), registry)
+	am.NonBidsMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.nonbids", adapterOrAccount, exchange), registry)
 	am.BuyerUIDScrubbed = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.buyeruid_scrubbed", adapterOrAccount, exchange), registry)
 	am.GDPRRequestBlocked = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.gdpr_request_blocked", adapterOrAccount, exchange), registry)
 
@@ -560,6 +612,7 @@ func (me *Metrics) getAccountMetrics(id string) *accountMetrics {
 	am.adapterMetrics = make(map[string]*AdapterMetrics, len(me.exchanges))
 	am.moduleMetrics = make(map[string]*ModuleMetrics)
 	am.storedResponsesMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("account.%s.stored_responses", id), me.MetricsRegistry)
+	am.rateLimitedMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("account.%s.rate_limited", id), me.MetricsRegistry)
 	if !me.MetricsDisabled.AccountAdapterDetails {
 		for _, a := range me.exchanges {
 			am.adapterMetrics[a] = makeBlankAdapterMetrics(me.MetricsDisabled)
@@ -679,6 +732,11 @@ func (me *Metrics) RecordStoredDataError(labels StoredDataLabels) {
 	me.StoredDataErrorMeter[labels.DataType][labels.Error].Mark(1)
 }
 
+// RecordStoredDataEventLag implements a part of the MetricsEngine interface
+func (me *Metrics) RecordStoredDataEventLag(labels StoredDataLabels, lag time.Duration) {
+	me.StoredDataEventLagTimer[labels.DataType].Update(lag)
+}
+
 // RecordAdapterPanic implements a part of the MetricsEngine interface
 func (me *Metrics) RecordAdapterPanic(labels AdapterLabels) {
 	adapterStr := string(labels.Adapter)
@@ -906,6 +964,38 @@ func (me *Metrics) RecordTimeoutNotice(success bool) {
 	}
 }
 
+// RecordEventSignatureFailure implements a part of the MetricsEngine interface. Records an event URL which failed signature verification
+func (me *Metrics) RecordEventSignatureFailure(status EventSignatureStatus) {
+	if meter, exists := me.EventSignatureFailureMeter[status]; exists {
+		meter.Mark(1)
+	}
+}
+
+// RecordRateLimit implements a part of the MetricsEngine interface. Records a request limited by its account rate limits,
+// or allowed because they could not be checked
+func (me *Metrics) RecordRateLimit(labels RateLimitLabels) {
+	if meter, exists := me.RateLimitMeter[labels.RType][labels.Result]; exists {
+		meter.Mark(1)
+	}
+	if labels.Result == RateLimitLimited && labels.PubID != PublisherUnknown {
+		me.getAccountMetrics(labels.PubID).rateLimitedMeter.Mark(1)
+	}
+}
+
+// RecordMirroredRequest implements a part of the MetricsEngine interface. Records the result of the mirroring of a sampled request
+func (me *Metrics) RecordMirroredRequest(requestType RequestType, result MirrorResult) {
+	if meter, exists := me.MirrorMeter[requestType][result]; exists {
+		meter.Mark(1)
+	}
+}
+
+// RecordAccountConfigInvalid implements a part of the MetricsEngine interface. Records an invalid section of an account config
+func (me *Metrics) RecordAccountConfigInvalid(section AccountConfigSection) {
+	if meter, exists := me.AccountConfigInvalidMeter[section]; exists {
+		meter.Mark(1)
+	}
+}
+
 func (me *Metrics) RecordRequestPrivacy(privacy PrivacyLabels) {
 	if privacy.CCPAProvided {
 		me.PrivacyCCPARequest.Mark(1)
@@ -961,6 +1051,23 @@ func (me *Metrics) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.Bidde
 	am.GDPRRequestBlocked.Mark(1)
 }
 
+// RecordAdapterNonBid implements a part of the MetricsEngine interface. The go-metrics engine doesn't break down
+// the non bids by reason.
+func (me *Metrics) RecordAdapterNonBid(labels AdapterLabels, reason int) {
+	adapterStr := string(labels.Adapter)
+	if me.MetricsDisabled.AdapterNonBids {
+		return
+	}
+
+	am, ok := me.AdapterMetrics[strings.ToLower(adapterStr)]
+	if !ok {
+		glog.Errorf("Trying to log adapter non bid metric for %s: adapter not found", adapterStr)
+		return
+	}
+
+	am.NonBidsMeter.Mark(1)
+}
+
 func (me *Metrics) RecordAdsCertReq(success bool) {
 	if success {
 		me.AdsCertRequestsSuccess.Mark(1)
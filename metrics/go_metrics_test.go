@@ -29,12 +29,14 @@ func TestNewMetrics(t *testing.T) {
 	ensureContains(t, registry, "cookie_sync_requests.bad_request", m.CookieSyncStatusMeter[CookieSyncBadRequest])
 	ensureContains(t, registry, "cookie_sync_requests.opt_out", m.CookieSyncStatusMeter[CookieSyncOptOut])
 	ensureContains(t, registry, "cookie_sync_requests.gdpr_blocked_host_cookie", m.CookieSyncStatusMeter[CookieSyncGDPRHostCookieBlocked])
+	ensureContains(t, registry, "cookie_sync_requests.rejected_by_hook", m.CookieSyncStatusMeter[CookieSyncRejectedByHook])
 	ensureContains(t, registry, "setuid_requests", m.SetUidMeter)
 	ensureContains(t, registry, "setuid_requests.ok", m.SetUidStatusMeter[SetUidOK])
 	ensureContains(t, registry, "setuid_requests.bad_request", m.SetUidStatusMeter[SetUidBadRequest])
 	ensureContains(t, registry, "setuid_requests.opt_out", m.SetUidStatusMeter[SetUidOptOut])
 	ensureContains(t, registry, "setuid_requests.gdpr_blocked_host_cookie", m.SetUidStatusMeter[SetUidGDPRHostCookieBlocked])
 	ensureContains(t, registry, "setuid_requests.syncer_unknown", m.SetUidStatusMeter[SetUidSyncerUnknown])
+	ensureContains(t, registry, "setuid_requests.rejected_by_hook", m.SetUidStatusMeter[SetUidRejectedByHook])
 	ensureContains(t, registry, "stored_responses", m.StoredResponsesMeter)
 
 	ensureContains(t, registry, "prebid_cache_request_time.ok", m.PrebidCacheRequestTimerSuccess)
@@ -673,6 +675,16 @@ func TestRecordStoredDataFetchTime(t *testing.T) {
 	}
 }
 
+func TestRecordStoredDataEventLag(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("Foo"), openrtb_ext.BidderName("Bar")}, config.DisabledMetrics{AccountAdapterDetails: true}, nil, nil)
+	m.RecordStoredDataEventLag(StoredDataLabels{DataType: AMPDataType}, time.Duration(500))
+
+	assert.Equal(t, int64(1), m.StoredDataEventLagTimer[AMPDataType].Count())
+	assert.Equal(t, int64(500), m.StoredDataEventLagTimer[AMPDataType].Sum())
+	assert.Equal(t, int64(0), m.StoredDataEventLagTimer[RequestDataType].Count())
+}
+
 func TestRecordStoredDataError(t *testing.T) {
 	tests := []struct {
 		description string
@@ -996,6 +1008,100 @@ func TestStoredResponses(t *testing.T) {
 	}
 }
 
+func TestRecordAdapterNonBid(t *testing.T) {
+	tests := []struct {
+		name            string
+		metricsDisabled bool
+		expectedCount   int64
+	}{
+		{
+			name:            "enabled",
+			metricsDisabled: false,
+			expectedCount:   2,
+		},
+		{
+			name:            "disabled",
+			metricsDisabled: true,
+			expectedCount:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := metrics.NewRegistry()
+			m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("AnyName")}, config.DisabledMetrics{AdapterNonBids: tt.metricsDisabled}, nil, nil)
+
+			m.RecordAdapterNonBid(AdapterLabels{Adapter: openrtb_ext.BidderName("anyName")}, 301)
+			m.RecordAdapterNonBid(AdapterLabels{Adapter: openrtb_ext.BidderName("anyName")}, 101)
+			m.RecordAdapterNonBid(AdapterLabels{Adapter: openrtb_ext.BidderName("unknown")}, 101)
+
+			assert.Equal(t, tt.expectedCount, m.AdapterMetrics["anyname"].NonBidsMeter.Count())
+		})
+	}
+}
+
+func TestRecordRateLimit(t *testing.T) {
+	testCases := []struct {
+		description                 string
+		givenLabels                 RateLimitLabels
+		expectedRateLimitCount      int64
+		expectedAccountLimitedCount int64
+	}{
+		{
+			description:                 "Limited request of a known account, both metrics should be updated",
+			givenLabels:                 RateLimitLabels{RType: ReqTypeORTB2Web, PubID: "acct-id", Result: RateLimitLimited},
+			expectedRateLimitCount:      1,
+			expectedAccountLimitedCount: 1,
+		},
+		{
+			description:                 "Limited request of an unknown account, only the rate limit metric should be updated",
+			givenLabels:                 RateLimitLabels{RType: ReqTypeORTB2Web, PubID: PublisherUnknown, Result: RateLimitLimited},
+			expectedRateLimitCount:      1,
+			expectedAccountLimitedCount: 0,
+		},
+		{
+			description:                 "Backend error of a known account, only the rate limit metric should be updated",
+			givenLabels:                 RateLimitLabels{RType: ReqTypeORTB2Web, PubID: "acct-id", Result: RateLimitError},
+			expectedRateLimitCount:      1,
+			expectedAccountLimitedCount: 0,
+		},
+	}
+	for _, test := range testCases {
+		registry := metrics.NewRegistry()
+		m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("AnyName")}, config.DisabledMetrics{}, nil, nil)
+
+		m.RecordRateLimit(test.givenLabels)
+		am := m.getAccountMetrics(test.givenLabels.PubID)
+
+		assert.Equal(t, test.expectedRateLimitCount, m.RateLimitMeter[test.givenLabels.RType][test.givenLabels.Result].Count(), test.description)
+		assert.Equal(t, test.expectedAccountLimitedCount, am.rateLimitedMeter.Count(), test.description)
+	}
+}
+
+func TestRecordMirroredRequest(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("AnyName")}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordMirroredRequest(ReqTypeAMP, MirrorSent)
+	m.RecordMirroredRequest(ReqTypeAMP, MirrorDropped)
+	m.RecordMirroredRequest(ReqTypeAMP, MirrorDropped)
+
+	assert.Equal(t, int64(1), m.MirrorMeter[ReqTypeAMP][MirrorSent].Count())
+	assert.Equal(t, int64(2), m.MirrorMeter[ReqTypeAMP][MirrorDropped].Count())
+	assert.Equal(t, int64(0), m.MirrorMeter[ReqTypeAMP][MirrorFailed].Count())
+	assert.Equal(t, int64(2), registry.Get("mirror.amp.dropped").(metrics.Meter).Count())
+}
+
+func TestRecordAccountConfigInvalid(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("AnyName")}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordAccountConfigInvalid(AccountConfigPriceGranularityLadders)
+	m.RecordAccountConfigInvalid(AccountConfigPriceGranularityLadders)
+
+	assert.Equal(t, int64(2), m.AccountConfigInvalidMeter[AccountConfigPriceGranularityLadders].Count())
+	assert.Equal(t, int64(2), registry.Get("account_config_invalid.price_granularity_ladders").(metrics.Meter).Count())
+}
+
 func TestRecordAdsCertSignTime(t *testing.T) {
 	testCases := []struct {
 		description           string
@@ -69,6 +69,13 @@ type PrivacyLabels struct {
 	LMTEnforced    bool
 }
 
+// RateLimitLabels defines metric labels describing the result of an account rate limit check.
+type RateLimitLabels struct {
+	RType  RequestType
+	PubID  string // exchange specific ID, so we cannot compile in values
+	Result RateLimitResult
+}
+
 type ModuleLabels struct {
 	Module    string
 	Stage     string
@@ -120,12 +127,14 @@ type StoredDataLabels struct {
 type StoredDataError string
 
 const (
+	StoredDataErrorMalformed StoredDataError = "malformed"
 	StoredDataErrorNetwork   StoredDataError = "network"
 	StoredDataErrorUndefined StoredDataError = "undefined"
 )
 
 func StoredDataErrors() []StoredDataError {
 	return []StoredDataError{
+		StoredDataErrorMalformed,
 		StoredDataErrorNetwork,
 		StoredDataErrorUndefined,
 	}
@@ -237,6 +246,7 @@ const (
 	RequestStatusBlockedApp       RequestStatus = "blockedapp"
 	RequestStatusQueueTimeout     RequestStatus = "queuetimeout"
 	RequestStatusAccountConfigErr RequestStatus = "acctconfigerr"
+	RequestStatusRateLimited      RequestStatus = "ratelimited"
 )
 
 func RequestStatuses() []RequestStatus {
@@ -248,6 +258,7 @@ func RequestStatuses() []RequestStatus {
 		RequestStatusBlockedApp,
 		RequestStatusQueueTimeout,
 		RequestStatusAccountConfigErr,
+		RequestStatusRateLimited,
 	}
 }
 
@@ -293,13 +304,28 @@ const (
 	// CacheMiss represents a cache miss i.e that key wasn't found in cache
 	// and had to be fetched from the backend
 	CacheMiss CacheResult = "miss"
+	// CacheNegativeHit represents a key cached as missing, which wasn't fetched from the backend
+	CacheNegativeHit CacheResult = "negative_hit"
+	// CacheStale represents a stale value served while it's refreshed in the background
+	CacheStale CacheResult = "stale"
+	// CacheStaleIfError represents a stale value served because it couldn't be fetched from the backend
+	CacheStaleIfError CacheResult = "stale_if_error"
+	// CacheRefresh represents a stale value refreshed in the background
+	CacheRefresh CacheResult = "refresh"
+	// CacheRefreshError represents a stale value which couldn't be refreshed in the background
+	CacheRefreshError CacheResult = "refresh_error"
 )
 
-// CacheResults returns possible cache results i.e. cache hit or miss
+// CacheResults returns possible cache results i.e. cache hit or miss, and the results of stale and missing values
 func CacheResults() []CacheResult {
 	return []CacheResult{
 		CacheHit,
 		CacheMiss,
+		CacheNegativeHit,
+		CacheStale,
+		CacheStaleIfError,
+		CacheRefresh,
+		CacheRefreshError,
 	}
 }
 
@@ -339,6 +365,7 @@ const (
 	CookieSyncAccountBlocked         CookieSyncStatus = "acct_blocked"
 	CookieSyncAccountConfigMalformed CookieSyncStatus = "acct_config_malformed"
 	CookieSyncAccountInvalid         CookieSyncStatus = "acct_invalid"
+	CookieSyncRejectedByHook         CookieSyncStatus = "rejected_by_hook"
 )
 
 // CookieSyncStatuses returns possible cookie sync statuses.
@@ -351,6 +378,7 @@ func CookieSyncStatuses() []CookieSyncStatus {
 		CookieSyncAccountBlocked,
 		CookieSyncAccountConfigMalformed,
 		CookieSyncAccountInvalid,
+		CookieSyncRejectedByHook,
 	}
 }
 
@@ -387,6 +415,7 @@ const (
 	SetUidAccountConfigMalformed SetUidStatus = "acct_config_malformed"
 	SetUidAccountInvalid         SetUidStatus = "acct_invalid"
 	SetUidSyncerUnknown          SetUidStatus = "syncer_unknown"
+	SetUidRejectedByHook         SetUidStatus = "rejected_by_hook"
 )
 
 // SetUidStatuses returns possible setuid statuses.
@@ -400,6 +429,87 @@ func SetUidStatuses() []SetUidStatus {
 		SetUidAccountConfigMalformed,
 		SetUidAccountInvalid,
 		SetUidSyncerUnknown,
+		SetUidRejectedByHook,
+	}
+}
+
+// EventSignatureStatus describes why the signature of an event URL could not be verified.
+type EventSignatureStatus string
+
+// /event signature failure labels
+const (
+	EventSignatureUnsigned EventSignatureStatus = "unsigned"
+	EventSignatureInvalid  EventSignatureStatus = "invalid"
+	EventSignatureExpired  EventSignatureStatus = "expired"
+)
+
+// EventSignatureStatuses returns possible event signature failure statuses.
+func EventSignatureStatuses() []EventSignatureStatus {
+	return []EventSignatureStatus{
+		EventSignatureUnsigned,
+		EventSignatureInvalid,
+		EventSignatureExpired,
+	}
+}
+
+// RateLimitResult is the result of an account rate limit check which did not simply allow the request.
+type RateLimitResult string
+
+const (
+	// RateLimitLimited is a request over the rate limits of its account
+	RateLimitLimited RateLimitResult = "limited"
+	// RateLimitError is a request allowed because the rate limiting backend failed
+	RateLimitError RateLimitResult = "error"
+)
+
+// RateLimitResults returns possible rate limit results.
+func RateLimitResults() []RateLimitResult {
+	return []RateLimitResult{
+		RateLimitLimited,
+		RateLimitError,
+	}
+}
+
+// MirrorResult is the result of the mirroring of a sampled request.
+type MirrorResult string
+
+const (
+	// MirrorSent is a request copied to the mirroring target
+	MirrorSent MirrorResult = "sent"
+	// MirrorDropped is a request dropped because the mirroring queue was full
+	MirrorDropped MirrorResult = "dropped"
+	// MirrorFailed is a request which could not be copied to the mirroring target
+	MirrorFailed MirrorResult = "failed"
+)
+
+// MirrorResults returns possible mirror results.
+func MirrorResults() []MirrorResult {
+	return []MirrorResult{
+		MirrorSent,
+		MirrorDropped,
+		MirrorFailed,
+	}
+}
+
+// AccountConfigSection is a section of the account config which is reset to its default when invalid.
+type AccountConfigSection string
+
+const (
+	AccountConfigPriceGranularityLadders AccountConfigSection = "price_granularity_ladders"
+	AccountConfigTargetingKeys           AccountConfigSection = "targeting_keys"
+	AccountConfigHooksExecutionPlan      AccountConfigSection = "hooks_execution_plan"
+	AccountConfigRateLimits              AccountConfigSection = "rate_limits"
+	AccountConfigMirroring               AccountConfigSection = "mirroring"
+)
+
+// AccountConfigSections returns possible account config sections.
+func AccountConfigSections() []AccountConfigSection {
+	return []AccountConfigSection{
+		AccountConfigPriceGranularityLadders,
+		AccountConfigTargetingKeys,
+		AccountConfigHooksExecutionPlan,
+		AccountConfigRateLimits,
+		AccountConfigMirroring,
 	}
 }
 
@@ -442,6 +552,8 @@ type MetricsEngine interface {
 	RecordAdapterBidReceived(labels AdapterLabels, bidType openrtb_ext.BidType, hasAdm bool)
 	RecordAdapterPrice(labels AdapterLabels, cpm float64)
 	RecordAdapterTime(labels AdapterLabels, length time.Duration)
+	// RecordAdapterNonBid records a seat non bid of the auction response, with its nonbid status code.
+	RecordAdapterNonBid(labels AdapterLabels, reason int)
 	RecordCookieSync(status CookieSyncStatus)
 	RecordSyncerRequest(key string, status SyncerCookieSyncStatus)
 	RecordSetUid(status SetUidStatus)
@@ -451,9 +563,18 @@ type MetricsEngine interface {
 	RecordAccountCacheResult(cacheResult CacheResult, inc int)
 	RecordStoredDataFetchTime(labels StoredDataLabels, length time.Duration)
 	RecordStoredDataError(labels StoredDataLabels)
+	// RecordStoredDataEventLag records the time between a change of stored data in the backend and its event.
+	RecordStoredDataEventLag(labels StoredDataLabels, lag time.Duration)
 	RecordPrebidCacheRequestTime(success bool, length time.Duration)
 	RecordRequestQueueTime(success bool, requestType RequestType, length time.Duration)
 	RecordTimeoutNotice(success bool)
+	RecordEventSignatureFailure(status EventSignatureStatus)
+	// RecordRateLimit records a request limited by the rate limits of its account, or allowed because they could not be checked.
+	RecordRateLimit(labels RateLimitLabels)
+	// RecordMirroredRequest records the result of the mirroring of a sampled request.
+	RecordMirroredRequest(requestType RequestType, result MirrorResult)
+	// RecordAccountConfigInvalid records an invalid section of an account config, reset to its default.
+	RecordAccountConfigInvalid(section AccountConfigSection)
 	RecordRequestPrivacy(privacy PrivacyLabels)
 	RecordAdapterBuyerUIDScrubbed(adapterName openrtb_ext.BidderName)
 	RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.BidderName)
@@ -52,6 +52,11 @@ func (me *MetricsEngineMock) RecordStoredDataError(labels StoredDataLabels) {
 	me.Called(labels)
 }
 
+// RecordStoredDataEventLag mock
+func (me *MetricsEngineMock) RecordStoredDataEventLag(labels StoredDataLabels, lag time.Duration) {
+	me.Called(labels, lag)
+}
+
 // RecordAdapterPanic mock
 func (me *MetricsEngineMock) RecordAdapterPanic(labels AdapterLabels) {
 	me.Called(labels)
@@ -151,6 +156,26 @@ func (me *MetricsEngineMock) RecordTimeoutNotice(success bool) {
 	me.Called(success)
 }
 
+// RecordEventSignatureFailure mock
+func (me *MetricsEngineMock) RecordEventSignatureFailure(status EventSignatureStatus) {
+	me.Called(status)
+}
+
+// RecordRateLimit mock
+func (me *MetricsEngineMock) RecordRateLimit(labels RateLimitLabels) {
+	me.Called(labels)
+}
+
+// RecordMirroredRequest mock
+func (me *MetricsEngineMock) RecordMirroredRequest(requestType RequestType, result MirrorResult) {
+	me.Called(requestType, result)
+}
+
+// RecordAccountConfigInvalid mock
+func (me *MetricsEngineMock) RecordAccountConfigInvalid(section AccountConfigSection) {
+	me.Called(section)
+}
+
 // RecordRequestPrivacy mock
 func (me *MetricsEngineMock) RecordRequestPrivacy(privacy PrivacyLabels) {
 	me.Called(privacy)
@@ -161,6 +186,11 @@ func (me *MetricsEngineMock) RecordAdapterBuyerUIDScrubbed(adapterName openrtb_e
 	me.Called(adapterName)
 }
 
+// RecordAdapterNonBid mock
+func (me *MetricsEngineMock) RecordAdapterNonBid(labels AdapterLabels, reason int) {
+	me.Called(labels, reason)
+}
+
 // RecordAdapterGDPRRequestBlocked mock
 func (me *MetricsEngineMock) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.BidderName) {
 	me.Called(adapterName)
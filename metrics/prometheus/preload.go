@@ -16,13 +16,18 @@ func preloadLabelValues(m *Metrics, syncerKeys []string, moduleStageNames map[st
 		connectionErrorValues     = []string{connectionAcceptError, connectionCloseError}
 		cookieSyncStatusValues    = enumAsString(metrics.CookieSyncStatuses())
 		cookieValues              = enumAsString(metrics.CookieTypes())
+		eventSignatureValues      = enumAsString(metrics.EventSignatureStatuses())
 		overheadTypes             = enumAsString(metrics.OverheadTypes())
+		rateLimitResultValues     = enumAsString(metrics.RateLimitResults())
+		mirrorResultValues        = enumAsString(metrics.MirrorResults())
+		configSectionValues       = enumAsString(metrics.AccountConfigSections())
 		requestStatusValues       = enumAsString(metrics.RequestStatuses())
 		requestTypeValues         = enumAsString(metrics.RequestTypes())
 		setUidStatusValues        = enumAsString(metrics.SetUidStatuses())
 		sourceValues              = []string{sourceRequest}
 		storedDataErrorValues     = enumAsString(metrics.StoredDataErrors())
 		storedDataFetchTypeValues = enumAsString(metrics.StoredDataFetchTypes())
+		storedDataTypeValues      = enumAsString(metrics.StoredDataTypes())
 		syncerRequestStatusValues = enumAsString(metrics.SyncerRequestStatuses())
 		syncerSetsStatusValues    = enumAsString(metrics.SyncerSetUidStatuses())
 		tcfVersionValues          = enumAsString(metrics.TCFVersions())
@@ -40,6 +45,24 @@ func preloadLabelValues(m *Metrics, syncerKeys []string, moduleStageNames map[st
 		statusLabel: setUidStatusValues,
 	})
 
+	preloadLabelValuesForCounter(m.eventSignatureFailures, map[string][]string{
+		statusLabel: eventSignatureValues,
+	})
+
+	preloadLabelValuesForCounter(m.rateLimits, map[string][]string{
+		requestTypeLabel:     requestTypeValues,
+		rateLimitResultLabel: rateLimitResultValues,
+	})
+
+	preloadLabelValuesForCounter(m.mirroredRequests, map[string][]string{
+		requestTypeLabel:  requestTypeValues,
+		mirrorResultLabel: mirrorResultValues,
+	})
+
+	preloadLabelValuesForCounter(m.accountConfigInvalid, map[string][]string{
+		configSectionLabel: configSectionValues,
+	})
+
 	preloadLabelValuesForCounter(m.impressions, map[string][]string{
 		isBannerLabel: boolValues,
 		isVideoLabel:  boolValues,
@@ -84,6 +107,10 @@ func preloadLabelValues(m *Metrics, syncerKeys []string, moduleStageNames map[st
 		storedDataFetchTypeLabel: storedDataFetchTypeValues,
 	})
 
+	preloadLabelValuesForHistogram(m.storedDataEventLagTimer, map[string][]string{
+		storedDataTypeLabel: storedDataTypeValues,
+	})
+
 	preloadLabelValuesForCounter(m.storedAccountErrors, map[string][]string{
 		storedDataErrorLabel: storedDataErrorValues,
 	})
@@ -45,7 +45,12 @@ type Metrics struct {
 	storedRequestErrors          *prometheus.CounterVec
 	storedVideoFetchTimer        *prometheus.HistogramVec
 	storedVideoErrors            *prometheus.CounterVec
+	storedDataEventLagTimer      *prometheus.HistogramVec
 	timeoutNotifications         *prometheus.CounterVec
+	eventSignatureFailures       *prometheus.CounterVec
+	rateLimits                   *prometheus.CounterVec
+	mirroredRequests             *prometheus.CounterVec
+	accountConfigInvalid         *prometheus.CounterVec
 	dnsLookupTimer               prometheus.Histogram
 	tlsHandhakeTimer             prometheus.Histogram
 	privacyCCPA                  *prometheus.CounterVec
@@ -63,6 +68,7 @@ type Metrics struct {
 	adapterBids                           *prometheus.CounterVec
 	adapterErrors                         *prometheus.CounterVec
 	adapterPanics                         *prometheus.CounterVec
+	adapterNonBids                        *prometheus.CounterVec
 	adapterPrices                         *prometheus.HistogramVec
 	adapterRequests                       *prometheus.CounterVec
 	overheadTimer                         *prometheus.HistogramVec
@@ -85,6 +91,7 @@ type Metrics struct {
 	accountRequests                       *prometheus.CounterVec
 	accountDebugRequests                  *prometheus.CounterVec
 	accountStoredResponses                *prometheus.CounterVec
+	accountRateLimited                    *prometheus.CounterVec
 	accountBidResponseValidationSizeError *prometheus.CounterVec
 	accountBidResponseValidationSizeWarn  *prometheus.CounterVec
 	accountBidResponseSecureMarkupError   *prometheus.CounterVec
@@ -118,9 +125,13 @@ const (
 	isNativeLabel        = "native"
 	isVideoLabel         = "video"
 	markupDeliveryLabel  = "delivery"
+	nonBidReasonLabel    = "nonbid_reason"
 	optOutLabel          = "opt_out"
 	overheadTypeLabel    = "overhead_type"
 	privacyBlockedLabel  = "privacy_blocked"
+	rateLimitResultLabel = "rate_limit_result"
+	mirrorResultLabel    = "mirror_result"
+	configSectionLabel   = "config_section"
 	requestStatusLabel   = "request_status"
 	requestTypeLabel     = "request_type"
 	stageLabel           = "stage"
@@ -158,6 +169,7 @@ const (
 const (
 	storedDataFetchTypeLabel = "stored_data_fetch_type"
 	storedDataErrorLabel     = "stored_data_error"
+	storedDataTypeLabel      = "stored_data_type"
 )
 
 // NewMetrics initializes a new Prometheus metrics instance with preloaded label values.
@@ -300,11 +312,37 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of stored video errors by error type",
 		[]string{storedDataErrorLabel})
 
+	metrics.storedDataEventLagTimer = newHistogramVec(cfg, reg,
+		"stored_data_event_lag_seconds",
+		"Seconds between a change of stored data in the database and its event, labeled by stored data type",
+		[]string{storedDataTypeLabel},
+		standardTimeBuckets)
+
 	metrics.timeoutNotifications = newCounter(cfg, reg,
 		"timeout_notification",
 		"Count of timeout notifications triggered, and if they were successfully sent.",
 		[]string{successLabel})
 
+	metrics.eventSignatureFailures = newCounter(cfg, reg,
+		"event_signature_failures",
+		"Count of event requests which failed signature verification by status.",
+		[]string{statusLabel})
+
+	metrics.rateLimits = newCounter(cfg, reg,
+		"rate_limits",
+		"Count of requests limited by the rate limits of their account, or allowed because the limits could not be checked, by request type and result.",
+		[]string{requestTypeLabel, rateLimitResultLabel})
+
+	metrics.mirroredRequests = newCounter(cfg, reg,
+		"mirrored_requests",
+		"Count of sampled requests copied to the mirroring target, dropped because the queue was full, or failed, by request type and result.",
+		[]string{requestTypeLabel, mirrorResultLabel})
+
+	metrics.accountConfigInvalid = newCounter(cfg, reg,
+		"account_config_invalid",
+		"Count of invalid account config sections reset to their default, by section.",
+		[]string{configSectionLabel})
+
 	metrics.dnsLookupTimer = newHistogram(cfg, reg,
 		"dns_lookup_time",
 		"Seconds to resolve DNS",
@@ -378,6 +416,13 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of panics labeled by adapter.",
 		[]string{adapterLabel})
 
+	if !metrics.metricsDisabled.AdapterNonBids {
+		metrics.adapterNonBids = newCounter(cfg, reg,
+			"adapter_nonbids",
+			"Count of the seat non bids of the auction responses labeled by adapter and nonbid status code.",
+			[]string{adapterLabel, nonBidReasonLabel})
+	}
+
 	metrics.adapterPrices = newHistogramVec(cfg, reg,
 		"adapter_prices",
 		"Monetary value of the bids labeled by adapter.",
@@ -495,6 +540,11 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of total requests to Prebid Server that have stored responses labled by account",
 		[]string{accountLabel})
 
+	metrics.accountRateLimited = newCounter(cfg, reg,
+		"account_rate_limited",
+		"Count of requests limited by the rate limits of their account labeled by account.",
+		[]string{accountLabel})
+
 	metrics.adsCertSignTimer = newHistogram(cfg, reg,
 		"ads_cert_sign_time",
 		"Seconds to generate an AdsCert header",
@@ -769,6 +819,12 @@ func (m *Metrics) RecordStoredDataError(labels metrics.StoredDataLabels) {
 	}
 }
 
+func (m *Metrics) RecordStoredDataEventLag(labels metrics.StoredDataLabels, lag time.Duration) {
+	m.storedDataEventLagTimer.With(prometheus.Labels{
+		storedDataTypeLabel: string(labels.DataType),
+	}).Observe(lag.Seconds())
+}
+
 func (m *Metrics) RecordAdapterRequest(labels metrics.AdapterLabels) {
 	lowerCasedAdapter := strings.ToLower(string(labels.Adapter))
 	m.adapterRequests.With(prometheus.Labels{
@@ -826,6 +882,17 @@ func (m *Metrics) RecordAdapterPanic(labels metrics.AdapterLabels) {
 	}).Inc()
 }
 
+func (m *Metrics) RecordAdapterNonBid(labels metrics.AdapterLabels, reason int) {
+	if m.metricsDisabled.AdapterNonBids {
+		return
+	}
+
+	m.adapterNonBids.With(prometheus.Labels{
+		adapterLabel:      strings.ToLower(string(labels.Adapter)),
+		nonBidReasonLabel: strconv.Itoa(reason),
+	}).Inc()
+}
+
 func (m *Metrics) RecordAdapterBidReceived(labels metrics.AdapterLabels, bidType openrtb_ext.BidType, hasAdm bool) {
 	markupDelivery := markupDeliveryNurl
 	if hasAdm {
@@ -931,6 +998,37 @@ func (m *Metrics) RecordTimeoutNotice(success bool) {
 	}
 }
 
+func (m *Metrics) RecordEventSignatureFailure(status metrics.EventSignatureStatus) {
+	m.eventSignatureFailures.With(prometheus.Labels{
+		statusLabel: string(status),
+	}).Inc()
+}
+
+func (m *Metrics) RecordRateLimit(labels metrics.RateLimitLabels) {
+	m.rateLimits.With(prometheus.Labels{
+		requestTypeLabel:     string(labels.RType),
+		rateLimitResultLabel: string(labels.Result),
+	}).Inc()
+	if labels.Result == metrics.RateLimitLimited && labels.PubID != metrics.PublisherUnknown {
+		m.accountRateLimited.With(prometheus.Labels{
+			accountLabel: labels.PubID,
+		}).Inc()
+	}
+}
+
+func (m *Metrics) RecordMirroredRequest(requestType metrics.RequestType, result metrics.MirrorResult) {
+	m.mirroredRequests.With(prometheus.Labels{
+		requestTypeLabel:  string(requestType),
+		mirrorResultLabel: string(result),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAccountConfigInvalid(section metrics.AccountConfigSection) {
+	m.accountConfigInvalid.With(prometheus.Labels{
+		configSectionLabel: string(section),
+	}).Inc()
+}
+
 func (m *Metrics) RecordRequestPrivacy(privacy metrics.PrivacyLabels) {
 	if privacy.CCPAProvided {
 		m.privacyCCPA.With(prometheus.Labels{
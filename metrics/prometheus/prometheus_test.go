@@ -664,6 +664,15 @@ func TestRecordStoredDataFetchTime(t *testing.T) {
 	}
 }
 
+func TestRecordStoredDataEventLag(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordStoredDataEventLag(metrics.StoredDataLabels{DataType: metrics.RequestDataType}, 250*time.Millisecond)
+
+	result := getHistogramFromHistogramVec(m.storedDataEventLagTimer, storedDataTypeLabel, string(metrics.RequestDataType))
+	assertHistogram(t, "stored_data_event_lag_seconds", result, 1, 0.25)
+}
+
 func TestRecordStoredDataError(t *testing.T) {
 	tests := []struct {
 		description string
@@ -1119,6 +1128,20 @@ func TestAdapterPanicMetric(t *testing.T) {
 		})
 }
 
+func TestAdapterNonBidMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	labels := metrics.AdapterLabels{Adapter: openrtb_ext.BidderName("anyName")}
+
+	m.RecordAdapterNonBid(labels, 301)
+	m.RecordAdapterNonBid(labels, 301)
+	m.RecordAdapterNonBid(labels, 101)
+
+	assertCounterVecValue(t, "", "adapterNonBids", m.adapterNonBids, 2,
+		prometheus.Labels{adapterLabel: "anyname", nonBidReasonLabel: "301"})
+	assertCounterVecValue(t, "", "adapterNonBids", m.adapterNonBids, 1,
+		prometheus.Labels{adapterLabel: "anyname", nonBidReasonLabel: "101"})
+}
+
 func TestStoredReqCacheResultMetric(t *testing.T) {
 	m := createMetricsForTesting()
 
@@ -1638,6 +1661,7 @@ func TestDisabledMetrics(t *testing.T) {
 		AdapterBuyerUIDScrubbed:   true,
 		AdapterConnectionMetrics:  true,
 		AdapterGDPRRequestBlocked: true,
+		AdapterNonBids:            true,
 	},
 		nil, nil)
 
@@ -1647,6 +1671,11 @@ func TestDisabledMetrics(t *testing.T) {
 	assert.Nil(t, prometheusMetrics.adapterCreatedConnections, "Counter Vector adapterCreatedConnections should be nil")
 	assert.Nil(t, prometheusMetrics.adapterConnectionWaitTime, "Counter Vector adapterConnectionWaitTime should be nil")
 	assert.Nil(t, prometheusMetrics.adapterGDPRBlockedRequests, "Counter Vector adapterGDPRBlockedRequests should be nil")
+	assert.Nil(t, prometheusMetrics.adapterNonBids, "Counter Vector adapterNonBids should be nil")
+
+	assert.NotPanics(t, func() {
+		prometheusMetrics.RecordAdapterNonBid(metrics.AdapterLabels{Adapter: openrtb_ext.BidderName("anyName")}, 301)
+	})
 }
 
 func TestRecordRequestPrivacy(t *testing.T) {
@@ -1895,6 +1924,72 @@ func TestStoredResponsesMetric(t *testing.T) {
 	}
 }
 
+func TestRecordRateLimit(t *testing.T) {
+	testCases := []struct {
+		description                 string
+		labels                      metrics.RateLimitLabels
+		expectedRateLimitCount      float64
+		expectedAccountLimitedCount float64
+	}{
+		{
+			description:                 "Limited request of a known account, expected both counters to have a record",
+			labels:                      metrics.RateLimitLabels{RType: metrics.ReqTypeAMP, PubID: "acct-id", Result: metrics.RateLimitLimited},
+			expectedRateLimitCount:      1,
+			expectedAccountLimitedCount: 1,
+		},
+		{
+			description:                 "Limited request of an unknown account, expected rate limits counter only to have a record",
+			labels:                      metrics.RateLimitLabels{RType: metrics.ReqTypeAMP, PubID: metrics.PublisherUnknown, Result: metrics.RateLimitLimited},
+			expectedRateLimitCount:      1,
+			expectedAccountLimitedCount: 0,
+		},
+		{
+			description:                 "Backend error of a known account, expected rate limits counter only to have a record",
+			labels:                      metrics.RateLimitLabels{RType: metrics.ReqTypeAMP, PubID: "acct-id", Result: metrics.RateLimitError},
+			expectedRateLimitCount:      1,
+			expectedAccountLimitedCount: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		m := createMetricsForTesting()
+		m.RecordRateLimit(test.labels)
+
+		assertCounterVecValue(t, test.description, "rate limits", m.rateLimits, test.expectedRateLimitCount, prometheus.Labels{
+			requestTypeLabel:     string(metrics.ReqTypeAMP),
+			rateLimitResultLabel: string(test.labels.Result),
+		})
+		assertCounterVecValue(t, test.description, "account rate limited", m.accountRateLimited, test.expectedAccountLimitedCount, prometheus.Labels{accountLabel: "acct-id"})
+	}
+}
+
+func TestRecordMirroredRequest(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordMirroredRequest(metrics.ReqTypeVideo, metrics.MirrorSent)
+	m.RecordMirroredRequest(metrics.ReqTypeVideo, metrics.MirrorFailed)
+	m.RecordMirroredRequest(metrics.ReqTypeVideo, metrics.MirrorFailed)
+
+	assertCounterVecValue(t, "", "mirrored requests sent", m.mirroredRequests, 1, prometheus.Labels{
+		requestTypeLabel:  string(metrics.ReqTypeVideo),
+		mirrorResultLabel: string(metrics.MirrorSent),
+	})
+	assertCounterVecValue(t, "", "mirrored requests failed", m.mirroredRequests, 2, prometheus.Labels{
+		requestTypeLabel:  string(metrics.ReqTypeVideo),
+		mirrorResultLabel: string(metrics.MirrorFailed),
+	})
+}
+
+func TestRecordAccountConfigInvalid(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAccountConfigInvalid(metrics.AccountConfigPriceGranularityLadders)
+
+	assertCounterVecValue(t, "", "account config invalid", m.accountConfigInvalid, 1, prometheus.Labels{
+		configSectionLabel: string(metrics.AccountConfigPriceGranularityLadders),
+	})
+}
+
 func TestRecordAdsCertReqMetric(t *testing.T) {
 	testCases := []struct {
 		description                  string
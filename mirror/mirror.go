@@ -0,0 +1,216 @@
+// Package mirror copies a sample of the auction requests to a shadow PBS or a NDJSON file, so new versions and adapter
+// changes can be tested against the production traffic without affecting the responses.
+//
+// The requests are copied in the background through a bounded queue: a request which doesn't fit in the queue is
+// dropped rather than delaying the auction.
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+)
+
+// drainTimeout is how long Shutdown waits for the queued requests to be copied before aborting them.
+const drainTimeout = 5 * time.Second
+
+// Request is a mirrored request, as written to the NDJSON file.
+type Request struct {
+	Time time.Time `json:"time"`
+	// Endpoint is auction, amp or video.
+	Endpoint    string              `json:"endpoint"`
+	Account     string              `json:"account"`
+	RequestType metrics.RequestType `json:"-"`
+	// BidRequest is the OpenRTB request resolved from the stored requests.
+	BidRequest json.RawMessage `json:"request"`
+}
+
+// Target receives the mirrored requests.
+type Target interface {
+	Send(ctx context.Context, req *Request) error
+	Close() error
+}
+
+// Mirror samples the requests of the auction endpoints and copies them to its target.
+//
+// A nil *Mirror mirrors nothing, so the endpoints don't need to check if mirroring is enabled.
+type Mirror struct {
+	target        Target
+	metricsEngine metrics.MetricsEngine
+	time          timeutil.Time
+	random        func() float64
+
+	// mutex guards closed, so no request is queued once the queue is closed
+	mutex   sync.RWMutex
+	closed  bool
+	queue   chan *Request
+	workers sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewMirror returns the Mirror of the host config, or nil when mirroring is disabled.
+func NewMirror(cfg config.Mirroring, client *http.Client, metricsEngine metrics.MetricsEngine) (*Mirror, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	switch cfg.Target {
+	case config.MirroringTargetHTTP:
+		target := NewHTTPTarget(client, cfg.HTTP.Endpoint, time.Duration(cfg.HTTP.Timeout)*time.Millisecond)
+		return newMirror(target, cfg.QueueSize, cfg.HTTP.Workers, metricsEngine, &timeutil.RealTime{}, rand.Float64), nil
+	case config.MirroringTargetFile:
+		target, err := NewFileTarget(cfg.File.Path)
+		if err != nil {
+			return nil, err
+		}
+		// a single worker keeps the lines of the file whole
+		return newMirror(target, cfg.QueueSize, 1, metricsEngine, &timeutil.RealTime{}, rand.Float64), nil
+	}
+	return nil, fmt.Errorf("unknown mirroring target %s", cfg.Target)
+}
+
+func newMirror(target Target, queueSize, workers int, metricsEngine metrics.MetricsEngine, timeSource timeutil.Time, random func() float64) *Mirror {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Mirror{
+		target:        target,
+		metricsEngine: metricsEngine,
+		time:          timeSource,
+		random:        random,
+		queue:         make(chan *Request, queueSize),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	m.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go m.work()
+	}
+	return m
+}
+
+// Sample returns true if the request is sampled by the mirroring of its account, in which case it should be copied.
+// It's called before the request is copied, so that only the sampled requests are serialized.
+func (m *Mirror) Sample(endpoint string, account *config.Account) bool {
+	if m == nil || account == nil || !account.Mirroring.Mirrors(endpoint) {
+		return false
+	}
+	return m.random() < account.Mirroring.SampleRate
+}
+
+// Copy queues a copy of a sampled request, without the personal data of the user. The request is copied right away,
+// as the auction goes on modifying it.
+func (m *Mirror) Copy(endpoint string, account *config.Account, requestType metrics.RequestType, bidRequest *openrtb2.BidRequest) {
+	if m == nil || account == nil || bidRequest == nil {
+		return
+	}
+
+	body, err := marshalScrubbed(bidRequest, account)
+	if err != nil {
+		glog.Errorf("Failed to marshal the mirrored request of account %s: %v", account.ID, err)
+		m.metricsEngine.RecordMirroredRequest(requestType, metrics.MirrorFailed)
+		return
+	}
+	req := &Request{
+		Time:        m.time.Now(),
+		Endpoint:    endpoint,
+		Account:     account.ID,
+		RequestType: requestType,
+		BidRequest:  body,
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if m.closed {
+		return
+	}
+	select {
+	case m.queue <- req:
+	default:
+		m.metricsEngine.RecordMirroredRequest(requestType, metrics.MirrorDropped)
+	}
+}
+
+// marshalScrubbed marshals the request without the personal data of the user. The privacy enforcement of PBS runs
+// per bidder later in the auction, so the mirrored request is scrubbed as if every privacy regulation applied: the
+// user and device IDs, the user demographics, data and EIDs are removed, and the IP addresses and the geolocation are
+// truncated following the account settings.
+func marshalScrubbed(bidRequest *openrtb2.BidRequest, account *config.Account) (json.RawMessage, error) {
+	request := *bidRequest
+	if request.User != nil {
+		user := *request.User
+		request.User = &user
+	}
+	if request.Device != nil {
+		device := *request.Device
+		request.Device = &device
+	}
+
+	wrapper := &openrtb_ext.RequestWrapper{BidRequest: &request}
+	ipConf := privacy.IPConf{IPV6: account.Privacy.IPv6Config, IPV4: account.Privacy.IPv4Config}
+	privacy.ScrubDeviceIDsIPsUserDemoExt(wrapper, ipConf, "eids", false)
+	if request.User != nil {
+		privacy.ScrubUserFPD(wrapper)
+	}
+	if err := wrapper.RebuildRequest(); err != nil {
+		return nil, err
+	}
+	return jsonutil.Marshal(wrapper.BidRequest)
+}
+
+func (m *Mirror) work() {
+	defer m.workers.Done()
+	for req := range m.queue {
+		if err := m.target.Send(m.ctx, req); err != nil {
+			glog.Warningf("Failed to mirror a request of account %s: %v", req.Account, err)
+			m.metricsEngine.RecordMirroredRequest(req.RequestType, metrics.MirrorFailed)
+			continue
+		}
+		m.metricsEngine.RecordMirroredRequest(req.RequestType, metrics.MirrorSent)
+	}
+}
+
+// Shutdown stops the sampling and waits for the queued requests to be copied, aborting them after a few seconds,
+// before closing the target.
+func (m *Mirror) Shutdown() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	if m.closed {
+		m.mutex.Unlock()
+		return
+	}
+	m.closed = true
+	close(m.queue)
+	m.mutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(drainTimeout):
+		glog.Warningf("Aborting the %d mirrored requests still queued", len(m.queue))
+		m.cancel()
+		<-drained
+	}
+	m.cancel()
+
+	if err := m.target.Close(); err != nil {
+		glog.Errorf("Failed to close the mirroring target: %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTime struct {
+	time time.Time
+}
+
+func (ft *fakeTime) Now() time.Time {
+	return ft.time
+}
+
+type fakeTarget struct {
+	mutex    sync.Mutex
+	requests []*Request
+	err      error
+	closed   bool
+}
+
+func (t *fakeTarget) Send(_ context.Context, req *Request) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.requests = append(t.requests, req)
+	return t.err
+}
+
+func (t *fakeTarget) Close() error {
+	t.closed = true
+	return nil
+}
+
+func newTestMirror(target Target, queueSize, workers int, random float64) (*Mirror, *metrics.MetricsEngineMock) {
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordMirroredRequest", mock.Anything, mock.Anything).Return()
+	now := &fakeTime{time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	return newMirror(target, queueSize, workers, metricsEngine, now, func() float64 { return random }), metricsEngine
+}
+
+func TestNewMirror(t *testing.T) {
+	testCases := []struct {
+		description  string
+		cfg          config.Mirroring
+		expectMirror bool
+		expectError  bool
+	}{
+		{
+			description: "disabled",
+			cfg:         config.Mirroring{Enabled: false, Target: config.MirroringTargetHTTP},
+		},
+		{
+			description:  "http",
+			cfg:          config.Mirroring{Enabled: true, Target: config.MirroringTargetHTTP, HTTP: config.MirroringHTTP{Endpoint: "https://shadow/openrtb2/auction", Timeout: 100, Workers: 2}, QueueSize: 10},
+			expectMirror: true,
+		},
+		{
+			description:  "file",
+			cfg:          config.Mirroring{Enabled: true, Target: config.MirroringTargetFile, File: config.MirroringFile{Path: t.TempDir() + "/mirror.ndjson"}, QueueSize: 10},
+			expectMirror: true,
+		},
+		{
+			description: "file_in_missing_directory",
+			cfg:         config.Mirroring{Enabled: true, Target: config.MirroringTargetFile, File: config.MirroringFile{Path: t.TempDir() + "/missing/mirror.ndjson"}, QueueSize: 10},
+			expectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			m, err := NewMirror(test.cfg, http.DefaultClient, &metrics.MetricsEngineMock{})
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectMirror, m != nil)
+			m.Shutdown()
+		})
+	}
+}
+
+func TestSample(t *testing.T) {
+	bidRequest := &openrtb2.BidRequest{ID: "req-id"}
+
+	testCases := []struct {
+		description   string
+		account       *config.Account
+		endpoint      string
+		random        float64
+		expectSampled bool
+	}{
+		{
+			description:   "sampled",
+			account:       &config.Account{ID: "acct", Mirroring: config.AccountMirroring{SampleRate: 0.5}},
+			endpoint:      config.MirroringEndpointAuction,
+			random:        0.4,
+			expectSampled: true,
+		},
+		{
+			description: "not_sampled",
+			account:     &config.Account{ID: "acct", Mirroring: config.AccountMirroring{SampleRate: 0.5}},
+			endpoint:    config.MirroringEndpointAuction,
+			random:      0.5,
+		},
+		{
+			description:   "listed_endpoint",
+			account:       &config.Account{ID: "acct", Mirroring: config.AccountMirroring{SampleRate: 1, Endpoints: []string{"amp"}}},
+			endpoint:      config.MirroringEndpointAMP,
+			expectSampled: true,
+		},
+		{
+			description: "unlisted_endpoint",
+			account:     &config.Account{ID: "acct", Mirroring: config.AccountMirroring{SampleRate: 1, Endpoints: []string{"amp"}}},
+			endpoint:    config.MirroringEndpointVideo,
+		},
+		{
+			description: "no_sample_rate",
+			account:     &config.Account{ID: "acct"},
+			endpoint:    config.MirroringEndpointAuction,
+		},
+		{
+			description: "no_account",
+			endpoint:    config.MirroringEndpointAuction,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			target := &fakeTarget{}
+			m, metricsEngine := newTestMirror(target, 10, 1, test.random)
+
+			sampled := m.Sample(test.endpoint, test.account)
+			if sampled {
+				m.Copy(test.endpoint, test.account, metrics.ReqTypeORTB2Web, bidRequest)
+			}
+			m.Shutdown()
+
+			assert.Equal(t, test.expectSampled, sampled)
+
+			if !test.expectSampled {
+				assert.Empty(t, target.requests)
+				metricsEngine.AssertNotCalled(t, "RecordMirroredRequest", mock.Anything, mock.Anything)
+				return
+			}
+			require.Len(t, target.requests, 1)
+			assert.Equal(t, &Request{
+				Time:        time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				Endpoint:    test.endpoint,
+				Account:     "acct",
+				RequestType: metrics.ReqTypeORTB2Web,
+				BidRequest:  []byte(`{"id":"req-id","imp":null}`),
+			}, target.requests[0])
+			metricsEngine.AssertCalled(t, "RecordMirroredRequest", metrics.ReqTypeORTB2Web, metrics.MirrorSent)
+		})
+	}
+}
+
+func TestCopyCopiesRequest(t *testing.T) {
+	target := &fakeTarget{}
+	m, _ := newTestMirror(target, 10, 0, 0)
+	bidRequest := &openrtb2.BidRequest{ID: "req-id"}
+
+	m.Copy(config.MirroringEndpointAuction, &config.Account{ID: "acct", Mirroring: config.AccountMirroring{SampleRate: 1}}, metrics.ReqTypeORTB2Web, bidRequest)
+	bidRequest.ID = "modified-by-the-auction"
+
+	req := <-m.queue
+	assert.JSONEq(t, `{"id":"req-id","imp":null}`, string(req.BidRequest))
+}
+
+func TestCopyFullQueue(t *testing.T) {
+	target := &fakeTarget{}
+	// without workers, the queue is never emptied
+	m, metricsEngine := newTestMirror(target, 1, 0, 0)
+	account := &config.Account{ID: "acct", Mirroring: config.AccountMirroring{SampleRate: 1}}
+
+	m.Copy(config.MirroringEndpointAMP, account, metrics.ReqTypeAMP, &openrtb2.BidRequest{ID: "1"})
+	m.Copy(config.MirroringEndpointAMP, account, metrics.ReqTypeAMP, &openrtb2.BidRequest{ID: "2"})
+
+	assert.Len(t, m.queue, 1)
+	metricsEngine.AssertCalled(t, "RecordMirroredRequest", metrics.ReqTypeAMP, metrics.MirrorDropped)
+	metricsEngine.AssertNumberOfCalls(t, "RecordMirroredRequest", 1)
+}
+
+func TestCopyTargetError(t *testing.T) {
+	target := &fakeTarget{err: errors.New("shadow down")}
+	m, metricsEngine := newTestMirror(target, 10, 2, 0)
+
+	m.Copy(config.MirroringEndpointVideo, &config.Account{ID: "acct", Mirroring: config.AccountMirroring{SampleRate: 1}}, metrics.ReqTypeVideo, &openrtb2.BidRequest{ID: "1"})
+	m.Shutdown()
+
+	metricsEngine.AssertCalled(t, "RecordMirroredRequest", metrics.ReqTypeVideo, metrics.MirrorFailed)
+	metricsEngine.AssertNotCalled(t, "RecordMirroredRequest", metrics.ReqTypeVideo, metrics.MirrorSent)
+}
+
+func TestShutdown(t *testing.T) {
+	target := &fakeTarget{}
+	m, _ := newTestMirror(target, 10, 1, 0)
+	account := &config.Account{ID: "acct", Mirroring: config.AccountMirroring{SampleRate: 1}}
+
+	m.Copy(config.MirroringEndpointAuction, account, metrics.ReqTypeORTB2Web, &openrtb2.BidRequest{ID: "1"})
+	m.Shutdown()
+	m.Copy(config.MirroringEndpointAuction, account, metrics.ReqTypeORTB2Web, &openrtb2.BidRequest{ID: "2"})
+	m.Shutdown()
+
+	assert.Len(t, target.requests, 1, "the queued request is sent, and the requests after the shutdown are ignored")
+	assert.True(t, target.closed)
+}
+
+func TestNilMirror(t *testing.T) {
+	var m *Mirror
+	assert.NotPanics(t, func() {
+		assert.False(t, m.Sample(config.MirroringEndpointAuction, &config.Account{Mirroring: config.AccountMirroring{SampleRate: 1}}))
+		m.Copy(config.MirroringEndpointAuction, &config.Account{Mirroring: config.AccountMirroring{SampleRate: 1}}, metrics.ReqTypeORTB2Web, &openrtb2.BidRequest{})
+		m.Shutdown()
+	})
+}
+
+func TestCopyScrubsPersonalData(t *testing.T) {
+	target := &fakeTarget{}
+	m, _ := newTestMirror(target, 10, 0, 0)
+	account := &config.Account{ID: "acct", Privacy: config.AccountPrivacy{
+		IPv4Config: config.IPv4{AnonKeepBits: 24},
+		IPv6Config: config.IPv6{AnonKeepBits: 56},
+	}}
+	bidRequest := &openrtb2.BidRequest{
+		ID: "req-id",
+		User: &openrtb2.User{
+			ID:       "user-id",
+			BuyerUID: "buyer-uid",
+			Yob:      1980,
+			EIDs:     []openrtb2.EID{{Source: "example.com", UIDs: []openrtb2.UID{{ID: "eid"}}}},
+			Ext:      json.RawMessage(`{"consent":"consent-string","eids":[{"source":"example.com"}]}`),
+		},
+		Device: &openrtb2.Device{
+			IP:   "192.168.1.123",
+			IFA:  "ifa",
+			Geo:  &openrtb2.Geo{Country: "USA", Lat: ptrutil.ToPtr(40.71278), Lon: ptrutil.ToPtr(-74.00594)},
+			Make: "Apple",
+		},
+	}
+
+	m.Copy(config.MirroringEndpointAuction, account, metrics.ReqTypeORTB2Web, bidRequest)
+
+	req := <-m.queue
+	assert.JSONEq(t, `{
+		"id": "req-id",
+		"imp": null,
+		"user": {"ext": {"consent": "consent-string"}},
+		"device": {"ip": "192.168.1.0", "geo": {"country": "USA", "lat": 40.71, "lon": -74}, "make": "Apple"}
+	}`, string(req.BidRequest))
+	assert.Equal(t, "user-id", bidRequest.User.ID, "the request of the auction must not be scrubbed")
+	assert.Equal(t, "192.168.1.123", bidRequest.Device.IP, "the request of the auction must not be scrubbed")
+	assert.Len(t, bidRequest.User.EIDs, 1, "the request of the auction must not be scrubbed")
+}
@@ -0,0 +1,93 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// The headers telling the shadow PBS where the mirrored request comes from
+const (
+	endpointHeader = "X-Prebid-Mirror-Endpoint"
+	accountHeader  = "X-Prebid-Mirror-Account"
+)
+
+// HTTPTarget posts the mirrored requests to the auction endpoint of a shadow PBS. The responses are discarded.
+type HTTPTarget struct {
+	client   *http.Client
+	endpoint string
+	timeout  time.Duration
+}
+
+// NewHTTPTarget returns a HTTPTarget posting to the endpoint, and waiting up to the timeout for each response.
+func NewHTTPTarget(client *http.Client, endpoint string, timeout time.Duration) *HTTPTarget {
+	return &HTTPTarget{
+		client:   client,
+		endpoint: endpoint,
+		timeout:  timeout,
+	}
+}
+
+func (t *HTTPTarget) Send(ctx context.Context, req *Request) error {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(req.BidRequest))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(endpointHeader, req.Endpoint)
+	httpReq.Header.Set(accountHeader, req.Account)
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// the body is read so the connection can be reused
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("the shadow PBS responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (t *HTTPTarget) Close() error {
+	return nil
+}
+
+// FileTarget appends the mirrored requests to a NDJSON file, one request per line. It must not be used by
+// concurrent goroutines.
+type FileTarget struct {
+	file *os.File
+}
+
+// NewFileTarget opens the file, creating it if needed.
+func NewFileTarget(path string) (*FileTarget, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the mirroring file: %v", err)
+	}
+	return &FileTarget{file: file}, nil
+}
+
+func (t *FileTarget) Send(_ context.Context, req *Request) error {
+	line, err := jsonutil.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = t.file.Write(append(line, '\n'))
+	return err
+}
+
+func (t *FileTarget) Close() error {
+	return t.file.Close()
+}
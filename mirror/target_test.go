@@ -0,0 +1,93 @@
+package mirror
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPTarget(t *testing.T) {
+	testCases := []struct {
+		description string
+		status      int
+		delay       time.Duration
+		expectError bool
+	}{
+		{
+			description: "ok",
+			status:      http.StatusOK,
+		},
+		{
+			description: "no_content",
+			status:      http.StatusNoContent,
+		},
+		{
+			description: "bad_request_reached_the_shadow",
+			status:      http.StatusBadRequest,
+		},
+		{
+			description: "server_error",
+			status:      http.StatusInternalServerError,
+			expectError: true,
+		},
+		{
+			description: "timeout",
+			status:      http.StatusOK,
+			delay:       100 * time.Millisecond,
+			expectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			var gotHeader http.Header
+			var gotBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotHeader = r.Header
+				gotBody, _ = io.ReadAll(r.Body)
+				time.Sleep(test.delay)
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			target := NewHTTPTarget(server.Client(), server.URL+"/openrtb2/auction", 50*time.Millisecond)
+			err := target.Send(context.Background(), &Request{Endpoint: "amp", Account: "acct", BidRequest: []byte(`{"id":"req-id"}`)})
+
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, `{"id":"req-id"}`, string(gotBody))
+			assert.Equal(t, "application/json", gotHeader.Get("Content-Type"))
+			assert.Equal(t, "amp", gotHeader.Get("X-Prebid-Mirror-Endpoint"))
+			assert.Equal(t, "acct", gotHeader.Get("X-Prebid-Mirror-Account"))
+		})
+	}
+}
+
+func TestFileTarget(t *testing.T) {
+	path := t.TempDir() + "/mirror.ndjson"
+	require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0644))
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	target, err := NewFileTarget(path)
+	require.NoError(t, err)
+	require.NoError(t, target.Send(context.Background(), &Request{Time: now, Endpoint: "auction", Account: "acct1", RequestType: metrics.ReqTypeORTB2App, BidRequest: []byte(`{"id":"1"}`)}))
+	require.NoError(t, target.Send(context.Background(), &Request{Time: now, Endpoint: "video", Account: "acct2", RequestType: metrics.ReqTypeVideo, BidRequest: []byte(`{"id":"2"}`)}))
+	require.NoError(t, target.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n"+
+		`{"time":"2026-01-01T00:00:00Z","endpoint":"auction","account":"acct1","request":{"id":"1"}}`+"\n"+
+		`{"time":"2026-01-01T00:00:00Z","endpoint":"video","account":"acct2","request":{"id":"2"}}`+"\n", string(content), "the requests are appended")
+}
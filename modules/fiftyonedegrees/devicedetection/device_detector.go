@@ -2,6 +2,7 @@ package devicedetection
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/51Degrees/device-detection-go/v4/dd"
 	"github.com/51Degrees/device-detection-go/v4/onpremise"
@@ -10,6 +11,7 @@ import (
 type engine interface {
 	Process(evidences []onpremise.Evidence) (*dd.ResultsHash, error)
 	GetHttpHeaderKeys() []dd.EvidenceKey
+	Stop()
 }
 
 type extractor interface {
@@ -145,6 +147,11 @@ func (x defaultDeviceDetector) getSupportedHeaders() []dd.EvidenceKey {
 	return x.engine.GetHttpHeaderKeys()
 }
 
+// stop frees the resources held by the engine, e.g. the data file and its update routines.
+func (x defaultDeviceDetector) stop() {
+	x.engine.Stop()
+}
+
 func (x defaultDeviceDetector) getDeviceInfo(evidence []onpremise.Evidence, ua string) (*deviceInfo, error) {
 	results, err := x.engine.Process(evidence)
 	if err != nil {
@@ -156,3 +163,37 @@ func (x defaultDeviceDetector) getDeviceInfo(evidence []onpremise.Evidence, ua s
 
 	return deviceInfo, err
 }
+
+// reloadableDeviceDetector allows the device detector to be replaced while hooks are running.
+// Lookups in progress complete before the engine of the replaced detector is stopped.
+type reloadableDeviceDetector struct {
+	mu       sync.RWMutex
+	detector *defaultDeviceDetector
+}
+
+func (r *reloadableDeviceDetector) getSupportedHeaders() []dd.EvidenceKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.detector.getSupportedHeaders()
+}
+
+func (r *reloadableDeviceDetector) getDeviceInfo(evidence []onpremise.Evidence, ua string) (*deviceInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.detector.getDeviceInfo(evidence, ua)
+}
+
+func (r *reloadableDeviceDetector) replace(detector *defaultDeviceDetector) {
+	r.mu.Lock()
+	previous := r.detector
+	r.detector = detector
+	r.mu.Unlock()
+
+	previous.stop()
+}
+
+func (r *reloadableDeviceDetector) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.detector.stop()
+}
@@ -91,6 +91,10 @@ func (e *engineMock) GetHttpHeaderKeys() []dd.EvidenceKey {
 	return args.Get(0).([]dd.EvidenceKey)
 }
 
+func (e *engineMock) Stop() {
+	e.Called()
+}
+
 type extractorMock struct {
 	mock.Mock
 }
@@ -188,3 +192,24 @@ func TestGetSupportedHeaders(t *testing.T) {
 	assert.Equal(t, result[0].Key, "key")
 
 }
+
+func TestReloadableDeviceDetector(t *testing.T) {
+	firstEngine := &engineMock{}
+	firstEngine.On("GetHttpHeaderKeys").Return([]dd.EvidenceKey{{Key: "first", Prefix: dd.HttpEvidenceQuery}})
+	firstEngine.On("Stop").Return()
+
+	secondEngine := &engineMock{}
+	secondEngine.On("GetHttpHeaderKeys").Return([]dd.EvidenceKey{{Key: "second", Prefix: dd.HttpEvidenceQuery}})
+	secondEngine.On("Stop").Return()
+
+	deviceDetector := &reloadableDeviceDetector{detector: &defaultDeviceDetector{engine: firstEngine}}
+	assert.Equal(t, "first", deviceDetector.getSupportedHeaders()[0].Key)
+
+	deviceDetector.replace(&defaultDeviceDetector{engine: secondEngine})
+	assert.Equal(t, "second", deviceDetector.getSupportedHeaders()[0].Key)
+	firstEngine.AssertCalled(t, "Stop")
+	secondEngine.AssertNotCalled(t, "Stop")
+
+	deviceDetector.stop()
+	secondEngine.AssertCalled(t, "Stop")
+}
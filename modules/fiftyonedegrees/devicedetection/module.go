@@ -3,6 +3,7 @@ package devicedetection
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -33,14 +34,29 @@ func configHashFromConfig(cfg *config) *dd.ConfigHash {
 }
 
 func Builder(rawConfig json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, deviceDetectorImpl, err := buildDeviceDetector(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return Module{
+			cfg,
+			&reloadableDeviceDetector{detector: deviceDetectorImpl},
+			newEvidenceExtractor(),
+			newAccountValidator(),
+		},
+		nil
+}
+
+func buildDeviceDetector(rawConfig json.RawMessage) (config, *defaultDeviceDetector, error) {
 	cfg, err := parseConfig(rawConfig)
 	if err != nil {
-		return Module{}, fmt.Errorf("failed to parse config: %w", err)
+		return cfg, nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
 	err = validateConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
+		return cfg, nil, fmt.Errorf("invalid config: %w", err)
 	}
 
 	configHash := configHashFromConfig(&cfg)
@@ -50,16 +66,10 @@ func Builder(rawConfig json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, e
 		&cfg,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create device detector: %w", err)
+		return cfg, nil, fmt.Errorf("failed to create device detector: %w", err)
 	}
 
-	return Module{
-			cfg,
-			deviceDetectorImpl,
-			newEvidenceExtractor(),
-			newAccountValidator(),
-		},
-		nil
+	return cfg, deviceDetectorImpl, nil
 }
 
 type Module struct {
@@ -84,6 +94,31 @@ type evidenceExtractor interface {
 	extract(ctx hookstage.ModuleContext) ([]onpremise.Evidence, string, error)
 }
 
+// Shutdown stops the device detection engine along with its data file updates.
+func (m Module) Shutdown() {
+	if detector, ok := m.deviceDetector.(*reloadableDeviceDetector); ok {
+		detector.stop()
+	}
+}
+
+// Reload replaces the device detection engine with one using the data file and performance
+// settings of the given config, e.g. to pick up a data file refreshed outside of Prebid Server.
+// The account filter can't be changed without a restart.
+func (m Module) Reload(rawConfig json.RawMessage) error {
+	detector, ok := m.deviceDetector.(*reloadableDeviceDetector)
+	if !ok {
+		return errors.New("device detector does not support reloading")
+	}
+
+	_, deviceDetectorImpl, err := buildDeviceDetector(rawConfig)
+	if err != nil {
+		return err
+	}
+
+	detector.replace(deviceDetectorImpl)
+	return nil
+}
+
 func (m Module) HandleEntrypointHook(
 	_ context.Context,
 	_ hookstage.ModuleInvocationContext,
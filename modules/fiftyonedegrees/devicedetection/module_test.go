@@ -701,3 +701,23 @@ func TestHydrateFields(t *testing.T) {
 		string(deviceHolder.Device),
 	)
 }
+
+func TestReload(t *testing.T) {
+	engineM := &engineMock{}
+	engineM.On("Stop").Return()
+	module := Module{deviceDetector: &reloadableDeviceDetector{detector: &defaultDeviceDetector{engine: engineM}}}
+
+	err := module.Reload([]byte(`{"data_file":{"path":"nonexistent.hash"}}`))
+	assert.ErrorContains(t, err, "invalid config")
+	engineM.AssertNotCalled(t, "Stop")
+
+	module.Shutdown()
+	engineM.AssertCalled(t, "Stop")
+}
+
+func TestReloadNotSupported(t *testing.T) {
+	module := Module{deviceDetector: &mockDeviceDetector{}}
+
+	err := module.Reload([]byte(`{}`))
+	assert.EqualError(t, err, "device detector does not support reloading")
+}
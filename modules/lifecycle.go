@@ -0,0 +1,134 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// Shutdowner is implemented by modules that hold resources which must be released
+// when Prebid Server shuts down, e.g. background goroutines or buffered data.
+type Shutdowner interface {
+	Shutdown()
+}
+
+// HealthReporter is implemented by modules able to tell whether they can serve hooks.
+// A non-nil error marks the module as unhealthy and describes the reason.
+type HealthReporter interface {
+	Health(ctx context.Context) error
+}
+
+// Reloadable is implemented by modules able to apply a new host config without a restart.
+// The module is expected to keep its previous config if the new one can't be applied.
+type Reloadable interface {
+	Reload(cfg json.RawMessage) error
+}
+
+// ModuleHealth describes the health of a single module.
+type ModuleHealth struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Lifecycle keeps track of the built modules and dispatches lifecycle events
+// to the modules implementing the optional lifecycle interfaces.
+type Lifecycle struct {
+	modules map[string]interface{}
+	// reloadMu serializes reloads triggered by signals and the admin endpoint
+	reloadMu sync.Mutex
+}
+
+// NewLifecycle returns a lifecycle for the given modules keyed by their "vendor.module_name" ID.
+func NewLifecycle(modules map[string]interface{}) *Lifecycle {
+	return &Lifecycle{modules: modules}
+}
+
+// Shutdown shuts down every module implementing Shutdowner.
+func (l *Lifecycle) Shutdown() {
+	for _, id := range l.ids() {
+		if module, ok := l.modules[id].(Shutdowner); ok {
+			glog.Infof("Shutting down %s module.", id)
+			module.Shutdown()
+		}
+	}
+}
+
+// Health returns the health of every module implementing HealthReporter.
+func (l *Lifecycle) Health(ctx context.Context) map[string]ModuleHealth {
+	health := make(map[string]ModuleHealth)
+	for _, id := range l.ids() {
+		module, ok := l.modules[id].(HealthReporter)
+		if !ok {
+			continue
+		}
+
+		if err := module.Health(ctx); err != nil {
+			health[id] = ModuleHealth{Healthy: false, Error: err.Error()}
+		} else {
+			health[id] = ModuleHealth{Healthy: true}
+		}
+	}
+	return health
+}
+
+// Reload passes the new host config to every running module implementing Reloadable.
+// Modules enabled or disabled by the new config are only picked up on restart.
+// Errors of individual modules don't prevent the remaining modules from being reloaded.
+func (l *Lifecycle) Reload(cfg config.Modules) error {
+	if l == nil {
+		return nil
+	}
+
+	l.reloadMu.Lock()
+	defer l.reloadMu.Unlock()
+
+	var errs []error
+	for _, id := range l.ids() {
+		module, ok := l.modules[id].(Reloadable)
+		if !ok {
+			continue
+		}
+
+		vendor, moduleName, _ := strings.Cut(id, ".")
+		data, ok := cfg[vendor][moduleName]
+		if !ok {
+			glog.Warningf("Skip reloading %s module, config removed. Restart to disable it.", id)
+			continue
+		}
+
+		conf, err := jsonutil.Marshal(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(`failed to marshal "%s" module config: %s`, id, err))
+			continue
+		}
+
+		if err := module.Reload(conf); err != nil {
+			errs = append(errs, fmt.Errorf(`failed to reload "%s" module: %s`, id, err))
+			continue
+		}
+		glog.Infof("Reloaded %s module.", id)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (l *Lifecycle) ids() []string {
+	if l == nil {
+		return nil
+	}
+
+	ids := make([]string, 0, len(l.modules))
+	for id := range l.modules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
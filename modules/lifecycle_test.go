@@ -0,0 +1,105 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleShutdown(t *testing.T) {
+	shutdowner := &lifecycleModule{}
+	lifecycle := NewLifecycle(map[string]interface{}{"acme.foo": shutdowner, "acme.bar": module{}})
+
+	lifecycle.Shutdown()
+	assert.True(t, shutdowner.shutdown)
+}
+
+func TestLifecycleHealth(t *testing.T) {
+	lifecycle := NewLifecycle(map[string]interface{}{
+		"acme.healthy":   &lifecycleModule{},
+		"acme.unhealthy": &lifecycleModule{healthErr: errors.New("data file missing")},
+		"acme.silent":    module{},
+	})
+
+	expected := map[string]ModuleHealth{
+		"acme.healthy":   {Healthy: true},
+		"acme.unhealthy": {Healthy: false, Error: "data file missing"},
+	}
+	assert.Equal(t, expected, lifecycle.Health(context.Background()))
+}
+
+func TestLifecycleReload(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    config.Modules
+		givenReloadErr error
+		expectedConfig json.RawMessage
+		expectedErr    string
+	}{
+		{
+			description:    "new-config-passed-to-module",
+			givenConfig:    config.Modules{"acme": {"foo": map[string]interface{}{"enabled": true, "attr": "val"}}},
+			expectedConfig: json.RawMessage(`{"attr":"val","enabled":true}`),
+		},
+		{
+			description:    "module-skipped-if-config-removed",
+			givenConfig:    config.Modules{"acme": {"bar": map[string]interface{}{"enabled": true}}},
+			expectedConfig: nil,
+		},
+		{
+			description:    "module-error-returned",
+			givenConfig:    config.Modules{"acme": {"foo": map[string]interface{}{"enabled": true}}},
+			givenReloadErr: errors.New("invalid data file"),
+			expectedConfig: json.RawMessage(`{"enabled":true}`),
+			expectedErr:    `failed to reload "acme.foo" module: invalid data file`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			reloadable := &lifecycleModule{reloadErr: test.givenReloadErr}
+			lifecycle := NewLifecycle(map[string]interface{}{"acme.foo": reloadable, "acme.bar": module{}})
+
+			err := lifecycle.Reload(test.givenConfig)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedConfig, reloadable.reloadedConfig)
+		})
+	}
+}
+
+func TestLifecycleNil(t *testing.T) {
+	var lifecycle *Lifecycle
+
+	assert.NotPanics(t, lifecycle.Shutdown)
+	assert.Empty(t, lifecycle.Health(context.Background()))
+	assert.NoError(t, lifecycle.Reload(config.Modules{}))
+}
+
+type lifecycleModule struct {
+	module
+	shutdown       bool
+	healthErr      error
+	reloadErr      error
+	reloadedConfig json.RawMessage
+}
+
+func (m *lifecycleModule) Shutdown() {
+	m.shutdown = true
+}
+
+func (m *lifecycleModule) Health(_ context.Context) error {
+	return m.healthErr
+}
+
+func (m *lifecycleModule) Reload(cfg json.RawMessage) error {
+	m.reloadedConfig = cfg
+	return m.reloadErr
+}
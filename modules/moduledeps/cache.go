@@ -0,0 +1,83 @@
+package moduledeps
+
+import (
+	"math"
+	"time"
+
+	"github.com/coocood/freecache"
+)
+
+// Cache is an in-memory key/value cache shared by modules. Entries may be evicted before they expire
+// when the cache is full, so it must not be used as a primary store.
+type Cache interface {
+	// Get returns the value stored for the key, if any.
+	Get(key string) ([]byte, bool)
+	// Set stores the value for the key. A zero ttl keeps the entry until it is evicted.
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete removes the key from the cache.
+	Delete(key string)
+	// Namespace returns a cache whose keys don't collide with the keys of other namespaces.
+	Namespace(name string) Cache
+}
+
+// NewCache returns a cache holding up to sizeBytes of entries. A non-positive size returns a cache that stores nothing.
+func NewCache(sizeBytes int) Cache {
+	if sizeBytes <= 0 {
+		return nilCache{}
+	}
+	return &sharedCache{cache: freecache.NewCache(sizeBytes)}
+}
+
+type sharedCache struct {
+	cache  *freecache.Cache
+	prefix string
+}
+
+func (c *sharedCache) Get(key string) ([]byte, bool) {
+	value, err := c.cache.Get(c.key(key))
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+func (c *sharedCache) Set(key string, value []byte, ttl time.Duration) error {
+	return c.cache.Set(c.key(key), value, ttlSeconds(ttl))
+}
+
+func (c *sharedCache) Delete(key string) {
+	c.cache.Del(c.key(key))
+}
+
+func (c *sharedCache) Namespace(name string) Cache {
+	// The separator can't be part of a namespace, so the keys of nested namespaces can't collide.
+	return &sharedCache{cache: c.cache, prefix: c.prefix + name + "\x00"}
+}
+
+func (c *sharedCache) key(key string) []byte {
+	return []byte(c.prefix + key)
+}
+
+// ttlSeconds rounds the ttl up to whole seconds, as freecache expires entries with a one second precision.
+func ttlSeconds(ttl time.Duration) int {
+	if ttl <= 0 {
+		return 0
+	}
+	return int(math.Ceil(ttl.Seconds()))
+}
+
+type nilCache struct{}
+
+func (nilCache) Get(string) ([]byte, bool) {
+	return nil, false
+}
+
+func (nilCache) Set(string, []byte, time.Duration) error {
+	return nil
+}
+
+func (nilCache) Delete(string) {}
+
+func (c nilCache) Namespace(string) Cache {
+	return c
+}
@@ -0,0 +1,57 @@
+package moduledeps
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	cache := NewCache(512 * 1024)
+
+	require.NoError(t, cache.Set("key", []byte("value"), 0))
+	value, ok := cache.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), value)
+
+	cache.Delete("key")
+	_, ok = cache.Get("key")
+	assert.False(t, ok)
+}
+
+func TestCacheNamespaces(t *testing.T) {
+	cache := NewCache(512 * 1024)
+	foo := cache.Namespace("acme.foo")
+	bar := cache.Namespace("acme.bar")
+
+	require.NoError(t, foo.Set("key", []byte("foo"), time.Minute))
+	require.NoError(t, bar.Set("key", []byte("bar"), time.Minute))
+
+	value, _ := foo.Get("key")
+	assert.Equal(t, []byte("foo"), value)
+	value, _ = bar.Get("key")
+	assert.Equal(t, []byte("bar"), value)
+
+	_, ok := cache.Get("key")
+	assert.False(t, ok, "namespaced keys aren't visible outside of their namespace")
+
+	bar.Delete("key")
+	_, ok = foo.Get("key")
+	assert.True(t, ok, "deleting a key doesn't affect other namespaces")
+}
+
+func TestCacheDisabled(t *testing.T) {
+	cache := NewCache(0).Namespace("acme.foo")
+
+	require.NoError(t, cache.Set("key", []byte("value"), 0))
+	_, ok := cache.Get("key")
+	assert.False(t, ok)
+}
+
+func TestTTLSeconds(t *testing.T) {
+	assert.Equal(t, 0, ttlSeconds(0))
+	assert.Equal(t, 1, ttlSeconds(100*time.Millisecond))
+	assert.Equal(t, 2, ttlSeconds(2*time.Second))
+}
@@ -1,14 +1,45 @@
 package moduledeps
 
 import (
+	"log/slog"
 	"net/http"
 
 	"github.com/prebid/prebid-server/v3/currency"
+	"github.com/prebid/prebid-server/v3/stored_requests"
 )
 
 // ModuleDeps provides dependencies that custom modules may need for hooks execution.
 // Additional dependencies can be added here if modules need something more.
+//
+// Modules receive their dependencies scoped with ForModule, so metrics, cache entries
+// and log messages of a module can't be confused with the ones of other modules.
 type ModuleDeps struct {
 	HTTPClient    *http.Client
 	RateConvertor *currency.RateConverter
+	// Metrics registers metrics named after the module.
+	Metrics MetricsRegistrar
+	// Cache is an in-memory cache namespaced to the module.
+	Cache Cache
+	// Logger tags messages with the module as the "module" attribute.
+	Logger *slog.Logger
+	// StoredRequestFetcher fetches stored requests, imps and responses.
+	StoredRequestFetcher stored_requests.Fetcher
+	// AccountFetcher fetches account configs.
+	AccountFetcher stored_requests.AccountFetcher
+	GeoLookup      GeoLookup
+	DeviceLookup   DeviceLookup
+}
+
+// ForModule returns the dependencies scoped to the module with the given "vendor.module_name" ID.
+func (d ModuleDeps) ForModule(id string) ModuleDeps {
+	if d.Metrics != nil {
+		d.Metrics = d.Metrics.Scope(id)
+	}
+	if d.Cache != nil {
+		d.Cache = d.Cache.Namespace(id)
+	}
+	if d.Logger != nil {
+		d.Logger = d.Logger.With("module", id)
+	}
+	return d
 }
@@ -0,0 +1,45 @@
+package moduledeps
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForModule(t *testing.T) {
+	var logs bytes.Buffer
+	cache := NewCache(512 * 1024)
+	deps := ModuleDeps{
+		Metrics: NewMetricsRegistry(),
+		Cache:   cache,
+		Logger:  slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{ReplaceAttr: dropTime})),
+	}
+
+	moduleDeps := deps.ForModule("acme.foo")
+
+	counter, err := moduleDeps.Metrics.Counter("hits", "")
+	require.NoError(t, err)
+	assert.Equal(t, "module_acme_foo_hits", counter.(*moduleMetric).promName)
+
+	require.NoError(t, moduleDeps.Cache.Set("key", []byte("value"), time.Minute))
+	value, _ := cache.Namespace("acme.foo").Get("key")
+	assert.Equal(t, []byte("value"), value)
+
+	moduleDeps.Logger.Info("built")
+	assert.Equal(t, "level=INFO msg=built module=acme.foo\n", logs.String())
+}
+
+func TestForModuleWithoutDeps(t *testing.T) {
+	assert.Equal(t, ModuleDeps{}, ModuleDeps{}.ForModule("acme.foo"))
+}
+
+func dropTime(_ []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return attr
+}
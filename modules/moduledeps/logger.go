@@ -0,0 +1,85 @@
+package moduledeps
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// glogDepth is the number of frames between the caller of a slog.Logger method and glogHandler.Handle
+const glogDepth = 3
+
+// NewLogger returns a structured logger writing to the glog output used by Prebid Server.
+// Debug messages are logged at glog verbosity level 2.
+func NewLogger() *slog.Logger {
+	return slog.New(&glogHandler{})
+}
+
+type glogHandler struct {
+	attrs  []slog.Attr
+	prefix string
+}
+
+func (h *glogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo || bool(glog.V(2))
+}
+
+func (h *glogHandler) Handle(_ context.Context, record slog.Record) error {
+	var message strings.Builder
+	message.WriteString(record.Message)
+	for _, attr := range h.attrs {
+		writeAttr(&message, "", attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&message, h.prefix, attr)
+		return true
+	})
+
+	switch {
+	case record.Level >= slog.LevelError:
+		glog.ErrorDepth(glogDepth, message.String())
+	case record.Level >= slog.LevelWarn:
+		glog.WarningDepth(glogDepth, message.String())
+	default:
+		glog.InfoDepth(glogDepth, message.String())
+	}
+	return nil
+}
+
+func (h *glogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	withAttrs := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	withAttrs = append(withAttrs, h.attrs...)
+	for _, attr := range attrs {
+		withAttrs = append(withAttrs, slog.Attr{Key: h.prefix + attr.Key, Value: attr.Value})
+	}
+	return &glogHandler{attrs: withAttrs, prefix: h.prefix}
+}
+
+func (h *glogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &glogHandler{attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+func writeAttr(message *strings.Builder, prefix string, attr slog.Attr) {
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			writeAttr(message, groupPrefix, groupAttr)
+		}
+		return
+	}
+
+	fmt.Fprintf(message, " %s%s=%v", prefix, attr.Key, attr.Value.Resolve())
+}
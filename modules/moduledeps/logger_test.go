@@ -0,0 +1,23 @@
+package moduledeps
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlogHandlerAttrs(t *testing.T) {
+	handler := (&glogHandler{}).WithAttrs([]slog.Attr{slog.String("module", "acme.foo")}).WithGroup("request").(*glogHandler)
+
+	var message strings.Builder
+	for _, attr := range handler.attrs {
+		writeAttr(&message, "", attr)
+	}
+	writeAttr(&message, handler.prefix, slog.Int("imps", 2))
+	writeAttr(&message, handler.prefix, slog.Group("device", slog.String("type", "phone")))
+	writeAttr(&message, handler.prefix, slog.Attr{})
+
+	assert.Equal(t, " module=acme.foo request.imps=2 request.device.type=phone", message.String())
+}
@@ -0,0 +1,61 @@
+package moduledeps
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/prebid/prebid-server/v3/floors"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/httputil"
+	"github.com/prebid/prebid-server/v3/util/iputil"
+)
+
+// GeoLookup resolves the location of the device making a request.
+type GeoLookup interface {
+	// Country returns the country code of the device or an empty string if it's unknown.
+	Country(request *openrtb_ext.RequestWrapper) string
+}
+
+// DeviceLookup resolves the attributes of the device making a request.
+type DeviceLookup interface {
+	// DeviceType returns "phone", "tablet" or "desktop" as used by price floors rules,
+	// or an empty string if the request has no user agent.
+	DeviceType(request *openrtb_ext.RequestWrapper) string
+	// IP returns the public IP address of the client sending the HTTP request or nil if it can't be found.
+	IP(r *http.Request) net.IP
+}
+
+// NewGeoLookup returns a GeoLookup reading the location provided in the request.
+func NewGeoLookup() GeoLookup {
+	return requestGeoLookup{}
+}
+
+// NewDeviceLookup returns a DeviceLookup matching the client IP address against the given validator.
+func NewDeviceLookup(ipValidator iputil.IPValidator) DeviceLookup {
+	return deviceLookup{ipValidator: ipValidator}
+}
+
+type requestGeoLookup struct{}
+
+func (requestGeoLookup) Country(request *openrtb_ext.RequestWrapper) string {
+	if request == nil || request.BidRequest == nil || request.Device == nil || request.Device.Geo == nil {
+		return ""
+	}
+	return request.Device.Geo.Country
+}
+
+type deviceLookup struct {
+	ipValidator iputil.IPValidator
+}
+
+func (deviceLookup) DeviceType(request *openrtb_ext.RequestWrapper) string {
+	if request == nil || request.BidRequest == nil || request.Device == nil || request.Device.UA == "" {
+		return ""
+	}
+	return floors.UserAgentDeviceType(request.Device.UA)
+}
+
+func (l deviceLookup) IP(r *http.Request) net.IP {
+	ip, _ := httputil.FindIP(r, l.ipValidator)
+	return ip
+}
@@ -0,0 +1,51 @@
+package moduledeps
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/iputil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoLookupCountry(t *testing.T) {
+	lookup := NewGeoLookup()
+
+	assert.Equal(t, "USA", lookup.Country(&openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Device: &openrtb2.Device{Geo: &openrtb2.Geo{Country: "USA"}}}}))
+	assert.Equal(t, "", lookup.Country(&openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Device: &openrtb2.Device{}}}))
+	assert.Equal(t, "", lookup.Country(nil))
+}
+
+func TestDeviceLookupDeviceType(t *testing.T) {
+	lookup := NewDeviceLookup(iputil.PublicNetworkIPValidator{})
+
+	testCases := []struct {
+		description string
+		userAgent   string
+		expected    string
+	}{
+		{description: "phone", userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)", expected: "phone"},
+		{description: "tablet", userAgent: "Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X)", expected: "tablet"},
+		{description: "desktop", userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64)", expected: "desktop"},
+		{description: "no-user-agent", userAgent: "", expected: ""},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			request := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Device: &openrtb2.Device{UA: test.userAgent}}}
+			assert.Equal(t, test.expected, lookup.DeviceType(request))
+		})
+	}
+}
+
+func TestDeviceLookupIP(t *testing.T) {
+	_, privateNetwork, _ := net.ParseCIDR("10.0.0.0/8")
+	lookup := NewDeviceLookup(iputil.PublicNetworkIPValidator{IPv4PrivateNetworks: []net.IPNet{*privateNetwork}})
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("X-Forwarded-For", "10.0.0.1, 203.0.113.7")
+	assert.Equal(t, net.ParseIP("203.0.113.7").String(), lookup.IP(request).String())
+}
@@ -0,0 +1,248 @@
+package moduledeps
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prometheus/client_golang/prometheus"
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+// MetricsRegistrar registers module defined metrics, which are exposed through
+// every metrics backend enabled by the host: go-metrics (InfluxDB) and Prometheus.
+type MetricsRegistrar interface {
+	// Counter returns the counter with the given name and label names, registering it on first use.
+	Counter(name, help string, labels ...string) (Counter, error)
+	// Histogram returns the histogram with the given name, buckets and label names, registering it on first use.
+	// go-metrics records observed values truncated to integers, so values should use units like milliseconds.
+	Histogram(name, help string, buckets []float64, labels ...string) (Histogram, error)
+	// Scope returns a registrar whose metric names are prefixed by the given scope.
+	Scope(name string) MetricsRegistrar
+}
+
+// Counter is a monotonically increasing module metric.
+type Counter interface {
+	// Add increases the counter by value for the given label values, passed in the order of the label names.
+	Add(value float64, labelValues ...string)
+}
+
+// Histogram is a module metric sampling observations into buckets.
+type Histogram interface {
+	// Observe adds the value to the histogram for the given label values, passed in the order of the label names.
+	Observe(value float64, labelValues ...string)
+}
+
+var metricNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+type metricKind int
+
+const (
+	counterKind metricKind = iota
+	histogramKind
+)
+
+// MetricsRegistry is the MetricsRegistrar shared by all modules. Modules can register metrics when they're
+// built, before the metrics backends are set up, so metrics only start recording once the registry is bound
+// to the backends with Bind.
+type MetricsRegistry struct {
+	scope []string
+	state *metricsState
+}
+
+type metricsState struct {
+	mu       sync.Mutex
+	metrics  map[string]*moduleMetric
+	backends *hostBackends
+}
+
+type hostBackends struct {
+	goRegistry   gometrics.Registry
+	promRegistry prometheus.Registerer
+	promConfig   config.PrometheusMetrics
+}
+
+// NewMetricsRegistry returns an unbound metrics registry.
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{state: &metricsState{metrics: make(map[string]*moduleMetric)}}
+}
+
+// Bind starts recording the registered metrics to the given backends. Either backend may be nil if disabled.
+func (r *MetricsRegistry) Bind(goRegistry gometrics.Registry, promRegistry prometheus.Registerer, promConfig config.PrometheusMetrics) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	r.state.backends = &hostBackends{goRegistry: goRegistry, promRegistry: promRegistry, promConfig: promConfig}
+
+	var errs []error
+	for _, metric := range r.state.metrics {
+		if err := metric.bind(r.state.backends); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *MetricsRegistry) Counter(name, help string, labels ...string) (Counter, error) {
+	return r.register(counterKind, name, help, nil, labels)
+}
+
+func (r *MetricsRegistry) Histogram(name, help string, buckets []float64, labels ...string) (Histogram, error) {
+	return r.register(histogramKind, name, help, buckets, labels)
+}
+
+func (r *MetricsRegistry) Scope(name string) MetricsRegistrar {
+	scope := make([]string, 0, len(r.scope)+1)
+	scope = append(scope, r.scope...)
+	scope = append(scope, sanitizeMetricName(name))
+	return &MetricsRegistry{scope: scope, state: r.state}
+}
+
+func (r *MetricsRegistry) register(kind metricKind, name, help string, buckets []float64, labels []string) (*moduleMetric, error) {
+	if !metricNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid metric name %q", name)
+	}
+	for _, label := range labels {
+		if !metricNamePattern.MatchString(label) {
+			return nil, fmt.Errorf("invalid label name %q for metric %q", label, name)
+		}
+	}
+
+	metric := &moduleMetric{
+		kind:     kind,
+		goName:   strings.Join(append(append([]string{"modules", "module"}, r.scope...), name), "."),
+		promName: strings.Join(append(append([]string{"module"}, r.scope...), name), "_"),
+		help:     help,
+		labels:   labels,
+		buckets:  buckets,
+	}
+
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	if registered, ok := r.state.metrics[metric.promName]; ok {
+		if registered.kind != kind || !slices.Equal(registered.labels, labels) {
+			return nil, fmt.Errorf("metric %q already registered with a different type or labels", name)
+		}
+		return registered, nil
+	}
+
+	if r.state.backends != nil {
+		if err := metric.bind(r.state.backends); err != nil {
+			return nil, err
+		}
+	}
+	r.state.metrics[metric.promName] = metric
+	return metric, nil
+}
+
+// moduleMetric implements both Counter and Histogram, recording according to its kind.
+type moduleMetric struct {
+	kind     metricKind
+	goName   string
+	promName string
+	help     string
+	labels   []string
+	buckets  []float64
+	bound    atomic.Pointer[boundMetric]
+}
+
+type boundMetric struct {
+	goRegistry    gometrics.Registry
+	promCounter   *prometheus.CounterVec
+	promHistogram *prometheus.HistogramVec
+}
+
+func (m *moduleMetric) bind(backends *hostBackends) error {
+	bound := &boundMetric{goRegistry: backends.goRegistry}
+
+	if backends.promRegistry != nil {
+		switch m.kind {
+		case counterKind:
+			bound.promCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: backends.promConfig.Namespace,
+				Subsystem: backends.promConfig.Subsystem,
+				Name:      m.promName,
+				Help:      m.help,
+			}, m.labels)
+			if err := backends.promRegistry.Register(bound.promCounter); err != nil {
+				return fmt.Errorf("failed to register metric %q: %s", m.promName, err)
+			}
+		case histogramKind:
+			bound.promHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace: backends.promConfig.Namespace,
+				Subsystem: backends.promConfig.Subsystem,
+				Name:      m.promName,
+				Help:      m.help,
+				Buckets:   m.buckets,
+			}, m.labels)
+			if err := backends.promRegistry.Register(bound.promHistogram); err != nil {
+				return fmt.Errorf("failed to register metric %q: %s", m.promName, err)
+			}
+		}
+	}
+
+	m.bound.Store(bound)
+	return nil
+}
+
+func (m *moduleMetric) Add(value float64, labelValues ...string) {
+	backends := m.bound.Load()
+	if backends == nil || len(labelValues) != len(m.labels) {
+		return
+	}
+
+	if backends.goRegistry != nil {
+		gometrics.GetOrRegisterMeter(m.goMetricName(labelValues), backends.goRegistry).Mark(int64(value))
+	}
+	if backends.promCounter != nil {
+		backends.promCounter.WithLabelValues(labelValues...).Add(value)
+	}
+}
+
+func (m *moduleMetric) Observe(value float64, labelValues ...string) {
+	backends := m.bound.Load()
+	if backends == nil || len(labelValues) != len(m.labels) {
+		return
+	}
+
+	if backends.goRegistry != nil {
+		histogram := gometrics.GetOrRegisterHistogram(m.goMetricName(labelValues), backends.goRegistry, gometrics.NewExpDecaySample(1028, 0.015))
+		histogram.Update(int64(value))
+	}
+	if backends.promHistogram != nil {
+		backends.promHistogram.WithLabelValues(labelValues...).Observe(value)
+	}
+}
+
+// goMetricName appends the label names and values to the metric name, e.g. "modules.module.acme_foo.hits.bidder.appnexus",
+// since go-metrics doesn't support labels.
+func (m *moduleMetric) goMetricName(labelValues []string) string {
+	if len(labelValues) == 0 {
+		return m.goName
+	}
+
+	var name strings.Builder
+	name.WriteString(m.goName)
+	for i, value := range labelValues {
+		name.WriteString(".")
+		name.WriteString(m.labels[i])
+		name.WriteString(".")
+		name.WriteString(value)
+	}
+	return name.String()
+}
+
+func sanitizeMetricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
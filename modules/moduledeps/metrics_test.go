@@ -0,0 +1,123 @@
+package moduledeps
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	gometrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsRegistryRecordsOnceBound(t *testing.T) {
+	registry := NewMetricsRegistry()
+	scoped := registry.Scope("acme.foo")
+
+	counter, err := scoped.Counter("hits", "Count of hits.", "bidder")
+	require.NoError(t, err)
+	histogram, err := scoped.Histogram("latency", "Latency in ms.", []float64{10, 100})
+	require.NoError(t, err)
+
+	// dropped before the registry is bound
+	counter.Add(1, "appnexus")
+
+	goRegistry := gometrics.NewRegistry()
+	promRegistry := prometheus.NewRegistry()
+	require.NoError(t, registry.Bind(goRegistry, promRegistry, config.PrometheusMetrics{Namespace: "pbs"}))
+
+	counter.Add(2, "appnexus")
+	histogram.Observe(42)
+
+	assert.Equal(t, int64(2), goRegistry.Get("modules.module.acme_foo.hits.bidder.appnexus").(gometrics.Meter).Count())
+	assert.Equal(t, int64(1), goRegistry.Get("modules.module.acme_foo.latency").(gometrics.Histogram).Count())
+
+	promCounter, err := scoped.Counter("hits", "Count of hits.", "bidder")
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, testutil.ToFloat64(promCounter.(*moduleMetric).bound.Load().promCounter.WithLabelValues("appnexus")))
+	families, err := promRegistry.Gather()
+	require.NoError(t, err)
+	names := make([]string, 0, len(families))
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	assert.ElementsMatch(t, []string{"pbs_module_acme_foo_hits", "pbs_module_acme_foo_latency"}, names)
+}
+
+func TestMetricsRegistryRegisteredAfterBind(t *testing.T) {
+	registry := NewMetricsRegistry()
+	goRegistry := gometrics.NewRegistry()
+	require.NoError(t, registry.Bind(goRegistry, nil, config.PrometheusMetrics{}))
+
+	counter, err := registry.Scope("acme.foo").Counter("hits", "Count of hits.")
+	require.NoError(t, err)
+	counter.Add(1)
+
+	// label values not matching the label names are ignored
+	counter.Add(1, "unexpected")
+
+	assert.Equal(t, int64(1), goRegistry.Get("modules.module.acme_foo.hits").(gometrics.Meter).Count())
+}
+
+func TestMetricsRegistryErrors(t *testing.T) {
+	registry := NewMetricsRegistry().Scope("acme.foo")
+	_, err := registry.Counter("hits", "Count of hits.", "bidder")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		description string
+		register    func() error
+		expectedErr string
+	}{
+		{
+			description: "invalid-name",
+			register: func() error {
+				_, err := registry.Counter("hits.total", "")
+				return err
+			},
+			expectedErr: `invalid metric name "hits.total"`,
+		},
+		{
+			description: "invalid-label",
+			register: func() error {
+				_, err := registry.Counter("misses", "", "bidder-name")
+				return err
+			},
+			expectedErr: `invalid label name "bidder-name" for metric "misses"`,
+		},
+		{
+			description: "different-labels",
+			register: func() error {
+				_, err := registry.Counter("hits", "", "account")
+				return err
+			},
+			expectedErr: `metric "hits" already registered with a different type or labels`,
+		},
+		{
+			description: "different-type",
+			register: func() error {
+				_, err := registry.Histogram("hits", "", nil, "bidder")
+				return err
+			},
+			expectedErr: `metric "hits" already registered with a different type or labels`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			assert.EqualError(t, test.register(), test.expectedErr)
+		})
+	}
+}
+
+func TestMetricsRegistryScopesDontCollide(t *testing.T) {
+	registry := NewMetricsRegistry()
+	promRegistry := prometheus.NewRegistry()
+	require.NoError(t, registry.Bind(nil, promRegistry, config.PrometheusMetrics{}))
+
+	_, err := registry.Scope("acme.foo").Counter("hits", "")
+	require.NoError(t, err)
+	_, err = registry.Scope("acme.bar").Counter("hits", "", "bidder")
+	require.NoError(t, err)
+}
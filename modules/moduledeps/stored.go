@@ -0,0 +1,52 @@
+package moduledeps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+
+	"github.com/prebid/prebid-server/v3/stored_requests"
+)
+
+var errStoredDataUnavailable = errors.New("stored data is not available until Prebid Server finished starting up")
+
+// StoredData gives modules access to the stored requests and accounts fetchers of Prebid Server.
+// Modules are built before the fetchers are set up, so fetching fails until they're provided with Set.
+type StoredData struct {
+	fetchers atomic.Pointer[storedDataFetchers]
+}
+
+type storedDataFetchers struct {
+	requests stored_requests.Fetcher
+	accounts stored_requests.AccountFetcher
+}
+
+// Set provides the fetchers used once Prebid Server is started.
+func (s *StoredData) Set(requests stored_requests.Fetcher, accounts stored_requests.AccountFetcher) {
+	s.fetchers.Store(&storedDataFetchers{requests: requests, accounts: accounts})
+}
+
+func (s *StoredData) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	fetchers := s.fetchers.Load()
+	if fetchers == nil || fetchers.requests == nil {
+		return nil, nil, []error{errStoredDataUnavailable}
+	}
+	return fetchers.requests.FetchRequests(ctx, requestIDs, impIDs)
+}
+
+func (s *StoredData) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	fetchers := s.fetchers.Load()
+	if fetchers == nil || fetchers.requests == nil {
+		return nil, []error{errStoredDataUnavailable}
+	}
+	return fetchers.requests.FetchResponses(ctx, ids)
+}
+
+func (s *StoredData) FetchAccount(ctx context.Context, accountDefaultJSON json.RawMessage, accountID string) (json.RawMessage, []error) {
+	fetchers := s.fetchers.Load()
+	if fetchers == nil || fetchers.accounts == nil {
+		return nil, []error{errStoredDataUnavailable}
+	}
+	return fetchers.accounts.FetchAccount(ctx, accountDefaultJSON, accountID)
+}
@@ -0,0 +1,54 @@
+package moduledeps
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoredData(t *testing.T) {
+	storedData := &StoredData{}
+
+	_, _, errs := storedData.FetchRequests(context.Background(), []string{"req"}, nil)
+	assert.Equal(t, []error{errStoredDataUnavailable}, errs)
+	_, errs = storedData.FetchAccount(context.Background(), nil, "acc")
+	assert.Equal(t, []error{errStoredDataUnavailable}, errs)
+
+	storedData.Set(fakeStoredFetcher{}, fakeStoredFetcher{})
+
+	requests, _, errs := storedData.FetchRequests(context.Background(), []string{"req"}, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]json.RawMessage{"req": json.RawMessage(`{}`)}, requests)
+
+	responses, errs := storedData.FetchResponses(context.Background(), []string{"resp"})
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]json.RawMessage{"resp": json.RawMessage(`{}`)}, responses)
+
+	account, errs := storedData.FetchAccount(context.Background(), nil, "acc")
+	assert.Empty(t, errs)
+	assert.Equal(t, json.RawMessage(`{"id":"acc"}`), account)
+}
+
+type fakeStoredFetcher struct{}
+
+func (fakeStoredFetcher) FetchRequests(_ context.Context, requestIDs []string, _ []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	requests := make(map[string]json.RawMessage, len(requestIDs))
+	for _, id := range requestIDs {
+		requests[id] = json.RawMessage(`{}`)
+	}
+	return requests, nil, nil
+}
+
+func (fakeStoredFetcher) FetchResponses(_ context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	responses := make(map[string]json.RawMessage, len(ids))
+	for _, id := range ids {
+		responses[id] = json.RawMessage(`{}`)
+	}
+	return responses, nil
+}
+
+func (fakeStoredFetcher) FetchAccount(_ context.Context, _ json.RawMessage, accountID string) (json.RawMessage, []error) {
+	return json.RawMessage(`{"id":"` + accountID + `"}`), nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/hooks"
 	"github.com/prebid/prebid-server/v3/modules/moduledeps"
+	"github.com/prebid/prebid-server/v3/modules/wasm"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 )
 
@@ -22,10 +23,10 @@ func NewBuilder() Builder {
 // implementing hook interfaces [github.com/prebid/prebid-server/hooks/hookstage].
 type Builder interface {
 	// Build initializes existing hook modules passing them config and other dependencies.
-	// It returns hook repository created based on the implemented hook interfaces by modules
-	// and a map of modules to a list of stage names for which module provides hooks
-	// or an error encountered during module initialization.
-	Build(cfg config.Modules, client moduledeps.ModuleDeps) (hooks.HookRepository, map[string][]string, error)
+	// It returns hook repository created based on the implemented hook interfaces by modules,
+	// a map of modules to a list of stage names for which module provides hooks
+	// and the lifecycle of the built modules or an error encountered during module initialization.
+	Build(cfg config.Modules, client moduledeps.ModuleDeps) (hooks.HookRepository, map[string][]string, *Lifecycle, error)
 }
 
 type (
@@ -43,15 +44,20 @@ type builder struct {
 //
 // The ID chosen for the module's hooks represents a fully qualified module path in the format
 // "vendor.module_name" and should be used to retrieve module hooks from the hooks.HookRepository.
+// Each module receives the dependencies scoped to this ID.
 //
-// Method returns a hooks.HookRepository and a map of modules to a list of stage names
-// for which module provides hooks or an error occurred during modules initialization.
+// Modules configured under the "wasm" vendor are WebAssembly modules loaded from the configured path,
+// so they don't need a builder compiled into Prebid Server.
+//
+// Method returns a hooks.HookRepository, a map of modules to a list of stage names
+// for which module provides hooks and a Lifecycle dispatching shutdown, health and reload
+// events to the built modules or an error occurred during modules initialization.
 func (m *builder) Build(
 	cfg config.Modules,
 	deps moduledeps.ModuleDeps,
-) (hooks.HookRepository, map[string][]string, error) {
+) (hooks.HookRepository, map[string][]string, *Lifecycle, error) {
 	modules := make(map[string]interface{})
-	for vendor, moduleBuilders := range m.builders {
+	for vendor, moduleBuilders := range m.withWASMModules(cfg) {
 		for moduleName, builder := range moduleBuilders {
 			var err error
 			var conf json.RawMessage
@@ -60,7 +66,7 @@ func (m *builder) Build(
 			id := fmt.Sprintf("%s.%s", vendor, moduleName)
 			if data, ok := cfg[vendor][moduleName]; ok {
 				if conf, err = jsonutil.Marshal(data); err != nil {
-					return nil, nil, fmt.Errorf(`failed to marshal "%s" module config: %s`, id, err)
+					return nil, nil, nil, fmt.Errorf(`failed to marshal "%s" module config: %s`, id, err)
 				}
 
 				if values, ok := data.(map[string]interface{}); ok {
@@ -75,9 +81,9 @@ func (m *builder) Build(
 				continue
 			}
 
-			module, err := builder(conf, deps)
+			module, err := builder(conf, deps.ForModule(id))
 			if err != nil {
-				return nil, nil, fmt.Errorf(`failed to init "%s" module: %s`, id, err)
+				return nil, nil, nil, fmt.Errorf(`failed to init "%s" module: %s`, id, err)
 			}
 
 			modules[id] = module
@@ -86,10 +92,36 @@ func (m *builder) Build(
 
 	collection, err := createModuleStageNamesCollection(modules)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	repo, err := hooks.NewHookRepository(modules)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return repo, collection, NewLifecycle(modules), nil
+}
+
+// withWASMModules returns the registered builders along with a WASM builder for every configured WASM module
+func (m *builder) withWASMModules(cfg config.Modules) ModuleBuilders {
+	if len(cfg[wasm.Vendor]) == 0 {
+		return m.builders
+	}
+
+	builders := make(ModuleBuilders, len(m.builders)+1)
+	for vendor, moduleBuilders := range m.builders {
+		builders[vendor] = moduleBuilders
+	}
+
+	wasmBuilders := make(map[string]ModuleBuilderFn, len(cfg[wasm.Vendor]))
+	for moduleName := range cfg[wasm.Vendor] {
+		wasmBuilders[moduleName] = wasm.Builder
+	}
+	for moduleName, builder := range m.builders[wasm.Vendor] {
+		wasmBuilders[moduleName] = builder
+	}
+	builders[wasm.Vendor] = wasmBuilders
 
-	return repo, collection, err
+	return builders
 }
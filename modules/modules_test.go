@@ -80,6 +80,13 @@ func TestModuleBuilderBuild(t *testing.T) {
 			expectedModulesStages: nil,
 			expectedErr:           fmt.Errorf(`failed to init "%s.%s" module: %s`, vendor, moduleName, "failed to build module"),
 		},
+		"Builds configured WASM modules": {
+			givenModule:           module{},
+			givenConfig:           map[string]map[string]interface{}{"wasm": {"filter": map[string]interface{}{"enabled": true, "path": "/nonexistent/filter.wasm"}}},
+			expectedHookRepo:      nil,
+			expectedModulesStages: nil,
+			expectedErr:           errors.New(`failed to init "wasm.filter" module: failed to read WASM module: open /nonexistent/filter.wasm: no such file or directory`),
+		},
 		"Fails if config marshaling returns error": {
 			givenModule:           module{},
 			givenConfig:           map[string]map[string]interface{}{vendor: {moduleName: math.Inf(1)}},
@@ -101,7 +108,7 @@ func TestModuleBuilderBuild(t *testing.T) {
 				},
 			}
 
-			repo, modulesStages, err := builder.Build(test.givenConfig, moduledeps.ModuleDeps{HTTPClient: http.DefaultClient})
+			repo, modulesStages, _, err := builder.Build(test.givenConfig, moduledeps.ModuleDeps{HTTPClient: http.DefaultClient})
 			assert.Equal(t, test.expectedErr, err)
 			assert.Equal(t, test.expectedModulesStages, modulesStages)
 			assert.Equal(t, test.expectedHookRepo, repo)
@@ -0,0 +1,45 @@
+package creativesafety
+
+import (
+	"github.com/prebid/prebid-server/v3/adapters"
+	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+)
+
+const creativeScanTag = "creative_scan"
+
+const rulesAnalyticKey = "rules"
+
+// creativesafety module has only 1 activity: `creative_scan`, results are added only for bids matching a rule
+func newCreativeScanTags() hookanalytics.Analytics {
+	return hookanalytics.Analytics{
+		Activities: []hookanalytics.Activity{
+			{
+				Name:   creativeScanTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+			},
+		},
+	}
+}
+
+func addBlockedAnalyticTag(result *hookstage.HookResult[hookstage.RawBidderResponsePayload], bidder string, bid *adapters.TypedBid, matches []match) {
+	addMatchAnalyticTag(result, hookanalytics.ResultStatusBlock, bidder, bid, matches)
+}
+
+func addFlaggedAnalyticTag(result *hookstage.HookResult[hookstage.RawBidderResponsePayload], bidder string, bid *adapters.TypedBid, matches []match) {
+	addMatchAnalyticTag(result, hookanalytics.ResultStatusAllow, bidder, bid, matches)
+}
+
+func addMatchAnalyticTag(result *hookstage.HookResult[hookstage.RawBidderResponsePayload], status hookanalytics.ResultStatus, bidder string, bid *adapters.TypedBid, matches []match) {
+	newResult := hookanalytics.Result{
+		Status: status,
+		Values: map[string]interface{}{rulesAnalyticKey: matches},
+		AppliedTo: hookanalytics.AppliedTo{
+			Bidder: bidder,
+			BidIds: []string{bid.Bid.ID},
+			ImpIds: []string{bid.Bid.ImpID},
+		},
+	}
+
+	result.AnalyticsTags.Activities[0].Results = append(result.AnalyticsTags.Activities[0].Results, newResult)
+}
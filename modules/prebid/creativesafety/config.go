@@ -0,0 +1,68 @@
+package creativesafety
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+const defaultRefreshIntervalSeconds = 60
+
+// config represents the host-level module configuration.
+type config struct {
+	// SignaturesFile is an optional path to a JSON file holding a signatureList.
+	// The file is reloaded whenever its modification time changes.
+	SignaturesFile         string        `json:"signatures_file"`
+	RefreshIntervalSeconds int           `json:"refresh_interval_seconds"`
+	Signatures             signatureList `json:"signatures"`
+	ReportOnly             bool          `json:"report_only"`
+}
+
+// invocationConfig represents the settings read from the config of each invocation: the host-level config merged
+// with the account-level overrides. The request-level overrides are left out, as bidders or publishers' pages
+// calling the auction mustn't be able to switch the scan off.
+type invocationConfig struct {
+	Enabled    *bool `json:"enabled"`
+	ReportOnly *bool `json:"report_only"`
+}
+
+// signatureList defines the signatures bid creatives are scanned against.
+type signatureList struct {
+	BlockedDomains     []string    `json:"blocked_domains"`
+	Regexes            []signature `json:"regexes"`
+	JSPatterns         []signature `json:"js_patterns"`
+	RedirectHeuristics bool        `json:"redirect_heuristics"`
+}
+
+// signature is a named pattern. Regexes are compiled as Go regular expressions,
+// JavaScript patterns are matched as case-insensitive substrings.
+type signature struct {
+	ID      string `json:"id"`
+	Pattern string `json:"pattern"`
+}
+
+func newConfig(data json.RawMessage) (config, error) {
+	cfg := config{RefreshIntervalSeconds: defaultRefreshIntervalSeconds}
+	if len(data) == 0 {
+		return cfg, nil
+	}
+	if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+	if cfg.RefreshIntervalSeconds <= 0 {
+		cfg.RefreshIntervalSeconds = defaultRefreshIntervalSeconds
+	}
+	return cfg, nil
+}
+
+func newInvocationConfig(data json.RawMessage) (invocationConfig, error) {
+	var cfg invocationConfig
+	if len(data) == 0 {
+		return cfg, nil
+	}
+	if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+	return cfg, nil
+}
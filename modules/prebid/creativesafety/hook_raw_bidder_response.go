@@ -0,0 +1,79 @@
+package creativesafety
+
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/v3/adapters"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+func handleRawBidderResponseHook(
+	rules *ruleSet,
+	reportOnly bool,
+	payload hookstage.RawBidderResponsePayload,
+) (result hookstage.HookResult[hookstage.RawBidderResponsePayload], err error) {
+	if payload.BidderResponse == nil || len(payload.BidderResponse.Bids) == 0 {
+		return result, nil
+	}
+
+	bidder := payload.Bidder
+	result.AnalyticsTags = newCreativeScanTags()
+
+	allowedBids := make([]*adapters.TypedBid, 0, len(payload.BidderResponse.Bids))
+	var nonBids []openrtb_ext.NonBid
+	for _, bid := range payload.BidderResponse.Bids {
+		if bid == nil || bid.Bid == nil {
+			allowedBids = append(allowedBids, bid)
+			continue
+		}
+
+		matches := rules.scan("adm", bid.Bid.AdM)
+		matches = append(matches, rules.scan("nurl", bid.Bid.NURL)...)
+		if len(matches) == 0 {
+			allowedBids = append(allowedBids, bid)
+			continue
+		}
+
+		if reportOnly {
+			addFlaggedAnalyticTag(&result, bidder, bid, matches)
+			allowedBids = append(allowedBids, bid)
+			continue
+		}
+
+		addBlockedAnalyticTag(&result, bidder, bid, matches)
+		result.DebugMessages = append(result.DebugMessages, fmt.Sprintf("Bid %s from bidder %s rejected by creative safety rule %s", bid.Bid.ID, bidder, matches[0].RuleID))
+		nonBids = append(nonBids, newNonBid(bid, payload.BidderResponse.Currency))
+	}
+
+	if len(nonBids) > 0 {
+		changeSet := hookstage.ChangeSet[hookstage.RawBidderResponsePayload]{}
+		changeSet.RawBidderResponse().Bids().UpdateBids(allowedBids)
+		result.ChangeSet = changeSet
+		result.SeatNonBid = []openrtb_ext.SeatNonBid{{Seat: bidder, NonBid: nonBids}}
+	}
+
+	return result, nil
+}
+
+func newNonBid(bid *adapters.TypedBid, currency string) openrtb_ext.NonBid {
+	return openrtb_ext.NonBid{
+		ImpId:      bid.Bid.ImpID,
+		StatusCode: int(openrtb_ext.ResponseRejectedCreativeMalware),
+		Ext: &openrtb_ext.NonBidExt{
+			Prebid: openrtb_ext.ExtResponseNonBidPrebid{Bid: openrtb_ext.NonBidObject{
+				Price:          bid.Bid.Price,
+				ADomain:        bid.Bid.ADomain,
+				CatTax:         bid.Bid.CatTax,
+				Cat:            bid.Bid.Cat,
+				DealID:         bid.Bid.DealID,
+				W:              bid.Bid.W,
+				H:              bid.Bid.H,
+				Dur:            bid.Bid.Dur,
+				MType:          bid.Bid.MType,
+				OriginalBidCPM: bid.Bid.Price,
+				OriginalBidCur: currency,
+			}},
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package creativesafety
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/modules/moduledeps"
+	"github.com/prebid/prebid-server/v3/util/task"
+)
+
+// Builder creates the creative safety module. Signatures are loaded at startup and,
+// if a signatures file is configured, reloaded in the background when the file changes.
+func Builder(rawConfig json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, err := newConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newSignatureStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	module := Module{cfg: cfg, signatures: store}
+	if cfg.SignaturesFile != "" {
+		module.reloads = task.NewReloadTask(time.Duration(cfg.RefreshIntervalSeconds)*time.Second, store.reload)
+		module.reloads.Start()
+	}
+
+	return module, nil
+}
+
+// Module scans bid creatives for malware and forced-redirect signatures.
+type Module struct {
+	cfg        config
+	signatures *signatureStore
+	// reloads is nil when the signatures are only configured inline
+	reloads *task.ReloadTask
+}
+
+// HandleRawBidderResponseHook rejects bids whose markup or win notice URL matches a signature.
+func (m Module) HandleRawBidderResponseHook(
+	_ context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.RawBidderResponsePayload,
+) (hookstage.HookResult[hookstage.RawBidderResponsePayload], error) {
+	result := hookstage.HookResult[hookstage.RawBidderResponsePayload]{}
+
+	// the request can't switch the scan off, so only the host and account config is read
+	invocationCfg, err := newInvocationConfig(miCtx.HostAccountConfig)
+	if err != nil {
+		return result, err
+	}
+	if invocationCfg.Enabled != nil && !*invocationCfg.Enabled {
+		return result, nil
+	}
+
+	reportOnly := m.cfg.ReportOnly
+	if invocationCfg.ReportOnly != nil {
+		reportOnly = *invocationCfg.ReportOnly
+	}
+
+	return handleRawBidderResponseHook(m.signatures.get(), reportOnly, payload)
+}
+
+// Shutdown stops reloading the signatures file.
+func (m Module) Shutdown() {
+	if m.reloads != nil {
+		m.reloads.Stop()
+	}
+}
+
+// Health reports the module unhealthy while the signatures file can't be reloaded.
+// Bids are still scanned with the previously loaded signatures in that case.
+func (m Module) Health(_ context.Context) error {
+	if m.reloads == nil {
+		return nil
+	}
+	if err := m.reloads.LastError(); err != nil {
+		return fmt.Errorf("serving stale signatures: %s", err)
+	}
+	return nil
+}
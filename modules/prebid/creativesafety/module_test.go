@@ -0,0 +1,162 @@
+package creativesafety
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/adapters"
+	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/modules/moduledeps"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = json.RawMessage(`
+{
+  "enabled": true,
+  "signatures": {
+    "blocked_domains": ["malware.example"],
+    "js_patterns": [{"id": "eval-atob", "pattern": "eval(atob("}],
+    "redirect_heuristics": true
+  }
+}`)
+
+func TestBuilder(t *testing.T) {
+	_, err := Builder(json.RawMessage(`{"signatures": {"regexes": [{"id": "bad", "pattern": "("}]}}`), moduledeps.ModuleDeps{})
+	assert.EqualError(t, err, "invalid regex signature \"bad\": error parsing regexp: missing closing ): `(`")
+
+	_, err = Builder(json.RawMessage(`{"signatures_file": "/does/not/exist.json"}`), moduledeps.ModuleDeps{})
+	assert.Error(t, err)
+
+	module, err := Builder(testConfig, moduledeps.ModuleDeps{})
+	assert.NoError(t, err)
+	assert.IsType(t, Module{}, module)
+}
+
+func TestHandleRawBidderResponseHook(t *testing.T) {
+	cleanBid := &adapters.TypedBid{Bid: &openrtb2.Bid{ID: "clean", ImpID: "imp1", Price: 1, AdM: `<a href="https://good.example/click"><img src="https://cdn.good.example/ad.png"></a>`}}
+	domainBid := &adapters.TypedBid{Bid: &openrtb2.Bid{ID: "domain", ImpID: "imp2", Price: 2, AdM: "<img>", NURL: "https://win.malware.example/notify", ADomain: []string{"adv.com"}}}
+	redirectBid := &adapters.TypedBid{Bid: &openrtb2.Bid{ID: "redirect", ImpID: "imp3", Price: 3, AdM: `<script>top.location = "https://landing.example";</script>`}}
+
+	testCases := []struct {
+		description       string
+		hostAccountConfig json.RawMessage
+		// effectiveConfig includes the request config, which must not change what the module enforces
+		effectiveConfig    json.RawMessage
+		bids               []*adapters.TypedBid
+		expectedBids       []*adapters.TypedBid
+		expectedSeatNonBid []openrtb_ext.SeatNonBid
+		expectedAnalytics  hookanalytics.Analytics
+	}{
+		{
+			description:       "no-matches",
+			bids:              []*adapters.TypedBid{cleanBid},
+			expectedBids:      []*adapters.TypedBid{cleanBid},
+			expectedAnalytics: newCreativeScanTags(),
+		},
+		{
+			description:     "matching-bids-rejected",
+			effectiveConfig: json.RawMessage(`{"enabled": false, "report_only": true}`),
+			bids:            []*adapters.TypedBid{cleanBid, domainBid, redirectBid},
+			expectedBids:    []*adapters.TypedBid{cleanBid},
+			expectedSeatNonBid: []openrtb_ext.SeatNonBid{
+				{
+					Seat: "appnexus",
+					NonBid: []openrtb_ext.NonBid{
+						{
+							ImpId:      "imp2",
+							StatusCode: 354,
+							Ext: &openrtb_ext.NonBidExt{Prebid: openrtb_ext.ExtResponseNonBidPrebid{Bid: openrtb_ext.NonBidObject{
+								Price: 2, ADomain: []string{"adv.com"}, OriginalBidCPM: 2, OriginalBidCur: "USD",
+							}}},
+						},
+						{
+							ImpId:      "imp3",
+							StatusCode: 354,
+							Ext: &openrtb_ext.NonBidExt{Prebid: openrtb_ext.ExtResponseNonBidPrebid{Bid: openrtb_ext.NonBidObject{
+								Price: 3, OriginalBidCPM: 3, OriginalBidCur: "USD",
+							}}},
+						},
+					},
+				},
+			},
+			expectedAnalytics: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+				Name:   creativeScanTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+				Results: []hookanalytics.Result{
+					{
+						Status:    hookanalytics.ResultStatusBlock,
+						Values:    map[string]interface{}{rulesAnalyticKey: []match{{RuleType: ruleTypeDomain, RuleID: "malware.example", Field: "nurl"}}},
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"domain"}, ImpIds: []string{"imp2"}},
+					},
+					{
+						Status:    hookanalytics.ResultStatusBlock,
+						Values:    map[string]interface{}{rulesAnalyticKey: []match{{RuleType: ruleTypeRedirect, RuleID: "top-location-assignment", Field: "adm"}}},
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"redirect"}, ImpIds: []string{"imp3"}},
+					},
+				},
+			}}},
+		},
+		{
+			description:       "report-only-account",
+			hostAccountConfig: json.RawMessage(`{"report_only": true}`),
+			bids:              []*adapters.TypedBid{cleanBid, domainBid},
+			expectedBids:      []*adapters.TypedBid{cleanBid, domainBid},
+			expectedAnalytics: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+				Name:   creativeScanTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+				Results: []hookanalytics.Result{
+					{
+						Status:    hookanalytics.ResultStatusAllow,
+						Values:    map[string]interface{}{rulesAnalyticKey: []match{{RuleType: ruleTypeDomain, RuleID: "malware.example", Field: "nurl"}}},
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"domain"}, ImpIds: []string{"imp2"}},
+					},
+				},
+			}}},
+		},
+		{
+			description:       "disabled-for-account",
+			hostAccountConfig: json.RawMessage(`{"enabled": false}`),
+			bids:              []*adapters.TypedBid{domainBid},
+			expectedBids:      []*adapters.TypedBid{domainBid},
+		},
+	}
+
+	module, err := Builder(testConfig, moduledeps.ModuleDeps{})
+	require.NoError(t, err)
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			payload := hookstage.RawBidderResponsePayload{
+				Bidder:         "appnexus",
+				BidderResponse: &adapters.BidderResponse{Currency: "USD", Bids: test.bids},
+			}
+			miCtx := hookstage.ModuleInvocationContext{Config: test.effectiveConfig, HostAccountConfig: test.hostAccountConfig}
+
+			result, err := module.(Module).HandleRawBidderResponseHook(context.Background(), miCtx, payload)
+			require.NoError(t, err)
+
+			for _, mut := range result.ChangeSet.Mutations() {
+				payload, err = mut.Apply(payload)
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedBids, payload.BidderResponse.Bids)
+			assert.Equal(t, test.expectedSeatNonBid, result.SeatNonBid)
+			assert.Equal(t, test.expectedAnalytics, result.AnalyticsTags)
+		})
+	}
+}
+
+func TestHandleRawBidderResponseHookInvalidConfig(t *testing.T) {
+	module, err := Builder(testConfig, moduledeps.ModuleDeps{})
+	require.NoError(t, err)
+
+	miCtx := hookstage.ModuleInvocationContext{HostAccountConfig: json.RawMessage(`{"report_only": "yes"}`)}
+	_, err = module.(Module).HandleRawBidderResponseHook(context.Background(), miCtx, hookstage.RawBidderResponsePayload{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package creativesafety
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+const (
+	ruleTypeDomain   = "blocked_domain"
+	ruleTypeRegex    = "regex"
+	ruleTypeJS       = "js_pattern"
+	ruleTypeRedirect = "redirect"
+)
+
+// redirectHeuristics detect creatives that navigate the page away without user interaction.
+var redirectHeuristics = []regexRule{
+	{id: "top-location-assignment", re: regexp.MustCompile(`(?i)\b(?:window\.)?(?:top|parent)\.location(?:\.href)?\s*=`)},
+	{id: "location-assignment", re: regexp.MustCompile(`(?i)\b(?:window|document)\.location(?:\.href)?\s*=`)},
+	{id: "location-replace", re: regexp.MustCompile(`(?i)\blocation\.(?:replace|assign)\s*\(`)},
+	{id: "meta-refresh", re: regexp.MustCompile(`(?i)<meta[^>]+http-equiv\s*=\s*["']?refresh`)},
+}
+
+var urlHostPattern = regexp.MustCompile(`(?i)(?:https?:)?//([a-z0-9][a-z0-9.-]*\.[a-z]{2,})`)
+
+type regexRule struct {
+	id string
+	re *regexp.Regexp
+}
+
+type substringRule struct {
+	id      string
+	pattern string
+}
+
+// ruleSet is the compiled, immutable form of a signatureList.
+type ruleSet struct {
+	blockedDomains []string
+	regexes        []regexRule
+	jsPatterns     []substringRule
+	redirect       bool
+}
+
+// match describes a rule that fired on a creative field.
+type match struct {
+	RuleType string `json:"type"`
+	RuleID   string `json:"rule"`
+	Field    string `json:"field"`
+}
+
+func compileSignatures(lists ...signatureList) (*ruleSet, error) {
+	rules := &ruleSet{}
+	for _, list := range lists {
+		for _, domain := range list.BlockedDomains {
+			domain = strings.ToLower(strings.TrimSpace(domain))
+			if domain != "" {
+				rules.blockedDomains = append(rules.blockedDomains, domain)
+			}
+		}
+		for _, sig := range list.Regexes {
+			re, err := regexp.Compile(sig.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex signature %q: %s", sig.ID, err)
+			}
+			rules.regexes = append(rules.regexes, regexRule{id: signatureID(sig), re: re})
+		}
+		for _, sig := range list.JSPatterns {
+			if sig.Pattern == "" {
+				return nil, fmt.Errorf("empty js pattern signature %q", sig.ID)
+			}
+			rules.jsPatterns = append(rules.jsPatterns, substringRule{id: signatureID(sig), pattern: strings.ToLower(sig.Pattern)})
+		}
+		rules.redirect = rules.redirect || list.RedirectHeuristics
+	}
+	return rules, nil
+}
+
+func signatureID(sig signature) string {
+	if sig.ID != "" {
+		return sig.ID
+	}
+	return sig.Pattern
+}
+
+// scan returns every rule matching the given creative field value.
+func (r *ruleSet) scan(field, value string) []match {
+	if value == "" {
+		return nil
+	}
+
+	var matches []match
+	// markup embedded in JSON (e.g. native) may have escaped slashes
+	normalized := strings.ReplaceAll(value, `\/`, `/`)
+	lowered := strings.ToLower(normalized)
+
+	if len(r.blockedDomains) > 0 {
+		for _, host := range extractHosts(normalized) {
+			if domain, blocked := r.blockedDomain(host); blocked {
+				matches = append(matches, match{RuleType: ruleTypeDomain, RuleID: domain, Field: field})
+			}
+		}
+	}
+	for _, rule := range r.regexes {
+		if rule.re.MatchString(normalized) {
+			matches = append(matches, match{RuleType: ruleTypeRegex, RuleID: rule.id, Field: field})
+		}
+	}
+	for _, rule := range r.jsPatterns {
+		if strings.Contains(lowered, rule.pattern) {
+			matches = append(matches, match{RuleType: ruleTypeJS, RuleID: rule.id, Field: field})
+		}
+	}
+	if r.redirect {
+		for _, rule := range redirectHeuristics {
+			if rule.re.MatchString(normalized) {
+				matches = append(matches, match{RuleType: ruleTypeRedirect, RuleID: rule.id, Field: field})
+			}
+		}
+	}
+	return matches
+}
+
+func (r *ruleSet) blockedDomain(host string) (string, bool) {
+	for _, domain := range r.blockedDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return domain, true
+		}
+	}
+	return "", false
+}
+
+func extractHosts(value string) []string {
+	found := urlHostPattern.FindAllStringSubmatch(value, -1)
+	hosts := make([]string, 0, len(found))
+	seen := make(map[string]struct{}, len(found))
+	for _, f := range found {
+		host := strings.ToLower(f[1])
+		if _, ok := seen[host]; !ok {
+			seen[host] = struct{}{}
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// signatureStore holds the active rule set and reloads the signatures file when it changes.
+type signatureStore struct {
+	inline  signatureList
+	path    string
+	rules   atomic.Pointer[ruleSet]
+	modTime time.Time
+	mu      sync.Mutex
+}
+
+func newSignatureStore(cfg config) (*signatureStore, error) {
+	store := &signatureStore{inline: cfg.Signatures, path: cfg.SignaturesFile}
+	if err := store.refresh(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *signatureStore) get() *ruleSet {
+	return s.rules.Load()
+}
+
+// refresh reloads the signatures file if it was modified since the last load.
+// The active rule set is kept if the file can't be read or contains invalid signatures.
+func (s *signatureStore) refresh() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" {
+		if s.rules.Load() != nil {
+			return nil
+		}
+		rules, err := compileSignatures(s.inline)
+		if err != nil {
+			return err
+		}
+		s.rules.Store(rules)
+		return nil
+	}
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat signatures file: %s", err)
+	}
+	if s.rules.Load() != nil && info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read signatures file: %s", err)
+	}
+	var fileSignatures signatureList
+	if err := jsonutil.UnmarshalValid(data, &fileSignatures); err != nil {
+		return fmt.Errorf("failed to parse signatures file: %s", err)
+	}
+	rules, err := compileSignatures(s.inline, fileSignatures)
+	if err != nil {
+		return err
+	}
+
+	s.rules.Store(rules)
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// reload refreshes the signatures file in the background. A failure is logged and bids go on being
+// scanned with the rule set that was active before.
+func (s *signatureStore) reload() error {
+	err := s.refresh()
+	if err != nil {
+		glog.Errorf("creativesafety: keeping previous signatures, reload failed: %s", err)
+	}
+	return err
+}
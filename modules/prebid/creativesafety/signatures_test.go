@@ -0,0 +1,129 @@
+package creativesafety
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/util/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScan(t *testing.T) {
+	rules, err := compileSignatures(signatureList{
+		BlockedDomains:     []string{"Malware.example "},
+		Regexes:            []signature{{ID: "coinhive", Pattern: `coinhive(\.min)?\.js`}},
+		JSPatterns:         []signature{{Pattern: "eval(atob("}},
+		RedirectHeuristics: true,
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		description string
+		value       string
+		expected    []match
+	}{
+		{
+			description: "empty",
+			value:       "",
+			expected:    nil,
+		},
+		{
+			description: "clean",
+			value:       `<a href="https://notmalware.example/"><img src="//cdn.good.example/a.png"></a>`,
+			expected:    nil,
+		},
+		{
+			description: "blocked-subdomain-protocol-relative",
+			value:       `<script src="//cdn.MALWARE.example/x.js"></script>`,
+			expected:    []match{{RuleType: ruleTypeDomain, RuleID: "malware.example", Field: "adm"}},
+		},
+		{
+			description: "blocked-domain-json-escaped",
+			value:       `{"assets":[{"img":{"url":"https:\/\/malware.example\/a.png"}}]}`,
+			expected:    []match{{RuleType: ruleTypeDomain, RuleID: "malware.example", Field: "adm"}},
+		},
+		{
+			description: "regex-and-js-pattern",
+			value:       `<script src="coinhive.min.js"></script><script>EVAL(ATOB("YWxlcnQoMSk="))</script>`,
+			expected: []match{
+				{RuleType: ruleTypeRegex, RuleID: "coinhive", Field: "adm"},
+				{RuleType: ruleTypeJS, RuleID: "eval(atob(", Field: "adm"},
+			},
+		},
+		{
+			description: "redirect-heuristics",
+			value:       `<meta http-equiv="refresh" content="0"><script>window.location.replace("x")</script>`,
+			expected: []match{
+				{RuleType: ruleTypeRedirect, RuleID: "location-replace", Field: "adm"},
+				{RuleType: ruleTypeRedirect, RuleID: "meta-refresh", Field: "adm"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, rules.scan("adm", test.value))
+		})
+	}
+}
+
+func TestCompileSignaturesInvalid(t *testing.T) {
+	_, err := compileSignatures(signatureList{JSPatterns: []signature{{ID: "empty"}}})
+	assert.EqualError(t, err, `empty js pattern signature "empty"`)
+}
+
+func TestSignatureStoreRefresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"blocked_domains": ["first.example"]}`), 0644))
+
+	store, err := newSignatureStore(config{SignaturesFile: path, Signatures: signatureList{BlockedDomains: []string{"inline.example"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"inline.example", "first.example"}, store.get().blockedDomains)
+
+	// changed file is picked up
+	require.NoError(t, os.WriteFile(path, []byte(`{"blocked_domains": ["second.example"]}`), 0644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	require.NoError(t, store.refresh())
+	assert.Equal(t, []string{"inline.example", "second.example"}, store.get().blockedDomains)
+
+	// invalid file keeps previous signatures
+	require.NoError(t, os.WriteFile(path, []byte(`{"regexes": [{"pattern": "("}]}`), 0644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+	assert.Error(t, store.refresh())
+	assert.Equal(t, []string{"inline.example", "second.example"}, store.get().blockedDomains)
+
+	// deleted file keeps previous signatures
+	require.NoError(t, os.Remove(path))
+	assert.Error(t, store.refresh())
+	assert.Equal(t, []string{"inline.example", "second.example"}, store.get().blockedDomains)
+}
+
+func TestSignatureStoreWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"blocked_domains": ["first.example"]}`), 0644))
+
+	store, err := newSignatureStore(config{SignaturesFile: path})
+	require.NoError(t, err)
+	module := Module{signatures: store, reloads: task.NewReloadTask(10*time.Millisecond, store.reload)}
+
+	module.reloads.Start()
+	defer module.Shutdown()
+
+	assert.NoError(t, module.Health(context.Background()))
+
+	// failed reloads are reported until the file can be loaded again
+	require.NoError(t, os.Remove(path))
+	assert.Eventually(t, func() bool { return module.Health(context.Background()) != nil }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"blocked_domains": ["second.example"]}`), 0644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	assert.Eventually(t, func() bool { return module.Health(context.Background()) == nil }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"second.example"}, store.get().blockedDomains)
+
+	// shutting down more than once is safe
+	module.Shutdown()
+}
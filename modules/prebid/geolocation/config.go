@@ -0,0 +1,64 @@
+package geolocation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+const defaultRefreshIntervalSeconds = 60
+
+// config represents the host-level module configuration.
+type config struct {
+	// Databases are paths to MaxMind DB (MMDB) files. Each one is optional and
+	// reloaded whenever its modification time changes.
+	Databases              databases `json:"databases"`
+	RefreshIntervalSeconds int       `json:"refresh_interval_seconds"`
+	// Overwrite replaces the geo values sent in the request, instead of only filling in the missing ones.
+	Overwrite bool `json:"overwrite"`
+}
+
+type databases struct {
+	// City is a GeoIP2/GeoLite2 City or Country database.
+	City string `json:"city"`
+	// ISP is a GeoIP2 ISP database.
+	ISP string `json:"isp"`
+	// ConnectionType is a GeoIP2 Connection Type database.
+	ConnectionType string `json:"connection_type"`
+}
+
+// invocationConfig represents the settings read from the effective config of each invocation: the host-level
+// config merged with the account-level and request-level overrides.
+type invocationConfig struct {
+	Enabled   *bool `json:"enabled"`
+	Overwrite *bool `json:"overwrite"`
+}
+
+func newConfig(data json.RawMessage) (config, error) {
+	cfg := config{RefreshIntervalSeconds: defaultRefreshIntervalSeconds}
+	if len(data) > 0 {
+		if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("failed to parse config: %s", err)
+		}
+	}
+	if cfg.Databases == (databases{}) {
+		return cfg, errors.New("at least one database must be configured")
+	}
+	if cfg.RefreshIntervalSeconds <= 0 {
+		cfg.RefreshIntervalSeconds = defaultRefreshIntervalSeconds
+	}
+	return cfg, nil
+}
+
+func newInvocationConfig(data json.RawMessage) (invocationConfig, error) {
+	var cfg invocationConfig
+	if len(data) == 0 {
+		return cfg, nil
+	}
+	if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+	return cfg, nil
+}
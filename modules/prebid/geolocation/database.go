@@ -0,0 +1,217 @@
+package geolocation
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/prebid/openrtb/v20/adcom1"
+)
+
+// location is the geolocation resolved for an IP address.
+type location struct {
+	Country        string
+	Region         string
+	Metro          string
+	City           string
+	ZIP            string
+	Lat            *float64
+	Lon            *float64
+	Accuracy       int64
+	ISP            string
+	ConnectionType *adcom1.ConnectionType
+}
+
+type cityRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Location struct {
+		AccuracyRadius uint16   `maxminddb:"accuracy_radius"`
+		Latitude       *float64 `maxminddb:"latitude"`
+		Longitude      *float64 `maxminddb:"longitude"`
+		MetroCode      uint     `maxminddb:"metro_code"`
+	} `maxminddb:"location"`
+	Postal struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"postal"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+}
+
+type ispRecord struct {
+	ISP string `maxminddb:"isp"`
+}
+
+type connectionTypeRecord struct {
+	ConnectionType string `maxminddb:"connection_type"`
+}
+
+// connectionTypes maps the MaxMind connection types to the OpenRTB ones. Dialup and satellite
+// connections have no OpenRTB equivalent.
+var connectionTypes = map[string]adcom1.ConnectionType{
+	"Cable/DSL": adcom1.ConnectionEthernet,
+	"Corporate": adcom1.ConnectionEthernet,
+	"Cellular":  adcom1.ConnectionCellular,
+}
+
+// databaseStore holds the configured databases and reloads them when their files change.
+type databaseStore struct {
+	city           *databaseFile
+	isp            *databaseFile
+	connectionType *databaseFile
+}
+
+// databaseFile is an MMDB file loaded in memory, so it can be replaced while lookups are in progress.
+type databaseFile struct {
+	path    string
+	reader  atomic.Pointer[maxminddb.Reader]
+	modTime time.Time
+	mu      sync.Mutex
+}
+
+func newDatabaseStore(cfg databases) (*databaseStore, error) {
+	store := &databaseStore{
+		city:           newDatabaseFile(cfg.City),
+		isp:            newDatabaseFile(cfg.ISP),
+		connectionType: newDatabaseFile(cfg.ConnectionType),
+	}
+	if err := store.refresh(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func newDatabaseFile(path string) *databaseFile {
+	if path == "" {
+		return nil
+	}
+	return &databaseFile{path: path}
+}
+
+// refresh reloads the database files modified since they were last loaded.
+// A database keeps serving its previous content if the new file can't be loaded.
+func (s *databaseStore) refresh() error {
+	var errs []error
+	for _, file := range []*databaseFile{s.city, s.isp, s.connectionType} {
+		if file == nil {
+			continue
+		}
+		if err := file.refresh(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f *databaseFile) refresh() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat database %s: %s", f.path, err)
+	}
+	if f.reader.Load() != nil && info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to read database %s: %s", f.path, err)
+	}
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return fmt.Errorf("failed to open database %s: %s", f.path, err)
+	}
+
+	f.reader.Store(reader)
+	f.modTime = info.ModTime()
+	return nil
+}
+
+// lookup decodes the record of the IP address into result. It returns false if the database
+// isn't configured or has no record for the IP address.
+func (f *databaseFile) lookup(ip net.IP, result interface{}) (bool, error) {
+	if f == nil {
+		return false, nil
+	}
+
+	_, found, err := f.reader.Load().LookupNetwork(ip, result)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up %s in %s: %s", ip, f.path, err)
+	}
+	return found, nil
+}
+
+// lookup resolves the location of the IP address from all configured databases.
+func (s *databaseStore) lookup(ip net.IP) (location, bool, error) {
+	var loc location
+	var resolved bool
+
+	var city cityRecord
+	found, err := s.city.lookup(ip, &city)
+	if err != nil {
+		return loc, false, err
+	}
+	if found {
+		resolved = true
+		loc.Country = alpha3Countries[city.Country.ISOCode]
+		if len(city.Subdivisions) > 0 {
+			loc.Region = city.Subdivisions[0].ISOCode
+		}
+		if city.Location.MetroCode > 0 {
+			loc.Metro = fmt.Sprint(city.Location.MetroCode)
+		}
+		loc.City = city.City.Names["en"]
+		loc.ZIP = city.Postal.Code
+		loc.Lat = city.Location.Latitude
+		loc.Lon = city.Location.Longitude
+		// the accuracy radius is in kilometers while OpenRTB expects meters
+		loc.Accuracy = int64(city.Location.AccuracyRadius) * 1000
+	}
+
+	var isp ispRecord
+	found, err = s.isp.lookup(ip, &isp)
+	if err != nil {
+		return loc, false, err
+	}
+	if found {
+		resolved = true
+		loc.ISP = isp.ISP
+	}
+
+	var connection connectionTypeRecord
+	found, err = s.connectionType.lookup(ip, &connection)
+	if err != nil {
+		return loc, false, err
+	}
+	if found {
+		resolved = true
+		if connectionType, ok := connectionTypes[connection.ConnectionType]; ok {
+			loc.ConnectionType = &connectionType
+		}
+	}
+
+	return loc, resolved, nil
+}
+
+// reload refreshes the databases in the background. Failures are logged, as lookups go on with the
+// databases loaded before.
+func (s *databaseStore) reload() error {
+	err := s.refresh()
+	if err != nil {
+		glog.Errorf("geolocation: keeping previous databases, reload failed: %s", err)
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+package geolocation
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCityNetworks = []testNetwork{
+	{
+		cidr: "203.0.113.0/24",
+		record: map[string]interface{}{
+			"city":         map[string]interface{}{"names": map[string]interface{}{"en": "Mountain View"}},
+			"country":      map[string]interface{}{"iso_code": "US"},
+			"location":     map[string]interface{}{"accuracy_radius": uint16(5), "latitude": 37.386, "longitude": -122.0838, "metro_code": uint16(807)},
+			"postal":       map[string]interface{}{"code": "94035"},
+			"subdivisions": []interface{}{map[string]interface{}{"iso_code": "CA"}},
+		},
+	},
+	{
+		cidr: "2001:db8::/32",
+		record: map[string]interface{}{
+			"country":  map[string]interface{}{"iso_code": "DE"},
+			"location": map[string]interface{}{"accuracy_radius": uint16(100), "latitude": 51.2993, "longitude": 9.491},
+		},
+	},
+}
+
+func newTestDatabases(t *testing.T) (databases, string) {
+	dir := t.TempDir()
+	cfg := databases{
+		City:           filepath.Join(dir, "city.mmdb"),
+		ISP:            filepath.Join(dir, "isp.mmdb"),
+		ConnectionType: filepath.Join(dir, "connection-type.mmdb"),
+	}
+	writeTestDatabase(t, cfg.City, testCityNetworks...)
+	writeTestDatabase(t, cfg.ISP, testNetwork{cidr: "203.0.113.0/24", record: map[string]interface{}{"isp": "Example ISP"}})
+	writeTestDatabase(t, cfg.ConnectionType, testNetwork{cidr: "203.0.113.0/24", record: map[string]interface{}{"connection_type": "Cellular"}})
+	return cfg, dir
+}
+
+func TestDatabaseStoreLookup(t *testing.T) {
+	cfg, _ := newTestDatabases(t)
+	store, err := newDatabaseStore(cfg)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		description      string
+		ip               string
+		expectedLocation location
+		expectedFound    bool
+	}{
+		{
+			description: "ipv4-all-databases",
+			ip:          "203.0.113.7",
+			expectedLocation: location{
+				Country:        "USA",
+				Region:         "CA",
+				Metro:          "807",
+				City:           "Mountain View",
+				ZIP:            "94035",
+				Lat:            ptrutil.ToPtr(37.386),
+				Lon:            ptrutil.ToPtr(-122.0838),
+				Accuracy:       5000,
+				ISP:            "Example ISP",
+				ConnectionType: ptrutil.ToPtr(adcom1.ConnectionCellular),
+			},
+			expectedFound: true,
+		},
+		{
+			description: "ipv6-city-database",
+			ip:          "2001:db8::1",
+			expectedLocation: location{
+				Country:  "DEU",
+				Lat:      ptrutil.ToPtr(51.2993),
+				Lon:      ptrutil.ToPtr(9.491),
+				Accuracy: 100000,
+			},
+			expectedFound: true,
+		},
+		{
+			description:   "not-found",
+			ip:            "198.51.100.1",
+			expectedFound: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			loc, found, err := store.lookup(net.ParseIP(test.ip))
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedFound, found)
+			assert.Equal(t, test.expectedLocation, loc)
+		})
+	}
+}
+
+func TestDatabaseStoreRefresh(t *testing.T) {
+	cfg, _ := newTestDatabases(t)
+	store, err := newDatabaseStore(databases{City: cfg.City})
+	require.NoError(t, err)
+
+	// changed file is picked up
+	writeTestDatabase(t, cfg.City, testNetwork{cidr: "203.0.113.0/24", record: map[string]interface{}{"country": map[string]interface{}{"iso_code": "FR"}}})
+	require.NoError(t, os.Chtimes(cfg.City, time.Now(), time.Now().Add(time.Minute)))
+	require.NoError(t, store.refresh())
+	loc, _, err := store.lookup(net.ParseIP("203.0.113.7"))
+	require.NoError(t, err)
+	assert.Equal(t, "FRA", loc.Country)
+
+	// invalid file keeps previous database
+	require.NoError(t, os.WriteFile(cfg.City, []byte("not a database"), 0644))
+	require.NoError(t, os.Chtimes(cfg.City, time.Now(), time.Now().Add(2*time.Minute)))
+	assert.Error(t, store.refresh())
+	loc, _, err = store.lookup(net.ParseIP("203.0.113.7"))
+	require.NoError(t, err)
+	assert.Equal(t, "FRA", loc.Country)
+
+	// deleted file keeps previous database
+	require.NoError(t, os.Remove(cfg.City))
+	assert.Error(t, store.refresh())
+	loc, _, err = store.lookup(net.ParseIP("203.0.113.7"))
+	require.NoError(t, err)
+	assert.Equal(t, "FRA", loc.Country)
+}
+
+func TestNewDatabaseStoreMissingFile(t *testing.T) {
+	_, err := newDatabaseStore(databases{City: "/does/not/exist.mmdb"})
+	assert.ErrorContains(t, err, "failed to stat database /does/not/exist.mmdb")
+}
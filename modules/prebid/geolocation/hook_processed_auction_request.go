@@ -0,0 +1,118 @@
+package geolocation
+
+import (
+	"net"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+)
+
+func handleProcessedAuctionHook(
+	databases *databaseStore,
+	overwrite bool,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	result := hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{}
+
+	if payload.Request == nil || payload.Request.BidRequest == nil || payload.Request.Device == nil {
+		return result, nil
+	}
+
+	device := payload.Request.Device
+	seenIP := device.IP
+	if seenIP == "" {
+		seenIP = device.IPv6
+	}
+	ip := net.ParseIP(seenIP)
+	if ip == nil {
+		return result, nil
+	}
+
+	loc, found, err := databases.lookup(ip)
+	if err != nil {
+		return result, err
+	}
+	if !found {
+		result.DebugMessages = append(result.DebugMessages, "no location found for the device IP address")
+		return result, nil
+	}
+
+	changeSet := hookstage.ChangeSet[hookstage.ProcessedAuctionRequestPayload]{}
+	changeSet.AddMutation(func(payload hookstage.ProcessedAuctionRequestPayload) (hookstage.ProcessedAuctionRequestPayload, error) {
+		if payload.Request.Device == nil {
+			return payload, nil
+		}
+
+		// PBS truncates the IP address seen by the module if ActivityTransmitPreciseGeo is denied for it,
+		// in which case only the country and region resolved from the truncated address are filled in.
+		precise := payload.Request.Device.IP == seenIP || payload.Request.Device.IPv6 == seenIP
+		payload.Request.Device = applyLocation(payload.Request.Device, loc, precise, overwrite)
+		return payload, nil
+	}, hookstage.MutationUpdate, "bidrequest", "device", "geo")
+	result.ChangeSet = changeSet
+
+	return result, nil
+}
+
+// applyLocation returns a copy of the device with the location filled in. Values sent in the request
+// are only replaced if overwrite is set.
+func applyLocation(device *openrtb2.Device, loc location, precise, overwrite bool) *openrtb2.Device {
+	deviceCopy := *device
+	geo := &openrtb2.Geo{}
+	if device.Geo != nil {
+		geoCopy := *device.Geo
+		geo = &geoCopy
+	}
+
+	filled := setString(&geo.Country, loc.Country, overwrite)
+	filled = setString(&geo.Region, loc.Region, overwrite) || filled
+
+	if precise {
+		filled = setString(&geo.Metro, loc.Metro, overwrite) || filled
+		filled = setString(&geo.City, loc.City, overwrite) || filled
+		filled = setString(&geo.ZIP, loc.ZIP, overwrite) || filled
+
+		if loc.Lat != nil && loc.Lon != nil && (geo.Lat == nil || geo.Lon == nil || overwrite) {
+			lat, lon := *loc.Lat, *loc.Lon
+			geo.Lat = &lat
+			geo.Lon = &lon
+			geo.Accuracy = loc.Accuracy
+			geo.Type = adcom1.LocationIP
+			geo.IPService = adcom1.LocationServiceMaxMind
+			filled = true
+		}
+	}
+
+	if filled && geo.Type == 0 {
+		geo.Type = adcom1.LocationIP
+		geo.IPService = adcom1.LocationServiceMaxMind
+	}
+
+	if loc.ConnectionType != nil && (deviceCopy.ConnectionType == nil || overwrite) {
+		connectionType := *loc.ConnectionType
+		deviceCopy.ConnectionType = &connectionType
+	}
+	// the ISP of a wired or wifi connection isn't a mobile carrier
+	if isCellular(deviceCopy.ConnectionType) {
+		setString(&deviceCopy.Carrier, loc.ISP, overwrite)
+	}
+
+	if filled {
+		deviceCopy.Geo = geo
+	}
+	return &deviceCopy
+}
+
+func isCellular(connectionType *adcom1.ConnectionType) bool {
+	return connectionType != nil && *connectionType >= adcom1.ConnectionCellular && *connectionType <= adcom1.Connection5G
+}
+
+// setString sets the field to the value, if any, and returns whether the field was set.
+func setString(field *string, value string, overwrite bool) bool {
+	if value == "" || (*field != "" && !overwrite) {
+		return false
+	}
+	*field = value
+	return true
+}
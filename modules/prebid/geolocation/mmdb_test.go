@@ -0,0 +1,153 @@
+package geolocation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testNetwork is a network and the record a test database returns for the addresses in it.
+type testNetwork struct {
+	cidr   string
+	record map[string]interface{}
+}
+
+const (
+	recordEmpty = iota
+	recordNode
+	recordData
+)
+
+type testNode struct {
+	kinds  [2]int
+	values [2]int
+}
+
+// writeTestDatabase writes an IPv6 MaxMind DB with 24 bit records holding the given networks.
+// IPv4 networks are stored in the IPv4-compatible ::/96 subtree, like MaxMind does.
+func writeTestDatabase(t *testing.T, path string, networks ...testNetwork) {
+	nodes := []testNode{{}}
+	var data bytes.Buffer
+
+	for _, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network.cidr)
+		require.NoError(t, err)
+
+		ip := ipNet.IP.To16()
+		ones, _ := ipNet.Mask.Size()
+		if ipv4 := ipNet.IP.To4(); ipv4 != nil {
+			ip = append(make(net.IP, 12), ipv4...)
+			ones += 96
+		}
+
+		offset := data.Len()
+		encodeValue(&data, network.record)
+
+		current := 0
+		for i := 0; i < ones; i++ {
+			bit := int(ip[i/8]>>(7-uint(i%8))) & 1
+			if i == ones-1 {
+				nodes[current].kinds[bit] = recordData
+				nodes[current].values[bit] = offset
+				break
+			}
+			if nodes[current].kinds[bit] != recordNode {
+				nodes = append(nodes, testNode{})
+				nodes[current].kinds[bit] = recordNode
+				nodes[current].values[bit] = len(nodes) - 1
+			}
+			current = nodes[current].values[bit]
+		}
+	}
+
+	var file bytes.Buffer
+	nodeCount := len(nodes)
+	for _, node := range nodes {
+		for bit := 0; bit < 2; bit++ {
+			value := nodeCount
+			switch node.kinds[bit] {
+			case recordNode:
+				value = node.values[bit]
+			case recordData:
+				value = nodeCount + 16 + node.values[bit]
+			}
+			file.Write([]byte{byte(value >> 16), byte(value >> 8), byte(value)})
+		}
+	}
+	file.Write(make([]byte, 16))
+	file.Write(data.Bytes())
+	file.WriteString("\xab\xcd\xefMaxMind.com")
+	encodeValue(&file, map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1700000000),
+		"database_type":               "Test",
+		"description":                 map[string]interface{}{"en": "Test database"},
+		"ip_version":                  uint16(6),
+		"languages":                   []interface{}{"en"},
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(24),
+	})
+
+	require.NoError(t, os.WriteFile(path, file.Bytes(), 0644))
+}
+
+// encodeValue encodes the value in the MaxMind DB data section format.
+func encodeValue(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		writeControl(buf, 2, len(v))
+		buf.WriteString(v)
+	case float64:
+		writeControl(buf, 3, 8)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case uint16:
+		writeUint(buf, 5, uint64(v))
+	case uint32:
+		writeUint(buf, 6, uint64(v))
+	case uint64:
+		writeUint(buf, 9, v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		writeControl(buf, 7, len(keys))
+		for _, key := range keys {
+			encodeValue(buf, key)
+			encodeValue(buf, v[key])
+		}
+	case []interface{}:
+		writeControl(buf, 11, len(v))
+		for _, item := range v {
+			encodeValue(buf, item)
+		}
+	default:
+		panic("unsupported test database value")
+	}
+}
+
+func writeUint(buf *bytes.Buffer, dataType int, value uint64) {
+	var bytes []byte
+	for ; value > 0; value >>= 8 {
+		bytes = append([]byte{byte(value)}, bytes...)
+	}
+	writeControl(buf, dataType, len(bytes))
+	buf.Write(bytes)
+}
+
+func writeControl(buf *bytes.Buffer, dataType, size int) {
+	if dataType <= 7 {
+		buf.WriteByte(byte(dataType<<5 | size))
+		return
+	}
+	buf.WriteByte(byte(size))
+	buf.WriteByte(byte(dataType - 7))
+}
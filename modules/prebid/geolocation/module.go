@@ -0,0 +1,79 @@
+// Package geolocation resolves device.geo from the device IP address using local MaxMind DB files,
+// so country dependent logic like price floors, the GDPR default for EEA countries and activity rules
+// also works for requests sent without a location.
+package geolocation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/modules/moduledeps"
+	"github.com/prebid/prebid-server/v3/util/task"
+)
+
+// Builder creates the geolocation module. Databases are loaded at startup and reloaded
+// in the background when their files change.
+func Builder(rawConfig json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, err := newConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newDatabaseStore(cfg.Databases)
+	if err != nil {
+		return nil, err
+	}
+
+	reloads := task.NewReloadTask(time.Duration(cfg.RefreshIntervalSeconds)*time.Second, store.reload)
+	reloads.Start()
+
+	return Module{cfg: cfg, databases: store, reloads: reloads}, nil
+}
+
+// Module fills in the device geolocation, carrier and connection type resolved from the device IP address.
+type Module struct {
+	cfg       config
+	databases *databaseStore
+	reloads   *task.ReloadTask
+}
+
+// HandleProcessedAuctionHook resolves the location of the device IP address.
+func (m Module) HandleProcessedAuctionHook(
+	_ context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	result := hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{}
+
+	invocationCfg, err := newInvocationConfig(miCtx.Config)
+	if err != nil {
+		return result, err
+	}
+	if invocationCfg.Enabled != nil && !*invocationCfg.Enabled {
+		return result, nil
+	}
+
+	overwrite := m.cfg.Overwrite
+	if invocationCfg.Overwrite != nil {
+		overwrite = *invocationCfg.Overwrite
+	}
+
+	return handleProcessedAuctionHook(m.databases, overwrite, payload)
+}
+
+// Shutdown stops reloading the databases.
+func (m Module) Shutdown() {
+	m.reloads.Stop()
+}
+
+// Health reports the module unhealthy while a database can't be reloaded.
+// Locations are still resolved with the previously loaded databases in that case.
+func (m Module) Health(_ context.Context) error {
+	if err := m.reloads.LastError(); err != nil {
+		return fmt.Errorf("serving stale databases: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package geolocation
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/modules/moduledeps"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/prebid/prebid-server/v3/util/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	cfg, _ := newTestDatabases(t)
+
+	_, err := Builder(json.RawMessage(`{"enabled": true}`), moduledeps.ModuleDeps{})
+	assert.EqualError(t, err, "at least one database must be configured")
+
+	_, err = Builder(json.RawMessage(`{"databases": {"city": "/does/not/exist.mmdb"}}`), moduledeps.ModuleDeps{})
+	assert.Error(t, err)
+
+	module, err := Builder(json.RawMessage(`{"databases": {"city": "`+cfg.City+`"}}`), moduledeps.ModuleDeps{})
+	require.NoError(t, err)
+	assert.IsType(t, Module{}, module)
+	module.(Module).Shutdown()
+}
+
+func TestHandleProcessedAuctionHook(t *testing.T) {
+	preciseGeo := &openrtb2.Geo{
+		Country:   "USA",
+		Region:    "CA",
+		Metro:     "807",
+		City:      "Mountain View",
+		ZIP:       "94035",
+		Lat:       ptrutil.ToPtr(37.386),
+		Lon:       ptrutil.ToPtr(-122.0838),
+		Accuracy:  5000,
+		Type:      adcom1.LocationIP,
+		IPService: adcom1.LocationServiceMaxMind,
+	}
+
+	testCases := []struct {
+		description      string
+		moduleConfig     string
+		invocationConfig json.RawMessage
+		device           *openrtb2.Device
+		seenIP           string
+		expectedDevice   *openrtb2.Device
+		expectedMessage  []string
+	}{
+		{
+			description: "location-filled-in",
+			device:      &openrtb2.Device{IP: "203.0.113.7"},
+			expectedDevice: &openrtb2.Device{
+				IP:             "203.0.113.7",
+				Geo:            preciseGeo,
+				Carrier:        "Example ISP",
+				ConnectionType: ptrutil.ToPtr(adcom1.ConnectionCellular),
+			},
+		},
+		{
+			description: "ipv6-location-filled-in",
+			device:      &openrtb2.Device{IPv6: "2001:db8::1"},
+			expectedDevice: &openrtb2.Device{
+				IPv6: "2001:db8::1",
+				Geo: &openrtb2.Geo{
+					Country:   "DEU",
+					Lat:       ptrutil.ToPtr(51.2993),
+					Lon:       ptrutil.ToPtr(9.491),
+					Accuracy:  100000,
+					Type:      adcom1.LocationIP,
+					IPService: adcom1.LocationServiceMaxMind,
+				},
+			},
+		},
+		{
+			description: "truncated-ip-only-fills-country-and-region",
+			device:      &openrtb2.Device{IP: "203.0.113.7"},
+			seenIP:      "203.0.113.0",
+			expectedDevice: &openrtb2.Device{
+				IP:             "203.0.113.7",
+				Geo:            &openrtb2.Geo{Country: "USA", Region: "CA", Type: adcom1.LocationIP, IPService: adcom1.LocationServiceMaxMind},
+				Carrier:        "Example ISP",
+				ConnectionType: ptrutil.ToPtr(adcom1.ConnectionCellular),
+			},
+		},
+		{
+			description: "request-values-kept",
+			device:      &openrtb2.Device{IP: "203.0.113.7", Carrier: "Carrier", Geo: &openrtb2.Geo{Country: "CAN", Type: adcom1.LocationGPS}},
+			expectedDevice: &openrtb2.Device{
+				IP:      "203.0.113.7",
+				Carrier: "Carrier",
+				Geo: &openrtb2.Geo{
+					Country:  "CAN",
+					Region:   "CA",
+					Metro:    "807",
+					City:     "Mountain View",
+					ZIP:      "94035",
+					Lat:      ptrutil.ToPtr(37.386),
+					Lon:      ptrutil.ToPtr(-122.0838),
+					Accuracy: 5000,
+					// lat and lon were resolved from the IP address
+					Type:      adcom1.LocationIP,
+					IPService: adcom1.LocationServiceMaxMind,
+				},
+				ConnectionType: ptrutil.ToPtr(adcom1.ConnectionCellular),
+			},
+		},
+		{
+			description: "carrier-not-filled-in-for-non-cellular-connection",
+			device:      &openrtb2.Device{IP: "203.0.113.7", ConnectionType: ptrutil.ToPtr(adcom1.ConnectionWIFI)},
+			expectedDevice: &openrtb2.Device{
+				IP:             "203.0.113.7",
+				Geo:            preciseGeo,
+				ConnectionType: ptrutil.ToPtr(adcom1.ConnectionWIFI),
+			},
+		},
+		{
+			description: "carrier-filled-in-for-cellular-generation",
+			device:      &openrtb2.Device{IP: "203.0.113.7", ConnectionType: ptrutil.ToPtr(adcom1.Connection4G)},
+			expectedDevice: &openrtb2.Device{
+				IP:             "203.0.113.7",
+				Geo:            preciseGeo,
+				Carrier:        "Example ISP",
+				ConnectionType: ptrutil.ToPtr(adcom1.Connection4G),
+			},
+		},
+		{
+			description:      "request-values-overwritten-by-account",
+			invocationConfig: json.RawMessage(`{"overwrite": true}`),
+			device:           &openrtb2.Device{IP: "203.0.113.7", Carrier: "Carrier", Geo: &openrtb2.Geo{Country: "CAN"}},
+			expectedDevice: &openrtb2.Device{
+				IP:             "203.0.113.7",
+				Geo:            preciseGeo,
+				Carrier:        "Example ISP",
+				ConnectionType: ptrutil.ToPtr(adcom1.ConnectionCellular),
+			},
+		},
+		{
+			description:      "disabled-by-account",
+			invocationConfig: json.RawMessage(`{"enabled": false}`),
+			device:           &openrtb2.Device{IP: "203.0.113.7"},
+			expectedDevice:   &openrtb2.Device{IP: "203.0.113.7"},
+		},
+		{
+			description:     "location-not-found",
+			device:          &openrtb2.Device{IP: "198.51.100.1"},
+			expectedDevice:  &openrtb2.Device{IP: "198.51.100.1"},
+			expectedMessage: []string{"no location found for the device IP address"},
+		},
+		{
+			description:    "invalid-ip",
+			device:         &openrtb2.Device{IP: "invalid"},
+			expectedDevice: &openrtb2.Device{IP: "invalid"},
+		},
+	}
+
+	cfg, _ := newTestDatabases(t)
+	rawConfig, err := json.Marshal(map[string]interface{}{"databases": cfg})
+	require.NoError(t, err)
+	module, err := Builder(rawConfig, moduledeps.ModuleDeps{})
+	require.NoError(t, err)
+	t.Cleanup(module.(Module).Shutdown)
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			payload := hookstage.ProcessedAuctionRequestPayload{
+				Request: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Device: test.device}},
+			}
+
+			// the hook sees a scrubbed copy of the request when precise geo is denied for the module
+			seenPayload := payload
+			if test.seenIP != "" {
+				seenDevice := *test.device
+				seenDevice.IP = test.seenIP
+				seenPayload = hookstage.ProcessedAuctionRequestPayload{
+					Request: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Device: &seenDevice}},
+				}
+			}
+
+			miCtx := hookstage.ModuleInvocationContext{Config: test.invocationConfig}
+			result, err := module.(Module).HandleProcessedAuctionHook(context.Background(), miCtx, seenPayload)
+			require.NoError(t, err)
+
+			for _, mut := range result.ChangeSet.Mutations() {
+				payload, err = mut.Apply(payload)
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedDevice, payload.Request.Device)
+			assert.Equal(t, test.expectedMessage, result.DebugMessages)
+		})
+	}
+}
+
+func TestHandleProcessedAuctionHookInvalidConfig(t *testing.T) {
+	cfg, _ := newTestDatabases(t)
+	module, err := Builder(json.RawMessage(`{"databases": {"city": "`+cfg.City+`"}}`), moduledeps.ModuleDeps{})
+	require.NoError(t, err)
+	t.Cleanup(module.(Module).Shutdown)
+
+	miCtx := hookstage.ModuleInvocationContext{Config: json.RawMessage(`{"overwrite": "yes"}`)}
+	_, err = module.(Module).HandleProcessedAuctionHook(context.Background(), miCtx, hookstage.ProcessedAuctionRequestPayload{})
+	assert.Error(t, err)
+}
+
+func TestHealth(t *testing.T) {
+	cfg, _ := newTestDatabases(t)
+	store, err := newDatabaseStore(databases{City: cfg.City})
+	require.NoError(t, err)
+	module := Module{databases: store, reloads: task.NewReloadTask(time.Hour, store.reload)}
+
+	assert.NoError(t, module.Health(context.Background()))
+
+	// starting the reloads refreshes the databases right away
+	require.NoError(t, os.Remove(cfg.City))
+	module.reloads.Start()
+	t.Cleanup(module.Shutdown)
+	assert.ErrorContains(t, module.Health(context.Background()), "serving stale databases")
+}
@@ -0,0 +1,261 @@
+package rulesengine
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/currency"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// updateRequest applies the actions of the rules to the request. The request is edited as JSON,
+// so the wrapper is replaced by a new one holding the updated request. The floors are converted
+// with the rates of the request, falling back to the PBS rates.
+func updateRequest(wrapper *openrtb_ext.RequestWrapper, rules []rule, rates *currency.RateConverter) error {
+	if wrapper == nil || wrapper.BidRequest == nil {
+		return nil
+	}
+	conversions, err := requestConversions(wrapper, rates)
+	if err != nil {
+		return err
+	}
+	if err := wrapper.RebuildRequest(); err != nil {
+		return err
+	}
+
+	data, err := jsonutil.Marshal(wrapper.BidRequest)
+	if err != nil {
+		return err
+	}
+	for _, r := range rules {
+		for _, a := range r.actions {
+			if data, err = a.apply(data, conversions); err != nil {
+				return fmt.Errorf(`failed to apply %s action of rule "%s": %s`, a.kind, r.id, err)
+			}
+		}
+	}
+
+	request := &openrtb2.BidRequest{}
+	if err := jsonutil.Unmarshal(data, request); err != nil {
+		return fmt.Errorf("failed to parse the updated request: %s", err)
+	}
+	*wrapper = openrtb_ext.RequestWrapper{BidRequest: request}
+	return nil
+}
+
+func requestConversions(wrapper *openrtb_ext.RequestWrapper, rates *currency.RateConverter) (currency.Conversions, error) {
+	reqExt, err := wrapper.GetRequestExt()
+	if err != nil {
+		return nil, err
+	}
+	var requestRates *openrtb_ext.ExtRequestCurrency
+	if prebid := reqExt.GetPrebid(); prebid != nil {
+		requestRates = prebid.CurrencyConversions
+	}
+	return currency.GetAuctionCurrencyRates(rates, requestRates), nil
+}
+
+func (a action) apply(request []byte, conversions currency.Conversions) ([]byte, error) {
+	switch a.kind {
+	case actionExcludeBidders:
+		return removeImpBidders(request, a.bidders)
+	case actionSetField:
+		return setPath(request, a.path, a.value)
+	case actionRemoveField:
+		return removePath(request, a.path), nil
+	case actionAddBCat:
+		return addStrings(request, a.values, "bcat")
+	case actionAddBAdv:
+		return addStrings(request, a.values, "badv")
+	case actionSetFloor:
+		return setFloor(request, a.floor, a.currency, conversions)
+	case actionAddTargeting:
+		return appendValue(request, a.target, "ext", "prebid", "adservertargeting")
+	}
+	// bidders are excluded from a bidder request by rejecting it
+	return request, nil
+}
+
+// setPath sets the value at the path, creating missing objects. If the path goes through an array,
+// the value is set in every element of the array.
+func setPath(data []byte, path []string, value []byte) ([]byte, error) {
+	if len(path) > 1 {
+		child, dataType, _, err := jsonparser.Get(data, path[0])
+		if err == nil && (dataType == jsonparser.Array || dataType == jsonparser.Object) {
+			updated, err := updateChild(child, dataType, func(elem []byte) ([]byte, error) {
+				return setPath(elem, path[1:], value)
+			})
+			if err != nil {
+				return nil, err
+			}
+			return jsonparser.Set(data, updated, path[0])
+		}
+	}
+	return jsonparser.Set(data, value, path...)
+}
+
+// removePath removes the value at the path. If the path goes through an array,
+// the value is removed from every element of the array.
+func removePath(data []byte, path []string) []byte {
+	if len(path) == 1 {
+		return jsonparser.Delete(data, path[0])
+	}
+
+	child, dataType, _, err := jsonparser.Get(data, path[0])
+	if err != nil || (dataType != jsonparser.Array && dataType != jsonparser.Object) {
+		return data
+	}
+	updated, _ := updateChild(child, dataType, func(elem []byte) ([]byte, error) {
+		return removePath(elem, path[1:]), nil
+	})
+	if updated, err := jsonparser.Set(data, updated, path[0]); err == nil {
+		return updated
+	}
+	return data
+}
+
+// updateChild applies fn to the object, or to every object of the array. Objects are copied before being
+// passed to fn, as jsonparser.Delete edits its input in place.
+func updateChild(child []byte, dataType jsonparser.ValueType, fn func([]byte) ([]byte, error)) ([]byte, error) {
+	if dataType == jsonparser.Object {
+		return fn(slices.Clone(child))
+	}
+
+	updated := child
+	for i, n := 0, arrayLen(child); i < n; i++ {
+		index := "[" + strconv.Itoa(i) + "]"
+		elem, elemType, _, err := jsonparser.Get(updated, index)
+		if err != nil || elemType != jsonparser.Object {
+			continue
+		}
+		elem, err = fn(slices.Clone(elem))
+		if err != nil {
+			return nil, err
+		}
+		if updated, err = jsonparser.Set(updated, elem, index); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+// removeImpBidders removes the bidders from imp.ext.prebid.bidder of every imp. Bidder names are case insensitive.
+func removeImpBidders(request []byte, bidders map[string]struct{}) ([]byte, error) {
+	imps, dataType, _, err := jsonparser.Get(request, "imp")
+	if err != nil || dataType != jsonparser.Array {
+		return request, nil
+	}
+
+	updated, err := updateChild(imps, dataType, func(imp []byte) ([]byte, error) {
+		var excluded []string
+		jsonparser.ObjectEach(imp, func(key []byte, _ []byte, _ jsonparser.ValueType, _ int) error {
+			if _, ok := bidders[strings.ToLower(string(key))]; ok {
+				excluded = append(excluded, string(key))
+			}
+			return nil
+		}, "ext", "prebid", "bidder")
+		for _, bidder := range excluded {
+			imp = jsonparser.Delete(imp, "ext", "prebid", "bidder", bidder)
+		}
+		return imp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jsonparser.Set(request, updated, "imp")
+}
+
+// addStrings adds the values missing from the array of strings at the path.
+func addStrings(data []byte, values []string, path ...string) ([]byte, error) {
+	current := lookup(data, jsonparser.Object, path)
+	missing := false
+	for _, value := range values {
+		if !slices.Contains(current, value) {
+			current = append(current, value)
+			missing = true
+		}
+	}
+	if !missing {
+		return data, nil
+	}
+
+	array, err := jsonutil.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	return jsonparser.Set(data, array, path...)
+}
+
+// appendValue appends the value to the array at the path.
+func appendValue(data []byte, value []byte, path ...string) ([]byte, error) {
+	array, dataType, _, err := jsonparser.Get(data, path...)
+	if err != nil || dataType != jsonparser.Array || arrayLen(array) == 0 {
+		return jsonparser.Set(data, append(append([]byte("["), value...), ']'), path...)
+	}
+
+	end := strings.LastIndexByte(string(array), ']')
+	updated := make([]byte, 0, len(array)+len(value)+1)
+	updated = append(updated, array[:end]...)
+	updated = append(updated, ',')
+	updated = append(updated, value...)
+	updated = append(updated, array[end:]...)
+	return jsonparser.Set(data, updated, path...)
+}
+
+// setFloor raises the floor of every imp to the floor of the rule. The floors are compared in the currency of
+// the imp, and an imp keeps its floor if it's as high or if the rule floor can't be converted to its currency,
+// so an existing floor is never lowered.
+func setFloor(request []byte, floor float64, floorCurrency string, conversions currency.Conversions) ([]byte, error) {
+	imps, dataType, _, err := jsonparser.Get(request, "imp")
+	if err != nil || dataType != jsonparser.Array {
+		return request, nil
+	}
+
+	floorValue := []byte(strconv.FormatFloat(floor, 'f', -1, 64))
+	currencyValue := []byte(strconv.Quote(floorCurrency))
+	updated, err := updateChild(imps, dataType, func(imp []byte) ([]byte, error) {
+		if impFloor, err := jsonparser.GetFloat(imp, "bidfloor"); err == nil && impFloor > 0 {
+			impCurrency, _ := jsonparser.GetString(imp, "bidfloorcur")
+			if impCurrency == "" {
+				impCurrency = defaultFloorCurrency
+			}
+			rate, err := conversionRate(conversions, floorCurrency, impCurrency)
+			if err != nil || impFloor >= floor*rate {
+				return imp, nil
+			}
+		}
+
+		imp, err := jsonparser.Set(imp, floorValue, "bidfloor")
+		if err != nil {
+			return nil, err
+		}
+		return jsonparser.Set(imp, currencyValue, "bidfloorcur")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jsonparser.Set(request, updated, "imp")
+}
+
+func conversionRate(conversions currency.Conversions, from, to string) (float64, error) {
+	if strings.EqualFold(from, to) {
+		return 1, nil
+	}
+	if conversions == nil {
+		return 0, fmt.Errorf("no rate to convert %s to %s", from, to)
+	}
+	return conversions.GetRate(from, to)
+}
+
+func arrayLen(array []byte) int {
+	n := 0
+	jsonparser.ArrayEach(array, func(_ []byte, _ jsonparser.ValueType, _ int, _ error) {
+		n++
+	})
+	return n
+}
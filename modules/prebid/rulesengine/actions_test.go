@@ -0,0 +1,135 @@
+package rulesengine
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/currency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionApply(t *testing.T) {
+	request := `{"imp":[{"id":"imp1","bidfloor":2,"bidfloorcur":"USD","ext":{"prebid":{"bidder":{"appnexus":{},"Rubicon":{}}}}},{"id":"imp2","bidfloor":0.5,"ext":{"prebid":{"bidder":{"rubicon":{}}}}}],"bcat":["IAB25"],"user":{"eids":[{"source":"a"}]}}`
+
+	testCases := []struct {
+		description string
+		action      action
+		expected    string
+	}{
+		{
+			description: "exclude-bidders",
+			action:      action{kind: actionExcludeBidders, bidders: map[string]struct{}{"rubicon": {}}},
+			expected:    `{"imp":[{"id":"imp1","bidfloor":2,"bidfloorcur":"USD","ext":{"prebid":{"bidder":{"appnexus":{}}}}},{"id":"imp2","bidfloor":0.5,"ext":{"prebid":{"bidder":{}}}}],"bcat":["IAB25"],"user":{"eids":[{"source":"a"}]}}`,
+		},
+		{
+			description: "set-field",
+			action:      action{kind: actionSetField, path: []string{"regs", "coppa"}, value: []byte(`1`)},
+			expected:    `{"imp":[{"id":"imp1","bidfloor":2,"bidfloorcur":"USD","ext":{"prebid":{"bidder":{"appnexus":{},"Rubicon":{}}}}},{"id":"imp2","bidfloor":0.5,"ext":{"prebid":{"bidder":{"rubicon":{}}}}}],"bcat":["IAB25"],"user":{"eids":[{"source":"a"}]},"regs":{"coppa":1}}`,
+		},
+		{
+			description: "set-field-in-every-imp",
+			action:      action{kind: actionSetField, path: []string{"imp", "secure"}, value: []byte(`1`)},
+			expected:    `{"imp":[{"id":"imp1","bidfloor":2,"bidfloorcur":"USD","ext":{"prebid":{"bidder":{"appnexus":{},"Rubicon":{}}}},"secure":1},{"id":"imp2","bidfloor":0.5,"ext":{"prebid":{"bidder":{"rubicon":{}}}},"secure":1}],"bcat":["IAB25"],"user":{"eids":[{"source":"a"}]}}`,
+		},
+		{
+			description: "remove-field",
+			action:      action{kind: actionRemoveField, path: []string{"user", "eids"}},
+			expected:    `{"imp":[{"id":"imp1","bidfloor":2,"bidfloorcur":"USD","ext":{"prebid":{"bidder":{"appnexus":{},"Rubicon":{}}}}},{"id":"imp2","bidfloor":0.5,"ext":{"prebid":{"bidder":{"rubicon":{}}}}}],"bcat":["IAB25"],"user":{}}`,
+		},
+		{
+			description: "remove-missing-field",
+			action:      action{kind: actionRemoveField, path: []string{"site", "content"}},
+			expected:    request,
+		},
+		{
+			description: "add-bcat",
+			action:      action{kind: actionAddBCat, values: []string{"IAB25", "IAB26"}},
+			expected:    `{"imp":[{"id":"imp1","bidfloor":2,"bidfloorcur":"USD","ext":{"prebid":{"bidder":{"appnexus":{},"Rubicon":{}}}}},{"id":"imp2","bidfloor":0.5,"ext":{"prebid":{"bidder":{"rubicon":{}}}}}],"bcat":["IAB25","IAB26"],"user":{"eids":[{"source":"a"}]}}`,
+		},
+		{
+			description: "add-badv",
+			action:      action{kind: actionAddBAdv, values: []string{"bad.com"}},
+			expected:    `{"imp":[{"id":"imp1","bidfloor":2,"bidfloorcur":"USD","ext":{"prebid":{"bidder":{"appnexus":{},"Rubicon":{}}}}},{"id":"imp2","bidfloor":0.5,"ext":{"prebid":{"bidder":{"rubicon":{}}}}}],"bcat":["IAB25"],"user":{"eids":[{"source":"a"}]},"badv":["bad.com"]}`,
+		},
+		{
+			description: "set-floor-keeps-higher-floors",
+			action:      action{kind: actionSetFloor, floor: 1, currency: "USD"},
+			expected:    `{"imp":[{"id":"imp1","bidfloor":2,"bidfloorcur":"USD","ext":{"prebid":{"bidder":{"appnexus":{},"Rubicon":{}}}}},{"id":"imp2","bidfloor":1,"ext":{"prebid":{"bidder":{"rubicon":{}}}},"bidfloorcur":"USD"}],"bcat":["IAB25"],"user":{"eids":[{"source":"a"}]}}`,
+		},
+		{
+			description: "set-floor-in-other-currency",
+			action:      action{kind: actionSetFloor, floor: 1.25, currency: "EUR"},
+			expected:    `{"imp":[{"id":"imp1","bidfloor":2,"bidfloorcur":"USD","ext":{"prebid":{"bidder":{"appnexus":{},"Rubicon":{}}}}},{"id":"imp2","bidfloor":1.25,"ext":{"prebid":{"bidder":{"rubicon":{}}}},"bidfloorcur":"EUR"}],"bcat":["IAB25"],"user":{"eids":[{"source":"a"}]}}`,
+		},
+		{
+			description: "add-targeting",
+			action:      action{kind: actionAddTargeting, target: []byte(`{"key":"hb_rule","source":"static","value":"app"}`)},
+			expected:    `{"imp":[{"id":"imp1","bidfloor":2,"bidfloorcur":"USD","ext":{"prebid":{"bidder":{"appnexus":{},"Rubicon":{}}}}},{"id":"imp2","bidfloor":0.5,"ext":{"prebid":{"bidder":{"rubicon":{}}}}}],"bcat":["IAB25"],"user":{"eids":[{"source":"a"}]},"ext":{"prebid":{"adservertargeting":[{"key":"hb_rule","source":"static","value":"app"}]}}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			updated, err := test.action.apply([]byte(request), currency.NewRates(map[string]map[string]float64{"USD": {"EUR": 0.9}}))
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expected, string(updated))
+		})
+	}
+}
+
+func TestSetFloor(t *testing.T) {
+	conversions := currency.NewRates(map[string]map[string]float64{"USD": {"EUR": 0.5}})
+
+	testCases := []struct {
+		description string
+		imp         string
+		expected    string
+	}{
+		{
+			description: "no-floor",
+			imp:         `{"id":"1"}`,
+			expected:    `{"id":"1","bidfloor":2,"bidfloorcur":"USD"}`,
+		},
+		{
+			description: "lower-floor-in-other-currency",
+			imp:         `{"id":"1","bidfloor":0.9,"bidfloorcur":"EUR"}`,
+			expected:    `{"id":"1","bidfloor":2,"bidfloorcur":"USD"}`,
+		},
+		{
+			description: "higher-floor-in-other-currency",
+			imp:         `{"id":"1","bidfloor":5,"bidfloorcur":"EUR"}`,
+			expected:    `{"id":"1","bidfloor":5,"bidfloorcur":"EUR"}`,
+		},
+		{
+			description: "same-floor-in-other-currency",
+			imp:         `{"id":"1","bidfloor":1,"bidfloorcur":"EUR"}`,
+			expected:    `{"id":"1","bidfloor":1,"bidfloorcur":"EUR"}`,
+		},
+		{
+			description: "floor-in-currency-without-rate",
+			imp:         `{"id":"1","bidfloor":0.1,"bidfloorcur":"GBP"}`,
+			expected:    `{"id":"1","bidfloor":0.1,"bidfloorcur":"GBP"}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			updated, err := setFloor([]byte(`{"imp":[`+test.imp+`]}`), 2, "USD", conversions)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"imp":[`+test.expected+`]}`, string(updated))
+		})
+	}
+}
+
+func TestSetFloorWithoutConversions(t *testing.T) {
+	updated, err := setFloor([]byte(`{"imp":[{"id":"1","bidfloor":1,"bidfloorcur":"USD"},{"id":"2","bidfloor":1,"bidfloorcur":"EUR"}]}`), 2, "USD", nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"imp":[{"id":"1","bidfloor":2,"bidfloorcur":"USD"},{"id":"2","bidfloor":1,"bidfloorcur":"EUR"}]}`, string(updated))
+}
+
+func TestAppendValue(t *testing.T) {
+	data := []byte(`{"ext":{"prebid":{"adservertargeting":[{"key":"a"}]}}}`)
+	updated, err := appendValue(data, []byte(`{"key":"b"}`), "ext", "prebid", "adservertargeting")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ext":{"prebid":{"adservertargeting":[{"key":"a"},{"key":"b"}]}}}`, string(updated))
+}
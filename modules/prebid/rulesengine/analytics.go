@@ -0,0 +1,37 @@
+package rulesengine
+
+import (
+	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+)
+
+const applyRulesTag = "apply_rules"
+
+const (
+	ruleAnalyticKey    = "rule"
+	actionsAnalyticKey = "actions"
+)
+
+// rulesengine module has only 1 activity: `apply_rules`, results are added for every rule applied
+func newApplyRulesTags() hookanalytics.Analytics {
+	return hookanalytics.Analytics{
+		Activities: []hookanalytics.Activity{
+			{
+				Name:   applyRulesTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+			},
+		},
+	}
+}
+
+func addRuleAnalyticTag(analytics *hookanalytics.Analytics, status hookanalytics.ResultStatus, r rule, appliedTo hookanalytics.AppliedTo) {
+	newResult := hookanalytics.Result{
+		Status: status,
+		Values: map[string]interface{}{
+			ruleAnalyticKey:    r.id,
+			actionsAnalyticKey: r.actionTypes(),
+		},
+		AppliedTo: appliedTo,
+	}
+
+	analytics.Activities[0].Results = append(analytics.Activities[0].Results, newResult)
+}
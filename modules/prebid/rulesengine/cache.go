@@ -0,0 +1,78 @@
+package rulesengine
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+)
+
+const (
+	// compiledRulesTTL is how long the rules compiled from a config are reused, so the changes to the
+	// stored rule sets it references apply within this delay.
+	compiledRulesTTL = time.Minute
+	// maxCompiledRules bounds the number of configs whose rules are kept compiled.
+	maxCompiledRules = 1000
+)
+
+// compiledRules are the rules compiled from the host and account config and the stored rule sets it references.
+type compiledRules struct {
+	rules   *ruleSet
+	expires time.Time
+}
+
+// rulesCache keeps the rules compiled from a config, keyed by the config hash, so they aren't parsed and the rule
+// sets fetched again on every request. The host and account config identifies the account rules, as accounts
+// with the same config share the same rules.
+type rulesCache struct {
+	sync.Mutex
+	entries map[[sha256.Size]byte]compiledRules
+	time    timeutil.Time
+}
+
+func newRulesCache() *rulesCache {
+	return &rulesCache{
+		entries: make(map[[sha256.Size]byte]compiledRules),
+		time:    &timeutil.RealTime{},
+	}
+}
+
+// get returns the unexpired rules compiled from the config. A nil cache never holds any rules.
+func (c *rulesCache) get(cfg json.RawMessage) (*ruleSet, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	entry, ok := c.entries[sha256.Sum256(cfg)]
+	if !ok || !c.time.Now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.rules, true
+}
+
+// set keeps the rules compiled from the config. The expired rules are dropped once the cache is full,
+// and all of them if none expired.
+func (c *rulesCache) set(cfg json.RawMessage, rules *ruleSet) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	now := c.time.Now()
+	if len(c.entries) >= maxCompiledRules {
+		for key, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries) >= maxCompiledRules {
+			clear(c.entries)
+		}
+	}
+	c.entries[sha256.Sum256(cfg)] = compiledRules{rules: rules, expires: now.Add(compiledRulesTTL)}
+}
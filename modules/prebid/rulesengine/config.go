@@ -0,0 +1,44 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// config represents the module configuration: the host-level config merged with the account-level config.
+type config struct {
+	Enabled *bool            `json:"enabled"`
+	Rules   []ruleDefinition `json:"rules"`
+	// RuleSets are IDs of stored requests holding a storedRuleSet.
+	RuleSets []string `json:"rule_sets"`
+}
+
+// storedRuleSet is the format of a rule set kept in the stored requests backend.
+type storedRuleSet struct {
+	Rules []ruleDefinition `json:"rules"`
+}
+
+func newConfig(data json.RawMessage) (config, error) {
+	var cfg config
+	if len(data) == 0 {
+		return cfg, nil
+	}
+	if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+	return cfg, nil
+}
+
+func newStoredRuleSet(id string, data json.RawMessage) (*ruleSet, error) {
+	var stored storedRuleSet
+	if err := jsonutil.UnmarshalValid(data, &stored); err != nil {
+		return nil, fmt.Errorf(`failed to parse rule set "%s": %s`, id, err)
+	}
+	rules, err := newRuleSet(stored.Rules)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid rule set "%s": %s`, id, err)
+	}
+	return rules, nil
+}
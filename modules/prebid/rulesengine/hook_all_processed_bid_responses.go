@@ -0,0 +1,138 @@
+package rulesengine
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/prebid/prebid-server/v3/exchange/entities"
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+func handleAllProcessedBidResponsesHook(
+	state *requestState,
+	payload hookstage.AllProcessedBidResponsesPayload,
+) (result hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload], err error) {
+	var rules []int
+	for i, r := range state.rules.rules {
+		// rules with conditions on request fields only apply if the request met them at the processed_auction_request stage
+		if r.stage == hooks.StageAllProcessedBidResponses && (len(r.conditions) == 0 || (state.matched != nil && state.matched[i])) {
+			rules = append(rules, i)
+		}
+	}
+	if len(rules) == 0 || len(payload.Responses) == 0 {
+		return result, nil
+	}
+
+	bidders := make([]openrtb_ext.BidderName, 0, len(payload.Responses))
+	for bidder := range payload.Responses {
+		bidders = append(bidders, bidder)
+	}
+	slices.Sort(bidders)
+
+	rejected := make(map[*entities.PbsOrtbBid]struct{})
+	for _, bidder := range bidders {
+		seatBid := payload.Responses[bidder]
+		if seatBid == nil {
+			continue
+		}
+
+		rejectedByRule := make(map[int][]*entities.PbsOrtbBid)
+		var nonBids []openrtb_ext.NonBid
+		for _, bid := range seatBid.Bids {
+			if bid == nil || bid.Bid == nil {
+				continue
+			}
+
+			var bidJSON []byte
+			marshalBid := func() []byte {
+				if bidJSON == nil {
+					bidJSON, _ = jsonutil.Marshal(bid.Bid)
+				}
+				return bidJSON
+			}
+			for _, i := range rules {
+				r := state.rules.rules[i]
+				if r.rejectsBids(bidder.String()) && r.matchesBid(bidder.String(), string(bid.BidType), marshalBid) {
+					rejected[bid] = struct{}{}
+					rejectedByRule[i] = append(rejectedByRule[i], bid)
+					nonBids = append(nonBids, newNonBid(bid))
+					result.DebugMessages = append(result.DebugMessages, fmt.Sprintf(`Bid %s from bidder %s rejected by rule "%s"`, bid.Bid.ID, bidder, r.id))
+					break
+				}
+			}
+		}
+		if len(nonBids) == 0 {
+			continue
+		}
+
+		seat := seatBid.Seat
+		if seat == "" {
+			seat = bidder.String()
+		}
+		result.SeatNonBid = append(result.SeatNonBid, openrtb_ext.SeatNonBid{Seat: seat, NonBid: nonBids})
+
+		if len(result.AnalyticsTags.Activities) == 0 {
+			result.AnalyticsTags = newApplyRulesTags()
+		}
+		for _, i := range rules {
+			if bids, ok := rejectedByRule[i]; ok {
+				addRuleAnalyticTag(&result.AnalyticsTags, hookanalytics.ResultStatusBlock, state.rules.rules[i], appliedToBids(bidder.String(), bids))
+			}
+		}
+	}
+
+	if len(rejected) == 0 {
+		return result, nil
+	}
+
+	changeSet := hookstage.ChangeSet[hookstage.AllProcessedBidResponsesPayload]{}
+	changeSet.AddMutation(func(payload hookstage.AllProcessedBidResponsesPayload) (hookstage.AllProcessedBidResponsesPayload, error) {
+		for _, seatBid := range payload.Responses {
+			if seatBid != nil {
+				seatBid.Bids = slices.DeleteFunc(seatBid.Bids, func(bid *entities.PbsOrtbBid) bool {
+					_, ok := rejected[bid]
+					return ok
+				})
+			}
+		}
+		return payload, nil
+	}, hookstage.MutationDelete, "processedBidResponses", "bids")
+	result.ChangeSet = changeSet
+
+	return result, nil
+}
+
+func appliedToBids(bidder string, bids []*entities.PbsOrtbBid) hookanalytics.AppliedTo {
+	appliedTo := hookanalytics.AppliedTo{Bidder: bidder}
+	for _, bid := range bids {
+		appliedTo.BidIds = append(appliedTo.BidIds, bid.Bid.ID)
+		appliedTo.ImpIds = append(appliedTo.ImpIds, bid.Bid.ImpID)
+	}
+	return appliedTo
+}
+
+func newNonBid(bid *entities.PbsOrtbBid) openrtb_ext.NonBid {
+	return openrtb_ext.NonBid{
+		ImpId:      bid.Bid.ImpID,
+		StatusCode: int(openrtb_ext.ResponseRejectedGeneral),
+		Ext: &openrtb_ext.NonBidExt{
+			Prebid: openrtb_ext.ExtResponseNonBidPrebid{Bid: openrtb_ext.NonBidObject{
+				Price:          bid.Bid.Price,
+				ADomain:        bid.Bid.ADomain,
+				CatTax:         bid.Bid.CatTax,
+				Cat:            bid.Bid.Cat,
+				DealID:         bid.Bid.DealID,
+				W:              bid.Bid.W,
+				H:              bid.Bid.H,
+				Dur:            bid.Bid.Dur,
+				MType:          bid.Bid.MType,
+				OriginalBidCPM: bid.OriginalBidCPM,
+				OriginalBidCur: bid.OriginalBidCur,
+			}},
+		},
+	}
+}
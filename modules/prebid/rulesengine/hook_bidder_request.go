@@ -0,0 +1,64 @@
+package rulesengine
+
+import (
+	"fmt"
+
+	"github.com/prebid/openrtb/v20/openrtb3"
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v3/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+func handleBidderRequestHook(
+	state *requestState,
+	payload hookstage.BidderRequestPayload,
+) (result hookstage.HookResult[hookstage.BidderRequestPayload], err error) {
+	if payload.Request == nil || payload.Request.BidRequest == nil {
+		return result, hookexecution.NewFailure("payload contains a nil bid request")
+	}
+
+	var request []byte
+	var applied []rule
+	for _, r := range state.rules.rules {
+		if r.stage != hooks.StageBidderRequest {
+			continue
+		}
+		if request == nil {
+			if request, err = jsonutil.Marshal(payload.Request.BidRequest); err != nil {
+				return result, hookexecution.NewFailure("failed to marshal the bid request: %s", err)
+			}
+		}
+		if !r.matchesRequest(request, payload.Bidder) {
+			continue
+		}
+
+		if r.excludes(payload.Bidder) {
+			result.AnalyticsTags = newApplyRulesTags()
+			addRuleAnalyticTag(&result.AnalyticsTags, hookanalytics.ResultStatusBlock, r, hookanalytics.AppliedTo{Bidder: payload.Bidder})
+			result.Reject = true
+			result.NbrCode = int(openrtb3.NoBidUnknownError)
+			result.Message = fmt.Sprintf(`bidder %s excluded by rule "%s"`, payload.Bidder, r.id)
+			return result, nil
+		}
+		applied = append(applied, r)
+	}
+
+	if len(applied) == 0 {
+		return result, nil
+	}
+
+	result.AnalyticsTags = newApplyRulesTags()
+	for _, r := range applied {
+		addRuleAnalyticTag(&result.AnalyticsTags, hookanalytics.ResultStatusModify, r, hookanalytics.AppliedTo{Bidder: payload.Bidder})
+	}
+
+	changeSet := hookstage.ChangeSet[hookstage.BidderRequestPayload]{}
+	changeSet.AddMutation(func(payload hookstage.BidderRequestPayload) (hookstage.BidderRequestPayload, error) {
+		return payload, updateRequest(payload.Request, applied, state.rates)
+	}, hookstage.MutationUpdate, "bidrequest")
+	result.ChangeSet = changeSet
+
+	return result, nil
+}
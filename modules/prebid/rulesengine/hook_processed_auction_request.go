@@ -0,0 +1,56 @@
+package rulesengine
+
+import (
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v3/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+func handleProcessedAuctionHook(
+	state *requestState,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (result hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], err error) {
+	if payload.Request == nil || payload.Request.BidRequest == nil {
+		return result, hookexecution.NewFailure("payload contains a nil bid request")
+	}
+
+	request, err := jsonutil.Marshal(payload.Request.BidRequest)
+	if err != nil {
+		return result, hookexecution.NewFailure("failed to marshal the bid request: %s", err)
+	}
+
+	// the conditions on request fields of the rules applied to bids are evaluated here,
+	// as the request isn't available at the all_processed_bid_responses stage
+	state.matched = make([]bool, len(state.rules.rules))
+	var applied []rule
+	for i, r := range state.rules.rules {
+		switch r.stage {
+		case hooks.StageProcessedAuctionRequest:
+			if r.matchesRequest(request, "") {
+				applied = append(applied, r)
+			}
+		case hooks.StageAllProcessedBidResponses:
+			state.matched[i] = r.matchesRequest(request, "")
+		}
+	}
+	result.ModuleContext = hookstage.ModuleContext{stateContextKey: state}
+
+	if len(applied) == 0 {
+		return result, nil
+	}
+
+	result.AnalyticsTags = newApplyRulesTags()
+	for _, r := range applied {
+		addRuleAnalyticTag(&result.AnalyticsTags, hookanalytics.ResultStatusModify, r, hookanalytics.AppliedTo{Request: true})
+	}
+
+	changeSet := hookstage.ChangeSet[hookstage.ProcessedAuctionRequestPayload]{}
+	changeSet.AddMutation(func(payload hookstage.ProcessedAuctionRequestPayload) (hookstage.ProcessedAuctionRequestPayload, error) {
+		return payload, updateRequest(payload.Request, applied, state.rates)
+	}, hookstage.MutationUpdate, "bidrequest")
+	result.ChangeSet = changeSet
+
+	return result, nil
+}
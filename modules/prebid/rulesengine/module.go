@@ -0,0 +1,168 @@
+// Package rulesengine applies declarative rules to auctions: rules match conditions on request or bid fields
+// and apply actions such as excluding bidders, setting or removing request fields, adding blocked categories
+// and advertisers, setting floors, adding targeting or rejecting bids.
+package rulesengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v3/currency"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/modules/moduledeps"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+)
+
+// stateContextKey is the module context key of the requestState.
+const stateContextKey = "state"
+
+// Builder creates the rules engine module. The host-level rules are validated at startup.
+func Builder(rawConfig json.RawMessage, deps moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, err := newConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := newRuleSet(cfg.Rules); err != nil {
+		return nil, err
+	}
+
+	return Module{storedData: deps.StoredRequestFetcher, rates: deps.RateConvertor, cache: newRulesCache()}, nil
+}
+
+// Module applies the rules of the host and account config and of the stored rule sets it references.
+// The request can't change the rules, as any caller can send it.
+type Module struct {
+	storedData stored_requests.Fetcher
+	// rates convert the floors of the rules to the currencies of the imps.
+	rates *currency.RateConverter
+	cache *rulesCache
+}
+
+// requestState is passed from the processed_auction_request stage to the following ones,
+// so the rules are loaded once per request.
+type requestState struct {
+	rules *ruleSet
+	rates *currency.RateConverter
+	// matched tells, for every rule of the all_processed_bid_responses stage,
+	// whether the request met its conditions at the processed_auction_request stage.
+	matched []bool
+}
+
+// HandleProcessedAuctionHook applies the rules of the processed_auction_request stage to the request.
+func (m Module) HandleProcessedAuctionHook(
+	ctx context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	result := hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{}
+
+	state, warnings, err := m.loadState(ctx, miCtx)
+	if err != nil || state == nil {
+		return result, err
+	}
+
+	result, err = handleProcessedAuctionHook(state, payload)
+	result.Warnings = append(warnings, result.Warnings...)
+	return result, err
+}
+
+// HandleBidderRequestHook applies the rules of the bidder_request stage to the request of the bidder.
+// The request is rejected if a matching rule excludes the bidder.
+func (m Module) HandleBidderRequestHook(
+	ctx context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.BidderRequestPayload,
+) (hookstage.HookResult[hookstage.BidderRequestPayload], error) {
+	result := hookstage.HookResult[hookstage.BidderRequestPayload]{}
+
+	state, warnings, err := m.loadState(ctx, miCtx)
+	if err != nil || state == nil {
+		return result, err
+	}
+
+	result, err = handleBidderRequestHook(state, payload)
+	result.Warnings = append(warnings, result.Warnings...)
+	return result, err
+}
+
+// HandleAllProcessedBidResponsesHook rejects the bids matching the rules of the all_processed_bid_responses stage.
+func (m Module) HandleAllProcessedBidResponsesHook(
+	ctx context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.AllProcessedBidResponsesPayload,
+) (hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload], error) {
+	result := hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload]{}
+
+	state, warnings, err := m.loadState(ctx, miCtx)
+	if err != nil || state == nil {
+		return result, err
+	}
+
+	result, err = handleAllProcessedBidResponsesHook(state, payload)
+	result.Warnings = append(warnings, result.Warnings...)
+	return result, err
+}
+
+// loadState returns the state saved at the processed_auction_request stage or, if the module didn't run
+// at that stage, loads the rules from the host and account config. It returns nil if the module is disabled.
+func (m Module) loadState(ctx context.Context, miCtx hookstage.ModuleInvocationContext) (*requestState, []string, error) {
+	if state, ok := miCtx.ModuleContext[stateContextKey].(*requestState); ok {
+		return state, nil, nil
+	}
+
+	cfg, err := newConfig(miCtx.HostAccountConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Enabled != nil && !*cfg.Enabled {
+		return nil, nil, nil
+	}
+
+	if rules, ok := m.cache.get(miCtx.HostAccountConfig); ok {
+		return &requestState{rules: rules, rates: m.rates}, nil, nil
+	}
+	rules, warnings, err := m.compileRules(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the rules are compiled again on the next request if a rule set couldn't be loaded
+	if len(warnings) == 0 {
+		m.cache.set(miCtx.HostAccountConfig, rules)
+	}
+	return &requestState{rules: rules, rates: m.rates}, warnings, nil
+}
+
+// compileRules compiles the rules of the config followed by the rules of the stored rule sets it references.
+func (m Module) compileRules(ctx context.Context, cfg config) (*ruleSet, []string, error) {
+	rules, err := newRuleSet(cfg.Rules)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cfg.RuleSets) == 0 {
+		return rules, nil, nil
+	}
+
+	// a rule set which can't be loaded is skipped, so the other rules still apply
+	if m.storedData == nil {
+		return rules, []string{"stored rule sets are not available"}, nil
+	}
+	var warnings []string
+	storedRuleSets, _, errs := m.storedData.FetchRequests(ctx, cfg.RuleSets, nil)
+	for _, err := range errs {
+		warnings = append(warnings, fmt.Sprintf("failed to fetch rule sets: %s", err))
+	}
+	for _, id := range cfg.RuleSets {
+		data, ok := storedRuleSets[id]
+		if !ok {
+			continue
+		}
+		stored, err := newStoredRuleSet(id, data)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			continue
+		}
+		rules.rules = append(rules.rules, stored.rules...)
+	}
+	return rules, warnings, nil
+}
@@ -0,0 +1,395 @@
+package rulesengine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/exchange/entities"
+	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/modules/moduledeps"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = json.RawMessage(`
+{
+  "enabled": true,
+  "rules": [
+    {
+      "id": "drop-appnexus-on-app",
+      "conditions": [{"field": "app.bundle", "operator": "in", "values": ["com.example.game"]}],
+      "actions": [
+        {"type": "exclude_bidders", "bidders": ["appnexus"]},
+        {"type": "add_bcat", "values": ["IAB25"]},
+        {"type": "add_targeting", "key": "hb_rule", "value": "game"}
+      ]
+    },
+    {
+      "id": "rubicon-floor",
+      "stage": "bidder_request",
+      "conditions": [{"field": "bidder", "operator": "in", "values": ["rubicon"]}],
+      "actions": [{"type": "set_floor", "floor": 1.5}]
+    },
+    {
+      "id": "no-openx-on-app",
+      "stage": "bidder_request",
+      "conditions": [{"field": "app", "operator": "exists"}],
+      "actions": [{"type": "exclude_bidders", "bidders": ["openx"]}]
+    },
+    {
+      "id": "block-gambling-advertisers",
+      "stage": "all_processed_bid_responses",
+      "conditions": [
+        {"field": "app.bundle", "operator": "prefix", "values": ["com.example."]},
+        {"field": "bid.adomain", "operator": "in", "values": ["casino.com"]}
+      ],
+      "actions": [{"type": "reject_bids"}]
+    }
+  ]
+}`)
+
+func TestBuilder(t *testing.T) {
+	_, err := Builder(json.RawMessage(`{"rules": [{"id": "r1"}]}`), moduledeps.ModuleDeps{})
+	assert.EqualError(t, err, `invalid rule 0: rule "r1" has no actions`)
+
+	_, err = Builder(json.RawMessage(`{"rules": "all"}`), moduledeps.ModuleDeps{})
+	assert.Error(t, err)
+
+	module, err := Builder(testConfig, moduledeps.ModuleDeps{})
+	assert.NoError(t, err)
+	assert.IsType(t, Module{}, module)
+}
+
+func TestHandleProcessedAuctionHook(t *testing.T) {
+	testCases := []struct {
+		description       string
+		config            json.RawMessage
+		request           *openrtb2.BidRequest
+		expectedRequest   *openrtb2.BidRequest
+		expectedAnalytics hookanalytics.Analytics
+	}{
+		{
+			description: "rule-applied",
+			config:      testConfig,
+			request: &openrtb2.BidRequest{
+				ID:  "req1",
+				App: &openrtb2.App{Bundle: "com.example.game"},
+				Imp: []openrtb2.Imp{{ID: "imp1", Ext: json.RawMessage(`{"prebid":{"bidder":{"appnexus":{"placementId":1},"rubicon":{"zoneId":2}}}}`)}},
+			},
+			expectedRequest: &openrtb2.BidRequest{
+				ID:   "req1",
+				App:  &openrtb2.App{Bundle: "com.example.game"},
+				Imp:  []openrtb2.Imp{{ID: "imp1", Ext: json.RawMessage(`{"prebid":{"bidder":{"rubicon":{"zoneId":2}}}}`)}},
+				BCat: []string{"IAB25"},
+				Ext:  json.RawMessage(`{"prebid":{"adservertargeting":[{"key":"hb_rule","source":"static","value":"game"}]}}`),
+			},
+			expectedAnalytics: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+				Name:   applyRulesTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+				Results: []hookanalytics.Result{{
+					Status:    hookanalytics.ResultStatusModify,
+					Values:    map[string]interface{}{"rule": "drop-appnexus-on-app", "actions": []string{"exclude_bidders", "add_bcat", "add_targeting"}},
+					AppliedTo: hookanalytics.AppliedTo{Request: true},
+				}},
+			}}},
+		},
+		{
+			description: "conditions-not-met",
+			config:      testConfig,
+			request:     &openrtb2.BidRequest{ID: "req1", Site: &openrtb2.Site{Domain: "example.com"}},
+			expectedRequest: &openrtb2.BidRequest{
+				ID:   "req1",
+				Site: &openrtb2.Site{Domain: "example.com"},
+			},
+		},
+		{
+			description: "module-disabled",
+			config:      json.RawMessage(`{"enabled": false, "rules": [{"id": "r1", "actions": [{"type": "add_bcat", "values": ["IAB25"]}]}]}`),
+			request:     &openrtb2.BidRequest{ID: "req1"},
+			expectedRequest: &openrtb2.BidRequest{
+				ID: "req1",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			payload := hookstage.ProcessedAuctionRequestPayload{Request: &openrtb_ext.RequestWrapper{BidRequest: test.request}}
+			result, err := Module{}.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{HostAccountConfig: test.config}, payload)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedAnalytics, result.AnalyticsTags)
+
+			for _, mut := range result.ChangeSet.Mutations() {
+				payload, err = mut.Apply(payload)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedRequest, payload.Request.BidRequest)
+		})
+	}
+}
+
+func TestHandleBidderRequestHook(t *testing.T) {
+	request := &openrtb2.BidRequest{
+		ID:  "req1",
+		App: &openrtb2.App{Bundle: "com.example.game"},
+		Imp: []openrtb2.Imp{{ID: "imp1", BidFloor: 1, BidFloorCur: "USD"}},
+	}
+
+	testCases := []struct {
+		description     string
+		bidder          string
+		expectedReject  bool
+		expectedMessage string
+		expectedRequest *openrtb2.BidRequest
+	}{
+		{
+			description: "floor-raised",
+			bidder:      "rubicon",
+			expectedRequest: &openrtb2.BidRequest{
+				ID:  "req1",
+				App: &openrtb2.App{Bundle: "com.example.game"},
+				Imp: []openrtb2.Imp{{ID: "imp1", BidFloor: 1.5, BidFloorCur: "USD"}},
+			},
+		},
+		{
+			description:     "bidder-excluded",
+			bidder:          "openx",
+			expectedReject:  true,
+			expectedMessage: `bidder openx excluded by rule "no-openx-on-app"`,
+			expectedRequest: request,
+		},
+		{
+			description:     "no-rules-for-bidder",
+			bidder:          "appnexus",
+			expectedRequest: request,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			requestCopy := *request
+			requestCopy.Imp = append([]openrtb2.Imp(nil), request.Imp...)
+			payload := hookstage.BidderRequestPayload{Request: &openrtb_ext.RequestWrapper{BidRequest: &requestCopy}, Bidder: test.bidder}
+
+			result, err := Module{}.HandleBidderRequestHook(context.Background(), hookstage.ModuleInvocationContext{HostAccountConfig: testConfig}, payload)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedReject, result.Reject)
+			assert.Equal(t, test.expectedMessage, result.Message)
+
+			for _, mut := range result.ChangeSet.Mutations() {
+				payload, err = mut.Apply(payload)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedRequest, payload.Request.BidRequest)
+		})
+	}
+}
+
+func TestHandleAllProcessedBidResponsesHook(t *testing.T) {
+	casinoBid := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "bid1", ImpID: "imp1", Price: 2, ADomain: []string{"casino.com"}}, BidType: openrtb_ext.BidTypeBanner}
+	cleanBid := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "bid2", ImpID: "imp1", Price: 1, ADomain: []string{"shoes.com"}}, BidType: openrtb_ext.BidTypeBanner}
+
+	module := Module{}
+	processedPayload := hookstage.ProcessedAuctionRequestPayload{Request: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
+		ID:  "req1",
+		App: &openrtb2.App{Bundle: "com.example.news"},
+	}}}
+	processedResult, err := module.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{HostAccountConfig: testConfig}, processedPayload)
+	require.NoError(t, err)
+
+	payload := hookstage.AllProcessedBidResponsesPayload{Responses: map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+		"appnexus": {Bids: []*entities.PbsOrtbBid{casinoBid, cleanBid}, Currency: "USD"},
+		"rubicon":  {Bids: []*entities.PbsOrtbBid{cleanBid}, Currency: "USD"},
+	}}
+	miCtx := hookstage.ModuleInvocationContext{HostAccountConfig: testConfig, ModuleContext: processedResult.ModuleContext}
+	result, err := module.HandleAllProcessedBidResponsesHook(context.Background(), miCtx, payload)
+	require.NoError(t, err)
+
+	assert.Equal(t, []openrtb_ext.SeatNonBid{{Seat: "appnexus", NonBid: []openrtb_ext.NonBid{newNonBid(casinoBid)}}}, result.SeatNonBid)
+	assert.Equal(t, hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+		Name:   applyRulesTag,
+		Status: hookanalytics.ActivityStatusSuccess,
+		Results: []hookanalytics.Result{{
+			Status:    hookanalytics.ResultStatusBlock,
+			Values:    map[string]interface{}{"rule": "block-gambling-advertisers", "actions": []string{"reject_bids"}},
+			AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"bid1"}, ImpIds: []string{"imp1"}},
+		}},
+	}}}, result.AnalyticsTags)
+
+	for _, mut := range result.ChangeSet.Mutations() {
+		payload, err = mut.Apply(payload)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []*entities.PbsOrtbBid{cleanBid}, payload.Responses["appnexus"].Bids)
+	assert.Equal(t, []*entities.PbsOrtbBid{cleanBid}, payload.Responses["rubicon"].Bids)
+}
+
+func TestHandleAllProcessedBidResponsesHookWithoutRequestConditions(t *testing.T) {
+	bid := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "bid1", ImpID: "imp1", ADomain: []string{"casino.com"}}}
+	payload := hookstage.AllProcessedBidResponsesPayload{Responses: map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+		"appnexus": {Bids: []*entities.PbsOrtbBid{bid}},
+	}}
+
+	// the request conditions can't be evaluated if the module didn't run at the processed_auction_request stage
+	result, err := Module{}.HandleAllProcessedBidResponsesHook(context.Background(), hookstage.ModuleInvocationContext{HostAccountConfig: testConfig}, payload)
+	require.NoError(t, err)
+	assert.Empty(t, result.ChangeSet.Mutations())
+
+	config := json.RawMessage(`{"rules": [{"id": "r1", "stage": "all_processed_bid_responses", "actions": [{"type": "exclude_bidders", "bidders": ["AppNexus"]}]}]}`)
+	result, err = Module{}.HandleAllProcessedBidResponsesHook(context.Background(), hookstage.ModuleInvocationContext{HostAccountConfig: config}, payload)
+	require.NoError(t, err)
+	assert.Len(t, result.ChangeSet.Mutations(), 1)
+	assert.Len(t, result.SeatNonBid, 1)
+}
+
+func TestLoadStateWithStoredRuleSets(t *testing.T) {
+	fetcher := &mockStoredRuleSets{data: map[string]json.RawMessage{
+		"valid":   json.RawMessage(`{"rules": [{"id": "stored", "actions": [{"type": "add_badv", "values": ["bad.com"]}]}]}`),
+		"invalid": json.RawMessage(`{"rules": [{"id": "broken"}]}`),
+	}}
+	config := json.RawMessage(`{
+		"rules": [{"id": "inline", "actions": [{"type": "add_bcat", "values": ["IAB25"]}]}],
+		"rule_sets": ["valid", "invalid", "missing"]
+	}`)
+
+	state, warnings, err := Module{storedData: fetcher}.loadState(context.Background(), hookstage.ModuleInvocationContext{HostAccountConfig: config})
+	require.NoError(t, err)
+	require.Len(t, state.rules.rules, 2)
+	assert.Equal(t, "inline", state.rules.rules[0].id)
+	assert.Equal(t, "stored", state.rules.rules[1].id)
+	assert.Equal(t, []string{
+		"failed to fetch rule sets: rule set missing not found",
+		`invalid rule set "invalid": invalid rule 0: rule "broken" has no actions`,
+	}, warnings)
+	assert.Equal(t, []string{"valid", "invalid", "missing"}, fetcher.requested)
+
+	_, warnings, err = Module{}.loadState(context.Background(), hookstage.ModuleInvocationContext{HostAccountConfig: config})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stored rule sets are not available"}, warnings)
+
+	// the state saved at the processed_auction_request stage is reused
+	saved := &requestState{rules: &ruleSet{}}
+	state, _, err = Module{storedData: fetcher}.loadState(context.Background(), hookstage.ModuleInvocationContext{
+		HostAccountConfig: config,
+		ModuleContext:     hookstage.ModuleContext{stateContextKey: saved},
+	})
+	require.NoError(t, err)
+	assert.Same(t, saved, state)
+}
+
+type mockStoredRuleSets struct {
+	data      map[string]json.RawMessage
+	requested []string
+}
+
+func (f *mockStoredRuleSets) FetchRequests(_ context.Context, requestIDs []string, _ []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	f.requested = requestIDs
+	found := make(map[string]json.RawMessage)
+	var errs []error
+	for _, id := range requestIDs {
+		if data, ok := f.data[id]; ok {
+			found[id] = data
+		} else {
+			errs = append(errs, errors.New("rule set "+id+" not found"))
+		}
+	}
+	return found, nil, errs
+}
+
+func (f *mockStoredRuleSets) FetchResponses(_ context.Context, _ []string) (map[string]json.RawMessage, []error) {
+	return nil, nil
+}
+
+func TestLoadStateIgnoresRequestConfig(t *testing.T) {
+	hostAccountConfig := json.RawMessage(`{"rules": [{"id": "host", "actions": [{"type": "add_bcat", "values": ["IAB25"]}]}]}`)
+	requestConfig := json.RawMessage(`{"enabled": false, "rules": []}`)
+
+	state, _, err := Module{}.loadState(context.Background(), hookstage.ModuleInvocationContext{Config: requestConfig, HostAccountConfig: hostAccountConfig})
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	require.Len(t, state.rules.rules, 1)
+	assert.Equal(t, "host", state.rules.rules[0].id)
+}
+
+func TestLoadStateCachesCompiledRules(t *testing.T) {
+	fetcher := &mockStoredRuleSets{data: map[string]json.RawMessage{
+		"valid": json.RawMessage(`{"rules": [{"id": "stored", "actions": [{"type": "add_badv", "values": ["bad.com"]}]}]}`),
+	}}
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := &fakeTime{now: now}
+	cache := newRulesCache()
+	cache.time = clock
+	module := Module{storedData: fetcher, cache: cache}
+
+	testCases := []struct {
+		description     string
+		givenConfig     json.RawMessage
+		givenNow        time.Time
+		expectedFetched bool
+	}{
+		{
+			description:     "compiled",
+			givenConfig:     json.RawMessage(`{"rule_sets": ["valid"]}`),
+			givenNow:        now,
+			expectedFetched: true,
+		},
+		{
+			description:     "reused",
+			givenConfig:     json.RawMessage(`{"rule_sets": ["valid"]}`),
+			givenNow:        now.Add(compiledRulesTTL - time.Second),
+			expectedFetched: false,
+		},
+		{
+			description:     "other-config-compiled",
+			givenConfig:     json.RawMessage(`{"rule_sets": ["valid"], "enabled": true}`),
+			givenNow:        now.Add(compiledRulesTTL - time.Second),
+			expectedFetched: true,
+		},
+		{
+			description:     "expired-compiled-again",
+			givenConfig:     json.RawMessage(`{"rule_sets": ["valid"]}`),
+			givenNow:        now.Add(compiledRulesTTL),
+			expectedFetched: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			clock.now = test.givenNow
+			fetcher.requested = nil
+
+			state, warnings, err := module.loadState(context.Background(), hookstage.ModuleInvocationContext{HostAccountConfig: test.givenConfig})
+			require.NoError(t, err)
+			assert.Empty(t, warnings)
+			require.Len(t, state.rules.rules, 1)
+			assert.Equal(t, "stored", state.rules.rules[0].id)
+			assert.Equal(t, test.expectedFetched, fetcher.requested != nil)
+		})
+	}
+}
+
+func TestRulesCacheBounded(t *testing.T) {
+	cache := newRulesCache()
+	for i := 0; i <= maxCompiledRules; i++ {
+		cache.set(json.RawMessage(fmt.Sprintf(`{"rule_sets": ["%d"]}`, i)), &ruleSet{})
+	}
+
+	assert.LessOrEqual(t, len(cache.entries), maxCompiledRules)
+	_, ok := cache.get(json.RawMessage(fmt.Sprintf(`{"rule_sets": ["%d"]}`, maxCompiledRules)))
+	assert.True(t, ok, "the last compiled rules are kept")
+}
+
+type fakeTime struct {
+	now time.Time
+}
+
+func (f *fakeTime) Now() time.Time {
+	return f.now
+}
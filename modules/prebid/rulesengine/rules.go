@@ -0,0 +1,483 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// Operators of the rule conditions.
+const (
+	operatorIn        = "in"
+	operatorNotIn     = "not_in"
+	operatorExists    = "exists"
+	operatorNotExists = "not_exists"
+	operatorPrefix    = "prefix"
+	operatorSuffix    = "suffix"
+	operatorContains  = "contains"
+	operatorGT        = "gt"
+	operatorGTE       = "gte"
+	operatorLT        = "lt"
+	operatorLTE       = "lte"
+)
+
+// Types of the rule actions.
+const (
+	actionExcludeBidders = "exclude_bidders"
+	actionSetField       = "set_field"
+	actionRemoveField    = "remove_field"
+	actionAddBCat        = "add_bcat"
+	actionAddBAdv        = "add_badv"
+	actionSetFloor       = "set_floor"
+	actionAddTargeting   = "add_targeting"
+	actionRejectBids     = "reject_bids"
+)
+
+// Fields which aren't paths in the bid request.
+const (
+	fieldBidder    = "bidder"
+	fieldMediaType = "mediatype"
+	bidFieldPrefix = "bid."
+)
+
+const defaultFloorCurrency = "USD"
+
+// stageActions lists the actions supported at each stage.
+var stageActions = map[hooks.Stage][]string{
+	hooks.StageProcessedAuctionRequest: {
+		actionExcludeBidders, actionSetField, actionRemoveField, actionAddBCat, actionAddBAdv, actionSetFloor, actionAddTargeting,
+	},
+	hooks.StageBidderRequest: {
+		actionExcludeBidders, actionSetField, actionRemoveField, actionAddBCat, actionAddBAdv, actionSetFloor,
+	},
+	hooks.StageAllProcessedBidResponses: {
+		actionExcludeBidders, actionRejectBids,
+	},
+}
+
+// ruleDefinition is the declarative format of a rule. A rule applies its actions
+// when all of its conditions are met.
+type ruleDefinition struct {
+	ID         string                `json:"id"`
+	Stage      string                `json:"stage"`
+	Conditions []conditionDefinition `json:"conditions"`
+	Actions    []actionDefinition    `json:"actions"`
+}
+
+type conditionDefinition struct {
+	Field    string            `json:"field"`
+	Operator string            `json:"operator"`
+	Values   []json.RawMessage `json:"values"`
+}
+
+type actionDefinition struct {
+	Type     string          `json:"type"`
+	Bidders  []string        `json:"bidders"`
+	Field    string          `json:"field"`
+	Value    json.RawMessage `json:"value"`
+	Values   []string        `json:"values"`
+	Floor    float64         `json:"floor"`
+	Currency string          `json:"currency"`
+	Key      string          `json:"key"`
+}
+
+// ruleSet holds the compiled rules in effect for a request.
+type ruleSet struct {
+	rules []rule
+}
+
+type rule struct {
+	id    string
+	stage hooks.Stage
+	// conditions are evaluated against the bid request seen at the stage of the rule,
+	// or at the processed_auction_request stage for the rules of the all_processed_bid_responses stage.
+	conditions []condition
+	// bidConditions are evaluated against every bid at the all_processed_bid_responses stage.
+	bidConditions []condition
+	actions       []action
+}
+
+type condition struct {
+	// path is the field path in the bid request or the bid, unless field is a pseudo field.
+	path     []string
+	field    string
+	operator string
+	values   []string
+	number   float64
+}
+
+type action struct {
+	kind     string
+	bidders  map[string]struct{}
+	path     []string
+	value    []byte
+	values   []string
+	floor    float64
+	currency string
+	target   []byte
+}
+
+func newRuleSet(definitions []ruleDefinition) (*ruleSet, error) {
+	rules := make([]rule, 0, len(definitions))
+	for i, definition := range definitions {
+		r, err := newRule(definition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %d: %s", i, err)
+		}
+		rules = append(rules, r)
+	}
+	return &ruleSet{rules: rules}, nil
+}
+
+func newRule(definition ruleDefinition) (rule, error) {
+	r := rule{id: definition.ID, stage: hooks.Stage(definition.Stage)}
+	if r.id == "" {
+		return r, errors.New("id is required")
+	}
+	if r.stage == "" {
+		r.stage = hooks.StageProcessedAuctionRequest
+	}
+	actions, ok := stageActions[r.stage]
+	if !ok {
+		return r, fmt.Errorf(`rule "%s" has unsupported stage "%s"`, r.id, r.stage)
+	}
+	if len(definition.Actions) == 0 {
+		return r, fmt.Errorf(`rule "%s" has no actions`, r.id)
+	}
+
+	for _, conditionDef := range definition.Conditions {
+		c, err := newCondition(conditionDef, r.stage)
+		if err != nil {
+			return r, fmt.Errorf(`rule "%s" has an invalid condition: %s`, r.id, err)
+		}
+		if r.stage == hooks.StageAllProcessedBidResponses && c.isBidCondition() {
+			r.bidConditions = append(r.bidConditions, c)
+		} else {
+			r.conditions = append(r.conditions, c)
+		}
+	}
+
+	for _, actionDef := range definition.Actions {
+		if !slices.Contains(actions, actionDef.Type) {
+			return r, fmt.Errorf(`rule "%s" has action "%s" not supported at the %s stage`, r.id, actionDef.Type, r.stage)
+		}
+		a, err := newAction(actionDef)
+		if err != nil {
+			return r, fmt.Errorf(`rule "%s" has an invalid %s action: %s`, r.id, actionDef.Type, err)
+		}
+		r.actions = append(r.actions, a)
+	}
+	return r, nil
+}
+
+func newCondition(definition conditionDefinition, stage hooks.Stage) (condition, error) {
+	c := condition{field: definition.Field, operator: definition.Operator}
+	switch {
+	case c.field == fieldBidder:
+		if stage == hooks.StageProcessedAuctionRequest {
+			return c, fmt.Errorf("field %s is not available at the %s stage", c.field, stage)
+		}
+	case c.field == fieldMediaType || strings.HasPrefix(c.field, bidFieldPrefix):
+		if stage != hooks.StageAllProcessedBidResponses {
+			return c, fmt.Errorf("field %s is not available at the %s stage", c.field, stage)
+		}
+		if c.field != fieldMediaType {
+			path, err := splitPath(strings.TrimPrefix(c.field, bidFieldPrefix))
+			if err != nil {
+				return c, err
+			}
+			c.path = path
+		}
+	default:
+		path, err := splitPath(c.field)
+		if err != nil {
+			return c, err
+		}
+		c.path = path
+	}
+
+	for _, raw := range definition.Values {
+		value, err := rawValue(raw)
+		if err != nil {
+			return c, fmt.Errorf("field %s has an invalid value: %s", c.field, err)
+		}
+		c.values = append(c.values, value)
+	}
+
+	switch c.operator {
+	case operatorExists, operatorNotExists:
+	case operatorIn, operatorNotIn, operatorPrefix, operatorSuffix, operatorContains:
+		if len(c.values) == 0 {
+			return c, fmt.Errorf("operator %s of field %s requires values", c.operator, c.field)
+		}
+	case operatorGT, operatorGTE, operatorLT, operatorLTE:
+		if len(c.values) != 1 {
+			return c, fmt.Errorf("operator %s of field %s requires exactly one value", c.operator, c.field)
+		}
+		number, err := strconv.ParseFloat(c.values[0], 64)
+		if err != nil {
+			return c, fmt.Errorf("operator %s of field %s requires a number", c.operator, c.field)
+		}
+		c.number = number
+	default:
+		return c, fmt.Errorf(`field %s has unknown operator "%s"`, c.field, c.operator)
+	}
+	return c, nil
+}
+
+func newAction(definition actionDefinition) (action, error) {
+	a := action{kind: definition.Type}
+	switch a.kind {
+	case actionExcludeBidders:
+		if len(definition.Bidders) == 0 {
+			return a, errors.New("bidders are required")
+		}
+		a.bidders = make(map[string]struct{}, len(definition.Bidders))
+		for _, bidder := range definition.Bidders {
+			a.bidders[strings.ToLower(bidder)] = struct{}{}
+		}
+	case actionSetField, actionRemoveField:
+		path, err := splitPath(definition.Field)
+		if err != nil {
+			return a, err
+		}
+		a.path = path
+		if a.kind == actionSetField {
+			if len(definition.Value) == 0 || !json.Valid(definition.Value) {
+				return a, errors.New("value is required")
+			}
+			a.value = definition.Value
+		}
+	case actionAddBCat, actionAddBAdv:
+		if len(definition.Values) == 0 {
+			return a, errors.New("values are required")
+		}
+		a.values = definition.Values
+	case actionSetFloor:
+		if definition.Floor <= 0 {
+			return a, errors.New("floor must be greater than 0")
+		}
+		a.floor = definition.Floor
+		a.currency = strings.ToUpper(definition.Currency)
+		if a.currency == "" {
+			a.currency = defaultFloorCurrency
+		}
+	case actionAddTargeting:
+		var value string
+		if definition.Key == "" || len(definition.Value) == 0 || jsonutil.UnmarshalValid(definition.Value, &value) != nil {
+			return a, errors.New("key and a string value are required")
+		}
+		target, err := jsonutil.Marshal(adServerTarget{Key: definition.Key, Source: "static", Value: value})
+		if err != nil {
+			return a, err
+		}
+		a.target = target
+	}
+	return a, nil
+}
+
+// adServerTarget is an ext.prebid.adservertargeting entry.
+type adServerTarget struct {
+	Key    string `json:"key"`
+	Source string `json:"source"`
+	Value  string `json:"value"`
+}
+
+func (c condition) isBidCondition() bool {
+	return c.field == fieldBidder || c.field == fieldMediaType || strings.HasPrefix(c.field, bidFieldPrefix)
+}
+
+// matches reports whether the values found for the field meet the condition. A field holding
+// an array meets the condition if any of its elements does, except for the not_in operator.
+func (c condition) matches(values []string) bool {
+	switch c.operator {
+	case operatorExists:
+		return len(values) > 0
+	case operatorNotExists:
+		return len(values) == 0
+	case operatorNotIn:
+		for _, value := range values {
+			if slices.Contains(c.values, value) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, value := range values {
+		if c.matchesValue(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c condition) matchesValue(value string) bool {
+	switch c.operator {
+	case operatorIn:
+		return slices.Contains(c.values, value)
+	case operatorPrefix:
+		return slices.ContainsFunc(c.values, func(v string) bool { return strings.HasPrefix(value, v) })
+	case operatorSuffix:
+		return slices.ContainsFunc(c.values, func(v string) bool { return strings.HasSuffix(value, v) })
+	case operatorContains:
+		return slices.ContainsFunc(c.values, func(v string) bool { return strings.Contains(value, v) })
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	switch c.operator {
+	case operatorGT:
+		return number > c.number
+	case operatorGTE:
+		return number >= c.number
+	case operatorLT:
+		return number < c.number
+	case operatorLTE:
+		return number <= c.number
+	}
+	return false
+}
+
+// matchesRequest reports whether the request meets the conditions of the rule.
+// The bidder is empty at the processed_auction_request stage.
+func (r rule) matchesRequest(request []byte, bidder string) bool {
+	for _, c := range r.conditions {
+		var values []string
+		if c.field == fieldBidder {
+			if bidder != "" {
+				values = []string{bidder}
+			}
+		} else {
+			values = lookup(request, jsonparser.Object, c.path)
+		}
+		if !c.matches(values) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesBid reports whether the bid meets the bid conditions of the rule.
+// The bid is marshaled by bidJSON only if a condition is on a bid field.
+func (r rule) matchesBid(bidder, mediaType string, bidJSON func() []byte) bool {
+	for _, c := range r.bidConditions {
+		var values []string
+		switch c.field {
+		case fieldBidder:
+			values = []string{bidder}
+		case fieldMediaType:
+			values = []string{mediaType}
+		default:
+			values = lookup(bidJSON(), jsonparser.Object, c.path)
+		}
+		if !c.matches(values) {
+			return false
+		}
+	}
+	return true
+}
+
+// excludes reports whether the rule excludes the bidder.
+func (r rule) excludes(bidder string) bool {
+	for _, a := range r.actions {
+		if a.kind == actionExcludeBidders {
+			if _, ok := a.bidders[strings.ToLower(bidder)]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rejectsBids reports whether the rule rejects the bids of the bidder meeting its conditions.
+func (r rule) rejectsBids(bidder string) bool {
+	for _, a := range r.actions {
+		if a.kind == actionRejectBids {
+			return true
+		}
+	}
+	return r.excludes(bidder)
+}
+
+func (r rule) actionTypes() []string {
+	types := make([]string, 0, len(r.actions))
+	for _, a := range r.actions {
+		types = append(types, a.kind)
+	}
+	return types
+}
+
+// lookup returns the scalar values found at the path. Arrays are traversed, so "imp.banner.w"
+// returns the banner width of every imp. Objects are returned as raw JSON.
+func lookup(value []byte, dataType jsonparser.ValueType, path []string) []string {
+	switch dataType {
+	case jsonparser.Array:
+		var values []string
+		jsonparser.ArrayEach(value, func(elem []byte, elemType jsonparser.ValueType, _ int, _ error) {
+			values = append(values, lookup(elem, elemType, path)...)
+		})
+		return values
+	case jsonparser.Object:
+		if len(path) == 0 {
+			return []string{string(value)}
+		}
+		child, childType, _, err := jsonparser.Get(value, path[0])
+		if err != nil {
+			return nil
+		}
+		return lookup(child, childType, path[1:])
+	case jsonparser.String:
+		if len(path) > 0 {
+			return nil
+		}
+		s, err := jsonparser.ParseString(value)
+		if err != nil {
+			return nil
+		}
+		return []string{s}
+	case jsonparser.Number, jsonparser.Boolean:
+		if len(path) > 0 {
+			return nil
+		}
+		return []string{string(value)}
+	}
+	return nil
+}
+
+func splitPath(field string) ([]string, error) {
+	if field == "" {
+		return nil, errors.New("field is required")
+	}
+	path := strings.Split(field, ".")
+	for _, key := range path {
+		if key == "" {
+			return nil, fmt.Errorf("field %s is not a valid path", field)
+		}
+	}
+	return path, nil
+}
+
+// rawValue returns strings unquoted and other JSON values as they're written.
+func rawValue(raw json.RawMessage) (string, error) {
+	value, dataType, _, err := jsonparser.Get(raw)
+	if err != nil {
+		return "", err
+	}
+	switch dataType {
+	case jsonparser.String:
+		return jsonparser.ParseString(value)
+	case jsonparser.Number, jsonparser.Boolean:
+		return string(value), nil
+	}
+	return "", fmt.Errorf("%s is not a string, number or boolean", raw)
+}
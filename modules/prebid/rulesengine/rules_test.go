@@ -0,0 +1,233 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRuleSet(t *testing.T) {
+	testCases := []struct {
+		description   string
+		rules         string
+		expectedError string
+	}{
+		{
+			description: "valid-rules",
+			rules: `[
+				{"id": "r1", "conditions": [{"field": "app.bundle", "operator": "in", "values": ["com.example"]}], "actions": [{"type": "exclude_bidders", "bidders": ["appnexus"]}]},
+				{"id": "r2", "stage": "bidder_request", "conditions": [{"field": "bidder", "operator": "in", "values": ["rubicon"]}], "actions": [{"type": "add_bcat", "values": ["IAB25"]}]},
+				{"id": "r3", "stage": "all_processed_bid_responses", "conditions": [{"field": "bid.price", "operator": "lt", "values": [0.1]}], "actions": [{"type": "reject_bids"}]}
+			]`,
+		},
+		{
+			description:   "missing-id",
+			rules:         `[{"actions": [{"type": "reject_bids"}]}]`,
+			expectedError: "invalid rule 0: id is required",
+		},
+		{
+			description:   "unknown-stage",
+			rules:         `[{"id": "r1", "stage": "auction_response", "actions": [{"type": "reject_bids"}]}]`,
+			expectedError: `invalid rule 0: rule "r1" has unsupported stage "auction_response"`,
+		},
+		{
+			description:   "no-actions",
+			rules:         `[{"id": "r1"}]`,
+			expectedError: `invalid rule 0: rule "r1" has no actions`,
+		},
+		{
+			description:   "action-not-supported-at-stage",
+			rules:         `[{"id": "r1", "actions": [{"type": "reject_bids"}]}]`,
+			expectedError: `invalid rule 0: rule "r1" has action "reject_bids" not supported at the processed_auction_request stage`,
+		},
+		{
+			description:   "bidder-field-at-processed-stage",
+			rules:         `[{"id": "r1", "conditions": [{"field": "bidder", "operator": "exists"}], "actions": [{"type": "add_bcat", "values": ["IAB25"]}]}]`,
+			expectedError: `invalid rule 0: rule "r1" has an invalid condition: field bidder is not available at the processed_auction_request stage`,
+		},
+		{
+			description:   "bid-field-at-bidder-stage",
+			rules:         `[{"id": "r1", "stage": "bidder_request", "conditions": [{"field": "bid.price", "operator": "exists"}], "actions": [{"type": "add_bcat", "values": ["IAB25"]}]}]`,
+			expectedError: `invalid rule 0: rule "r1" has an invalid condition: field bid.price is not available at the bidder_request stage`,
+		},
+		{
+			description:   "unknown-operator",
+			rules:         `[{"id": "r1", "conditions": [{"field": "site.domain", "operator": "matches"}], "actions": [{"type": "add_bcat", "values": ["IAB25"]}]}]`,
+			expectedError: `invalid rule 0: rule "r1" has an invalid condition: field site.domain has unknown operator "matches"`,
+		},
+		{
+			description:   "missing-values",
+			rules:         `[{"id": "r1", "conditions": [{"field": "site.domain", "operator": "in"}], "actions": [{"type": "add_bcat", "values": ["IAB25"]}]}]`,
+			expectedError: `invalid rule 0: rule "r1" has an invalid condition: operator in of field site.domain requires values`,
+		},
+		{
+			description:   "non-numeric-comparison",
+			rules:         `[{"id": "r1", "conditions": [{"field": "tmax", "operator": "gt", "values": ["fast"]}], "actions": [{"type": "add_bcat", "values": ["IAB25"]}]}]`,
+			expectedError: `invalid rule 0: rule "r1" has an invalid condition: operator gt of field tmax requires a number`,
+		},
+		{
+			description:   "invalid-path",
+			rules:         `[{"id": "r1", "conditions": [{"field": "site..domain", "operator": "exists"}], "actions": [{"type": "add_bcat", "values": ["IAB25"]}]}]`,
+			expectedError: `invalid rule 0: rule "r1" has an invalid condition: field site..domain is not a valid path`,
+		},
+		{
+			description:   "set-field-without-value",
+			rules:         `[{"id": "r1", "actions": [{"type": "set_field", "field": "regs.coppa"}]}]`,
+			expectedError: `invalid rule 0: rule "r1" has an invalid set_field action: value is required`,
+		},
+		{
+			description:   "set-floor-without-floor",
+			rules:         `[{"id": "r1", "actions": [{"type": "set_floor"}]}]`,
+			expectedError: `invalid rule 0: rule "r1" has an invalid set_floor action: floor must be greater than 0`,
+		},
+		{
+			description:   "add-targeting-with-non-string-value",
+			rules:         `[{"id": "r1", "actions": [{"type": "add_targeting", "key": "hb_rule", "value": 1}]}]`,
+			expectedError: `invalid rule 0: rule "r1" has an invalid add_targeting action: key and a string value are required`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			var definitions []ruleDefinition
+			require.NoError(t, json.Unmarshal([]byte(test.rules), &definitions))
+
+			rules, err := newRuleSet(definitions)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, rules.rules, len(definitions))
+		})
+	}
+}
+
+func TestNewRuleSetSplitsBidConditions(t *testing.T) {
+	rules, err := newRuleSet([]ruleDefinition{{
+		ID:    "r1",
+		Stage: string(hooks.StageAllProcessedBidResponses),
+		Conditions: []conditionDefinition{
+			{Field: "app.bundle", Operator: operatorExists},
+			{Field: "bidder", Operator: operatorIn, Values: []json.RawMessage{json.RawMessage(`"appnexus"`)}},
+			{Field: "mediatype", Operator: operatorIn, Values: []json.RawMessage{json.RawMessage(`"video"`)}},
+			{Field: "bid.adomain", Operator: operatorIn, Values: []json.RawMessage{json.RawMessage(`"bad.com"`)}},
+		},
+		Actions: []actionDefinition{{Type: actionRejectBids}},
+	}})
+	require.NoError(t, err)
+
+	r := rules.rules[0]
+	require.Len(t, r.conditions, 1)
+	assert.Equal(t, []string{"app", "bundle"}, r.conditions[0].path)
+	require.Len(t, r.bidConditions, 3)
+	assert.Equal(t, []string{"adomain"}, r.bidConditions[2].path)
+}
+
+func TestConditionMatches(t *testing.T) {
+	testCases := []struct {
+		description string
+		operator    string
+		values      []string
+		number      float64
+		fieldValues []string
+		expected    bool
+	}{
+		{description: "exists", operator: operatorExists, fieldValues: []string{"a"}, expected: true},
+		{description: "exists-missing", operator: operatorExists, expected: false},
+		{description: "not-exists", operator: operatorNotExists, expected: true},
+		{description: "in", operator: operatorIn, values: []string{"a", "b"}, fieldValues: []string{"b"}, expected: true},
+		{description: "in-any-array-element", operator: operatorIn, values: []string{"IAB25"}, fieldValues: []string{"IAB1", "IAB25"}, expected: true},
+		{description: "in-no-match", operator: operatorIn, values: []string{"a"}, fieldValues: []string{"A"}, expected: false},
+		{description: "in-missing", operator: operatorIn, values: []string{"a"}, expected: false},
+		{description: "not-in", operator: operatorNotIn, values: []string{"a"}, fieldValues: []string{"b"}, expected: true},
+		{description: "not-in-array-element", operator: operatorNotIn, values: []string{"a"}, fieldValues: []string{"b", "a"}, expected: false},
+		{description: "not-in-missing", operator: operatorNotIn, values: []string{"a"}, expected: true},
+		{description: "prefix", operator: operatorPrefix, values: []string{"com.example."}, fieldValues: []string{"com.example.app"}, expected: true},
+		{description: "suffix", operator: operatorSuffix, values: []string{".example.com"}, fieldValues: []string{"news.example.com"}, expected: true},
+		{description: "contains", operator: operatorContains, values: []string{"casino"}, fieldValues: []string{"best-casino-games"}, expected: true},
+		{description: "gt", operator: operatorGT, number: 1, fieldValues: []string{"1.5"}, expected: true},
+		{description: "gt-equal", operator: operatorGT, number: 1, fieldValues: []string{"1"}, expected: false},
+		{description: "gte", operator: operatorGTE, number: 1, fieldValues: []string{"1"}, expected: true},
+		{description: "lt", operator: operatorLT, number: 1, fieldValues: []string{"0.5"}, expected: true},
+		{description: "lte", operator: operatorLTE, number: 1, fieldValues: []string{"2"}, expected: false},
+		{description: "comparison-not-a-number", operator: operatorLT, number: 1, fieldValues: []string{"low"}, expected: false},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			c := condition{operator: test.operator, values: test.values, number: test.number}
+			assert.Equal(t, test.expected, c.matches(test.fieldValues))
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	request := []byte(`{
+		"id": "req1",
+		"imp": [{"id": "imp1", "banner": {"w": 300}}, {"id": "imp2", "video": {"w": 640}}, {"id": "imp3", "banner": {"w": 728}}],
+		"app": {"bundle": "com.example", "publisher": {"id": "pub1"}},
+		"bcat": ["IAB25", "IAB26"],
+		"regs": {"coppa": 1, "gdpr": null},
+		"test": true
+	}`)
+
+	testCases := []struct {
+		path     []string
+		expected []string
+	}{
+		{path: []string{"id"}, expected: []string{"req1"}},
+		{path: []string{"app", "bundle"}, expected: []string{"com.example"}},
+		{path: []string{"app", "publisher", "id"}, expected: []string{"pub1"}},
+		{path: []string{"imp", "banner", "w"}, expected: []string{"300", "728"}},
+		{path: []string{"imp", "id"}, expected: []string{"imp1", "imp2", "imp3"}},
+		{path: []string{"bcat"}, expected: []string{"IAB25", "IAB26"}},
+		{path: []string{"regs", "coppa"}, expected: []string{"1"}},
+		{path: []string{"regs", "gdpr"}, expected: nil},
+		{path: []string{"test"}, expected: []string{"true"}},
+		{path: []string{"site", "domain"}, expected: nil},
+		{path: []string{"id", "value"}, expected: nil},
+		{path: []string{"app", "publisher"}, expected: []string{`{"id": "pub1"}`}},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, lookup(request, jsonparser.Object, test.path), test.path)
+	}
+}
+
+func TestRuleMatchesRequest(t *testing.T) {
+	request := []byte(`{"app": {"bundle": "com.example"}, "device": {"geo": {"country": "USA"}}}`)
+
+	r := rule{conditions: []condition{
+		{path: []string{"app", "bundle"}, operator: operatorIn, values: []string{"com.example"}},
+		{field: fieldBidder, operator: operatorIn, values: []string{"appnexus"}},
+	}}
+	assert.True(t, r.matchesRequest(request, "appnexus"))
+	assert.False(t, r.matchesRequest(request, "rubicon"))
+	assert.False(t, r.matchesRequest(request, ""))
+
+	r = rule{conditions: []condition{
+		{path: []string{"app", "bundle"}, operator: operatorIn, values: []string{"com.example"}},
+		{path: []string{"device", "geo", "country"}, operator: operatorNotIn, values: []string{"USA"}},
+	}}
+	assert.False(t, r.matchesRequest(request, ""))
+
+	assert.True(t, rule{}.matchesRequest(request, ""))
+}
+
+func TestRuleExcludes(t *testing.T) {
+	r := rule{actions: []action{{kind: actionExcludeBidders, bidders: map[string]struct{}{"appnexus": {}}}}}
+	assert.True(t, r.excludes("appnexus"))
+	assert.True(t, r.excludes("AppNexus"))
+	assert.False(t, r.excludes("rubicon"))
+	assert.True(t, r.rejectsBids("appnexus"))
+	assert.False(t, r.rejectsBids("rubicon"))
+
+	r = rule{actions: []action{{kind: actionRejectBids}}}
+	assert.False(t, r.excludes("appnexus"))
+	assert.True(t, r.rejectsBids("rubicon"))
+}
@@ -0,0 +1,138 @@
+package wasm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// Names of the functions and memory a module exports, see README.md for the full ABI.
+const (
+	exportMemory        = "memory"
+	exportAlloc         = "alloc"
+	exportHandlerPrefix = "handle_"
+)
+
+// Mutation paths a module can update, per stage.
+const (
+	pathBody     = "body"
+	pathHeaders  = "headers"
+	pathRequest  = "request"
+	pathBids     = "bids"
+	pathBidders  = "bidders"
+	pathResponse = "response"
+)
+
+const mutationTypeUpdate = "update"
+
+// handlerExport returns the name of the function handling the stage, e.g. "handle_raw_auction_request"
+func handlerExport(stage hooks.Stage) string {
+	return exportHandlerPrefix + stage.String()
+}
+
+// input is the document passed to the module handlers.
+type input struct {
+	Stage         string                  `json:"stage"`
+	Endpoint      string                  `json:"endpoint"`
+	ModuleConfig  json.RawMessage         `json:"module_config,omitempty"`
+	AccountConfig json.RawMessage         `json:"account_config,omitempty"`
+	ModuleContext hookstage.ModuleContext `json:"module_context,omitempty"`
+	Payload       interface{}             `json:"payload"`
+}
+
+// output is the document returned by the module handlers.
+type output struct {
+	Reject        bool                    `json:"reject"`
+	NbrCode       int                     `json:"nbr"`
+	Message       string                  `json:"message"`
+	Errors        []string                `json:"errors"`
+	Warnings      []string                `json:"warnings"`
+	DebugMessages []string                `json:"debug_messages"`
+	AnalyticsTags hookanalytics.Analytics `json:"analytics_tags"`
+	ModuleContext hookstage.ModuleContext `json:"module_context"`
+	Mutations     []mutation              `json:"mutations"`
+}
+
+// mutation replaces the part of the payload found at the path with the value.
+type mutation struct {
+	Type  string          `json:"type"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (m mutation) key() []string {
+	return strings.Split(m.Path, ".")
+}
+
+type entrypointPayload struct {
+	Method  string              `json:"method"`
+	URL     string              `json:"url"`
+	Headers map[string][]string `json:"headers"`
+	Body    json.RawMessage     `json:"body,omitempty"`
+}
+
+type rawAuctionRequestPayload struct {
+	Body json.RawMessage `json:"body"`
+}
+
+type auctionRequestPayload struct {
+	Bidder  string               `json:"bidder,omitempty"`
+	Request *openrtb2.BidRequest `json:"request"`
+}
+
+type bidsPayload struct {
+	Bidder string `json:"bidder,omitempty"`
+	Bids   []bid  `json:"bids"`
+}
+
+type allProcessedBidResponsesPayload struct {
+	Bidders map[openrtb_ext.BidderName][]bid `json:"bidders"`
+}
+
+type auctionResponsePayload struct {
+	Response *openrtb2.BidResponse `json:"response"`
+}
+
+type exitpointPayload struct {
+	Headers map[string][]string `json:"headers"`
+	Body    json.RawMessage     `json:"body,omitempty"`
+}
+
+// bid is a bid and its media type, as received from or returned to the module
+type bid struct {
+	Bid  *openrtb2.Bid       `json:"bid"`
+	Type openrtb_ext.BidType `json:"type"`
+}
+
+// rawBody returns the body as is when it is valid JSON, as the module receives it unescaped
+func rawBody(body []byte) json.RawMessage {
+	if len(body) == 0 || !json.Valid(body) {
+		return nil
+	}
+	return body
+}
+
+// newHookResult converts the module output, without its mutations, to a hook result
+func newHookResult[T any](out output) hookstage.HookResult[T] {
+	return hookstage.HookResult[T]{
+		Reject:        out.Reject,
+		NbrCode:       out.NbrCode,
+		Message:       out.Message,
+		Errors:        out.Errors,
+		Warnings:      out.Warnings,
+		DebugMessages: out.DebugMessages,
+		AnalyticsTags: out.AnalyticsTags,
+		ModuleContext: out.ModuleContext,
+	}
+}
+
+// unsupportedMutationError reports a mutation the stage doesn't support
+func unsupportedMutationError(stage hooks.Stage, m mutation) error {
+	return fmt.Errorf("unsupported %s mutation of %q at stage %s", m.Type, m.Path, stage)
+}
@@ -0,0 +1,45 @@
+package wasm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+const (
+	defaultMemoryLimitMB = 16
+	maxMemoryLimitMB     = 4096
+	pagesPerMB           = 16 // a WebAssembly page is 64KiB
+)
+
+// config represents the host-level configuration of a WASM module.
+type config struct {
+	// Path is the location of the compiled WebAssembly module.
+	Path string `json:"path"`
+	// MemoryLimitMB caps the memory of every module instance, an instance being created per hook call.
+	MemoryLimitMB int `json:"memory_limit_mb"`
+	// Config is passed as is to the module with every call.
+	Config json.RawMessage `json:"config"`
+}
+
+func newConfig(data json.RawMessage) (config, error) {
+	cfg := config{MemoryLimitMB: defaultMemoryLimitMB}
+	if len(data) > 0 {
+		if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("failed to parse config: %s", err)
+		}
+	}
+	if cfg.Path == "" {
+		return cfg, errors.New("path to the WASM module is required")
+	}
+	if cfg.MemoryLimitMB <= 0 || cfg.MemoryLimitMB > maxMemoryLimitMB {
+		return cfg, fmt.Errorf("memory_limit_mb must be between 1 and %d. Got %d", maxMemoryLimitMB, cfg.MemoryLimitMB)
+	}
+	return cfg, nil
+}
+
+func (cfg config) memoryLimitPages() uint32 {
+	return uint32(cfg.MemoryLimitMB * pagesPerMB)
+}
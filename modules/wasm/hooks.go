@@ -0,0 +1,403 @@
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/adapters"
+	"github.com/prebid/prebid-server/v3/exchange/entities"
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// HandleEntrypointHook passes the HTTP request to the module. The module can update the body and headers.
+func (m *Module) HandleEntrypointHook(
+	ctx context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.EntrypointPayload,
+) (hookstage.HookResult[hookstage.EntrypointPayload], error) {
+	stage := hooks.StageEntrypoint
+	in := entrypointPayload{Body: rawBody(payload.Body)}
+	if payload.Request != nil {
+		in.Method = payload.Request.Method
+		in.URL = payload.Request.URL.String()
+		in.Headers = payload.Request.Header
+	}
+
+	out, err := m.call(ctx, stage, miCtx, in)
+	if err != nil {
+		return hookstage.HookResult[hookstage.EntrypointPayload]{}, err
+	}
+
+	result := newHookResult[hookstage.EntrypointPayload](out)
+	for _, mut := range out.Mutations {
+		switch mut.Path {
+		case pathBody:
+			body := []byte(mut.Value)
+			result.ChangeSet.AddMutation(func(p hookstage.EntrypointPayload) (hookstage.EntrypointPayload, error) {
+				p.Body = body
+				return p, nil
+			}, hookstage.MutationUpdate, mut.key()...)
+		case pathHeaders:
+			var headers http.Header
+			if err := decodeValue(stage, mut, &headers); err != nil {
+				return hookstage.HookResult[hookstage.EntrypointPayload]{}, err
+			}
+			result.ChangeSet.AddMutation(func(p hookstage.EntrypointPayload) (hookstage.EntrypointPayload, error) {
+				// The request is shared with the endpoint, so its headers are replaced in place
+				if p.Request != nil {
+					for name := range p.Request.Header {
+						p.Request.Header.Del(name)
+					}
+					for name, values := range headers {
+						p.Request.Header[http.CanonicalHeaderKey(name)] = values
+					}
+				}
+				return p, nil
+			}, hookstage.MutationUpdate, mut.key()...)
+		default:
+			return hookstage.HookResult[hookstage.EntrypointPayload]{}, unsupportedMutationError(stage, mut)
+		}
+	}
+	return result, nil
+}
+
+// HandleRawAuctionHook passes the raw auction request to the module. The module can update the body.
+func (m *Module) HandleRawAuctionHook(
+	ctx context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.RawAuctionRequestPayload,
+) (hookstage.HookResult[hookstage.RawAuctionRequestPayload], error) {
+	stage := hooks.StageRawAuctionRequest
+	out, err := m.call(ctx, stage, miCtx, rawAuctionRequestPayload{Body: rawBody(payload)})
+	if err != nil {
+		return hookstage.HookResult[hookstage.RawAuctionRequestPayload]{}, err
+	}
+
+	result := newHookResult[hookstage.RawAuctionRequestPayload](out)
+	for _, mut := range out.Mutations {
+		if mut.Path != pathBody {
+			return hookstage.HookResult[hookstage.RawAuctionRequestPayload]{}, unsupportedMutationError(stage, mut)
+		}
+		body := hookstage.RawAuctionRequestPayload(mut.Value)
+		result.ChangeSet.AddMutation(func(hookstage.RawAuctionRequestPayload) (hookstage.RawAuctionRequestPayload, error) {
+			return body, nil
+		}, hookstage.MutationUpdate, mut.key()...)
+	}
+	return result, nil
+}
+
+// HandleProcessedAuctionHook passes the processed auction request to the module. The module can update the request.
+func (m *Module) HandleProcessedAuctionHook(
+	ctx context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	stage := hooks.StageProcessedAuctionRequest
+	in := auctionRequestPayload{}
+	if payload.Request != nil {
+		in.Request = payload.Request.BidRequest
+	}
+
+	out, err := m.call(ctx, stage, miCtx, in)
+	if err != nil {
+		return hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{}, err
+	}
+
+	result := newHookResult[hookstage.ProcessedAuctionRequestPayload](out)
+	for _, mut := range out.Mutations {
+		request, err := decodeRequest(stage, mut)
+		if err != nil {
+			return hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{}, err
+		}
+		result.ChangeSet.AddMutation(func(p hookstage.ProcessedAuctionRequestPayload) (hookstage.ProcessedAuctionRequestPayload, error) {
+			replaceRequest(p.Request, request)
+			return p, nil
+		}, hookstage.MutationUpdate, mut.key()...)
+	}
+	return result, nil
+}
+
+// HandleBidderRequestHook passes the request to a bidder to the module. The module can update the request.
+func (m *Module) HandleBidderRequestHook(
+	ctx context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.BidderRequestPayload,
+) (hookstage.HookResult[hookstage.BidderRequestPayload], error) {
+	stage := hooks.StageBidderRequest
+	in := auctionRequestPayload{Bidder: payload.Bidder}
+	if payload.Request != nil {
+		in.Request = payload.Request.BidRequest
+	}
+
+	out, err := m.call(ctx, stage, miCtx, in)
+	if err != nil {
+		return hookstage.HookResult[hookstage.BidderRequestPayload]{}, err
+	}
+
+	result := newHookResult[hookstage.BidderRequestPayload](out)
+	for _, mut := range out.Mutations {
+		request, err := decodeRequest(stage, mut)
+		if err != nil {
+			return hookstage.HookResult[hookstage.BidderRequestPayload]{}, err
+		}
+		result.ChangeSet.AddMutation(func(p hookstage.BidderRequestPayload) (hookstage.BidderRequestPayload, error) {
+			replaceRequest(p.Request, request)
+			return p, nil
+		}, hookstage.MutationUpdate, mut.key()...)
+	}
+	return result, nil
+}
+
+// HandleRawBidderResponseHook passes the bids of a bidder to the module. The module can update, add and remove bids.
+func (m *Module) HandleRawBidderResponseHook(
+	ctx context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.RawBidderResponsePayload,
+) (hookstage.HookResult[hookstage.RawBidderResponsePayload], error) {
+	stage := hooks.StageRawBidderResponse
+	in := bidsPayload{Bidder: payload.Bidder, Bids: []bid{}}
+	if payload.BidderResponse != nil {
+		for _, typedBid := range payload.BidderResponse.Bids {
+			in.Bids = append(in.Bids, bid{Bid: typedBid.Bid, Type: typedBid.BidType})
+		}
+	}
+
+	out, err := m.call(ctx, stage, miCtx, in)
+	if err != nil {
+		return hookstage.HookResult[hookstage.RawBidderResponsePayload]{}, err
+	}
+
+	result := newHookResult[hookstage.RawBidderResponsePayload](out)
+	for _, mut := range out.Mutations {
+		if mut.Path != pathBids {
+			return hookstage.HookResult[hookstage.RawBidderResponsePayload]{}, unsupportedMutationError(stage, mut)
+		}
+		var bids []bid
+		if err := decodeValue(stage, mut, &bids); err != nil {
+			return hookstage.HookResult[hookstage.RawBidderResponsePayload]{}, err
+		}
+		if err := validateBids(stage, mut, bids); err != nil {
+			return hookstage.HookResult[hookstage.RawBidderResponsePayload]{}, err
+		}
+		result.ChangeSet.AddMutation(func(p hookstage.RawBidderResponsePayload) (hookstage.RawBidderResponsePayload, error) {
+			if p.BidderResponse != nil {
+				p.BidderResponse.Bids = replaceTypedBids(p.BidderResponse.Bids, bids)
+			}
+			return p, nil
+		}, hookstage.MutationUpdate, mut.key()...)
+	}
+	return result, nil
+}
+
+// HandleAllProcessedBidResponsesHook passes the bids of all bidders to the module. The module can update and
+// remove bids but not add new ones, as processed bids carry data the module can't provide.
+func (m *Module) HandleAllProcessedBidResponsesHook(
+	ctx context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.AllProcessedBidResponsesPayload,
+) (hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload], error) {
+	stage := hooks.StageAllProcessedBidResponses
+	in := allProcessedBidResponsesPayload{Bidders: make(map[openrtb_ext.BidderName][]bid, len(payload.Responses))}
+	for bidder, seatBid := range payload.Responses {
+		bids := []bid{}
+		if seatBid != nil {
+			for _, pbsBid := range seatBid.Bids {
+				bids = append(bids, bid{Bid: pbsBid.Bid, Type: pbsBid.BidType})
+			}
+		}
+		in.Bidders[bidder] = bids
+	}
+
+	out, err := m.call(ctx, stage, miCtx, in)
+	if err != nil {
+		return hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload]{}, err
+	}
+
+	result := newHookResult[hookstage.AllProcessedBidResponsesPayload](out)
+	for _, mut := range out.Mutations {
+		if mut.Path != pathBidders {
+			return hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload]{}, unsupportedMutationError(stage, mut)
+		}
+		var bidders map[openrtb_ext.BidderName][]bid
+		if err := decodeValue(stage, mut, &bidders); err != nil {
+			return hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload]{}, err
+		}
+		for _, bids := range bidders {
+			if err := validateBids(stage, mut, bids); err != nil {
+				return hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload]{}, err
+			}
+		}
+		result.ChangeSet.AddMutation(func(p hookstage.AllProcessedBidResponsesPayload) (hookstage.AllProcessedBidResponsesPayload, error) {
+			for bidder, bids := range bidders {
+				if seatBid := p.Responses[bidder]; seatBid != nil {
+					seatBid.Bids = replacePbsOrtbBids(seatBid.Bids, bids)
+				}
+			}
+			return p, nil
+		}, hookstage.MutationUpdate, mut.key()...)
+	}
+	return result, nil
+}
+
+// HandleAuctionResponseHook passes the auction response to the module. The module can update the response.
+func (m *Module) HandleAuctionResponseHook(
+	ctx context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.AuctionResponsePayload,
+) (hookstage.HookResult[hookstage.AuctionResponsePayload], error) {
+	stage := hooks.StageAuctionResponse
+	out, err := m.call(ctx, stage, miCtx, auctionResponsePayload{Response: payload.BidResponse})
+	if err != nil {
+		return hookstage.HookResult[hookstage.AuctionResponsePayload]{}, err
+	}
+
+	result := newHookResult[hookstage.AuctionResponsePayload](out)
+	for _, mut := range out.Mutations {
+		if mut.Path != pathResponse {
+			return hookstage.HookResult[hookstage.AuctionResponsePayload]{}, unsupportedMutationError(stage, mut)
+		}
+		var response openrtb2.BidResponse
+		if err := decodeValue(stage, mut, &response); err != nil {
+			return hookstage.HookResult[hookstage.AuctionResponsePayload]{}, err
+		}
+		result.ChangeSet.AddMutation(func(p hookstage.AuctionResponsePayload) (hookstage.AuctionResponsePayload, error) {
+			if p.BidResponse != nil {
+				*p.BidResponse = response
+			}
+			return p, nil
+		}, hookstage.MutationUpdate, mut.key()...)
+	}
+	return result, nil
+}
+
+func decodeValue(stage hooks.Stage, mut mutation, value interface{}) error {
+	if err := jsonutil.UnmarshalValid(mut.Value, value); err != nil {
+		return fmt.Errorf("invalid value for %s mutation of %q at stage %s: %s", mut.Type, mut.Path, stage, err)
+	}
+	return nil
+}
+
+func decodeRequest(stage hooks.Stage, mut mutation) (*openrtb2.BidRequest, error) {
+	if mut.Path != pathRequest {
+		return nil, unsupportedMutationError(stage, mut)
+	}
+	var request openrtb2.BidRequest
+	if err := decodeValue(stage, mut, &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func validateBids(stage hooks.Stage, mut mutation, bids []bid) error {
+	for _, b := range bids {
+		if b.Bid == nil || b.Bid.ID == "" {
+			return fmt.Errorf("invalid value for %s mutation of %q at stage %s: bids require an id", mut.Type, mut.Path, stage)
+		}
+	}
+	return nil
+}
+
+// replaceRequest swaps the request in place, as the wrapper is shared with the auction,
+// dropping the extensions the wrapper parsed from the previous request
+func replaceRequest(wrapper *openrtb_ext.RequestWrapper, request *openrtb2.BidRequest) {
+	if wrapper != nil {
+		*wrapper = openrtb_ext.RequestWrapper{BidRequest: request}
+	}
+}
+
+// replaceTypedBids returns the bids the module kept, keeping the metadata PBS holds for the existing ones
+func replaceTypedBids(current []*adapters.TypedBid, bids []bid) []*adapters.TypedBid {
+	byID := make(map[string]*adapters.TypedBid, len(current))
+	for _, typedBid := range current {
+		if typedBid.Bid != nil {
+			byID[typedBid.Bid.ID] = typedBid
+		}
+	}
+
+	replaced := make([]*adapters.TypedBid, 0, len(bids))
+	for _, b := range bids {
+		typedBid := &adapters.TypedBid{}
+		if existing, ok := byID[b.Bid.ID]; ok {
+			*typedBid = *existing
+		}
+		typedBid.Bid = b.Bid
+		if b.Type != "" {
+			typedBid.BidType = b.Type
+		}
+		replaced = append(replaced, typedBid)
+	}
+	return replaced
+}
+
+// replacePbsOrtbBids returns the existing bids the module kept
+func replacePbsOrtbBids(current []*entities.PbsOrtbBid, bids []bid) []*entities.PbsOrtbBid {
+	byID := make(map[string]*entities.PbsOrtbBid, len(current))
+	for _, pbsBid := range current {
+		if pbsBid.Bid != nil {
+			byID[pbsBid.Bid.ID] = pbsBid
+		}
+	}
+
+	replaced := make([]*entities.PbsOrtbBid, 0, len(bids))
+	for _, b := range bids {
+		existing, ok := byID[b.Bid.ID]
+		if !ok {
+			continue
+		}
+		pbsBid := *existing
+		pbsBid.Bid = b.Bid
+		if b.Type != "" {
+			pbsBid.BidType = b.Type
+		}
+		replaced = append(replaced, &pbsBid)
+	}
+	return replaced
+}
+
+// HandleExitpointHook passes the encoded HTTP response to the module. The module can update the body and headers.
+func (m *Module) HandleExitpointHook(
+	ctx context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.ExitpointPayload,
+) (hookstage.HookResult[hookstage.ExitpointPayload], error) {
+	stage := hooks.StageExitpoint
+	out, err := m.call(ctx, stage, miCtx, exitpointPayload{Headers: payload.Headers, Body: rawBody(payload.Body)})
+	if err != nil {
+		return hookstage.HookResult[hookstage.ExitpointPayload]{}, err
+	}
+
+	result := newHookResult[hookstage.ExitpointPayload](out)
+	for _, mut := range out.Mutations {
+		switch mut.Path {
+		case pathBody:
+			body := []byte(mut.Value)
+			result.ChangeSet.AddMutation(func(p hookstage.ExitpointPayload) (hookstage.ExitpointPayload, error) {
+				p.Body = body
+				return p, nil
+			}, hookstage.MutationUpdate, mut.key()...)
+		case pathHeaders:
+			var headers http.Header
+			if err := decodeValue(stage, mut, &headers); err != nil {
+				return hookstage.HookResult[hookstage.ExitpointPayload]{}, err
+			}
+			result.ChangeSet.AddMutation(func(p hookstage.ExitpointPayload) (hookstage.ExitpointPayload, error) {
+				// The headers are those of the response writer, so they are replaced in place
+				for name := range p.Headers {
+					p.Headers.Del(name)
+				}
+				for name, values := range headers {
+					p.Headers[http.CanonicalHeaderKey(name)] = values
+				}
+				return p, nil
+			}, hookstage.MutationUpdate, mut.key()...)
+		default:
+			return hookstage.HookResult[hookstage.ExitpointPayload]{}, unsupportedMutationError(stage, mut)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,174 @@
+package wasm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/adapters"
+	"github.com/prebid/prebid-server/v3/exchange/entities"
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func applyMutations[T any](t *testing.T, result hookstage.HookResult[T], payload T) T {
+	t.Helper()
+	for _, mut := range result.ChangeSet.Mutations() {
+		var err error
+		payload, err = mut.Apply(payload)
+		require.NoError(t, err)
+	}
+	return payload
+}
+
+func TestHandleEntrypointHook(t *testing.T) {
+	module := newTestModule(t, config{}, testHandler{
+		stage:  hooks.StageEntrypoint,
+		output: `{"warnings":["rewritten"],"mutations":[{"type":"update","path":"body","value":{"id":"new"}},{"type":"update","path":"headers","value":{"x-custom":["1"]}}]}`,
+	})
+
+	request := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+	request.Header.Set("User-Agent", "test")
+	payload := hookstage.EntrypointPayload{Request: request, Body: []byte(`{"id":"old"}`)}
+
+	result, err := module.HandleEntrypointHook(context.Background(), hookstage.ModuleInvocationContext{}, payload)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rewritten"}, result.Warnings)
+
+	payload = applyMutations(t, result, payload)
+	assert.JSONEq(t, `{"id":"new"}`, string(payload.Body))
+	assert.Equal(t, "1", request.Header.Get("X-Custom"))
+	assert.Empty(t, request.Header.Get("User-Agent"))
+}
+
+func TestHandleRawAuctionHook(t *testing.T) {
+	module := newTestModule(t, config{}, testHandler{
+		stage:  hooks.StageRawAuctionRequest,
+		output: `{"reject":true,"nbr":123,"mutations":[{"type":"update","path":"body","value":{"id":"new"}}]}`,
+	})
+
+	result, err := module.HandleRawAuctionHook(context.Background(), hookstage.ModuleInvocationContext{}, []byte(`{"id":"old"}`))
+	require.NoError(t, err)
+	assert.True(t, result.Reject)
+	assert.Equal(t, 123, result.NbrCode)
+
+	payload := applyMutations(t, result, hookstage.RawAuctionRequestPayload(`{"id":"old"}`))
+	assert.JSONEq(t, `{"id":"new"}`, string(payload))
+}
+
+func TestHandleProcessedAuctionHook(t *testing.T) {
+	module := newTestModule(t, config{}, testHandler{
+		stage:  hooks.StageProcessedAuctionRequest,
+		output: `{"mutations":[{"type":"update","path":"request","value":{"id":"req","tmax":100}}]}`,
+	})
+
+	wrapper := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "req", TMax: 500}}
+	payload := hookstage.ProcessedAuctionRequestPayload{Request: wrapper}
+
+	result, err := module.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{}, payload)
+	require.NoError(t, err)
+
+	applyMutations(t, result, payload)
+	assert.Equal(t, int64(100), wrapper.TMax, "the wrapper shared with the auction must be updated")
+}
+
+func TestHandleBidderRequestHook(t *testing.T) {
+	module := newTestModule(t, config{}, testHandler{
+		stage:  hooks.StageBidderRequest,
+		output: `{"mutations":[{"type":"update","path":"request.imp","value":[]}]}`,
+	})
+
+	payload := hookstage.BidderRequestPayload{Bidder: "appnexus", Request: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "req"}}}
+
+	_, err := module.HandleBidderRequestHook(context.Background(), hookstage.ModuleInvocationContext{}, payload)
+	assert.EqualError(t, err, `unsupported update mutation of "request.imp" at stage bidder_request`)
+}
+
+func TestHandleRawBidderResponseHook(t *testing.T) {
+	module := newTestModule(t, config{}, testHandler{
+		stage:  hooks.StageRawBidderResponse,
+		output: `{"mutations":[{"type":"update","path":"bids","value":[{"bid":{"id":"bid1","impid":"imp1","price":0.5}},{"bid":{"id":"bid3","impid":"imp1","price":2},"type":"video"}]}]}`,
+	})
+
+	meta := &openrtb_ext.ExtBidPrebidMeta{AdvertiserName: "advertiser"}
+	response := &adapters.BidderResponse{Bids: []*adapters.TypedBid{
+		{Bid: &openrtb2.Bid{ID: "bid1", ImpID: "imp1", Price: 1}, BidType: openrtb_ext.BidTypeBanner, BidMeta: meta},
+		{Bid: &openrtb2.Bid{ID: "bid2", ImpID: "imp1", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+	}}
+	payload := hookstage.RawBidderResponsePayload{Bidder: "appnexus", BidderResponse: response}
+
+	result, err := module.HandleRawBidderResponseHook(context.Background(), hookstage.ModuleInvocationContext{}, payload)
+	require.NoError(t, err)
+
+	applyMutations(t, result, payload)
+	expectedBids := []*adapters.TypedBid{
+		{Bid: &openrtb2.Bid{ID: "bid1", ImpID: "imp1", Price: 0.5}, BidType: openrtb_ext.BidTypeBanner, BidMeta: meta},
+		{Bid: &openrtb2.Bid{ID: "bid3", ImpID: "imp1", Price: 2}, BidType: openrtb_ext.BidTypeVideo},
+	}
+	assert.Equal(t, expectedBids, response.Bids)
+}
+
+func TestHandleAllProcessedBidResponsesHook(t *testing.T) {
+	module := newTestModule(t, config{}, testHandler{
+		stage:  hooks.StageAllProcessedBidResponses,
+		output: `{"mutations":[{"type":"update","path":"bidders","value":{"appnexus":[{"bid":{"id":"bid2","price":3}},{"bid":{"id":"new"}}]}}]}`,
+	})
+
+	appnexus := &entities.PbsOrtbSeatBid{Bids: []*entities.PbsOrtbBid{
+		{Bid: &openrtb2.Bid{ID: "bid1"}, BidType: openrtb_ext.BidTypeBanner},
+		{Bid: &openrtb2.Bid{ID: "bid2"}, BidType: openrtb_ext.BidTypeBanner, DealPriority: 5},
+	}}
+	rubicon := &entities.PbsOrtbSeatBid{Bids: []*entities.PbsOrtbBid{{Bid: &openrtb2.Bid{ID: "bid4"}}}}
+	payload := hookstage.AllProcessedBidResponsesPayload{Responses: map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+		"appnexus": appnexus,
+		"rubicon":  rubicon,
+	}}
+
+	result, err := module.HandleAllProcessedBidResponsesHook(context.Background(), hookstage.ModuleInvocationContext{}, payload)
+	require.NoError(t, err)
+
+	applyMutations(t, result, payload)
+	assert.Equal(t, []*entities.PbsOrtbBid{{Bid: &openrtb2.Bid{ID: "bid2", Price: 3}, BidType: openrtb_ext.BidTypeBanner, DealPriority: 5}}, appnexus.Bids)
+	assert.Len(t, rubicon.Bids, 1, "bidders absent from the mutation keep their bids")
+}
+
+func TestHandleAuctionResponseHook(t *testing.T) {
+	module := newTestModule(t, config{}, testHandler{
+		stage: hooks.StageAuctionResponse,
+		output: `{"analytics_tags":{"activities":[{"name":"rewrite","status":"success"}]},` +
+			`"mutations":[{"type":"update","path":"response","value":{"id":"resp","cur":"EUR"}}]}`,
+	})
+
+	response := &openrtb2.BidResponse{ID: "resp", Cur: "USD"}
+	payload := hookstage.AuctionResponsePayload{BidResponse: response}
+
+	result, err := module.HandleAuctionResponseHook(context.Background(), hookstage.ModuleInvocationContext{}, payload)
+	require.NoError(t, err)
+	assert.Equal(t, hookanalytics.Analytics{Activities: []hookanalytics.Activity{{Name: "rewrite", Status: hookanalytics.ActivityStatusSuccess}}}, result.AnalyticsTags)
+
+	applyMutations(t, result, payload)
+	assert.Equal(t, "EUR", response.Cur)
+}
+
+func TestHandleExitpointHook(t *testing.T) {
+	module := newTestModule(t, config{}, testHandler{
+		stage:  hooks.StageExitpoint,
+		output: `{"mutations":[{"type":"update","path":"body","value":{"id":"new"}},{"type":"update","path":"headers","value":{"content-type":["application/json"],"x-custom":["1"]}}]}`,
+	})
+
+	headers := http.Header{"Content-Type": []string{"text/plain"}, "X-Prebid": []string{"pbs-go"}}
+	payload := hookstage.ExitpointPayload{Headers: headers, Body: []byte(`{"id":"old"}`)}
+
+	result, err := module.HandleExitpointHook(context.Background(), hookstage.ModuleInvocationContext{}, payload)
+	require.NoError(t, err)
+
+	payload = applyMutations(t, result, payload)
+	assert.JSONEq(t, `{"id":"new"}`, string(payload.Body))
+	assert.Equal(t, http.Header{"Content-Type": []string{"application/json"}, "X-Custom": []string{"1"}}, headers)
+}
@@ -0,0 +1,204 @@
+// Package wasm runs hook modules compiled to WebAssembly, so modules can be added to Prebid Server
+// without recompiling it. Modules are configured under the "wasm" vendor of hooks.modules and are
+// referred to as "wasm.<module name>" in execution plans.
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/modules/moduledeps"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// Vendor is the hooks.modules vendor under which WASM modules are configured
+const Vendor = "wasm"
+
+var stages = []hooks.Stage{
+	hooks.StageEntrypoint,
+	hooks.StageRawAuctionRequest,
+	hooks.StageProcessedAuctionRequest,
+	hooks.StageBidderRequest,
+	hooks.StageRawBidderResponse,
+	hooks.StageAllProcessedBidResponses,
+	hooks.StageAuctionResponse,
+	hooks.StageExitpoint,
+}
+
+// Builder compiles the WASM module found at the configured path.
+func Builder(rawConfig json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, err := newConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	binary, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WASM module: %s", err)
+	}
+
+	return newModule(context.Background(), cfg, binary)
+}
+
+// Module runs the hooks exported by a WASM module. Each call runs in a new module instance, limited
+// to the configured memory and interrupted when the hook group times out.
+type Module struct {
+	cfg      config
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	handlers map[hooks.Stage]bool
+}
+
+func newModule(ctx context.Context, cfg config, binary []byte) (*Module, error) {
+	runtimeConfig := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(cfg.memoryLimitPages()).
+		WithCloseOnContextDone(true)
+	runtime := wazero.NewRuntimeWithConfig(ctx, runtimeConfig)
+
+	module, err := compile(ctx, runtime, binary)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+	module.cfg = cfg
+	return module, nil
+}
+
+// Shutdown closes the WASM runtime along with the compiled module.
+func (m *Module) Shutdown() {
+	if err := m.runtime.Close(context.Background()); err != nil {
+		glog.Errorf("wasm: failed to close runtime: %s", err)
+	}
+}
+
+func compile(ctx context.Context, runtime wazero.Runtime, binary []byte) (*Module, error) {
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		return nil, fmt.Errorf("failed to instantiate WASI: %s", err)
+	}
+
+	compiled, err := runtime.CompileModule(ctx, binary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile WASM module: %s", err)
+	}
+
+	if _, ok := compiled.ExportedMemories()[exportMemory]; !ok {
+		return nil, fmt.Errorf("WASM module must export its %q", exportMemory)
+	}
+
+	functions := compiled.ExportedFunctions()
+	if !hasSignature(functions[exportAlloc], []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}) {
+		return nil, fmt.Errorf("WASM module must export %s(i32) i32", exportAlloc)
+	}
+
+	handlers := make(map[hooks.Stage]bool)
+	for _, stage := range stages {
+		name := handlerExport(stage)
+		definition, ok := functions[name]
+		if !ok {
+			continue
+		}
+		if !hasSignature(definition, []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{api.ValueTypeI64}) {
+			return nil, fmt.Errorf("WASM module export %s must be %s(i32, i32) i64", name, name)
+		}
+		handlers[stage] = true
+	}
+	if len(handlers) == 0 {
+		return nil, errors.New("WASM module does not export any hook handler")
+	}
+
+	return &Module{runtime: runtime, compiled: compiled, handlers: handlers}, nil
+}
+
+// moduleConfig returns the config object of the effective module config, which includes the account-level overrides.
+// The host-level config object is used when no effective config was resolved for the call.
+func (m *Module) moduleConfig(miCtx hookstage.ModuleInvocationContext) json.RawMessage {
+	if len(miCtx.Config) == 0 {
+		return m.cfg.Config
+	}
+
+	var effective config
+	if err := jsonutil.Unmarshal(miCtx.Config, &effective); err != nil {
+		glog.Errorf("wasm: failed to read the effective module config, using the host config: %s", err)
+		return m.cfg.Config
+	}
+	return effective.Config
+}
+
+func hasSignature(definition api.FunctionDefinition, params, results []api.ValueType) bool {
+	if definition == nil {
+		return false
+	}
+	return string(definition.ParamTypes()) == string(params) && string(definition.ResultTypes()) == string(results)
+}
+
+// call runs the stage handler in a new instance of the module and returns its output. The stages the module
+// doesn't export a handler for are skipped, so an execution plan naming them doesn't fail every request.
+func (m *Module) call(ctx context.Context, stage hooks.Stage, miCtx hookstage.ModuleInvocationContext, payload interface{}) (output, error) {
+	var out output
+
+	if !m.handlers[stage] {
+		return out, nil
+	}
+
+	in, err := jsonutil.Marshal(input{
+		Stage:         stage.String(),
+		Endpoint:      miCtx.Endpoint,
+		ModuleConfig:  m.moduleConfig(miCtx),
+		AccountConfig: miCtx.AccountConfig,
+		ModuleContext: miCtx.ModuleContext,
+		Payload:       payload,
+	})
+	if err != nil {
+		return out, fmt.Errorf("failed to encode %s input: %s", stage, err)
+	}
+
+	instanceConfig := wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize")
+	instance, err := m.runtime.InstantiateModule(ctx, m.compiled, instanceConfig)
+	if err != nil {
+		return out, fmt.Errorf("failed to instantiate WASM module: %s", err)
+	}
+	defer instance.Close(context.Background())
+
+	allocated, err := instance.ExportedFunction(exportAlloc).Call(ctx, uint64(len(in)))
+	if err != nil {
+		return out, fmt.Errorf("%s failed: %s", exportAlloc, err)
+	}
+	inPtr := uint32(allocated[0])
+	if !instance.Memory().Write(inPtr, in) {
+		return out, fmt.Errorf("%s returned an out of range pointer for %d bytes", exportAlloc, len(in))
+	}
+
+	handler := handlerExport(stage)
+	results, err := instance.ExportedFunction(handler).Call(ctx, uint64(inPtr), uint64(len(in)))
+	if err != nil {
+		if ctx.Err() != nil {
+			return out, fmt.Errorf("%s interrupted: %s", handler, ctx.Err())
+		}
+		return out, fmt.Errorf("%s failed: %s", handler, err)
+	}
+
+	// The handler returns the pointer to its output in the high 32 bits and its length in the low 32 bits
+	outPtr, outLen := uint32(results[0]>>32), uint32(results[0])
+	data, ok := instance.Memory().Read(outPtr, outLen)
+	if !ok {
+		return out, fmt.Errorf("%s returned an out of range output", handler)
+	}
+	if err := jsonutil.UnmarshalValid(data, &out); err != nil {
+		return out, fmt.Errorf("failed to decode %s output: %s", handler, err)
+	}
+	for _, m := range out.Mutations {
+		if m.Type != mutationTypeUpdate {
+			return out, unsupportedMutationError(stage, m)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,347 @@
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/modules/moduledeps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testHandler is a stage handler of a test guest. It returns a fixed output, its input or never returns.
+type testHandler struct {
+	stage  hooks.Stage
+	output string
+	echo   bool
+	loop   bool
+}
+
+// buildTestGuest assembles a WASM module exporting memory, a bump allocator and the handlers
+func buildTestGuest(memoryPages int, handlers ...testHandler) []byte {
+	var data []byte
+	offsets := make([]int, len(handlers))
+	for i, h := range handlers {
+		offsets[i] = len(data)
+		data = append(data, h.output...)
+	}
+	heapStart := (len(data)/8 + 1) * 8
+
+	types := wasmVec(
+		[]byte{0x60, 0x01, 0x7f, 0x01, 0x7f},       // alloc: (i32) -> i32
+		[]byte{0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e}, // handle_*: (i32, i32) -> i64
+	)
+
+	functions := [][]byte{{0x00}}
+	exports := [][]byte{
+		append(wasmName(exportMemory), 0x02, 0x00),
+		append(wasmName(exportAlloc), 0x00, 0x00),
+	}
+	bodies := [][]byte{
+		// global.get 0, global.get 0, local.get 0, i32.add, global.set 0
+		wasmBody(0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00),
+	}
+	for i, h := range handlers {
+		functions = append(functions, []byte{0x01})
+		exports = append(exports, append(wasmName(handlerExport(h.stage)), 0x00, byte(i+1)))
+		if h.loop {
+			// loop, br 0, end, i64.const 0
+			bodies = append(bodies, wasmBody(0x03, 0x40, 0x0c, 0x00, 0x0b, 0x42, 0x00))
+		} else if h.echo {
+			// local.get 0, i64.extend_i32_u, i64.const 32, i64.shl, local.get 1, i64.extend_i32_u, i64.or
+			bodies = append(bodies, wasmBody(0x20, 0x00, 0xad, 0x42, 0x20, 0x86, 0x20, 0x01, 0xad, 0x84))
+		} else {
+			result := int64(offsets[i])<<32 | int64(len(h.output))
+			bodies = append(bodies, wasmBody(append([]byte{0x42}, wasmSLEB(result)...)...))
+		}
+	}
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	module = append(module, wasmSection(0x01, types)...)
+	module = append(module, wasmSection(0x03, wasmVec(functions...))...)
+	module = append(module, wasmSection(0x05, wasmVec(append([]byte{0x00}, wasmULEB(uint64(memoryPages))...)))...)
+	module = append(module, wasmSection(0x06, wasmVec(append(append([]byte{0x7f, 0x01, 0x41}, wasmSLEB(int64(heapStart))...), 0x0b)))...)
+	module = append(module, wasmSection(0x07, wasmVec(exports...))...)
+	module = append(module, wasmSection(0x0a, wasmVec(bodies...))...)
+	module = append(module, wasmSection(0x0b, wasmVec(append([]byte{0x00, 0x41, 0x00, 0x0b}, append(wasmULEB(uint64(len(data))), data...)...)))...)
+	return module
+}
+
+func wasmSection(id byte, content []byte) []byte {
+	return append(append([]byte{id}, wasmULEB(uint64(len(content)))...), content...)
+}
+
+func wasmVec(items ...[]byte) []byte {
+	vec := wasmULEB(uint64(len(items)))
+	for _, item := range items {
+		vec = append(vec, item...)
+	}
+	return vec
+}
+
+func wasmName(name string) []byte {
+	return append(wasmULEB(uint64(len(name))), name...)
+}
+
+func wasmBody(instructions ...byte) []byte {
+	body := append([]byte{0x00}, instructions...) // no locals
+	body = append(body, 0x0b)
+	return append(wasmULEB(uint64(len(body))), body...)
+}
+
+func wasmULEB(v uint64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func wasmSLEB(v int64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func newTestModule(t *testing.T, cfg config, handlers ...testHandler) *Module {
+	t.Helper()
+	if cfg.MemoryLimitMB == 0 {
+		cfg.MemoryLimitMB = defaultMemoryLimitMB
+	}
+	module, err := newModule(context.Background(), cfg, buildTestGuest(1, handlers...))
+	require.NoError(t, err)
+	t.Cleanup(module.Shutdown)
+	return module
+}
+
+func TestNewConfig(t *testing.T) {
+	testCases := []struct {
+		name           string
+		data           json.RawMessage
+		expectedConfig config
+		expectedErr    string
+	}{
+		{
+			name:           "defaults",
+			data:           json.RawMessage(`{"enabled":true,"path":"module.wasm"}`),
+			expectedConfig: config{Path: "module.wasm", MemoryLimitMB: defaultMemoryLimitMB},
+		},
+		{
+			name:           "module-config",
+			data:           json.RawMessage(`{"path":"module.wasm","memory_limit_mb":4,"config":{"key":"value"}}`),
+			expectedConfig: config{Path: "module.wasm", MemoryLimitMB: 4, Config: json.RawMessage(`{"key":"value"}`)},
+		},
+		{
+			name:        "missing-path",
+			data:        json.RawMessage(`{"enabled":true}`),
+			expectedErr: "path to the WASM module is required",
+		},
+		{
+			name:        "memory-limit-too-high",
+			data:        json.RawMessage(`{"path":"module.wasm","memory_limit_mb":8192}`),
+			expectedErr: "memory_limit_mb must be between 1 and 4096. Got 8192",
+		},
+		{
+			name:        "malformed",
+			data:        json.RawMessage(`{"path":1}`),
+			expectedErr: "failed to parse config",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			cfg, err := newConfig(test.data)
+			if test.expectedErr != "" {
+				assert.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedConfig, cfg)
+		})
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	dir := t.TempDir()
+	validPath := filepath.Join(dir, "valid.wasm")
+	require.NoError(t, os.WriteFile(validPath, buildTestGuest(1, testHandler{stage: hooks.StageAuctionResponse, output: `{}`}), 0644))
+	noHandlersPath := filepath.Join(dir, "no-handlers.wasm")
+	require.NoError(t, os.WriteFile(noHandlersPath, buildTestGuest(1), 0644))
+	invalidPath := filepath.Join(dir, "invalid.wasm")
+	require.NoError(t, os.WriteFile(invalidPath, []byte("not wasm"), 0644))
+
+	testCases := []struct {
+		name             string
+		path             string
+		expectedHandlers map[hooks.Stage]bool
+		expectedErr      string
+	}{
+		{
+			name:             "valid",
+			path:             validPath,
+			expectedHandlers: map[hooks.Stage]bool{hooks.StageAuctionResponse: true},
+		},
+		{
+			name:        "missing-file",
+			path:        filepath.Join(dir, "missing.wasm"),
+			expectedErr: "failed to read WASM module",
+		},
+		{
+			name:        "invalid-binary",
+			path:        invalidPath,
+			expectedErr: "failed to compile WASM module",
+		},
+		{
+			name:        "no-handlers",
+			path:        noHandlersPath,
+			expectedErr: "WASM module does not export any hook handler",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			rawConfig, err := json.Marshal(map[string]interface{}{"enabled": true, "path": test.path})
+			require.NoError(t, err)
+
+			module, err := Builder(rawConfig, moduledeps.ModuleDeps{})
+			if test.expectedErr != "" {
+				assert.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedHandlers, module.(*Module).handlers)
+		})
+	}
+}
+
+func TestCall(t *testing.T) {
+	module := newTestModule(t, config{Config: json.RawMessage(`{"key":"value"}`)},
+		testHandler{stage: hooks.StageRawAuctionRequest, output: `{"message":"ok","module_context":{"seen":true}}`},
+		testHandler{stage: hooks.StageBidderRequest, output: `not json`},
+		testHandler{stage: hooks.StageAuctionResponse, output: `{"mutations":[{"type":"delete","path":"response"}]}`},
+		testHandler{stage: hooks.StageRawBidderResponse, loop: true},
+		testHandler{stage: hooks.StageProcessedAuctionRequest, echo: true},
+	)
+
+	testCases := []struct {
+		name           string
+		stage          hooks.Stage
+		timeout        time.Duration
+		accountConfig  json.RawMessage
+		moduleContext  hookstage.ModuleContext
+		expectedOutput output
+		expectedErr    string
+	}{
+		{
+			name:           "output",
+			stage:          hooks.StageRawAuctionRequest,
+			expectedOutput: output{Message: "ok", ModuleContext: hookstage.ModuleContext{"seen": true}},
+		},
+		{
+			name:           "module-context-passed-to-module",
+			stage:          hooks.StageProcessedAuctionRequest,
+			moduleContext:  hookstage.ModuleContext{"previous": "stage"},
+			expectedOutput: output{ModuleContext: hookstage.ModuleContext{"previous": "stage"}},
+		},
+		{
+			name:           "handler-not-exported-skipped",
+			stage:          hooks.StageEntrypoint,
+			expectedOutput: output{},
+		},
+		{
+			name:        "malformed-output",
+			stage:       hooks.StageBidderRequest,
+			expectedErr: "failed to decode handle_bidder_request output",
+		},
+		{
+			name:        "unsupported-mutation-type",
+			stage:       hooks.StageAuctionResponse,
+			expectedErr: `unsupported delete mutation of "response" at stage auction_response`,
+		},
+		{
+			name:        "interrupted-on-timeout",
+			stage:       hooks.StageRawBidderResponse,
+			timeout:     50 * time.Millisecond,
+			expectedErr: "handle_raw_bidder_response interrupted: context deadline exceeded",
+		},
+		{
+			name:          "input-exceeds-memory",
+			stage:         hooks.StageRawAuctionRequest,
+			accountConfig: json.RawMessage(`"` + strings.Repeat("a", 70000) + `"`),
+			expectedErr:   "alloc returned an out of range pointer",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, test.timeout)
+				defer cancel()
+			}
+
+			out, err := module.call(ctx, test.stage, hookstage.ModuleInvocationContext{AccountConfig: test.accountConfig, ModuleContext: test.moduleContext}, struct{}{})
+			if test.expectedErr != "" {
+				assert.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedOutput, out)
+		})
+	}
+}
+
+func TestModuleConfig(t *testing.T) {
+	module := &Module{cfg: config{Config: json.RawMessage(`{"source":"host"}`)}}
+
+	testCases := []struct {
+		name           string
+		effective      json.RawMessage
+		expectedConfig json.RawMessage
+	}{
+		{
+			name:           "effective-config",
+			effective:      json.RawMessage(`{"path":"module.wasm","config":{"source":"account"}}`),
+			expectedConfig: json.RawMessage(`{"source":"account"}`),
+		},
+		{
+			name:           "effective-config-without-config-object",
+			effective:      json.RawMessage(`{"path":"module.wasm"}`),
+			expectedConfig: nil,
+		},
+		{
+			name:           "no-effective-config",
+			expectedConfig: json.RawMessage(`{"source":"host"}`),
+		},
+		{
+			name:           "malformed-effective-config",
+			effective:      json.RawMessage(`{"config":`),
+			expectedConfig: json.RawMessage(`{"source":"host"}`),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := module.moduleConfig(hookstage.ModuleInvocationContext{Config: test.effective})
+			assert.Equal(t, test.expectedConfig, cfg)
+		})
+	}
+}
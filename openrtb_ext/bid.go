@@ -170,6 +170,24 @@ const (
 	HbCategoryDurationKey TargetingKey = "hb_pb_cat_dur"
 )
 
+// TargetingKeys lists the targeting keys Prebid Server sets on bids.
+var TargetingKeys = []TargetingKey{
+	HbpbConstantKey,
+	HbEnvKey,
+	HbConstantCacheHostKey,
+	HbConstantCachePathKey,
+	HbBidderConstantKey,
+	HbSizeConstantKey,
+	HbDealIDConstantKey,
+	HbFormatKey,
+	HbCacheKey,
+	HbVastCacheKey,
+	HbCategoryDurationKey,
+}
+
+// TargetingKeyNamespace is the namespace shared by the targeting keys Prebid Server sets on bids.
+const TargetingKeyNamespace = "hb"
+
 func (key TargetingKey) BidderKey(bidder BidderName, maxLength int) string {
 	s := string(key) + "_" + string(bidder)
 	if maxLength != 0 {
@@ -74,6 +74,8 @@ type ExtImpPrebidFloors struct {
 // ExtStoredRequest defines the contract for bidrequest.imp[i].ext.prebid.storedrequest
 type ExtStoredRequest struct {
 	ID string `json:"id"`
+	// Params are the values of the params placeholders of a templated stored request or imp.
+	Params map[string]json.RawMessage `json:"params,omitempty"`
 }
 
 // ExtStoredAuctionResponse defines the contract for bidrequest.imp[i].ext.prebid.storedauctionresponse
@@ -0,0 +1,20 @@
+package openrtb_ext
+
+// SeatNonBid list the reasons why bid was not resulted in positive bid
+// reason could be either No bid, Error, Request rejection or Response rejection
+// Reference:  https://github.com/InteractiveAdvertisingBureau/openrtb/blob/master/extensions/community_extensions/seat-non-bid.md#list-non-bid-status-codes
+type NonBidReason int64
+
+const (
+	ErrorGeneral                           NonBidReason = 100 // Error - General
+	ErrorTimeout                           NonBidReason = 101 // Error - Timeout
+	ErrorBidderUnreachable                 NonBidReason = 103 // Error - Bidder Unreachable
+	ResponseRejectedGeneral                NonBidReason = 300
+	ResponseRejectedBelowFloor             NonBidReason = 301 // Response Rejected - Below Floor
+	ResponseRejectedCategoryMappingInvalid NonBidReason = 303 // Response Rejected - Category Mapping Invalid
+	ResponseRejectedBelowDealFloor         NonBidReason = 304 // Response Rejected - Bid was Below Deal Floor
+	ResponseRejectedInvalidCreative        NonBidReason = 350 // Response Rejected - Invalid Creative
+	ResponseRejectedCreativeSizeNotAllowed NonBidReason = 351 // Response Rejected - Invalid Creative (Size Not Allowed)
+	ResponseRejectedCreativeNotSecure      NonBidReason = 352 // Response Rejected - Invalid Creative (Not Secure)
+	ResponseRejectedCreativeMalware        NonBidReason = 354 // Response Rejected - Invalid Creative (Malware)
+)
@@ -42,32 +42,34 @@ type ExtRequest struct {
 
 // ExtRequestPrebid defines the contract for bidrequest.ext.prebid
 type ExtRequestPrebid struct {
-	AdServerTargeting    []AdServerTarget                `json:"adservertargeting,omitempty"`
-	Aliases              map[string]string               `json:"aliases,omitempty"`
-	AliasGVLIDs          map[string]uint16               `json:"aliasgvlids,omitempty"`
-	Analytics            map[string]json.RawMessage      `json:"analytics,omitempty"`
-	BidAdjustmentFactors map[string]float64              `json:"bidadjustmentfactors,omitempty"`
-	BidAdjustments       *ExtRequestPrebidBidAdjustments `json:"bidadjustments,omitempty"`
-	BidderConfigs        []BidderConfig                  `json:"bidderconfig,omitempty"`
-	BidderParams         json.RawMessage                 `json:"bidderparams,omitempty"`
-	Cache                *ExtRequestPrebidCache          `json:"cache,omitempty"`
-	Channel              *ExtRequestPrebidChannel        `json:"channel,omitempty"`
-	CurrencyConversions  *ExtRequestCurrency             `json:"currency,omitempty"`
-	Data                 *ExtRequestPrebidData           `json:"data,omitempty"`
-	Debug                bool                            `json:"debug,omitempty"`
-	Events               json.RawMessage                 `json:"events,omitempty"`
-	Experiment           *Experiment                     `json:"experiment,omitempty"`
-	Floors               *PriceFloorRules                `json:"floors,omitempty"`
-	Integration          string                          `json:"integration,omitempty"`
-	MultiBid             []*ExtMultiBid                  `json:"multibid,omitempty"`
-	MultiBidMap          map[string]ExtMultiBid          `json:"-"`
-	Passthrough          json.RawMessage                 `json:"passthrough,omitempty"`
-	SChains              []*ExtRequestPrebidSChain       `json:"schains,omitempty"`
-	Sdk                  *ExtRequestSdk                  `json:"sdk,omitempty"`
-	Server               *ExtRequestPrebidServer         `json:"server,omitempty"`
-	StoredRequest        *ExtStoredRequest               `json:"storedrequest,omitempty"`
-	SupportDeals         bool                            `json:"supportdeals,omitempty"`
-	Targeting            *ExtRequestTargeting            `json:"targeting,omitempty"`
+	AdServerTargeting    []AdServerTarget                      `json:"adservertargeting,omitempty"`
+	Aliases              map[string]string                     `json:"aliases,omitempty"`
+	AliasGVLIDs          map[string]uint16                     `json:"aliasgvlids,omitempty"`
+	Analytics            map[string]json.RawMessage            `json:"analytics,omitempty"`
+	BidAdjustmentFactors map[string]float64                    `json:"bidadjustmentfactors,omitempty"`
+	BidAdjustments       *ExtRequestPrebidBidAdjustments       `json:"bidadjustments,omitempty"`
+	BidderConfigs        []BidderConfig                        `json:"bidderconfig,omitempty"`
+	BidderParams         json.RawMessage                       `json:"bidderparams,omitempty"`
+	Cache                *ExtRequestPrebidCache                `json:"cache,omitempty"`
+	Channel              *ExtRequestPrebidChannel              `json:"channel,omitempty"`
+	CurrencyConversions  *ExtRequestCurrency                   `json:"currency,omitempty"`
+	Data                 *ExtRequestPrebidData                 `json:"data,omitempty"`
+	Debug                bool                                  `json:"debug,omitempty"`
+	Events               json.RawMessage                       `json:"events,omitempty"`
+	Experiment           *Experiment                           `json:"experiment,omitempty"`
+	Floors               *PriceFloorRules                      `json:"floors,omitempty"`
+	Integration          string                                `json:"integration,omitempty"`
+	Modules              map[string]map[string]json.RawMessage `json:"modules,omitempty"`
+	MultiBid             []*ExtMultiBid                        `json:"multibid,omitempty"`
+	MultiBidMap          map[string]ExtMultiBid                `json:"-"`
+	Passthrough          json.RawMessage                       `json:"passthrough,omitempty"`
+	SChains              []*ExtRequestPrebidSChain             `json:"schains,omitempty"`
+	Sdk                  *ExtRequestSdk                        `json:"sdk,omitempty"`
+	Server               *ExtRequestPrebidServer               `json:"server,omitempty"`
+	StoredRequest        *ExtStoredRequest                     `json:"storedrequest,omitempty"`
+	StoredVariants       *ExtStoredVariants                    `json:"storedvariants,omitempty"`
+	SupportDeals         bool                                  `json:"supportdeals,omitempty"`
+	Targeting            *ExtRequestTargeting                  `json:"targeting,omitempty"`
 
 	//AlternateBidderCodes is populated with host's AlternateBidderCodes config if not defined in request
 	AlternateBidderCodes *ExtAlternateBidderCodes `json:"alternatebiddercodes,omitempty"`
@@ -93,6 +95,26 @@ type ExtRequestPrebid struct {
 	Trace string `json:"trace,omitempty"`
 }
 
+// ExtStoredVariants records the variants of the versioned stored requests, imps and account used by the request.
+// It's set by Prebid Server and overwrites any value sent in the request.
+type ExtStoredVariants struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Imps     map[string]string `json:"imps,omitempty"`
+	Account  string            `json:"account,omitempty"`
+}
+
+// Clone returns a deep copy of the stored variants.
+func (esv *ExtStoredVariants) Clone() *ExtStoredVariants {
+	if esv == nil {
+		return nil
+	}
+	return &ExtStoredVariants{
+		Requests: maps.Clone(esv.Requests),
+		Imps:     maps.Clone(esv.Imps),
+		Account:  esv.Account,
+	}
+}
+
 type AdServerTarget struct {
 	Key    string `json:"key,omitempty"`
 	Source string `json:"source,omitempty"`
@@ -201,6 +223,7 @@ type ExtRequestTargeting struct {
 	PreferDeals               bool                       `json:"preferdeals,omitempty"`
 	AppendBidderNames         bool                       `json:"appendbiddernames,omitempty"`
 	AlwaysIncludeDeals        bool                       `json:"alwaysincludedeals,omitempty"`
+	AdServerCurrency          string                     `json:"adservercurrency,omitempty"`
 }
 
 type ExtIncludeBrandCategory struct {
@@ -480,12 +503,15 @@ func (erp *ExtRequestPrebid) Clone() *ExtRequestPrebid {
 
 	clone.StoredRequest = ptrutil.Clone(erp.StoredRequest)
 
+	clone.StoredVariants = erp.StoredVariants.Clone()
+
 	if erp.Targeting != nil {
 		newTargeting := &ExtRequestTargeting{
 			IncludeFormat:     erp.Targeting.IncludeFormat,
 			DurationRangeSec:  slices.Clone(erp.Targeting.DurationRangeSec),
 			PreferDeals:       erp.Targeting.PreferDeals,
 			AppendBidderNames: erp.Targeting.AppendBidderNames,
+			AdServerCurrency:  erp.Targeting.AdServerCurrency,
 		}
 		if erp.Targeting.PriceGranularity != nil {
 			newPriceGranularity := &PriceGranularity{
@@ -16,6 +16,9 @@ type ValidationConfig struct {
 
 type RequestValidator interface {
 	ValidateImp(imp *openrtb_ext.ImpWrapper, cfg ValidationConfig, index int, aliases map[string]string, hasStoredAuctionResponses bool, storedBidResponses stored_responses.ImpBidderStoredResp) []error
+	// ValidateAliases checks the aliases refer to enabled core bidders, and replaces the bidders by their normalized names
+	ValidateAliases(aliases map[string]string) error
+	ValidateBidAdjustmentFactors(adjustmentFactors map[string]float64, aliases map[string]string) error
 }
 
 func NewRequestValidator(bidderMap map[string]openrtb_ext.BidderName, disabledBidders map[string]string, paramsValidator openrtb_ext.BidderParamValidator) RequestValidator {
@@ -0,0 +1,89 @@
+package ortb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// The request level validations are shared by the auction endpoints and the stored requests write API, so stored
+// requests are rejected with the same messages as the requests using them.
+
+// ValidateTMax checks the request timeout isn't negative. A zero tmax leaves the timeout to the host config.
+func ValidateTMax(tmax int64) error {
+	if tmax < 0 {
+		return fmt.Errorf("request.tmax must be nonnegative. Got %d", tmax)
+	}
+	return nil
+}
+
+// ValidateInventoryType checks the request doesn't define more than one of site, app or dooh. One of them is
+// required unless the request may leave them out, as stored requests do for the incoming request to fill them.
+func ValidateInventoryType(req *openrtb_ext.RequestWrapper, required bool) error {
+	invTypeNumMatches := 0
+	if req.Site != nil {
+		invTypeNumMatches++
+	}
+	if req.App != nil {
+		invTypeNumMatches++
+	}
+	if req.DOOH != nil {
+		invTypeNumMatches++
+	}
+
+	if invTypeNumMatches == 0 && required {
+		return errors.New("One of request.site or request.app or request.dooh must be defined")
+	} else if invTypeNumMatches >= 2 {
+		return errors.New("No more than one of request.site or request.app or request.dooh can be defined")
+	}
+	return nil
+}
+
+func (srv *standardRequestValidator) ValidateAliases(aliases map[string]string) error {
+	for alias, bidderName := range aliases {
+		normalisedBidderName, _ := openrtb_ext.NormalizeBidderName(bidderName)
+		coreBidderName := normalisedBidderName.String()
+		if _, isCoreBidderDisabled := srv.disabledBidders[coreBidderName]; isCoreBidderDisabled {
+			return fmt.Errorf("request.ext.prebid.aliases.%s refers to disabled bidder: %s", alias, bidderName)
+		}
+
+		if _, isCoreBidder := srv.bidderMap[coreBidderName]; !isCoreBidder {
+			return fmt.Errorf("request.ext.prebid.aliases.%s refers to unknown bidder: %s", alias, bidderName)
+		}
+
+		if alias == coreBidderName {
+			return fmt.Errorf("request.ext.prebid.aliases.%s defines a no-op alias. Choose a different alias, or remove this entry.", alias)
+		}
+		aliases[alias] = coreBidderName
+	}
+	return nil
+}
+
+func (srv *standardRequestValidator) ValidateBidAdjustmentFactors(adjustmentFactors map[string]float64, aliases map[string]string) error {
+	uniqueBidders := make(map[string]struct{})
+	for bidderToAdjust, adjustmentFactor := range adjustmentFactors {
+		if adjustmentFactor <= 0 {
+			return fmt.Errorf("request.ext.prebid.bidadjustmentfactors.%s must be a positive number. Got %f", bidderToAdjust, adjustmentFactor)
+		}
+
+		bidderName := bidderToAdjust
+		normalizedCoreBidder, ok := openrtb_ext.NormalizeBidderName(bidderToAdjust)
+		if ok {
+			bidderName = normalizedCoreBidder.String()
+		}
+
+		if _, exists := uniqueBidders[bidderName]; exists {
+			return fmt.Errorf("cannot have multiple bidders that differ only in case style")
+		} else {
+			uniqueBidders[bidderName] = struct{}{}
+		}
+
+		if _, isBidder := srv.bidderMap[bidderName]; !isBidder {
+			if _, isAlias := aliases[bidderToAdjust]; !isAlias {
+				return fmt.Errorf("request.ext.prebid.bidadjustmentfactors.%s is not a known bidder or alias", bidderToAdjust)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+)
+
+const (
+	localShards = 16
+	// localSweepInterval is how often a shard drops the buckets which have been idle long enough to be full again.
+	localSweepInterval = time.Minute
+)
+
+// LocalStore keeps the token buckets in memory, so each PBS instance enforces the limits on its own.
+type LocalStore struct {
+	time   timeutil.Time
+	shards [localShards]localShard
+}
+
+type localShard struct {
+	mutex   sync.Mutex
+	buckets map[string]*localBucket
+	swept   time.Time
+}
+
+type localBucket struct {
+	bucket
+	// full is when the bucket will be full again if no token is taken.
+	full time.Time
+}
+
+// NewLocalStore returns a Store keeping the token buckets in memory.
+func NewLocalStore(timeSource timeutil.Time) *LocalStore {
+	store := &LocalStore{time: timeSource}
+	now := timeSource.Now()
+	for i := range store.shards {
+		store.shards[i].buckets = make(map[string]*localBucket)
+		store.shards[i].swept = now
+	}
+	return store
+}
+
+func (s *LocalStore) Take(ctx context.Context, key string, rate float64, burst int) (bool, error) {
+	now := s.time.Now()
+	shard := s.shard(key)
+
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	if now.Sub(shard.swept) >= localSweepInterval {
+		shard.sweep(now)
+	}
+
+	b, ok := shard.buckets[key]
+	if !ok {
+		b = &localBucket{bucket: bucket{tokens: float64(burst), updated: now}}
+		shard.buckets[key] = b
+	}
+	allowed := b.take(now, rate, burst)
+	b.full = now.Add(time.Duration((float64(burst) - b.tokens) / rate * float64(time.Second)))
+	return allowed, nil
+}
+
+func (s *LocalStore) shard(key string) *localShard {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return &s.shards[hash.Sum32()%localShards]
+}
+
+// sweep drops the buckets which are full, since a new bucket starts full anyway.
+func (shard *localShard) sweep(now time.Time) {
+	for key, b := range shard.buckets {
+		if !now.Before(b.full) {
+			delete(shard.buckets, key)
+		}
+	}
+	shard.swept = now
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+)
+
+// Store keeps the token buckets of the rate limits.
+type Store interface {
+	// Take takes a token from the bucket of the key, holding up to burst tokens and refilled at rate tokens
+	// per second. It returns false when the bucket is empty.
+	Take(ctx context.Context, key string, rate float64, burst int) (bool, error)
+}
+
+// RateLimiter enforces the rate limits of the accounts.
+//
+// A nil *RateLimiter allows every request, so the endpoints don't need to check if rate limiting is enabled.
+type RateLimiter struct {
+	store         Store
+	metricsEngine metrics.MetricsEngine
+}
+
+// NewRateLimiter returns the RateLimiter of the host config, or nil when rate limiting is disabled.
+func NewRateLimiter(cfg config.RateLimiting, metricsEngine metrics.MetricsEngine) *RateLimiter {
+	if !cfg.Enabled {
+		return nil
+	}
+	var store Store
+	if cfg.Backend == "redis" {
+		store = NewRedisStore(cfg.Redis, &timeutil.RealTime{})
+	} else {
+		store = NewLocalStore(&timeutil.RealTime{})
+	}
+	return &RateLimiter{store: store, metricsEngine: metricsEngine}
+}
+
+// Limit takes a token from the bucket of the request and returns a *errortypes.RateLimited error if the
+// request is over the rate limits of its account on the endpoint.
+//
+// The request is allowed when the store fails, so an outage of a shared backend doesn't stop the auctions.
+func (l *RateLimiter) Limit(ctx context.Context, account *config.Account, endpoint string, rType metrics.RequestType, req *openrtb2.BidRequest) error {
+	if l == nil || account == nil || !account.RateLimits.Enabled {
+		return nil
+	}
+	limit := account.RateLimits.Limit(endpoint)
+	if limit.RequestsPerSecond <= 0 {
+		return nil
+	}
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(limit.RequestsPerSecond))
+	}
+
+	allowed, err := l.take(ctx, account, endpoint, req, limit.RequestsPerSecond, burst)
+	if err != nil {
+		l.metricsEngine.RecordRateLimit(metrics.RateLimitLabels{RType: rType, PubID: account.ID, Result: metrics.RateLimitError})
+		return nil
+	}
+	if allowed {
+		return nil
+	}
+
+	l.metricsEngine.RecordRateLimit(metrics.RateLimitLabels{RType: rType, PubID: account.ID, Result: metrics.RateLimitLimited})
+	return &errortypes.RateLimited{
+		Message: fmt.Sprintf("Request rate of account %s on %s is over %g requests per second", account.ID, endpoint, limit.RequestsPerSecond),
+		NoBid:   account.RateLimits.Action == config.RateLimitActionNoBid,
+	}
+}
+
+// take takes a token from the bucket of the account and endpoint. With the "property" scope, the requests
+// with a property also take a token from the bucket of their property. The properties come from the request,
+// so the account bucket, holding max_properties times the limit, stops the requests with a new property each
+// time from bypassing the limits, and hashing the properties to max_properties buckets bounds the buckets kept.
+func (l *RateLimiter) take(ctx context.Context, account *config.Account, endpoint string, req *openrtb2.BidRequest, rate float64, burst int) (bool, error) {
+	accountKey := account.ID + "|" + endpoint
+	property := property(account, req)
+	if property == "" {
+		return l.store.Take(ctx, accountKey, rate, burst)
+	}
+
+	maxProperties := account.RateLimits.MaxProperties
+	if maxProperties <= 0 {
+		maxProperties = config.RateLimitDefaultMaxProperties
+	}
+	allowed, err := l.store.Take(ctx, accountKey, rate*float64(maxProperties), burst*maxProperties)
+	if err != nil || !allowed {
+		return allowed, err
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(property))
+	return l.store.Take(ctx, accountKey+"|property:"+strconv.FormatUint(uint64(hash.Sum32()%uint32(maxProperties)), 10), rate, burst)
+}
+
+// property returns the app bundle or site domain of the request with the "property" scope.
+func property(account *config.Account, req *openrtb2.BidRequest) string {
+	if account.RateLimits.Scope != config.RateLimitScopeProperty || req == nil {
+		return ""
+	}
+	if req.App != nil && req.App.Bundle != "" {
+		return "app:" + req.App.Bundle
+	}
+	if req.Site != nil && req.Site.Domain != "" {
+		return "site:" + req.Site.Domain
+	}
+	return ""
+}
+
+// bucket is a token bucket holding up to burst tokens, refilled at rate tokens per second.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills the bucket for the time since its last update and takes a token if it has one.
+func (b *bucket) take(now time.Time, rate float64, burst int) bool {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+		b.updated = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,288 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeTime struct {
+	time time.Time
+}
+
+func (ft *fakeTime) Now() time.Time {
+	return ft.time
+}
+
+type fakeStore struct {
+	allowed bool
+	err     error
+	keys    []string
+	bursts  []int
+}
+
+func (s *fakeStore) Take(ctx context.Context, key string, rate float64, burst int) (bool, error) {
+	s.keys = append(s.keys, key)
+	s.bursts = append(s.bursts, burst)
+	return s.allowed, s.err
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	assert.Nil(t, NewRateLimiter(config.RateLimiting{Enabled: false}, &metrics.MetricsEngineMock{}))
+
+	local := NewRateLimiter(config.RateLimiting{Enabled: true, Backend: "local"}, &metrics.MetricsEngineMock{})
+	assert.IsType(t, &LocalStore{}, local.store)
+
+	redis := NewRateLimiter(config.RateLimiting{Enabled: true, Backend: "redis", Redis: config.RateLimitingRedis{Mode: config.RateLimitRedisStandalone, Addresses: []string{"localhost:6379"}, Timeout: 20, PoolSize: 1}}, &metrics.MetricsEngineMock{})
+	assert.IsType(t, &RedisStore{}, redis.store)
+}
+
+func TestNilRateLimiter(t *testing.T) {
+	var limiter *RateLimiter
+	account := &config.Account{ID: "acct", RateLimits: config.AccountRateLimits{Enabled: true, Default: config.AccountRateLimit{RequestsPerSecond: 1}}}
+
+	assert.NoError(t, limiter.Limit(context.Background(), account, config.RateLimitEndpointAuction, metrics.ReqTypeORTB2Web, nil))
+}
+
+func TestLimit(t *testing.T) {
+	limits := config.AccountRateLimits{
+		Enabled: true,
+		Default: config.AccountRateLimit{RequestsPerSecond: 2.5},
+		Endpoints: map[string]config.AccountRateLimit{
+			config.RateLimitEndpointAMP:   {RequestsPerSecond: 10, Burst: 20},
+			config.RateLimitEndpointVideo: {RequestsPerSecond: 0},
+		},
+	}
+	propertyLimits := limits
+	propertyLimits.Scope = config.RateLimitScopeProperty
+	maxPropertiesLimits := propertyLimits
+	maxPropertiesLimits.MaxProperties = 2
+	noBidLimits := limits
+	noBidLimits.Action = config.RateLimitActionNoBid
+	disabledLimits := limits
+	disabledLimits.Enabled = false
+
+	app := &openrtb2.BidRequest{App: &openrtb2.App{Bundle: "com.app"}, Site: &openrtb2.Site{Domain: "site.com"}}
+	site := &openrtb2.BidRequest{Site: &openrtb2.Site{Domain: "site.com"}}
+
+	testCases := []struct {
+		description    string
+		limits         config.AccountRateLimits
+		endpoint       string
+		req            *openrtb2.BidRequest
+		store          fakeStore
+		expectedKeys   []string
+		expectedBursts []int
+		expectedResult metrics.RateLimitResult
+		expectedErr    error
+	}{
+		{
+			description: "disabled",
+			limits:      disabledLimits,
+			endpoint:    config.RateLimitEndpointAuction,
+		},
+		{
+			description: "unlimited_endpoint",
+			limits:      limits,
+			endpoint:    config.RateLimitEndpointVideo,
+		},
+		{
+			description:    "allowed_default_limit",
+			limits:         limits,
+			endpoint:       config.RateLimitEndpointAuction,
+			req:            app,
+			store:          fakeStore{allowed: true},
+			expectedKeys:   []string{"acct|auction"},
+			expectedBursts: []int{3},
+		},
+		{
+			description:    "allowed_endpoint_limit",
+			limits:         limits,
+			endpoint:       config.RateLimitEndpointAMP,
+			store:          fakeStore{allowed: true},
+			expectedKeys:   []string{"acct|amp"},
+			expectedBursts: []int{20},
+		},
+		{
+			description:    "property_scope_app",
+			limits:         propertyLimits,
+			endpoint:       config.RateLimitEndpointAuction,
+			req:            app,
+			store:          fakeStore{allowed: true},
+			expectedKeys:   []string{"acct|auction", "acct|auction|property:6"},
+			expectedBursts: []int{300, 3},
+		},
+		{
+			description:    "property_scope_site",
+			limits:         propertyLimits,
+			endpoint:       config.RateLimitEndpointAuction,
+			req:            site,
+			store:          fakeStore{allowed: true},
+			expectedKeys:   []string{"acct|auction", "acct|auction|property:88"},
+			expectedBursts: []int{300, 3},
+		},
+		{
+			description:    "property_scope_max_properties",
+			limits:         maxPropertiesLimits,
+			endpoint:       config.RateLimitEndpointAMP,
+			req:            site,
+			store:          fakeStore{allowed: true},
+			expectedKeys:   []string{"acct|amp", "acct|amp|property:0"},
+			expectedBursts: []int{40, 20},
+		},
+		{
+			description:    "property_scope_account_limited",
+			limits:         propertyLimits,
+			endpoint:       config.RateLimitEndpointAuction,
+			req:            site,
+			store:          fakeStore{allowed: false},
+			expectedKeys:   []string{"acct|auction"},
+			expectedBursts: []int{300},
+			expectedResult: metrics.RateLimitLimited,
+			expectedErr:    &errortypes.RateLimited{Message: "Request rate of account acct on auction is over 2.5 requests per second"},
+		},
+		{
+			description:    "property_scope_without_property",
+			limits:         propertyLimits,
+			endpoint:       config.RateLimitEndpointAuction,
+			req:            &openrtb2.BidRequest{},
+			store:          fakeStore{allowed: true},
+			expectedKeys:   []string{"acct|auction"},
+			expectedBursts: []int{3},
+		},
+		{
+			description:    "limited_reject",
+			limits:         limits,
+			endpoint:       config.RateLimitEndpointAuction,
+			store:          fakeStore{allowed: false},
+			expectedKeys:   []string{"acct|auction"},
+			expectedBursts: []int{3},
+			expectedResult: metrics.RateLimitLimited,
+			expectedErr:    &errortypes.RateLimited{Message: "Request rate of account acct on auction is over 2.5 requests per second"},
+		},
+		{
+			description:    "limited_no_bid",
+			limits:         noBidLimits,
+			endpoint:       config.RateLimitEndpointAuction,
+			store:          fakeStore{allowed: false},
+			expectedKeys:   []string{"acct|auction"},
+			expectedBursts: []int{3},
+			expectedResult: metrics.RateLimitLimited,
+			expectedErr:    &errortypes.RateLimited{Message: "Request rate of account acct on auction is over 2.5 requests per second", NoBid: true},
+		},
+		{
+			description:    "store_error_allows",
+			limits:         limits,
+			endpoint:       config.RateLimitEndpointAuction,
+			store:          fakeStore{err: errors.New("connection refused")},
+			expectedKeys:   []string{"acct|auction"},
+			expectedBursts: []int{3},
+			expectedResult: metrics.RateLimitError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			metricsEngine := &metrics.MetricsEngineMock{}
+			if tc.expectedResult != "" {
+				metricsEngine.On("RecordRateLimit", metrics.RateLimitLabels{RType: metrics.ReqTypeORTB2Web, PubID: "acct", Result: tc.expectedResult}).Once()
+			}
+			limiter := &RateLimiter{store: &tc.store, metricsEngine: metricsEngine}
+			account := &config.Account{ID: "acct", RateLimits: tc.limits}
+
+			err := limiter.Limit(context.Background(), account, tc.endpoint, metrics.ReqTypeORTB2Web, tc.req)
+
+			assert.Equal(t, tc.expectedErr, err)
+			assert.Equal(t, tc.expectedKeys, tc.store.keys)
+			assert.Equal(t, tc.expectedBursts, tc.store.bursts)
+			metricsEngine.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	now := &fakeTime{time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewLocalStore(now)
+	ctx := context.Background()
+
+	take := func(key string) bool {
+		allowed, err := store.Take(ctx, key, 2, 3)
+		assert.NoError(t, err)
+		return allowed
+	}
+
+	// the bucket starts full
+	assert.True(t, take("a"))
+	assert.True(t, take("a"))
+	assert.True(t, take("a"))
+	assert.False(t, take("a"), "burst exhausted")
+	assert.True(t, take("b"), "buckets are per key")
+
+	// refilled at 2 tokens per second
+	now.time = now.time.Add(500 * time.Millisecond)
+	assert.True(t, take("a"))
+	assert.False(t, take("a"))
+
+	// never refilled above the burst
+	now.time = now.time.Add(time.Hour)
+	assert.True(t, take("a"))
+	assert.True(t, take("a"))
+	assert.True(t, take("a"))
+	assert.False(t, take("a"))
+}
+
+func TestLocalStoreSweep(t *testing.T) {
+	now := &fakeTime{time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewLocalStore(now)
+	ctx := context.Background()
+
+	store.Take(ctx, "idle", 1, 1)
+	store.Take(ctx, "busy", 0.001, 1)
+
+	now.time = now.time.Add(localSweepInterval)
+	for i := range store.shards {
+		store.shards[i].sweep(now.time)
+	}
+
+	assert.NotContains(t, store.shard("idle").buckets, "idle", "full again after a second")
+	assert.Contains(t, store.shard("busy").buckets, "busy", "full again after 1000 seconds")
+	allowed, _ := store.Take(ctx, "busy", 0.001, 1)
+	assert.False(t, allowed)
+}
+
+func TestLimitPropertiesShareAccountBucket(t *testing.T) {
+	limiter := &RateLimiter{store: NewLocalStore(&fakeTime{time: time.Now()}), metricsEngine: &metrics.MetricsEngineMock{}}
+	limiter.metricsEngine.(*metrics.MetricsEngineMock).On("RecordRateLimit", mock.Anything)
+	account := &config.Account{ID: "acct", RateLimits: config.AccountRateLimits{
+		Enabled:       true,
+		Default:       config.AccountRateLimit{RequestsPerSecond: 1},
+		Scope:         config.RateLimitScopeProperty,
+		MaxProperties: 10,
+	}}
+
+	// each request has a new domain, so only the account bucket limits them
+	limited := 0
+	for i := 0; i < 20; i++ {
+		req := &openrtb2.BidRequest{Site: &openrtb2.Site{Domain: fmt.Sprintf("site%d.com", i)}}
+		if limiter.Limit(context.Background(), account, config.RateLimitEndpointAuction, metrics.ReqTypeORTB2Web, req) != nil {
+			limited++
+		}
+	}
+
+	assert.GreaterOrEqual(t, limited, 10)
+	buckets := 0
+	shards := &limiter.store.(*LocalStore).shards
+	for i := range shards {
+		buckets += len(shards[i].buckets)
+	}
+	assert.LessOrEqual(t, buckets, 11, "the account bucket and at most max_properties property buckets")
+}
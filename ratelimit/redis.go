@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript takes a token from the bucket stored in the hash of KEYS[1], refilled at ARGV[1] tokens per second
+// and holding up to ARGV[2] tokens, at the time ARGV[3] in milliseconds. The time is passed by PBS rather than read
+// with TIME, so the script only depends on its arguments and can be replicated as is. A bucket updated by an instance
+// with a clock ahead isn't refilled until the other clocks catch up, so the instances need clocks synced with NTP.
+// The bucket expires once it would be full again, since a new bucket starts full anyway.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3]) / 1000
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1])
+local updated = tonumber(state[2])
+if tokens == nil or updated == nil then
+  tokens = burst
+  updated = now
+end
+if now > updated then
+  tokens = math.min(burst, tokens + (now - updated) * rate)
+  updated = now
+end
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(updated))
+redis.call('EXPIRE', KEYS[1], math.ceil((burst - tokens) / rate) + 1)
+return allowed
+`)
+
+// RedisStore keeps the token buckets in Redis, so all the PBS instances using it share the limits.
+type RedisStore struct {
+	client    redis.UniversalClient
+	time      timeutil.Time
+	timeout   time.Duration
+	keyPrefix string
+}
+
+// NewRedisStore returns a Store keeping the token buckets in the Redis servers of the config.
+// The connections are opened on demand.
+func NewRedisStore(cfg config.RateLimitingRedis, timeSource timeutil.Time) *RedisStore {
+	return &RedisStore{
+		client:    newRedisClient(cfg),
+		time:      timeSource,
+		timeout:   time.Duration(cfg.Timeout) * time.Millisecond,
+		keyPrefix: cfg.KeyPrefix,
+	}
+}
+
+func newRedisClient(cfg config.RateLimitingRedis) redis.UniversalClient {
+	timeout := time.Duration(cfg.Timeout) * time.Millisecond
+	var tlsConfig *tls.Config
+	if cfg.TLS {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	switch cfg.Mode {
+	case config.RateLimitRedisCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:                 cfg.Addresses,
+			Username:              cfg.Username,
+			Password:              cfg.Password,
+			TLSConfig:             tlsConfig,
+			DialTimeout:           timeout,
+			ReadTimeout:           timeout,
+			WriteTimeout:          timeout,
+			ContextTimeoutEnabled: true,
+			PoolSize:              cfg.PoolSize,
+		})
+	case config.RateLimitRedisSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:            cfg.MasterName,
+			SentinelAddrs:         cfg.Addresses,
+			Username:              cfg.Username,
+			Password:              cfg.Password,
+			DB:                    cfg.DB,
+			TLSConfig:             tlsConfig,
+			DialTimeout:           timeout,
+			ReadTimeout:           timeout,
+			WriteTimeout:          timeout,
+			ContextTimeoutEnabled: true,
+			PoolSize:              cfg.PoolSize,
+		})
+	}
+
+	var address string
+	if len(cfg.Addresses) > 0 {
+		address = cfg.Addresses[0]
+	}
+	return redis.NewClient(&redis.Options{
+		Addr:                  address,
+		Username:              cfg.Username,
+		Password:              cfg.Password,
+		DB:                    cfg.DB,
+		TLSConfig:             tlsConfig,
+		DialTimeout:           timeout,
+		ReadTimeout:           timeout,
+		WriteTimeout:          timeout,
+		ContextTimeoutEnabled: true,
+		PoolSize:              cfg.PoolSize,
+	})
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, rate float64, burst int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	now := s.time.Now().UnixMilli()
+	allowed, err := takeScript.Run(ctx, s.client, []string{s.keyPrefix + key}, strconv.FormatFloat(rate, 'g', -1, 64), burst, now).Int64()
+	if err != nil {
+		return false, fmt.Errorf("redis: %v", err)
+	}
+	return allowed == 1, nil
+}
+
+// Close closes the connections to Redis.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,150 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireUserAuth("pbs", "secret")
+	clock := &fakeTime{time: time.Unix(1700000000, 0)}
+	store := NewRedisStore(config.RateLimitingRedis{
+		Mode:      config.RateLimitRedisStandalone,
+		Addresses: []string{server.Addr()},
+		Username:  "pbs",
+		Password:  "secret",
+		DB:        2,
+		Timeout:   1000,
+		PoolSize:  1,
+		KeyPrefix: "pbs:",
+	}, clock)
+	defer store.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		allowed, err := store.Take(ctx, "acct|auction", 1, 2)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err := store.Take(ctx, "acct|auction", 1, 2)
+	assert.NoError(t, err)
+	assert.False(t, allowed, "the bucket is empty")
+
+	assert.True(t, server.DB(2).Exists("pbs:acct|auction"))
+	assert.Equal(t, 3*time.Second, server.DB(2).TTL("pbs:acct|auction"), "the bucket expires once full again")
+
+	clock.time = clock.time.Add(time.Second)
+	allowed, err = store.Take(ctx, "acct|auction", 1, 2)
+	assert.NoError(t, err)
+	assert.True(t, allowed, "the bucket is refilled with the time passed by PBS")
+}
+
+func TestRedisStoreErrors(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+
+	testCases := []struct {
+		description string
+		cfg         config.RateLimitingRedis
+		expectedErr string
+	}{
+		{
+			description: "wrong_password",
+			cfg:         config.RateLimitingRedis{Addresses: []string{server.Addr()}, Password: "wrong", Timeout: 1000, PoolSize: 1},
+			expectedErr: "WRONGPASS",
+		},
+		{
+			description: "connection_refused",
+			cfg:         config.RateLimitingRedis{Addresses: []string{closedAddress(t)}, Timeout: 1000, PoolSize: 1},
+			expectedErr: "connection refused",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			store := NewRedisStore(tc.cfg, &fakeTime{time: time.Now()})
+			defer store.Close()
+
+			allowed, err := store.Take(context.Background(), "acct|auction", 1, 1)
+			assert.False(t, allowed)
+			assert.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestRedisStoreTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	// accept the connections but never reply
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+
+	store := NewRedisStore(config.RateLimitingRedis{Addresses: []string{listener.Addr().String()}, Timeout: 50, PoolSize: 1}, &fakeTime{time: time.Now()})
+	defer store.Close()
+	start := time.Now()
+	_, err = store.Take(context.Background(), "acct|auction", 1, 1)
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestNewRedisClient(t *testing.T) {
+	testCases := []struct {
+		description  string
+		cfg          config.RateLimitingRedis
+		expectedType redis.UniversalClient
+	}{
+		{
+			description:  "standalone",
+			cfg:          config.RateLimitingRedis{Mode: config.RateLimitRedisStandalone, Addresses: []string{"localhost:6379"}},
+			expectedType: &redis.Client{},
+		},
+		{
+			description:  "cluster",
+			cfg:          config.RateLimitingRedis{Mode: config.RateLimitRedisCluster, Addresses: []string{"node1:6379", "node2:6379"}},
+			expectedType: &redis.ClusterClient{},
+		},
+		{
+			description:  "sentinel",
+			cfg:          config.RateLimitingRedis{Mode: config.RateLimitRedisSentinel, Addresses: []string{"sentinel:26379"}, MasterName: "ratelimit", TLS: true},
+			expectedType: &redis.Client{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			client := newRedisClient(tc.cfg)
+			defer client.Close()
+			assert.IsType(t, tc.expectedType, client)
+		})
+	}
+}
+
+func closedAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+	return address
+}
@@ -5,12 +5,16 @@ import (
 	"net/http/pprof"
 	"time"
 
+	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/currency"
 	"github.com/prebid/prebid-server/v3/endpoints"
+	"github.com/prebid/prebid-server/v3/hostconfig"
+	"github.com/prebid/prebid-server/v3/metrics/dashboard"
+	"github.com/prebid/prebid-server/v3/modules"
 	"github.com/prebid/prebid-server/v3/version"
 )
 
-func Admin(rateConverter *currency.RateConverter, rateConverterFetchingInterval time.Duration) *http.ServeMux {
+func Admin(cfg *config.Configuration, rateConverter *currency.RateConverter, rateConverterFetchingInterval time.Duration, moduleLifecycle *modules.Lifecycle, reloadModules func() error, hostConfig *hostconfig.Store, dashboardRecorder *dashboard.Recorder) *http.ServeMux {
 	// Add endpoints to the admin server
 	// Making sure to add pprof routes
 	mux := http.NewServeMux()
@@ -23,5 +27,17 @@ func Admin(rateConverter *currency.RateConverter, rateConverterFetchingInterval
 	// Register prebid-server defined admin handlers
 	mux.HandleFunc("/currency/rates", endpoints.NewCurrencyRatesEndpoint(rateConverter, rateConverterFetchingInterval))
 	mux.HandleFunc("/version", endpoints.NewVersionEndpoint(version.Ver, version.Rev))
+	mux.HandleFunc("/modules/health", endpoints.NewModulesHealthEndpoint(moduleLifecycle))
+	mux.HandleFunc("/modules/reload", endpoints.NewModulesReloadEndpoint(reloadModules, cfg.Admin.ConfigAPI.Tokens))
+	if hostConfig != nil {
+		tokens := cfg.Admin.ConfigAPI.Tokens
+		biddersEndpoint := endpoints.NewAdminBiddersEndpoint(hostConfig, tokens)
+		mux.HandleFunc("/admin/config", endpoints.NewAdminConfigEndpoint(cfg, hostConfig, tokens))
+		mux.HandleFunc("/admin/bidders", biddersEndpoint)
+		mux.HandleFunc("/admin/bidders/", biddersEndpoint)
+	}
+	if dashboardRecorder != nil {
+		mux.HandleFunc("/dashboard", endpoints.NewDashboardEndpoint(dashboardRecorder, cfg.Admin.ConfigAPI.Tokens))
+	}
 	return mux
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	openrtb2model "github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/account"
 	analyticsBuild "github.com/prebid/prebid-server/v3/analytics/build"
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/currency"
@@ -23,21 +25,27 @@ import (
 	"github.com/prebid/prebid-server/v3/experiment/adscert"
 	"github.com/prebid/prebid-server/v3/floors"
 	"github.com/prebid/prebid-server/v3/gdpr"
+	"github.com/prebid/prebid-server/v3/health"
 	"github.com/prebid/prebid-server/v3/hooks"
+	"github.com/prebid/prebid-server/v3/hostconfig"
 	"github.com/prebid/prebid-server/v3/macros"
 	"github.com/prebid/prebid-server/v3/metrics"
 	metricsConf "github.com/prebid/prebid-server/v3/metrics/config"
+	"github.com/prebid/prebid-server/v3/mirror"
 	"github.com/prebid/prebid-server/v3/modules"
 	"github.com/prebid/prebid-server/v3/modules/moduledeps"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/ortb"
 	"github.com/prebid/prebid-server/v3/pbs"
 	pbc "github.com/prebid/prebid-server/v3/prebid_cache_client"
+	"github.com/prebid/prebid-server/v3/ratelimit"
 	"github.com/prebid/prebid-server/v3/router/aspects"
 	"github.com/prebid/prebid-server/v3/server/ssl"
 	storedRequestsConf "github.com/prebid/prebid-server/v3/stored_requests/config"
 	"github.com/prebid/prebid-server/v3/usersync"
+	"github.com/prebid/prebid-server/v3/util/iputil"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
 	"github.com/prebid/prebid-server/v3/util/uuidutil"
 	"github.com/prebid/prebid-server/v3/version"
 
@@ -45,6 +53,8 @@ import (
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	gometrics "github.com/rcrowley/go-metrics"
 	"github.com/rs/cors"
 )
 
@@ -116,15 +126,31 @@ type Router struct {
 	*httprouter.Router
 	MetricsEngine   *metricsConf.DetailedMetricsEngine
 	ParamsValidator openrtb_ext.BidderParamValidator
+	ModuleLifecycle *modules.Lifecycle
+	ModuleConfigs   *hooks.ModuleConfigs
+	// HostConfig holds the bidder changes made at runtime through the admin server. It's nil unless
+	// admin.config_api.enabled is true.
+	HostConfig *hostconfig.Store
+	// Health tells if PBS is ready to serve requests. It's drained by server.Listen on shutdown.
+	Health *health.Checker
 
 	shutdowns []func()
 }
 
 func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *Router, err error) {
 	const schemaDirectory = "./static/bidder-params"
+	const moduleSchemaDirectory = "./static/module-schemas"
 
 	r = &Router{
 		Router: httprouter.New(),
+		Health: health.NewChecker(),
+	}
+
+	// the rates are only fetched again if an interval is set, so they can't be waited for otherwise
+	if cfg.CurrencyConverter.FetchIntervalSeconds > 0 {
+		r.Health.Register("currency_rates", health.Loaded("currency rates", func() bool {
+			return !rateConvertor.LastUpdated().IsZero()
+		}))
 	}
 
 	// For bid processing, we need both the hardcoded certificates and the certificates found in container's
@@ -185,20 +211,39 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		syncerKeys = append(syncerKeys, k)
 	}
 
-	moduleDeps := moduledeps.ModuleDeps{HTTPClient: generalHttpClient, RateConvertor: rateConvertor}
-	repo, moduleStageNames, err := modules.NewBuilder().Build(cfg.Hooks.Modules, moduleDeps)
+	moduleMetrics := moduledeps.NewMetricsRegistry()
+	moduleStoredData := &moduledeps.StoredData{}
+	moduleDeps := moduledeps.ModuleDeps{
+		HTTPClient:           generalHttpClient,
+		RateConvertor:        rateConvertor,
+		Metrics:              moduleMetrics,
+		Cache:                moduledeps.NewCache(cfg.Hooks.ModuleCache.SizeBytes),
+		Logger:               moduledeps.NewLogger(),
+		StoredRequestFetcher: moduleStoredData,
+		AccountFetcher:       moduleStoredData,
+		GeoLookup:            moduledeps.NewGeoLookup(),
+		DeviceLookup: moduledeps.NewDeviceLookup(iputil.PublicNetworkIPValidator{
+			IPv4PrivateNetworks: cfg.RequestValidation.IPv4PrivateNetworksParsed,
+			IPv6PrivateNetworks: cfg.RequestValidation.IPv6PrivateNetworksParsed,
+		}),
+	}
+	moduleConfigs, err := hooks.NewModuleConfigs(moduleSchemaDirectory, cfg.Hooks.Modules)
+	if err != nil {
+		glog.Fatalf("Failed to validate hook modules config: %v", err)
+	}
+	r.ModuleConfigs = moduleConfigs
+
+	repo, moduleStageNames, moduleLifecycle, err := modules.NewBuilder().Build(cfg.Hooks.Modules, moduleDeps)
 	if err != nil {
 		glog.Fatalf("Failed to init hook modules: %v", err)
 	}
+	r.ModuleLifecycle = moduleLifecycle
 
 	// Metrics engine
 	r.MetricsEngine = metricsConf.NewMetricsEngine(cfg, openrtb_ext.CoreBidderNames(), syncerKeys, moduleStageNames)
-	shutdown, fetcher, ampFetcher, accounts, categoriesFetcher, videoFetcher, storedRespFetcher := storedRequestsConf.NewStoredRequests(cfg, r.MetricsEngine, generalHttpClient, r.Router)
-
-	analyticsRunner := analyticsBuild.New(&cfg.Analytics)
-
-	// register the analytics runner for shutdown
-	r.shutdowns = append(r.shutdowns, shutdown, analyticsRunner.Shutdown)
+	if err := bindModuleMetrics(moduleMetrics, r.MetricsEngine, cfg.Metrics.Prometheus); err != nil {
+		glog.Fatalf("Failed to register hook module metrics: %v", err)
+	}
 
 	paramsValidator, err := openrtb_ext.NewBidderParamsValidator(schemaDirectory)
 	if err != nil {
@@ -207,11 +252,27 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 
 	activeBidders := exchange.GetActiveBidders(cfg.BidderInfos)
 	disabledBidders := exchange.GetDisabledBidderWarningMessages(cfg.BidderInfos)
+	requestValidator := ortb.NewRequestValidator(activeBidders, disabledBidders, paramsValidator)
+
+	shutdown, fetcher, ampFetcher, accounts, categoriesFetcher, videoFetcher, storedRespFetcher := storedRequestsConf.NewStoredRequests(cfg, r.MetricsEngine, generalHttpClient, r.Router, requestValidator, r.Health)
+	accounts = account.WithModuleConfigResolver(accounts, moduleConfigs)
+	moduleStoredData.Set(fetcher, accounts)
+
+	analyticsRunner := analyticsBuild.New(&cfg.Analytics)
+
+	// register the analytics runner and hook modules for shutdown
+	r.shutdowns = append(r.shutdowns, shutdown, analyticsRunner.Shutdown, moduleLifecycle.Shutdown)
 
 	defReqJSON := readDefaultRequest(cfg.DefReqConfig)
 
 	gvlVendorIDs := cfg.BidderInfos.ToGVLVendorIDMap()
-	vendorListFetcher := gdpr.NewVendorListFetcher(context.Background(), cfg.GDPR, generalHttpClient, gdpr.VendorListURLMaker)
+	// the preload retries run until the vendor lists are loaded, so they are stopped on shutdown
+	vendorListCtx, stopVendorListRetries := context.WithCancel(context.Background())
+	r.shutdowns = append(r.shutdowns, stopVendorListRetries)
+	vendorListFetcher, vendorListsLoaded := gdpr.NewVendorListFetcher(vendorListCtx, cfg.GDPR, generalHttpClient, gdpr.VendorListURLMaker)
+	if cfg.GDPR.Enabled {
+		r.Health.Register("gvl", health.Loaded("GDPR vendor lists", vendorListsLoaded))
+	}
 	gdprPermsBuilder := gdpr.NewPermissionsBuilder(cfg.GDPR, gvlVendorIDs, vendorListFetcher)
 	tcf2CfgBuilder := gdpr.NewTCF2Config
 
@@ -227,25 +288,57 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		glog.Fatalf("Failed to create ads cert signer: %v", err)
 	}
 
-	requestValidator := ortb.NewRequestValidator(activeBidders, disabledBidders, paramsValidator)
+	eventSigner, err := events.NewURLSigner(cfg.Event.Signing)
+	if err != nil {
+		glog.Fatalf("Failed to create event URL signer: %v", err)
+	}
+
 	priceFloorFetcher := floors.NewPriceFloorFetcher(cfg.PriceFloors, floorFechterHttpClient, r.MetricsEngine)
+	// the floors are fetched per account on demand, so only the fetcher itself can be waited for
+	if priceFloorFetcher != nil {
+		r.Health.Register("price_floors", func() error {
+			if !priceFloorFetcher.Running() {
+				return errors.New("price floors fetcher not running")
+			}
+			return nil
+		})
+	}
 
 	tmaxAdjustments := exchange.ProcessTMaxAdjustments(cfg.TmaxAdjustments)
-	planBuilder := hooks.NewExecutionPlanBuilder(cfg.Hooks, repo)
+	planBuilder := hooks.NewExecutionPlanBuilder(cfg.Hooks, repo, moduleConfigs)
 	macroReplacer := macros.NewStringIndexBasedReplacer()
-	theExchange := exchange.NewExchange(adapters, cacheClient, cfg, requestValidator, syncersByBidder, r.MetricsEngine, cfg.BidderInfos, gdprPermsBuilder, rateConvertor, categoriesFetcher, adsCertSigner, macroReplacer, priceFloorFetcher)
+	theExchange := exchange.NewExchange(adapters, cacheClient, cfg, requestValidator, syncersByBidder, r.MetricsEngine, cfg.BidderInfos, gdprPermsBuilder, rateConvertor, categoriesFetcher, adsCertSigner, macroReplacer, priceFloorFetcher, eventSigner)
+	if cfg.Admin.ConfigAPI.Enabled {
+		auditLog, err := hostconfig.NewAuditLog(cfg.Admin.ConfigAPI.AuditLogPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the admin config api audit log: %v", err)
+		}
+		r.HostConfig = hostconfig.NewStore(cfg.BidderInfos, auditLog, &timeutil.RealTime{})
+		if err := exchange.SubscribeToHostConfig(theExchange, r.HostConfig, generalHttpClient, cfg, r.MetricsEngine); err != nil {
+			return nil, err
+		}
+	}
+	requestMirror, err := mirror.NewMirror(cfg.Mirroring, generalHttpClient, r.MetricsEngine)
+	if err != nil {
+		return nil, err
+	}
+	r.shutdowns = append(r.shutdowns, requestMirror.Shutdown)
+
 	var uuidGenerator uuidutil.UUIDRandomGenerator
-	openrtbEndpoint, err := openrtb2.NewEndpoint(uuidGenerator, theExchange, requestValidator, fetcher, accounts, cfg, r.MetricsEngine, analyticsRunner, disabledBidders, defReqJSON, activeBidders, storedRespFetcher, planBuilder, tmaxAdjustments)
+	// the endpoints share the limiter, so they share its Redis connections
+	rateLimiter := ratelimit.NewRateLimiter(cfg.RateLimiting, r.MetricsEngine)
+
+	openrtbEndpoint, err := openrtb2.NewEndpoint(uuidGenerator, theExchange, requestValidator, fetcher, accounts, cfg, r.MetricsEngine, analyticsRunner, disabledBidders, defReqJSON, activeBidders, storedRespFetcher, planBuilder, tmaxAdjustments, requestMirror, rateLimiter)
 	if err != nil {
 		glog.Fatalf("Failed to create the openrtb2 endpoint handler. %v", err)
 	}
 
-	ampEndpoint, err := openrtb2.NewAmpEndpoint(uuidGenerator, theExchange, requestValidator, ampFetcher, accounts, cfg, r.MetricsEngine, analyticsRunner, disabledBidders, defReqJSON, activeBidders, storedRespFetcher, planBuilder, tmaxAdjustments)
+	ampEndpoint, err := openrtb2.NewAmpEndpoint(uuidGenerator, theExchange, requestValidator, ampFetcher, accounts, cfg, r.MetricsEngine, analyticsRunner, disabledBidders, defReqJSON, activeBidders, storedRespFetcher, planBuilder, tmaxAdjustments, requestMirror, rateLimiter)
 	if err != nil {
 		glog.Fatalf("Failed to create the amp endpoint handler. %v", err)
 	}
 
-	videoEndpoint, err := openrtb2.NewVideoEndpoint(uuidGenerator, theExchange, requestValidator, fetcher, videoFetcher, accounts, cfg, r.MetricsEngine, analyticsRunner, disabledBidders, defReqJSON, activeBidders, cacheClient, tmaxAdjustments)
+	videoEndpoint, err := openrtb2.NewVideoEndpoint(uuidGenerator, theExchange, requestValidator, fetcher, videoFetcher, accounts, cfg, r.MetricsEngine, analyticsRunner, disabledBidders, defReqJSON, activeBidders, cacheClient, planBuilder, tmaxAdjustments, requestMirror, rateLimiter)
 	if err != nil {
 		glog.Fatalf("Failed to create the video endpoint handler. %v", err)
 	}
@@ -261,8 +354,11 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 	r.GET("/info/bidders", infoEndpoints.NewBiddersEndpoint(cfg.BidderInfos))
 	r.GET("/info/bidders/:bidderName", infoEndpoints.NewBiddersDetailEndpoint(cfg.BidderInfos))
 	r.GET("/bidders/params", NewJsonDirectoryServer(schemaDirectory, paramsValidator))
-	r.POST("/cookie_sync", endpoints.NewCookieSyncEndpoint(syncersByBidder, cfg, gdprPermsBuilder, tcf2CfgBuilder, r.MetricsEngine, analyticsRunner, accounts, activeBidders).Handle)
+	r.GET("/info/modules", infoEndpoints.NewModulesEndpoint(moduleStageNames, moduleConfigs))
+	r.POST("/cookie_sync", endpoints.NewCookieSyncEndpoint(syncersByBidder, cfg, gdprPermsBuilder, tcf2CfgBuilder, r.MetricsEngine, analyticsRunner, accounts, activeBidders, planBuilder).Handle)
 	r.GET("/status", endpoints.NewStatusEndpoint(cfg.StatusResponse))
+	r.GET("/status/live", endpoints.NewStatusEndpoint(cfg.StatusResponse))
+	r.GET("/status/ready", endpoints.NewReadyEndpoint(r.Health))
 	r.GET("/", serveIndex)
 	r.Handler("GET", "/version", endpoints.NewVersionEndpoint(version.Ver, version.Rev))
 	r.ServeFiles("/static/*filepath", http.Dir("static"))
@@ -274,7 +370,7 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 	}
 
 	// event endpoint
-	eventEndpoint := events.NewEventEndpoint(cfg, accounts, analyticsRunner, r.MetricsEngine)
+	eventEndpoint := events.NewEventEndpoint(cfg, accounts, analyticsRunner, r.MetricsEngine, eventSigner, planBuilder)
 	r.GET("/event", eventEndpoint)
 
 	userSyncDeps := &pbs.UserSyncDeps{
@@ -284,7 +380,7 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		PriorityGroups:   cfg.UserSync.PriorityGroups,
 	}
 
-	r.GET("/setuid", endpoints.NewSetUIDEndpoint(cfg, syncersByBidder, gdprPermsBuilder, tcf2CfgBuilder, analyticsRunner, accounts, r.MetricsEngine))
+	r.GET("/setuid", endpoints.NewSetUIDEndpoint(cfg, syncersByBidder, gdprPermsBuilder, tcf2CfgBuilder, analyticsRunner, accounts, r.MetricsEngine, planBuilder))
 	r.GET("/getuids", endpoints.NewGetUIDsEndpoint(cfg.HostCookie))
 	r.POST("/optout", userSyncDeps.OptOut)
 	r.GET("/optout", userSyncDeps.OptOut)
@@ -292,6 +388,21 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 	return r, nil
 }
 
+// bindModuleMetrics starts recording the metrics registered by hook modules to the enabled metrics backends
+func bindModuleMetrics(registry *moduledeps.MetricsRegistry, engine *metricsConf.DetailedMetricsEngine, promCfg config.PrometheusMetrics) error {
+	var goRegistry gometrics.Registry
+	if engine.GoMetrics != nil {
+		goRegistry = engine.GoMetrics.MetricsRegistry
+	}
+
+	var promRegistry prometheus.Registerer
+	if engine.PrometheusMetrics != nil {
+		promRegistry = engine.PrometheusMetrics.Registerer
+	}
+
+	return registry.Bind(goRegistry, promRegistry, promCfg)
+}
+
 // Shutdown closes any dependencies of the router that may need closing
 func (r *Router) Shutdown() {
 	glog.Info("[PBS Router] shutting down")
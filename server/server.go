@@ -14,12 +14,15 @@ import (
 	"github.com/NYTimes/gziphandler"
 	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/health"
 	"github.com/prebid/prebid-server/v3/metrics"
 	metricsconfig "github.com/prebid/prebid-server/v3/metrics/config"
 )
 
 // Listen blocks forever, serving PBS requests on the given port. This will block forever, until the process is shut down.
-func Listen(cfg *config.Configuration, handler http.Handler, adminHandler http.Handler, metrics *metricsconfig.DetailedMetricsEngine) (err error) {
+//
+// On shutdown, the health checker is drained for health.drain_seconds before the servers stop.
+func Listen(cfg *config.Configuration, handler http.Handler, adminHandler http.Handler, metrics *metricsconfig.DetailedMetricsEngine, healthChecker *health.Checker) (err error) {
 	stopSignals := make(chan os.Signal, 1)
 	signal.Notify(stopSignals, syscall.SIGTERM, syscall.SIGINT)
 
@@ -82,7 +85,8 @@ func Listen(cfg *config.Configuration, handler http.Handler, adminHandler http.H
 		go runServer(prometheusServer, "Prometheus", prometheusListener)
 	}
 
-	wait(stopSignals, done, stopChannels...)
+	drainPeriod := time.Duration(cfg.Health.DrainSeconds) * time.Second
+	wait(drainAfterSignals(stopSignals, healthChecker, drainPeriod), done, stopChannels...)
 
 	return
 }
@@ -193,6 +197,27 @@ func wait(inbound <-chan os.Signal, done <-chan struct{}, outbound ...chan<- os.
 	}
 }
 
+// drainAfterSignals drains the health checker when a signal is received, so /status/ready fails while the servers
+// still serve requests. The signal is forwarded once the drain period is over, or right away on a second signal.
+func drainAfterSignals(inbound <-chan os.Signal, healthChecker *health.Checker, period time.Duration) <-chan os.Signal {
+	outbound := make(chan os.Signal, 1)
+	go func() {
+		sig := <-inbound
+		healthChecker.Drain()
+
+		if period > 0 {
+			glog.Infof("Draining for %s because of signal: %s", period, sig.String())
+			select {
+			case <-time.After(period):
+			case sig = <-inbound:
+				glog.Infof("Stopping the drain because of signal: %s", sig.String())
+			}
+		}
+		outbound <- sig
+	}()
+	return outbound
+}
+
 func shutdownAfterSignals(server *http.Server, stopper <-chan os.Signal, done chan<- struct{}) {
 	sig := <-stopper
 
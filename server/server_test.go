@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/health"
 	metricsconfig "github.com/prebid/prebid-server/v3/metrics/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -50,6 +51,63 @@ func TestWait(t *testing.T) {
 	// If this doesn't hang, then wait() is sending and receiving messages as expected.
 }
 
+func TestDrainAfterSignals(t *testing.T) {
+	testCases := []struct {
+		description  string
+		period       time.Duration
+		secondSignal bool
+	}{
+		{
+			description: "no_drain_period",
+		},
+		{
+			description: "drain_period_over",
+			period:      10 * time.Millisecond,
+		},
+		{
+			description:  "second_signal",
+			period:       time.Hour,
+			secondSignal: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			inbound := make(chan os.Signal)
+			checker := health.NewChecker()
+
+			outbound := drainAfterSignals(inbound, checker, test.period)
+			assert.True(t, checker.Status().Ready, "The checker shouldn't drain before a signal")
+
+			inbound <- os.Interrupt
+			if test.secondSignal {
+				inbound <- os.Interrupt
+			}
+
+			select {
+			case sig := <-outbound:
+				assert.Equal(t, os.Interrupt, sig)
+			case <-time.After(time.Second):
+				require.Fail(t, "The signal wasn't forwarded")
+			}
+			assert.False(t, checker.Status().Ready, "The checker should be drained")
+		})
+	}
+}
+
+func TestDrainAfterSignalsWaits(t *testing.T) {
+	inbound := make(chan os.Signal)
+	outbound := drainAfterSignals(inbound, nil, time.Hour)
+
+	inbound <- os.Interrupt
+
+	select {
+	case <-outbound:
+		assert.Fail(t, "The signal shouldn't be forwarded during the drain period")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
 // forwardSignal is basically a working mock for shutdownAfterSignals().
 // It is used to test wait() effectively
 func forwardSignal(t *testing.T, outbound chan<- struct{}, inbound <-chan os.Signal) {
@@ -191,6 +249,6 @@ func TestListen(t *testing.T) {
 		}
 	)
 
-	err := Listen(cfg, handler, adminHandler, metrics)
+	err := Listen(cfg, handler, adminHandler, metrics, health.NewChecker())
 	assert.NotEqual(t, nil, err, "err : isNil()")
 }
@@ -104,6 +104,8 @@ func (fetcher *dbFetcher) FetchRequests(ctx context.Context, requestIDs []string
 
 	errs := appendErrors("Request", requestIDs, storedRequestData, nil)
 	errs = appendErrors("Imp", impIDs, storedImpData, errs)
+	errs = stored_requests.DropInvalidTemplates("Request", storedRequestData, errs)
+	errs = stored_requests.DropInvalidTemplates("Imp", storedImpData, errs)
 
 	return storedRequestData, storedImpData, errs
 }
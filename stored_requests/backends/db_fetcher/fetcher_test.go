@@ -318,3 +318,22 @@ func assertErrorCount(t *testing.T, num int, errs []error) {
 		t.Errorf("Wrong number of errors. Expected %d. Got %d. Errors are %v", num, len(errs), errs)
 	}
 }
+
+// TestInvalidTemplate makes sure the stored data which isn't a valid template is left out with an error.
+func TestInvalidTemplate(t *testing.T) {
+	mockQuery := "SELECT id, data, 'request' AS dataType FROM req_table WHERE id IN (?, ?) UNION ALL SELECT id, data, 'imp' as dataType FROM imp_table WHERE id IN (NULL)"
+	mockReturn := sqlmock.NewRows([]string{"id", "data", "dataType"}).
+		AddRow("stored-req-id", `{"tmax":"{{params.tmax:int}}"}`, "request").
+		AddRow("stored-req-id-2", `{"tmax":"{{params.tmax:number}}"}`, "request")
+
+	mock, fetcher := newFetcher(t, mockReturn, mockQuery, "stored-req-id", "stored-req-id-2")
+	defer fetcher.provider.Close()
+
+	storedReqs, _, errs := fetcher.FetchRequests(context.Background(), []string{"stored-req-id", "stored-req-id-2"}, nil)
+
+	assertMockExpectations(t, mock)
+	assertErrorCount(t, 1, errs)
+	assert.EqualError(t, errs[0], `Stored Request stored-req-id-2 is not a valid template: placeholder params.tmax has an unknown type "number"`)
+	assertMapLength(t, 1, storedReqs)
+	assertHasData(t, storedReqs, "stored-req-id", `{"tmax":"{{params.tmax:int}}"}`)
+}
@@ -0,0 +1,70 @@
+package db_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_provider"
+)
+
+// NewWriter returns a Writer which saves and deletes stored data with the given queries.
+// The queries may use the $ID, $TYPE and $DATA parameters, $DATA being available to the save query only.
+func NewWriter(provider db_provider.DbProvider, saveQuery string, deleteQuery string) stored_requests.Writer {
+	if provider == nil {
+		glog.Fatalf("The Database Stored Data Writer requires a database connection. Please report this as a bug.")
+	}
+	if saveQuery == "" || deleteQuery == "" {
+		glog.Fatalf("The Database Stored Data Writer requires a saveQuery and a deleteQuery. Please report this as a bug.")
+	}
+	return &dbWriter{
+		provider:    provider,
+		saveQuery:   saveQuery,
+		deleteQuery: deleteQuery,
+	}
+}
+
+// dbWriter writes stored data to a database. This should be instantiated through the NewWriter() function.
+type dbWriter struct {
+	provider    db_provider.DbProvider
+	saveQuery   string
+	deleteQuery string
+}
+
+func (writer *dbWriter) Save(ctx context.Context, dataType stored_requests.StoredDataType, id string, data json.RawMessage) error {
+	params := queryParams(writer.saveQuery,
+		db_provider.QueryParam{Name: "ID", Value: id},
+		db_provider.QueryParam{Name: "TYPE", Value: string(dataType)},
+		db_provider.QueryParam{Name: "DATA", Value: string(data)})
+
+	_, err := writer.provider.ExecContext(ctx, writer.saveQuery, params...)
+	return err
+}
+
+func (writer *dbWriter) Delete(ctx context.Context, dataType stored_requests.StoredDataType, id string) error {
+	params := queryParams(writer.deleteQuery,
+		db_provider.QueryParam{Name: "ID", Value: id},
+		db_provider.QueryParam{Name: "TYPE", Value: string(dataType)})
+
+	result, err := writer.provider.ExecContext(ctx, writer.deleteQuery, params...)
+	if err != nil {
+		return err
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return stored_requests.NotFoundError{ID: id, DataType: string(dataType)}
+	}
+	return nil
+}
+
+// queryParams returns the params used by the query, as the providers pass every param to the database.
+func queryParams(query string, params ...db_provider.QueryParam) []db_provider.QueryParam {
+	used := make([]db_provider.QueryParam, 0, len(params))
+	for _, param := range params {
+		if strings.Contains(query, "$"+param.Name) {
+			used = append(used, param)
+		}
+	}
+	return used
+}
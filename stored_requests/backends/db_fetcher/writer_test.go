@@ -0,0 +1,89 @@
+package db_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	saveQuery   = "INSERT INTO stored_data (id, type, data) VALUES ($ID, $TYPE, $DATA) ON CONFLICT (id, type) DO UPDATE SET data = $DATA"
+	deleteQuery = "DELETE FROM stored_data WHERE id = $ID AND type = $TYPE"
+)
+
+func TestWriterSave(t *testing.T) {
+	provider, mock, err := db_provider.NewDbProviderMock()
+	require.NoError(t, err)
+	defer provider.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(saveQuery)).
+		WithArgs("req1", "request", `{"id":"req1"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	writer := NewWriter(provider, saveQuery, deleteQuery)
+	err = writer.Save(context.Background(), stored_requests.StoredRequestType, "req1", json.RawMessage(`{"id":"req1"}`))
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWriterSaveError(t *testing.T) {
+	provider, mock, err := db_provider.NewDbProviderMock()
+	require.NoError(t, err)
+	defer provider.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(saveQuery)).WillReturnError(errors.New("connection lost"))
+
+	writer := NewWriter(provider, saveQuery, deleteQuery)
+	err = writer.Save(context.Background(), stored_requests.StoredImpType, "imp1", json.RawMessage(`{}`))
+	assert.EqualError(t, err, "connection lost")
+}
+
+func TestWriterDelete(t *testing.T) {
+	testCases := []struct {
+		description   string
+		rowsAffected  int64
+		expectedError error
+	}{
+		{
+			description:  "deleted",
+			rowsAffected: 1,
+		},
+		{
+			description:   "not-found",
+			rowsAffected:  0,
+			expectedError: stored_requests.NotFoundError{ID: "imp1", DataType: "imp"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			provider, mock, err := db_provider.NewDbProviderMock()
+			require.NoError(t, err)
+			defer provider.Close()
+
+			mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).
+				WithArgs("imp1", "imp").
+				WillReturnResult(sqlmock.NewResult(0, test.rowsAffected))
+
+			writer := NewWriter(provider, saveQuery, deleteQuery)
+			err = writer.Delete(context.Background(), stored_requests.StoredImpType, "imp1")
+			assert.Equal(t, test.expectedError, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	params := queryParams("DELETE FROM stored_imps WHERE id = $ID",
+		db_provider.QueryParam{Name: "ID", Value: "imp1"},
+		db_provider.QueryParam{Name: "TYPE", Value: "imp"})
+	assert.Equal(t, []db_provider.QueryParam{{Name: "ID", Value: "imp1"}}, params)
+}
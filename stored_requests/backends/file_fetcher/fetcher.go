@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
-	jsonpatch "gopkg.in/evanphx/json-patch.v5"
 )
 
 // NewFileFetcher _immediately_ loads stored request data from local files.
@@ -18,19 +20,31 @@ import (
 //
 // This expects each file in the directory to be named "{config_id}.json".
 // For example, when asked to fetch the request with ID == "23", it will return the data from "directory/23.json".
+//
+// The stored requests and imps are validated as templates, and an invalid one fails the load.
 func NewFileFetcher(directory string) (stored_requests.AllFetcher, error) {
 	storedData, err := collectStoredData(directory, FileSystem{make(map[string]FileSystem), make(map[string]json.RawMessage)}, nil)
-	return &eagerFetcher{storedData, nil}, err
+	if err == nil {
+		errs := stored_requests.DropInvalidTemplates("Request", storedData.Directories["stored_requests"].Files, nil)
+		errs = stored_requests.DropInvalidTemplates("Imp", storedData.Directories["stored_imps"].Files, errs)
+		err = errors.Join(errs...)
+	}
+	fetcher := &eagerFetcher{}
+	fetcher.fileSystem.Store(&storedData)
+	return fetcher, err
 }
 
 type eagerFetcher struct {
-	FileSystem FileSystem
+	// fileSystem is replaced as a whole when files are updated, so reads never see a partial update.
+	fileSystem atomic.Pointer[FileSystem]
+	updateLock sync.Mutex
 	Categories map[string]map[string]stored_requests.Category
 }
 
 func (fetcher *eagerFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
-	storedRequests := fetcher.FileSystem.Directories["stored_requests"].Files
-	storedImpressions := fetcher.FileSystem.Directories["stored_imps"].Files
+	fileSystem := fetcher.fileSystem.Load()
+	storedRequests := fileSystem.Directories["stored_requests"].Files
+	storedImpressions := fileSystem.Directories["stored_imps"].Files
 	errs := appendErrors("Request", requestIDs, storedRequests, nil)
 	errs = appendErrors("Imp", impIDs, storedImpressions, errs)
 	return storedRequests, storedImpressions, errs
@@ -38,7 +52,7 @@ func (fetcher *eagerFetcher) FetchRequests(ctx context.Context, requestIDs []str
 
 // Fetch Responses - Implements the interface to read the stored response information from the fetcher's FileSystem, the directory name is "stored_responses"
 func (fetcher *eagerFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
-	storedRespFS, found := fetcher.FileSystem.Directories["stored_responses"]
+	storedRespFS, found := fetcher.fileSystem.Load().Directories["stored_responses"]
 	if !found {
 		return nil, append(errs, errors.New(`no "stored_responses" directory found`))
 	}
@@ -52,7 +66,7 @@ func (fetcher *eagerFetcher) FetchAccount(ctx context.Context, accountDefaultsJS
 	if len(accountID) == 0 {
 		return nil, []error{fmt.Errorf("Cannot look up an empty accountID")}
 	}
-	accountJSON, ok := fetcher.FileSystem.Directories["accounts"].Files[accountID]
+	accountJSON, ok := fetcher.fileSystem.Load().Directories["accounts"].Files[accountID]
 	if !ok {
 		return nil, []error{stored_requests.NotFoundError{
 			ID:       accountID,
@@ -63,7 +77,7 @@ func (fetcher *eagerFetcher) FetchAccount(ctx context.Context, accountDefaultsJS
 	if accountDefaultsJSON == nil {
 		return accountJSON, nil
 	}
-	completeJSON, err := jsonpatch.MergePatch(accountDefaultsJSON, accountJSON)
+	completeJSON, err := stored_requests.MergeAccountDefaults(accountDefaultsJSON, accountJSON)
 	if err != nil {
 		return nil, []error{err}
 	}
@@ -84,7 +98,7 @@ func (fetcher *eagerFetcher) FetchCategories(ctx context.Context, primaryAdServe
 		return data[iabCategory].Id, nil
 	}
 
-	if primaryAdServerDir, found := fetcher.FileSystem.Directories[primaryAdServer]; found {
+	if primaryAdServerDir, found := fetcher.fileSystem.Load().Directories[primaryAdServer]; found {
 
 		if file, ok := primaryAdServerDir.Files[fileName]; ok {
 
@@ -113,6 +127,34 @@ func (fetcher *eagerFetcher) FetchCategories(ctx context.Context, primaryAdServe
 
 }
 
+// UpdateFiles saves and deletes files of a directory. The stored data is swapped atomically,
+// so fetches in progress keep reading the previous data.
+func (fetcher *eagerFetcher) UpdateFiles(directory string, saved map[string]json.RawMessage, deleted []string) {
+	fetcher.updateLock.Lock()
+	defer fetcher.updateLock.Unlock()
+
+	current := fetcher.fileSystem.Load()
+	currentDirectory := current.Directories[directory]
+
+	files := maps.Clone(currentDirectory.Files)
+	if files == nil {
+		files = make(map[string]json.RawMessage, len(saved))
+	}
+	for id, data := range saved {
+		files[id] = data
+	}
+	for _, id := range deleted {
+		delete(files, id)
+	}
+
+	directories := maps.Clone(current.Directories)
+	if directories == nil {
+		directories = make(map[string]FileSystem, 1)
+	}
+	directories[directory] = FileSystem{Directories: currentDirectory.Directories, Files: files}
+	fetcher.fileSystem.Store(&FileSystem{Directories: directories, Files: current.Files})
+}
+
 type FileSystem struct {
 	Directories map[string]FileSystem
 	Files       map[string]json.RawMessage
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/prebid/prebid-server/v3/stored_requests"
@@ -99,6 +101,36 @@ func TestAccountFetcher(t *testing.T) {
 
 }
 
+func TestAccountFetcherVersioned(t *testing.T) {
+	fetcher, err := NewFileFetcher("./test")
+	assert.NoError(t, err, "Failed to create test fetcher")
+
+	account, errs := fetcher.FetchAccount(context.Background(), json.RawMessage(`{"events_enabled":true}`), "versioned")
+	assertErrorCount(t, 0, errs)
+	assert.JSONEq(t, `{"variants":[
+		{"id":"v2","weight":10,"data":{"id":"versioned","disabled":false,"events":{"enabled":true},"events_enabled":true}},
+		{"id":"v1","weight":90,"data":{"id":"versioned","disabled":false,"events_enabled":true}}
+	]}`, string(account))
+}
+
+func TestUpdateFiles(t *testing.T) {
+	fetcher, err := NewFileFetcher("./test")
+	assert.NoError(t, err, "Failed to create test fetcher")
+	storedRequests, _, _ := fetcher.FetchRequests(context.Background(), nil, nil)
+
+	fetcher.(*eagerFetcher).UpdateFiles("stored_requests", map[string]json.RawMessage{"3": json.RawMessage(`{"id":"3"}`)}, []string{"2"})
+
+	requests, _, errs := fetcher.FetchRequests(context.Background(), []string{"1", "2", "3"}, nil)
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "2", DataType: "Request"}}, errs)
+	assert.JSONEq(t, `{"id":"3"}`, string(requests["3"]))
+	assert.Contains(t, requests, "1")
+	assert.Contains(t, storedRequests, "2", "the data previously fetched must not be modified")
+
+	fetcher.(*eagerFetcher).UpdateFiles("accounts", nil, []string{"valid"})
+	_, errs = fetcher.FetchAccount(context.Background(), nil, "valid")
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "valid", DataType: "Account"}}, errs)
+}
+
 func TestInvalidDirectory(t *testing.T) {
 	_, err := NewFileFetcher("./nonexistant-directory")
 	if err == nil {
@@ -250,3 +282,13 @@ func validateStoredResponse[T any](t *testing.T, storedInfo map[string]json.RawM
 		t.Errorf(`Bad data in stored response of id: "%s": %v`, id, err)
 	}
 }
+
+func TestInvalidTemplate(t *testing.T) {
+	directory := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(directory, "stored_imps"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, "stored_imps", "imp-1.json"), []byte(`{"w":"{{params.w:integer}}"}`), 0644))
+
+	_, err := NewFileFetcher(directory)
+
+	assert.EqualError(t, err, `Stored Imp imp-1 is not a valid template: placeholder params.w has an unknown type "integer"`)
+}
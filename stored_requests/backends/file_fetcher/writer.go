@@ -0,0 +1,101 @@
+package file_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/prebid/prebid-server/v3/stored_requests"
+)
+
+var directoriesByType = map[stored_requests.StoredDataType]string{
+	stored_requests.StoredRequestType:  "stored_requests",
+	stored_requests.StoredImpType:      "stored_imps",
+	stored_requests.StoredResponseType: "stored_responses",
+	stored_requests.StoredAccountType:  "accounts",
+}
+
+// NewFileWriter returns a Writer which saves stored data as "{id}.json" files in the subdirectories of the directory
+// loaded by the fetcher, which must have been created by NewFileFetcher. The fetcher serves the written data immediately.
+func NewFileWriter(directory string, fetcher stored_requests.AllFetcher) (stored_requests.Writer, error) {
+	eager, ok := fetcher.(*eagerFetcher)
+	if !ok {
+		return nil, errors.New("the fetcher wasn't created by NewFileFetcher")
+	}
+	return &fileWriter{directory: directory, fetcher: eager}, nil
+}
+
+type fileWriter struct {
+	directory string
+	fetcher   *eagerFetcher
+}
+
+func (writer *fileWriter) Save(ctx context.Context, dataType stored_requests.StoredDataType, id string, data json.RawMessage) error {
+	subdirectory, err := writer.subdirectory(dataType, id)
+	if err != nil {
+		return err
+	}
+	directory := filepath.Join(writer.directory, subdirectory)
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+
+	// the data is written to a temporary file which is renamed, so readers never see a partially written file
+	file, err := os.CreateTemp(directory, "."+id+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), filepath.Join(directory, id+".json")); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	writer.fetcher.UpdateFiles(subdirectory, map[string]json.RawMessage{id: data}, nil)
+	return nil
+}
+
+func (writer *fileWriter) Delete(ctx context.Context, dataType stored_requests.StoredDataType, id string) error {
+	subdirectory, err := writer.subdirectory(dataType, id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(writer.directory, subdirectory, id+".json")); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return stored_requests.NotFoundError{ID: id, DataType: string(dataType)}
+		}
+		return err
+	}
+
+	writer.fetcher.UpdateFiles(subdirectory, nil, []string{id})
+	return nil
+}
+
+func (writer *fileWriter) subdirectory(dataType stored_requests.StoredDataType, id string) (string, error) {
+	subdirectory, ok := directoriesByType[dataType]
+	if !ok {
+		return "", fmt.Errorf("stored %s data can't be written to files", dataType)
+	}
+	// the ID is used as a file name, so it must not lead outside of the directory
+	if id == "" || strings.HasPrefix(id, ".") || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf(`"%s" is not a valid file name`, id)
+	}
+	return subdirectory, nil
+}
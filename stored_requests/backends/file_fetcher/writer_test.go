@@ -0,0 +1,80 @@
+package file_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWriter(t *testing.T) {
+	directory := t.TempDir()
+	fetcher, err := NewFileFetcher(directory)
+	require.NoError(t, err)
+	writer, err := NewFileWriter(directory, fetcher)
+	require.NoError(t, err)
+
+	err = writer.Save(context.Background(), stored_requests.StoredImpType, "imp1", json.RawMessage(`{"id":"imp1"}`))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(directory, "stored_imps", "imp1.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"imp1"}`, string(data))
+	entries, err := os.ReadDir(filepath.Join(directory, "stored_imps"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary file must be renamed")
+
+	_, imps, errs := fetcher.FetchRequests(context.Background(), nil, []string{"imp1"})
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"id":"imp1"}`, string(imps["imp1"]))
+
+	err = writer.Delete(context.Background(), stored_requests.StoredImpType, "imp1")
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(directory, "stored_imps", "imp1.json"))
+	_, _, errs = fetcher.FetchRequests(context.Background(), nil, []string{"imp1"})
+	assert.Len(t, errs, 1)
+
+	err = writer.Delete(context.Background(), stored_requests.StoredImpType, "imp1")
+	assert.Equal(t, stored_requests.NotFoundError{ID: "imp1", DataType: "imp"}, err)
+}
+
+func TestFileWriterAccount(t *testing.T) {
+	directory := t.TempDir()
+	fetcher, err := NewFileFetcher(directory)
+	require.NoError(t, err)
+	writer, err := NewFileWriter(directory, fetcher)
+	require.NoError(t, err)
+
+	err = writer.Save(context.Background(), stored_requests.StoredAccountType, "acct1", json.RawMessage(`{"disabled":true}`))
+	require.NoError(t, err)
+
+	account, errs := fetcher.FetchAccount(context.Background(), json.RawMessage(`{"disabled":false,"events":{"enabled":true}}`), "acct1")
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"disabled":true,"events":{"enabled":true}}`, string(account))
+}
+
+func TestFileWriterInvalidID(t *testing.T) {
+	directory := t.TempDir()
+	fetcher, err := NewFileFetcher(directory)
+	require.NoError(t, err)
+	writer, err := NewFileWriter(directory, fetcher)
+	require.NoError(t, err)
+
+	for _, id := range []string{"", "..", "../accounts/acct1", `a\b`, ".hidden"} {
+		err := writer.Save(context.Background(), stored_requests.StoredRequestType, id, json.RawMessage(`{}`))
+		assert.Error(t, err, id)
+	}
+	entries, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestNewFileWriterInvalidFetcher(t *testing.T) {
+	_, err := NewFileWriter(t.TempDir(), nil)
+	assert.Error(t, err)
+}
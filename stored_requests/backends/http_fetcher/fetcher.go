@@ -11,7 +11,6 @@ import (
 
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
-	jsonpatch "gopkg.in/evanphx/json-patch.v5"
 
 	"github.com/golang/glog"
 	"golang.org/x/net/context/ctxhttp"
@@ -167,7 +166,7 @@ func (fetcher *HttpFetcher) FetchAccount(ctx context.Context, accountDefaultsJSO
 	if accountDefaultsJSON == nil {
 		return accountJSON, nil
 	}
-	completeJSON, err := jsonpatch.MergePatch(accountDefaultsJSON, accountJSON)
+	completeJSON, err := stored_requests.MergeAccountDefaults(accountDefaultsJSON, accountJSON)
 	if err != nil {
 		return nil, []error{err}
 	}
@@ -256,6 +255,8 @@ func unpackResponse(resp *http.Response) (requestData map[string]json.RawMessage
 
 		errs = convertNullsToErrs(requestData, "Request", errs)
 		errs = convertNullsToErrs(impData, "Imp", errs)
+		errs = stored_requests.DropInvalidTemplates("Request", requestData, errs)
+		errs = stored_requests.DropInvalidTemplates("Imp", impData, errs)
 
 		return
 	}
@@ -352,3 +352,19 @@ func assertMapKeys(t *testing.T, m map[string]json.RawMessage, keys ...string) {
 		}
 	}
 }
+
+func TestInvalidTemplate(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"requests":{"req-1":{"tagid":"{{params.slot}}"}},"imps":{"imp-1":{"w":"x-{{params.w:int}}"}}}`))
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	fetcher := NewFetcher(server.Client(), server.URL)
+
+	reqData, impData, errs := fetcher.FetchRequests(context.Background(), []string{"req-1"}, []string{"imp-1"})
+	assertMapKeys(t, reqData, "req-1")
+	assert.Empty(t, impData)
+	if assert.Len(t, errs, 1) {
+		assert.EqualError(t, errs[0], `Stored Imp imp-1 is not a valid template: placeholder params.w within a string can't be of type "int"`)
+	}
+}
@@ -2,12 +2,15 @@ package memory
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/coocood/freecache"
 	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
 )
 
 // NewCache returns an in-memory Cache which evicts items if:
@@ -29,34 +32,66 @@ func NewCache(size int, ttl int, dataType string) stored_requests.CacheJSON {
 				Cache:      freecache.NewCache(size),
 				ttlSeconds: ttl,
 			},
+			time: &timeutil.RealTime{},
 		}
 	} else {
 		glog.Infof("Using an unbounded Stored %s in-memory cache.", dataType)
 		return &cache{
 			dataType: dataType,
 			cache:    &pbsSyncMap{&sync.Map{}},
+			time:     &timeutil.RealTime{},
 		}
 	}
 }
 
+// The values are saved with a header: the kind of entry, followed by the time the data was saved or,
+// for ids known to be missing, the time the entry expires.
+const (
+	entryData byte = iota
+	entryMissing
+
+	entryHeaderSize = 9
+)
+
 type cache struct {
 	dataType string
 	cache    mapLike
+	time     timeutil.Time
 }
 
 func (c *cache) Get(ctx context.Context, ids []string) (data map[string]json.RawMessage) {
 	data = make(map[string]json.RawMessage, len(ids))
 	for _, id := range ids {
-		if val, ok := c.cache.Get(id); ok {
-			data[id] = val
+		if entry, ok := c.getEntry(id); ok && entry.Data != nil {
+			data[id] = entry.Data
+		}
+	}
+	return
+}
+
+// GetEntries implements stored_requests.EntryCache. It also returns the ids known to be missing.
+func (c *cache) GetEntries(ctx context.Context, ids []string) (entries map[string]stored_requests.CacheEntry) {
+	entries = make(map[string]stored_requests.CacheEntry, len(ids))
+	for _, id := range ids {
+		if entry, ok := c.getEntry(id); ok {
+			entries[id] = entry
 		}
 	}
 	return
 }
 
 func (c *cache) Save(ctx context.Context, data map[string]json.RawMessage) {
+	now := c.time.Now()
 	for id, data := range data {
-		c.cache.Set(id, data)
+		c.cache.Set(id, encodeEntry(entryData, now, data))
+	}
+}
+
+// SaveMissing implements stored_requests.EntryCache.
+func (c *cache) SaveMissing(ctx context.Context, ids []string, ttl time.Duration) {
+	expiresAt := c.time.Now().Add(ttl)
+	for _, id := range ids {
+		c.cache.SetWithTTL(id, encodeEntry(entryMissing, expiresAt, nil), ttl)
 	}
 }
 
@@ -65,3 +100,26 @@ func (c *cache) Invalidate(ctx context.Context, ids []string) {
 		c.cache.Delete(id)
 	}
 }
+
+func (c *cache) getEntry(id string) (stored_requests.CacheEntry, bool) {
+	val, ok := c.cache.Get(id)
+	if !ok || len(val) < entryHeaderSize {
+		return stored_requests.CacheEntry{}, false
+	}
+	entryTime := time.Unix(0, int64(binary.BigEndian.Uint64(val[1:entryHeaderSize]))).UTC()
+	if val[0] == entryMissing {
+		if !c.time.Now().Before(entryTime) {
+			return stored_requests.CacheEntry{}, false
+		}
+		return stored_requests.CacheEntry{}, true
+	}
+	return stored_requests.CacheEntry{Data: val[entryHeaderSize:], SavedAt: entryTime}, true
+}
+
+func encodeEntry(kind byte, entryTime time.Time, data json.RawMessage) json.RawMessage {
+	val := make([]byte, entryHeaderSize+len(data))
+	val[0] = kind
+	binary.BigEndian.PutUint64(val[1:entryHeaderSize], uint64(entryTime.UnixNano()))
+	copy(val[entryHeaderSize:], data)
+	return val
+}
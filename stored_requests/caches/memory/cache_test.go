@@ -6,9 +6,11 @@ import (
 	"math/rand"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/stored_requests/caches/cachestest"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestLRURobustness(t *testing.T) {
@@ -85,3 +87,52 @@ func mapForVal(val int) map[string]json.RawMessage {
 func sliceForVal(val int) []string {
 	return []string{strconv.Itoa(val)}
 }
+
+type fakeTime struct {
+	time time.Time
+}
+
+func (t *fakeTime) Now() time.Time {
+	return t.time
+}
+
+func TestCacheEntries(t *testing.T) {
+	now := &fakeTime{time: time.Date(2020, time.July, 1, 12, 0, 0, 0, time.UTC)}
+	c := NewCache(256*1024, -1, "TestData").(*cache)
+	c.time = now
+	ctx := context.Background()
+
+	c.Save(ctx, map[string]json.RawMessage{"saved": json.RawMessage(`{"id":"saved"}`)})
+	c.SaveMissing(ctx, []string{"missing"}, time.Minute)
+
+	assert.Equal(t, map[string]stored_requests.CacheEntry{
+		"saved":   {Data: json.RawMessage(`{"id":"saved"}`), SavedAt: now.time},
+		"missing": {},
+	}, c.GetEntries(ctx, []string{"saved", "missing", "unknown"}))
+	assert.Equal(t, map[string]json.RawMessage{"saved": json.RawMessage(`{"id":"saved"}`)},
+		c.Get(ctx, []string{"saved", "missing", "unknown"}), "the missing ids must not be returned as data")
+
+	now.time = now.time.Add(time.Minute)
+	assert.Empty(t, c.GetEntries(ctx, []string{"missing"}), "the missing ids must expire with their ttl")
+
+	c.SaveMissing(ctx, []string{"saved"}, time.Minute)
+	c.Save(ctx, map[string]json.RawMessage{"missing": json.RawMessage(`{}`)})
+	assert.Equal(t, map[string]stored_requests.CacheEntry{
+		"saved":   {},
+		"missing": {Data: json.RawMessage(`{}`), SavedAt: now.time},
+	}, c.GetEntries(ctx, []string{"saved", "missing"}))
+
+	c.Invalidate(ctx, []string{"saved", "missing"})
+	assert.Empty(t, c.GetEntries(ctx, []string{"saved", "missing"}))
+}
+
+func TestUnboundedCacheDoesNotSaveMissing(t *testing.T) {
+	c := NewCache(0, -1, "TestData").(*cache)
+	ctx := context.Background()
+
+	c.SaveMissing(ctx, []string{"missing"}, time.Minute)
+
+	_, ok := c.cache.Get("missing")
+	assert.False(t, ok, "the unbounded cache can't evict the missing ids so it must not save them")
+	assert.Empty(t, c.GetEntries(ctx, []string{"missing"}))
+}
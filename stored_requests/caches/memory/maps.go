@@ -3,6 +3,7 @@ package memory
 import (
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/coocood/freecache"
 	"github.com/golang/glog"
@@ -15,6 +16,9 @@ import (
 type mapLike interface {
 	Get(id string) (json.RawMessage, bool)
 	Set(id string, value json.RawMessage)
+	// SetWithTTL saves a value which expires after the TTL, or after the TTL of the map if shorter. Maps which
+	// can't expire values don't save it.
+	SetWithTTL(id string, value json.RawMessage, ttl time.Duration)
 	Delete(id string)
 }
 
@@ -36,6 +40,12 @@ func (m *pbsSyncMap) Set(id string, value json.RawMessage) {
 	m.Map.Store(id, value)
 }
 
+// SetWithTTL doesn't save the value: a sync.Map can't evict it once expired, so it would be kept forever.
+// Config validation rejects negative_ttl_seconds for unbounded caches, the only users of this map.
+func (m *pbsSyncMap) SetWithTTL(id string, value json.RawMessage, ttl time.Duration) {
+	glog.Warningf("unbounded in-memory cache can't save %s with a TTL. Config validation should have caught this.", id)
+}
+
 func (m *pbsSyncMap) Delete(id string) {
 	m.Map.Delete(id)
 }
@@ -63,6 +73,16 @@ func (m *pbsLRUCache) Set(id string, value json.RawMessage) {
 	}
 }
 
+func (m *pbsLRUCache) SetWithTTL(id string, value json.RawMessage, ttl time.Duration) {
+	ttlSeconds := int((ttl + time.Second - 1) / time.Second)
+	if m.ttlSeconds > 0 && m.ttlSeconds < ttlSeconds {
+		ttlSeconds = m.ttlSeconds
+	}
+	if err := m.Cache.Set([]byte(id), value, ttlSeconds); err != nil {
+		glog.Errorf("error saving value in freecache: %v", err)
+	}
+}
+
 func (m *pbsLRUCache) Delete(id string) {
 	m.Cache.Del([]byte(id))
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"time"
 
@@ -10,6 +11,8 @@ import (
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
 	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/health"
+	"github.com/prebid/prebid-server/v3/ortb"
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_fetcher"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_provider"
@@ -19,8 +22,10 @@ import (
 	"github.com/prebid/prebid-server/v3/stored_requests/caches/memory"
 	"github.com/prebid/prebid-server/v3/stored_requests/caches/nil_cache"
 	"github.com/prebid/prebid-server/v3/stored_requests/events"
+	adminEvents "github.com/prebid/prebid-server/v3/stored_requests/events/admin"
 	apiEvents "github.com/prebid/prebid-server/v3/stored_requests/events/api"
 	databaseEvents "github.com/prebid/prebid-server/v3/stored_requests/events/database"
+	filesystemEvents "github.com/prebid/prebid-server/v3/stored_requests/events/filesystem"
 	httpEvents "github.com/prebid/prebid-server/v3/stored_requests/events/http"
 	"github.com/prebid/prebid-server/v3/util/task"
 )
@@ -35,7 +40,9 @@ import (
 //
 // As a side-effect, it will add some endpoints to the router if the config calls for it.
 // In the future we should look for ways to simplify this so that it's not doing two things.
-func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router, provider db_provider.DbProvider) (fetcher stored_requests.AllFetcher, shutdown func()) {
+//
+// When the data is loaded in the in-memory cache, PBS isn't ready until it's loaded, so a check is registered on the checker.
+func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router, provider db_provider.DbProvider, validator *adminEvents.Validator, checker *health.Checker) (fetcher stored_requests.AllFetcher, shutdown func()) {
 	// Create database connection if given options for one
 	if cfg.Database.ConnectionInfo.Database != "" {
 		if provider == nil {
@@ -56,20 +63,40 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 	}
 
 	eventProducers := newEventProducers(cfg, client, provider, metricsEngine, router)
-	fetcher = newFetcher(cfg, client, provider)
+	fetcher, fileEvents := newFetcher(cfg, client, provider, metricsEngine)
+
+	// the events of these producers must be consumed for them to keep working, even without a cache to update
+	var backendEventProducers []events.EventProducer
+	if fileEvents != nil {
+		backendEventProducers = append(backendEventProducers, fileEvents)
+	}
+	if cfg.WriteAPI.Enabled {
+		backendEventProducers = append(backendEventProducers, newWriteAPI(cfg, fetcher, provider, router, validator))
+	}
 
 	var shutdown1 func()
 
 	if cfg.InMemoryCache.Type != "" {
 		cache := newCache(cfg)
-		fetcher = stored_requests.WithCache(fetcher, cache, metricsEngine)
-		shutdown1 = addListeners(cache, eventProducers)
+		fetcher = stored_requests.WithCachePolicy(fetcher, cache, newCachePolicy(cfg), metricsEngine)
+		shutdown1 = addListeners(cache, append(eventProducers, backendEventProducers...))
+		registerLoadedCheck(checker, cfg, eventProducers)
+	} else if len(backendEventProducers) > 0 {
+		shutdown1 = addListeners(newNilCache(), backendEventProducers)
 	}
 
 	shutdown = func() {
 		if shutdown1 != nil {
 			shutdown1()
 		}
+		if fileEvents != nil {
+			fileEvents.Close()
+		}
+		for _, eventProducer := range eventProducers {
+			if closer, ok := eventProducer.(io.Closer); ok {
+				closer.Close()
+			}
+		}
 
 		if provider == nil {
 			return
@@ -97,7 +124,7 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 //
 // As a side-effect, it will add some endpoints to the router if the config calls for it.
 // In the future we should look for ways to simplify this so that it's not doing two things.
-func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router) (shutdown func(),
+func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router, requestValidator ortb.RequestValidator, checker *health.Checker) (shutdown func(),
 	fetcher stored_requests.Fetcher,
 	ampFetcher stored_requests.Fetcher,
 	accountsFetcher stored_requests.AccountFetcher,
@@ -106,13 +133,14 @@ func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsE
 	storedRespFetcher stored_requests.Fetcher) {
 
 	var provider db_provider.DbProvider
+	validator := adminEvents.NewValidator(requestValidator, cfg.AccountDefaultsJSON())
 
-	fetcher1, shutdown1 := CreateStoredRequests(&cfg.StoredRequests, metricsEngine, client, router, provider)
-	fetcher2, shutdown2 := CreateStoredRequests(&cfg.StoredRequestsAMP, metricsEngine, client, router, provider)
-	fetcher3, shutdown3 := CreateStoredRequests(&cfg.CategoryMapping, metricsEngine, client, router, provider)
-	fetcher4, shutdown4 := CreateStoredRequests(&cfg.StoredVideo, metricsEngine, client, router, provider)
-	fetcher5, shutdown5 := CreateStoredRequests(&cfg.Accounts, metricsEngine, client, router, provider)
-	fetcher6, shutdown6 := CreateStoredRequests(&cfg.StoredResponses, metricsEngine, client, router, provider)
+	fetcher1, shutdown1 := CreateStoredRequests(&cfg.StoredRequests, metricsEngine, client, router, provider, validator, checker)
+	fetcher2, shutdown2 := CreateStoredRequests(&cfg.StoredRequestsAMP, metricsEngine, client, router, provider, validator, checker)
+	fetcher3, shutdown3 := CreateStoredRequests(&cfg.CategoryMapping, metricsEngine, client, router, provider, validator, checker)
+	fetcher4, shutdown4 := CreateStoredRequests(&cfg.StoredVideo, metricsEngine, client, router, provider, validator, checker)
+	fetcher5, shutdown5 := CreateStoredRequests(&cfg.Accounts, metricsEngine, client, router, provider, validator, checker)
+	fetcher6, shutdown6 := CreateStoredRequests(&cfg.StoredResponses, metricsEngine, client, router, provider, validator, checker)
 
 	fetcher = fetcher1.(stored_requests.Fetcher)
 	ampFetcher = fetcher2.(stored_requests.Fetcher)
@@ -133,6 +161,33 @@ func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsE
 	return
 }
 
+// loader is implemented by the event producers loading all the data on startup.
+type loader interface {
+	Loaded() bool
+}
+
+// registerLoadedCheck makes PBS not ready until all the event producers loading the data on startup have loaded it.
+func registerLoadedCheck(checker *health.Checker, cfg *config.StoredRequests, eventProducers []events.EventProducer) {
+	var loaders []loader
+	for _, eventProducer := range eventProducers {
+		if l, ok := eventProducer.(loader); ok {
+			loaders = append(loaders, l)
+		}
+	}
+	if len(loaders) == 0 {
+		return
+	}
+
+	checker.Register(cfg.Section(), health.Loaded("stored "+string(cfg.DataType())+" data", func() bool {
+		for _, l := range loaders {
+			if !l.Loaded() {
+				return false
+			}
+		}
+		return true
+	}))
+}
+
 func addListeners(cache stored_requests.Cache, eventProducers []events.EventProducer) (shutdown func()) {
 	listeners := make([]*events.EventListener, 0, len(eventProducers))
 
@@ -149,12 +204,23 @@ func addListeners(cache stored_requests.Cache, eventProducers []events.EventProd
 	}
 }
 
-func newFetcher(cfg *config.StoredRequests, client *http.Client, provider db_provider.DbProvider) (fetcher stored_requests.AllFetcher) {
+func newFetcher(cfg *config.StoredRequests, client *http.Client, provider db_provider.DbProvider, metricsEngine metrics.MetricsEngine) (fetcher stored_requests.AllFetcher, fileEvents *filesystemEvents.FilesystemEvents) {
 	idList := make(stored_requests.MultiFetcher, 0, 3)
 
 	if cfg.Files.Enabled {
+		if cfg.Files.Watch {
+			// the files are watched before they are loaded, so a change in between isn't missed
+			fileEvents = newFilesystemEvents(cfg.DataType(), cfg.Files.Path, metricsEngine)
+		}
 		fFetcher := newFilesystem(cfg.DataType(), cfg.Files.Path)
 		idList = append(idList, fFetcher)
+		if fileEvents != nil {
+			updater, ok := fFetcher.(filesystemEvents.Updater)
+			if !ok {
+				glog.Fatalf("The %s FileFetcher doesn't support watching files", cfg.DataType())
+			}
+			fileEvents.Start(updater)
+		}
 	}
 	if cfg.Database.FetcherQueries.QueryTemplate != "" {
 		glog.Infof("Loading Stored %s data via Database.\nQuery: %s", cfg.DataType(), cfg.Database.FetcherQueries.QueryTemplate)
@@ -173,13 +239,17 @@ func newFetcher(cfg *config.StoredRequests, client *http.Client, provider db_pro
 	return
 }
 
-func newCache(cfg *config.StoredRequests) stored_requests.Cache {
-	cache := stored_requests.Cache{
+func newNilCache() stored_requests.Cache {
+	return stored_requests.Cache{
 		Requests:  &nil_cache.NilCache{},
 		Imps:      &nil_cache.NilCache{},
 		Responses: &nil_cache.NilCache{},
 		Accounts:  &nil_cache.NilCache{},
 	}
+}
+
+func newCache(cfg *config.StoredRequests) stored_requests.Cache {
+	cache := newNilCache()
 	switch {
 	case cfg.InMemoryCache.Type == "none":
 		glog.Warningf("No %s cache configured. The %s Fetcher backend will be used for all data requests", cfg.DataType(), cfg.DataType())
@@ -193,6 +263,15 @@ func newCache(cfg *config.StoredRequests) stored_requests.Cache {
 	return cache
 }
 
+func newCachePolicy(cfg *config.StoredRequests) stored_requests.CachePolicy {
+	return stored_requests.CachePolicy{
+		NegativeTTL:          time.Duration(cfg.InMemoryCache.NegativeTTL) * time.Second,
+		MaxAge:               time.Duration(cfg.InMemoryCache.MaxAge) * time.Second,
+		StaleWhileRevalidate: time.Duration(cfg.InMemoryCache.StaleWhileRevalidate) * time.Second,
+		StaleIfError:         time.Duration(cfg.InMemoryCache.StaleIfError) * time.Second,
+	}
+}
+
 func newEventProducers(cfg *config.StoredRequests, client *http.Client, provider db_provider.DbProvider, metricsEngine metrics.MetricsEngine, router *httprouter.Router) (eventProducers []events.EventProducer) {
 	if cfg.CacheEvents.Enabled {
 		eventProducers = append(eventProducers, newEventsAPI(router, cfg.CacheEvents.Endpoint))
@@ -200,6 +279,7 @@ func newEventProducers(cfg *config.StoredRequests, client *http.Client, provider
 	if cfg.HTTPEvents.RefreshRate != 0 && cfg.HTTPEvents.Endpoint != "" {
 		eventProducers = append(eventProducers, newHttpEvents(client, cfg.HTTPEvents.TimeoutDuration(), cfg.HTTPEvents.RefreshRateDuration(), cfg.HTTPEvents.Endpoint))
 	}
+	var dbEventProducer *databaseEvents.DatabaseEventProducer
 	if cfg.Database.CacheInitialization.Query != "" {
 		dbEventCfg := databaseEvents.DatabaseEventProducerConfig{
 			Provider:           provider,
@@ -210,11 +290,44 @@ func newEventProducers(cfg *config.StoredRequests, client *http.Client, provider
 			CacheUpdateTimeout: time.Duration(cfg.Database.PollUpdates.Timeout) * time.Millisecond,
 			MetricsEngine:      metricsEngine,
 		}
-		dbEventProducer := databaseEvents.NewDatabaseEventProducer(dbEventCfg)
-		fetchInterval := time.Duration(cfg.Database.PollUpdates.RefreshRate) * time.Second
+		dbEventProducer = databaseEvents.NewDatabaseEventProducer(dbEventCfg)
+		eventProducers = append(eventProducers, dbEventProducer)
+	}
+	fetchInterval := time.Duration(cfg.Database.PollUpdates.RefreshRate) * time.Second
+	if cfg.Database.Notifications.Channel != "" {
+		notificationCfg := databaseEvents.NotificationEventProducerConfig{
+			Provider:             provider,
+			RequestType:          cfg.DataType(),
+			Channel:              cfg.Database.Notifications.Channel,
+			MinReconnectInterval: time.Duration(cfg.Database.Notifications.MinReconnectInterval) * time.Millisecond,
+			MaxReconnectInterval: time.Duration(cfg.Database.Notifications.MaxReconnectInterval) * time.Millisecond,
+			FallbackInterval:     fetchInterval,
+			MetricsEngine:        metricsEngine,
+		}
+		// the polling catches up with the changes missed while reconnecting, so it's run by the notifications
+		if dbEventProducer != nil {
+			notificationCfg.Fallback = dbEventProducer
+		}
+		notificationEventProducer := databaseEvents.NewNotificationEventProducer(notificationCfg)
+		notificationEventProducer.Start()
+		eventProducers = append(eventProducers, notificationEventProducer)
+	} else if dbEventProducer != nil {
 		dbEventTickerTask := task.NewTickerTask(fetchInterval, dbEventProducer)
 		dbEventTickerTask.Start()
-		eventProducers = append(eventProducers, dbEventProducer)
+	}
+	if cfg.Database.Notifications.ChangeLog.Query != "" {
+		changeLogEventProducer := databaseEvents.NewChangeLogEventProducer(databaseEvents.ChangeLogEventProducerConfig{
+			Provider:          provider,
+			RequestType:       cfg.DataType(),
+			Query:             cfg.Database.Notifications.ChangeLog.Query,
+			LastSequenceQuery: cfg.Database.Notifications.ChangeLog.LastSequenceQuery,
+			Timeout:           time.Duration(cfg.Database.Notifications.ChangeLog.Timeout) * time.Millisecond,
+			MetricsEngine:     metricsEngine,
+		})
+		changeLogInterval := time.Duration(cfg.Database.Notifications.ChangeLog.RefreshRate) * time.Second
+		changeLogTickerTask := task.NewTickerTask(changeLogInterval, changeLogEventProducer)
+		changeLogTickerTask.Start()
+		eventProducers = append(eventProducers, changeLogEventProducer)
 	}
 	return
 }
@@ -226,6 +339,47 @@ func newEventsAPI(router *httprouter.Router, endpoint string) events.EventProduc
 	return producer
 }
 
+func newWriteAPI(cfg *config.StoredRequests, fetcher stored_requests.AllFetcher, provider db_provider.DbProvider, router *httprouter.Router, validator *adminEvents.Validator) events.EventProducer {
+	var writer stored_requests.Writer
+	if cfg.Database.ConnectionInfo.Database != "" && cfg.Database.WriteQueries.SaveQuery != "" {
+		glog.Infof("Writing Stored %s data via Database.\nSave query: %s\nDelete query: %s", cfg.DataType(), cfg.Database.WriteQueries.SaveQuery, cfg.Database.WriteQueries.DeleteQuery)
+		writer = db_fetcher.NewWriter(provider, cfg.Database.WriteQueries.SaveQuery, cfg.Database.WriteQueries.DeleteQuery)
+	} else if cfg.Files.Enabled {
+		glog.Infof("Writing Stored %s data to filesystem at path %s", cfg.DataType(), cfg.Files.Path)
+		writer = newFileWriter(cfg.DataType(), cfg.Files.Path, fetcher)
+	} else {
+		glog.Fatalf("The %s write api requires the filesystem or the database write queries", cfg.DataType())
+	}
+
+	producer, handler := adminEvents.NewWriteAPI(adminEvents.WriteAPIConfig{
+		DataType:  cfg.DataType(),
+		Tokens:    cfg.WriteAPI.Tokens,
+		Fetcher:   fetcher,
+		Writer:    writer,
+		Validator: validator,
+	})
+	path := cfg.WriteAPI.Endpoint + "/:type/:id"
+	router.GET(path, handler)
+	router.PUT(path, handler)
+	router.DELETE(path, handler)
+	return producer
+}
+
+// newFileWriter returns a writer for the file fetcher, which may be one of several fetchers.
+func newFileWriter(dataType config.DataType, configPath string, fetcher stored_requests.AllFetcher) stored_requests.Writer {
+	fetchers := []stored_requests.AllFetcher{fetcher}
+	if multiFetcher, ok := fetcher.(stored_requests.MultiFetcher); ok {
+		fetchers = multiFetcher
+	}
+	for _, f := range fetchers {
+		if writer, err := file_fetcher.NewFileWriter(configPath, f); err == nil {
+			return writer
+		}
+	}
+	glog.Fatalf("Failed to create a %s FileWriter: no FileFetcher found", dataType)
+	return nil
+}
+
 func newHttpEvents(client *http.Client, timeout time.Duration, refreshRate time.Duration, endpoint string) events.EventProducer {
 	ctxProducer := func() (ctx context.Context, canceller func()) {
 		return context.WithTimeout(context.Background(), timeout)
@@ -242,6 +396,14 @@ func newFilesystem(dataType config.DataType, configPath string) stored_requests.
 	return fetcher
 }
 
+func newFilesystemEvents(dataType config.DataType, configPath string, metricsEngine metrics.MetricsEngine) *filesystemEvents.FilesystemEvents {
+	producer, err := filesystemEvents.NewFilesystemEvents(configPath, dataType, metricsEngine)
+	if err != nil {
+		glog.Fatalf("Failed to watch the %s files: %v", dataType, err)
+	}
+	return producer
+}
+
 // consolidate returns a single Fetcher from an array of fetchers of any size.
 func consolidate(dataType config.DataType, fetchers []stored_requests.AllFetcher) stored_requests.AllFetcher {
 	if len(fetchers) == 0 {
@@ -14,12 +14,14 @@ import (
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/julienschmidt/httprouter"
 	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/health"
 	"github.com/prebid/prebid-server/v3/metrics"
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_provider"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/http_fetcher"
 	"github.com/prebid/prebid-server/v3/stored_requests/events"
+	adminEvents "github.com/prebid/prebid-server/v3/stored_requests/events/admin"
 	httpEvents "github.com/prebid/prebid-server/v3/stored_requests/events/http"
 	"github.com/stretchr/testify/mock"
 )
@@ -117,7 +119,7 @@ func TestNewEmptyFetcher(t *testing.T) {
 	}
 
 	for _, test := range testCases {
-		fetcher := newFetcher(test.config, nil, db_provider.DbProviderMock{})
+		fetcher, _ := newFetcher(test.config, nil, db_provider.DbProviderMock{}, nil)
 		assert.NotNil(t, fetcher, "The fetcher should be non-nil.")
 		if test.emptyFetcher {
 			assert.Equal(t, empty_fetcher.EmptyFetcher{}, fetcher, "Empty fetcher should be returned")
@@ -128,11 +130,11 @@ func TestNewEmptyFetcher(t *testing.T) {
 }
 
 func TestNewHTTPFetcher(t *testing.T) {
-	fetcher := newFetcher(&config.StoredRequests{
+	fetcher, _ := newFetcher(&config.StoredRequests{
 		HTTP: config.HTTPFetcherConfig{
 			Endpoint: "stored-requests.prebid.com",
 		},
-	}, nil, nil)
+	}, nil, nil, nil)
 	if httpFetcher, ok := fetcher.(*http_fetcher.HttpFetcher); ok {
 		if httpFetcher.Endpoint != "stored-requests.prebid.com?" {
 			t.Errorf("The HTTP fetcher is using the wrong endpoint. Expected %s, got %s", "stored-requests.prebid.com?", httpFetcher.Endpoint)
@@ -231,6 +233,87 @@ func TestNewDatabaseEventProducers(t *testing.T) {
 	metricsMock.AssertExpectations(t)
 }
 
+func TestNewChangeLogEventProducers(t *testing.T) {
+	metricsMock := &metrics.MetricsEngineMock{}
+
+	cfg := &config.StoredRequests{
+		Database: config.DatabaseConfig{
+			Notifications: config.DatabaseNotifications{
+				ChangeLog: config.DatabaseChangeLog{
+					Timeout:           50,
+					Query:             "SELECT seq, id, data, type, changed_at FROM stored_data_changes WHERE seq > $1",
+					LastSequenceQuery: "SELECT MAX(seq) FROM stored_data_changes",
+				},
+			},
+		},
+	}
+	client := &http.Client{}
+	provider, mock, err := db_provider.NewDbProviderMock()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	// the first run only reads where the change log ends
+	mock.ExpectQuery("^" + regexp.QuoteMeta(cfg.Database.Notifications.ChangeLog.LastSequenceQuery) + "$").WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+
+	evProducers := newEventProducers(cfg, client, provider, metricsMock, nil)
+	assertProducerLength(t, evProducers, 1)
+
+	assertExpectationsMet(t, mock)
+	metricsMock.AssertExpectations(t)
+}
+
+func TestRegisterLoadedCheck(t *testing.T) {
+	testCases := []struct {
+		description    string
+		producers      []events.EventProducer
+		expectedChecks map[string]string
+	}{
+		{
+			description:    "no_loaders",
+			producers:      []events.EventProducer{&fakeProducer{}},
+			expectedChecks: map[string]string{},
+		},
+		{
+			description:    "all_loaded",
+			producers:      []events.EventProducer{&fakeProducer{}, &fakeLoader{loaded: true}, &fakeLoader{loaded: true}},
+			expectedChecks: map[string]string{"accounts": "ok"},
+		},
+		{
+			description:    "one_not_loaded",
+			producers:      []events.EventProducer{&fakeLoader{loaded: true}, &fakeLoader{loaded: false}},
+			expectedChecks: map[string]string{"accounts": "stored Account data not loaded yet"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			checker := health.NewChecker()
+			registerLoadedCheck(checker, typedConfig(config.AccountDataType, &config.StoredRequests{}), test.producers)
+
+			assert.Equal(t, test.expectedChecks, checker.Status().Checks)
+		})
+	}
+}
+
+type fakeProducer struct{}
+
+func (p *fakeProducer) Saves() <-chan events.Save {
+	return nil
+}
+
+func (p *fakeProducer) Invalidations() <-chan events.Invalidation {
+	return nil
+}
+
+type fakeLoader struct {
+	fakeProducer
+	loaded bool
+}
+
+func (l *fakeLoader) Loaded() bool {
+	return l.loaded
+}
+
 func TestNewEventsAPI(t *testing.T) {
 	router := httprouter.New()
 	newEventsAPI(router, "/test-endpoint")
@@ -242,6 +325,24 @@ func TestNewEventsAPI(t *testing.T) {
 	}
 }
 
+func TestNewWriteAPI(t *testing.T) {
+	router := httprouter.New()
+	cfg := typedConfig(config.RequestDataType, &config.StoredRequests{
+		Files:    config.FileFetcherConfig{Enabled: true, Path: t.TempDir()},
+		WriteAPI: config.WriteAPIConfig{Enabled: true, Endpoint: "/test-endpoint", Tokens: []string{"token"}},
+	})
+	fetcher := stored_requests.MultiFetcher{empty_fetcher.EmptyFetcher{}, newFilesystem(cfg.DataType(), cfg.Files.Path)}
+
+	producer := newWriteAPI(cfg, fetcher, nil, router, adminEvents.NewValidator(nil, nil))
+	assert.NotNil(t, producer)
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		handle, params, _ := router.Lookup(method, "/test-endpoint/request/req1")
+		assert.NotNil(t, handle, "The newWriteAPI method didn't add a %s route", method)
+		assert.Equal(t, "request", params.ByName("type"))
+		assert.Equal(t, "req1", params.ByName("id"))
+	}
+}
+
 func assertProducerLength(t *testing.T, producers []events.EventProducer, expectedLength int) {
 	t.Helper()
 	if len(producers) != expectedLength {
@@ -0,0 +1,224 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/prebid/prebid-server/v3/stored_requests/events"
+	"github.com/prebid/prebid-server/v3/util/httputil"
+)
+
+// maxBodySize is the largest stored data accepted by the write api.
+const maxBodySize = 1 << 20
+
+var validID = regexp.MustCompile(`^[A-Za-z0-9_\-.:]+$`)
+
+var typesByDataType = map[config.DataType][]stored_requests.StoredDataType{
+	config.RequestDataType:    {stored_requests.StoredRequestType, stored_requests.StoredImpType},
+	config.AMPRequestDataType: {stored_requests.StoredRequestType, stored_requests.StoredImpType},
+	config.VideoDataType:      {stored_requests.StoredRequestType, stored_requests.StoredImpType},
+	config.ResponseDataType:   {stored_requests.StoredResponseType},
+	config.AccountDataType:    {stored_requests.StoredAccountType},
+}
+
+// WriteAPIConfig contains the dependencies of the write api of a stored data config section.
+type WriteAPIConfig struct {
+	DataType config.DataType
+	// Tokens are the bearer tokens accepted in the Authorization header.
+	Tokens []string
+	// Fetcher reads the stored data from the backend, without any cache.
+	Fetcher   stored_requests.AllFetcher
+	Writer    stored_requests.Writer
+	Validator *Validator
+}
+
+type writeAPI struct {
+	cfg           WriteAPIConfig
+	types         map[string]stored_requests.StoredDataType
+	saves         chan events.Save
+	invalidations chan events.Invalidation
+}
+
+// NewWriteAPI creates an EventProducer that generates cache events from the stored data written through HTTP requests.
+// The returned httprouter.Handle must be registered on the GET (read), PUT (save) and DELETE (delete) methods and
+// provided `:type` and `:id` params via the URL, e.g.:
+//
+// writeEvents, writeHandler := NewWriteAPI(cfg)
+// router.GET("/storeddata/openrtb2/:type/:id", writeHandler)
+// router.PUT("/storeddata/openrtb2/:type/:id", writeHandler)
+// router.DELETE("/storeddata/openrtb2/:type/:id", writeHandler)
+// listener := events.Listen(cache, writeEvents)
+//
+// The type is "request" or "imp" for stored requests, "response" for stored responses and "account" for accounts.
+// Saved data is validated, then written to the backend before the cache is updated. Other instances
+// pick up the change from the backend, through their own filesystem or database event producers.
+//
+// Requests must carry one of the configured tokens in an "Authorization: Bearer {token}" header.
+func NewWriteAPI(cfg WriteAPIConfig) (events.EventProducer, httprouter.Handle) {
+	api := &writeAPI{
+		cfg:           cfg,
+		types:         make(map[string]stored_requests.StoredDataType),
+		saves:         make(chan events.Save),
+		invalidations: make(chan events.Invalidation),
+	}
+	for _, dataType := range typesByDataType[cfg.DataType] {
+		api.types[string(dataType)] = dataType
+	}
+	return api, httprouter.Handle(api.HandleRequest)
+}
+
+func (api *writeAPI) HandleRequest(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	if httputil.BearerTokenIndex(r, api.cfg.Tokens) < 0 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	dataType, ok := api.types[params.ByName("type")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "Unknown stored data type %q.\n", params.ByName("type"))
+		return
+	}
+	id := params.ByName("id")
+	if !validID.MatchString(id) || strings.HasPrefix(id, ".") {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Invalid stored %s ID %q.\n", dataType, id)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		api.handleGet(w, r, dataType, id)
+	case http.MethodPut:
+		api.handlePut(w, r, dataType, id)
+	case http.MethodDelete:
+		api.handleDelete(w, r, dataType, id)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (api *writeAPI) handleGet(w http.ResponseWriter, r *http.Request, dataType stored_requests.StoredDataType, id string) {
+	data, errs := api.fetch(r.Context(), dataType, id)
+	if len(data) == 0 {
+		if len(errs) > 0 && !isNotFound(errs[0]) {
+			glog.Errorf("Failed to read stored %s %s: %v", dataType, id, errs)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "Stored %s %q not found.\n", dataType, id)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (api *writeAPI) fetch(ctx context.Context, dataType stored_requests.StoredDataType, id string) (json.RawMessage, []error) {
+	switch dataType {
+	case stored_requests.StoredRequestType:
+		requests, _, errs := api.cfg.Fetcher.FetchRequests(ctx, []string{id}, nil)
+		return requests[id], errs
+	case stored_requests.StoredImpType:
+		_, imps, errs := api.cfg.Fetcher.FetchRequests(ctx, nil, []string{id})
+		return imps[id], errs
+	case stored_requests.StoredResponseType:
+		responses, errs := api.cfg.Fetcher.FetchResponses(ctx, []string{id})
+		return responses[id], errs
+	default:
+		// the account is returned as stored, without the account defaults
+		return api.cfg.Fetcher.FetchAccount(ctx, nil, id)
+	}
+}
+
+func (api *writeAPI) handlePut(w http.ResponseWriter, r *http.Request, dataType stored_requests.StoredDataType, id string) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Failed to read the stored data: %v\n", err)
+		return
+	}
+	if len(body) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Missing stored data.\n"))
+		return
+	}
+
+	if errs := api.cfg.Validator.Validate(dataType, id, body); len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Invalid stored %s %q:\n", dataType, id)
+		for _, err := range errs {
+			fmt.Fprintf(w, "%v\n", err)
+		}
+		return
+	}
+
+	data := json.RawMessage(body)
+	if err := api.cfg.Writer.Save(r.Context(), dataType, id, data); err != nil {
+		glog.Errorf("Failed to save stored %s %s: %v", dataType, id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch dataType {
+	case stored_requests.StoredRequestType:
+		api.saves <- events.Save{Requests: map[string]json.RawMessage{id: data}}
+	case stored_requests.StoredImpType:
+		api.saves <- events.Save{Imps: map[string]json.RawMessage{id: data}}
+	case stored_requests.StoredResponseType:
+		api.saves <- events.Save{Responses: map[string]json.RawMessage{id: data}}
+	case stored_requests.StoredAccountType:
+		// caches hold accounts merged with the account defaults, so the account must be fetched again
+		api.invalidations <- events.Invalidation{Accounts: []string{id}}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *writeAPI) handleDelete(w http.ResponseWriter, r *http.Request, dataType stored_requests.StoredDataType, id string) {
+	if err := api.cfg.Writer.Delete(r.Context(), dataType, id); err != nil {
+		if isNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "Stored %s %q not found.\n", dataType, id)
+			return
+		}
+		glog.Errorf("Failed to delete stored %s %s: %v", dataType, id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch dataType {
+	case stored_requests.StoredRequestType:
+		api.invalidations <- events.Invalidation{Requests: []string{id}}
+	case stored_requests.StoredImpType:
+		api.invalidations <- events.Invalidation{Imps: []string{id}}
+	case stored_requests.StoredResponseType:
+		api.invalidations <- events.Invalidation{Responses: []string{id}}
+	case stored_requests.StoredAccountType:
+		api.invalidations <- events.Invalidation{Accounts: []string{id}}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func isNotFound(err error) bool {
+	var notFound stored_requests.NotFoundError
+	return errors.As(err, &notFound)
+}
+
+func (api *writeAPI) Invalidations() <-chan events.Invalidation {
+	return api.invalidations
+}
+
+func (api *writeAPI) Saves() <-chan events.Save {
+	return api.saves
+}
@@ -0,0 +1,194 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/file_fetcher"
+	"github.com/prebid/prebid-server/v3/stored_requests/caches/memory"
+	"github.com/prebid/prebid-server/v3/stored_requests/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testToken = "secret"
+	validImp  = `{"banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placement_id":1}}}}}`
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Save(ctx context.Context, dataType stored_requests.StoredDataType, id string, data json.RawMessage) error {
+	return errors.New("disk full")
+}
+
+func (failingWriter) Delete(ctx context.Context, dataType stored_requests.StoredDataType, id string) error {
+	return errors.New("disk full")
+}
+
+type testAPI struct {
+	directory string
+	handler   httprouter.Handle
+	cache     stored_requests.Cache
+	saved     chan struct{}
+	deleted   chan struct{}
+}
+
+func newTestAPI(t *testing.T, dataType config.DataType, writer stored_requests.Writer) *testAPI {
+	directory := t.TempDir()
+	fetcher, err := file_fetcher.NewFileFetcher(directory)
+	require.NoError(t, err)
+	if writer == nil {
+		writer, err = file_fetcher.NewFileWriter(directory, fetcher)
+		require.NoError(t, err)
+	}
+
+	producer, handler := NewWriteAPI(WriteAPIConfig{
+		DataType:  dataType,
+		Tokens:    []string{"other", testToken},
+		Fetcher:   fetcher,
+		Writer:    writer,
+		Validator: newTestValidator(t),
+	})
+
+	api := &testAPI{
+		directory: directory,
+		handler:   handler,
+		cache: stored_requests.Cache{
+			Requests:  memory.NewCache(256*1024, -1, "Request"),
+			Imps:      memory.NewCache(256*1024, -1, "Imp"),
+			Responses: memory.NewCache(256*1024, -1, "Responses"),
+			Accounts:  memory.NewCache(256*1024, -1, "Account"),
+		},
+		saved:   make(chan struct{}, 1),
+		deleted: make(chan struct{}, 1),
+	}
+	listener := events.NewEventListener(
+		func() { api.saved <- struct{}{} },
+		func() { api.deleted <- struct{}{} },
+	)
+	go listener.Listen(api.cache, producer)
+	t.Cleanup(listener.Stop)
+	return api
+}
+
+func (api *testAPI) do(method, dataType, id, body, token string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, "/storeddata", strings.NewReader(body))
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	api.handler(recorder, request, httprouter.Params{{Key: "type", Value: dataType}, {Key: "id", Value: id}})
+	return recorder
+}
+
+func TestWriteAPIUnauthorized(t *testing.T) {
+	api := newTestAPI(t, config.RequestDataType, nil)
+
+	for _, token := range []string{"", "wrong", testToken + "x"} {
+		recorder := api.do(http.MethodPut, "imp", "imp1", validImp, token)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+	}
+	assert.NoDirExists(t, filepath.Join(api.directory, "stored_imps"))
+}
+
+func TestWriteAPISaveImp(t *testing.T) {
+	api := newTestAPI(t, config.RequestDataType, nil)
+
+	recorder := api.do(http.MethodPut, "imp", "imp1", validImp, testToken)
+	require.Equal(t, http.StatusNoContent, recorder.Code, recorder.Body.String())
+	<-api.saved
+
+	data, err := os.ReadFile(filepath.Join(api.directory, "stored_imps", "imp1.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, validImp, string(data))
+	assert.JSONEq(t, validImp, string(api.cache.Imps.Get(context.Background(), []string{"imp1"})["imp1"]))
+
+	recorder = api.do(http.MethodGet, "imp", "imp1", "", testToken)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, validImp, recorder.Body.String())
+}
+
+func TestWriteAPISaveInvalid(t *testing.T) {
+	api := newTestAPI(t, config.RequestDataType, nil)
+
+	recorder := api.do(http.MethodPut, "imp", "imp1", `{"ext":{"prebid":{"bidder":{"appnexus":{"placement_id":1}}}}}`, testToken)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `Invalid stored imp "imp1"`)
+	assert.NoFileExists(t, filepath.Join(api.directory, "stored_imps", "imp1.json"))
+
+	recorder = api.do(http.MethodPut, "imp", "imp1", "", testToken)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestWriteAPIDelete(t *testing.T) {
+	api := newTestAPI(t, config.RequestDataType, nil)
+	request := `{"id":"req1","imp":[{"id":"imp1","ext":{"prebid":{"storedrequest":{"id":"imp1"}}}}]}`
+
+	recorder := api.do(http.MethodPut, "request", "req1", request, testToken)
+	require.Equal(t, http.StatusNoContent, recorder.Code, recorder.Body.String())
+	<-api.saved
+
+	recorder = api.do(http.MethodDelete, "request", "req1", "", testToken)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	<-api.deleted
+	assert.NoFileExists(t, filepath.Join(api.directory, "stored_requests", "req1.json"))
+	assert.Empty(t, api.cache.Requests.Get(context.Background(), []string{"req1"}))
+
+	recorder = api.do(http.MethodDelete, "request", "req1", "", testToken)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = api.do(http.MethodGet, "request", "req1", "", testToken)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestWriteAPISaveAccount(t *testing.T) {
+	api := newTestAPI(t, config.AccountDataType, nil)
+	api.cache.Accounts.Save(context.Background(), map[string]json.RawMessage{"acct1": json.RawMessage(`{"disabled":false}`)})
+
+	recorder := api.do(http.MethodPut, "account", "acct1", `{"disabled":true}`, testToken)
+	require.Equal(t, http.StatusNoContent, recorder.Code, recorder.Body.String())
+	<-api.deleted
+	assert.Empty(t, api.cache.Accounts.Get(context.Background(), []string{"acct1"}), "the merged account must be fetched again")
+
+	recorder = api.do(http.MethodGet, "account", "acct1", "", testToken)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"disabled":true}`, recorder.Body.String())
+}
+
+func TestWriteAPIInvalidPath(t *testing.T) {
+	api := newTestAPI(t, config.AccountDataType, nil)
+
+	recorder := api.do(http.MethodPut, "request", "req1", `{}`, testToken)
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "requests aren't written by the accounts api")
+
+	for _, id := range []string{"..", ".hidden", "a b", "a%2Fb"} {
+		recorder = api.do(http.MethodPut, "account", id, `{}`, testToken)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, id)
+	}
+
+	recorder = api.do(http.MethodPost, "account", "acct1", `{}`, testToken)
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestWriteAPIWriterError(t *testing.T) {
+	api := newTestAPI(t, config.ResponseDataType, failingWriter{})
+
+	recorder := api.do(http.MethodPut, "response", "resp1", `{"seatbid":[]}`, testToken)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	recorder = api.do(http.MethodDelete, "response", "resp1", "", testToken)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Empty(t, api.saved)
+	assert.Empty(t, api.deleted)
+}
@@ -0,0 +1,193 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/currency"
+	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/ortb"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// Validator checks stored data before it's written, so data which would fail every request using it is rejected.
+type Validator struct {
+	requestValidator ortb.RequestValidator
+	accountDefaults  json.RawMessage
+}
+
+// NewValidator returns a Validator checking stored requests and imps with the request validator, which validates
+// the bidder params against the bidder schemas, and accounts merged with the account defaults.
+func NewValidator(requestValidator ortb.RequestValidator, accountDefaults json.RawMessage) *Validator {
+	return &Validator{
+		requestValidator: requestValidator,
+		accountDefaults:  accountDefaults,
+	}
+}
+
+// Validate returns the errors found in the stored data. Versioned stored requests, imps and accounts
+// are checked variant by variant.
+func (v *Validator) Validate(dataType stored_requests.StoredDataType, id string, data json.RawMessage) []error {
+	if !json.Valid(data) {
+		return []error{errors.New("the data is not valid JSON")}
+	}
+	if dataType == stored_requests.StoredResponseType || !stored_requests.IsVersioned(data) {
+		return v.validateData(dataType, id, data)
+	}
+
+	variants, err := stored_requests.ParseVariants(data)
+	if err != nil {
+		return []error{fmt.Errorf("invalid variants: %v", err)}
+	}
+	var errs []error
+	for _, variant := range variants {
+		for _, err := range v.validateData(dataType, id, variant.Data) {
+			errs = append(errs, fmt.Errorf(`variant "%s": %v`, variant.ID, err))
+		}
+	}
+	return errs
+}
+
+func (v *Validator) validateData(dataType stored_requests.StoredDataType, id string, data json.RawMessage) []error {
+	// the values of templated stored requests and imps are only known when they're used
+	if (dataType == stored_requests.StoredRequestType || dataType == stored_requests.StoredImpType) && stored_requests.IsTemplate(data) {
+		if err := stored_requests.ValidateTemplate(data); err != nil {
+			return []error{err}
+		}
+		return nil
+	}
+
+	switch dataType {
+	case stored_requests.StoredRequestType:
+		return v.validateRequest(data)
+	case stored_requests.StoredImpType:
+		return v.validateImp(id, data)
+	case stored_requests.StoredAccountType:
+		return v.validateAccount(data)
+	}
+	// stored responses are returned as is, so they only need to be valid JSON
+	return nil
+}
+
+// validateRequest checks the fields of the stored request. The fields it leaves out, such as the ID or the imps,
+// are filled by the incoming request, so they aren't required.
+func (v *Validator) validateRequest(data json.RawMessage) []error {
+	var request openrtb2.BidRequest
+	if err := jsonutil.UnmarshalValid(data, &request); err != nil {
+		return []error{err}
+	}
+	requestWrapper := &openrtb_ext.RequestWrapper{BidRequest: &request}
+
+	var errs []error
+	if err := ortb.ValidateTMax(request.TMax); err != nil {
+		errs = append(errs, err)
+	}
+	// the inventory may be left out for the incoming request to fill
+	if err := ortb.ValidateInventoryType(requestWrapper, false); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, validateExts(requestWrapper)...)
+
+	requestExt, err := requestWrapper.GetRequestExt()
+	if err != nil {
+		return append(errs, fmt.Errorf("request.ext is invalid: %v", err))
+	}
+	var aliases map[string]string
+	if prebid := requestExt.GetPrebid(); prebid != nil {
+		aliases = prebid.Aliases
+		errs = append(errs, v.validateRequestPrebid(prebid)...)
+	}
+
+	for i, imp := range requestWrapper.GetImp() {
+		if isMergedAtRuntime(imp) {
+			continue
+		}
+		errs = append(errs, v.validateImpWrapper(imp, i, aliases)...)
+	}
+	return errs
+}
+
+// validateExts checks the exts of the request objects can be read by the endpoints.
+func validateExts(requestWrapper *openrtb_ext.RequestWrapper) []error {
+	var errs []error
+	exts := []struct {
+		field string
+		get   func() error
+	}{
+		{"request.site.ext", func() error { _, err := requestWrapper.GetSiteExt(); return err }},
+		{"request.app.ext", func() error { _, err := requestWrapper.GetAppExt(); return err }},
+		{"request.dooh.ext", func() error { _, err := requestWrapper.GetDOOHExt(); return err }},
+		{"request.user.ext", func() error { _, err := requestWrapper.GetUserExt(); return err }},
+		{"request.device.ext", func() error { _, err := requestWrapper.GetDeviceExt(); return err }},
+		{"request.regs.ext", func() error { _, err := requestWrapper.GetRegExt(); return err }},
+		{"request.source.ext", func() error { _, err := requestWrapper.GetSourceExt(); return err }},
+	}
+	for _, ext := range exts {
+		if err := ext.get(); err != nil {
+			errs = append(errs, fmt.Errorf("%s is invalid: %v", ext.field, err))
+		}
+	}
+	return errs
+}
+
+// validateRequestPrebid checks the aliases, bid adjustment factors and currency rates, which don't depend on the
+// incoming request.
+func (v *Validator) validateRequestPrebid(prebid *openrtb_ext.ExtRequestPrebid) []error {
+	var errs []error
+	if err := v.requestValidator.ValidateAliases(prebid.Aliases); err != nil {
+		errs = append(errs, err)
+	}
+	if err := v.requestValidator.ValidateBidAdjustmentFactors(prebid.BidAdjustmentFactors, prebid.Aliases); err != nil {
+		errs = append(errs, err)
+	}
+	if err := currency.ValidateCustomRates(prebid.CurrencyConversions); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func (v *Validator) validateImp(id string, data json.RawMessage) []error {
+	var imp openrtb2.Imp
+	if err := jsonutil.UnmarshalValid(data, &imp); err != nil {
+		return []error{err}
+	}
+	// stored imps usually leave out the ID, which is taken from the imp of the incoming request
+	if imp.ID == "" {
+		imp.ID = id
+	}
+	return v.validateImpWrapper(&openrtb_ext.ImpWrapper{Imp: &imp}, 0, nil)
+}
+
+func (v *Validator) validateImpWrapper(imp *openrtb_ext.ImpWrapper, index int, aliases map[string]string) []error {
+	errs := v.requestValidator.ValidateImp(imp, ortb.ValidationConfig{}, index, aliases, false, nil)
+	// disabled bidders are only warned about, as they may be enabled again
+	return errortypes.FatalOnly(errs)
+}
+
+// isMergedAtRuntime returns true if the imp is completed with a stored imp or a stored response
+// when the stored request is used, so it can't be validated on its own.
+func isMergedAtRuntime(imp *openrtb_ext.ImpWrapper) bool {
+	impExt, err := imp.GetImpExt()
+	if err != nil {
+		return false
+	}
+	prebid := impExt.GetPrebid()
+	return prebid != nil && (prebid.StoredRequest != nil || prebid.StoredAuctionResponse != nil || len(prebid.StoredBidResponse) > 0)
+}
+
+func (v *Validator) validateAccount(data json.RawMessage) []error {
+	merged, err := stored_requests.MergeAccountDefaults(v.accountDefaults, data)
+	if err != nil {
+		return []error{err}
+	}
+	var account config.Account
+	if err := jsonutil.UnmarshalValid(merged, &account); err != nil {
+		return []error{err}
+	}
+	return account.Validate(nil)
+}
@@ -0,0 +1,184 @@
+package admin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/ortb"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestValidator(t *testing.T) *Validator {
+	paramsValidator, err := openrtb_ext.NewBidderParamsValidator("../../../static/bidder-params")
+	require.NoError(t, err)
+	requestValidator := ortb.NewRequestValidator(
+		map[string]openrtb_ext.BidderName{"appnexus": openrtb_ext.BidderAppnexus},
+		map[string]string{"oldbidder": "oldbidder is disabled"},
+		paramsValidator)
+	accountDefaults := json.RawMessage(`{"price_floors":{"enforce_floors_rate":100,"fetch":{"period_sec":3600,"max_age_sec":86400,"timeout_ms":3000}}}`)
+	return NewValidator(requestValidator, accountDefaults)
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		description    string
+		dataType       stored_requests.StoredDataType
+		data           string
+		expectedErrors int
+	}{
+		{
+			description: "valid-imp",
+			dataType:    stored_requests.StoredImpType,
+			data:        `{"banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placement_id":12883451}}}}}`,
+		},
+		{
+			description: "valid-imp-disabled-bidder",
+			dataType:    stored_requests.StoredImpType,
+			data:        `{"banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placement_id":1},"oldbidder":{}}}}}`,
+		},
+		{
+			description:    "imp-invalid-bidder-params",
+			dataType:       stored_requests.StoredImpType,
+			data:           `{"banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placement_id":true}}}}}`,
+			expectedErrors: 1,
+		},
+		{
+			description:    "imp-unknown-bidder",
+			dataType:       stored_requests.StoredImpType,
+			data:           `{"banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"unknown":{}}}}}`,
+			expectedErrors: 1,
+		},
+		{
+			description:    "imp-no-media-type",
+			dataType:       stored_requests.StoredImpType,
+			data:           `{"ext":{"prebid":{"bidder":{"appnexus":{"placement_id":1}}}}}`,
+			expectedErrors: 1,
+		},
+		{
+			description: "valid-request-with-alias",
+			dataType:    stored_requests.StoredRequestType,
+			data:        `{"id":"req1","imp":[{"id":"imp1","banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"anx":{"placement_id":1}}}}}],"ext":{"prebid":{"aliases":{"anx":"appnexus"}}}}`,
+		},
+		{
+			description: "valid-request-with-stored-imp",
+			dataType:    stored_requests.StoredRequestType,
+			data:        `{"id":"req1","imp":[{"id":"imp1","ext":{"prebid":{"storedrequest":{"id":"imp1"}}}}]}`,
+		},
+		{
+			description:    "request-invalid-imps",
+			dataType:       stored_requests.StoredRequestType,
+			data:           `{"id":"req1","imp":[{"id":"imp1"},{"id":"imp2","banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placement_id":true}}}}}]}`,
+			expectedErrors: 2,
+		},
+		{
+			description: "valid-request-without-imps",
+			dataType:    stored_requests.StoredRequestType,
+			data:        `{"site":{"page":"https://example.com"},"user":{"ext":{"consent":"abc"}},"ext":{"prebid":{"bidadjustmentfactors":{"appnexus":0.9},"currency":{"rates":{"USD":{"EUR":0.9}}}}}}`,
+		},
+		{
+			description:    "request-invalid-fields",
+			dataType:       stored_requests.StoredRequestType,
+			data:           `{"tmax":-1,"site":{"page":"https://example.com"},"app":{"bundle":"com.example"}}`,
+			expectedErrors: 2,
+		},
+		{
+			description:    "request-invalid-exts",
+			dataType:       stored_requests.StoredRequestType,
+			data:           `{"user":{"ext":{"eids":"none"}},"regs":{"ext":{"gdpr":"yes"}}}`,
+			expectedErrors: 2,
+		},
+		{
+			description:    "request-invalid-ext",
+			dataType:       stored_requests.StoredRequestType,
+			data:           `{"ext":{"prebid":{"targeting":"all"}}}`,
+			expectedErrors: 1,
+		},
+		{
+			description:    "request-invalid-prebid",
+			dataType:       stored_requests.StoredRequestType,
+			data:           `{"ext":{"prebid":{"aliases":{"anx":"unknown"},"bidadjustmentfactors":{"appnexus":0},"currency":{"rates":{"USD":{"XYZW":1}}}}}}`,
+			expectedErrors: 3,
+		},
+		{
+			description:    "request-noop-alias",
+			dataType:       stored_requests.StoredRequestType,
+			data:           `{"ext":{"prebid":{"aliases":{"appnexus":"appnexus"}}}}`,
+			expectedErrors: 1,
+		},
+		{
+			description: "valid-templated-imp",
+			dataType:    stored_requests.StoredImpType,
+			data:        `{"banner":{"format":[{"w":"{{params.w:int=300}}","h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placement_id":"{{params.placement:int}}"}}}}}`,
+		},
+		{
+			description:    "templated-request-invalid-placeholder",
+			dataType:       stored_requests.StoredRequestType,
+			data:           `{"id":"req1","tmax":"{{params.tmax:integer}}"}`,
+			expectedErrors: 1,
+		},
+		{
+			description:    "request-wrong-type",
+			dataType:       stored_requests.StoredRequestType,
+			data:           `{"imp":{}}`,
+			expectedErrors: 1,
+		},
+		{
+			description:    "not-json",
+			dataType:       stored_requests.StoredResponseType,
+			data:           `{"seatbid":`,
+			expectedErrors: 1,
+		},
+		{
+			description: "valid-response",
+			dataType:    stored_requests.StoredResponseType,
+			data:        `{"variants":"are not special in responses"}`,
+		},
+		{
+			description: "valid-account",
+			dataType:    stored_requests.StoredAccountType,
+			data:        `{"disabled":false,"privacy":{"ipv6":{"anon_keep_bits":56}}}`,
+		},
+		{
+			description:    "account-invalid-values",
+			dataType:       stored_requests.StoredAccountType,
+			data:           `{"price_floors":{"enforce_floors_rate":101},"privacy":{"ipv4":{"anon_keep_bits":33}}}`,
+			expectedErrors: 2,
+		},
+		{
+			description:    "account-wrong-type",
+			dataType:       stored_requests.StoredAccountType,
+			data:           `{"disabled":"no"}`,
+			expectedErrors: 1,
+		},
+		{
+			description:    "versioned-imp-invalid-variant",
+			dataType:       stored_requests.StoredImpType,
+			data:           `{"variants":[{"id":"v1","weight":1,"data":{"banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placement_id":1}}}}}},{"id":"v2","weight":1,"data":{}}]}`,
+			expectedErrors: 1,
+		},
+		{
+			description:    "versioned-invalid-weights",
+			dataType:       stored_requests.StoredAccountType,
+			data:           `{"variants":[{"id":"v1","weight":0,"data":{}}]}`,
+			expectedErrors: 1,
+		},
+	}
+
+	validator := newTestValidator(t)
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			errs := validator.Validate(test.dataType, "id1", json.RawMessage(test.data))
+			assert.Len(t, errs, test.expectedErrors, "%v", errs)
+		})
+	}
+}
+
+func TestValidateVariantErrorMessage(t *testing.T) {
+	validator := newTestValidator(t)
+	errs := validator.Validate(stored_requests.StoredAccountType, "acct1", json.RawMessage(`{"variants":[{"id":"v1","weight":1,"data":{"privacy":{"ipv4":{"anon_keep_bits":33}}}}]}`))
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), `variant "v1": `)
+}
@@ -0,0 +1,246 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_provider"
+	"github.com/prebid/prebid-server/v3/stored_requests/events"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+)
+
+type ChangeLogEventProducerConfig struct {
+	Provider    db_provider.DbProvider
+	RequestType config.DataType
+	Query       string
+	// LastSequenceQuery returns the sequence of the last change logged, where the first run starts.
+	LastSequenceQuery string
+	Timeout           time.Duration
+	MetricsEngine     metrics.MetricsEngine
+}
+
+// ChangeLogEventProducer sends the stored data changes read from a change log table, in the order they
+// were made. It's meant for databases without notifications, such as MySQL, where the table is filled
+// by triggers in the same transactions as the changes.
+//
+// The rows returned by the query are (seq, id, data, type, changed_at), where seq is an increasing
+// integer, data is NULL for deleted stored data and changed_at is the time of the change in milliseconds
+// since the epoch, or NULL if unknown. The accounts are invalidated whether they were saved or deleted, as
+// the caches hold them merged with the account defaults.
+type ChangeLogEventProducer struct {
+	cfg           ChangeLogEventProducerConfig
+	lastSequence  int64
+	started       bool
+	invalidations chan events.Invalidation
+	saves         chan events.Save
+	time          timeutil.Time
+}
+
+func NewChangeLogEventProducer(cfg ChangeLogEventProducerConfig) *ChangeLogEventProducer {
+	if cfg.Provider == nil {
+		glog.Fatalf("The Database Stored %s Change Log needs a database connection to work.", cfg.RequestType)
+	}
+
+	return &ChangeLogEventProducer{
+		cfg:           cfg,
+		saves:         make(chan events.Save, 1),
+		invalidations: make(chan events.Invalidation, 1),
+		time:          &timeutil.RealTime{},
+	}
+}
+
+// Run reads the change log until the last change. The first run only reads the sequence of the last change,
+// as the caches are initialized from the stored data tables.
+func (e *ChangeLogEventProducer) Run() error {
+	if !e.started {
+		lastSequence, err := e.fetchLastSequence()
+		if err != nil {
+			return err
+		}
+		e.lastSequence = lastSequence
+		e.started = true
+		return nil
+	}
+	for {
+		count, err := e.fetchChanges()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+	}
+}
+
+func (e *ChangeLogEventProducer) Saves() <-chan events.Save {
+	return e.saves
+}
+
+func (e *ChangeLogEventProducer) Invalidations() <-chan events.Invalidation {
+	return e.invalidations
+}
+
+// fetchLastSequence returns the sequence of the last change logged, or 0 if the change log is empty.
+func (e *ChangeLogEventProducer) fetchLastSequence() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.Timeout)
+	defer cancel()
+
+	var lastSequence sql.NullInt64
+	rows, err := e.cfg.Provider.QueryContext(ctx, e.cfg.LastSequenceQuery)
+	if err == nil {
+		if rows.Next() {
+			err = rows.Scan(&lastSequence)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		if closeErr := rows.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		glog.Warningf("Failed to fetch the last Stored %s change log sequence from the DB: %v", e.cfg.RequestType, err)
+		if _, ok := err.(net.Error); ok {
+			e.recordError(metrics.StoredDataErrorNetwork)
+		} else {
+			e.recordError(metrics.StoredDataErrorUndefined)
+		}
+		return 0, err
+	}
+	return lastSequence.Int64, nil
+}
+
+// fetchChanges sends the events for the next changes, and returns the number of changes read.
+func (e *ChangeLogEventProducer) fetchChanges() (count int, fetchErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.Timeout)
+	defer cancel()
+
+	startTime := e.time.Now().UTC()
+	params := []db_provider.QueryParam{
+		{Name: "LAST_SEQUENCE", Value: e.lastSequence},
+	}
+	rows, err := e.cfg.Provider.QueryContext(ctx, e.cfg.Query, params...)
+	e.cfg.MetricsEngine.RecordStoredDataFetchTime(
+		metrics.StoredDataLabels{
+			DataType:      storedDataTypeMetricMap[e.cfg.RequestType],
+			DataFetchType: metrics.FetchDelta,
+		}, time.Since(startTime))
+
+	if err != nil {
+		glog.Warningf("Failed to fetch the Stored %s change log from the DB: %v", e.cfg.RequestType, err)
+		if _, ok := err.(net.Error); ok {
+			e.recordError(metrics.StoredDataErrorNetwork)
+		} else {
+			e.recordError(metrics.StoredDataErrorUndefined)
+		}
+		return 0, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			glog.Warningf("Failed to close the Stored %s DB connection: %v", e.cfg.RequestType, err)
+			e.recordError(metrics.StoredDataErrorUndefined)
+			fetchErr = err
+		}
+	}()
+	count, err = e.sendEvents(rows)
+	if err != nil {
+		glog.Warningf("Failed to load the Stored %s change log from the DB: %v", e.cfg.RequestType, err)
+		e.recordError(metrics.StoredDataErrorUndefined)
+		return 0, err
+	}
+	return count, nil
+}
+
+// sendEvents reads the changes and sends the events for the last change of each stored data.
+// If it returns an error, then no events were sent and the position in the change log is unchanged.
+func (e *ChangeLogEventProducer) sendEvents(rows *sql.Rows) (int, error) {
+	saves := map[string]map[string]json.RawMessage{"request": {}, "imp": {}, "response": {}}
+	invalidations := map[string]map[string]struct{}{"request": {}, "imp": {}, "response": {}, "account": {}}
+	var lags []time.Duration
+
+	count := 0
+	lastSequence := e.lastSequence
+	now := e.time.Now()
+	for rows.Next() {
+		var sequence int64
+		var id string
+		var data []byte
+		var dataType string
+		var changedAt sql.NullInt64
+
+		if err := rows.Scan(&sequence, &id, &data, &dataType, &changedAt); err != nil {
+			return 0, err
+		}
+		count++
+		if sequence > lastSequence {
+			lastSequence = sequence
+		}
+		if _, ok := invalidations[dataType]; !ok {
+			glog.Warningf("Stored Data change with id=%s has invalid type: %s. This will be ignored.", id, dataType)
+			continue
+		}
+
+		if dataType == "account" || len(data) == 0 || bytes.Equal(data, bytesNull()) {
+			delete(saves[dataType], id)
+			invalidations[dataType][id] = struct{}{}
+		} else {
+			delete(invalidations[dataType], id)
+			saves[dataType][id] = data
+		}
+		if changedAt.Valid {
+			lags = append(lags, now.Sub(time.UnixMilli(changedAt.Int64)))
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	e.lastSequence = lastSequence
+
+	if len(saves["request"]) > 0 || len(saves["imp"]) > 0 || len(saves["response"]) > 0 {
+		e.saves <- events.Save{
+			Requests:  saves["request"],
+			Imps:      saves["imp"],
+			Responses: saves["response"],
+		}
+	}
+	if len(invalidations["request"]) > 0 || len(invalidations["imp"]) > 0 || len(invalidations["response"]) > 0 || len(invalidations["account"]) > 0 {
+		e.invalidations <- events.Invalidation{
+			Requests:  keys(invalidations["request"]),
+			Imps:      keys(invalidations["imp"]),
+			Responses: keys(invalidations["response"]),
+			Accounts:  keys(invalidations["account"]),
+		}
+	}
+	for _, lag := range lags {
+		recordEventLag(e.cfg.MetricsEngine, e.cfg.RequestType, lag)
+	}
+	return count, nil
+}
+
+func (e *ChangeLogEventProducer) recordError(errorType metrics.StoredDataError) {
+	e.cfg.MetricsEngine.RecordStoredDataError(
+		metrics.StoredDataLabels{
+			DataType: storedDataTypeMetricMap[e.cfg.RequestType],
+			Error:    errorType,
+		})
+}
+
+func keys(set map[string]struct{}) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,190 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_provider"
+	"github.com/prebid/prebid-server/v3/stored_requests/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	fakeChangeLogQuery    = "SELECT seq, id, data, type, changed_at FROM stored_data_changes WHERE seq > $1"
+	fakeLastSequenceQuery = "SELECT MAX(seq) FROM stored_data_changes"
+)
+
+var changeLogColumns = []string{"seq", "id", "data", "type", "changed_at"}
+
+func fakeChangeLogQueryRegex() string {
+	return "^" + regexp.QuoteMeta(fakeChangeLogQuery) + "$"
+}
+
+func fakeLastSequenceQueryRegex() string {
+	return "^" + regexp.QuoteMeta(fakeLastSequenceQuery) + "$"
+}
+
+func newTestChangeLogEventProducer(provider db_provider.DbProvider, metricsEngine metrics.MetricsEngine) *ChangeLogEventProducer {
+	producer := NewChangeLogEventProducer(ChangeLogEventProducerConfig{
+		Provider:          provider,
+		RequestType:       config.RequestDataType,
+		Query:             fakeChangeLogQuery,
+		LastSequenceQuery: fakeLastSequenceQuery,
+		Timeout:           100 * time.Millisecond,
+		MetricsEngine:     metricsEngine,
+	})
+	producer.time = &FakeTime{time: time.UnixMilli(10000)}
+	return producer
+}
+
+func TestChangeLogStartsFromLastSequence(t *testing.T) {
+	tests := []struct {
+		description      string
+		giveLastSequence interface{}
+		wantLastSequence int64
+	}{
+		{
+			description:      "changes logged",
+			giveLastSequence: 42,
+			wantLastSequence: 42,
+		},
+		{
+			description:      "empty change log",
+			giveLastSequence: nil,
+			wantLastSequence: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			provider, dbMock, _ := db_provider.NewDbProviderMock()
+			dbMock.ExpectQuery(fakeLastSequenceQueryRegex()).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(tt.giveLastSequence))
+			dbMock.ExpectQuery(fakeChangeLogQueryRegex()).WithArgs(tt.wantLastSequence).WillReturnRows(sqlmock.NewRows(changeLogColumns))
+
+			metricsMock := &metrics.MetricsEngineMock{}
+			metricsMock.On("RecordStoredDataFetchTime", metrics.StoredDataLabels{DataType: metrics.RequestDataType, DataFetchType: metrics.FetchDelta}, mock.Anything).Return()
+
+			producer := newTestChangeLogEventProducer(provider, metricsMock)
+			// the first run doesn't read the changes logged before
+			assert.NoError(t, producer.Run())
+			assert.Equal(t, tt.wantLastSequence, producer.lastSequence)
+			assert.True(t, producer.started)
+
+			assert.NoError(t, producer.Run())
+			assert.Empty(t, producer.Saves())
+			assert.Empty(t, producer.Invalidations())
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestChangeLogLastSequenceError(t *testing.T) {
+	provider, dbMock, _ := db_provider.NewDbProviderMock()
+	dbMock.ExpectQuery(fakeLastSequenceQueryRegex()).WillReturnError(errors.New("db error"))
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordStoredDataError", metrics.StoredDataLabels{DataType: metrics.RequestDataType, Error: metrics.StoredDataErrorUndefined}).Return()
+
+	producer := newTestChangeLogEventProducer(provider, metricsMock)
+	assert.Error(t, producer.Run())
+	assert.False(t, producer.started, "the next run must read the last sequence again")
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+	metricsMock.AssertExpectations(t)
+}
+
+func TestChangeLogSendsLastChanges(t *testing.T) {
+	provider, dbMock, _ := db_provider.NewDbProviderMock()
+	dbMock.ExpectQuery(fakeChangeLogQueryRegex()).WithArgs(int64(5)).WillReturnRows(sqlmock.NewRows(changeLogColumns).
+		AddRow(6, "req-1", `{"v":1}`, "request", 9000).
+		AddRow(7, "req-1", nil, "request", 9000).
+		AddRow(8, "req-2", nil, "request", 9000).
+		AddRow(9, "req-2", `{"v":2}`, "request", 9500).
+		AddRow(10, "imp-1", "", "imp", nil).
+		AddRow(11, "resp-1", `{"v":3}`, "response", 9000).
+		AddRow(12, "cat-1", `{}`, "category", 9000).
+		AddRow(13, "acct-1", `{"disabled":true}`, "account", nil))
+	dbMock.ExpectQuery(fakeChangeLogQueryRegex()).WithArgs(int64(13)).WillReturnRows(sqlmock.NewRows(changeLogColumns))
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordStoredDataFetchTime", mock.Anything, mock.Anything).Return()
+	metricsMock.On("RecordStoredDataEventLag", metrics.StoredDataLabels{DataType: metrics.RequestDataType}, time.Second).Return().Times(4)
+	metricsMock.On("RecordStoredDataEventLag", metrics.StoredDataLabels{DataType: metrics.RequestDataType}, 500*time.Millisecond).Return().Once()
+
+	producer := newTestChangeLogEventProducer(provider, metricsMock)
+	producer.lastSequence = 5
+	producer.started = true
+	assert.NoError(t, producer.Run())
+	assert.Equal(t, int64(13), producer.lastSequence)
+
+	assert.Equal(t, events.Save{
+		Requests:  map[string]json.RawMessage{"req-2": json.RawMessage(`{"v":2}`)},
+		Imps:      map[string]json.RawMessage{},
+		Responses: map[string]json.RawMessage{"resp-1": json.RawMessage(`{"v":3}`)},
+	}, <-producer.Saves())
+	assert.Equal(t, events.Invalidation{
+		Requests: []string{"req-1"},
+		Imps:     []string{"imp-1"},
+		Accounts: []string{"acct-1"},
+	}, <-producer.Invalidations())
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+	metricsMock.AssertExpectations(t)
+}
+
+func TestChangeLogErrors(t *testing.T) {
+	tests := []struct {
+		description   string
+		giveMockRows  *sqlmock.Rows
+		giveQueryErr  error
+		wantErrorType metrics.StoredDataError
+	}{
+		{
+			description:   "query failed",
+			giveQueryErr:  errors.New("db error"),
+			wantErrorType: metrics.StoredDataErrorUndefined,
+		},
+		{
+			description:   "corrupted row",
+			giveMockRows:  sqlmock.NewRows(changeLogColumns).AddRow("not a sequence", "req-1", "{}", "request", nil),
+			wantErrorType: metrics.StoredDataErrorUndefined,
+		},
+		{
+			description: "rows error",
+			giveMockRows: sqlmock.NewRows(changeLogColumns).
+				AddRow(6, "req-1", "{}", "request", nil).
+				RowError(0, errors.New("row error")),
+			wantErrorType: metrics.StoredDataErrorUndefined,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			provider, dbMock, _ := db_provider.NewDbProviderMock()
+			if tt.giveQueryErr != nil {
+				dbMock.ExpectQuery(fakeChangeLogQueryRegex()).WillReturnError(tt.giveQueryErr)
+			} else {
+				dbMock.ExpectQuery(fakeChangeLogQueryRegex()).WillReturnRows(tt.giveMockRows)
+			}
+
+			metricsMock := &metrics.MetricsEngineMock{}
+			metricsMock.On("RecordStoredDataFetchTime", mock.Anything, mock.Anything).Return()
+			metricsMock.On("RecordStoredDataError", metrics.StoredDataLabels{DataType: metrics.RequestDataType, Error: tt.wantErrorType}).Return()
+
+			producer := newTestChangeLogEventProducer(provider, metricsMock)
+			producer.lastSequence = 5
+			producer.started = true
+
+			assert.Error(t, producer.Run())
+			assert.Equal(t, int64(5), producer.lastSequence, "the position must not change")
+			assert.Empty(t, producer.Saves())
+			assert.Empty(t, producer.Invalidations())
+			metricsMock.AssertExpectations(t)
+		})
+	}
+}
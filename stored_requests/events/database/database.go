@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"net"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/glog"
@@ -45,6 +46,7 @@ type DatabaseEventProducer struct {
 	invalidations chan events.Invalidation
 	saves         chan events.Save
 	time          timeutil.Time
+	loaded        atomic.Bool
 }
 
 func NewDatabaseEventProducer(cfg DatabaseEventProducerConfig) (eventProducer *DatabaseEventProducer) {
@@ -69,6 +71,11 @@ func (e *DatabaseEventProducer) Run() error {
 	return e.fetchDelta()
 }
 
+// Loaded returns true once all the data has been fetched from the DB.
+func (e *DatabaseEventProducer) Loaded() bool {
+	return e.loaded.Load()
+}
+
 func (e *DatabaseEventProducer) Saves() <-chan events.Save {
 	return e.saves
 }
@@ -111,6 +118,7 @@ func (e *DatabaseEventProducer) fetchAll() (fetchErr error) {
 	}
 
 	e.lastUpdate = startTime
+	e.loaded.Store(true)
 	return nil
 }
 
@@ -0,0 +1,254 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/lib/pq"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_provider"
+	"github.com/prebid/prebid-server/v3/stored_requests/events"
+	"github.com/prebid/prebid-server/v3/util/task"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+)
+
+// pingInterval is how often the listening connection is checked, as a lost connection
+// may otherwise go unnoticed until the next notification.
+const pingInterval = 90 * time.Second
+
+// notificationListener is the part of *pq.Listener used by the NotificationEventProducer.
+type notificationListener interface {
+	Listen(channel string) error
+	NotificationChannel() <-chan *pq.Notification
+	Ping() error
+	Close() error
+}
+
+// notification is the payload of the notifications sent to the channel.
+type notification struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+type NotificationEventProducerConfig struct {
+	Provider             db_provider.DbProvider
+	RequestType          config.DataType
+	Channel              string
+	MinReconnectInterval time.Duration
+	MaxReconnectInterval time.Duration
+	// Fallback catches up with the changes made while no notification could be received. It's run once when
+	// the producer starts, again once listening, after each reconnection and every FallbackInterval, if positive.
+	// This is usually the DatabaseEventProducer, which must not be run by anything else.
+	Fallback         task.Runner
+	FallbackInterval time.Duration
+	MetricsEngine    metrics.MetricsEngine
+}
+
+// NotificationEventProducer sends the stored data changes as soon as they're notified by Postgres
+// on a LISTEN/NOTIFY channel.
+type NotificationEventProducer struct {
+	cfg           NotificationEventProducerConfig
+	listener      notificationListener
+	invalidations chan events.Invalidation
+	saves         chan events.Save
+	done          chan struct{}
+	closeOnce     sync.Once
+	time          timeutil.Time
+}
+
+func NewNotificationEventProducer(cfg NotificationEventProducerConfig) *NotificationEventProducer {
+	if cfg.Provider == nil {
+		glog.Fatalf("The Database Stored %s Notifications need a database connection to work.", cfg.RequestType)
+	}
+	connStr, err := cfg.Provider.ConnString()
+	if err != nil {
+		glog.Fatalf("Failed to build the connection string for the Stored %s Notifications: %v", cfg.RequestType, err)
+	}
+
+	producer := newNotificationEventProducer(cfg)
+	producer.listener = pq.NewListener(connStr, cfg.MinReconnectInterval, cfg.MaxReconnectInterval, producer.onListenerEvent)
+	return producer
+}
+
+func newNotificationEventProducer(cfg NotificationEventProducerConfig) *NotificationEventProducer {
+	return &NotificationEventProducer{
+		cfg:           cfg,
+		saves:         make(chan events.Save, 1),
+		invalidations: make(chan events.Invalidation, 1),
+		done:          make(chan struct{}),
+		time:          &timeutil.RealTime{},
+	}
+}
+
+// Start runs the fallback once, so the caches are initialized before it returns, then listens
+// to the notifications in the background until Close is called.
+func (e *NotificationEventProducer) Start() {
+	if e.cfg.Fallback != nil {
+		e.cfg.Fallback.Run()
+	}
+	go e.run()
+}
+
+// Close stops listening to the notifications.
+func (e *NotificationEventProducer) Close() error {
+	var err error
+	e.closeOnce.Do(func() {
+		close(e.done)
+		err = e.listener.Close()
+	})
+	return err
+}
+
+func (e *NotificationEventProducer) Saves() <-chan events.Save {
+	return e.saves
+}
+
+func (e *NotificationEventProducer) Invalidations() <-chan events.Invalidation {
+	return e.invalidations
+}
+
+func (e *NotificationEventProducer) run() {
+	// Listen blocks until the database acknowledges it, so the fallback then covers every change
+	// made since it was last run.
+	if err := e.listener.Listen(e.cfg.Channel); err != nil {
+		glog.Errorf("Failed to listen to the Stored %s notifications on channel %s: %v", e.cfg.RequestType, e.cfg.Channel, err)
+		return
+	}
+	e.runFallback()
+
+	var fallbackTicks <-chan time.Time
+	if e.cfg.Fallback != nil && e.cfg.FallbackInterval > 0 {
+		ticker := time.NewTicker(e.cfg.FallbackInterval)
+		defer ticker.Stop()
+		fallbackTicks = ticker.C
+	}
+	pingTicker := time.NewTicker(pingInterval)
+	defer pingTicker.Stop()
+
+	notifications := e.listener.NotificationChannel()
+	for {
+		select {
+		case n, ok := <-notifications:
+			if !ok {
+				return
+			}
+			// a nil notification is sent after reconnecting, as the notifications sent in between were lost
+			if n == nil {
+				e.runFallback()
+				continue
+			}
+			e.handleNotification(n.Extra)
+		case <-fallbackTicks:
+			e.runFallback()
+		case <-pingTicker.C:
+			// a failed ping closes the connection, which makes the listener reconnect
+			go e.listener.Ping()
+		case <-e.done:
+			return
+		}
+	}
+}
+
+func (e *NotificationEventProducer) runFallback() {
+	if e.cfg.Fallback != nil {
+		e.cfg.Fallback.Run()
+	}
+}
+
+func (e *NotificationEventProducer) onListenerEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventDisconnected:
+		glog.Warningf("Lost the connection listening to the Stored %s notifications: %v", e.cfg.RequestType, err)
+		e.recordError(metrics.StoredDataErrorNetwork)
+	case pq.ListenerEventConnectionAttemptFailed:
+		glog.Warningf("Failed to connect to listen to the Stored %s notifications: %v", e.cfg.RequestType, err)
+		e.recordError(metrics.StoredDataErrorNetwork)
+	case pq.ListenerEventReconnected:
+		glog.Infof("Listening to the Stored %s notifications again", e.cfg.RequestType)
+	}
+}
+
+func (e *NotificationEventProducer) handleNotification(payload string) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil || n.ID == "" {
+		glog.Warningf("Stored %s notification %q is malformed. This will be ignored.", e.cfg.RequestType, payload)
+		e.recordError(metrics.StoredDataErrorMalformed)
+		return
+	}
+
+	var save *events.Save
+	var invalidation *events.Invalidation
+	deleted := len(n.Data) == 0 || bytes.Equal(n.Data, bytesNull())
+	switch n.Type {
+	case "request":
+		if deleted {
+			invalidation = &events.Invalidation{Requests: []string{n.ID}}
+		} else {
+			save = &events.Save{Requests: map[string]json.RawMessage{n.ID: n.Data}}
+		}
+	case "imp":
+		if deleted {
+			invalidation = &events.Invalidation{Imps: []string{n.ID}}
+		} else {
+			save = &events.Save{Imps: map[string]json.RawMessage{n.ID: n.Data}}
+		}
+	case "response":
+		if deleted {
+			invalidation = &events.Invalidation{Responses: []string{n.ID}}
+		} else {
+			save = &events.Save{Responses: map[string]json.RawMessage{n.ID: n.Data}}
+		}
+	case "account":
+		// the caches hold the accounts merged with the account defaults, so they're fetched again even when saved
+		invalidation = &events.Invalidation{Accounts: []string{n.ID}}
+	default:
+		glog.Warningf("Stored Data notification with id=%s has invalid type: %s. This will be ignored.", n.ID, n.Type)
+		return
+	}
+
+	// the events are dropped once the producer is closed, as nothing receives them anymore
+	if save != nil {
+		select {
+		case e.saves <- *save:
+		case <-e.done:
+			return
+		}
+	}
+	if invalidation != nil {
+		select {
+		case e.invalidations <- *invalidation:
+		case <-e.done:
+			return
+		}
+	}
+
+	if !n.Timestamp.IsZero() {
+		recordEventLag(e.cfg.MetricsEngine, e.cfg.RequestType, e.time.Now().Sub(n.Timestamp))
+	}
+}
+
+func (e *NotificationEventProducer) recordError(errorType metrics.StoredDataError) {
+	e.cfg.MetricsEngine.RecordStoredDataError(
+		metrics.StoredDataLabels{
+			DataType: storedDataTypeMetricMap[e.cfg.RequestType],
+			Error:    errorType,
+		})
+}
+
+// recordEventLag records the time between a change of the stored data and the event sent for it.
+func recordEventLag(metricsEngine metrics.MetricsEngine, requestType config.DataType, lag time.Duration) {
+	// the database and the server clocks may be slightly apart
+	if lag < 0 {
+		lag = 0
+	}
+	metricsEngine.RecordStoredDataEventLag(
+		metrics.StoredDataLabels{
+			DataType: storedDataTypeMetricMap[requestType],
+		}, lag)
+}
@@ -0,0 +1,250 @@
+package database
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/stored_requests/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeListener struct {
+	channel       chan string
+	notifications chan *pq.Notification
+}
+
+func newFakeListener() *fakeListener {
+	return &fakeListener{
+		channel:       make(chan string, 1),
+		notifications: make(chan *pq.Notification),
+	}
+}
+
+func (l *fakeListener) Listen(channel string) error {
+	l.channel <- channel
+	return nil
+}
+
+func (l *fakeListener) NotificationChannel() <-chan *pq.Notification {
+	return l.notifications
+}
+
+func (l *fakeListener) Ping() error {
+	return nil
+}
+
+func (l *fakeListener) Close() error {
+	return nil
+}
+
+type countingRunner struct {
+	runs atomic.Int32
+}
+
+func (r *countingRunner) Run() error {
+	r.runs.Add(1)
+	return nil
+}
+
+func TestHandleNotification(t *testing.T) {
+	changeTime := time.Date(2020, time.July, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		description       string
+		givePayload       string
+		wantSave          *events.Save
+		wantInvalidation  *events.Invalidation
+		wantLag           time.Duration
+		wantMalformedData bool
+	}{
+		{
+			description: "saved request",
+			givePayload: `{"id":"req-1","type":"request","data":{"id":"req"},"timestamp":"2020-07-01T12:30:00.000+00:00"}`,
+			wantSave:    &events.Save{Requests: map[string]json.RawMessage{"req-1": json.RawMessage(`{"id":"req"}`)}},
+			wantLag:     2 * time.Second,
+		},
+		{
+			description: "saved imp",
+			givePayload: `{"id":"imp-1","type":"imp","data":{"id":"imp"},"timestamp":"2020-07-01T12:30:00.000+00:00"}`,
+			wantSave:    &events.Save{Imps: map[string]json.RawMessage{"imp-1": json.RawMessage(`{"id":"imp"}`)}},
+			wantLag:     2 * time.Second,
+		},
+		{
+			description: "saved response without timestamp",
+			givePayload: `{"id":"resp-1","type":"response","data":{"id":"resp"}}`,
+			wantSave:    &events.Save{Responses: map[string]json.RawMessage{"resp-1": json.RawMessage(`{"id":"resp"}`)}},
+		},
+		{
+			description:      "deleted request",
+			givePayload:      `{"id":"req-1","type":"request","timestamp":"2020-07-01T12:30:00.000+00:00"}`,
+			wantInvalidation: &events.Invalidation{Requests: []string{"req-1"}},
+			wantLag:          2 * time.Second,
+		},
+		{
+			description:      "imp with null data",
+			givePayload:      `{"id":"imp-1","type":"imp","data":null}`,
+			wantInvalidation: &events.Invalidation{Imps: []string{"imp-1"}},
+		},
+		{
+			description:      "saved account",
+			givePayload:      `{"id":"acct-1","type":"account","data":{"disabled":true},"timestamp":"2020-07-01T12:30:00.000+00:00"}`,
+			wantInvalidation: &events.Invalidation{Accounts: []string{"acct-1"}},
+			wantLag:          2 * time.Second,
+		},
+		{
+			description:      "deleted account",
+			givePayload:      `{"id":"acct-1","type":"account"}`,
+			wantInvalidation: &events.Invalidation{Accounts: []string{"acct-1"}},
+		},
+		{
+			description: "unknown type",
+			givePayload: `{"id":"req-1","type":"category","data":{}}`,
+		},
+		{
+			description:       "malformed payload",
+			givePayload:       `req-1`,
+			wantMalformedData: true,
+		},
+		{
+			description:       "missing id",
+			givePayload:       `{"type":"request","data":{}}`,
+			wantMalformedData: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			metricsMock := &metrics.MetricsEngineMock{}
+			if tt.wantLag > 0 {
+				metricsMock.On("RecordStoredDataEventLag", metrics.StoredDataLabels{DataType: metrics.RequestDataType}, tt.wantLag).Return()
+			}
+			if tt.wantMalformedData {
+				metricsMock.On("RecordStoredDataError", metrics.StoredDataLabels{DataType: metrics.RequestDataType, Error: metrics.StoredDataErrorMalformed}).Return()
+			}
+
+			producer := newNotificationEventProducer(NotificationEventProducerConfig{
+				RequestType:   config.RequestDataType,
+				MetricsEngine: metricsMock,
+			})
+			producer.time = &FakeTime{time: changeTime.Add(2 * time.Second)}
+			producer.handleNotification(tt.givePayload)
+
+			var save *events.Save
+			select {
+			case s := <-producer.Saves():
+				save = &s
+			default:
+			}
+			var invalidation *events.Invalidation
+			select {
+			case i := <-producer.Invalidations():
+				invalidation = &i
+			default:
+			}
+
+			assert.Equal(t, tt.wantSave, save)
+			assert.Equal(t, tt.wantInvalidation, invalidation)
+			metricsMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandleNotificationAfterClose(t *testing.T) {
+	producer := newNotificationEventProducer(NotificationEventProducerConfig{
+		RequestType:   config.RequestDataType,
+		MetricsEngine: &metrics.MetricsEngineMock{},
+	})
+	producer.listener = newFakeListener()
+
+	// fill the buffered channels, so the next sends block until the producer is closed
+	producer.handleNotification(`{"id":"req-1","type":"request","data":{}}`)
+	producer.handleNotification(`{"id":"req-2","type":"request"}`)
+
+	handled := make(chan struct{})
+	go func() {
+		producer.handleNotification(`{"id":"req-3","type":"request","data":{}}`)
+		producer.handleNotification(`{"id":"req-4","type":"request"}`)
+		close(handled)
+	}()
+	assert.NoError(t, producer.Close())
+
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the notifications must be dropped once the producer is closed")
+	}
+}
+
+func TestNotificationEventProducerFallback(t *testing.T) {
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordStoredDataEventLag", mock.Anything, mock.Anything).Return()
+
+	fallback := &countingRunner{}
+	listener := newFakeListener()
+	producer := newNotificationEventProducer(NotificationEventProducerConfig{
+		RequestType:   config.RequestDataType,
+		Channel:       "stored_data",
+		Fallback:      fallback,
+		MetricsEngine: metricsMock,
+	})
+	producer.listener = listener
+
+	producer.Start()
+	assert.Equal(t, int32(1), fallback.runs.Load(), "the fallback must initialize the caches on start")
+
+	assert.Equal(t, "stored_data", <-listener.channel)
+	assert.Eventually(t, func() bool { return fallback.runs.Load() == 2 }, time.Second, time.Millisecond,
+		"the fallback must catch up once listening")
+
+	listener.notifications <- &pq.Notification{Extra: `{"id":"req-1","type":"request","data":{}}`}
+	assert.Equal(t, events.Save{Requests: map[string]json.RawMessage{"req-1": json.RawMessage(`{}`)}}, <-producer.Saves())
+
+	// a nil notification is sent after reconnecting
+	listener.notifications <- nil
+	assert.Eventually(t, func() bool { return fallback.runs.Load() == 3 }, time.Second, time.Millisecond,
+		"the fallback must catch up after reconnecting")
+
+	assert.NoError(t, producer.Close())
+	assert.NoError(t, producer.Close(), "closing again must be a noop")
+}
+
+func TestNotificationEventProducerFallbackInterval(t *testing.T) {
+	fallback := &countingRunner{}
+	listener := newFakeListener()
+	producer := newNotificationEventProducer(NotificationEventProducerConfig{
+		RequestType:      config.RequestDataType,
+		Channel:          "stored_data",
+		Fallback:         fallback,
+		FallbackInterval: time.Millisecond,
+		MetricsEngine:    &metrics.MetricsEngineMock{},
+	})
+	producer.listener = listener
+
+	producer.Start()
+	<-listener.channel
+	assert.Eventually(t, func() bool { return fallback.runs.Load() > 3 }, time.Second, time.Millisecond,
+		"the fallback must be run periodically")
+	producer.Close()
+}
+
+func TestListenerEventsMetrics(t *testing.T) {
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordStoredDataError", metrics.StoredDataLabels{DataType: metrics.AMPDataType, Error: metrics.StoredDataErrorNetwork}).Return()
+
+	producer := newNotificationEventProducer(NotificationEventProducerConfig{
+		RequestType:   config.AMPRequestDataType,
+		MetricsEngine: metricsMock,
+	})
+	producer.onListenerEvent(pq.ListenerEventConnected, nil)
+	producer.onListenerEvent(pq.ListenerEventDisconnected, assert.AnError)
+	producer.onListenerEvent(pq.ListenerEventConnectionAttemptFailed, assert.AnError)
+	producer.onListenerEvent(pq.ListenerEventReconnected, nil)
+
+	metricsMock.AssertNumberOfCalls(t, "RecordStoredDataError", 2)
+}
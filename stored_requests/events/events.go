@@ -3,7 +3,9 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"maps"
 
+	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/v3/stored_requests"
 )
 
@@ -65,8 +67,16 @@ func (e *EventListener) Listen(cache stored_requests.Cache, events EventProducer
 	for {
 		select {
 		case save := <-events.Saves():
-			cache.Requests.Save(context.Background(), save.Requests)
-			cache.Imps.Save(context.Background(), save.Imps)
+			// the invalid templates are left out, so the cache keeps serving the previous data. The maps
+			// are copied since the producers may share them between the data types.
+			requests, imps := maps.Clone(save.Requests), maps.Clone(save.Imps)
+			errs := stored_requests.DropInvalidTemplates("Request", requests, nil)
+			errs = stored_requests.DropInvalidTemplates("Imp", imps, errs)
+			for _, err := range errs {
+				glog.Errorf("%v, keeping the previous data", err)
+			}
+			cache.Requests.Save(context.Background(), requests)
+			cache.Imps.Save(context.Background(), imps)
 			cache.Accounts.Save(context.Background(), save.Accounts)
 			cache.Responses.Save(context.Background(), save.Responses)
 			if e.onSave != nil {
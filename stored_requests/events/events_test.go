@@ -9,6 +9,7 @@ import (
 
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/stored_requests/caches/memory"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestListen(t *testing.T) {
@@ -87,6 +88,33 @@ func TestListen(t *testing.T) {
 	}
 }
 
+func TestListenKeepsPreviousDataOfInvalidTemplates(t *testing.T) {
+	ep := &fakeProducer{
+		saves:         make(chan Save),
+		invalidations: make(chan Invalidation),
+	}
+	cache := stored_requests.Cache{
+		Requests:  memory.NewCache(256*1024, -1, "Requests"),
+		Imps:      memory.NewCache(256*1024, -1, "Imps"),
+		Responses: memory.NewCache(256*1024, -1, "Responses"),
+		Accounts:  memory.NewCache(256*1024, -1, "Account"),
+	}
+	previous := map[string]json.RawMessage{"1": json.RawMessage(`{"tagid":"{{params.slot}}"}`)}
+	cache.Imps.Save(context.Background(), previous)
+
+	saveOccurred := make(chan struct{})
+	listener := NewEventListener(func() { saveOccurred <- struct{}{} }, nil)
+	go listener.Listen(cache, ep)
+	defer listener.Stop()
+
+	data := map[string]json.RawMessage{"1": json.RawMessage(`{"w":"{{params.w:integer}}"}`)}
+	ep.saves <- Save{Imps: data, Accounts: data}
+	<-saveOccurred
+
+	assert.Equal(t, previous, cache.Imps.Get(context.Background(), []string{"1"}))
+	assert.Equal(t, data, cache.Accounts.Get(context.Background(), []string{"1"}), "only the requests and imps are templates")
+}
+
 type fakeProducer struct {
 	saves         chan Save
 	invalidations chan Invalidation
@@ -0,0 +1,317 @@
+package filesystem
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/prebid/prebid-server/v3/stored_requests/events"
+)
+
+// debounceDelay is how long changes are collected before the files are reloaded. Editors and deployment tools
+// often write a file in several steps, or replace whole directories at once.
+const debounceDelay = 100 * time.Millisecond
+
+const (
+	requestsDirectory  = "stored_requests"
+	impsDirectory      = "stored_imps"
+	responsesDirectory = "stored_responses"
+	accountsDirectory  = "accounts"
+)
+
+var watchedDirectories = []string{requestsDirectory, impsDirectory, responsesDirectory, accountsDirectory}
+
+var storedDataTypeMetricMap = map[config.DataType]metrics.StoredDataType{
+	config.RequestDataType:    metrics.RequestDataType,
+	config.CategoryDataType:   metrics.CategoryDataType,
+	config.VideoDataType:      metrics.VideoDataType,
+	config.AMPRequestDataType: metrics.AMPDataType,
+	config.AccountDataType:    metrics.AccountDataType,
+	config.ResponseDataType:   metrics.ResponseDataType,
+}
+
+// Updater applies the changes to the files of a directory to the data loaded from the filesystem.
+type Updater interface {
+	UpdateFiles(directory string, saved map[string]json.RawMessage, deleted []string)
+}
+
+// FilesystemEvents is an EventProducer which watches the directories of the file fetcher.
+type FilesystemEvents struct {
+	directory     string
+	dataType      config.DataType
+	metricsEngine metrics.MetricsEngine
+	updater       Updater
+	watcher       *fsnotify.Watcher
+	debounce      time.Duration
+
+	files         map[string]map[string]json.RawMessage
+	saves         chan events.Save
+	invalidations chan events.Invalidation
+	done          chan struct{}
+	closeOnce     sync.Once
+	stopped       chan struct{}
+}
+
+// NewFilesystemEvents makes an EventProducer which reloads the files of the directory whenever they change.
+// The stored_requests, stored_imps, stored_responses and accounts subdirectories are watched.
+//
+// The files are read right away, so it must be called before the file fetcher loads them: a file changed in between
+// is then reloaded rather than missed. The changes are applied once Start is called.
+//
+// Created and modified files are applied to the file fetcher through the updater and sent as Save events.
+// Deleted files are removed and sent as Invalidation events. Modified accounts are sent as Invalidation events too,
+// as caches hold accounts merged with the account defaults, which must be fetched again.
+//
+// Files which aren't valid JSON are logged and don't replace the data previously loaded.
+func NewFilesystemEvents(directory string, dataType config.DataType, metricsEngine metrics.MetricsEngine) (*FilesystemEvents, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &FilesystemEvents{
+		directory:     directory,
+		dataType:      dataType,
+		metricsEngine: metricsEngine,
+		watcher:       watcher,
+		debounce:      debounceDelay,
+		files:         make(map[string]map[string]json.RawMessage, len(watchedDirectories)),
+		saves:         make(chan events.Save, 1),
+		invalidations: make(chan events.Invalidation, 1),
+		done:          make(chan struct{}),
+	}
+
+	if err := watcher.Add(directory); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	for _, name := range watchedDirectories {
+		e.watch(name)
+		// the file fetcher loads the files next, so the initial content is the reference for later changes
+		e.files[name], _ = e.readDirectory(name)
+	}
+	return e, nil
+}
+
+// Start applies the changes to the files through the updater and sends them as events, until Close is called.
+func (e *FilesystemEvents) Start(updater Updater) {
+	e.updater = updater
+	e.stopped = make(chan struct{})
+	glog.Infof("Watching Stored %s data files at path %s", e.dataType, e.directory)
+	go e.run()
+}
+
+func (e *FilesystemEvents) Saves() <-chan events.Save {
+	return e.saves
+}
+
+func (e *FilesystemEvents) Invalidations() <-chan events.Invalidation {
+	return e.invalidations
+}
+
+// Close stops watching the files. It returns once the pending events are dropped, even if they were never received.
+func (e *FilesystemEvents) Close() (err error) {
+	e.closeOnce.Do(func() {
+		close(e.done)
+		err = e.watcher.Close()
+		if e.stopped != nil {
+			<-e.stopped
+		}
+	})
+	return err
+}
+
+func (e *FilesystemEvents) run() {
+	defer close(e.stopped)
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-e.done:
+			return
+		case event, ok := <-e.watcher.Events:
+			if !ok {
+				return
+			}
+			// subdirectories created after startup must be watched as well
+			if event.Op&fsnotify.Create != 0 && filepath.Dir(event.Name) == filepath.Clean(e.directory) {
+				e.watch(filepath.Base(event.Name))
+			}
+			if reload == nil {
+				reload = time.After(e.debounce)
+			}
+		case err, ok := <-e.watcher.Errors:
+			if !ok {
+				return
+			}
+			glog.Errorf("Error watching Stored %s data files at path %s: %v", e.dataType, e.directory, err)
+		case <-reload:
+			reload = nil
+			if !e.reload() {
+				return
+			}
+		}
+	}
+}
+
+func (e *FilesystemEvents) watch(name string) {
+	for _, watched := range watchedDirectories {
+		if name != watched {
+			continue
+		}
+		err := e.watcher.Add(filepath.Join(e.directory, name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			glog.Errorf("Failed to watch Stored %s data files at path %s: %v", e.dataType, filepath.Join(e.directory, name), err)
+		}
+	}
+}
+
+// reload reads the watched directories again and applies the files which changed. It returns false if the events
+// were closed while sending the changes.
+func (e *FilesystemEvents) reload() bool {
+	var save events.Save
+	var invalidation events.Invalidation
+	hasSave, hasInvalidation := false, false
+
+	for _, name := range watchedDirectories {
+		saved, deleted := e.changes(name)
+		if len(saved) == 0 && len(deleted) == 0 {
+			continue
+		}
+		// the file fetcher is updated before the caches, so a cache miss can't load the previous data again
+		e.updater.UpdateFiles(name, saved, deleted)
+
+		switch name {
+		case requestsDirectory:
+			save.Requests, invalidation.Requests = saved, deleted
+		case impsDirectory:
+			save.Imps, invalidation.Imps = saved, deleted
+		case responsesDirectory:
+			save.Responses, invalidation.Responses = saved, deleted
+		case accountsDirectory:
+			for id := range saved {
+				deleted = append(deleted, id)
+			}
+			invalidation.Accounts = deleted
+		}
+		hasSave = hasSave || (len(saved) > 0 && name != accountsDirectory)
+		hasInvalidation = hasInvalidation || len(deleted) > 0
+	}
+
+	if hasSave {
+		select {
+		case e.saves <- save:
+		case <-e.done:
+			return false
+		}
+	}
+	if hasInvalidation {
+		select {
+		case e.invalidations <- invalidation:
+		case <-e.done:
+			return false
+		}
+	}
+	return true
+}
+
+// changes returns the files of the directory which were saved or deleted since it was last read.
+func (e *FilesystemEvents) changes(name string) (saved map[string]json.RawMessage, deleted []string) {
+	current := e.files[name]
+	files, malformed := e.readDirectory(name)
+
+	for id, data := range files {
+		if previous, ok := current[id]; ok && bytes.Equal(previous, data) {
+			continue
+		}
+		if saved == nil {
+			saved = make(map[string]json.RawMessage)
+		}
+		saved[id] = data
+	}
+	for id, data := range current {
+		if _, ok := files[id]; ok {
+			continue
+		}
+		if _, ok := malformed[id]; ok {
+			// the previous data is kept until the file is fixed
+			files[id] = data
+			continue
+		}
+		deleted = append(deleted, id)
+	}
+
+	e.files[name] = files
+	return saved, deleted
+}
+
+// readDirectory returns the valid files of the directory, along with the IDs of the files which couldn't be loaded.
+func (e *FilesystemEvents) readDirectory(name string) (files map[string]json.RawMessage, malformed map[string]struct{}) {
+	files = make(map[string]json.RawMessage)
+	malformed = make(map[string]struct{})
+
+	directory := filepath.Join(e.directory, name)
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			glog.Errorf("Failed to read Stored %s data files at path %s: %v", e.dataType, directory, err)
+			e.recordError(metrics.StoredDataErrorUndefined)
+			// none of the files are deleted when the directory can't be read
+			for id := range e.files[name] {
+				malformed[id] = struct{}{}
+			}
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		path := filepath.Join(directory, entry.Name())
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			glog.Errorf("Failed to read Stored %s data file %s: %v", e.dataType, path, err)
+			e.recordError(metrics.StoredDataErrorUndefined)
+			malformed[id] = struct{}{}
+			continue
+		}
+		if !json.Valid(data) {
+			glog.Errorf("Stored %s data file %s is not valid JSON, keeping the previous data", e.dataType, path)
+			e.recordError(metrics.StoredDataErrorMalformed)
+			malformed[id] = struct{}{}
+			continue
+		}
+		if name == requestsDirectory || name == impsDirectory {
+			if err := stored_requests.ValidateTemplate(data); err != nil {
+				glog.Errorf("Stored %s data file %s is not a valid template, keeping the previous data: %v", e.dataType, path, err)
+				e.recordError(metrics.StoredDataErrorMalformed)
+				malformed[id] = struct{}{}
+				continue
+			}
+		}
+		files[id] = json.RawMessage(data)
+	}
+	return
+}
+
+func (e *FilesystemEvents) recordError(errorType metrics.StoredDataError) {
+	e.metricsEngine.RecordStoredDataError(
+		metrics.StoredDataLabels{
+			DataType: storedDataTypeMetricMap[e.dataType],
+			Error:    errorType,
+		})
+}
@@ -0,0 +1,210 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/stored_requests/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUpdater struct {
+	mutex   sync.Mutex
+	saved   map[string]map[string]json.RawMessage
+	deleted map[string][]string
+	updated chan string
+}
+
+func (u *mockUpdater) UpdateFiles(directory string, saved map[string]json.RawMessage, deleted []string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	if u.saved == nil {
+		u.saved = make(map[string]map[string]json.RawMessage)
+		u.deleted = make(map[string][]string)
+	}
+	u.saved[directory] = saved
+	u.deleted[directory] = deleted
+	if u.updated != nil {
+		u.updated <- directory
+	}
+}
+
+func (u *mockUpdater) get(directory string) (map[string]json.RawMessage, []string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	return u.saved[directory], u.deleted[directory]
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+}
+
+func newTestEvents(t *testing.T) (*FilesystemEvents, *mockUpdater, <-chan struct{}, string) {
+	e, malformed, directory := newUnstartedTestEvents(t)
+	updater := &mockUpdater{}
+	e.Start(updater)
+	return e, updater, malformed, directory
+}
+
+func newUnstartedTestEvents(t *testing.T) (*FilesystemEvents, <-chan struct{}, string) {
+	directory := t.TempDir()
+	writeFile(t, filepath.Join(directory, "stored_requests", "req1.json"), `{"id":"req1"}`)
+	writeFile(t, filepath.Join(directory, "stored_requests", "req2.json"), `{"id":"req2"}`)
+	writeFile(t, filepath.Join(directory, "accounts", "acct1.json"), `{"disabled":false}`)
+
+	malformed := make(chan struct{}, 10)
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordStoredDataError", metrics.StoredDataLabels{DataType: metrics.RequestDataType, Error: metrics.StoredDataErrorMalformed}).Return().Run(func(mock.Arguments) {
+		malformed <- struct{}{}
+	})
+
+	e, err := NewFilesystemEvents(directory, config.RequestDataType, metricsMock)
+	require.NoError(t, err)
+	t.Cleanup(func() { e.Close() })
+	return e, malformed, directory
+}
+
+func waitFor[T any](t *testing.T, ch <-chan T, message string) T {
+	t.Helper()
+	select {
+	case value := <-ch:
+		return value
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, message)
+	}
+	var zero T
+	return zero
+}
+
+func receiveSave(t *testing.T, e *FilesystemEvents) events.Save {
+	t.Helper()
+	return waitFor(t, e.Saves(), "no save event received")
+}
+
+func receiveInvalidation(t *testing.T, e *FilesystemEvents) events.Invalidation {
+	t.Helper()
+	return waitFor(t, e.Invalidations(), "no invalidation event received")
+}
+
+func TestFilesystemEventsSave(t *testing.T) {
+	e, updater, _, directory := newTestEvents(t)
+
+	writeFile(t, filepath.Join(directory, "stored_requests", "req1.json"), `{"id":"req1","tmax":500}`)
+	writeFile(t, filepath.Join(directory, "stored_imps", "imp1.json"), `{"id":"imp1"}`)
+	writeFile(t, filepath.Join(directory, "stored_requests", "notes.txt"), `not stored data`)
+
+	save := receiveSave(t, e)
+	assert.Equal(t, map[string]json.RawMessage{"req1": json.RawMessage(`{"id":"req1","tmax":500}`)}, save.Requests)
+	assert.Equal(t, map[string]json.RawMessage{"imp1": json.RawMessage(`{"id":"imp1"}`)}, save.Imps)
+
+	saved, deleted := updater.get("stored_requests")
+	assert.Equal(t, save.Requests, saved)
+	assert.Empty(t, deleted)
+	saved, _ = updater.get("stored_imps")
+	assert.Equal(t, save.Imps, saved)
+}
+
+func TestFilesystemEventsDelete(t *testing.T) {
+	e, updater, _, directory := newTestEvents(t)
+
+	require.NoError(t, os.Remove(filepath.Join(directory, "stored_requests", "req2.json")))
+
+	invalidation := receiveInvalidation(t, e)
+	assert.Equal(t, []string{"req2"}, invalidation.Requests)
+	_, deleted := updater.get("stored_requests")
+	assert.Equal(t, []string{"req2"}, deleted)
+}
+
+func TestFilesystemEventsAccountsInvalidated(t *testing.T) {
+	e, updater, _, directory := newTestEvents(t)
+
+	writeFile(t, filepath.Join(directory, "accounts", "acct1.json"), `{"disabled":true}`)
+
+	invalidation := receiveInvalidation(t, e)
+	assert.Equal(t, []string{"acct1"}, invalidation.Accounts)
+	saved, _ := updater.get("accounts")
+	assert.Equal(t, map[string]json.RawMessage{"acct1": json.RawMessage(`{"disabled":true}`)}, saved)
+}
+
+func TestFilesystemEventsMalformedFile(t *testing.T) {
+	e, updater, malformed, directory := newTestEvents(t)
+
+	writeFile(t, filepath.Join(directory, "stored_requests", "req1.json"), `{"id":`)
+	waitFor(t, malformed, "the malformed file wasn't reported")
+
+	// the malformed file is neither saved nor deleted
+	writeFile(t, filepath.Join(directory, "stored_requests", "req3.json"), `{"id":"req3"}`)
+	save := receiveSave(t, e)
+	assert.Equal(t, map[string]json.RawMessage{"req3": json.RawMessage(`{"id":"req3"}`)}, save.Requests)
+	_, deleted := updater.get("stored_requests")
+	assert.Empty(t, deleted)
+
+	// the fixed file is saved
+	writeFile(t, filepath.Join(directory, "stored_requests", "req1.json"), `{"id":"req1","tmax":500}`)
+	save = receiveSave(t, e)
+	assert.Equal(t, map[string]json.RawMessage{"req1": json.RawMessage(`{"id":"req1","tmax":500}`)}, save.Requests)
+}
+
+func TestFilesystemEventsInvalidTemplate(t *testing.T) {
+	e, _, malformed, directory := newTestEvents(t)
+
+	writeFile(t, filepath.Join(directory, "stored_imps", "imp1.json"), `{"banner":{"w":"{{params.w:integer}}"}}`)
+	waitFor(t, malformed, "the invalid template wasn't reported")
+
+	writeFile(t, filepath.Join(directory, "stored_imps", "imp1.json"), `{"banner":{"w":"{{params.w:int}}"}}`)
+	save := receiveSave(t, e)
+	assert.Equal(t, map[string]json.RawMessage{"imp1": json.RawMessage(`{"banner":{"w":"{{params.w:int}}"}}`)}, save.Imps)
+}
+
+func TestFilesystemEventsNewDirectory(t *testing.T) {
+	e, _, _, directory := newTestEvents(t)
+
+	// the reload following the new directory reads the file if it was written before the directory was watched
+	require.NoError(t, os.Mkdir(filepath.Join(directory, "stored_responses"), 0755))
+	writeFile(t, filepath.Join(directory, "stored_responses", "resp1.json"), `{"seatbid":[]}`)
+
+	save := receiveSave(t, e)
+	assert.Equal(t, map[string]json.RawMessage{"resp1": json.RawMessage(`{"seatbid":[]}`)}, save.Responses)
+}
+
+func TestFilesystemEventsChangedBeforeStart(t *testing.T) {
+	e, _, directory := newUnstartedTestEvents(t)
+
+	// the file fetcher would load this content between the events creation and start
+	writeFile(t, filepath.Join(directory, "stored_requests", "req1.json"), `{"id":"req1","tmax":500}`)
+	e.Start(&mockUpdater{})
+
+	save := receiveSave(t, e)
+	assert.Equal(t, map[string]json.RawMessage{"req1": json.RawMessage(`{"id":"req1","tmax":500}`)}, save.Requests)
+}
+
+func TestFilesystemEventsCloseWithPendingEvents(t *testing.T) {
+	e, _, directory := newUnstartedTestEvents(t)
+	updater := &mockUpdater{updated: make(chan string, 10)}
+	e.Start(updater)
+
+	// nothing receives the saves, so the second one waits for the first one to be received
+	writeFile(t, filepath.Join(directory, "stored_requests", "req1.json"), `{"id":"req1","tmax":500}`)
+	waitFor(t, updater.updated, "the first change wasn't applied")
+	writeFile(t, filepath.Join(directory, "stored_requests", "req2.json"), `{"id":"req2","tmax":500}`)
+	waitFor(t, updater.updated, "the second change wasn't applied")
+
+	closed := make(chan error)
+	go func() { closed <- e.Close() }()
+	assert.NoError(t, waitFor(t, closed, "close is blocked by the pending save"))
+}
+
+func TestNewFilesystemEventsInvalidDirectory(t *testing.T) {
+	_, err := NewFilesystemEvents(filepath.Join(t.TempDir(), "missing"), config.RequestDataType, &metrics.MetricsEngineMock{})
+	assert.Error(t, err)
+}
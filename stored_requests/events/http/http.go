@@ -7,6 +7,7 @@ import (
 	"io"
 	httpCore "net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/context/ctxhttp"
@@ -62,6 +63,8 @@ import (
 //
 // To signal deletions, the endpoint may return { "deleted": true }
 // in place of the Stored Data if the "last-modified" param existed.
+//
+// If all the data can't be loaded on startup, it's fetched again on each refresh until it's loaded.
 func NewHTTPEvents(client *httpCore.Client, endpoint string, ctxProducer func() (ctx context.Context, canceller func()), refreshRate time.Duration) *HTTPEvents {
 	// If we're not given a function to produce Contexts, use the Background one.
 	if ctxProducer == nil {
@@ -91,14 +94,23 @@ type HTTPEvents struct {
 	invalidations chan events.Invalidation
 	lastUpdate    time.Time
 	saves         chan events.Save
+	loaded        atomic.Bool
+}
+
+// Loaded returns true once all the data has been fetched.
+func (e *HTTPEvents) Loaded() bool {
+	return e.loaded.Load()
 }
 
-func (e *HTTPEvents) fetchAll() {
+func (e *HTTPEvents) fetchAll() bool {
 	ctx, cancel := e.ctxProducer()
 	defer cancel()
 	resp, err := ctxhttp.Get(ctx, e.client, e.Endpoint)
-	if respObj, ok := e.parse(e.Endpoint, resp, err); ok &&
-		(len(respObj.StoredRequests) > 0 || len(respObj.StoredImps) > 0 || len(respObj.StoredResponses) > 0 || len(respObj.Accounts) > 0) {
+	respObj, ok := e.parse(e.Endpoint, resp, err)
+	if !ok {
+		return false
+	}
+	if len(respObj.StoredRequests) > 0 || len(respObj.StoredImps) > 0 || len(respObj.StoredResponses) > 0 || len(respObj.Accounts) > 0 {
 		e.saves <- events.Save{
 			Requests:  respObj.StoredRequests,
 			Imps:      respObj.StoredImps,
@@ -106,12 +118,23 @@ func (e *HTTPEvents) fetchAll() {
 			Accounts:  respObj.Accounts,
 		}
 	}
+	e.loaded.Store(true)
+	return true
 }
 
 func (e *HTTPEvents) refresh(ticker <-chan time.Time) {
 	for thisTime := range ticker {
 		thisTimeInUTC := thisTime.UTC()
 
+		// the updates can't be applied until all the data is loaded
+		if !e.loaded.Load() {
+			glog.Infof("Loading HTTP cache from GET %s", e.Endpoint)
+			if e.fetchAll() {
+				e.lastUpdate = thisTimeInUTC
+			}
+			continue
+		}
+
 		// Parse the endpoint url defined
 		endpointUrl, urlErr := url.Parse(e.Endpoint)
 
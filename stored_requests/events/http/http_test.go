@@ -178,3 +178,25 @@ func (m *mockResponseHandler) ServeHTTP(rw httpCore.ResponseWriter, r *httpCore.
 	rw.WriteHeader(m.statusCode)
 	rw.Write([]byte(m.response))
 }
+
+func TestLoadedRetry(t *testing.T) {
+	handler := &mockResponseHandler{statusCode: httpCore.StatusInternalServerError}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ev := NewHTTPEvents(server.Client(), server.URL, nil, -1)
+	assert.False(t, ev.Loaded(), "Data shouldn't be loaded after a failed fetch")
+
+	handler.statusCode = httpCore.StatusOK
+	handler.response = `{"requests": {"request1": {"value":1}}}`
+	timeChan := make(chan time.Time, 1)
+	timeChan <- time.Now()
+	close(timeChan)
+	ev.refresh(timeChan)
+
+	saves, err := jsonutil.Marshal(<-ev.Saves())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"requests": {"request1": {"value":1}}, "imps": null, "responses": null, "accounts": null}`, string(saves))
+	assert.Empty(t, ev.Invalidations(), "Unexpected invalidations when loading all the data")
+	assert.True(t, ev.Loaded(), "Data should be loaded once fetched on refresh")
+}
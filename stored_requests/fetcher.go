@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
 )
 
 // Fetcher knows how to fetch Stored Request data by id.
@@ -90,6 +93,25 @@ type CacheJSON interface {
 	Save(ctx context.Context, data map[string]json.RawMessage)
 }
 
+// CacheEntry is a value of an EntryCache. Its Data is nil if the id is known to be missing.
+type CacheEntry struct {
+	Data json.RawMessage
+	// SavedAt is the time the data was saved, or the zero time if unknown.
+	SavedAt time.Time
+}
+
+// EntryCache is a CacheJSON which knows when its data was saved, and can remember missing ids.
+// It's used by WithCachePolicy to serve stale data and cache the ids which weren't found.
+type EntryCache interface {
+	CacheJSON
+
+	// GetEntries works like Get, but also returns the ids saved as missing, with a nil Data.
+	GetEntries(ctx context.Context, ids []string) (entries map[string]CacheEntry)
+
+	// SaveMissing saves the ids as missing until the ttl expires, or the ids are saved or invalidated.
+	SaveMissing(ctx context.Context, ids []string, ttl time.Duration)
+}
+
 // ComposedCache creates an interface to treat a slice of caches as a single cache
 type ComposedCache []CacheJSON
 
@@ -131,6 +153,55 @@ func updateFromCache(data map[string]json.RawMessage, ids []string, newData map[
 	return data, remainingIDs
 }
 
+// GetEntries will attempt to get the entries from the caches in the order in which they are in the slice,
+// like Get. The data of the caches which aren't EntryCaches is returned with a zero SavedAt.
+func (c ComposedCache) GetEntries(ctx context.Context, ids []string) (entries map[string]CacheEntry) {
+	entries = make(map[string]CacheEntry, len(ids))
+
+	remainingIDs := ids
+
+	for _, cache := range c {
+		var cachedEntries map[string]CacheEntry
+		if entryCache, ok := cache.(EntryCache); ok {
+			cachedEntries = entryCache.GetEntries(ctx, remainingIDs)
+		} else {
+			cachedData := cache.Get(ctx, remainingIDs)
+			cachedEntries = make(map[string]CacheEntry, len(cachedData))
+			for id, data := range cachedData {
+				cachedEntries[id] = CacheEntry{Data: data}
+			}
+		}
+
+		if len(cachedEntries) > 0 {
+			leftovers := make([]string, 0, len(remainingIDs))
+			for _, id := range remainingIDs {
+				if entry, ok := cachedEntries[id]; ok {
+					entries[id] = entry
+				} else {
+					leftovers = append(leftovers, id)
+				}
+			}
+			remainingIDs = leftovers
+		}
+
+		// finish early if all ids filled
+		if len(remainingIDs) == 0 {
+			break
+		}
+	}
+
+	return
+}
+
+// SaveMissing will propagate the missing ids to the underlying EntryCaches
+func (c ComposedCache) SaveMissing(ctx context.Context, ids []string, ttl time.Duration) {
+	for _, cache := range c {
+		if entryCache, ok := cache.(EntryCache); ok {
+			entryCache.SaveMissing(ctx, ids, ttl)
+		}
+	}
+}
+
 // Invalidate will propagate invalidations to all underlying caches
 func (c ComposedCache) Invalidate(ctx context.Context, ids []string) {
 	for _, cache := range c {
@@ -145,10 +216,34 @@ func (c ComposedCache) Save(ctx context.Context, data map[string]json.RawMessage
 	}
 }
 
+// CachePolicy configures how the data of the caches implementing EntryCache is served.
+// The zero CachePolicy serves all the cached data, and doesn't cache the missing ids.
+type CachePolicy struct {
+	// NegativeTTL is how long the ids which weren't found are cached as missing. 0 disables negative caching.
+	NegativeTTL time.Duration
+	// MaxAge is how long the cached data is fresh. 0 means the cached data is always fresh.
+	MaxAge time.Duration
+	// StaleWhileRevalidate is how long after MaxAge the cached data is served while it's refreshed in the background.
+	StaleWhileRevalidate time.Duration
+	// StaleIfError is how long after MaxAge the cached data is served if it can't be fetched.
+	StaleIfError time.Duration
+}
+
 type fetcherWithCache struct {
 	fetcher       AllFetcher
 	cache         Cache
+	policy        CachePolicy
 	metricsEngine metrics.MetricsEngine
+	time          timeutil.Time
+
+	// refreshing holds the refreshKeys of the data being refreshed in the background
+	refreshing sync.Map
+	refreshes  sync.WaitGroup
+}
+
+type refreshKey struct {
+	dataType string
+	id       string
 }
 
 // WithCache returns a Fetcher which uses the given Caches before delegating to the original.
@@ -156,65 +251,92 @@ type fetcherWithCache struct {
 // it is usually more desirable to first compose caches with Compose, ensuring propagation of updates
 // and invalidations through all cache layers.
 func WithCache(fetcher AllFetcher, cache Cache, metricsEngine metrics.MetricsEngine) AllFetcher {
+	return WithCachePolicy(fetcher, cache, CachePolicy{}, metricsEngine)
+}
+
+// WithCachePolicy returns a Fetcher like WithCache, which applies the policy to the Caches implementing EntryCache.
+func WithCachePolicy(fetcher AllFetcher, cache Cache, policy CachePolicy, metricsEngine metrics.MetricsEngine) AllFetcher {
 	return &fetcherWithCache{
 		cache:         cache,
 		fetcher:       fetcher,
+		policy:        policy,
 		metricsEngine: metricsEngine,
+		time:          &timeutil.RealTime{},
 	}
 }
 
 func (f *fetcherWithCache) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error) {
-
-	requestData = f.cache.Requests.Get(ctx, requestIDs)
-	impData = f.cache.Imps.Get(ctx, impIDs)
-
-	// Fixes #311
-	leftoverImps := findLeftovers(impIDs, impData)
-	leftoverReqs := findLeftovers(requestIDs, requestData)
+	reqs := f.lookup(ctx, f.cache.Requests, requestIDs)
+	imps := f.lookup(ctx, f.cache.Imps, impIDs)
 
 	// Record cache hits for stored requests and stored imps
-	f.metricsEngine.RecordStoredReqCacheResult(metrics.CacheHit, len(requestIDs)-len(leftoverReqs))
-	f.metricsEngine.RecordStoredImpCacheResult(metrics.CacheHit, len(impIDs)-len(leftoverImps))
+	f.metricsEngine.RecordStoredReqCacheResult(metrics.CacheHit, reqs.fresh)
+	f.metricsEngine.RecordStoredImpCacheResult(metrics.CacheHit, imps.fresh)
 	// Record cache misses for stored requests and stored imps
-	f.metricsEngine.RecordStoredReqCacheResult(metrics.CacheMiss, len(leftoverReqs))
-	f.metricsEngine.RecordStoredImpCacheResult(metrics.CacheMiss, len(leftoverImps))
-
-	if len(leftoverReqs) > 0 || len(leftoverImps) > 0 {
-		fetcherReqData, fetcherImpData, fetcherErrs := f.fetcher.FetchRequests(ctx, leftoverReqs, leftoverImps)
-		errs = fetcherErrs
-
-		f.cache.Requests.Save(ctx, fetcherReqData)
-		f.cache.Imps.Save(ctx, fetcherImpData)
+	f.metricsEngine.RecordStoredReqCacheResult(metrics.CacheMiss, len(reqs.leftovers))
+	f.metricsEngine.RecordStoredImpCacheResult(metrics.CacheMiss, len(imps.leftovers))
+	reqs.record(f.metricsEngine.RecordStoredReqCacheResult)
+	imps.record(f.metricsEngine.RecordStoredImpCacheResult)
+
+	requestData = reqs.data
+	impData = imps.data
+	errs = append(reqs.missingErrors("Request"), imps.missingErrors("Imp")...)
+
+	if len(reqs.leftovers) > 0 || len(imps.leftovers) > 0 {
+		fetcherReqData, fetcherImpData, fetcherErrs := f.fetcher.FetchRequests(ctx, reqs.leftovers, imps.leftovers)
+		notFound, failed := splitErrors(fetcherErrs)
+
+		var reqStale, impStale, reqUnresolved, impUnresolved int
+		requestData, reqStale, reqUnresolved = f.update(ctx, f.cache.Requests, "Request", reqs, fetcherReqData, notFound, failed)
+		impData, impStale, impUnresolved = f.update(ctx, f.cache.Imps, "Imp", imps, fetcherImpData, notFound, failed)
+		recordIfAny(f.metricsEngine.RecordStoredReqCacheResult, metrics.CacheStaleIfError, reqStale)
+		recordIfAny(f.metricsEngine.RecordStoredImpCacheResult, metrics.CacheStaleIfError, impStale)
+
+		errs = append(errs, servedErrors(fetcherErrs, reqStale+impStale, reqUnresolved+impUnresolved)...)
+	}
 
-		requestData = mergeData(requestData, fetcherReqData)
-		impData = mergeData(impData, fetcherImpData)
+	if len(reqs.revalidate) > 0 || len(imps.revalidate) > 0 {
+		f.refreshRequests(ctx, reqs.revalidate, imps.revalidate)
 	}
 
 	return
 }
 
 func (f *fetcherWithCache) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
-	data = f.cache.Responses.Get(ctx, ids)
+	resps := f.lookup(ctx, f.cache.Responses, ids)
 
-	leftoverResp := findLeftovers(ids, data)
+	data = resps.data
+	errs = resps.missingErrors("Response")
 
-	if len(leftoverResp) > 0 {
-		fetcherRespData, fetcherErrs := f.fetcher.FetchResponses(ctx, leftoverResp)
-		errs = fetcherErrs
+	if len(resps.leftovers) > 0 {
+		fetcherRespData, fetcherErrs := f.fetcher.FetchResponses(ctx, resps.leftovers)
+		notFound, failed := splitErrors(fetcherErrs)
 
-		f.cache.Responses.Save(ctx, fetcherRespData)
+		var stale, unresolved int
+		data, stale, unresolved = f.update(ctx, f.cache.Responses, "Response", resps, fetcherRespData, notFound, failed)
 
-		data = mergeData(data, fetcherRespData)
+		errs = append(errs, servedErrors(fetcherErrs, stale, unresolved)...)
+	}
+
+	if len(resps.revalidate) > 0 {
+		f.refreshResponses(ctx, resps.revalidate)
 	}
 
 	return
 }
 
 func (f *fetcherWithCache) FetchAccount(ctx context.Context, acccountDefaultJSON json.RawMessage, accountID string) (account json.RawMessage, errs []error) {
-	accountData := f.cache.Accounts.Get(ctx, []string{accountID})
-	// TODO: add metrics
-	if account, ok := accountData[accountID]; ok {
-		f.metricsEngine.RecordAccountCacheResult(metrics.CacheHit, 1)
+	accounts := f.lookup(ctx, f.cache.Accounts, []string{accountID})
+	accounts.record(f.metricsEngine.RecordAccountCacheResult)
+	if len(accounts.missing) > 0 {
+		return nil, accounts.missingErrors("Account")
+	}
+	if account, ok := accounts.data[accountID]; ok {
+		if accounts.fresh > 0 {
+			f.metricsEngine.RecordAccountCacheResult(metrics.CacheHit, 1)
+		} else {
+			f.refreshAccount(ctx, acccountDefaultJSON, accountID)
+		}
 		return account, errs
 	} else {
 		f.metricsEngine.RecordAccountCacheResult(metrics.CacheMiss, 1)
@@ -222,10 +344,294 @@ func (f *fetcherWithCache) FetchAccount(ctx context.Context, acccountDefaultJSON
 	account, errs = f.fetcher.FetchAccount(ctx, acccountDefaultJSON, accountID)
 	if len(errs) == 0 {
 		f.cache.Accounts.Save(ctx, map[string]json.RawMessage{accountID: account})
+		return account, errs
+	}
+
+	notFound, failed := splitErrors(errs)
+	if notFound.has("Account", accountID) {
+		f.saveMissing(ctx, f.cache.Accounts, []string{accountID})
+	} else if stale, ok := accounts.stale[accountID]; ok && failed {
+		f.metricsEngine.RecordAccountCacheResult(metrics.CacheStaleIfError, 1)
+		return stale, nil
 	}
 	return account, errs
 }
 
+// cacheLookup splits the ids looked up in a cache by the state of their cached data.
+type cacheLookup struct {
+	// data is the cached data to serve, fresh or stale
+	data  map[string]json.RawMessage
+	fresh int
+	// missing are the ids cached as missing
+	missing []string
+	// revalidate are the ids of the stale data served while it's refreshed
+	revalidate []string
+	// leftovers are the ids to fetch
+	leftovers []string
+	// stale is the cached data of leftovers which may be served if they can't be fetched
+	stale map[string]json.RawMessage
+}
+
+func (f *fetcherWithCache) lookup(ctx context.Context, cache CacheJSON, ids []string) (l cacheLookup) {
+	entryCache, ok := cache.(EntryCache)
+	if !ok || f.policy == (CachePolicy{}) {
+		l.data = cache.Get(ctx, ids)
+		l.leftovers = findLeftovers(ids, l.data)
+		l.fresh = len(ids) - len(l.leftovers)
+		return
+	}
+
+	entries := entryCache.GetEntries(ctx, ids)
+	l.data = make(map[string]json.RawMessage, len(entries))
+	l.leftovers = make([]string, 0, len(ids)-len(entries))
+	now := f.time.Now()
+	for _, id := range ids {
+		entry, ok := entries[id]
+		if !ok {
+			l.leftovers = append(l.leftovers, id)
+			continue
+		}
+		if entry.Data == nil {
+			l.missing = append(l.missing, id)
+			continue
+		}
+
+		age := now.Sub(entry.SavedAt)
+		switch {
+		case f.policy.MaxAge <= 0 || entry.SavedAt.IsZero() || age <= f.policy.MaxAge:
+			l.data[id] = entry.Data
+			l.fresh++
+		case age <= f.policy.MaxAge+f.policy.StaleWhileRevalidate:
+			l.data[id] = entry.Data
+			l.revalidate = append(l.revalidate, id)
+		default:
+			l.leftovers = append(l.leftovers, id)
+			if age <= f.policy.MaxAge+f.policy.StaleIfError {
+				if l.stale == nil {
+					l.stale = make(map[string]json.RawMessage)
+				}
+				l.stale[id] = entry.Data
+			}
+		}
+	}
+	return
+}
+
+// record records the cache results of the lookup other than the hits and misses.
+func (l cacheLookup) record(record func(metrics.CacheResult, int)) {
+	recordIfAny(record, metrics.CacheNegativeHit, len(l.missing))
+	recordIfAny(record, metrics.CacheStale, len(l.revalidate))
+}
+
+func (l cacheLookup) missingErrors(dataType string) (errs []error) {
+	for _, id := range l.missing {
+		errs = append(errs, NotFoundError{ID: id, DataType: dataType})
+	}
+	return
+}
+
+// update saves the fetched data of the lookup leftovers in the cache, and returns the data to serve.
+// The stale data of the leftovers which couldn't be fetched is served if the fetch failed. It returns the
+// number of ids served stale, and the number of ids neither served nor reported as not found.
+func (f *fetcherWithCache) update(ctx context.Context, cache CacheJSON, dataType string, l cacheLookup, fetched map[string]json.RawMessage, notFound notFoundIDs, failed bool) (data map[string]json.RawMessage, stale int, unresolved int) {
+	cache.Save(ctx, fetched)
+
+	var missing []string
+	for _, id := range l.leftovers {
+		if _, ok := fetched[id]; ok {
+			continue
+		}
+		if notFound.has(dataType, id) {
+			missing = append(missing, id)
+		} else if staleData, ok := l.stale[id]; ok && failed {
+			l.data[id] = staleData
+			stale++
+		} else {
+			unresolved++
+		}
+	}
+	f.saveMissing(ctx, cache, missing)
+
+	return mergeData(l.data, fetched), stale, unresolved
+}
+
+// saveMissing caches the ids which weren't found as missing, if negative caching is enabled.
+func (f *fetcherWithCache) saveMissing(ctx context.Context, cache CacheJSON, ids []string) {
+	if len(ids) == 0 || f.policy == (CachePolicy{}) {
+		return
+	}
+	if entryCache, ok := cache.(EntryCache); ok && f.policy.NegativeTTL > 0 {
+		entryCache.SaveMissing(ctx, ids, f.policy.NegativeTTL)
+	} else {
+		// the stale data of deleted ids must not be served anymore
+		cache.Invalidate(ctx, ids)
+	}
+}
+
+// refreshRequests fetches the stale requests and imps in the background, other than those already being refreshed.
+func (f *fetcherWithCache) refreshRequests(ctx context.Context, requestIDs []string, impIDs []string) {
+	requestIDs = f.startRefresh("Request", requestIDs)
+	impIDs = f.startRefresh("Imp", impIDs)
+	if len(requestIDs) == 0 && len(impIDs) == 0 {
+		return
+	}
+
+	f.refreshes.Add(1)
+	go func() {
+		defer f.refreshes.Done()
+		defer f.endRefresh("Request", requestIDs)
+		defer f.endRefresh("Imp", impIDs)
+
+		refreshCtx, cancel := refreshContext(ctx)
+		defer cancel()
+		reqData, impData, errs := f.fetcher.FetchRequests(refreshCtx, requestIDs, impIDs)
+		notFound, _ := splitErrors(errs)
+		f.saveRefreshed(refreshCtx, f.cache.Requests, "Request", requestIDs, reqData, notFound, f.metricsEngine.RecordStoredReqCacheResult)
+		f.saveRefreshed(refreshCtx, f.cache.Imps, "Imp", impIDs, impData, notFound, f.metricsEngine.RecordStoredImpCacheResult)
+	}()
+}
+
+// refreshResponses fetches the stale responses in the background, other than those already being refreshed.
+func (f *fetcherWithCache) refreshResponses(ctx context.Context, ids []string) {
+	ids = f.startRefresh("Response", ids)
+	if len(ids) == 0 {
+		return
+	}
+
+	f.refreshes.Add(1)
+	go func() {
+		defer f.refreshes.Done()
+		defer f.endRefresh("Response", ids)
+
+		refreshCtx, cancel := refreshContext(ctx)
+		defer cancel()
+		data, errs := f.fetcher.FetchResponses(refreshCtx, ids)
+		notFound, _ := splitErrors(errs)
+		f.saveRefreshed(refreshCtx, f.cache.Responses, "Response", ids, data, notFound, nil)
+	}()
+}
+
+// refreshAccount fetches the stale account in the background, unless it's already being refreshed.
+func (f *fetcherWithCache) refreshAccount(ctx context.Context, accountDefaultJSON json.RawMessage, accountID string) {
+	ids := f.startRefresh("Account", []string{accountID})
+	if len(ids) == 0 {
+		return
+	}
+
+	f.refreshes.Add(1)
+	go func() {
+		defer f.refreshes.Done()
+		defer f.endRefresh("Account", ids)
+
+		refreshCtx, cancel := refreshContext(ctx)
+		defer cancel()
+		account, errs := f.fetcher.FetchAccount(refreshCtx, accountDefaultJSON, accountID)
+		var data map[string]json.RawMessage
+		if len(errs) == 0 {
+			data = map[string]json.RawMessage{accountID: account}
+		}
+		notFound, _ := splitErrors(errs)
+		f.saveRefreshed(refreshCtx, f.cache.Accounts, "Account", ids, data, notFound, f.metricsEngine.RecordAccountCacheResult)
+	}()
+}
+
+// saveRefreshed saves the refreshed data in the cache. The stale data of the ids which couldn't be fetched
+// is kept, so it's served until it's too old.
+func (f *fetcherWithCache) saveRefreshed(ctx context.Context, cache CacheJSON, dataType string, ids []string, fetched map[string]json.RawMessage, notFound notFoundIDs, record func(metrics.CacheResult, int)) {
+	if len(ids) == 0 {
+		return
+	}
+	cache.Save(ctx, fetched)
+
+	var missing []string
+	for _, id := range ids {
+		if _, ok := fetched[id]; ok {
+			continue
+		}
+		if notFound.has(dataType, id) {
+			missing = append(missing, id)
+		}
+	}
+	f.saveMissing(ctx, cache, missing)
+
+	failures := len(ids) - len(fetched) - len(missing)
+	if record != nil {
+		recordIfAny(record, metrics.CacheRefresh, len(ids)-failures)
+		recordIfAny(record, metrics.CacheRefreshError, failures)
+	}
+}
+
+// startRefresh returns the ids which aren't already being refreshed, and marks them as being refreshed.
+func (f *fetcherWithCache) startRefresh(dataType string, ids []string) []string {
+	started := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, loaded := f.refreshing.LoadOrStore(refreshKey{dataType, id}, struct{}{}); !loaded {
+			started = append(started, id)
+		}
+	}
+	return started
+}
+
+func (f *fetcherWithCache) endRefresh(dataType string, ids []string) {
+	for _, id := range ids {
+		f.refreshing.Delete(refreshKey{dataType, id})
+	}
+}
+
+// refreshContext returns the context of a background refresh. It isn't canceled with the request,
+// but keeps the deadline of the request.
+func refreshContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	refreshCtx := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(refreshCtx, deadline)
+	}
+	return context.WithCancel(refreshCtx)
+}
+
+// notFoundIDs holds the ids of the NotFoundErrors by data type, since a request and an imp may share an id.
+type notFoundIDs map[NotFoundError]struct{}
+
+func (n notFoundIDs) has(dataType string, id string) bool {
+	_, ok := n[NotFoundError{ID: id, DataType: dataType}]
+	return ok
+}
+
+// splitErrors returns the ids of the NotFoundErrors, and whether any other error occurred.
+func splitErrors(errs []error) (notFound notFoundIDs, failed bool) {
+	for _, err := range errs {
+		if notFoundErr, ok := err.(NotFoundError); ok {
+			if notFound == nil {
+				notFound = make(notFoundIDs)
+			}
+			notFound[notFoundErr] = struct{}{}
+		} else {
+			failed = true
+		}
+	}
+	return
+}
+
+// servedErrors returns the fetch errors to report. If all the data which couldn't be fetched was served stale,
+// then only the NotFoundErrors are reported.
+func servedErrors(errs []error, stale int, unresolved int) []error {
+	if stale == 0 || unresolved > 0 {
+		return errs
+	}
+	var notFoundErrs []error
+	for _, err := range errs {
+		if _, ok := err.(NotFoundError); ok {
+			notFoundErrs = append(notFoundErrs, err)
+		}
+	}
+	return notFoundErrs
+}
+
+func recordIfAny(record func(metrics.CacheResult, int), result metrics.CacheResult, inc int) {
+	if inc > 0 {
+		record(result, inc)
+	}
+}
+
 func (f *fetcherWithCache) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
 	return "", nil
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/prebid/prebid-server/v3/metrics"
 	"github.com/prebid/prebid-server/v3/stored_requests/caches/nil_cache"
@@ -372,3 +374,311 @@ func (c *mockCache) Save(ctx context.Context, data map[string]json.RawMessage) {
 func (c *mockCache) Invalidate(ctx context.Context, ids []string) {
 	c.Called(ctx, ids)
 }
+
+type fakeTime struct {
+	time time.Time
+}
+
+func (t *fakeTime) Now() time.Time {
+	return t.time
+}
+
+// fakeEntryCache is an EntryCache which saves the data with the time of the fetcher.
+type fakeEntryCache struct {
+	mutex   sync.Mutex
+	time    *fakeTime
+	entries map[string]CacheEntry
+}
+
+func newFakeEntryCache(now *fakeTime) *fakeEntryCache {
+	return &fakeEntryCache{time: now, entries: make(map[string]CacheEntry)}
+}
+
+func (c *fakeEntryCache) Get(ctx context.Context, ids []string) map[string]json.RawMessage {
+	data := make(map[string]json.RawMessage)
+	for id, entry := range c.GetEntries(ctx, ids) {
+		if entry.Data != nil {
+			data[id] = entry.Data
+		}
+	}
+	return data
+}
+
+func (c *fakeEntryCache) GetEntries(ctx context.Context, ids []string) map[string]CacheEntry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entries := make(map[string]CacheEntry)
+	for _, id := range ids {
+		if entry, ok := c.entries[id]; ok {
+			entries[id] = entry
+		}
+	}
+	return entries
+}
+
+func (c *fakeEntryCache) Save(ctx context.Context, data map[string]json.RawMessage) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for id, value := range data {
+		c.entries[id] = CacheEntry{Data: value, SavedAt: c.time.Now()}
+	}
+}
+
+func (c *fakeEntryCache) SaveMissing(ctx context.Context, ids []string, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, id := range ids {
+		c.entries[id] = CacheEntry{SavedAt: c.time.Now()}
+	}
+}
+
+func (c *fakeEntryCache) Invalidate(ctx context.Context, ids []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, id := range ids {
+		delete(c.entries, id)
+	}
+}
+
+func (c *fakeEntryCache) entry(id string) (CacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[id]
+	return entry, ok
+}
+
+var testCachePolicy = CachePolicy{
+	NegativeTTL:          time.Minute,
+	MaxAge:               time.Minute,
+	StaleWhileRevalidate: time.Minute,
+	StaleIfError:         time.Hour,
+}
+
+func setupFetcherWithCachePolicy() (*fakeTime, Cache, *mockFetcher, *fetcherWithCache, *metrics.MetricsEngineMock) {
+	now := &fakeTime{time: time.Date(2020, time.July, 1, 12, 0, 0, 0, time.UTC)}
+	cache := Cache{
+		Requests:  newFakeEntryCache(now),
+		Imps:      newFakeEntryCache(now),
+		Responses: newFakeEntryCache(now),
+		Accounts:  newFakeEntryCache(now),
+	}
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordStoredReqCacheResult", mock.Anything, mock.Anything)
+	metricsEngine.On("RecordStoredImpCacheResult", mock.Anything, mock.Anything)
+	metricsEngine.On("RecordAccountCacheResult", mock.Anything, mock.Anything)
+	fetcher := &mockFetcher{}
+	f := WithCachePolicy(fetcher, cache, testCachePolicy, metricsEngine).(*fetcherWithCache)
+	f.time = now
+	return now, cache, fetcher, f, metricsEngine
+}
+
+func TestNegativeCaching(t *testing.T) {
+	now, cache, fetcher, f, metricsEngine := setupFetcherWithCachePolicy()
+	ctx := context.Background()
+
+	fetcher.On("FetchRequests", ctx, []string{"missing"}, []string{"imp"}).Return(
+		map[string]json.RawMessage{},
+		map[string]json.RawMessage{"imp": json.RawMessage(`{}`)},
+		[]error{NotFoundError{ID: "missing", DataType: "Request"}}).Once()
+
+	_, _, errs := f.FetchRequests(ctx, []string{"missing"}, []string{"imp"})
+	assert.Equal(t, []error{NotFoundError{ID: "missing", DataType: "Request"}}, errs)
+
+	now.time = now.time.Add(30 * time.Second)
+	reqData, impData, errs := f.FetchRequests(ctx, []string{"missing"}, []string{"imp"})
+	assert.Empty(t, reqData)
+	assert.Equal(t, map[string]json.RawMessage{"imp": json.RawMessage(`{}`)}, impData)
+	assert.Equal(t, []error{NotFoundError{ID: "missing", DataType: "Request"}}, errs, "the missing id must not be fetched again")
+	fetcher.AssertExpectations(t)
+	metricsEngine.AssertCalled(t, "RecordStoredReqCacheResult", metrics.CacheNegativeHit, 1)
+
+	// saving the id replaces the missing entry
+	cache.Requests.Save(ctx, map[string]json.RawMessage{"missing": json.RawMessage(`{}`)})
+	reqData, _, errs = f.FetchRequests(ctx, []string{"missing"}, nil)
+	assert.Equal(t, map[string]json.RawMessage{"missing": json.RawMessage(`{}`)}, reqData)
+	assert.Empty(t, errs)
+}
+
+func TestNegativeCachingSharedID(t *testing.T) {
+	_, cache, fetcher, f, _ := setupFetcherWithCachePolicy()
+	ctx := context.Background()
+
+	fetcher.On("FetchRequests", ctx, []string{"shared"}, []string{"shared"}).Return(
+		map[string]json.RawMessage{},
+		map[string]json.RawMessage{},
+		[]error{NotFoundError{ID: "shared", DataType: "Request"}, errors.New("imp failed")}).Once()
+
+	f.FetchRequests(ctx, []string{"shared"}, []string{"shared"})
+
+	_, ok := cache.Requests.(*fakeEntryCache).entry("shared")
+	assert.True(t, ok, "the missing request must be cached as missing")
+	_, ok = cache.Imps.(*fakeEntryCache).entry("shared")
+	assert.False(t, ok, "the imp which failed to fetch must not be cached as missing")
+}
+
+func TestAccountNegativeCaching(t *testing.T) {
+	_, _, fetcher, f, metricsEngine := setupFetcherWithCachePolicy()
+	ctx := context.Background()
+
+	fetcher.On("FetchAccount", ctx, json.RawMessage(`{}`), "missing").Return(json.RawMessage(nil),
+		[]error{NotFoundError{ID: "missing", DataType: "Account"}}).Once()
+
+	for i := 0; i < 2; i++ {
+		account, errs := f.FetchAccount(ctx, json.RawMessage(`{}`), "missing")
+		assert.Nil(t, account)
+		assert.Equal(t, []error{NotFoundError{ID: "missing", DataType: "Account"}}, errs)
+	}
+	fetcher.AssertExpectations(t)
+	metricsEngine.AssertCalled(t, "RecordAccountCacheResult", metrics.CacheMiss, 1)
+	metricsEngine.AssertCalled(t, "RecordAccountCacheResult", metrics.CacheNegativeHit, 1)
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	now, cache, fetcher, f, metricsEngine := setupFetcherWithCachePolicy()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cache.Requests.Save(ctx, map[string]json.RawMessage{"req": json.RawMessage(`{"v":1}`), "deleted": json.RawMessage(`{}`)})
+	cache.Imps.Save(ctx, map[string]json.RawMessage{"imp": json.RawMessage(`{"v":1}`)})
+	now.time = now.time.Add(90 * time.Second)
+
+	fetcher.On("FetchRequests", mock.Anything, []string{"req", "deleted"}, []string{"imp"}).Return(
+		map[string]json.RawMessage{"req": json.RawMessage(`{"v":2}`)},
+		map[string]json.RawMessage{},
+		[]error{NotFoundError{ID: "deleted", DataType: "Request"}, errors.New("imp failed")}).Once()
+
+	reqData, impData, errs := f.FetchRequests(ctx, []string{"req", "deleted"}, []string{"imp"})
+	// the refresh must outlive the request
+	cancel()
+	assert.Equal(t, map[string]json.RawMessage{"req": json.RawMessage(`{"v":1}`), "deleted": json.RawMessage(`{}`)}, reqData)
+	assert.Equal(t, map[string]json.RawMessage{"imp": json.RawMessage(`{"v":1}`)}, impData)
+	assert.Empty(t, errs)
+
+	f.refreshes.Wait()
+	fetcher.AssertExpectations(t)
+	assert.Equal(t, ctx.Err(), context.Canceled)
+
+	entry, _ := cache.Requests.(*fakeEntryCache).entry("req")
+	assert.Equal(t, CacheEntry{Data: json.RawMessage(`{"v":2}`), SavedAt: now.time}, entry)
+	entry, _ = cache.Requests.(*fakeEntryCache).entry("deleted")
+	assert.Equal(t, CacheEntry{SavedAt: now.time}, entry, "the deleted request must be cached as missing")
+	_, ok := cache.Imps.(*fakeEntryCache).entry("imp")
+	assert.True(t, ok, "the imp which couldn't be refreshed must be kept")
+
+	metricsEngine.AssertCalled(t, "RecordStoredReqCacheResult", metrics.CacheStale, 2)
+	metricsEngine.AssertCalled(t, "RecordStoredImpCacheResult", metrics.CacheStale, 1)
+	metricsEngine.AssertCalled(t, "RecordStoredReqCacheResult", metrics.CacheRefresh, 2)
+	metricsEngine.AssertCalled(t, "RecordStoredImpCacheResult", metrics.CacheRefreshError, 1)
+}
+
+func TestStaleWhileRevalidateDeduplicatesRefreshes(t *testing.T) {
+	now, cache, fetcher, f, _ := setupFetcherWithCachePolicy()
+	ctx := context.Background()
+
+	cache.Responses.Save(ctx, map[string]json.RawMessage{"resp": json.RawMessage(`{"v":1}`)})
+	now.time = now.time.Add(90 * time.Second)
+
+	release := make(chan time.Time)
+	fetcher.On("FetchResponses", mock.Anything, []string{"resp"}).WaitUntil(release).Return(
+		map[string]json.RawMessage{"resp": json.RawMessage(`{"v":2}`)}, []error{}).Once()
+
+	for i := 0; i < 3; i++ {
+		data, errs := f.FetchResponses(ctx, []string{"resp"})
+		assert.Equal(t, map[string]json.RawMessage{"resp": json.RawMessage(`{"v":1}`)}, data)
+		assert.Empty(t, errs)
+	}
+	close(release)
+	f.refreshes.Wait()
+	fetcher.AssertExpectations(t)
+
+	data, _ := f.FetchResponses(ctx, []string{"resp"})
+	assert.Equal(t, map[string]json.RawMessage{"resp": json.RawMessage(`{"v":2}`)}, data)
+}
+
+func TestStaleIfError(t *testing.T) {
+	tests := []struct {
+		description  string
+		giveAge      time.Duration
+		giveFetchErr error
+		wantData     map[string]json.RawMessage
+		wantErrs     []error
+	}{
+		{
+			description:  "fetch failed",
+			giveAge:      30 * time.Minute,
+			giveFetchErr: errors.New("fetch failed"),
+			wantData:     map[string]json.RawMessage{"req": json.RawMessage(`{"v":1}`)},
+		},
+		{
+			description:  "not found",
+			giveAge:      30 * time.Minute,
+			giveFetchErr: NotFoundError{ID: "req", DataType: "Request"},
+			wantData:     map[string]json.RawMessage{},
+			wantErrs:     []error{NotFoundError{ID: "req", DataType: "Request"}},
+		},
+		{
+			description:  "too old",
+			giveAge:      2 * time.Hour,
+			giveFetchErr: errors.New("fetch failed"),
+			wantData:     map[string]json.RawMessage{},
+			wantErrs:     []error{errors.New("fetch failed")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			now, cache, fetcher, f, metricsEngine := setupFetcherWithCachePolicy()
+			ctx := context.Background()
+
+			cache.Requests.Save(ctx, map[string]json.RawMessage{"req": json.RawMessage(`{"v":1}`)})
+			now.time = now.time.Add(tt.giveAge)
+			fetcher.On("FetchRequests", ctx, []string{"req"}, []string{}).Return(
+				map[string]json.RawMessage{}, map[string]json.RawMessage{}, []error{tt.giveFetchErr})
+
+			reqData, _, errs := f.FetchRequests(ctx, []string{"req"}, nil)
+			assert.Equal(t, tt.wantData, reqData)
+			assert.Equal(t, tt.wantErrs, errs)
+			if len(tt.wantErrs) == 0 {
+				metricsEngine.AssertCalled(t, "RecordStoredReqCacheResult", metrics.CacheStaleIfError, 1)
+			} else {
+				metricsEngine.AssertNotCalled(t, "RecordStoredReqCacheResult", metrics.CacheStaleIfError, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAccountStaleIfError(t *testing.T) {
+	now, cache, fetcher, f, metricsEngine := setupFetcherWithCachePolicy()
+	ctx := context.Background()
+
+	cache.Accounts.Save(ctx, map[string]json.RawMessage{"account": json.RawMessage(`{"v":1}`)})
+	now.time = now.time.Add(30 * time.Minute)
+	fetcher.On("FetchAccount", ctx, json.RawMessage(`{}`), "account").Return(json.RawMessage(nil), []error{errors.New("fetch failed")})
+
+	account, errs := f.FetchAccount(ctx, json.RawMessage(`{}`), "account")
+	assert.Equal(t, json.RawMessage(`{"v":1}`), account)
+	assert.Empty(t, errs)
+	metricsEngine.AssertCalled(t, "RecordAccountCacheResult", metrics.CacheStaleIfError, 1)
+}
+
+func TestComposedCacheEntries(t *testing.T) {
+	now := &fakeTime{time: time.Date(2020, time.July, 1, 12, 0, 0, 0, time.UTC)}
+	entryCache := newFakeEntryCache(now)
+	jsonCache := &mockCache{}
+	cache := ComposedCache{entryCache, jsonCache}
+	ctx := context.Background()
+
+	entryCache.Save(ctx, map[string]json.RawMessage{"1": json.RawMessage(`{"id":"1"}`)})
+	entryCache.SaveMissing(ctx, []string{"2"}, time.Minute)
+	jsonCache.On("Get", ctx, []string{"3", "4"}).Return(map[string]json.RawMessage{"3": json.RawMessage(`{"id":"3"}`)})
+
+	assert.Equal(t, map[string]CacheEntry{
+		"1": {Data: json.RawMessage(`{"id":"1"}`), SavedAt: now.time},
+		"2": {SavedAt: now.time},
+		"3": {Data: json.RawMessage(`{"id":"3"}`)},
+	}, cache.GetEntries(ctx, []string{"1", "2", "3", "4"}))
+
+	cache.SaveMissing(ctx, []string{"4"}, time.Minute)
+	_, ok := entryCache.entry("4")
+	assert.True(t, ok)
+	jsonCache.AssertExpectations(t)
+}
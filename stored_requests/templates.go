@@ -0,0 +1,320 @@
+package stored_requests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+	"github.com/prebid/prebid-server/v3/util/templateutil"
+)
+
+// Stored requests and imps may be templates: their string values may hold placeholders resolved
+// from the incoming request when the stored data is used.
+//
+//	{"tagid": "{{params.slot}}", "banner": {"w": "{{params.width:int=300}}"}, "ext": {"site": "site-{{request.site.id}}"}}
+//
+// A placeholder is written {{source.path}}, optionally followed by a :type and a =default. The sources are:
+//
+//   - request: a field of the incoming request, e.g. {{request.site.page}} or {{request.imp.0.id}}
+//   - imp: a field of the incoming imp using the stored imp, e.g. {{imp.tagid}}
+//   - params: a value of ext.prebid.storedrequest.params, in the request or the imp using the stored data
+//   - amp: a query parameter of the AMP request, e.g. {{amp.slot}}
+//
+// A string which is a single placeholder is replaced by the value, converted to the type if given: string, int,
+// float, bool or json. Placeholders within a longer string are replaced by the text of the value, so they can
+// only be of the string type. The default is used when the value is missing or null. A missing value without
+// a default is an error. Text between braces which isn't such a placeholder, e.g. {{UUID}}, is kept as is.
+
+var templateSyntax = templateutil.Syntax{
+	Sources: map[string]bool{
+		"request": true,
+		"imp":     true,
+		"params":  true,
+		"amp":     true,
+	},
+	Lenient: true,
+}
+
+var templateTypes = map[string]struct{}{
+	"":       {},
+	"string": {},
+	"int":    {},
+	"float":  {},
+	"bool":   {},
+	"json":   {},
+}
+
+// TemplateValues are the values the placeholders of templated stored data are resolved from.
+type TemplateValues struct {
+	Request json.RawMessage
+	Imp     json.RawMessage
+	Params  map[string]json.RawMessage
+	AMP     url.Values
+}
+
+// IsTemplate returns true if the stored data holds placeholders.
+func IsTemplate(data json.RawMessage) bool {
+	if !bytes.Contains(data, []byte("{{")) {
+		return false
+	}
+	template, _ := templateSyntax.Parse(string(data))
+	return len(template.Placeholders()) > 0
+}
+
+// ValidateTemplate returns an error if a placeholder of the stored data has an unknown type, a type which can't
+// be used within a longer string or a default which can't be converted to its type.
+func ValidateTemplate(data json.RawMessage) error {
+	if !IsTemplate(data) {
+		return nil
+	}
+	value, err := decodeTemplate(data)
+	if err != nil {
+		return err
+	}
+	return walkStrings(value, func(s string) (any, error) {
+		template, _ := templateSyntax.Parse(s)
+		for _, p := range template.Placeholders() {
+			if _, ok := templateTypes[p.Type]; !ok {
+				return nil, fmt.Errorf(`placeholder %s has an unknown type "%s"`, p, p.Type)
+			}
+			if !template.IsPlaceholder() && p.Type != "" && p.Type != "string" {
+				return nil, fmt.Errorf(`placeholder %s within a string can't be of type "%s"`, p, p.Type)
+			}
+			if p.HasDefault {
+				if _, err := convertTemplateValue(quote(p.Default), p.Type); err != nil {
+					return nil, fmt.Errorf("placeholder %s has an invalid default: %v", p, err)
+				}
+			}
+		}
+		return s, nil
+	})
+}
+
+// DropInvalidTemplates removes the stored data which fails ValidateTemplate from data, and appends an error for
+// each of them to errs. The fetchers call it when the data is loaded, so an invalid template is reported as soon
+// as it's fetched rather than on each request using it.
+func DropInvalidTemplates(dataType string, data map[string]json.RawMessage, errs []error) []error {
+	for id, value := range data {
+		if err := ValidateTemplate(value); err != nil {
+			delete(data, id)
+			errs = append(errs, fmt.Errorf("Stored %s %s is not a valid template: %v", dataType, id, err))
+		}
+	}
+	return errs
+}
+
+// ResolveTemplate returns the stored data with its placeholders replaced by the values.
+// Stored data without placeholders is returned as is.
+func ResolveTemplate(data json.RawMessage, values TemplateValues) (json.RawMessage, error) {
+	if !IsTemplate(data) {
+		return data, nil
+	}
+	value, err := decodeTemplate(data)
+	if err != nil {
+		return nil, err
+	}
+	walkErr := walkStrings(value, func(s string) (any, error) {
+		template, _ := templateSyntax.Parse(s)
+		if template.IsPlaceholder() {
+			raw, err := values.resolve(*template.Parts[0].Placeholder)
+			if err != nil {
+				return nil, err
+			}
+			return json.RawMessage(raw), nil
+		}
+
+		var sb strings.Builder
+		for _, part := range template.Parts {
+			if part.Placeholder == nil {
+				sb.WriteString(part.Literal)
+				continue
+			}
+			raw, err := values.resolve(*part.Placeholder)
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteString(text(raw))
+		}
+		return sb.String(), nil
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	return jsonutil.Marshal(value)
+}
+
+// resolve returns the JSON value of the placeholder, converted to its type.
+func (values TemplateValues) resolve(p templateutil.Placeholder) (json.RawMessage, error) {
+	raw := values.lookup(p)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		if !p.HasDefault {
+			return nil, fmt.Errorf("template parameter %s is missing", p)
+		}
+		raw = quote(p.Default)
+	}
+	converted, err := convertTemplateValue(raw, p.Type)
+	if err != nil {
+		return nil, fmt.Errorf("template parameter %s: %v", p, err)
+	}
+	return converted, nil
+}
+
+func (values TemplateValues) lookup(p templateutil.Placeholder) json.RawMessage {
+	path := strings.Join(p.Path, ".")
+	switch p.Source {
+	case "request":
+		return getPath(values.Request, path)
+	case "imp":
+		return getPath(values.Imp, path)
+	case "params":
+		return getPath(values.Params[p.Path[0]], strings.Join(p.Path[1:], "."))
+	case "amp":
+		if !values.AMP.Has(path) {
+			return nil
+		}
+		return quote(values.AMP.Get(path))
+	}
+	return nil
+}
+
+// getPath returns the value at the dot separated path of the JSON data, where numbers index arrays.
+func getPath(data json.RawMessage, path string) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	if path == "" {
+		return data
+	}
+	keys := strings.Split(path, ".")
+	for i, key := range keys {
+		if _, err := strconv.Atoi(key); err == nil {
+			keys[i] = "[" + key + "]"
+		}
+	}
+	value, dataType, _, err := jsonparser.Get(data, keys...)
+	if err != nil {
+		return nil
+	}
+	if dataType == jsonparser.String {
+		// jsonparser strips the quotes of strings but keeps their escapes, so the quotes are put back as is
+		return json.RawMessage(`"` + string(value) + `"`)
+	}
+	return value
+}
+
+// convertTemplateValue converts the JSON value to the type. Strings are parsed for the other types, so
+// text values such as query parameters can be used as numbers.
+func convertTemplateValue(raw json.RawMessage, valueType string) (json.RawMessage, error) {
+	var s string
+	isString := jsonutil.Unmarshal(raw, &s) == nil
+
+	switch valueType {
+	case "":
+		return raw, nil
+	case "string":
+		if isString {
+			return raw, nil
+		}
+		return quote(string(raw)), nil
+	case "int":
+		if !isString {
+			s = string(raw)
+		}
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(`"%s" is not an int`, s)
+		}
+		return json.RawMessage(strconv.FormatInt(i, 10)), nil
+	case "float":
+		if !isString {
+			s = string(raw)
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf(`"%s" is not a float`, s)
+		}
+		return json.RawMessage(strconv.FormatFloat(f, 'f', -1, 64)), nil
+	case "bool":
+		if !isString {
+			s = string(raw)
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf(`"%s" is not a bool`, s)
+		}
+		return json.RawMessage(strconv.FormatBool(b)), nil
+	case "json":
+		if !isString {
+			return raw, nil
+		}
+		var v any
+		if jsonutil.UnmarshalValid([]byte(s), &v) != nil {
+			return nil, errors.New("the value is not valid JSON")
+		}
+		return json.RawMessage(s), nil
+	}
+	return nil, fmt.Errorf(`unknown type "%s"`, valueType)
+}
+
+func decodeTemplate(data json.RawMessage) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// numbers are kept as written, which jsonutil can't do when decoding to any
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// walkStrings replaces the string values of the decoded JSON, including strings nested in objects and arrays,
+// by the values returned by replace. Object keys are left as is.
+func walkStrings(value any, replace func(string) (any, error)) error {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if s, ok := child.(string); ok {
+				replaced, err := replace(s)
+				if err != nil {
+					return err
+				}
+				v[key] = replaced
+			} else if err := walkStrings(child, replace); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, child := range v {
+			if s, ok := child.(string); ok {
+				replaced, err := replace(s)
+				if err != nil {
+					return err
+				}
+				v[i] = replaced
+			} else if err := walkStrings(child, replace); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// text returns the JSON value as text: strings without their quotes and other values as written.
+func text(raw json.RawMessage) string {
+	var s string
+	if err := jsonutil.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+func quote(s string) json.RawMessage {
+	quoted, _ := jsonutil.Marshal(s)
+	return quoted
+}
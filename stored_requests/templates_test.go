@@ -0,0 +1,162 @@
+package stored_requests
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTemplate(t *testing.T) {
+	assert.True(t, IsTemplate(json.RawMessage(`{"tagid":"{{params.slot}}"}`)))
+	assert.True(t, IsTemplate(json.RawMessage(`{"tagid":"slot-{{ amp.slot:string=top }}"}`)))
+	assert.False(t, IsTemplate(json.RawMessage(`{"id":"{{UUID}}"}`)), "other macros must be left as is")
+	assert.False(t, IsTemplate(json.RawMessage(`{"tagid":"slot"}`)))
+}
+
+func TestValidateTemplate(t *testing.T) {
+	testCases := []struct {
+		description   string
+		data          string
+		expectedError string
+	}{
+		{
+			description: "valid",
+			data:        `{"tagid":"{{params.slot}}","banner":{"w":"{{params.w:int=300}}"},"ext":["site-{{request.site.id:string}}"]}`,
+		},
+		{
+			description: "not-a-template",
+			data:        `{"tagid":"slot"}`,
+		},
+		{
+			description:   "unknown-type",
+			data:          `{"banner":{"w":"{{params.w:integer}}"}}`,
+			expectedError: `placeholder params.w has an unknown type "integer"`,
+		},
+		{
+			description:   "typed-within-string",
+			data:          `{"tagid":"slot-{{params.slot:int}}"}`,
+			expectedError: `placeholder params.slot within a string can't be of type "int"`,
+		},
+		{
+			description:   "invalid-default",
+			data:          `{"bidfloor":"{{params.floor:float=low}}"}`,
+			expectedError: `placeholder params.floor has an invalid default: "low" is not a float`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			err := ValidateTemplate(json.RawMessage(test.data))
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestResolveTemplate(t *testing.T) {
+	values := TemplateValues{
+		Request: json.RawMessage(`{"site":{"id":"site-1","page":"http://example.com?a=1&b=2"},"imp":[{"id":"imp-1"}],"tmax":500}`),
+		Imp:     json.RawMessage(`{"id":"imp-1","tagid":"tag-1","escaped":"a \"b\" caf\u00e9 http:\/\/x"}`),
+		Params: map[string]json.RawMessage{
+			"slot":   json.RawMessage(`"top"`),
+			"w":      json.RawMessage(`"728"`),
+			"sizes":  json.RawMessage(`[[300,250]]`),
+			"nested": json.RawMessage(`{"a":{"b":true}}`),
+			"empty":  json.RawMessage(`null`),
+		},
+		AMP: url.Values{"slot": {"amp-top"}, "floor": {"0.5"}, "keys": {`{"k":"v"}`}},
+	}
+
+	testCases := []struct {
+		description   string
+		data          string
+		expected      string
+		expectedError string
+	}{
+		{
+			description: "not-a-template",
+			data:        `{"id":"{{UUID}}","w":300}`,
+			expected:    `{"id":"{{UUID}}","w":300}`,
+		},
+		{
+			description: "request-fields",
+			data:        `{"a":"{{request.site.id}}","b":"{{request.tmax}}","c":"{{request.imp.0.id}}","d":"{{request.site.page}}"}`,
+			expected:    `{"a":"site-1","b":500,"c":"imp-1","d":"http://example.com?a=1&b=2"}`,
+		},
+		{
+			description: "imp-fields",
+			data:        `{"tagid":"{{imp.tagid}}"}`,
+			expected:    `{"tagid":"tag-1"}`,
+		},
+		{
+			description: "escaped-characters",
+			data:        `{"tagid":"{{imp.escaped}}","ext":"slot {{imp.escaped}}"}`,
+			expected:    `{"tagid":"a \"b\" café http://x","ext":"slot a \"b\" café http://x"}`,
+		},
+		{
+			description: "params",
+			data:        `{"tagid":"{{params.slot}}","format":"{{params.sizes}}","b":"{{params.nested.a.b}}"}`,
+			expected:    `{"tagid":"top","format":[[300,250]],"b":true}`,
+		},
+		{
+			description: "amp-query-params",
+			data:        `{"tagid":"{{amp.slot}}","bidfloor":"{{amp.floor:float}}","ext":"{{amp.keys:json}}"}`,
+			expected:    `{"tagid":"amp-top","bidfloor":0.5,"ext":{"k":"v"}}`,
+		},
+		{
+			description: "type-coercion",
+			data:        `{"w":"{{params.w:int}}","tmax":"{{request.tmax:string}}","test":"{{params.test:bool=1}}"}`,
+			expected:    `{"w":728,"tmax":"500","test":true}`,
+		},
+		{
+			description: "defaults",
+			data:        `{"tagid":"{{params.missing=default}}","h":"{{params.empty:int=90}}","w":"{{params.missing:int=300}}"}`,
+			expected:    `{"tagid":"default","h":90,"w":300}`,
+		},
+		{
+			description: "within-strings",
+			data:        `{"tagid":"{{request.site.id}}/{{params.slot}}/{{params.w}}","list":["x-{{ imp.id }}",1]}`,
+			expected:    `{"tagid":"site-1/top/728","list":["x-imp-1",1]}`,
+		},
+		{
+			description:   "missing",
+			data:          `{"tagid":"{{params.missing}}"}`,
+			expectedError: "template parameter params.missing is missing",
+		},
+		{
+			description:   "missing-within-string",
+			data:          `{"tagid":"slot-{{amp.missing}}"}`,
+			expectedError: "template parameter amp.missing is missing",
+		},
+		{
+			description:   "invalid-coercion",
+			data:          `{"w":"{{params.slot:int}}"}`,
+			expectedError: `template parameter params.slot: "top" is not an int`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			resolved, err := ResolveTemplate(json.RawMessage(test.data), values)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expected, string(resolved))
+		})
+	}
+}
+
+func TestResolveTemplateKeepsNumbers(t *testing.T) {
+	resolved, err := ResolveTemplate(json.RawMessage(`{"tagid":"{{params.slot}}","bidfloor":0.10000000000000000001,"id":12345678901234567890}`),
+		TemplateValues{Params: map[string]json.RawMessage{"slot": json.RawMessage(`"top"`)}})
+	require.NoError(t, err)
+	assert.Equal(t, `{"bidfloor":0.10000000000000000001,"id":12345678901234567890,"tagid":"top"}`, string(resolved))
+}
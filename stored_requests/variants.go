@@ -0,0 +1,250 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+	jsonpatch "gopkg.in/evanphx/json-patch.v5"
+)
+
+// Stored requests, imps and accounts may be versioned: instead of the data itself, the backend holds
+// the variants of the data along with their share of the traffic.
+//
+//	{"variants": [
+//	  {"id": "v2", "weight": 10, "data": {...}},
+//	  {"id": "v1", "weight": 90, "data": {...}}
+//	]}
+//
+// Every request is assigned a variant deterministically from its variant key, so the same user gets the same
+// variant as long as the weights don't change. Variants with a zero weight get no traffic and are kept to roll back to.
+// Backends return versioned data as is, so caches and invalidations work at the stored data level.
+
+const variantsKey = "variants"
+
+// Variant is a version of stored data.
+type Variant struct {
+	ID     string          `json:"id"`
+	Weight int             `json:"weight"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type versionedData struct {
+	Variants []Variant `json:"variants"`
+}
+
+// IsVersioned returns true if the stored data holds variants rather than the data itself.
+func IsVersioned(data json.RawMessage) bool {
+	_, dataType, _, err := jsonparser.Get(data, variantsKey)
+	return err == nil && dataType == jsonparser.Array
+}
+
+// ParseVariants returns the variants of versioned stored data.
+func ParseVariants(data json.RawMessage) ([]Variant, error) {
+	var versioned versionedData
+	if err := jsonutil.UnmarshalValid(data, &versioned); err != nil {
+		return nil, err
+	}
+
+	total := 0
+	ids := make(map[string]struct{}, len(versioned.Variants))
+	for i, variant := range versioned.Variants {
+		if variant.ID == "" {
+			return nil, fmt.Errorf("variant %d has no id", i)
+		}
+		if _, ok := ids[variant.ID]; ok {
+			return nil, fmt.Errorf(`variant id "%s" is not unique`, variant.ID)
+		}
+		ids[variant.ID] = struct{}{}
+		if variant.Weight < 0 {
+			return nil, fmt.Errorf(`variant "%s" has a negative weight`, variant.ID)
+		}
+		if len(variant.Data) == 0 {
+			return nil, fmt.Errorf(`variant "%s" has no data`, variant.ID)
+		}
+		total += variant.Weight
+	}
+	if total == 0 {
+		return nil, errors.New("no variant has a positive weight")
+	}
+	return versioned.Variants, nil
+}
+
+// SelectVariant returns the data of the variant assigned to the key, along with the variant ID.
+// Data which isn't versioned is returned as is, with an empty variant ID.
+func SelectVariant(dataType, id string, data json.RawMessage, key string) (json.RawMessage, string, error) {
+	if !IsVersioned(data) {
+		return data, "", nil
+	}
+
+	variants, err := ParseVariants(data)
+	if err != nil {
+		return nil, "", fmt.Errorf(`Stored %s with ID="%s" has invalid variants: %s`, dataType, id, err)
+	}
+
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+
+	// the stored data ID is part of the hash, so the traffic of different stored data is split independently
+	hash := fnv.New32a()
+	hash.Write([]byte(dataType + "\x00" + id + "\x00" + key))
+	bucket := int(hash.Sum32() % uint32(total))
+	for _, variant := range variants {
+		if bucket < variant.Weight {
+			return variant.Data, variant.ID, nil
+		}
+		bucket -= variant.Weight
+	}
+	// unreachable as the bucket is lower than the total weight
+	return nil, "", fmt.Errorf(`Stored %s with ID="%s" has invalid variants`, dataType, id)
+}
+
+// MergeAccountDefaults merges the account config on top of the defaults. For versioned accounts,
+// the defaults are merged into every variant.
+func MergeAccountDefaults(defaults, account json.RawMessage) (json.RawMessage, error) {
+	if len(defaults) == 0 {
+		return account, nil
+	}
+	if !IsVersioned(account) {
+		return jsonpatch.MergePatch(defaults, account)
+	}
+
+	var versioned versionedData
+	if err := jsonutil.UnmarshalValid(account, &versioned); err != nil {
+		return nil, err
+	}
+	for i, variant := range versioned.Variants {
+		merged, err := jsonpatch.MergePatch(defaults, variant.Data)
+		if err != nil {
+			return nil, err
+		}
+		versioned.Variants[i].Data = merged
+	}
+	return jsonutil.Marshal(versioned)
+}
+
+// VariantSelection picks the variants of the versioned stored data used by a request and records them.
+// A nil VariantSelection picks variants with an empty key and records nothing.
+type VariantSelection struct {
+	key      string
+	mu       sync.Mutex
+	selected openrtb_ext.ExtStoredVariants
+}
+
+// NewVariantSelection returns a selection assigning variants from the key, such as a user ID.
+func NewVariantSelection(key string) *VariantSelection {
+	return &VariantSelection{key: key}
+}
+
+// Requests replaces the versioned stored requests by their assigned variant.
+func (s *VariantSelection) Requests(data map[string]json.RawMessage) (map[string]json.RawMessage, []error) {
+	return s.selectAll("Request", data, func(id, variant string) {
+		s.selected.Requests = setVariant(s.selected.Requests, id, variant)
+	})
+}
+
+// Imps replaces the versioned stored imps by their assigned variant.
+func (s *VariantSelection) Imps(data map[string]json.RawMessage) (map[string]json.RawMessage, []error) {
+	return s.selectAll("Imp", data, func(id, variant string) {
+		s.selected.Imps = setVariant(s.selected.Imps, id, variant)
+	})
+}
+
+// Account returns the assigned variant of a versioned account config.
+func (s *VariantSelection) Account(id string, data json.RawMessage) (json.RawMessage, error) {
+	selected, variant, err := SelectVariant("Account", id, data, s.getKey())
+	if err != nil || variant == "" || s == nil {
+		return selected, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.selected.Account = variant
+	return selected, nil
+}
+
+// Selected returns the variants picked so far, or nil if the request uses no versioned stored data.
+func (s *VariantSelection) Selected() *openrtb_ext.ExtStoredVariants {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.selected.Requests) == 0 && len(s.selected.Imps) == 0 && s.selected.Account == "" {
+		return nil
+	}
+	return s.selected.Clone()
+}
+
+func (s *VariantSelection) selectAll(dataType string, data map[string]json.RawMessage, record func(id, variant string)) (map[string]json.RawMessage, []error) {
+	var errs []error
+	var selected map[string]json.RawMessage
+	for id, storedData := range data {
+		variantData, variant, err := SelectVariant(dataType, id, storedData, s.getKey())
+		if err == nil && variant == "" {
+			continue
+		}
+
+		// the map returned by the fetcher may be shared with a cache, so it's copied before being updated
+		if selected == nil {
+			selected = make(map[string]json.RawMessage, len(data))
+			for id, storedData := range data {
+				selected[id] = storedData
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+			delete(selected, id)
+			continue
+		}
+		selected[id] = variantData
+		if s != nil {
+			s.mu.Lock()
+			record(id, variant)
+			s.mu.Unlock()
+		}
+	}
+
+	if selected == nil {
+		return data, errs
+	}
+	return selected, errs
+}
+
+func (s *VariantSelection) getKey() string {
+	if s == nil {
+		return ""
+	}
+	return s.key
+}
+
+func setVariant(variants map[string]string, id, variant string) map[string]string {
+	if variants == nil {
+		variants = make(map[string]string)
+	}
+	variants[id] = variant
+	return variants
+}
+
+type variantSelectionKey struct{}
+
+// WithVariantSelection returns a context carrying the variant selection of the request,
+// so the account fetched for the request uses the same variant key.
+func WithVariantSelection(ctx context.Context, selection *VariantSelection) context.Context {
+	return context.WithValue(ctx, variantSelectionKey{}, selection)
+}
+
+// VariantSelectionFromContext returns the variant selection of the context, or nil.
+func VariantSelectionFromContext(ctx context.Context) *VariantSelection {
+	selection, _ := ctx.Value(variantSelectionKey{}).(*VariantSelection)
+	return selection
+}
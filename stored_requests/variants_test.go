@@ -0,0 +1,179 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVariants(t *testing.T) {
+	testCases := []struct {
+		description   string
+		data          string
+		expectedError string
+	}{
+		{
+			description: "valid",
+			data:        `{"variants":[{"id":"v2","weight":10,"data":{"b":2}},{"id":"v1","weight":0,"data":{"a":1}}]}`,
+		},
+		{
+			description:   "missing-id",
+			data:          `{"variants":[{"weight":10,"data":{}}]}`,
+			expectedError: "variant 0 has no id",
+		},
+		{
+			description:   "duplicate-id",
+			data:          `{"variants":[{"id":"v1","weight":10,"data":{}},{"id":"v1","weight":10,"data":{}}]}`,
+			expectedError: `variant id "v1" is not unique`,
+		},
+		{
+			description:   "negative-weight",
+			data:          `{"variants":[{"id":"v1","weight":-1,"data":{}}]}`,
+			expectedError: `variant "v1" has a negative weight`,
+		},
+		{
+			description:   "missing-data",
+			data:          `{"variants":[{"id":"v1","weight":1}]}`,
+			expectedError: `variant "v1" has no data`,
+		},
+		{
+			description:   "no-traffic",
+			data:          `{"variants":[{"id":"v1","weight":0,"data":{}}]}`,
+			expectedError: "no variant has a positive weight",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			variants, err := ParseVariants(json.RawMessage(test.data))
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, variants, 2)
+		})
+	}
+}
+
+func TestSelectVariant(t *testing.T) {
+	data := json.RawMessage(`{"variants":[{"id":"v2","weight":25,"data":{"v":2}},{"id":"v1","weight":75,"data":{"v":1}},{"id":"v0","weight":0,"data":{"v":0}}]}`)
+
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("user%d", i)
+		selected, variant, err := SelectVariant("Request", "req1", data, key)
+		require.NoError(t, err)
+		assert.JSONEq(t, fmt.Sprintf(`{"v":%s}`, variant[1:]), string(selected))
+		counts[variant]++
+
+		again, _, _ := SelectVariant("Request", "req1", data, key)
+		assert.Equal(t, selected, again, "the variant must be the same for a key")
+	}
+	assert.InDelta(t, 2500, counts["v2"], 250)
+	assert.InDelta(t, 7500, counts["v1"], 250)
+	assert.Zero(t, counts["v0"])
+}
+
+func TestSelectVariantNotVersioned(t *testing.T) {
+	data := json.RawMessage(`{"id":"req1","variants":{}}`)
+	selected, variant, err := SelectVariant("Request", "req1", data, "user1")
+	assert.NoError(t, err)
+	assert.Empty(t, variant)
+	assert.Equal(t, data, selected)
+}
+
+func TestSelectVariantInvalid(t *testing.T) {
+	_, _, err := SelectVariant("Imp", "imp1", json.RawMessage(`{"variants":[]}`), "user1")
+	assert.EqualError(t, err, `Stored Imp with ID="imp1" has invalid variants: no variant has a positive weight`)
+}
+
+func TestMergeAccountDefaults(t *testing.T) {
+	testCases := []struct {
+		description string
+		defaults    string
+		account     string
+		expected    string
+	}{
+		{
+			description: "no-defaults",
+			account:     `{"id":"a1"}`,
+			expected:    `{"id":"a1"}`,
+		},
+		{
+			description: "not-versioned",
+			defaults:    `{"disabled":false,"events":{"enabled":false}}`,
+			account:     `{"id":"a1","events":{"enabled":true}}`,
+			expected:    `{"id":"a1","disabled":false,"events":{"enabled":true}}`,
+		},
+		{
+			description: "versioned",
+			defaults:    `{"disabled":false,"events":{"enabled":false}}`,
+			account:     `{"variants":[{"id":"v2","weight":1,"data":{"events":{"enabled":true}}},{"id":"v1","weight":1,"data":{}}]}`,
+			expected:    `{"variants":[{"id":"v2","weight":1,"data":{"disabled":false,"events":{"enabled":true}}},{"id":"v1","weight":1,"data":{"disabled":false,"events":{"enabled":false}}}]}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			var defaults json.RawMessage
+			if test.defaults != "" {
+				defaults = json.RawMessage(test.defaults)
+			}
+			merged, err := MergeAccountDefaults(defaults, json.RawMessage(test.account))
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expected, string(merged))
+		})
+	}
+}
+
+func TestVariantSelection(t *testing.T) {
+	storedRequests := map[string]json.RawMessage{
+		"req1": json.RawMessage(`{"variants":[{"id":"v1","weight":1,"data":{"id":"req1"}}]}`),
+		"req2": json.RawMessage(`{"id":"req2"}`),
+	}
+	storedImps := map[string]json.RawMessage{
+		"imp1": json.RawMessage(`{"variants":[{"id":"v3","weight":1,"data":{"id":"imp1"}},{"id":"v2","weight":0,"data":{}}]}`),
+		"imp2": json.RawMessage(`{"variants":[{"id":"v1","weight":0,"data":{}}]}`),
+	}
+
+	selection := NewVariantSelection("user1")
+	ctx := WithVariantSelection(context.Background(), selection)
+	assert.Same(t, selection, VariantSelectionFromContext(ctx))
+	assert.Nil(t, selection.Selected())
+
+	requests, errs := VariantSelectionFromContext(ctx).Requests(storedRequests)
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]json.RawMessage{"req1": json.RawMessage(`{"id":"req1"}`), "req2": json.RawMessage(`{"id":"req2"}`)}, requests)
+	assert.JSONEq(t, `{"variants":[{"id":"v1","weight":1,"data":{"id":"req1"}}]}`, string(storedRequests["req1"]), "the fetched data must not be modified")
+
+	imps, errs := selection.Imps(storedImps)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, map[string]json.RawMessage{"imp1": json.RawMessage(`{"id":"imp1"}`)}, imps)
+
+	account, err := selection.Account("acct1", json.RawMessage(`{"variants":[{"id":"a","weight":1,"data":{"id":"acct1"}}]}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"acct1"}`, string(account))
+
+	expected := &openrtb_ext.ExtStoredVariants{
+		Requests: map[string]string{"req1": "v1"},
+		Imps:     map[string]string{"imp1": "v3"},
+		Account:  "a",
+	}
+	assert.Equal(t, expected, selection.Selected())
+}
+
+func TestNilVariantSelection(t *testing.T) {
+	selection := VariantSelectionFromContext(context.Background())
+	assert.Nil(t, selection)
+
+	requests, errs := selection.Requests(map[string]json.RawMessage{"req1": json.RawMessage(`{"variants":[{"id":"v1","weight":1,"data":{"id":"req1"}}]}`)})
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]json.RawMessage{"req1": json.RawMessage(`{"id":"req1"}`)}, requests)
+	assert.Nil(t, selection.Selected())
+}
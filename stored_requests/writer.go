@@ -0,0 +1,28 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// StoredDataType is the kind of stored data saved or deleted by a Writer. The values match
+// the type column used by the database queries.
+type StoredDataType string
+
+const (
+	StoredRequestType  StoredDataType = "request"
+	StoredImpType      StoredDataType = "imp"
+	StoredResponseType StoredDataType = "response"
+	StoredAccountType  StoredDataType = "account"
+)
+
+// Writer persists stored data to the backend read by a Fetcher.
+//
+// Implementations must be safe for concurrent access by multiple goroutines.
+type Writer interface {
+	// Save creates or replaces the stored data with the given type and ID.
+	Save(ctx context.Context, dataType StoredDataType, id string, data json.RawMessage) error
+	// Delete removes the stored data with the given type and ID.
+	// A NotFoundError is returned if it doesn't exist.
+	Delete(ctx context.Context, dataType StoredDataType, id string) error
+}
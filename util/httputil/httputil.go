@@ -1,6 +1,7 @@
 package httputil
 
 import (
+	"crypto/subtle"
 	"net"
 	"net/http"
 	"strings"
@@ -85,3 +86,20 @@ func findRemoteAddr(r *http.Request, v iputil.IPValidator) (net.IP, iputil.IPVer
 	}
 	return nil, iputil.IPvUnknown
 }
+
+// BearerTokenIndex returns the index of the token of the "Authorization: Bearer {token}" header of the request
+// among the tokens, or -1 if the request has none of them. Every token is compared in constant time, so the
+// response time doesn't tell which token was close.
+func BearerTokenIndex(r *http.Request, tokens []string) int {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return -1
+	}
+	index := -1
+	for i, expected := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			index = i
+		}
+	}
+	return index
+}
@@ -263,3 +263,72 @@ type hardcodedResponseIPValidator struct {
 func (v hardcodedResponseIPValidator) IsValid(net.IP, iputil.IPVersion) bool {
 	return v.response
 }
+
+func TestBearerTokenIndex(t *testing.T) {
+	tokens := []string{"token1", "token2"}
+
+	testCases := []struct {
+		description   string
+		authorization string
+		tokens        []string
+		expectedIndex int
+	}{
+		{
+			description:   "first_token",
+			authorization: "Bearer token1",
+			tokens:        tokens,
+			expectedIndex: 0,
+		},
+		{
+			description:   "second_token",
+			authorization: "Bearer token2",
+			tokens:        tokens,
+			expectedIndex: 1,
+		},
+		{
+			description:   "unknown_token",
+			authorization: "Bearer token3",
+			tokens:        tokens,
+			expectedIndex: -1,
+		},
+		{
+			description:   "token_prefix",
+			authorization: "Bearer token",
+			tokens:        tokens,
+			expectedIndex: -1,
+		},
+		{
+			description:   "no_header",
+			tokens:        tokens,
+			expectedIndex: -1,
+		},
+		{
+			description:   "not_bearer",
+			authorization: "Basic token1",
+			tokens:        tokens,
+			expectedIndex: -1,
+		},
+		{
+			description:   "empty_token",
+			authorization: "Bearer ",
+			tokens:        []string{""},
+			expectedIndex: -1,
+		},
+		{
+			description:   "no_tokens",
+			authorization: "Bearer token1",
+			expectedIndex: -1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			assert.NoError(t, err)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			assert.Equal(t, test.expectedIndex, BearerTokenIndex(req, test.tokens))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package task
+
+import (
+	"sync"
+	"time"
+)
+
+// ReloadTask periodically reloads data in the background. It keeps the error of the last reload, so
+// the owner of the data can keep serving what was previously loaded and report it as stale.
+type ReloadTask struct {
+	ticker   *TickerTask
+	err      error
+	mu       sync.RWMutex
+	stopOnce sync.Once
+}
+
+func NewReloadTask(interval time.Duration, reload func() error) *ReloadTask {
+	t := &ReloadTask{}
+	t.ticker = NewTickerTaskFromFunc(interval, func() error {
+		err := reload()
+		t.mu.Lock()
+		t.err = err
+		t.mu.Unlock()
+		return err
+	})
+	return t
+}
+
+// Start reloads the data immediately and then every interval until the task is stopped.
+func (t *ReloadTask) Start() {
+	t.ticker.Start()
+}
+
+// Stop stops the periodic reloads. It's safe to call more than once.
+func (t *ReloadTask) Stop() {
+	t.stopOnce.Do(t.ticker.Stop)
+}
+
+// LastError returns the error of the last reload, nil once a reload succeeds.
+func (t *ReloadTask) LastError() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.err
+}
@@ -0,0 +1,41 @@
+package task_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/util/task"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadTaskLastError(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	reloadTask := task.NewReloadTask(10*time.Millisecond, func() error {
+		if failing.Load() {
+			return errors.New("reload failed")
+		}
+		return nil
+	})
+
+	assert.NoError(t, reloadTask.LastError(), "no error before the first reload")
+
+	reloadTask.Start()
+	defer reloadTask.Stop()
+	assert.EqualError(t, reloadTask.LastError(), "reload failed", "the first reload runs on start")
+
+	failing.Store(false)
+	assert.Eventually(t, func() bool { return reloadTask.LastError() == nil }, time.Second, 10*time.Millisecond)
+}
+
+func TestReloadTaskStopTwice(t *testing.T) {
+	reloadTask := task.NewReloadTask(10*time.Millisecond, func() error { return nil })
+	reloadTask.Start()
+
+	assert.NotPanics(t, func() {
+		reloadTask.Stop()
+		reloadTask.Stop()
+	})
+}
@@ -0,0 +1,168 @@
+package templateutil
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The templates of the stored data and the account config share one syntax. Literal text is kept as is and every
+// {{source.path}} placeholder is replaced by the value it resolves to. A placeholder may be followed by a :type the
+// value is converted to and a =default used when the value is missing, e.g. {{params.width:int=300}} or
+// {{imp.tagid=none}}. The sources and types depend on where the template is used.
+
+const (
+	placeholderStart = "{{"
+	placeholderEnd   = "}}"
+	typeDelimiter    = ":"
+	defaultDelimiter = "="
+	pathDelimiter    = "."
+)
+
+var (
+	typePattern        = regexp.MustCompile(`^[A-Za-z]+$`)
+	pathSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9_\-]+$`)
+)
+
+// Syntax defines the placeholders of a kind of template.
+type Syntax struct {
+	// Sources maps the sources of the placeholders to whether they must be followed by a path, e.g. "bid" in
+	// {{bid.w}}.
+	Sources map[string]bool
+	// Lenient keeps the {{...}} text which isn't a valid placeholder as literal text rather than failing, for
+	// templates where such text may already mean something else, e.g. the {{UUID}} macro of the stored requests.
+	Lenient bool
+}
+
+// Template is a parsed template.
+type Template struct {
+	Parts []Part
+}
+
+// Part is either literal text or a placeholder.
+type Part struct {
+	Literal     string
+	Placeholder *Placeholder
+}
+
+// Placeholder is a {{source.path:type=default}} placeholder of a template.
+type Placeholder struct {
+	Source     string
+	Path       []string
+	Type       string
+	Default    string
+	HasDefault bool
+}
+
+// String returns the source and path of the placeholder, e.g. "bid.ext.dealid".
+func (p Placeholder) String() string {
+	return strings.Join(append([]string{p.Source}, p.Path...), pathDelimiter)
+}
+
+// Parse parses the template text.
+func (s Syntax) Parse(text string) (Template, error) {
+	var template Template
+	var literal strings.Builder
+
+	remaining := text
+	for len(remaining) > 0 {
+		start := strings.Index(remaining, placeholderStart)
+		if start < 0 {
+			if !s.Lenient && strings.Contains(remaining, placeholderEnd) {
+				return Template{}, fmt.Errorf("unexpected %s in template %q", placeholderEnd, text)
+			}
+			literal.WriteString(remaining)
+			break
+		}
+		if !s.Lenient && strings.Contains(remaining[:start], placeholderEnd) {
+			return Template{}, fmt.Errorf("unexpected %s in template %q", placeholderEnd, text)
+		}
+		literal.WriteString(remaining[:start])
+
+		rest := remaining[start+len(placeholderStart):]
+		end := strings.Index(rest, placeholderEnd)
+		if end < 0 {
+			if !s.Lenient {
+				return Template{}, fmt.Errorf("unterminated placeholder in template %q", text)
+			}
+			literal.WriteString(remaining[start:])
+			break
+		}
+
+		placeholder, err := s.parsePlaceholder(rest[:end])
+		if err != nil {
+			if !s.Lenient {
+				return Template{}, fmt.Errorf("%v in template %q", err, text)
+			}
+			// the braces are kept as text, and a placeholder may still start right after them
+			literal.WriteString(placeholderStart)
+			remaining = rest
+			continue
+		}
+
+		if literal.Len() > 0 {
+			template.Parts = append(template.Parts, Part{Literal: literal.String()})
+			literal.Reset()
+		}
+		template.Parts = append(template.Parts, Part{Placeholder: &placeholder})
+		remaining = rest[end+len(placeholderEnd):]
+	}
+
+	if literal.Len() > 0 {
+		template.Parts = append(template.Parts, Part{Literal: literal.String()})
+	}
+	return template, nil
+}
+
+func (s Syntax) parsePlaceholder(expr string) (Placeholder, error) {
+	var p Placeholder
+
+	if strings.Contains(expr, placeholderStart) {
+		return p, errors.New("nested placeholder")
+	}
+
+	expr, p.Default, p.HasDefault = strings.Cut(expr, defaultDelimiter)
+	expr = strings.TrimSpace(expr)
+	expr, p.Type, _ = strings.Cut(expr, typeDelimiter)
+	if p.Type != "" && !typePattern.MatchString(p.Type) {
+		return p, fmt.Errorf("invalid type %q", p.Type)
+	}
+
+	segments := strings.Split(expr, pathDelimiter)
+	p.Source = segments[0]
+	p.Path = segments[1:]
+
+	requiresPath, ok := s.Sources[p.Source]
+	if !ok {
+		return p, fmt.Errorf("unknown source %q", p.Source)
+	}
+	if requiresPath && len(p.Path) == 0 {
+		return p, fmt.Errorf("source %q requires a path", p.Source)
+	}
+	if !requiresPath && len(p.Path) > 0 {
+		return p, fmt.Errorf("source %q does not accept a path", p.Source)
+	}
+	for _, segment := range p.Path {
+		if !pathSegmentPattern.MatchString(segment) {
+			return p, fmt.Errorf("invalid path segment %q in %q", segment, expr)
+		}
+	}
+	return p, nil
+}
+
+// Placeholders returns the placeholders of the template.
+func (t Template) Placeholders() []Placeholder {
+	var placeholders []Placeholder
+	for _, part := range t.Parts {
+		if part.Placeholder != nil {
+			placeholders = append(placeholders, *part.Placeholder)
+		}
+	}
+	return placeholders
+}
+
+// IsPlaceholder returns true if the template is a single placeholder without literal text.
+func (t Template) IsPlaceholder() bool {
+	return len(t.Parts) == 1 && t.Parts[0].Placeholder != nil
+}
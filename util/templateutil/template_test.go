@@ -0,0 +1,144 @@
+package templateutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSyntax = Syntax{Sources: map[string]bool{"bid": true, "bidder": false}}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name             string
+		syntax           Syntax
+		text             string
+		expectedTemplate Template
+		expectedErr      string
+	}{
+		{
+			name:   "empty",
+			syntax: testSyntax,
+			text:   "",
+		},
+		{
+			name:             "literal",
+			syntax:           testSyntax,
+			text:             "static",
+			expectedTemplate: Template{Parts: []Part{{Literal: "static"}}},
+		},
+		{
+			name:   "placeholders",
+			syntax: testSyntax,
+			text:   "{{bid.w}}x{{ bid.h }}_{{bidder}}",
+			expectedTemplate: Template{Parts: []Part{
+				{Placeholder: &Placeholder{Source: "bid", Path: []string{"w"}}},
+				{Literal: "x"},
+				{Placeholder: &Placeholder{Source: "bid", Path: []string{"h"}}},
+				{Literal: "_"},
+				{Placeholder: &Placeholder{Source: "bidder", Path: []string{}}},
+			}},
+		},
+		{
+			name:   "type-and-default",
+			syntax: testSyntax,
+			text:   "{{bid.ext.deal-id:string=no deal}}",
+			expectedTemplate: Template{Parts: []Part{
+				{Placeholder: &Placeholder{Source: "bid", Path: []string{"ext", "deal-id"}, Type: "string", Default: "no deal", HasDefault: true}},
+			}},
+		},
+		{
+			name:   "empty-default",
+			syntax: testSyntax,
+			text:   "{{bid.dealid=}}",
+			expectedTemplate: Template{Parts: []Part{
+				{Placeholder: &Placeholder{Source: "bid", Path: []string{"dealid"}, HasDefault: true}},
+			}},
+		},
+		{
+			name:        "unterminated",
+			syntax:      testSyntax,
+			text:        "{{bid.w",
+			expectedErr: `unterminated placeholder in template "{{bid.w"`,
+		},
+		{
+			name:        "unexpected-end",
+			syntax:      testSyntax,
+			text:        "bid}}",
+			expectedErr: `unexpected }} in template "bid}}"`,
+		},
+		{
+			name:        "nested",
+			syntax:      testSyntax,
+			text:        "{{bid.{{bid.w}}}}",
+			expectedErr: `nested placeholder in template "{{bid.{{bid.w}}}}"`,
+		},
+		{
+			name:        "unknown-source",
+			syntax:      testSyntax,
+			text:        "{{seat.id}}",
+			expectedErr: `unknown source "seat" in template "{{seat.id}}"`,
+		},
+		{
+			name:        "missing-path",
+			syntax:      testSyntax,
+			text:        "{{bid}}",
+			expectedErr: `source "bid" requires a path in template "{{bid}}"`,
+		},
+		{
+			name:        "unexpected-path",
+			syntax:      testSyntax,
+			text:        "{{bidder.name}}",
+			expectedErr: `source "bidder" does not accept a path in template "{{bidder.name}}"`,
+		},
+		{
+			name:        "empty-segment",
+			syntax:      testSyntax,
+			text:        "{{bid.ext..id}}",
+			expectedErr: `invalid path segment "" in "bid.ext..id" in template "{{bid.ext..id}}"`,
+		},
+		{
+			name:        "invalid-type",
+			syntax:      testSyntax,
+			text:        "{{bid.w:int32}}",
+			expectedErr: `invalid type "int32" in template "{{bid.w:int32}}"`,
+		},
+		{
+			name:   "lenient-keeps-invalid-placeholders",
+			syntax: Syntax{Sources: testSyntax.Sources, Lenient: true},
+			text:   "{{UUID}}-{{{{bid.w}}}}-{{bid.h",
+			expectedTemplate: Template{Parts: []Part{
+				{Literal: "{{UUID}}-{{"},
+				{Placeholder: &Placeholder{Source: "bid", Path: []string{"w"}}},
+				{Literal: "}}-{{bid.h"},
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			template, err := test.syntax.Parse(test.text)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedTemplate, template)
+		})
+	}
+}
+
+func TestTemplatePlaceholders(t *testing.T) {
+	template, err := testSyntax.Parse("{{bid.w}}x{{bid.h=0}}")
+	assert.NoError(t, err)
+	assert.Equal(t, []Placeholder{
+		{Source: "bid", Path: []string{"w"}},
+		{Source: "bid", Path: []string{"h"}, HasDefault: true, Default: "0"},
+	}, template.Placeholders())
+	assert.False(t, template.IsPlaceholder())
+
+	template, err = testSyntax.Parse("{{bid.w}}")
+	assert.NoError(t, err)
+	assert.True(t, template.IsPlaceholder())
+	assert.Equal(t, "bid.w", template.Placeholders()[0].String())
+}
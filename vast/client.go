@@ -0,0 +1,84 @@
+package vast
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// maxWrapperRedirects bounds the redirects followed when fetching a wrapped VAST document.
+const maxWrapperRedirects = 3
+
+// nonPublicPrefixes are the special purpose ranges which aren't caught by the netip.Addr methods.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// NewWrapperClient builds the client following the VASTAdTagURI of the wrappers. These URIs come from the bidders,
+// so the client only connects to public IPs over http or https, follows a few redirects at most and gives up
+// after the timeout. It doesn't use the proxy of the environment, since the proxy would connect to the host
+// instead of the checked IP.
+func NewWrapperClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: rejectNonPublicIP,
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxWrapperRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxWrapperRedirects)
+			}
+			return checkScheme(req.URL)
+		},
+	}
+}
+
+// rejectNonPublicIP prevents the connections to the loopback, private, link-local and other special purpose
+// addresses, once the host name is resolved.
+func rejectNonPublicIP(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublic(addrPort.Addr()) {
+		return fmt.Errorf("%s is not a public address", addrPort.Addr())
+	}
+	return nil
+}
+
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("only http and https URIs are followed")
+	}
+	return nil
+}
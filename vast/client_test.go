@@ -0,0 +1,92 @@
+package vast
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublic(t *testing.T) {
+	testCases := []struct {
+		address  string
+		expected bool
+	}{
+		{address: "8.8.8.8", expected: true},
+		{address: "2001:4860:4860::8888", expected: true},
+		{address: "127.0.0.1", expected: false},
+		{address: "::1", expected: false},
+		{address: "10.1.2.3", expected: false},
+		{address: "172.16.0.1", expected: false},
+		{address: "192.168.1.1", expected: false},
+		{address: "169.254.169.254", expected: false},
+		{address: "fe80::1", expected: false},
+		{address: "fd00::1", expected: false},
+		{address: "100.64.0.1", expected: false},
+		{address: "0.0.0.0", expected: false},
+		{address: "::ffff:127.0.0.1", expected: false},
+		{address: "::ffff:169.254.169.254", expected: false},
+		{address: "224.0.0.1", expected: false},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.address, func(t *testing.T) {
+			assert.Equal(t, test.expected, isPublic(netip.MustParseAddr(test.address)))
+		})
+	}
+}
+
+func TestWrapperClientRejectsLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(inlineMarkup))
+	}))
+	defer server.Close()
+
+	processor := NewProcessor(NewWrapperClient(time.Second), 1)
+	result, err := processor.Process(context.Background(), wrapperMarkup(server.URL), nil)
+
+	assert.ErrorContains(t, err, "is not a public address")
+	assert.False(t, result.Resolved)
+}
+
+func TestFetchRejectsScheme(t *testing.T) {
+	processor := NewProcessor(&http.Client{}, 1)
+	_, err := processor.Process(context.Background(), wrapperMarkup("file:///etc/passwd"), nil)
+
+	assert.ErrorContains(t, err, "only http and https URIs are followed")
+}
+
+func TestWrapperClientRedirects(t *testing.T) {
+	redirects := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirects++
+		http.Redirect(w, r, server.URL, http.StatusFound)
+	}))
+	defer server.Close()
+
+	// the dialer of NewWrapperClient can't reach the test server, so only its redirect policy is used
+	client := server.Client()
+	client.CheckRedirect = NewWrapperClient(time.Second).CheckRedirect
+	_, err := client.Get(server.URL)
+
+	assert.ErrorContains(t, err, "stopped after 3 redirects")
+	assert.Equal(t, maxWrapperRedirects+1, redirects)
+}
+
+func TestWrapperClientRedirectScheme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "gopher://internal/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := server.Client()
+	client.CheckRedirect = NewWrapperClient(time.Second).CheckRedirect
+	_, err := client.Get(server.URL)
+
+	assert.ErrorContains(t, err, "only http and https URIs are followed")
+}
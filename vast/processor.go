@@ -0,0 +1,152 @@
+package vast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+)
+
+// maxWrapperResponseBytes bounds the size of a wrapped VAST document read from a VASTAdTagURI.
+const maxWrapperResponseBytes = 1 << 20
+
+// Result describes the outcome of processing a VAST creative.
+type Result struct {
+	// Duration is the duration of the linear creative, or zero when it could not be determined.
+	Duration time.Duration
+	// WrapperDepth is the number of wrappers followed to reach the inline document.
+	WrapperDepth int
+	// Resolved is true when the inline document was reached and its media was validated.
+	Resolved bool
+}
+
+// DurationSeconds returns the duration of the creative rounded up to whole seconds.
+func (r Result) DurationSeconds() int {
+	return int(math.Ceil(r.Duration.Seconds()))
+}
+
+// Processor parses and validates VAST creatives against the video object of the imp they bid on.
+type Processor struct {
+	client          *http.Client
+	maxWrapperDepth int
+}
+
+// NewProcessor builds a Processor which follows up to maxWrapperDepth wrappers using the client, which should
+// come from NewWrapperClient. A depth of zero disables wrapper unwrapping.
+func NewProcessor(client *http.Client, maxWrapperDepth int) *Processor {
+	return &Processor{
+		client:          client,
+		maxWrapperDepth: maxWrapperDepth,
+	}
+}
+
+// Process validates the markup against the video object. Wrappers are followed until an inline document
+// is found, the depth limit is reached or the context expires. In the last two cases the media files
+// can't be checked and the result is reported as unresolved rather than invalid.
+func (p *Processor) Process(ctx context.Context, markup string, video *openrtb2.Video) (Result, error) {
+	var result Result
+
+	doc, err := Parse([]byte(markup))
+	if err != nil {
+		return result, err
+	}
+	if video != nil && len(video.Protocols) > 0 && !slices.Contains(video.Protocols, doc.Protocol()) {
+		return result, fmt.Errorf("VAST %s protocol %d is not allowed by the imp", doc.Version, doc.Protocol())
+	}
+
+	for doc.IsWrapper() {
+		if result.WrapperDepth >= p.maxWrapperDepth || p.client == nil {
+			return result, nil
+		}
+		uri := doc.WrapperURI()
+		doc, err = p.fetch(ctx, uri)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, nil
+			}
+			return result, fmt.Errorf("wrapper %s did not resolve: %v", uri, err)
+		}
+		result.WrapperDepth++
+	}
+
+	result.Resolved = true
+	result.Duration, err = validateInLine(doc, video)
+	return result, err
+}
+
+func (p *Processor) fetch(ctx context.Context, uri string) (*Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkScheme(req.URL); err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxWrapperResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(body)
+}
+
+// validateInLine checks that the inline document has a playable linear creative and returns its duration.
+func validateInLine(doc *Document, video *openrtb2.Video) (time.Duration, error) {
+	linears := doc.linears()
+	if len(linears) == 0 {
+		// Non-linear and companion only ads have no duration or media files to check.
+		return 0, nil
+	}
+
+	var duration time.Duration
+	for _, linear := range linears {
+		d, err := ParseDuration(linear.Duration)
+		if err != nil {
+			return 0, err
+		}
+		duration = max(duration, d)
+		if len(linear.MediaFiles) == 0 {
+			return 0, errors.New("linear creative has no media files")
+		}
+		if video != nil && len(video.MIMEs) > 0 && !hasAllowedMediaFile(linear.MediaFiles, video.MIMEs) {
+			return 0, fmt.Errorf("no media file matches the imp mimes %s", strings.Join(video.MIMEs, ","))
+		}
+	}
+
+	if video != nil {
+		seconds := Result{Duration: duration}.DurationSeconds()
+		if video.MinDuration > 0 && int64(seconds) < video.MinDuration {
+			return 0, fmt.Errorf("duration %ds is less than the imp minduration %ds", seconds, video.MinDuration)
+		}
+		if video.MaxDuration > 0 && int64(seconds) > video.MaxDuration {
+			return 0, fmt.Errorf("duration %ds is greater than the imp maxduration %ds", seconds, video.MaxDuration)
+		}
+	}
+	return duration, nil
+}
+
+func hasAllowedMediaFile(mediaFiles []MediaFile, mimes []string) bool {
+	for _, mediaFile := range mediaFiles {
+		for _, mime := range mimes {
+			if strings.EqualFold(strings.TrimSpace(mediaFile.Type), mime) {
+				return true
+			}
+		}
+	}
+	return false
+}
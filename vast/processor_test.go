@@ -0,0 +1,189 @@
+package vast
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/stretchr/testify/assert"
+)
+
+const inlineMarkup = `<VAST version="3.0"><Ad><InLine><Creatives><Creative><Linear><Duration>00:00:14.2</Duration><MediaFiles><MediaFile type="video/mp4" delivery="progressive" width="640" height="360"><![CDATA[https://cdn.test/ad.mp4]]></MediaFile></MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>`
+
+func wrapperMarkup(uri string) string {
+	return fmt.Sprintf(`<VAST version="3.0"><Ad><Wrapper><VASTAdTagURI><![CDATA[%s]]></VASTAdTagURI></Wrapper></Ad></VAST>`, uri)
+}
+
+func TestProcessInLine(t *testing.T) {
+	testCases := []struct {
+		name             string
+		markup           string
+		video            *openrtb2.Video
+		expectedDuration int
+		expectedErr      string
+	}{
+		{
+			name:             "no-video-object",
+			markup:           inlineMarkup,
+			expectedDuration: 15,
+		},
+		{
+			name:             "valid",
+			markup:           inlineMarkup,
+			video:            &openrtb2.Video{MIMEs: []string{"video/webm", "video/mp4"}, MinDuration: 5, MaxDuration: 15, Protocols: []adcom1.MediaCreativeSubtype{adcom1.CreativeVAST30}},
+			expectedDuration: 15,
+		},
+		{
+			name:        "protocol-not-allowed",
+			markup:      inlineMarkup,
+			video:       &openrtb2.Video{Protocols: []adcom1.MediaCreativeSubtype{adcom1.CreativeVAST20, adcom1.CreativeVAST30Wrapper}},
+			expectedErr: "VAST 3.0 protocol 3 is not allowed by the imp",
+		},
+		{
+			name:        "mime-not-allowed",
+			markup:      inlineMarkup,
+			video:       &openrtb2.Video{MIMEs: []string{"video/webm"}},
+			expectedErr: "no media file matches the imp mimes video/webm",
+		},
+		{
+			name:        "too-short",
+			markup:      inlineMarkup,
+			video:       &openrtb2.Video{MinDuration: 20},
+			expectedErr: "duration 15s is less than the imp minduration 20s",
+		},
+		{
+			name:        "too-long",
+			markup:      inlineMarkup,
+			video:       &openrtb2.Video{MaxDuration: 10},
+			expectedErr: "duration 15s is greater than the imp maxduration 10s",
+		},
+		{
+			name:        "no-media-files",
+			markup:      `<VAST version="3.0"><Ad><InLine><Creatives><Creative><Linear><Duration>00:00:15</Duration></Linear></Creative></Creatives></InLine></Ad></VAST>`,
+			expectedErr: "linear creative has no media files",
+		},
+		{
+			name:        "invalid-duration",
+			markup:      `<VAST version="3.0"><Ad><InLine><Creatives><Creative><Linear><Duration>15s</Duration></Linear></Creative></Creatives></InLine></Ad></VAST>`,
+			expectedErr: `invalid duration "15s"`,
+		},
+		{
+			name:   "non-linear-only",
+			markup: `<VAST version="3.0"><Ad><InLine><Creatives><Creative></Creative></Creatives></InLine></Ad></VAST>`,
+			video:  &openrtb2.Video{MIMEs: []string{"video/mp4"}, MinDuration: 5},
+		},
+	}
+
+	processor := NewProcessor(nil, 0)
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := processor.Process(context.Background(), test.markup, test.video)
+			if len(test.expectedErr) > 0 {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, result.Resolved)
+			assert.Equal(t, test.expectedDuration, result.DurationSeconds())
+		})
+	}
+}
+
+func TestProcessWrapper(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/inline", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(inlineMarkup))
+	})
+	mux.HandleFunc("/wrapper", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(wrapperMarkup(server.URL + "/inline")))
+	})
+	mux.HandleFunc("/invalid", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<VAST"))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+
+	video := &openrtb2.Video{MIMEs: []string{"video/mp4"}, Protocols: []adcom1.MediaCreativeSubtype{adcom1.CreativeVAST30, adcom1.CreativeVAST30Wrapper}}
+
+	testCases := []struct {
+		name           string
+		markup         string
+		maxDepth       int
+		timeout        time.Duration
+		expectedResult Result
+		expectedErr    string
+	}{
+		{
+			name:           "unwrapping-disabled",
+			markup:         wrapperMarkup(server.URL + "/inline"),
+			maxDepth:       0,
+			expectedResult: Result{},
+		},
+		{
+			name:           "one-wrapper",
+			markup:         wrapperMarkup(server.URL + "/inline"),
+			maxDepth:       1,
+			expectedResult: Result{Duration: 14200 * time.Millisecond, WrapperDepth: 1, Resolved: true},
+		},
+		{
+			name:           "two-wrappers",
+			markup:         wrapperMarkup(server.URL + "/wrapper"),
+			maxDepth:       5,
+			expectedResult: Result{Duration: 14200 * time.Millisecond, WrapperDepth: 2, Resolved: true},
+		},
+		{
+			name:           "depth-limit-reached",
+			markup:         wrapperMarkup(server.URL + "/wrapper"),
+			maxDepth:       1,
+			expectedResult: Result{WrapperDepth: 1},
+		},
+		{
+			name:        "wrapped-vast-invalid",
+			markup:      wrapperMarkup(server.URL + "/invalid"),
+			maxDepth:    1,
+			expectedErr: "wrapper " + server.URL + "/invalid did not resolve: malformed VAST",
+		},
+		{
+			name:        "wrapped-vast-not-found",
+			markup:      wrapperMarkup(server.URL + "/missing"),
+			maxDepth:    1,
+			expectedErr: "wrapper " + server.URL + "/missing did not resolve: unexpected status code 404",
+		},
+		{
+			name:           "deadline-exceeded",
+			markup:         wrapperMarkup(server.URL + "/slow"),
+			maxDepth:       1,
+			timeout:        10 * time.Millisecond,
+			expectedResult: Result{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, test.timeout)
+				defer cancel()
+			}
+
+			result, err := NewProcessor(server.Client(), test.maxDepth).Process(ctx, test.markup, video)
+			if len(test.expectedErr) > 0 {
+				assert.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedResult, result)
+		})
+	}
+}
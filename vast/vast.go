@@ -0,0 +1,168 @@
+package vast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+)
+
+// Document is the subset of a VAST 2.0 - 4.3 document needed to decide whether a creative can play.
+type Document struct {
+	XMLName xml.Name `xml:"VAST"`
+	Version string   `xml:"version,attr"`
+	Ads     []Ad     `xml:"Ad"`
+}
+
+type Ad struct {
+	ID      string   `xml:"id,attr"`
+	InLine  *InLine  `xml:"InLine"`
+	Wrapper *Wrapper `xml:"Wrapper"`
+}
+
+type InLine struct {
+	Creatives []Creative `xml:"Creatives>Creative"`
+}
+
+type Wrapper struct {
+	VASTAdTagURI string     `xml:"VASTAdTagURI"`
+	Creatives    []Creative `xml:"Creatives>Creative"`
+}
+
+type Creative struct {
+	Linear *Linear `xml:"Linear"`
+}
+
+type Linear struct {
+	Duration   string      `xml:"Duration"`
+	MediaFiles []MediaFile `xml:"MediaFiles>MediaFile"`
+}
+
+type MediaFile struct {
+	Type     string `xml:"type,attr"`
+	Delivery string `xml:"delivery,attr"`
+	Width    int    `xml:"width,attr"`
+	Height   int    `xml:"height,attr"`
+	URL      string `xml:",chardata"`
+}
+
+// protocols maps the supported VAST versions to their inline and wrapper protocol values.
+// VAST 4.3 has no dedicated value in AdCOM and is announced by players as VAST 4.2.
+var protocols = map[string][2]adcom1.MediaCreativeSubtype{
+	"2.0": {adcom1.CreativeVAST20, adcom1.CreativeVAST20Wrapper},
+	"3.0": {adcom1.CreativeVAST30, adcom1.CreativeVAST30Wrapper},
+	"4.0": {adcom1.CreativeVAST40, adcom1.CreativeVAST40Wrapper},
+	"4.1": {adcom1.CreativeVAST41, adcom1.CreativeVAST41Wrapper},
+	"4.2": {adcom1.CreativeVAST42, adcom1.CreativeVAST42Wrapper},
+	"4.3": {adcom1.CreativeVAST42, adcom1.CreativeVAST42Wrapper},
+}
+
+// Parse decodes the VAST markup and checks that it is well formed and structurally valid.
+func Parse(markup []byte) (*Document, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(markup))
+	decoder.Strict = true
+
+	var doc Document
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("malformed VAST: %v", err)
+	}
+	if err := doc.validate(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (doc *Document) validate() error {
+	if _, ok := protocols[doc.normalizedVersion()]; !ok {
+		return fmt.Errorf("unsupported VAST version %q", doc.Version)
+	}
+	if len(doc.Ads) == 0 {
+		return errors.New("VAST contains no ads")
+	}
+	for i, ad := range doc.Ads {
+		switch {
+		case ad.InLine != nil && ad.Wrapper != nil:
+			return fmt.Errorf("ad %d has both InLine and Wrapper elements", i)
+		case ad.InLine == nil && ad.Wrapper == nil:
+			return fmt.Errorf("ad %d has neither InLine nor Wrapper element", i)
+		case ad.Wrapper != nil && strings.TrimSpace(ad.Wrapper.VASTAdTagURI) == "":
+			return fmt.Errorf("ad %d is a Wrapper without a VASTAdTagURI", i)
+		}
+	}
+	return nil
+}
+
+// normalizedVersion accepts both "4" and "4.0" style version attributes.
+func (doc *Document) normalizedVersion() string {
+	version := strings.TrimSpace(doc.Version)
+	if !strings.Contains(version, ".") {
+		version += ".0"
+	}
+	return version
+}
+
+// IsWrapper returns true when the document's first ad redirects to another VAST document.
+func (doc *Document) IsWrapper() bool {
+	return doc.Ads[0].Wrapper != nil
+}
+
+// WrapperURI returns the VASTAdTagURI of the document's first ad, or an empty string for inline documents.
+func (doc *Document) WrapperURI() string {
+	if !doc.IsWrapper() {
+		return ""
+	}
+	return strings.TrimSpace(doc.Ads[0].Wrapper.VASTAdTagURI)
+}
+
+// Protocol returns the OpenRTB protocol value a player must support to play the document.
+func (doc *Document) Protocol() adcom1.MediaCreativeSubtype {
+	protocol := protocols[doc.normalizedVersion()]
+	if doc.IsWrapper() {
+		return protocol[1]
+	}
+	return protocol[0]
+}
+
+// linears returns the linear creatives of the document's inline ads.
+func (doc *Document) linears() []*Linear {
+	var linears []*Linear
+	for _, ad := range doc.Ads {
+		if ad.InLine == nil {
+			continue
+		}
+		for _, creative := range ad.InLine.Creatives {
+			if creative.Linear != nil {
+				linears = append(linears, creative.Linear)
+			}
+		}
+	}
+	return linears
+}
+
+// ParseDuration parses a VAST HH:MM:SS or HH:MM:SS.mmm duration.
+func ParseDuration(value string) (time.Duration, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	hours, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	minutes, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil || minutes > 59 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil || seconds < 0 || seconds >= 60 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second)), nil
+}
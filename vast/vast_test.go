@@ -0,0 +1,120 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name             string
+		markup           string
+		expectedProtocol adcom1.MediaCreativeSubtype
+		expectedWrapper  string
+		expectedErr      string
+	}{
+		{
+			name:             "inline-2.0",
+			markup:           `<VAST version="2.0"><Ad id="1"><InLine><Creatives><Creative><Linear><Duration>00:00:15</Duration></Linear></Creative></Creatives></InLine></Ad></VAST>`,
+			expectedProtocol: adcom1.CreativeVAST20,
+		},
+		{
+			name:             "wrapper-4.1",
+			markup:           `<VAST version="4.1"><Ad><Wrapper><VASTAdTagURI><![CDATA[ https://vast.test/tag ]]></VASTAdTagURI></Wrapper></Ad></VAST>`,
+			expectedProtocol: adcom1.CreativeVAST41Wrapper,
+			expectedWrapper:  "https://vast.test/tag",
+		},
+		{
+			name:             "inline-4.3-maps-to-4.2",
+			markup:           `<VAST version="4.3"><Ad><InLine></InLine></Ad></VAST>`,
+			expectedProtocol: adcom1.CreativeVAST42,
+		},
+		{
+			name:             "major-version-only",
+			markup:           `<VAST version="3"><Ad><InLine></InLine></Ad></VAST>`,
+			expectedProtocol: adcom1.CreativeVAST30,
+		},
+		{
+			name:        "malformed",
+			markup:      `<VAST version="3.0"><Ad><InLine></Ad></VAST>`,
+			expectedErr: "malformed VAST",
+		},
+		{
+			name:        "not-vast",
+			markup:      `<div>banner</div>`,
+			expectedErr: "malformed VAST",
+		},
+		{
+			name:        "unsupported-version",
+			markup:      `<VAST version="1.0"><Ad><InLine></InLine></Ad></VAST>`,
+			expectedErr: `unsupported VAST version "1.0"`,
+		},
+		{
+			name:        "no-ads",
+			markup:      `<VAST version="3.0"></VAST>`,
+			expectedErr: "VAST contains no ads",
+		},
+		{
+			name:        "neither-inline-nor-wrapper",
+			markup:      `<VAST version="3.0"><Ad></Ad></VAST>`,
+			expectedErr: "ad 0 has neither InLine nor Wrapper element",
+		},
+		{
+			name:        "inline-and-wrapper",
+			markup:      `<VAST version="3.0"><Ad><InLine></InLine><Wrapper><VASTAdTagURI>https://vast.test</VASTAdTagURI></Wrapper></Ad></VAST>`,
+			expectedErr: "ad 0 has both InLine and Wrapper elements",
+		},
+		{
+			name:        "wrapper-without-uri",
+			markup:      `<VAST version="3.0"><Ad><Wrapper></Wrapper></Ad></VAST>`,
+			expectedErr: "ad 0 is a Wrapper without a VASTAdTagURI",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := Parse([]byte(test.markup))
+			if len(test.expectedErr) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedProtocol, doc.Protocol())
+			assert.Equal(t, test.expectedWrapper, doc.WrapperURI())
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	testCases := []struct {
+		value       string
+		expected    time.Duration
+		expectedErr bool
+	}{
+		{value: "00:00:30", expected: 30 * time.Second},
+		{value: "01:02:03", expected: time.Hour + 2*time.Minute + 3*time.Second},
+		{value: " 00:00:15.500 ", expected: 15500 * time.Millisecond},
+		{value: "", expectedErr: true},
+		{value: "30", expectedErr: true},
+		{value: "00:60:00", expectedErr: true},
+		{value: "00:00:61", expectedErr: true},
+		{value: "aa:00:00", expectedErr: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.value, func(t *testing.T) {
+			duration, err := ParseDuration(test.value)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, duration)
+		})
+	}
+}